@@ -21,6 +21,7 @@ import (
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/urfave/cli"
 	"github.com/dipperin/dipperin-core/core/chain-config"
+	"time"
 )
 
 // define flag names
@@ -36,6 +37,15 @@ const (
 	HttpPortFlagName = "http_port"
 	WsHostFlagName = "ws_host"
 	WsPortFlagName = "ws_port"
+	ExplorerHostFlagName = "explorer_host"
+	ExplorerPortFlagName = "explorer_port"
+	StratumHostFlagName = "stratum_host"
+	StratumPortFlagName = "stratum_port"
+	MineRewardModeFlagName = "mine_reward_mode"
+	MinePPLNSWindowFlagName = "mine_pplns_window"
+	MinePayoutThresholdFlagName = "mine_payout_threshold"
+	MinePayoutIntervalFlagName = "mine_payout_interval"
+	MinerThreadsFlagName = "miner_threads"
 	IPCPathFlagName = "ipc_path"
 	DebugModeFlagName = "debug_mode"
 
@@ -43,6 +53,8 @@ const (
 	SoftWalletPasswordFlagName = "soft_wallet_pwd"
 	SoftWalletPassPhraseFlagName = "soft_wallet_pass_phrase"
 	SoftWalletPath = "soft_wallet_path"
+	HardWalletFlagName = "hard_wallet"
+	HardWalletPathFlagName = "hard_wallet_path"
 
 	IsScannerFlagName = "is_scanner"
 
@@ -55,6 +67,16 @@ const (
 	NodeNameFlagName       = "node_name"
 
 	IsStartMine = "is_start_mine"
+	LightSync   = "light_sync"
+	AddressIndex = "address_index"
+	TxFeeBump = "tx_fee_bump"
+	EvidenceReporter = "evidence_reporter"
+	Archive = "archive"
+	StateRetain = "state_retain"
+	FastSync = "fast_sync"
+	Dev = "dev"
+	GenesisFlagName = "genesis"
+	DevMnemonic = "dev_mnemonic"
 	NoDiscovery = "no_discovery"
 	Nat = "nat"
 
@@ -75,6 +97,15 @@ var (
 		HttpPortFlag,
 		WsHostFlag,
 		WsPortFlag,
+		ExplorerHostFlag,
+		ExplorerPortFlag,
+		StratumHostFlag,
+		StratumPortFlag,
+		MineRewardModeFlag,
+		MinePPLNSWindowFlag,
+		MinePayoutThresholdFlag,
+		MinePayoutIntervalFlag,
+		MinerThreadsFlag,
 		IPCPathFlag,
 		UseStaticNodesFlag,
 		NodeNameFlag,
@@ -82,6 +113,8 @@ var (
 		SoftWalletPasswordFlag,
 		SoftWalletPassPhraseFlag,
 		SoftWalletPathFlag,
+		HardWalletFlag,
+		HardWalletPathFlag,
 		IsScannerFlag,
 		IsUploadNodeDataFlag,
 		//IsPerformanceFlag,
@@ -90,6 +123,15 @@ var (
 		MetricsEnabledFlag,
 
 		IsStartMineFlag,
+		LightSyncFlag,
+		AddressIndexFlag,
+		TxFeeBumpFlag,
+		EvidenceReporterFlag,
+		ArchiveFlag,
+		StateRetainFlag,
+		FastSyncFlag,
+		DevFlag,
+		DevMnemonicFlag,
 		NoDiscoveryFlag,
 		NatFlag,
 		AllowHostsFlag,
@@ -142,6 +184,16 @@ var (
 		Usage: "set whether needing path for creating or openning wallet",
 		Value: "",
 	}
+	HardWalletFlag = cli.StringFlag{
+		Name: HardWalletFlagName,
+		Usage: "keep the default account in a hardware wallet instead of the soft wallet, LedgerWallet or TrezorWallet",
+		Value: "",
+	}
+	HardWalletPathFlag = cli.StringFlag{
+		Name: HardWalletPathFlagName,
+		Usage: "set the hid device path of the hardware wallet, the first device found is used if not set",
+		Value: "",
+	}
 	HttpHostFlag = cli.StringFlag{
 		Name: HttpHostFlagName,
 		Usage: "set http host",
@@ -176,6 +228,47 @@ var (
 		Usage: "set web socket port",
 		Value: 7002,
 	}
+	ExplorerHostFlag = cli.StringFlag{
+		Name: ExplorerHostFlagName,
+		Usage: "set the host of the block explorer REST server, not start it if empty",
+	}
+	ExplorerPortFlag = cli.IntFlag{
+		Name: ExplorerPortFlagName,
+		Usage: "set the port of the block explorer REST server",
+		Value: 7003,
+	}
+	StratumHostFlag = cli.StringFlag{
+		Name: StratumHostFlagName,
+		Usage: "set the host of the stratum mining server of the mine master, not start it if empty",
+	}
+	StratumPortFlag = cli.IntFlag{
+		Name: StratumPortFlagName,
+		Usage: "set the port of the stratum mining server",
+		Value: 7004,
+	}
+	MineRewardModeFlag = cli.StringFlag{
+		Name: MineRewardModeFlagName,
+		Usage: "how the mine master shares the block rewards with the workers, pplns or pps",
+		Value: "pplns",
+	}
+	MinePPLNSWindowFlag = cli.Float64Flag{
+		Name: MinePPLNSWindowFlagName,
+		Usage: "the PPLNS window of the last shares in multiples of the block difficulty",
+		Value: 2,
+	}
+	MinePayoutThresholdFlag = cli.Float64Flag{
+		Name: MinePayoutThresholdFlagName,
+		Usage: "the balance in DIP of a worker paid by the mine master automatically, 0 disables the payout",
+	}
+	MinePayoutIntervalFlag = cli.DurationFlag{
+		Name: MinePayoutIntervalFlagName,
+		Usage: "the interval of the payout rounds of the mine master",
+		Value: 10 * time.Minute,
+	}
+	MinerThreadsFlag = cli.IntFlag{
+		Name: MinerThreadsFlagName,
+		Usage: "the number of the hashing threads of the local worker, 0 uses all the cpu cores",
+	}
 	P2PListenerFlag = cli.StringFlag{
 		Name: P2PListenerFlagName,
 		Usage: "set p2p port",
@@ -217,6 +310,58 @@ var (
 		Usage: "set whether mining，0 no，1 yes",
 	}
 
+	LightSyncFlag = cli.BoolFlag{
+		Name:  LightSync,
+		Usage: "sync the chain as a light client with super block proof and headers, only for the normal node",
+	}
+
+	AddressIndexFlag = cli.BoolFlag{
+		Name:  AddressIndex,
+		Usage: "index the transactions by address to query the history of an address",
+	}
+
+	TxFeeBumpFlag = cli.Uint64Flag{
+		Name:  TxFeeBump,
+		Usage: "minimum fee bump percentage to replace a transaction in the tx pool, 0 uses the default",
+	}
+
+	EvidenceReporterFlag = cli.StringFlag{
+		Name:  EvidenceReporter,
+		Usage: "the wallet account of the verifier sending the double sign evidence found by bft automatically",
+	}
+
+	ArchiveFlag = cli.BoolFlag{
+		Name:  Archive,
+		Usage: "keep the state of all blocks on disk instead of pruning the old ones",
+	}
+
+	StateRetainFlag = cli.IntFlag{
+		Name:  StateRetain,
+		Value: 256,
+		Usage: "number of recent blocks whose state is kept when the node isn't an archive node",
+	}
+
+	FastSyncFlag = cli.BoolFlag{
+		Name:  FastSync,
+		Usage: "download the state of a recent block instead of processing all blocks when the node starts from the genesis",
+	}
+
+	DevFlag = cli.BoolFlag{
+		Name:  Dev,
+		Usage: "run a single node dev chain which seals a block for every new tx, the dev accounts are funded in the genesis",
+	}
+
+	DevMnemonicFlag = cli.StringFlag{
+		Name:  DevMnemonic,
+		Usage: "mnemonic of the funded dev accounts, the default one is used if not set",
+	}
+
+	// only used by the init command
+	GenesisFlag = cli.StringFlag{
+		Name:  GenesisFlagName,
+		Usage: "path of the json genesis file of the private network",
+	}
+
 	NoDiscoveryFlag = cli.IntFlag{
 		Name: NoDiscovery,
 		Value: 0,
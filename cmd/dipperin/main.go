@@ -33,6 +33,7 @@ func main() {
 	log.Info("~~~~~~~~~start app ~~~~~~~~~~~~")
 	app := base.NewApp("dipperin", "dipperin node and console")
 	app.Flags = append(config.Flags, debug.Flags...)
+	app.Commands = service.ChainCommands
 	app.Action = func(c *cli.Context) error {
 		debug.Setup(c)
 
@@ -27,6 +27,7 @@ import (
 	"github.com/urfave/cli"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 )
 
@@ -38,8 +39,8 @@ func StartNode(c *cli.Context, async bool, logToConsole bool, logToFile bool) (d
 
 	log.Info("the nodeConf nodeType is:","nodeType",nodeConf.NodeType)
 	log.Info("the nodeConf SoftWalletPath is:","SoftWalletPath",nodeConf.SoftWalletPath)
-	//normal not need wallet password
-	if nodeConf.NodeType != chain_config.NodeTypeOfNormal{
+	//normal not need wallet password, neither does the node keeping its account in a hardware wallet
+	if nodeConf.NodeType != chain_config.NodeTypeOfNormal && nodeConf.HardWallet == ""{
 		if nodeConf.SoftWalletPassword == ""{
 			log.Error("please input password to establish or open wallet")
 			return nil,errors.New("please input password to establish or open wallet")
@@ -98,6 +99,15 @@ func getNodeConf(c *cli.Context) dipperin.NodeConfig {
 	nodeConf.HTTPPort = c.Int(config.HttpPortFlagName)
 	nodeConf.WSHost = c.String(config.WsHostFlagName)
 	nodeConf.WSPort = c.Int(config.WsPortFlagName)
+	nodeConf.ExplorerHost = c.String(config.ExplorerHostFlagName)
+	nodeConf.ExplorerPort = c.Int(config.ExplorerPortFlagName)
+	nodeConf.StratumHost = c.String(config.StratumHostFlagName)
+	nodeConf.StratumPort = c.Int(config.StratumPortFlagName)
+	nodeConf.MineRewardMode = c.String(config.MineRewardModeFlagName)
+	nodeConf.MinePPLNSWindow = c.Float64(config.MinePPLNSWindowFlagName)
+	nodeConf.MinePayoutThreshold = c.Float64(config.MinePayoutThresholdFlagName)
+	nodeConf.MinePayoutInterval = c.Duration(config.MinePayoutIntervalFlagName)
+	nodeConf.MinerThreads = c.Int(config.MinerThreadsFlagName)
 	nodeConf.IPCPath = c.String(config.IPCPathFlagName)
 	nodeConf.DataDir = c.String(config.DataDirFlagName)
 	nodeConf.NodeType = c.Int(config.NodeTypeFlagName)
@@ -109,6 +119,8 @@ func getNodeConf(c *cli.Context) dipperin.NodeConfig {
 	nodeConf.SoftWalletPassword = c.String(config.SoftWalletPasswordFlagName)
 	nodeConf.SoftWalletPassPhrase = c.String(config.SoftWalletPassPhraseFlagName)
 	nodeConf.SoftWalletPath = c.String(config.SoftWalletPath)
+	nodeConf.HardWallet = c.String(config.HardWalletFlagName)
+	nodeConf.HardWalletPath = c.String(config.HardWalletPathFlagName)
 	nodeConf.IsScanner = c.Int(config.IsScannerFlagName)
 	nodeConf.IsUploadNodeData = c.Int(config.IsUploadNodeData)
 	nodeConf.UploadURL = c.String(config.UploadURL)
@@ -116,6 +128,25 @@ func getNodeConf(c *cli.Context) dipperin.NodeConfig {
 	nodeConf.Nat = c.String(config.Nat)
 	nodeConf.AllowHosts = c.StringSlice(config.AllowHostsFlagName)
 	nodeConf.PMetricsPort = c.Int(config.MetricsPortFlagName)
+	nodeConf.LightSync = c.Bool(config.LightSync)
+	nodeConf.AddressIndex = c.Bool(config.AddressIndex)
+	nodeConf.TxFeeBump = c.Uint64(config.TxFeeBump)
+	nodeConf.EvidenceReporter = c.String(config.EvidenceReporter)
+	nodeConf.Archive = c.Bool(config.Archive)
+	nodeConf.StateRetainBlocks = c.Int(config.StateRetain)
+	nodeConf.FastSync = c.Bool(config.FastSync)
+	nodeConf.DevMode = c.Bool(config.Dev)
+	nodeConf.DevMnemonic = c.String(config.DevMnemonic)
+	if nodeConf.DevMode {
+		// keep the dev chain apart from the data of the real chain
+		if !c.IsSet(config.DataDirFlagName) {
+			nodeConf.DataDir = filepath.Join(chain_config.DefaultDataDir(), "dev")
+		}
+		nodeConf.NoDiscovery = 1
+		if nodeConf.SoftWalletPassword == "" {
+			nodeConf.SoftWalletPassword = dipperin.DefaultDevWalletPassword
+		}
+	}
 
 	if c.Int(config.IsStartMine) == 0{
 		nodeConf.IsStartMine =false
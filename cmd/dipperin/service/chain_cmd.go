@@ -0,0 +1,176 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"bufio"
+	"errors"
+	"github.com/dipperin/dipperin-core/cmd/dipperin/config"
+	"github.com/dipperin/dipperin-core/cmd/utils"
+	"github.com/dipperin/dipperin-core/core/cs-chain"
+	"github.com/dipperin/dipperin-core/core/dipperin"
+	"github.com/dipperin/dipperin-core/third-party/log"
+	"github.com/urfave/cli"
+	"os"
+	"strconv"
+)
+
+var (
+	ErrChainFileNotSet   = errors.New("the file is required")
+	ErrGenesisFileNotSet = errors.New("the genesis file is required")
+)
+
+// ChainCommands init, export and import the chain in the data dir while the node isn't running
+var ChainCommands = []cli.Command{
+	{
+		Name:   "init",
+		Usage:  "write the genesis block of the genesis file into a fresh data dir, the node started on the data dir uses the chain config of the file",
+		Flags:  append([]cli.Flag{config.GenesisFlag}, config.Flags...),
+		Action: initGenesis,
+	},
+	{
+		Name:      "export",
+		Usage:     "export the blocks with their votes to the file, the last block defaults to the current block",
+		ArgsUsage: "<file> [from] [to]",
+		Flags:     config.Flags,
+		Action:    exportChain,
+	},
+	{
+		Name:      "import",
+		Usage:     "import the blocks exported to the file through the chain writer",
+		ArgsUsage: "<file>",
+		Flags:     config.Flags,
+		Action:    importChain,
+	},
+	{
+		Name:      "export_state",
+		Usage:     "export the state snapshot of the block to the file, the block defaults to the current block",
+		ArgsUsage: "<file> [number]",
+		Flags:     config.Flags,
+		Action:    exportState,
+	},
+}
+
+// parse the block number at the index of the args, the current block number is used if not set
+func blockNumberArg(c *cli.Context, index int, chain *cs_chain.CsChainService) (uint64, error) {
+	if len(c.Args()) <= index {
+		return chain.CurrentBlock().Number(), nil
+	}
+	return strconv.ParseUint(c.Args().Get(index), 10, 64)
+}
+
+func openOfflineChain(c *cli.Context) *cs_chain.CsChainService {
+	extraBeforeStart(c, true, false)
+	return dipperin.OpenOfflineChain(getNodeConf(c))
+}
+
+func closeOfflineChain(chain *cs_chain.CsChainService) {
+	if err := chain.GetStateStorage().Flush(); err != nil {
+		log.Error("flush state failed", "err", err)
+	}
+	chain.GetDB().Close()
+}
+
+func initGenesis(c *cli.Context) error {
+	genesisFile := c.String(config.GenesisFlagName)
+	if genesisFile == "" {
+		return ErrGenesisFileNotSet
+	}
+	extraBeforeStart(c, true, false)
+
+	dataDir := c.String(config.DataDirFlagName)
+	if err := utils.InitGenesis(dataDir, genesisFile); err != nil {
+		return err
+	}
+	log.Info("init genesis finished", "data dir", dataDir, "genesis file", genesisFile)
+	return nil
+}
+
+func exportChain(c *cli.Context) error {
+	if len(c.Args()) < 1 {
+		return ErrChainFileNotSet
+	}
+	chain := openOfflineChain(c)
+	defer closeOfflineChain(chain)
+
+	from := uint64(0)
+	if len(c.Args()) > 1 {
+		var err error
+		if from, err = strconv.ParseUint(c.Args().Get(1), 10, 64); err != nil {
+			return err
+		}
+	}
+	to, err := blockNumberArg(c, 2, chain)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(c.Args().First())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	if err = chain.ExportChain(w, from, to); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+func importChain(c *cli.Context) error {
+	if len(c.Args()) < 1 {
+		return ErrChainFileNotSet
+	}
+	chain := openOfflineChain(c)
+	defer closeOfflineChain(chain)
+
+	file, err := os.Open(c.Args().First())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	imported, err := chain.ImportChain(bufio.NewReader(file))
+	log.Info("import chain finished", "imported", imported, "current block", chain.CurrentBlock().Number())
+	return err
+}
+
+func exportState(c *cli.Context) error {
+	if len(c.Args()) < 1 {
+		return ErrChainFileNotSet
+	}
+	chain := openOfflineChain(c)
+	defer closeOfflineChain(chain)
+
+	number, err := blockNumberArg(c, 1, chain)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(c.Args().First())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	if err = chain.ExportStateSnapshot(w, number); err != nil {
+		return err
+	}
+	return w.Flush()
+}
@@ -44,6 +44,7 @@ var CliCommands = []cli.Command{
 			return nil
 		},
 	},
+	offlineTxCommand,
 }
 
 var rpcFlags = []cli.Flag{
@@ -0,0 +1,146 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/core/rpc-interface"
+	"github.com/urfave/cli"
+)
+
+func (caller *rpcCaller) GetDelegations(c *cli.Context) {
+	mName, cParams, err := getRpcMethodAndParam(c)
+	if err != nil {
+		l.Error("getRpcMethodAndParam error", "err", err)
+		return
+	}
+
+	if len(cParams) != 1 {
+		l.Error("GetDelegations need：verifierAddress")
+		return
+	}
+
+	verifier, err := CheckAndChangeHexToAddress(cParams[0])
+	if err != nil {
+		l.Error("the verifier address is invalid", "err", err)
+		return
+	}
+
+	var resp rpc_interface.DelegationsResp
+	if err := client.Call(&resp, getDipperinRpcMethodByName(mName), verifier); err != nil {
+		l.Error("call get delegations", "err", err)
+		return
+	}
+	stake, _ := CSCoinToMoneyValue(resp.Stake)
+	delegated, _ := CSCoinToMoneyValue(resp.Delegated)
+	l.Info("GetDelegations result", "stake", stake, "delegated", delegated)
+	for _, d := range resp.Delegations {
+		amount, _ := CSCoinToMoneyValue(d.Amount)
+		l.Info("delegation", "delegator", d.Delegator.Hex(), "amount", amount, "unbondNum", d.UnbondNum)
+	}
+}
+
+func (caller *rpcCaller) SendDelegateTransaction(c *cli.Context) {
+	if checkSync() {
+		return
+	}
+
+	mName, cParams, err := getRpcMethodAndParam(c)
+	if err != nil {
+		l.Error("getRpcMethodAndParam error", "err", err)
+		return
+	}
+
+	if len(cParams) != 4 {
+		l.Error("SendDelegateTransaction need：from verifier value transactionFee")
+		return
+	}
+
+	from, err := CheckAndChangeHexToAddress(cParams[0])
+	if err != nil {
+		l.Error("the from address is invalid", "err", err)
+		return
+	}
+
+	verifier, err := CheckAndChangeHexToAddress(cParams[1])
+	if err != nil {
+		l.Error("the verifier address is invalid", "err", err)
+		return
+	}
+
+	value, err := MoneyValueToCSCoin(cParams[2])
+	if err != nil {
+		l.Error("the parameter value invalid")
+		return
+	}
+
+	txFee, err := MoneyValueToCSCoin(cParams[3])
+	if err != nil {
+		l.Error("the parameter transactionFee invalid")
+		return
+	}
+
+	var resp common.Hash
+	if err := client.Call(&resp, getDipperinRpcMethodByName(mName), from, verifier, value, txFee, nil); err != nil {
+		l.Error("call send delegate transaction", "err", err)
+		return
+	}
+	l.Info("SendDelegateTransaction result", "txId", resp.Hex())
+}
+
+// the first tx starts unbonding, send it again after the stake lock slots to get the DIP back
+func (caller *rpcCaller) SendUnDelegateTransaction(c *cli.Context) {
+	if checkSync() {
+		return
+	}
+
+	mName, cParams, err := getRpcMethodAndParam(c)
+	if err != nil {
+		l.Error("getRpcMethodAndParam error", "err", err)
+		return
+	}
+
+	if len(cParams) != 3 {
+		l.Error("SendUnDelegateTransaction need：from verifier transactionFee")
+		return
+	}
+
+	from, err := CheckAndChangeHexToAddress(cParams[0])
+	if err != nil {
+		l.Error("the from address is invalid", "err", err)
+		return
+	}
+
+	verifier, err := CheckAndChangeHexToAddress(cParams[1])
+	if err != nil {
+		l.Error("the verifier address is invalid", "err", err)
+		return
+	}
+
+	txFee, err := MoneyValueToCSCoin(cParams[2])
+	if err != nil {
+		l.Error("the parameter transactionFee invalid")
+		return
+	}
+
+	var resp common.Hash
+	if err := client.Call(&resp, getDipperinRpcMethodByName(mName), from, verifier, txFee, nil); err != nil {
+		l.Error("call send undelegate transaction", "err", err)
+		return
+	}
+	l.Info("SendUnDelegateTransaction result", "txId", resp.Hex())
+}
@@ -0,0 +1,127 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+	"os"
+	"testing"
+)
+
+func Test_rpcCaller_GetDelegations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	// the early token tests expect a nil client
+	defer func() { client = nil }()
+
+	app := getRpcTestApp()
+	app.Action = func(context *cli.Context) {
+		client = NewMockRpcClient(ctrl)
+		c := &rpcCaller{}
+		c.GetDelegations(context)
+
+		wrapRpcArgs(context, "GetDelegations", "")
+		c.GetDelegations(context)
+
+		wrapRpcArgs(context, "GetDelegations", "a")
+		c.GetDelegations(context)
+
+		wrapRpcArgs(context, "GetDelegations", testLockAddr1)
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("test"))
+		c.GetDelegations(context)
+
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		c.GetDelegations(context)
+	}
+	assert.NoError(t, app.Run([]string{os.Args[0]}))
+}
+
+func Test_rpcCaller_SendDelegateTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	// the early token tests expect a nil client
+	defer func() { client = nil }()
+
+	app := getRpcTestApp()
+	app.Action = func(context *cli.Context) {
+		client = NewMockRpcClient(ctrl)
+		c := &rpcCaller{}
+		SyncStatus.Store(true)
+		c.SendDelegateTransaction(context)
+
+		wrapRpcArgs(context, "SendDelegateTransaction", "")
+		c.SendDelegateTransaction(context)
+
+		wrapRpcArgs(context, "SendDelegateTransaction", "a,b,c,d")
+		c.SendDelegateTransaction(context)
+
+		wrapRpcArgs(context, "SendDelegateTransaction", testLockAddr1+",b,c,d")
+		c.SendDelegateTransaction(context)
+
+		wrapRpcArgs(context, "SendDelegateTransaction", testLockAddr1+","+testLockAddr2+",c,d")
+		c.SendDelegateTransaction(context)
+
+		wrapRpcArgs(context, "SendDelegateTransaction", testLockAddr1+","+testLockAddr2+",100,d")
+		c.SendDelegateTransaction(context)
+
+		wrapRpcArgs(context, "SendDelegateTransaction", testLockAddr1+","+testLockAddr2+",100,1")
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("test"))
+		c.SendDelegateTransaction(context)
+
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		c.SendDelegateTransaction(context)
+	}
+	assert.NoError(t, app.Run([]string{os.Args[0]}))
+}
+
+func Test_rpcCaller_SendUnDelegateTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	// the early token tests expect a nil client
+	defer func() { client = nil }()
+
+	app := getRpcTestApp()
+	app.Action = func(context *cli.Context) {
+		client = NewMockRpcClient(ctrl)
+		c := &rpcCaller{}
+		SyncStatus.Store(true)
+		c.SendUnDelegateTransaction(context)
+
+		wrapRpcArgs(context, "SendUnDelegateTransaction", "")
+		c.SendUnDelegateTransaction(context)
+
+		wrapRpcArgs(context, "SendUnDelegateTransaction", "a,b,c")
+		c.SendUnDelegateTransaction(context)
+
+		wrapRpcArgs(context, "SendUnDelegateTransaction", testLockAddr1+",b,c")
+		c.SendUnDelegateTransaction(context)
+
+		wrapRpcArgs(context, "SendUnDelegateTransaction", testLockAddr1+","+testLockAddr2+",c")
+		c.SendUnDelegateTransaction(context)
+
+		wrapRpcArgs(context, "SendUnDelegateTransaction", testLockAddr1+","+testLockAddr2+",1")
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("test"))
+		c.SendUnDelegateTransaction(context)
+
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		c.SendUnDelegateTransaction(context)
+	}
+	assert.NoError(t, app.Run([]string{os.Args[0]}))
+}
@@ -0,0 +1,233 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/hexutil"
+	"github.com/dipperin/dipperin-core/core/rpc-interface"
+	"github.com/dipperin/dipperin-core/third-party/crypto/cs-crypto"
+	"github.com/urfave/cli"
+	"math/big"
+)
+
+// get the hash lock of a hash key, the hash key is hex encoded
+func (caller *rpcCaller) GetHashLock(c *cli.Context) {
+	_, cParams, err := getRpcMethodAndParam(c)
+	if err != nil {
+		l.Error("getRpcMethodAndParam error", "err", err)
+		return
+	}
+
+	if len(cParams) != 1 {
+		l.Error("GetHashLock need：hashKey")
+		return
+	}
+
+	hashKey, err := hexutil.Decode(cParams[0])
+	if err != nil {
+		l.Error("the hashKey is invalid", "err", err)
+		return
+	}
+	l.Info("GetHashLock result", "hashLock", cs_crypto.Keccak256Hash(hashKey).Hex())
+}
+
+// lock value to the receiver until the time lock height
+func (caller *rpcCaller) SendLockTransaction(c *cli.Context) {
+	if checkSync() {
+		return
+	}
+
+	mName, cParams, err := getRpcMethodAndParam(c)
+	if err != nil {
+		l.Error("getRpcMethodAndParam error", "err", err)
+		return
+	}
+
+	if len(cParams) != 6 {
+		l.Error("SendLockTransaction need：from to hashLock timeLock value transactionFee")
+		return
+	}
+
+	from, err := CheckAndChangeHexToAddress(cParams[0])
+	if err != nil {
+		l.Error("the from address is invalid", "err", err)
+		return
+	}
+
+	to, err := CheckAndChangeHexToAddress(cParams[1])
+	if err != nil {
+		l.Error("the to address is invalid", "err", err)
+		return
+	}
+
+	hashLockBytes, err := hexutil.Decode(cParams[2])
+	if err != nil || len(hashLockBytes) != common.HashLength {
+		l.Error("the hashLock is invalid", "err", err)
+		return
+	}
+	hashLock := common.BytesToHash(hashLockBytes)
+
+	timeLock, ok := new(big.Int).SetString(cParams[3], 10)
+	if !ok {
+		l.Error("the timeLock is invalid")
+		return
+	}
+
+	value, err := MoneyValueToCSCoin(cParams[4])
+	if err != nil {
+		l.Error("the parameter value invalid")
+		return
+	}
+
+	txFee, err := MoneyValueToCSCoin(cParams[5])
+	if err != nil {
+		l.Error("the parameter transactionFee invalid")
+		return
+	}
+
+	var resp common.Hash
+	if err := client.Call(&resp, getDipperinRpcMethodByName(mName), from, to, hashLock, timeLock, value, txFee, nil); err != nil {
+		l.Error("call send lock transaction", "err", err)
+		return
+	}
+	l.Info("SendLockTransaction result", "txId", resp.Hex(), "lockAddress", cs_crypto.GetLockAddress(from, to).Hex())
+}
+
+// claim the locked value with the hash key before the time lock
+func (caller *rpcCaller) SendClaimTransaction(c *cli.Context) {
+	if checkSync() {
+		return
+	}
+
+	mName, cParams, err := getRpcMethodAndParam(c)
+	if err != nil {
+		l.Error("getRpcMethodAndParam error", "err", err)
+		return
+	}
+
+	if len(cParams) != 4 {
+		l.Error("SendClaimTransaction need：from lockSender hashKey transactionFee")
+		return
+	}
+
+	from, err := CheckAndChangeHexToAddress(cParams[0])
+	if err != nil {
+		l.Error("the from address is invalid", "err", err)
+		return
+	}
+
+	lockSender, err := CheckAndChangeHexToAddress(cParams[1])
+	if err != nil {
+		l.Error("the lockSender address is invalid", "err", err)
+		return
+	}
+
+	hashKey, err := hexutil.Decode(cParams[2])
+	if err != nil {
+		l.Error("the hashKey is invalid", "err", err)
+		return
+	}
+
+	txFee, err := MoneyValueToCSCoin(cParams[3])
+	if err != nil {
+		l.Error("the parameter transactionFee invalid")
+		return
+	}
+
+	var resp common.Hash
+	if err := client.Call(&resp, getDipperinRpcMethodByName(mName), from, lockSender, hexutil.Bytes(hashKey), txFee, nil); err != nil {
+		l.Error("call send claim transaction", "err", err)
+		return
+	}
+	l.Info("SendClaimTransaction result", "txId", resp.Hex())
+}
+
+// take the locked value back after the time lock
+func (caller *rpcCaller) SendRefundTransaction(c *cli.Context) {
+	if checkSync() {
+		return
+	}
+
+	mName, cParams, err := getRpcMethodAndParam(c)
+	if err != nil {
+		l.Error("getRpcMethodAndParam error", "err", err)
+		return
+	}
+
+	if len(cParams) != 3 {
+		l.Error("SendRefundTransaction need：from lockReceiver transactionFee")
+		return
+	}
+
+	from, err := CheckAndChangeHexToAddress(cParams[0])
+	if err != nil {
+		l.Error("the from address is invalid", "err", err)
+		return
+	}
+
+	lockReceiver, err := CheckAndChangeHexToAddress(cParams[1])
+	if err != nil {
+		l.Error("the lockReceiver address is invalid", "err", err)
+		return
+	}
+
+	txFee, err := MoneyValueToCSCoin(cParams[2])
+	if err != nil {
+		l.Error("the parameter transactionFee invalid")
+		return
+	}
+
+	var resp common.Hash
+	if err := client.Call(&resp, getDipperinRpcMethodByName(mName), from, lockReceiver, txFee, nil); err != nil {
+		l.Error("call send refund transaction", "err", err)
+		return
+	}
+	l.Info("SendRefundTransaction result", "txId", resp.Hex())
+}
+
+func (caller *rpcCaller) GetLockInfo(c *cli.Context) {
+	mName, cParams, err := getRpcMethodAndParam(c)
+	if err != nil {
+		l.Error("getRpcMethodAndParam error", "err", err)
+		return
+	}
+
+	if len(cParams) != 2 {
+		l.Error("GetLockInfo need：lockSender lockReceiver")
+		return
+	}
+
+	lockSender, err := CheckAndChangeHexToAddress(cParams[0])
+	if err != nil {
+		l.Error("the lockSender address is invalid", "err", err)
+		return
+	}
+
+	lockReceiver, err := CheckAndChangeHexToAddress(cParams[1])
+	if err != nil {
+		l.Error("the lockReceiver address is invalid", "err", err)
+		return
+	}
+
+	var resp rpc_interface.LockInfoResp
+	if err := client.Call(&resp, getDipperinRpcMethodByName(mName), lockSender, lockReceiver); err != nil {
+		l.Error("call get lock info", "err", err)
+		return
+	}
+	l.Info("GetLockInfo result", "lockAddress", resp.LockAddress.Hex(), "value", resp.Value.ToInt(), "hashLock", resp.HashLock.Hex(), "timeLock", resp.TimeLock.ToInt())
+}
@@ -0,0 +1,190 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+	"os"
+	"testing"
+)
+
+const (
+	testLockAddr1 = "0x00005033874289F4F823A896700D94274683535cF0E1"
+	testLockAddr2 = "0x0000b4293d60F051936beDecfaE1B85d5A46d377aF37"
+	testHashLock  = "0x6a4f4ac3a0bed4cb4bfda0c3a80a9ae3ca7b11e8c4dd40c3bd3fd0e5a27a3c34"
+)
+
+func Test_rpcCaller_GetHashLock(t *testing.T) {
+	app := getRpcTestApp()
+	app.Action = func(context *cli.Context) {
+		c := &rpcCaller{}
+		c.GetHashLock(context)
+
+		wrapRpcArgs(context, "GetHashLock", "")
+		c.GetHashLock(context)
+
+		wrapRpcArgs(context, "GetHashLock", "key")
+		c.GetHashLock(context)
+
+		wrapRpcArgs(context, "GetHashLock", "0x1234")
+		c.GetHashLock(context)
+	}
+	assert.NoError(t, app.Run([]string{os.Args[0]}))
+}
+
+func Test_rpcCaller_SendLockTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := getRpcTestApp()
+	app.Action = func(context *cli.Context) {
+		client = NewMockRpcClient(ctrl)
+		c := &rpcCaller{}
+		SyncStatus.Store(true)
+		c.SendLockTransaction(context)
+
+		wrapRpcArgs(context, "SendLockTransaction", "")
+		c.SendLockTransaction(context)
+
+		wrapRpcArgs(context, "SendLockTransaction", "a,b,c,d,e,f")
+		c.SendLockTransaction(context)
+
+		wrapRpcArgs(context, "SendLockTransaction", testLockAddr1+",b,c,d,e,f")
+		c.SendLockTransaction(context)
+
+		wrapRpcArgs(context, "SendLockTransaction", testLockAddr1+","+testLockAddr2+",0x12,d,e,f")
+		c.SendLockTransaction(context)
+
+		wrapRpcArgs(context, "SendLockTransaction", testLockAddr1+","+testLockAddr2+","+testHashLock+",d,e,f")
+		c.SendLockTransaction(context)
+
+		wrapRpcArgs(context, "SendLockTransaction", testLockAddr1+","+testLockAddr2+","+testHashLock+",100,e,f")
+		c.SendLockTransaction(context)
+
+		wrapRpcArgs(context, "SendLockTransaction", testLockAddr1+","+testLockAddr2+","+testHashLock+",100,10,f")
+		c.SendLockTransaction(context)
+
+		wrapRpcArgs(context, "SendLockTransaction", testLockAddr1+","+testLockAddr2+","+testHashLock+",100,10,1")
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("test"))
+		c.SendLockTransaction(context)
+
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		c.SendLockTransaction(context)
+	}
+	assert.NoError(t, app.Run([]string{os.Args[0]}))
+}
+
+func Test_rpcCaller_SendClaimTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := getRpcTestApp()
+	app.Action = func(context *cli.Context) {
+		client = NewMockRpcClient(ctrl)
+		c := &rpcCaller{}
+		SyncStatus.Store(true)
+		c.SendClaimTransaction(context)
+
+		wrapRpcArgs(context, "SendClaimTransaction", "")
+		c.SendClaimTransaction(context)
+
+		wrapRpcArgs(context, "SendClaimTransaction", "a,b,c,d")
+		c.SendClaimTransaction(context)
+
+		wrapRpcArgs(context, "SendClaimTransaction", testLockAddr1+",b,c,d")
+		c.SendClaimTransaction(context)
+
+		wrapRpcArgs(context, "SendClaimTransaction", testLockAddr1+","+testLockAddr2+",c,d")
+		c.SendClaimTransaction(context)
+
+		wrapRpcArgs(context, "SendClaimTransaction", testLockAddr1+","+testLockAddr2+",0x1234,d")
+		c.SendClaimTransaction(context)
+
+		wrapRpcArgs(context, "SendClaimTransaction", testLockAddr1+","+testLockAddr2+",0x1234,1")
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("test"))
+		c.SendClaimTransaction(context)
+
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		c.SendClaimTransaction(context)
+	}
+	assert.NoError(t, app.Run([]string{os.Args[0]}))
+}
+
+func Test_rpcCaller_SendRefundTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := getRpcTestApp()
+	app.Action = func(context *cli.Context) {
+		client = NewMockRpcClient(ctrl)
+		c := &rpcCaller{}
+		SyncStatus.Store(true)
+		c.SendRefundTransaction(context)
+
+		wrapRpcArgs(context, "SendRefundTransaction", "")
+		c.SendRefundTransaction(context)
+
+		wrapRpcArgs(context, "SendRefundTransaction", "a,b,c")
+		c.SendRefundTransaction(context)
+
+		wrapRpcArgs(context, "SendRefundTransaction", testLockAddr1+",b,c")
+		c.SendRefundTransaction(context)
+
+		wrapRpcArgs(context, "SendRefundTransaction", testLockAddr1+","+testLockAddr2+",c")
+		c.SendRefundTransaction(context)
+
+		wrapRpcArgs(context, "SendRefundTransaction", testLockAddr1+","+testLockAddr2+",1")
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("test"))
+		c.SendRefundTransaction(context)
+
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		c.SendRefundTransaction(context)
+	}
+	assert.NoError(t, app.Run([]string{os.Args[0]}))
+}
+
+func Test_rpcCaller_GetLockInfo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := getRpcTestApp()
+	app.Action = func(context *cli.Context) {
+		client = NewMockRpcClient(ctrl)
+		c := &rpcCaller{}
+		c.GetLockInfo(context)
+
+		wrapRpcArgs(context, "GetLockInfo", "")
+		c.GetLockInfo(context)
+
+		wrapRpcArgs(context, "GetLockInfo", "a,b")
+		c.GetLockInfo(context)
+
+		wrapRpcArgs(context, "GetLockInfo", testLockAddr1+",b")
+		c.GetLockInfo(context)
+
+		wrapRpcArgs(context, "GetLockInfo", testLockAddr1+","+testLockAddr2)
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("test"))
+		c.GetLockInfo(context)
+
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		c.GetLockInfo(context)
+	}
+	assert.NoError(t, app.Run([]string{os.Args[0]}))
+}
@@ -0,0 +1,91 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"github.com/dipperin/dipperin-core/core/rpc-interface"
+	"github.com/urfave/cli"
+	"time"
+)
+
+// GetMineWorkers list the workers registered to the mine master
+func (caller *rpcCaller) GetMineWorkers(c *cli.Context) {
+	mName, _, err := getRpcMethodAndParam(c)
+	if err != nil {
+		l.Error("getRpcMethodAndParam error", "err", err)
+		return
+	}
+
+	var resp []*rpc_interface.MineWorkerResp
+	if err := client.Call(&resp, getDipperinRpcMethodByName(mName)); err != nil {
+		l.Error("call get mine workers", "err", err)
+		return
+	}
+	for _, w := range resp {
+		reward, _ := CSCoinToMoneyValue(w.PendingReward)
+		l.Info("mine worker", "workerId", w.WorkerId, "coinbase", w.Coinbase.Hex(), "hashRate", w.HashRate, "accepted", w.Accepted, "stale", w.Stale, "lastSeen", time.Unix(w.LastSeen, 0).Format(time.RFC3339), "pendingReward", reward)
+	}
+	l.Info("GetMineWorkers result", "workers", len(resp))
+}
+
+// KickMineWorker stop the worker and remove it from the mine master
+func (caller *rpcCaller) KickMineWorker(c *cli.Context) {
+	mName, cParams, err := getRpcMethodAndParam(c)
+	if err != nil {
+		l.Error("getRpcMethodAndParam error", "err", err)
+		return
+	}
+
+	if len(cParams) != 1 || cParams[0] == "" {
+		l.Error("KickMineWorker need：workerId")
+		return
+	}
+
+	var resp interface{}
+	if err := client.Call(&resp, getDipperinRpcMethodByName(mName), cParams[0]); err != nil {
+		l.Error("call kick mine worker", "err", err)
+		return
+	}
+	l.Info("KickMineWorker complete", "workerId", cParams[0])
+}
+
+// SetMineWorkerCoinbase change the coinbase of the worker
+func (caller *rpcCaller) SetMineWorkerCoinbase(c *cli.Context) {
+	mName, cParams, err := getRpcMethodAndParam(c)
+	if err != nil {
+		l.Error("getRpcMethodAndParam error", "err", err)
+		return
+	}
+
+	if len(cParams) != 2 {
+		l.Error("SetMineWorkerCoinbase need：workerId coinbase")
+		return
+	}
+
+	coinbase, err := CheckAndChangeHexToAddress(cParams[1])
+	if err != nil {
+		l.Error("the coinbase address is invalid", "err", err)
+		return
+	}
+
+	var resp interface{}
+	if err := client.Call(&resp, getDipperinRpcMethodByName(mName), cParams[0], coinbase); err != nil {
+		l.Error("call set mine worker coinbase", "err", err)
+		return
+	}
+	l.Info("SetMineWorkerCoinbase complete", "workerId", cParams[0], "coinbase", coinbase.Hex())
+}
@@ -0,0 +1,108 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"errors"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/hexutil"
+	"github.com/dipperin/dipperin-core/core/rpc-interface"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+	"math/big"
+	"os"
+	"testing"
+)
+
+func Test_rpcCaller_GetMineWorkers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := getRpcTestApp()
+	app.Action = func(context *cli.Context) {
+		client = NewMockRpcClient(ctrl)
+		c := &rpcCaller{}
+		c.GetMineWorkers(context)
+
+		wrapRpcArgs(context, "GetMineWorkers", "")
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any()).Return(errors.New("test"))
+		c.GetMineWorkers(context)
+
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any()).DoAndReturn(func(result interface{}, method string, args ...interface{}) error {
+			*result.(*[]*rpc_interface.MineWorkerResp) = []*rpc_interface.MineWorkerResp{{
+				WorkerId:      "stratum-1",
+				Coinbase:      common.HexToAddress(testLockAddr1),
+				HashRate:      1e6,
+				Accepted:      10,
+				PendingReward: (*hexutil.Big)(big.NewInt(1e9)),
+			}}
+			return nil
+		})
+		c.GetMineWorkers(context)
+	}
+	assert.NoError(t, app.Run([]string{os.Args[0]}))
+}
+
+func Test_rpcCaller_KickMineWorker(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := getRpcTestApp()
+	app.Action = func(context *cli.Context) {
+		client = NewMockRpcClient(ctrl)
+		c := &rpcCaller{}
+		c.KickMineWorker(context)
+
+		wrapRpcArgs(context, "KickMineWorker", "")
+		c.KickMineWorker(context)
+
+		wrapRpcArgs(context, "KickMineWorker", "stratum-1")
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("test"))
+		c.KickMineWorker(context)
+
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		c.KickMineWorker(context)
+	}
+	assert.NoError(t, app.Run([]string{os.Args[0]}))
+}
+
+func Test_rpcCaller_SetMineWorkerCoinbase(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := getRpcTestApp()
+	app.Action = func(context *cli.Context) {
+		client = NewMockRpcClient(ctrl)
+		c := &rpcCaller{}
+		c.SetMineWorkerCoinbase(context)
+
+		wrapRpcArgs(context, "SetMineWorkerCoinbase", "stratum-1")
+		c.SetMineWorkerCoinbase(context)
+
+		wrapRpcArgs(context, "SetMineWorkerCoinbase", "stratum-1,a")
+		c.SetMineWorkerCoinbase(context)
+
+		wrapRpcArgs(context, "SetMineWorkerCoinbase", "stratum-1,"+testLockAddr1)
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("test"))
+		c.SetMineWorkerCoinbase(context)
+
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		c.SetMineWorkerCoinbase(context)
+	}
+	assert.NoError(t, app.Run([]string{os.Args[0]}))
+}
@@ -0,0 +1,264 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/hexutil"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/core/rpc-interface"
+	"github.com/urfave/cli"
+	"strconv"
+)
+
+// parse multiSig,nonce,to,value of the proposal
+func getMultiSigProposal(params []string) (*model.MultiSigProposal, error) {
+	multiSig, err := CheckAndChangeHexToAddress(params[0])
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := strconv.ParseUint(params[1], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	to, err := CheckAndChangeHexToAddress(params[2])
+	if err != nil {
+		return nil, err
+	}
+	value, err := MoneyValueToCSCoin(params[3])
+	if err != nil {
+		return nil, err
+	}
+	return &model.MultiSigProposal{MultiSig: multiSig, Nonce: nonce, To: to, Amount: value}, nil
+}
+
+func (caller *rpcCaller) GetMultiSigAccount(c *cli.Context) {
+	mName, cParams, err := getRpcMethodAndParam(c)
+	if err != nil {
+		l.Error("getRpcMethodAndParam error", "err", err)
+		return
+	}
+
+	if len(cParams) != 1 {
+		l.Error("GetMultiSigAccount need：multiSigAddress")
+		return
+	}
+
+	multiSig, err := CheckAndChangeHexToAddress(cParams[0])
+	if err != nil {
+		l.Error("the multiSig address is invalid", "err", err)
+		return
+	}
+
+	var resp rpc_interface.MultiSigAccountResp
+	if err := client.Call(&resp, getDipperinRpcMethodByName(mName), multiSig); err != nil {
+		l.Error("call get multi signature account", "err", err)
+		return
+	}
+	balance, _ := CSCoinToMoneyValue(resp.Balance)
+	l.Info("GetMultiSigAccount result", "threshold", resp.Threshold, "owners", resp.Owners, "nonce", resp.Nonce, "balance", balance)
+}
+
+// register the multi signature account of the owners, the owners are the params after the transactionFee
+func (caller *rpcCaller) SendRegisterMultiSigTransaction(c *cli.Context) {
+	if checkSync() {
+		return
+	}
+
+	mName, cParams, err := getRpcMethodAndParam(c)
+	if err != nil {
+		l.Error("getRpcMethodAndParam error", "err", err)
+		return
+	}
+
+	if len(cParams) < 5 {
+		l.Error("SendRegisterMultiSigTransaction need：from threshold value transactionFee owner1 [owner2...]")
+		return
+	}
+
+	from, err := CheckAndChangeHexToAddress(cParams[0])
+	if err != nil {
+		l.Error("the from address is invalid", "err", err)
+		return
+	}
+
+	threshold, err := strconv.ParseUint(cParams[1], 10, 64)
+	if err != nil {
+		l.Error("the threshold is invalid", "err", err)
+		return
+	}
+
+	value, err := MoneyValueToCSCoin(cParams[2])
+	if err != nil {
+		l.Error("the parameter value invalid")
+		return
+	}
+
+	txFee, err := MoneyValueToCSCoin(cParams[3])
+	if err != nil {
+		l.Error("the parameter transactionFee invalid")
+		return
+	}
+
+	var owners []common.Address
+	for _, param := range cParams[4:] {
+		owner, err := CheckAndChangeHexToAddress(param)
+		if err != nil {
+			l.Error("the owner address is invalid", "err", err)
+			return
+		}
+		owners = append(owners, owner)
+	}
+
+	keySet := &model.MultiSigKeySet{Threshold: threshold, Owners: owners}
+	if err := keySet.Valid(); err != nil {
+		l.Error("the owners are invalid", "err", err)
+		return
+	}
+
+	var resp common.Hash
+	if err := client.Call(&resp, getDipperinRpcMethodByName(mName), from, threshold, owners, value, txFee, nil); err != nil {
+		l.Error("call send multi signature register transaction", "err", err)
+		return
+	}
+	l.Info("SendRegisterMultiSigTransaction result", "txId", resp.Hex(), "multiSigAddress", keySet.Address().Hex())
+}
+
+// create the proposal with the current nonce of the multi signature account
+func (caller *rpcCaller) NewMultiSigProposal(c *cli.Context) {
+	mName, cParams, err := getRpcMethodAndParam(c)
+	if err != nil {
+		l.Error("getRpcMethodAndParam error", "err", err)
+		return
+	}
+
+	if len(cParams) != 3 {
+		l.Error("NewMultiSigProposal need：multiSigAddress to value")
+		return
+	}
+
+	multiSig, err := CheckAndChangeHexToAddress(cParams[0])
+	if err != nil {
+		l.Error("the multiSig address is invalid", "err", err)
+		return
+	}
+
+	to, err := CheckAndChangeHexToAddress(cParams[1])
+	if err != nil {
+		l.Error("the to address is invalid", "err", err)
+		return
+	}
+
+	value, err := MoneyValueToCSCoin(cParams[2])
+	if err != nil {
+		l.Error("the parameter value invalid")
+		return
+	}
+
+	var resp rpc_interface.MultiSigProposalResp
+	if err := client.Call(&resp, getDipperinRpcMethodByName(mName), multiSig, to, value); err != nil {
+		l.Error("call new multi signature proposal", "err", err)
+		return
+	}
+	l.Info("NewMultiSigProposal result", "multiSig", resp.Proposal.MultiSig.Hex(), "nonce", resp.Proposal.Nonce, "to", resp.Proposal.To.Hex(), "value", cParams[2], "hash", resp.Hash.Hex())
+}
+
+// sign the proposal with the owner account in the wallet
+func (caller *rpcCaller) SignMultiSigProposal(c *cli.Context) {
+	mName, cParams, err := getRpcMethodAndParam(c)
+	if err != nil {
+		l.Error("getRpcMethodAndParam error", "err", err)
+		return
+	}
+
+	if len(cParams) != 5 {
+		l.Error("SignMultiSigProposal need：signer multiSigAddress nonce to value")
+		return
+	}
+
+	signer, err := CheckAndChangeHexToAddress(cParams[0])
+	if err != nil {
+		l.Error("the signer address is invalid", "err", err)
+		return
+	}
+
+	proposal, err := getMultiSigProposal(cParams[1:])
+	if err != nil {
+		l.Error("the proposal is invalid", "err", err)
+		return
+	}
+
+	var resp hexutil.Bytes
+	if err := client.Call(&resp, getDipperinRpcMethodByName(mName), signer, proposal); err != nil {
+		l.Error("call sign multi signature proposal", "err", err)
+		return
+	}
+	l.Info("SignMultiSigProposal result", "signature", resp.String())
+}
+
+// submit the proposal, the owners signatures are the params after the transactionFee
+func (caller *rpcCaller) SendMultiSigTransaction(c *cli.Context) {
+	if checkSync() {
+		return
+	}
+
+	mName, cParams, err := getRpcMethodAndParam(c)
+	if err != nil {
+		l.Error("getRpcMethodAndParam error", "err", err)
+		return
+	}
+
+	if len(cParams) < 7 {
+		l.Error("SendMultiSigTransaction need：from multiSigAddress nonce to value transactionFee signature1 [signature2...]")
+		return
+	}
+
+	from, err := CheckAndChangeHexToAddress(cParams[0])
+	if err != nil {
+		l.Error("the from address is invalid", "err", err)
+		return
+	}
+
+	proposal, err := getMultiSigProposal(cParams[1:5])
+	if err != nil {
+		l.Error("the proposal is invalid", "err", err)
+		return
+	}
+
+	txFee, err := MoneyValueToCSCoin(cParams[5])
+	if err != nil {
+		l.Error("the parameter transactionFee invalid")
+		return
+	}
+
+	var signatures []hexutil.Bytes
+	for _, param := range cParams[6:] {
+		sig, err := hexutil.Decode(param)
+		if err != nil {
+			l.Error("the signature is invalid", "err", err)
+			return
+		}
+		signatures = append(signatures, sig)
+	}
+
+	var resp common.Hash
+	if err := client.Call(&resp, getDipperinRpcMethodByName(mName), from, proposal, signatures, txFee, nil); err != nil {
+		l.Error("call send multi signature transaction", "err", err)
+		return
+	}
+	l.Info("SendMultiSigTransaction result", "txId", resp.Hex())
+}
@@ -0,0 +1,204 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+	"os"
+	"testing"
+)
+
+const testMultiSigAddr = "0x000634A7DFe82E1c6C4bB5dB5b4F2b5C8C0E05e2B2Bb"
+
+func Test_rpcCaller_GetMultiSigAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := getRpcTestApp()
+	app.Action = func(context *cli.Context) {
+		client = NewMockRpcClient(ctrl)
+		c := &rpcCaller{}
+		c.GetMultiSigAccount(context)
+
+		wrapRpcArgs(context, "GetMultiSigAccount", "")
+		c.GetMultiSigAccount(context)
+
+		wrapRpcArgs(context, "GetMultiSigAccount", "a")
+		c.GetMultiSigAccount(context)
+
+		wrapRpcArgs(context, "GetMultiSigAccount", testMultiSigAddr)
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("test"))
+		c.GetMultiSigAccount(context)
+
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		c.GetMultiSigAccount(context)
+	}
+	assert.NoError(t, app.Run([]string{os.Args[0]}))
+}
+
+func Test_rpcCaller_SendRegisterMultiSigTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := getRpcTestApp()
+	app.Action = func(context *cli.Context) {
+		client = NewMockRpcClient(ctrl)
+		c := &rpcCaller{}
+		SyncStatus.Store(true)
+		c.SendRegisterMultiSigTransaction(context)
+
+		wrapRpcArgs(context, "SendRegisterMultiSigTransaction", "")
+		c.SendRegisterMultiSigTransaction(context)
+
+		wrapRpcArgs(context, "SendRegisterMultiSigTransaction", "a,b,c,d,e")
+		c.SendRegisterMultiSigTransaction(context)
+
+		wrapRpcArgs(context, "SendRegisterMultiSigTransaction", testLockAddr1+",b,c,d,e")
+		c.SendRegisterMultiSigTransaction(context)
+
+		wrapRpcArgs(context, "SendRegisterMultiSigTransaction", testLockAddr1+",2,c,d,e")
+		c.SendRegisterMultiSigTransaction(context)
+
+		wrapRpcArgs(context, "SendRegisterMultiSigTransaction", testLockAddr1+",2,100,d,e")
+		c.SendRegisterMultiSigTransaction(context)
+
+		wrapRpcArgs(context, "SendRegisterMultiSigTransaction", testLockAddr1+",2,100,1,e")
+		c.SendRegisterMultiSigTransaction(context)
+
+		// threshold greater than the owners
+		wrapRpcArgs(context, "SendRegisterMultiSigTransaction", testLockAddr1+",2,100,1,"+testLockAddr1)
+		c.SendRegisterMultiSigTransaction(context)
+
+		wrapRpcArgs(context, "SendRegisterMultiSigTransaction", testLockAddr1+",2,100,1,"+testLockAddr1+","+testLockAddr2)
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("test"))
+		c.SendRegisterMultiSigTransaction(context)
+
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		c.SendRegisterMultiSigTransaction(context)
+	}
+	assert.NoError(t, app.Run([]string{os.Args[0]}))
+}
+
+func Test_rpcCaller_NewMultiSigProposal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := getRpcTestApp()
+	app.Action = func(context *cli.Context) {
+		client = NewMockRpcClient(ctrl)
+		c := &rpcCaller{}
+		c.NewMultiSigProposal(context)
+
+		wrapRpcArgs(context, "NewMultiSigProposal", "")
+		c.NewMultiSigProposal(context)
+
+		wrapRpcArgs(context, "NewMultiSigProposal", "a,b,c")
+		c.NewMultiSigProposal(context)
+
+		wrapRpcArgs(context, "NewMultiSigProposal", testMultiSigAddr+",b,c")
+		c.NewMultiSigProposal(context)
+
+		wrapRpcArgs(context, "NewMultiSigProposal", testMultiSigAddr+","+testLockAddr1+",c")
+		c.NewMultiSigProposal(context)
+
+		wrapRpcArgs(context, "NewMultiSigProposal", testMultiSigAddr+","+testLockAddr1+",10")
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("test"))
+		c.NewMultiSigProposal(context)
+
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		c.NewMultiSigProposal(context)
+	}
+	assert.NoError(t, app.Run([]string{os.Args[0]}))
+}
+
+func Test_rpcCaller_SignMultiSigProposal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := getRpcTestApp()
+	app.Action = func(context *cli.Context) {
+		client = NewMockRpcClient(ctrl)
+		c := &rpcCaller{}
+		c.SignMultiSigProposal(context)
+
+		wrapRpcArgs(context, "SignMultiSigProposal", "")
+		c.SignMultiSigProposal(context)
+
+		wrapRpcArgs(context, "SignMultiSigProposal", "a,b,c,d,e")
+		c.SignMultiSigProposal(context)
+
+		wrapRpcArgs(context, "SignMultiSigProposal", testLockAddr1+",b,c,d,e")
+		c.SignMultiSigProposal(context)
+
+		wrapRpcArgs(context, "SignMultiSigProposal", testLockAddr1+","+testMultiSigAddr+",c,d,e")
+		c.SignMultiSigProposal(context)
+
+		wrapRpcArgs(context, "SignMultiSigProposal", testLockAddr1+","+testMultiSigAddr+",0,d,e")
+		c.SignMultiSigProposal(context)
+
+		wrapRpcArgs(context, "SignMultiSigProposal", testLockAddr1+","+testMultiSigAddr+",0,"+testLockAddr2+",e")
+		c.SignMultiSigProposal(context)
+
+		wrapRpcArgs(context, "SignMultiSigProposal", testLockAddr1+","+testMultiSigAddr+",0,"+testLockAddr2+",10")
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("test"))
+		c.SignMultiSigProposal(context)
+
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		c.SignMultiSigProposal(context)
+	}
+	assert.NoError(t, app.Run([]string{os.Args[0]}))
+}
+
+func Test_rpcCaller_SendMultiSigTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := getRpcTestApp()
+	app.Action = func(context *cli.Context) {
+		client = NewMockRpcClient(ctrl)
+		c := &rpcCaller{}
+		SyncStatus.Store(true)
+		c.SendMultiSigTransaction(context)
+
+		wrapRpcArgs(context, "SendMultiSigTransaction", "")
+		c.SendMultiSigTransaction(context)
+
+		wrapRpcArgs(context, "SendMultiSigTransaction", "a,b,c,d,e,f,g")
+		c.SendMultiSigTransaction(context)
+
+		wrapRpcArgs(context, "SendMultiSigTransaction", testLockAddr1+",b,c,d,e,f,g")
+		c.SendMultiSigTransaction(context)
+
+		proposal := testMultiSigAddr + ",0," + testLockAddr2 + ",10"
+		wrapRpcArgs(context, "SendMultiSigTransaction", testLockAddr1+","+proposal+",f,g")
+		c.SendMultiSigTransaction(context)
+
+		wrapRpcArgs(context, "SendMultiSigTransaction", testLockAddr1+","+proposal+",1,g")
+		c.SendMultiSigTransaction(context)
+
+		wrapRpcArgs(context, "SendMultiSigTransaction", testLockAddr1+","+proposal+",1,0x1234,0x5678")
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("test"))
+		c.SendMultiSigTransaction(context)
+
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		c.SendMultiSigTransaction(context)
+	}
+	assert.NoError(t, app.Run([]string{os.Args[0]}))
+}
@@ -0,0 +1,283 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/hexutil"
+	"github.com/dipperin/dipperin-core/common/util"
+	"github.com/dipperin/dipperin-core/core/accounts/soft-wallet"
+	"github.com/dipperin/dipperin-core/core/chain-config"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/third-party/rpc"
+	"github.com/urfave/cli"
+	"io/ioutil"
+	"math/big"
+	"strings"
+)
+
+var (
+	ErrSignKeyNotSet   = errors.New("need one of the wallet and the mnemonic_file to sign the tx")
+	ErrTxSenderInvalid = errors.New("the signed tx sender isn't the from address")
+)
+
+// unsigned tx written by "tx build" and read by "tx sign", it's plain json so the air-gapped signer can review it
+type UnsignedTx struct {
+	From      common.Address `json:"from"`
+	ChainId   *hexutil.Big   `json:"chain_id"`
+	Nonce     hexutil.Uint64 `json:"nonce"`
+	To        common.Address `json:"to"`
+	Value     *hexutil.Big   `json:"value"`
+	Fee       *hexutil.Big   `json:"fee"`
+	ExtraData hexutil.Bytes  `json:"extra_data"`
+}
+
+func (tx *UnsignedTx) Transaction() *model.Transaction {
+	return model.NewTransaction(uint64(tx.Nonce), tx.To, tx.Value.ToInt(), tx.Fee.ToInt(), tx.ExtraData)
+}
+
+// sign the tx with the private key of the from address, the tx is signed for the chain id in the file
+func (tx *UnsignedTx) Sign(key *ecdsa.PrivateKey) (*model.Transaction, error) {
+	signedTx, err := tx.Transaction().SignTx(key, model.NewMercurySigner(tx.ChainId.ToInt()))
+	if err != nil {
+		return nil, err
+	}
+
+	sender, err := signedTx.Sender(nil)
+	if err != nil {
+		return nil, err
+	}
+	if !sender.IsEqual(tx.From) {
+		return nil, ErrTxSenderInvalid
+	}
+	return signedTx, nil
+}
+
+var offlineTxCommand = cli.Command{
+	Name:  "tx",
+	Usage: "build an unsigned tx, sign it offline and send the signed tx",
+	Subcommands: []cli.Command{
+		{
+			Name:  "build",
+			Usage: "write an unsigned tx to the file, the nonce is queried from the node if not set",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "from", Usage: "the sender address"},
+				cli.StringFlag{Name: "to", Usage: "the receiver address"},
+				cli.StringFlag{Name: "value", Usage: "the value in DIP"},
+				cli.StringFlag{Name: "fee", Usage: "the transaction fee in DIP"},
+				cli.Uint64Flag{Name: "nonce", Usage: "the nonce of the sender"},
+				cli.StringFlag{Name: "extra_data", Usage: "the extra data"},
+				cli.Uint64Flag{Name: "chain_id", Usage: "the chain id, the default chain id if not set"},
+				cli.StringFlag{Name: "out", Usage: "the unsigned tx file"},
+			},
+			Action: func(c *cli.Context) error {
+				BuildUnsignedTx(c)
+				return nil
+			},
+		},
+		{
+			Name:  "sign",
+			Usage: "sign the unsigned tx file with the soft wallet file or the mnemonic, no node is needed",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "in", Usage: "the unsigned tx file"},
+				cli.StringFlag{Name: "out", Usage: "the signed tx file"},
+				cli.StringFlag{Name: "wallet", Usage: "the soft wallet file"},
+				cli.StringFlag{Name: "password", Usage: "the soft wallet password"},
+				cli.StringFlag{Name: "mnemonic_file", Usage: "the file holding the wallet mnemonic"},
+				cli.StringFlag{Name: "passphrase", Usage: "the mnemonic passphrase"},
+			},
+			Action: func(c *cli.Context) error {
+				SignUnsignedTx(c)
+				return nil
+			},
+		},
+		{
+			Name:  "send",
+			Usage: "send the signed tx file to the node",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "in", Usage: "the signed tx file"},
+				cli.IntFlag{Name: "ws_port", Usage: "the websocket port of the node, used when not running in the console"},
+			},
+			Action: func(c *cli.Context) error {
+				SendSignedTx(c)
+				return nil
+			},
+		},
+	},
+}
+
+func BuildUnsignedTx(c *cli.Context) {
+	from, err := CheckAndChangeHexToAddress(c.String("from"))
+	if err != nil {
+		l.Error("the from address is invalid", "err", err)
+		return
+	}
+	to, err := CheckAndChangeHexToAddress(c.String("to"))
+	if err != nil {
+		l.Error("the to address is invalid", "err", err)
+		return
+	}
+	value, err := MoneyValueToCSCoin(c.String("value"))
+	if err != nil {
+		l.Error("the parameter value invalid", "err", err)
+		return
+	}
+	fee, err := MoneyValueToCSCoin(c.String("fee"))
+	if err != nil {
+		l.Error("the parameter fee invalid", "err", err)
+		return
+	}
+	if c.String("out") == "" {
+		l.Error("tx build need：out")
+		return
+	}
+
+	nonce := c.Uint64("nonce")
+	if !c.IsSet("nonce") {
+		if client == nil {
+			l.Error("the nonce isn't set and the node isn't connected")
+			return
+		}
+		if err = client.Call(&nonce, getDipperinRpcMethodByName("GetTransactionNonce"), from); err != nil {
+			l.Error("call GetTransactionNonce", "err", err)
+			return
+		}
+	}
+
+	chainId := chain_config.GetChainConfig().ChainId
+	if c.IsSet("chain_id") {
+		chainId = new(big.Int).SetUint64(c.Uint64("chain_id"))
+	}
+
+	tx := &UnsignedTx{
+		From:      from,
+		ChainId:   (*hexutil.Big)(chainId),
+		Nonce:     hexutil.Uint64(nonce),
+		To:        to,
+		Value:     (*hexutil.Big)(value),
+		Fee:       (*hexutil.Big)(fee),
+		ExtraData: []byte(c.String("extra_data")),
+	}
+	if err = ioutil.WriteFile(c.String("out"), util.StringifyJsonToBytes(tx), 0644); err != nil {
+		l.Error("write unsigned tx error", "err", err)
+		return
+	}
+	l.Info("tx build result", "from", from.Hex(), "to", to.Hex(), "nonce", nonce, "out", c.String("out"))
+}
+
+// get the private key of the address from the wallet file or the mnemonic file
+func getOfflineSignKey(c *cli.Context, address common.Address) (*ecdsa.PrivateKey, error) {
+	if c.String("mnemonic_file") != "" {
+		mnemonic, err := ioutil.ReadFile(c.String("mnemonic_file"))
+		if err != nil {
+			return nil, err
+		}
+		return soft_wallet.GetSKFromMnemonic(strings.TrimSpace(string(mnemonic)), c.String("passphrase"), address)
+	}
+
+	if c.String("wallet") == "" {
+		return nil, ErrSignKeyNotSet
+	}
+	wallet, err := soft_wallet.NewSoftWallet()
+	if err != nil {
+		return nil, err
+	}
+	path, name := ParseWalletPathAndName(c.String("wallet"))
+	if err = wallet.Open(path, name, c.String("password")); err != nil {
+		return nil, err
+	}
+	return wallet.GetSKFromAddress(address)
+}
+
+func SignUnsignedTx(c *cli.Context) {
+	if c.String("in") == "" || c.String("out") == "" {
+		l.Error("tx sign need：in out")
+		return
+	}
+
+	data, err := ioutil.ReadFile(c.String("in"))
+	if err != nil {
+		l.Error("read unsigned tx error", "err", err)
+		return
+	}
+	var tx UnsignedTx
+	if err = util.ParseJsonFromBytes(data, &tx); err != nil {
+		l.Error("the unsigned tx is invalid", "err", err)
+		return
+	}
+	if tx.ChainId == nil || tx.Value == nil || tx.Fee == nil {
+		l.Error("the unsigned tx is incomplete")
+		return
+	}
+
+	key, err := getOfflineSignKey(c, tx.From)
+	if err != nil {
+		l.Error("get the sign key error", "err", err)
+		return
+	}
+	signedTx, err := tx.Sign(key)
+	if err != nil {
+		l.Error("sign tx error", "err", err)
+		return
+	}
+
+	txRlp, err := signedTx.EncodeRlpToBytes()
+	if err != nil {
+		l.Error("encode signed tx error", "err", err)
+		return
+	}
+	if err = ioutil.WriteFile(c.String("out"), []byte(hexutil.Encode(txRlp)), 0644); err != nil {
+		l.Error("write signed tx error", "err", err)
+		return
+	}
+	l.Info("tx sign result", "txId", signedTx.CalTxId().Hex(), "out", c.String("out"))
+}
+
+func SendSignedTx(c *cli.Context) {
+	if c.String("in") == "" {
+		l.Error("tx send need：in")
+		return
+	}
+	data, err := ioutil.ReadFile(c.String("in"))
+	if err != nil {
+		l.Error("read signed tx error", "err", err)
+		return
+	}
+	txRlp, err := hexutil.Decode(strings.TrimSpace(string(data)))
+	if err != nil {
+		l.Error("the signed tx is invalid", "err", err)
+		return
+	}
+
+	if client == nil {
+		if client, err = rpc.Dial(fmt.Sprintf("ws://%v:%d", "127.0.0.1", c.Int("ws_port"))); err != nil {
+			client = nil
+			l.Error("connect the node error", "err", err)
+			return
+		}
+	}
+
+	var resp common.Hash
+	if err = client.Call(&resp, getDipperinRpcMethodByName("NewTransaction"), txRlp); err != nil {
+		l.Error("call NewTransaction", "err", err)
+		return
+	}
+	l.Info("tx send result", "txId", resp.Hex())
+}
@@ -0,0 +1,156 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/hexutil"
+	"github.com/dipperin/dipperin-core/common/util"
+	"github.com/dipperin/dipperin-core/core/accounts/soft-wallet"
+	"github.com/dipperin/dipperin-core/core/chain-config"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func runOfflineTxCommand(args ...string) {
+	app := cli.NewApp()
+	app.Commands = []cli.Command{offlineTxCommand}
+	app.Run(append([]string{"xxx", "tx"}, args...))
+}
+
+func readSignedTx(t *testing.T, path string) *model.Transaction {
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	txRlp, err := hexutil.Decode(string(data))
+	assert.NoError(t, err)
+	var tx model.Transaction
+	assert.NoError(t, rlp.DecodeBytes(txRlp, &tx))
+	return &tx
+}
+
+func TestOfflineTx(t *testing.T) {
+	dir, err := ioutil.TempDir(util.HomeDir(), "offline_tx")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	wallet, err := soft_wallet.NewSoftWallet()
+	assert.NoError(t, err)
+	walletPath := filepath.Join(dir, "wallet")
+	mnemonic, err := wallet.Establish(walletPath, "wallet", "12345678", "passphrase")
+	assert.NoError(t, err)
+	walletAccounts, err := wallet.Accounts()
+	assert.NoError(t, err)
+	from := walletAccounts[0].Address
+	to := common.HexToAddress("0x0000b4293d60F051936beDecfaE1B85d5A46d377aF37")
+	mnemonicPath := filepath.Join(dir, "mnemonic")
+	assert.NoError(t, ioutil.WriteFile(mnemonicPath, []byte(mnemonic+"\n"), 0600))
+
+	unsignedPath := filepath.Join(dir, "unsigned.json")
+	runOfflineTxCommand("build", "--from", from.Hex(), "--to", to.Hex(), "--value", "10", "--fee", "0.00001", "--nonce", "3", "--extra_data", "test", "--out", unsignedPath)
+	var unsignedTx UnsignedTx
+	data, err := ioutil.ReadFile(unsignedPath)
+	assert.NoError(t, err)
+	assert.NoError(t, util.ParseJsonFromBytes(data, &unsignedTx))
+	assert.Equal(t, from, unsignedTx.From)
+	assert.Equal(t, uint64(3), uint64(unsignedTx.Nonce))
+	assert.Equal(t, chain_config.GetChainConfig().ChainId, unsignedTx.ChainId.ToInt())
+	assert.Equal(t, []byte("test"), []byte(unsignedTx.ExtraData))
+
+	// sign with the mnemonic
+	signedPath := filepath.Join(dir, "signed")
+	runOfflineTxCommand("sign", "--in", unsignedPath, "--out", signedPath, "--mnemonic_file", mnemonicPath, "--passphrase", "passphrase")
+	signedTx := readSignedTx(t, signedPath)
+	sender, err := signedTx.Sender(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, from, sender)
+	assert.Equal(t, to, *signedTx.To())
+	assert.Equal(t, uint64(3), signedTx.Nonce())
+	assert.Equal(t, unsignedTx.Value.ToInt(), signedTx.Amount())
+
+	// sign with the wallet file
+	walletSignedPath := filepath.Join(dir, "wallet_signed")
+	runOfflineTxCommand("sign", "--in", unsignedPath, "--out", walletSignedPath, "--wallet", walletPath, "--password", "12345678")
+	assert.Equal(t, signedTx.CalTxId(), readSignedTx(t, walletSignedPath).CalTxId())
+
+	// the wrong password, passphrase or missing key leave no signed tx
+	for i, args := range [][]string{
+		{"--wallet", walletPath, "--password", "87654321"},
+		{"--mnemonic_file", mnemonicPath},
+		{},
+	} {
+		out := filepath.Join(dir, fmt.Sprintf("failed%d", i))
+		runOfflineTxCommand(append([]string{"sign", "--in", unsignedPath, "--out", out}, args...)...)
+		_, err = os.Stat(out)
+		assert.True(t, os.IsNotExist(err))
+	}
+
+	// send the signed tx
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client = NewMockRpcClient(ctrl)
+	client.(*MockRpcClient).EXPECT().Call(gomock.Any(), "Dipperin_newTransaction", gomock.Any()).DoAndReturn(func(result interface{}, method string, args ...interface{}) error {
+		var tx model.Transaction
+		assert.NoError(t, rlp.DecodeBytes(args[0].([]byte), &tx))
+		*result.(*common.Hash) = tx.CalTxId()
+		return nil
+	})
+	runOfflineTxCommand("send", "--in", signedPath)
+	client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("test"))
+	runOfflineTxCommand("send", "--in", signedPath)
+	runOfflineTxCommand("send", "--in", unsignedPath)
+	runOfflineTxCommand("send")
+
+	// the nonce is queried from the node when not set
+	client.(*MockRpcClient).EXPECT().Call(gomock.Any(), "Dipperin_getTransactionNonce", from).DoAndReturn(func(result interface{}, method string, args ...interface{}) error {
+		*result.(*uint64) = 5
+		return nil
+	})
+	runOfflineTxCommand("build", "--from", from.Hex(), "--to", to.Hex(), "--value", "10", "--fee", "0.00001", "--chain_id", "2", "--out", unsignedPath)
+	data, err = ioutil.ReadFile(unsignedPath)
+	assert.NoError(t, err)
+	assert.NoError(t, util.ParseJsonFromBytes(data, &unsignedTx))
+	assert.Equal(t, uint64(5), uint64(unsignedTx.Nonce))
+	assert.Equal(t, big.NewInt(2), unsignedTx.ChainId.ToInt())
+	client = nil
+
+	// the nonce can't be queried without the node
+	runOfflineTxCommand("build", "--from", from.Hex(), "--to", to.Hex(), "--value", "10", "--fee", "0.00001", "--out", filepath.Join(dir, "no_nonce"))
+	_, err = os.Stat(filepath.Join(dir, "no_nonce"))
+	assert.True(t, os.IsNotExist(err))
+	runOfflineTxCommand("build", "--from", "test")
+	runOfflineTxCommand("build", "--from", from.Hex(), "--to", "test")
+	runOfflineTxCommand("build", "--from", from.Hex(), "--to", to.Hex(), "--value", "test")
+	runOfflineTxCommand("build", "--from", from.Hex(), "--to", to.Hex(), "--value", "10", "--fee", "test")
+	runOfflineTxCommand("build", "--from", from.Hex(), "--to", to.Hex(), "--value", "10", "--fee", "1")
+}
+
+func TestUnsignedTx_Sign(t *testing.T) {
+	key, _ := model.CreateKey()
+	tx := &UnsignedTx{ChainId: (*hexutil.Big)(big.NewInt(1)), Value: (*hexutil.Big)(big.NewInt(1)), Fee: (*hexutil.Big)(big.NewInt(1))}
+	_, err := tx.Sign(key)
+	assert.Equal(t, ErrTxSenderInvalid, err)
+}
@@ -0,0 +1,109 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/core/rpc-interface"
+	"github.com/urfave/cli"
+	"strconv"
+)
+
+func (caller *rpcCaller) GetPoolContent(c *cli.Context) {
+	mName, cParams, err := getRpcMethodAndParam(c)
+	if err != nil {
+		l.Error("getRpcMethodAndParam error", "err", err)
+		return
+	}
+
+	if len(cParams) != 1 {
+		l.Error("GetPoolContent need：address")
+		return
+	}
+
+	address, err := CheckAndChangeHexToAddress(cParams[0])
+	if err != nil {
+		l.Error("the address is invalid", "err", err)
+		return
+	}
+
+	var resp rpc_interface.PoolContentResp
+	if err := client.Call(&resp, getDipperinRpcMethodByName(mName), address); err != nil {
+		l.Error("call get pool content", "err", err)
+		return
+	}
+	for _, p := range resp.Pending {
+		l.Info("pending transaction", "txId", p.Transaction.CalTxId().Hex(), "nonce", p.Transaction.Nonce(), "fee", p.Transaction.Fee())
+	}
+	for _, q := range resp.Queued {
+		l.Info("queued transaction", "txId", q.Transaction.CalTxId().Hex(), "nonce", q.Transaction.Nonce(), "fee", q.Transaction.Fee(), "reason", q.Reason)
+	}
+	l.Info("GetPoolContent result", "pending", len(resp.Pending), "queued", len(resp.Queued))
+}
+
+// ReplaceTransaction and CancelPoolTransaction both take from,nonce,transactionFee
+func (caller *rpcCaller) sendPoolReplacement(c *cli.Context) {
+	if checkSync() {
+		return
+	}
+
+	mName, cParams, err := getRpcMethodAndParam(c)
+	if err != nil {
+		l.Error("getRpcMethodAndParam error", "err", err)
+		return
+	}
+
+	if len(cParams) != 3 {
+		l.Error(mName + " need：from nonce transactionFee")
+		return
+	}
+
+	from, err := CheckAndChangeHexToAddress(cParams[0])
+	if err != nil {
+		l.Error("the from address is invalid", "err", err)
+		return
+	}
+
+	nonce, err := strconv.ParseUint(cParams[1], 10, 64)
+	if err != nil {
+		l.Error("the nonce is invalid", "err", err)
+		return
+	}
+
+	txFee, err := MoneyValueToCSCoin(cParams[2])
+	if err != nil {
+		l.Error("the parameter transactionFee invalid")
+		return
+	}
+
+	var resp common.Hash
+	if err := client.Call(&resp, getDipperinRpcMethodByName(mName), from, nonce, txFee); err != nil {
+		l.Error("call "+mName, "err", err)
+		return
+	}
+	l.Info(mName+" result", "txId", resp.Hex())
+}
+
+// replace the tx pool transaction of the nonce with a higher fee
+func (caller *rpcCaller) ReplaceTransaction(c *cli.Context) {
+	caller.sendPoolReplacement(c)
+}
+
+// cancel the tx pool transaction of the nonce with a higher fee
+func (caller *rpcCaller) CancelPoolTransaction(c *cli.Context) {
+	caller.sendPoolReplacement(c)
+}
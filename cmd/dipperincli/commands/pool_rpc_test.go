@@ -0,0 +1,115 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"errors"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/core/rpc-interface"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+	"math/big"
+	"os"
+	"testing"
+)
+
+func Test_rpcCaller_GetPoolContent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := getRpcTestApp()
+	app.Action = func(context *cli.Context) {
+		client = NewMockRpcClient(ctrl)
+		c := &rpcCaller{}
+		c.GetPoolContent(context)
+
+		wrapRpcArgs(context, "GetPoolContent", "")
+		c.GetPoolContent(context)
+
+		wrapRpcArgs(context, "GetPoolContent", "a")
+		c.GetPoolContent(context)
+
+		wrapRpcArgs(context, "GetPoolContent", testLockAddr1)
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("test"))
+		c.GetPoolContent(context)
+
+		tx := model.NewTransaction(1, common.HexToAddress(testLockAddr1), big.NewInt(1), big.NewInt(1), nil)
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(result interface{}, method string, args ...interface{}) error {
+			*result.(*rpc_interface.PoolContentResp) = rpc_interface.PoolContentResp{
+				Pending: []*rpc_interface.PoolTxResp{{Transaction: tx}},
+				Queued:  []*rpc_interface.PoolTxResp{{Transaction: tx, Reason: "nonce gap"}},
+			}
+			return nil
+		})
+		c.GetPoolContent(context)
+	}
+	assert.NoError(t, app.Run([]string{os.Args[0]}))
+}
+
+func Test_rpcCaller_ReplaceTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := getRpcTestApp()
+	app.Action = func(context *cli.Context) {
+		client = NewMockRpcClient(ctrl)
+		c := &rpcCaller{}
+		SyncStatus.Store(true)
+		c.ReplaceTransaction(context)
+
+		wrapRpcArgs(context, "ReplaceTransaction", "")
+		c.ReplaceTransaction(context)
+
+		wrapRpcArgs(context, "ReplaceTransaction", "a,b,c")
+		c.ReplaceTransaction(context)
+
+		wrapRpcArgs(context, "ReplaceTransaction", testLockAddr1+",b,c")
+		c.ReplaceTransaction(context)
+
+		wrapRpcArgs(context, "ReplaceTransaction", testLockAddr1+",1,c")
+		c.ReplaceTransaction(context)
+
+		wrapRpcArgs(context, "ReplaceTransaction", testLockAddr1+",1,1")
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("test"))
+		c.ReplaceTransaction(context)
+
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		c.ReplaceTransaction(context)
+	}
+	assert.NoError(t, app.Run([]string{os.Args[0]}))
+}
+
+func Test_rpcCaller_CancelPoolTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := getRpcTestApp()
+	app.Action = func(context *cli.Context) {
+		client = NewMockRpcClient(ctrl)
+		c := &rpcCaller{}
+		SyncStatus.Store(true)
+		wrapRpcArgs(context, "CancelPoolTransaction", testLockAddr1)
+		c.CancelPoolTransaction(context)
+
+		wrapRpcArgs(context, "CancelPoolTransaction", testLockAddr1+",1,1")
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		c.CancelPoolTransaction(context)
+	}
+	assert.NoError(t, app.Run([]string{os.Args[0]}))
+}
@@ -636,6 +636,49 @@ func (caller *rpcCaller) Transaction(c *cli.Context) {
 	printTransactionInfo(resp)
 }
 
+//get the transactions sent from or to the address, the node must run with the address index
+func (caller *rpcCaller) GetTransactionsByAddress(c *cli.Context) {
+
+	if checkSync() {
+		return
+	}
+
+	mName, cParams, err := getRpcMethodAndParam(c)
+	if err != nil {
+		l.Error("getRpcMethodAndParam error")
+		return
+	}
+	if len(cParams) != 4 {
+		l.Error("GetTransactionsByAddress need：address fromBlock toBlock limit")
+		return
+	}
+
+	address, err := CheckAndChangeHexToAddress(cParams[0])
+	if err != nil {
+		l.Error("the address is invalid", "err", err)
+		return
+	}
+
+	var nums [3]uint64
+	for i := range nums {
+		if nums[i], err = strconv.ParseUint(cParams[i+1], 10, 64); err != nil {
+			l.Error("the fromBlock toBlock and limit should be uint64", "err", err)
+			return
+		}
+	}
+
+	var resp []rpc_interface.TransactionResp
+	if err := client.Call(&resp, getDipperinRpcMethodByName(mName), address, nums[0], nums[1], nums[2]); err != nil {
+		l.Error("Call GetTransactionsByAddress", "err", err)
+		return
+	}
+
+	l.Info("GetTransactionsByAddress result", "txNumber", len(resp))
+	for _, tx := range resp {
+		printTransactionInfo(tx)
+	}
+}
+
 //List Wallet
 func (caller *rpcCaller) ListWallet(c *cli.Context) {
 	mName, _, err := getRpcMethodAndParam(c)
@@ -670,7 +713,7 @@ func (caller *rpcCaller) ListWalletAccount(c *cli.Context) {
 		if cParams[0] == "SoftWallet" {
 			identifier.WalletType = accounts.SoftWallet
 		} else if cParams[0] == "LedgerWallet" {
-			identifier.WalletType = accounts.TrezorWallet
+			identifier.WalletType = accounts.LedgerWallet
 		} else if cParams[0] == "TrezorWallet" {
 			identifier.WalletType = accounts.TrezorWallet
 		} else {
@@ -717,7 +760,7 @@ func (caller *rpcCaller) EstablishWallet(c *cli.Context) {
 	if cParams[0] == "SoftWallet" {
 		identifier.WalletType = accounts.SoftWallet
 	} else if cParams[0] == "LedgerWallet" {
-		identifier.WalletType = accounts.TrezorWallet
+		identifier.WalletType = accounts.LedgerWallet
 	} else if cParams[0] == "TrezorWallet" {
 		identifier.WalletType = accounts.TrezorWallet
 	} else {
@@ -768,7 +811,7 @@ func (caller *rpcCaller) RestoreWallet(c *cli.Context) {
 	if cParams[0] == "SoftWallet" {
 		identifier.WalletType = accounts.SoftWallet
 	} else if cParams[0] == "LedgerWallet" {
-		identifier.WalletType = accounts.TrezorWallet
+		identifier.WalletType = accounts.LedgerWallet
 	} else if cParams[0] == "TrezorWallet" {
 		identifier.WalletType = accounts.TrezorWallet
 	} else {
@@ -861,7 +904,7 @@ func (caller *rpcCaller) CloseWallet(c *cli.Context) {
 		if cParams[0] == "SoftWallet" {
 			identifier.WalletType = accounts.SoftWallet
 		} else if cParams[0] == "LedgerWallet" {
-			identifier.WalletType = accounts.TrezorWallet
+			identifier.WalletType = accounts.LedgerWallet
 		} else if cParams[0] == "TrezorWallet" {
 			identifier.WalletType = accounts.TrezorWallet
 		} else {
@@ -900,7 +943,7 @@ func (caller *rpcCaller) AddAccount(c *cli.Context) {
 		if cParams[0] == "SoftWallet" {
 			identifier.WalletType = accounts.SoftWallet
 		} else if cParams[0] == "LedgerWallet" {
-			identifier.WalletType = accounts.TrezorWallet
+			identifier.WalletType = accounts.LedgerWallet
 		} else if cParams[0] == "TrezorWallet" {
 			identifier.WalletType = accounts.TrezorWallet
 		} else {
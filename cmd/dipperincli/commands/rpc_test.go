@@ -730,6 +730,53 @@ func Test_rpcCaller_Transaction(t *testing.T) {
 	client = nil
 }
 
+func Test_rpcCaller_GetTransactionsByAddress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := cli.NewApp()
+
+	app.Flags = []cli.Flag{
+		cli.StringFlag{Name: "m", Usage: "operation"},
+		cli.StringFlag{Name: "p", Usage: "parameters"},
+	}
+
+	app.Action = func(c *cli.Context) {
+		client = NewMockRpcClient(ctrl)
+		caller := &rpcCaller{}
+		SyncStatus.Store(false)
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any()).Return(nil)
+		caller.GetTransactionsByAddress(c)
+
+		SyncStatus.Store(true)
+		caller.GetTransactionsByAddress(c)
+
+		c.Set("m", "test")
+		c.Set("p", "test")
+		caller.GetTransactionsByAddress(c)
+
+		c.Set("p", "test,0,0,10")
+		caller.GetTransactionsByAddress(c)
+
+		c.Set("p", common.HexToAddress("0x1234").Hex()+",0,test,10")
+		caller.GetTransactionsByAddress(c)
+
+		c.Set("p", common.HexToAddress("0x1234").Hex()+",0,0,10")
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("test"))
+		caller.GetTransactionsByAddress(c)
+
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(result interface{}, args ...interface{}) error {
+			tx, _ := factory.CreateTestTx()
+			*result.(*[]rpc_interface.TransactionResp) = []rpc_interface.TransactionResp{{Transaction: tx, BlockNumber: 1}}
+			return nil
+		})
+		caller.GetTransactionsByAddress(c)
+	}
+
+	app.Run([]string{"xxx"})
+	client = nil
+}
+
 func Test_rpcCaller_ListWallet(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -807,7 +854,10 @@ func Test_rpcCaller_ListWalletAccount(t *testing.T) {
 		caller.ListWalletAccount(c)
 
 		c.Set("p", "LedgerWallet, test")
-		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("test"))
+		client.(*MockRpcClient).EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(result interface{}, method string, args ...interface{}) error {
+			assert.Equal(t, accounts.LedgerWallet, args[0].(accounts.WalletIdentifier).WalletType)
+			return errors.New("test")
+		})
 		caller.ListWalletAccount(c)
 
 		c.Set("p", "TrezorWallet, test")
@@ -99,6 +99,7 @@ var methodFlags = []prompt.Suggest{
 	//{Text: "ERC20TotalSupply", Description: ""},
 	{Text: "ERC20Transfer", Description: ""},
 	{Text: "ERC20TransferFrom", Description: ""},
+	{Text: "CancelPoolTransaction", Description: ""},
 	{Text: "EstablishWallet", Description: ""},
 	{Text: "GetAddressNonceFromWallet", Description: ""},
 	{Text: "GetBlockByHash", Description: ""},
@@ -106,26 +107,45 @@ var methodFlags = []prompt.Suggest{
 	{Text: "GetCurVerifiers", Description: ""},
 	{Text: "GetDefaultAccountBalance", Description: ""},
 	{Text: "GetDefaultAccountStake", Description: ""},
+	{Text: "GetDelegations", Description: ""},
 	{Text: "GetGenesis", Description: ""},
+	{Text: "GetHashLock", Description: ""},
+	{Text: "GetLockInfo", Description: ""},
+	{Text: "GetMineWorkers", Description: ""},
+	{Text: "GetMultiSigAccount", Description: ""},
 	{Text: "GetNextVerifiers", Description: ""},
+	{Text: "GetPoolContent", Description: ""},
 	{Text: "GetTransactionNonce", Description: ""},
+	{Text: "GetTransactionsByAddress", Description: ""},
 	{Text: "GetVerifiersBySlot", Description: ""},
+	{Text: "KickMineWorker", Description: ""},
 	{Text: "ListWallet", Description: ""},
 	{Text: "ListWalletAccount", Description: ""},
+	{Text: "NewMultiSigProposal", Description: ""},
 	{Text: "OpenWallet", Description: ""},
+	{Text: "ReplaceTransaction", Description: ""},
 	{Text: "Peers", Description: ""},
 	{Text: "RestoreWallet", Description: ""},
 	{Text: "SendCancelTransaction", Description: ""},
 	{Text: "SendCancelTx", Description: ""},
+	{Text: "SendClaimTransaction", Description: ""},
+	{Text: "SendDelegateTransaction", Description: ""},
+	{Text: "SendLockTransaction", Description: ""},
+	{Text: "SendMultiSigTransaction", Description: ""},
+	{Text: "SendRefundTransaction", Description: ""},
 	{Text: "SendUnStakeTransaction", Description: ""},
 	{Text: "SendUnStakeTx", Description: ""},
+	{Text: "SendUnDelegateTransaction", Description: ""},
 	{Text: "SendRegisterTransaction", Description: ""},
 	{Text: "SendRegisterTx", Description: ""},
+	{Text: "SendRegisterMultiSigTransaction", Description: ""},
 	{Text: "SendTransaction", Description: ""},
 	{Text: "SendTx", Description: ""},
 	{Text: "SetExchangeRate", Description: ""},
 	{Text: "SetMineCoinBase", Description: ""},
+	{Text: "SetMineWorkerCoinbase", Description: ""},
 	{Text: "SetBftSigner", Description: ""},
+	{Text: "SignMultiSigProposal", Description: ""},
 	{Text: "StartMine", Description: ""},
 	{Text: "StopMine", Description: ""},
 	{Text: "Transaction", Description: ""},
@@ -48,8 +48,7 @@ func main() {
 		},
 		cli.IntFlag{
 			Name: minerCountFlagName,
-			Usage: "number of miners",
-			Value: 1,
+			Usage: "number of miners, 0 uses all the cpu cores",
 		},
 		cli.StringFlag{
 			Usage: "p2p port",
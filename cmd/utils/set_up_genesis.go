@@ -18,39 +18,70 @@
 package utils
 
 import (
+	"bytes"
+	"errors"
 	"github.com/dipperin/dipperin-core/core/chain"
 	"github.com/dipperin/dipperin-core/core/chain-config"
 	"github.com/dipperin/dipperin-core/core/chain/registerdb"
 	"github.com/dipperin/dipperin-core/core/chain/state-processor"
 	"github.com/dipperin/dipperin-core/core/cs-chain/chain-state"
 	"github.com/dipperin/dipperin-core/core/cs-chain/chain-writer"
+	"io/ioutil"
+	"path/filepath"
 )
 
+var ErrGenesisFileExist = errors.New("the data dir is initialized with another genesis file")
+
 func SetupGenesis(dataDir string, cConfig *chain_config.ChainConfig) {
+	if err := setupGenesis(dataDir, cConfig); err != nil {
+		panic(err.Error())
+	}
+}
+
+// InitGenesis writes the genesis block of the genesis file into the data dir, the file is copied into the data dir
+// so that the node started on it uses the same chain config
+func InitGenesis(dataDir string, genesisFile string) error {
+	data, err := ioutil.ReadFile(genesisFile)
+	if err != nil {
+		return err
+	}
+	dataDirFile := filepath.Join(dataDir, chain.GenesisFileName)
+	if stored, err := ioutil.ReadFile(dataDirFile); err == nil && !bytes.Equal(stored, data) {
+		return ErrGenesisFileExist
+	}
+
+	if err = chain.LoadGenesisFile(genesisFile); err != nil {
+		return err
+	}
+	if err = setupGenesis(dataDir, chain_config.GetChainConfig()); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(dataDirFile, data, 0644)
+}
+
+func setupGenesis(dataDir string, cConfig *chain_config.ChainConfig) error {
 	cs := chain_state.NewChainState(&chain_state.ChainStateConfig{
 		ChainConfig: cConfig,
 		DataDir: dataDir,
 		WriterFactory: chain_writer.NewChainWriterFactory(),
 	})
-	setupGenesis(cs)
-	cs.ChainDB.DB().Close()
-}
+	defer cs.ChainDB.DB().Close()
 
-func setupGenesis(cs *chain_state.ChainState) {
 	genesisAccountStateProcessor, err := state_processor.MakeGenesisAccountStateProcessor(cs.StateStorage)
 	if err != nil {
-		panic("open account state processor for genesis failed: " + err.Error())
+		return errors.New("open account state processor for genesis failed: " + err.Error())
 	}
 
 	genesisRegisterProcessor, err := registerdb.MakeGenesisRegisterProcessor(cs.StateStorage)
 	if err != nil {
-		panic("make registerDB processor for genesis failed: " + err.Error())
+		return errors.New("make registerDB processor for genesis failed: " + err.Error())
 	}
 	// setup genesis block
 	defaultGenesis := chain.DefaultGenesisBlock(cs.ChainDB, genesisAccountStateProcessor, genesisRegisterProcessor,
 		cs.ChainConfig)
 
 	if _, _, err = chain.SetupGenesisBlock(defaultGenesis); err != nil {
-		panic("setup genesis block failed: " + err.Error())
+		return errors.New("setup genesis block failed: " + err.Error())
 	}
+	return nil
 }
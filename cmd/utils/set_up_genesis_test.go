@@ -18,9 +18,12 @@
 package utils
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/dipperin/dipperin-core/core/chain"
 	"github.com/dipperin/dipperin-core/core/chain-config"
 	"github.com/stretchr/testify/assert"
 )
@@ -40,3 +43,34 @@ func TestFatalf(t *testing.T) {
 		Fatalf("test Fatalf")
 	})
 }
+
+func TestInitGenesis(t *testing.T) {
+	conf := chain_config.GetChainConfig()
+	oldConf, verifiers := *conf, chain.VerifierAddress
+	defer func() {
+		*conf = oldConf
+		chain.VerifierAddress = verifiers
+	}()
+
+	dir, err := ioutil.TempDir("", "init_genesis")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	genesisFile := filepath.Join(dir, "test_genesis.json")
+	dataDir := filepath.Join(dir, "data")
+	assert.Error(t, InitGenesis(dataDir, genesisFile))
+
+	genesis := `{"chain_id": 1234, "network_id": 77, "verifier_number": 1, "verifiers": ["0x0000970e8128aB834E8EAC17aB8E3812f010678CF791"]}`
+	assert.NoError(t, ioutil.WriteFile(genesisFile, []byte(genesis), 0666))
+	assert.NoError(t, InitGenesis(dataDir, genesisFile))
+	assert.Equal(t, uint64(77), conf.NetworkID)
+	stored, err := ioutil.ReadFile(filepath.Join(dataDir, chain.GenesisFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, genesis, string(stored))
+
+	// init again with the same file
+	assert.NoError(t, InitGenesis(dataDir, genesisFile))
+
+	assert.NoError(t, ioutil.WriteFile(genesisFile, []byte(`{"verifiers": ["0x0000970e8128aB834E8EAC17aB8E3812f010678CF791"]}`), 0666))
+	assert.Equal(t, ErrGenesisFileExist, InitGenesis(dataDir, genesisFile))
+}
@@ -30,4 +30,5 @@ var (
 	ErrTxOverSize                           = errors.New("tx over size")
 	ErrEmptyVoteList                        = errors.New("empty vote list")
 	ErrTxNonceNotMatch                      = errors.New("tx nonce not match")
+	ErrTxTypeNotActive                      = errors.New("the tx type isn't active at the block")
 )
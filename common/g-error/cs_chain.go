@@ -52,6 +52,14 @@ var (
 	ErrWitNotMatch = errors.New("witness not match")
 	//ErrNotEnoughCredit is returned when a transaction try to spent more than coin
 	ErrNotEnoughCredit = errors.New("credit smaller than spent")
+	//ErrTransactionNotFound is returned when the transaction is not in the chain
+	ErrTransactionNotFound = errors.New("transaction not found")
+	//ErrReceiptNotFound is returned when the transaction isn't mined or its block has no receipts
+	ErrReceiptNotFound = errors.New("receipt not found")
+	//ErrAddressIndexDisabled is returned when querying the address txs of a node without the address index
+	ErrAddressIndexDisabled = errors.New("address index is disabled")
+	//ErrBlockNotInAddressIndex is returned when querying the address txs of the blocks before the address index was enabled
+	ErrBlockNotInAddressIndex = errors.New("the block is before the start of the address index")
 	//ErrAlreadyHaveThisBlock is returned when
 	ErrAlreadyHaveThisBlock = errors.New("already have this block")
 
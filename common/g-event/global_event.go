@@ -24,6 +24,8 @@ import(
 
 const (
 	NewBlockInsertEvent = "on_new_block"
+	NewTxInPoolEvent    = "on_new_tx_in_pool"
+	ChainRollbackEvent  = "on_chain_rollback"
 )
 
 type Subscription interface {
@@ -49,6 +49,9 @@ const (
 	AddressTypeCancel   = 0x0003
 	AddressTypeUnStake  = 0x0004
 	AddressTypeEvidence = 0x0005
+	AddressTypeMultiSig = 0x0006
+	AddressTypeDelegate = 0x0007
+	AddressTypeUnDelegate = 0x0008
 	AddressTypeERC20    = 0x0010
 	AddressTypeEarlyReward    = 0x0011
 
@@ -68,6 +71,12 @@ func (txType TxType) String() string {
 		return "unstake transaction"
 	case AddressTypeEvidence:
 		return "evidence transaction"
+	case AddressTypeMultiSig:
+		return "multi signature transaction"
+	case AddressTypeDelegate:
+		return "delegate transaction"
+	case AddressTypeUnDelegate:
+		return "undelegate transaction"
 	case AddressTypeERC20:
 		return "erc20 transaction"
 	default:
@@ -76,9 +85,10 @@ func (txType TxType) String() string {
 }
 
 const (
-	AddressStake   = "0x00020000000000000000000000000000000000000000"
-	AddressCancel  = "0x00030000000000000000000000000000000000000000"
-	AddressUnStake = "0x00040000000000000000000000000000000000000000"
+	AddressCrossChain = "0x00010000000000000000000000000000000000000000"
+	AddressStake      = "0x00020000000000000000000000000000000000000000"
+	AddressCancel     = "0x00030000000000000000000000000000000000000000"
+	AddressUnStake    = "0x00040000000000000000000000000000000000000000"
 )
 
 // Dipperin hash
@@ -251,6 +261,12 @@ func (addr Address) GetAddressTypeStr() string {
 		return "UnStake"
 	case AddressTypeEvidence:
 		return "Evidence"
+	case AddressTypeMultiSig:
+		return "MultiSig"
+	case AddressTypeDelegate:
+		return "Delegate"
+	case AddressTypeUnDelegate:
+		return "UnDelegate"
 	case AddressTypeEarlyReward:
 		return consts.EarlyTokenTypeName
 	}
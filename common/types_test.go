@@ -179,6 +179,12 @@ func TestTypeXX(t *testing.T) {
 	assert.Equal(t, "unstake transaction", (TxType)(x).String())
 	x = AddressTypeEvidence
 	assert.Equal(t, "evidence transaction", (TxType)(x).String())
+	x = AddressTypeMultiSig
+	assert.Equal(t, "multi signature transaction", (TxType)(x).String())
+	x = AddressTypeDelegate
+	assert.Equal(t, "delegate transaction", (TxType)(x).String())
+	x = AddressTypeUnDelegate
+	assert.Equal(t, "undelegate transaction", (TxType)(x).String())
 	x = AddressTypeERC20
 	assert.Equal(t, "erc20 transaction", (TxType)(x).String())
 	x = 0x999
@@ -231,6 +237,9 @@ func TestAddressFuncs(t *testing.T) {
 	assert.Equal(t, "Cancel", HexToAddress("0x00035033874289F4F823A896700D94274683535cF0E1").GetAddressTypeStr())
 	assert.Equal(t, "UnStake", HexToAddress("0x00045033874289F4F823A896700D94274683535cF0E1").GetAddressTypeStr())
 	assert.Equal(t, "Evidence", HexToAddress("0x00055033874289F4F823A896700D94274683535cF0E1").GetAddressTypeStr())
+	assert.Equal(t, "MultiSig", HexToAddress("0x00065033874289F4F823A896700D94274683535cF0E1").GetAddressTypeStr())
+	assert.Equal(t, "Delegate", HexToAddress("0x00075033874289F4F823A896700D94274683535cF0E1").GetAddressTypeStr())
+	assert.Equal(t, "UnDelegate", HexToAddress("0x00085033874289F4F823A896700D94274683535cF0E1").GetAddressTypeStr())
 	assert.Equal(t, consts.EarlyTokenTypeName, HexToAddress("0x00115033874289F4F823A896700D94274683535cF0E1").GetAddressTypeStr())
 
 	assert.False(t, StringToAddress("0x00012").IsEmpty())
@@ -22,6 +22,8 @@ import (
 
 var ErrNotSupportUsbWallet = errors.New("not support USB wallet")
 
+var ErrInvalidWalletType = errors.New("invalid wallet type")
+
 var ErrNotFindWallet = errors.New("not find the wallet")
 
 var ErrInvalidKDFParameter = errors.New("invalid KDFParameter")
@@ -0,0 +1,50 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hard_wallet
+
+import (
+	"crypto/ecdsa"
+	"github.com/dipperin/dipperin-core/core/accounts"
+	"io"
+)
+
+// what the user confirms on the device when the data is signed
+type signKind byte
+
+const (
+	// the data is the rlp sign data of the tx, the device shows the tx decoded from it and signs its hash
+	signKindTx signKind = iota
+	// bft votes and the other messages, the data is the hash and it is signed blind
+	signKindMsg
+)
+
+// PinPrompt asks the user for the pin of a locked trezor. The device shows a new scrambled matrix for every
+// request, so the answer is the positions of the pin digits on the matrix on the screen
+type PinPrompt func() (string, error)
+
+// driver talks the wallet protocol of a device
+type driver interface {
+	// open checks the app or the firmware on the device, the prompt is called every time the device asks for the pin
+	open(device io.ReadWriter, prompt PinPrompt) error
+	close() error
+	// derive returns the public key of the account at the path
+	derive(path accounts.DerivationPath) (*ecdsa.PublicKey, error)
+	// sign signs the data of the kind with the key at the path, the signature is in the [R || S || V] format
+	sign(path accounts.DerivationPath, kind signKind, data []byte) ([]byte, error)
+	// evaluate generates the vrf proof of the seed with the key at the path
+	evaluate(path accounts.DerivationPath, seed []byte) ([32]byte, []byte, error)
+}
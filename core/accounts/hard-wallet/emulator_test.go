@@ -0,0 +1,393 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hard_wallet
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"github.com/dipperin/dipperin-core/core/accounts"
+	"github.com/dipperin/dipperin-core/core/accounts/soft-wallet"
+	"github.com/dipperin/dipperin-core/third-party/crypto"
+	"github.com/dipperin/dipperin-core/third-party/go-bip39"
+	"github.com/ethereum/go-ethereum/rlp"
+	"io"
+	"math/rand"
+	"sync"
+)
+
+const testMnemonic = "chicken coconut winner february brown topple pond bird endless salt filter journey mass ramp milk tuition card seat worth school length rain slice ozone"
+
+var errEmulatorClosed = errors.New("emulator closed")
+
+// the keys of an emulated device derived from the test mnemonic as a soft wallet does
+type emulatorKeys struct {
+	master *soft_wallet.ExtendedKey
+}
+
+func newEmulatorKeys() *emulatorKeys {
+	seed, err := bip39.NewSeedWithErrorChecking(testMnemonic, "")
+	if err != nil {
+		panic(err)
+	}
+	master, err := soft_wallet.NewMaster(seed, &soft_wallet.DipperinChainCfg)
+	if err != nil {
+		panic(err)
+	}
+	return &emulatorKeys{master: master}
+}
+
+func (k *emulatorKeys) key(path accounts.DerivationPath) *ecdsa.PrivateKey {
+	extKey := k.master
+	for _, component := range path {
+		var err error
+		if extKey, err = extKey.Child(component); err != nil {
+			panic(err)
+		}
+	}
+	sk, err := extKey.ECPrivKey()
+	if err != nil {
+		panic(err)
+	}
+	return &ecdsa.PrivateKey{PublicKey: sk.PublicKey, D: sk.D}
+}
+
+// sign signs the data as the firmware does, the sign data of a tx is decoded to be shown and hashed on the device
+func (k *emulatorKeys) sign(path accounts.DerivationPath, kind signKind, data []byte) ([]byte, error) {
+	if kind == signKindTx {
+		var tx []rlp.RawValue
+		if err := rlp.DecodeBytes(data, &tx); err != nil {
+			return nil, err
+		}
+		data = crypto.Keccak256(data)
+	}
+	return crypto.Sign(data, k.key(path))
+}
+
+// emulatedDevice is a hid device answering the requests written to it, the frames are parsed by parse
+// and the reply frames of the request are returned by handle
+type emulatedDevice struct {
+	parse  func(frames []byte) ([]byte, error)
+	handle func(req []byte) []byte
+
+	lock    sync.Mutex
+	pending []byte
+	replies bytes.Buffer
+	closed  bool
+}
+
+func (d *emulatedDevice) Write(p []byte) (int, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.closed {
+		return 0, errEmulatorClosed
+	}
+	d.pending = append(d.pending, p...)
+	req, err := d.parse(d.pending)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		// wait for the rest of the request
+		return len(p), nil
+	}
+	d.pending = nil
+	if err != nil {
+		return 0, err
+	}
+	d.replies.Write(d.handle(req))
+	return len(p), nil
+}
+
+func (d *emulatedDevice) Read(p []byte) (int, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.closed {
+		return 0, errEmulatorClosed
+	}
+	return d.replies.Read(p)
+}
+
+func (d *emulatedDevice) Close() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.closed = true
+	return nil
+}
+
+// ledgerEmulator is the dipperin app on a ledger
+type ledgerEmulator struct {
+	keys    *emulatorKeys
+	appOpen bool
+	deny    bool
+	// the kinds of the signed data
+	signed []signKind
+	// the sign data received in the previous apdus
+	pending []byte
+}
+
+func newLedgerEmulator() *ledgerEmulator {
+	return &ledgerEmulator{keys: newEmulatorKeys(), appOpen: true}
+}
+
+func (e *ledgerEmulator) device() *emulatedDevice {
+	return &emulatedDevice{
+		parse: func(frames []byte) ([]byte, error) {
+			return readLedgerFrames(bytes.NewReader(frames))
+		},
+		handle: func(apdu []byte) []byte {
+			var frames bytes.Buffer
+			writeLedgerFrames(&frames, e.handle(apdu))
+			return frames.Bytes()
+		},
+	}
+}
+
+func ledgerReply(data []byte, status uint16) []byte {
+	reply := make([]byte, len(data)+2)
+	copy(reply, data)
+	binary.BigEndian.PutUint16(reply[len(data):], status)
+	return reply
+}
+
+func decodeLedgerPath(data []byte) (accounts.DerivationPath, []byte) {
+	path := make(accounts.DerivationPath, data[0])
+	for i := range path {
+		path[i] = binary.BigEndian.Uint32(data[1+4*i:])
+	}
+	return path, data[1+4*len(path):]
+}
+
+func (e *ledgerEmulator) handle(apdu []byte) []byte {
+	if !e.appOpen || apdu[0] != ledgerCla {
+		return ledgerReply(nil, ledgerStatusClaInvalid)
+	}
+	p1, p2, data := apdu[2], apdu[3], apdu[5:5+int(apdu[4])]
+	switch apdu[1] {
+	case ledgerInsGetVersion:
+		return ledgerReply([]byte{1, 0, 2}, ledgerStatusOk)
+	case ledgerInsGetPublicKey:
+		path, _ := decodeLedgerPath(data)
+		return ledgerReply(crypto.FromECDSAPub(&e.keys.key(path).PublicKey), ledgerStatusOk)
+	case ledgerInsSign:
+		e.pending = append(e.pending, data...)
+		if p2 == ledgerP2More {
+			return ledgerReply(nil, ledgerStatusOk)
+		}
+		path, signData := decodeLedgerPath(e.pending)
+		e.pending = nil
+		if e.deny {
+			return ledgerReply(nil, ledgerStatusDenied)
+		}
+		sig, err := e.keys.sign(path, signKind(p1), signData)
+		if err != nil {
+			return ledgerReply(nil, 0x6a80)
+		}
+		e.signed = append(e.signed, signKind(p1))
+		return ledgerReply(sig, ledgerStatusOk)
+	case ledgerInsEvaluate:
+		path, seed := decodeLedgerPath(data)
+		index, proof := crypto.Evaluate(e.keys.key(path), seed)
+		return ledgerReply(append(index[:], proof...), ledgerStatusOk)
+	default:
+		return ledgerReply(nil, ledgerStatusInsInvalid)
+	}
+}
+
+// trezorEmulator is the dipperin firmware on a trezor one, it is locked by the pin
+type trezorEmulator struct {
+	keys   *emulatorKeys
+	pin    string
+	locked bool
+	deny   bool
+	signed []signKind
+
+	// the digits at the keypad positions 1-9 of the pin matrix, scrambled for every pin request
+	matrix []byte
+	random *rand.Rand
+
+	// the request waiting for the pin or the button
+	pendingType uint16
+	pendingReq  []byte
+}
+
+func newTrezorEmulator(pin string) *trezorEmulator {
+	return &trezorEmulator{keys: newEmulatorKeys(), pin: pin, locked: pin != "", random: rand.New(rand.NewSource(1))}
+}
+
+// pinPositions is what the user enters after looking at the matrix on the screen
+func (e *trezorEmulator) pinPositions(pin string) string {
+	positions := make([]byte, len(pin))
+	for i := range pin {
+		positions[i] = byte(bytes.IndexByte(e.matrix, pin[i])) + '1'
+	}
+	return string(positions)
+}
+
+func (e *trezorEmulator) matrixPin(positions []byte) string {
+	pin := make([]byte, len(positions))
+	for i, p := range positions {
+		if p < '1' || p > '9' || e.matrix == nil {
+			return ""
+		}
+		pin[i] = e.matrix[p-'1']
+	}
+	return string(pin)
+}
+
+type trezorMsg struct {
+	kind uint16
+	data []byte
+}
+
+func (e *trezorEmulator) device() *emulatedDevice {
+	return &emulatedDevice{
+		parse: func(frames []byte) ([]byte, error) {
+			kind, data, err := readTrezorFrames(bytes.NewReader(frames))
+			if err != nil {
+				return nil, err
+			}
+			return append([]byte{byte(kind >> 8), byte(kind)}, data...), nil
+		},
+		handle: func(req []byte) []byte {
+			reply := e.handle(binary.BigEndian.Uint16(req), req[2:])
+			var frames bytes.Buffer
+			writeTrezorFrames(&frames, reply.kind, reply.data)
+			return frames.Bytes()
+		},
+	}
+}
+
+func trezorFailure(message string) trezorMsg {
+	return trezorMsg{trezorMsgFailure, protoMsg(nil).appendVarint(1, 99).appendBytes(2, []byte(message))}
+}
+
+func decodeTrezorPath(fields protoFields) accounts.DerivationPath {
+	var path accounts.DerivationPath
+	for _, field := range fields {
+		if field.num == 1 {
+			path = append(path, uint32(field.varint))
+		}
+	}
+	return path
+}
+
+func (e *trezorEmulator) handle(kind uint16, data []byte) trezorMsg {
+	switch kind {
+	case trezorMsgInitialize:
+		return trezorMsg{trezorMsgFeatures, protoMsg(nil).appendBytes(1, []byte("trezor.io")).appendVarint(2, 1).appendVarint(3, 8).appendVarint(4, 3)}
+	case trezorMsgPinMatrixAck:
+		fields, _ := decodeProto(data)
+		if e.pendingReq == nil && e.pendingType == 0 {
+			return trezorFailure("Unexpected message")
+		}
+		pin := e.matrixPin(fields.bytes(1))
+		e.matrix = nil
+		if pin != e.pin {
+			e.pendingType, e.pendingReq = 0, nil
+			return trezorFailure("PIN invalid")
+		}
+		e.locked = false
+		return e.handle(e.pendingType, e.pendingReq)
+	case trezorMsgButtonAck:
+		if e.deny {
+			e.pendingType, e.pendingReq = 0, nil
+			return trezorFailure("Signing cancelled")
+		}
+		return e.sign(e.pendingReq)
+	}
+
+	if e.locked {
+		e.pendingType, e.pendingReq = kind, data
+		e.matrix = make([]byte, 9)
+		for i, digit := range e.random.Perm(9) {
+			e.matrix[i] = byte(digit) + '1'
+		}
+		return trezorMsg{trezorMsgPinMatrixRequest, protoMsg(nil).appendVarint(1, 1)}
+	}
+	fields, _ := decodeProto(data)
+	switch kind {
+	case trezorMsgGetPublicKey:
+		pk := crypto.CompressPubkey(&e.keys.key(decodeTrezorPath(fields)).PublicKey)
+		node := protoMsg(nil).appendVarint(1, 4).appendBytes(6, pk)
+		return trezorMsg{trezorMsgPublicKey, protoMsg(nil).appendBytes(1, node)}
+	case trezorMsgDipperinSign:
+		e.pendingType, e.pendingReq = kind, data
+		return trezorMsg{trezorMsgButtonRequest, protoMsg(nil).appendVarint(1, 8)}
+	case trezorMsgDipperinEvaluate:
+		index, proof := crypto.Evaluate(e.keys.key(decodeTrezorPath(fields)), fields.bytes(2))
+		return trezorMsg{trezorMsgDipperinVrfProof, protoMsg(nil).appendBytes(1, index[:]).appendBytes(2, proof)}
+	default:
+		return trezorFailure("Unexpected message")
+	}
+}
+
+func (e *trezorEmulator) sign(req []byte) trezorMsg {
+	e.pendingType, e.pendingReq = 0, nil
+	fields, _ := decodeProto(req)
+	sig, err := e.keys.sign(decodeTrezorPath(fields), signKind(fields.varint(2)), fields.bytes(3))
+	if err != nil {
+		return trezorFailure(err.Error())
+	}
+	e.signed = append(e.signed, signKind(fields.varint(2)))
+	return trezorMsg{trezorMsgDipperinSignature, protoMsg(nil).appendBytes(1, sig)}
+}
+
+// fakeEnumerator serves the emulated devices, a device is connected every time it is opened
+type fakeEnumerator struct {
+	infos   []DeviceInfo
+	devices map[string]func() Device
+}
+
+func (e *fakeEnumerator) Enumerate(vendorID uint16) ([]DeviceInfo, error) {
+	var result []DeviceInfo
+	for _, info := range e.infos {
+		if info.VendorID == vendorID {
+			result = append(result, info)
+		}
+	}
+	return result, nil
+}
+
+func (e *fakeEnumerator) Open(info DeviceInfo) (Device, error) {
+	connect, ok := e.devices[info.Path]
+	if !ok {
+		return nil, errDeviceNotFound
+	}
+	return connect(), nil
+}
+
+var (
+	testLedgerInfo = DeviceInfo{Path: "/dev/hidraw1", VendorID: ledgerVendorID, ProductID: 0x1011, Product: "Nano S", Interface: 0}
+	testTrezorInfo = DeviceInfo{Path: "/dev/hidraw2", VendorID: trezorVendorID, ProductID: trezorOneProductID, Product: "TREZOR", Interface: 0}
+)
+
+// use the emulators as the plugged devices until the returned func is called
+func setTestEnumerator(ledger *ledgerEmulator, trezor *trezorEmulator) func() {
+	e := &fakeEnumerator{devices: map[string]func() Device{}}
+	if ledger != nil {
+		e.infos = append(e.infos, testLedgerInfo)
+		e.devices[testLedgerInfo.Path] = func() Device { return ledger.device() }
+	}
+	if trezor != nil {
+		e.infos = append(e.infos, testTrezorInfo)
+		e.devices[testTrezorInfo.Path] = func() Device { return trezor.device() }
+	}
+	old := currentEnumerator()
+	SetEnumerator(e)
+	return func() {
+		SetEnumerator(old)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// +build linux
+
+package hard_wallet
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var (
+	hidrawSysDir = "/sys/class/hidraw"
+	hidrawDevDir = "/dev"
+)
+
+func defaultEnumerator() Enumerator {
+	return hidrawEnumerator{}
+}
+
+// hidrawEnumerator finds the devices through the hidraw nodes of the kernel, no cgo is needed
+type hidrawEnumerator struct{}
+
+func (hidrawEnumerator) Enumerate(vendorID uint16) ([]DeviceInfo, error) {
+	entries, err := ioutil.ReadDir(hidrawSysDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var result []DeviceInfo
+	for _, entry := range entries {
+		info, ok := readHidrawInfo(entry.Name())
+		if ok && info.VendorID == vendorID {
+			result = append(result, info)
+		}
+	}
+	return result, nil
+}
+
+func (hidrawEnumerator) Open(info DeviceInfo) (Device, error) {
+	f, err := os.OpenFile(info.Path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &hidrawDevice{f: f}, nil
+}
+
+// read the ids from the uevent of the hid device, e.g. HID_ID=0003:00002C97:00000001
+func readHidrawInfo(name string) (DeviceInfo, bool) {
+	devicePath := filepath.Join(hidrawSysDir, name, "device")
+	f, err := os.Open(filepath.Join(devicePath, "uevent"))
+	if err != nil {
+		return DeviceInfo{}, false
+	}
+	defer f.Close()
+
+	info := DeviceInfo{Path: filepath.Join(hidrawDevDir, name), Interface: -1}
+	found := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		kv := strings.SplitN(scanner.Text(), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "HID_ID":
+			ids := strings.Split(kv[1], ":")
+			if len(ids) != 3 {
+				return DeviceInfo{}, false
+			}
+			vendor, vErr := strconv.ParseUint(ids[1], 16, 16)
+			product, pErr := strconv.ParseUint(ids[2], 16, 16)
+			if vErr != nil || pErr != nil {
+				return DeviceInfo{}, false
+			}
+			info.VendorID, info.ProductID = uint16(vendor), uint16(product)
+			found = true
+		case "HID_NAME":
+			info.Product = kv[1]
+		}
+	}
+	if !found {
+		return DeviceInfo{}, false
+	}
+
+	// the hid device is a child of the usb interface
+	if realPath, err := filepath.EvalSymlinks(devicePath); err == nil {
+		if data, err := ioutil.ReadFile(filepath.Join(filepath.Dir(realPath), "bInterfaceNumber")); err == nil {
+			if iface, err := strconv.ParseUint(strings.TrimSpace(string(data)), 16, 8); err == nil {
+				info.Interface = int(iface)
+			}
+		}
+	}
+	return info, true
+}
+
+type hidrawDevice struct {
+	f *os.File
+}
+
+func (d *hidrawDevice) Read(p []byte) (int, error) {
+	return d.f.Read(p)
+}
+
+// the report id 0 is prepended as the devices don't number their reports
+func (d *hidrawDevice) Write(p []byte) (int, error) {
+	n, err := d.f.Write(append([]byte{0}, p...))
+	if n > 0 {
+		n--
+	}
+	return n, err
+}
+
+func (d *hidrawDevice) Close() error {
+	return d.f.Close()
+}
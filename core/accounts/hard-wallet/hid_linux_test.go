@@ -0,0 +1,88 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// +build linux
+
+package hard_wallet
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// create the sysfs entry of a hidraw node under the usb interface
+func createHidrawEntry(t *testing.T, sysDir, name, iface, uevent string) {
+	hidDir := filepath.Join(sysDir, "devices", name+"-usb", "1-1:1."+iface, "0003:"+name)
+	assert.NoError(t, os.MkdirAll(hidDir, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(hidDir, "uevent"), []byte(uevent), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(filepath.Dir(hidDir), "bInterfaceNumber"), []byte("0"+iface+"\n"), 0644))
+
+	nodeDir := filepath.Join(sysDir, "class", name)
+	assert.NoError(t, os.MkdirAll(nodeDir, 0755))
+	assert.NoError(t, os.Symlink(hidDir, filepath.Join(nodeDir, "device")))
+}
+
+func TestHidrawEnumerator(t *testing.T) {
+	sysDir, err := ioutil.TempDir("", "hidraw")
+	assert.NoError(t, err)
+	defer os.RemoveAll(sysDir)
+
+	oldSysDir, oldDevDir := hidrawSysDir, hidrawDevDir
+	hidrawSysDir, hidrawDevDir = filepath.Join(sysDir, "class"), sysDir
+	defer func() {
+		hidrawSysDir, hidrawDevDir = oldSysDir, oldDevDir
+	}()
+
+	e := hidrawEnumerator{}
+	infos, err := e.Enumerate(ledgerVendorID)
+	assert.NoError(t, err)
+	assert.Empty(t, infos)
+
+	createHidrawEntry(t, sysDir, "hidraw0", "0", "DRIVER=hid-generic\nHID_ID=0003:00002C97:00001011\nHID_NAME=Ledger Nano S\n")
+	createHidrawEntry(t, sysDir, "hidraw1", "1", "HID_ID=0003:00002C97:00001011\nHID_NAME=Ledger Nano S\n")
+	createHidrawEntry(t, sysDir, "hidraw2", "0", "HID_ID=0003:0000534C:00000001\nHID_NAME=SatoshiLabs TREZOR\n")
+	createHidrawEntry(t, sysDir, "hidraw3", "0", "HID_ID=broken\n")
+
+	infos, err = e.Enumerate(ledgerVendorID)
+	assert.NoError(t, err)
+	assert.Equal(t, []DeviceInfo{
+		{Path: filepath.Join(sysDir, "hidraw0"), VendorID: ledgerVendorID, ProductID: 0x1011, Product: "Ledger Nano S", Interface: 0},
+		{Path: filepath.Join(sysDir, "hidraw1"), VendorID: ledgerVendorID, ProductID: 0x1011, Product: "Ledger Nano S", Interface: 1},
+	}, infos)
+	infos, err = e.Enumerate(trezorVendorID)
+	assert.NoError(t, err)
+	assert.Equal(t, []DeviceInfo{
+		{Path: filepath.Join(sysDir, "hidraw2"), VendorID: trezorVendorID, ProductID: trezorOneProductID, Product: "SatoshiLabs TREZOR", Interface: 0},
+	}, infos)
+
+	// a plain file stands for the device node, the report id is prepended to the written reports
+	assert.NoError(t, ioutil.WriteFile(infos[0].Path, nil, 0600))
+	device, err := e.Open(infos[0])
+	assert.NoError(t, err)
+	n, err := device.Write([]byte{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.NoError(t, device.Close())
+	data, err := ioutil.ReadFile(infos[0].Path)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0, 1, 2, 3}, data)
+
+	_, err = e.Open(DeviceInfo{Path: filepath.Join(sysDir, "hidraw9")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,36 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// +build !linux
+
+package hard_wallet
+
+import "github.com/dipperin/dipperin-core/core/accounts"
+
+func defaultEnumerator() Enumerator {
+	return unsupportedEnumerator{}
+}
+
+// there is no builtin hid backend on this platform, one can be set by SetEnumerator
+type unsupportedEnumerator struct{}
+
+func (unsupportedEnumerator) Enumerate(vendorID uint16) ([]DeviceInfo, error) {
+	return nil, accounts.ErrNotSupportUsbWallet
+}
+
+func (unsupportedEnumerator) Open(info DeviceInfo) (Device, error) {
+	return nil, accounts.ErrNotSupportUsbWallet
+}
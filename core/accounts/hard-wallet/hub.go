@@ -0,0 +1,151 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hard_wallet
+
+import (
+	"errors"
+	"github.com/dipperin/dipperin-core/core/accounts"
+	"io"
+	"sync"
+)
+
+// the size of a hid report exchanged with the devices
+const hidReportSize = 64
+
+const (
+	ledgerVendorID = 0x2c97
+	trezorVendorID = 0x534c
+	// trezor one, the model T uses webusb instead of hid
+	trezorOneProductID = 0x0001
+)
+
+var (
+	errNoDevice       = errors.New("no hardware wallet device found")
+	errDeviceNotFound = errors.New("hardware wallet device not found")
+)
+
+// DeviceInfo describes a hid interface of a usb device
+type DeviceInfo struct {
+	// the path used to open the device, e.g. /dev/hidraw0
+	Path      string
+	VendorID  uint16
+	ProductID uint16
+	Product   string
+	// the usb interface number, -1 if unknown
+	Interface int
+}
+
+// Device is an opened hid interface, every Read or Write transfers a single report without the report id
+type Device interface {
+	io.ReadWriteCloser
+}
+
+// Enumerator lists and opens the hid devices, it is replaced to use another hid backend or a device emulator
+type Enumerator interface {
+	Enumerate(vendorID uint16) ([]DeviceInfo, error)
+	Open(info DeviceInfo) (Device, error)
+}
+
+var (
+	enumeratorLock sync.RWMutex
+	enumerator     Enumerator = defaultEnumerator()
+)
+
+// SetEnumerator replaces the hid backend used by the hardware wallets opened afterwards
+func SetEnumerator(e Enumerator) {
+	enumeratorLock.Lock()
+	defer enumeratorLock.Unlock()
+	enumerator = e
+}
+
+func currentEnumerator() Enumerator {
+	enumeratorLock.RLock()
+	defer enumeratorLock.RUnlock()
+	return enumerator
+}
+
+// the devices which are able to hold the keys of a wallet type
+type deviceSpec struct {
+	vendorID uint16
+	// any product of the vendor if empty
+	productIDs []uint16
+	// the interface talking the wallet protocol
+	iface     int
+	newDriver func() driver
+}
+
+var deviceSpecs = map[accounts.WalletType]deviceSpec{
+	accounts.LedgerWallet: {vendorID: ledgerVendorID, iface: 0, newDriver: newLedgerDriver},
+	accounts.TrezorWallet: {vendorID: trezorVendorID, productIDs: []uint16{trezorOneProductID}, iface: 0, newDriver: newTrezorDriver},
+}
+
+func (spec deviceSpec) match(info DeviceInfo) bool {
+	if info.VendorID != spec.vendorID {
+		return false
+	}
+	if info.Interface >= 0 && info.Interface != spec.iface {
+		return false
+	}
+	if len(spec.productIDs) == 0 {
+		return true
+	}
+	for _, id := range spec.productIDs {
+		if id == info.ProductID {
+			return true
+		}
+	}
+	return false
+}
+
+// Devices lists the plugged devices of the hardware wallet type
+func Devices(walletType accounts.WalletType) ([]DeviceInfo, error) {
+	spec, ok := deviceSpecs[walletType]
+	if !ok {
+		return nil, accounts.ErrInvalidWalletType
+	}
+	infos, err := currentEnumerator().Enumerate(spec.vendorID)
+	if err != nil {
+		return nil, err
+	}
+	var result []DeviceInfo
+	for _, info := range infos {
+		if spec.match(info) {
+			result = append(result, info)
+		}
+	}
+	return result, nil
+}
+
+// find the device at the path, the first device is used if the path is empty
+func findDevice(walletType accounts.WalletType, path string) (DeviceInfo, error) {
+	infos, err := Devices(walletType)
+	if err != nil {
+		return DeviceInfo{}, err
+	}
+	if len(infos) == 0 {
+		return DeviceInfo{}, errNoDevice
+	}
+	if path == "" {
+		return infos[0], nil
+	}
+	for _, info := range infos {
+		if info.Path == path {
+			return info, nil
+		}
+	}
+	return DeviceInfo{}, errDeviceNotFound
+}
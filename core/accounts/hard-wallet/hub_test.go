@@ -0,0 +1,60 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hard_wallet
+
+import (
+	"github.com/dipperin/dipperin-core/core/accounts"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDevices(t *testing.T) {
+	e := &fakeEnumerator{infos: []DeviceInfo{
+		testLedgerInfo,
+		// the u2f interface of the ledger
+		{Path: "/dev/hidraw3", VendorID: ledgerVendorID, ProductID: 0x1011, Interface: 1},
+		{Path: "/dev/hidraw4", VendorID: ledgerVendorID, ProductID: 0x4011, Interface: -1},
+		testTrezorInfo,
+		// a trezor model T
+		{Path: "/dev/hidraw5", VendorID: trezorVendorID, ProductID: 0x0002, Interface: 0},
+	}}
+	old := currentEnumerator()
+	SetEnumerator(e)
+	defer SetEnumerator(old)
+
+	infos, err := Devices(accounts.LedgerWallet)
+	assert.NoError(t, err)
+	assert.Equal(t, []DeviceInfo{testLedgerInfo, e.infos[2]}, infos)
+	infos, err = Devices(accounts.TrezorWallet)
+	assert.NoError(t, err)
+	assert.Equal(t, []DeviceInfo{testTrezorInfo}, infos)
+	_, err = Devices(accounts.SoftWallet)
+	assert.Equal(t, accounts.ErrInvalidWalletType, err)
+
+	info, err := findDevice(accounts.LedgerWallet, "")
+	assert.NoError(t, err)
+	assert.Equal(t, testLedgerInfo, info)
+	info, err = findDevice(accounts.LedgerWallet, "/dev/hidraw4")
+	assert.NoError(t, err)
+	assert.Equal(t, e.infos[2], info)
+	_, err = findDevice(accounts.LedgerWallet, "/dev/hidraw3")
+	assert.Equal(t, errDeviceNotFound, err)
+
+	e.infos = nil
+	_, err = findDevice(accounts.TrezorWallet, "")
+	assert.Equal(t, errNoDevice, err)
+}
@@ -0,0 +1,230 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hard_wallet
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/dipperin/dipperin-core/core/accounts"
+	"github.com/dipperin/dipperin-core/third-party/crypto"
+	"github.com/dipperin/dipperin-core/third-party/log"
+	"io"
+)
+
+// the apdus of the dipperin ledger app
+const (
+	ledgerCla = 0xe0
+
+	ledgerInsGetPublicKey = 0x02
+	ledgerInsSign         = 0x04
+	ledgerInsGetVersion   = 0x06
+	ledgerInsEvaluate     = 0x08
+
+	// P2 of the sign apdus followed by more data
+	ledgerP2More = 0x80
+)
+
+const (
+	ledgerChannel = 0x0101
+	ledgerTagApdu = 0x05
+
+	ledgerStatusOk         = 0x9000
+	ledgerStatusDenied     = 0x6985
+	ledgerStatusInsInvalid = 0x6d00
+	ledgerStatusClaInvalid = 0x6e00
+
+	// the data of an apdu has a single byte length
+	ledgerMaxApduData = 255
+)
+
+var (
+	errLedgerAppNotOpen   = errors.New("ledger: the dipperin app isn't open on the device")
+	errLedgerDenied       = errors.New("ledger: denied by the user")
+	errLedgerReply        = errors.New("ledger: invalid reply")
+	errLedgerDataTooLarge = errors.New("ledger: apdu data is too large")
+)
+
+type ledgerDriver struct {
+	device  io.ReadWriter
+	version [3]byte
+}
+
+func newLedgerDriver() driver {
+	return &ledgerDriver{}
+}
+
+// the ledger is unlocked on the device, the pin prompt isn't used
+func (l *ledgerDriver) open(device io.ReadWriter, prompt PinPrompt) error {
+	l.device = device
+	reply, err := l.exchange(ledgerInsGetVersion, 0, 0, nil)
+	if err != nil {
+		return err
+	}
+	if len(reply) < 3 {
+		return errLedgerReply
+	}
+	copy(l.version[:], reply)
+	log.Info("open ledger wallet", "version", fmt.Sprintf("%d.%d.%d", l.version[0], l.version[1], l.version[2]))
+	return nil
+}
+
+func (l *ledgerDriver) close() error {
+	l.device = nil
+	return nil
+}
+
+// the public key is in the uncompressed format
+func (l *ledgerDriver) derive(path accounts.DerivationPath) (*ecdsa.PublicKey, error) {
+	reply, err := l.exchange(ledgerInsGetPublicKey, 0, 0, encodeLedgerPath(path))
+	if err != nil {
+		return nil, err
+	}
+	if len(reply) != 65 {
+		return nil, errLedgerReply
+	}
+	return crypto.UnmarshalPubkey(reply)
+}
+
+// the sign data of a tx may not fit in an apdu, it is sent in several apdus and P2 is set on all of them but the last
+func (l *ledgerDriver) sign(path accounts.DerivationPath, kind signKind, data []byte) ([]byte, error) {
+	var (
+		reply   []byte
+		err     error
+		payload = append(encodeLedgerPath(path), data...)
+	)
+	for len(payload) > 0 {
+		size, p2 := len(payload), byte(0)
+		if size > ledgerMaxApduData {
+			size, p2 = ledgerMaxApduData, ledgerP2More
+		}
+		if reply, err = l.exchange(ledgerInsSign, byte(kind), p2, payload[:size]); err != nil {
+			return nil, err
+		}
+		payload = payload[size:]
+	}
+	if len(reply) != 65 {
+		return nil, errLedgerReply
+	}
+	return reply, nil
+}
+
+func (l *ledgerDriver) evaluate(path accounts.DerivationPath, seed []byte) ([32]byte, []byte, error) {
+	var index [32]byte
+	reply, err := l.exchange(ledgerInsEvaluate, 0, 0, append(encodeLedgerPath(path), seed...))
+	if err != nil {
+		return index, nil, err
+	}
+	if len(reply) <= len(index) {
+		return index, nil, errLedgerReply
+	}
+	copy(index[:], reply)
+	return index, reply[len(index):], nil
+}
+
+// the path is sent as the count of the components followed by the big endian components
+func encodeLedgerPath(path accounts.DerivationPath) []byte {
+	data := make([]byte, 1+4*len(path))
+	data[0] = byte(len(path))
+	for i, component := range path {
+		binary.BigEndian.PutUint32(data[1+4*i:], component)
+	}
+	return data
+}
+
+// exchange sends an apdu and returns the reply without the status word.
+// The apdu is split into hid reports of the format:
+//
+//	channel (2) | tag (1) | sequence (2) | apdu length (2, the first report only) | apdu chunk
+func (l *ledgerDriver) exchange(ins, p1, p2 byte, data []byte) ([]byte, error) {
+	if l.device == nil {
+		return nil, accounts.ErrWalletNotOpen
+	}
+	if len(data) > ledgerMaxApduData {
+		return nil, errLedgerDataTooLarge
+	}
+	apdu := append([]byte{ledgerCla, ins, p1, p2, byte(len(data))}, data...)
+	if err := writeLedgerFrames(l.device, apdu); err != nil {
+		return nil, err
+	}
+	reply, err := readLedgerFrames(l.device)
+	if err != nil {
+		return nil, err
+	}
+	if len(reply) < 2 {
+		return nil, errLedgerReply
+	}
+
+	status := binary.BigEndian.Uint16(reply[len(reply)-2:])
+	switch status {
+	case ledgerStatusOk:
+		return reply[:len(reply)-2], nil
+	case ledgerStatusDenied:
+		return nil, errLedgerDenied
+	case ledgerStatusInsInvalid, ledgerStatusClaInvalid:
+		return nil, errLedgerAppNotOpen
+	default:
+		return nil, fmt.Errorf("ledger: unexpected status %#04x", status)
+	}
+}
+
+func writeLedgerFrames(w io.Writer, payload []byte) error {
+	// the length of the payload is in front of the first chunk
+	data := make([]byte, 2+len(payload))
+	binary.BigEndian.PutUint16(data, uint16(len(payload)))
+	copy(data[2:], payload)
+
+	report := make([]byte, hidReportSize)
+	for seq := uint16(0); len(data) > 0; seq++ {
+		for i := range report {
+			report[i] = 0
+		}
+		binary.BigEndian.PutUint16(report, ledgerChannel)
+		report[2] = ledgerTagApdu
+		binary.BigEndian.PutUint16(report[3:], seq)
+		n := copy(report[5:], data)
+		data = data[n:]
+		if _, err := w.Write(report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readLedgerFrames(r io.Reader) ([]byte, error) {
+	var (
+		payload []byte
+		length  = -1
+		report  = make([]byte, hidReportSize)
+	)
+	for seq := uint16(0); length < 0 || len(payload) < length; seq++ {
+		if _, err := io.ReadFull(r, report); err != nil {
+			return nil, err
+		}
+		if binary.BigEndian.Uint16(report) != ledgerChannel || report[2] != ledgerTagApdu || binary.BigEndian.Uint16(report[3:]) != seq {
+			return nil, errLedgerReply
+		}
+		chunk := report[5:]
+		if seq == 0 {
+			length = int(binary.BigEndian.Uint16(chunk))
+			chunk = chunk[2:]
+		}
+		payload = append(payload, chunk...)
+	}
+	return payload[:length], nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hard_wallet
+
+import (
+	"bytes"
+	"github.com/dipperin/dipperin-core/core/accounts"
+	"github.com/dipperin/dipperin-core/third-party/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLedgerFrames(t *testing.T) {
+	payload := make([]byte, 100)
+	for i := range payload {
+		payload[i] = byte(i)
+	}
+	var frames bytes.Buffer
+	assert.NoError(t, writeLedgerFrames(&frames, payload))
+	data := frames.Bytes()
+	assert.Equal(t, 2*hidReportSize, len(data))
+
+	// the first report carries the length, the second one continues at sequence 1
+	assert.Equal(t, []byte{0x01, 0x01, 0x05, 0x00, 0x00, 0x00, 100, 0, 1}, data[:9])
+	assert.Equal(t, []byte{0x01, 0x01, 0x05, 0x00, 0x01}, data[hidReportSize:hidReportSize+5])
+	assert.Equal(t, byte(57), data[hidReportSize+5])
+
+	result, err := readLedgerFrames(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, payload, result)
+
+	// out of sequence
+	data[hidReportSize+4] = 2
+	_, err = readLedgerFrames(bytes.NewReader(data))
+	assert.Equal(t, errLedgerReply, err)
+}
+
+func TestLedgerDriver(t *testing.T) {
+	emulator := newLedgerEmulator()
+	l := newLedgerDriver().(*ledgerDriver)
+	assert.NoError(t, l.open(emulator.device(), nil))
+	assert.Equal(t, [3]byte{1, 0, 2}, l.version)
+
+	path := append(append(accounts.DerivationPath{}, DefaultBaseDerivationPath...), 3)
+	key := emulator.keys.key(path)
+	pk, err := l.derive(path)
+	assert.NoError(t, err)
+	assert.Equal(t, key.X, pk.X)
+	assert.Equal(t, key.Y, pk.Y)
+
+	hash := crypto.Keccak256([]byte("vote"))
+	sig, err := l.sign(path, signKindMsg, hash)
+	assert.NoError(t, err)
+	assert.True(t, crypto.VerifySignature(crypto.FromECDSAPub(pk), hash, sig[:64]))
+	assert.Equal(t, []signKind{signKindMsg}, emulator.signed)
+
+	// the sign data of a tx with a large extra data is sent in several apdus
+	data, err := rlp.EncodeToBytes([]interface{}{make([]byte, 3*ledgerMaxApduData), uint64(1600)})
+	assert.NoError(t, err)
+	sig, err = l.sign(path, signKindTx, data)
+	assert.NoError(t, err)
+	assert.True(t, crypto.VerifySignature(crypto.FromECDSAPub(pk), crypto.Keccak256(data), sig[:64]))
+	assert.Equal(t, []signKind{signKindMsg, signKindTx}, emulator.signed)
+
+	index, proof, err := l.evaluate(path, hash)
+	assert.NoError(t, err)
+	expected, err := crypto.ProofToHash(pk, hash, proof)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, index)
+
+	emulator.deny = true
+	_, err = l.sign(path, signKindTx, data)
+	assert.Equal(t, errLedgerDenied, err)
+
+	_, _, err = l.evaluate(path, make([]byte, ledgerMaxApduData))
+	assert.Equal(t, errLedgerDataTooLarge, err)
+
+	assert.NoError(t, l.close())
+	_, err = l.derive(path)
+	assert.Equal(t, accounts.ErrWalletNotOpen, err)
+
+	// the dashboard or another app is open
+	emulator.appOpen = false
+	assert.Equal(t, errLedgerAppNotOpen, l.open(emulator.device(), nil))
+}
@@ -0,0 +1,125 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hard_wallet
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// the trezor messages only use a few scalar fields, so they are encoded by hand instead of generated code
+const (
+	protoWireVarint  = 0
+	protoWireFixed64 = 1
+	protoWireBytes   = 2
+	protoWireFixed32 = 5
+)
+
+var errInvalidProto = errors.New("invalid protobuf message")
+
+// protoMsg is an encoded protobuf message
+type protoMsg []byte
+
+func (m protoMsg) appendVarint(field int, v uint64) protoMsg {
+	m = appendUvarint(m, uint64(field)<<3|protoWireVarint)
+	return appendUvarint(m, v)
+}
+
+func (m protoMsg) appendBytes(field int, data []byte) protoMsg {
+	m = appendUvarint(m, uint64(field)<<3|protoWireBytes)
+	m = appendUvarint(m, uint64(len(data)))
+	return append(m, data...)
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
+
+type protoField struct {
+	num    int
+	varint uint64
+	data   []byte
+}
+
+// protoFields are the decoded fields of a message in the encoded order
+type protoFields []protoField
+
+func decodeProto(data []byte) (protoFields, error) {
+	var fields protoFields
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, errInvalidProto
+		}
+		data = data[n:]
+		field := protoField{num: int(key >> 3)}
+
+		switch key & 7 {
+		case protoWireVarint:
+			if field.varint, n = binary.Uvarint(data); n <= 0 {
+				return nil, errInvalidProto
+			}
+			data = data[n:]
+		case protoWireBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < length {
+				return nil, errInvalidProto
+			}
+			field.data = data[n : n+int(length)]
+			data = data[n+int(length):]
+		case protoWireFixed64:
+			if len(data) < 8 {
+				return nil, errInvalidProto
+			}
+			field.varint = binary.LittleEndian.Uint64(data)
+			data = data[8:]
+		case protoWireFixed32:
+			if len(data) < 4 {
+				return nil, errInvalidProto
+			}
+			field.varint = uint64(binary.LittleEndian.Uint32(data))
+			data = data[4:]
+		default:
+			return nil, errInvalidProto
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// the last value of the field wins as in protobuf, nil if the field is absent
+func (fields protoFields) bytes(num int) []byte {
+	var result []byte
+	for _, field := range fields {
+		if field.num == num {
+			result = field.data
+		}
+	}
+	return result
+}
+
+func (fields protoFields) varint(num int) uint64 {
+	var result uint64
+	for _, field := range fields {
+		if field.num == num {
+			result = field.varint
+		}
+	}
+	return result
+}
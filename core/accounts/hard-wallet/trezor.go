@@ -0,0 +1,263 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hard_wallet
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/dipperin/dipperin-core/core/accounts"
+	"github.com/dipperin/dipperin-core/third-party/crypto"
+	"github.com/dipperin/dipperin-core/third-party/log"
+	"io"
+	"os"
+	"strings"
+)
+
+// the message types of the trezor wire protocol
+const (
+	trezorMsgInitialize        = 0
+	trezorMsgFailure           = 3
+	trezorMsgGetPublicKey      = 11
+	trezorMsgPublicKey         = 12
+	trezorMsgFeatures          = 17
+	trezorMsgPinMatrixRequest  = 18
+	trezorMsgPinMatrixAck      = 19
+	trezorMsgButtonRequest     = 26
+	trezorMsgButtonAck         = 27
+	trezorMsgPassphraseRequest = 41
+	trezorMsgPassphraseAck     = 42
+
+	// the messages of the dipperin firmware, they are out of the range of the trezor messages
+	trezorMsgDipperinSign      = 1000
+	trezorMsgDipperinSignature = 1001
+	trezorMsgDipperinEvaluate  = 1002
+	trezorMsgDipperinVrfProof  = 1003
+)
+
+const (
+	trezorCurveName = "secp256k1"
+	// '?' '#' '#' | message type (2) | message length (4)
+	trezorHeaderSize = 9
+)
+
+var (
+	errTrezorPinNeeded = errors.New("trezor: the device is locked, unlock it with the pin first")
+	errTrezorReply     = errors.New("trezor: invalid reply")
+)
+
+type trezorDriver struct {
+	device io.ReadWriter
+	prompt PinPrompt
+}
+
+func newTrezorDriver() driver {
+	return &trezorDriver{}
+}
+
+func (t *trezorDriver) open(device io.ReadWriter, prompt PinPrompt) error {
+	t.device, t.prompt = device, prompt
+	reply, err := t.exchange(trezorMsgInitialize, nil, trezorMsgFeatures)
+	if err != nil {
+		return err
+	}
+	fields, err := decodeProto(reply)
+	if err != nil {
+		return err
+	}
+	log.Info("open trezor wallet", "vendor", string(fields.bytes(1)), "version",
+		fmt.Sprintf("%d.%d.%d", fields.varint(2), fields.varint(3), fields.varint(4)))
+	return nil
+}
+
+func (t *trezorDriver) close() error {
+	t.device, t.prompt = nil, nil
+	return nil
+}
+
+func (t *trezorDriver) derive(path accounts.DerivationPath) (*ecdsa.PublicKey, error) {
+	req := appendTrezorPath(nil, path).appendBytes(2, []byte(trezorCurveName))
+	reply, err := t.exchange(trezorMsgGetPublicKey, req, trezorMsgPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := decodeProto(reply)
+	if err != nil {
+		return nil, err
+	}
+	node, err := decodeProto(fields.bytes(1))
+	if err != nil {
+		return nil, err
+	}
+	// the public key of the node is compressed
+	pubKey := node.bytes(6)
+	if len(pubKey) != 33 {
+		return nil, errTrezorReply
+	}
+	return crypto.DecompressPubkey(pubKey)
+}
+
+func (t *trezorDriver) sign(path accounts.DerivationPath, kind signKind, data []byte) ([]byte, error) {
+	req := appendTrezorPath(nil, path).appendVarint(2, uint64(kind)).appendBytes(3, data)
+	reply, err := t.exchange(trezorMsgDipperinSign, req, trezorMsgDipperinSignature)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := decodeProto(reply)
+	if err != nil {
+		return nil, err
+	}
+	sig := fields.bytes(1)
+	if len(sig) != 65 {
+		return nil, errTrezorReply
+	}
+	return sig, nil
+}
+
+func (t *trezorDriver) evaluate(path accounts.DerivationPath, seed []byte) ([32]byte, []byte, error) {
+	var index [32]byte
+	req := appendTrezorPath(nil, path).appendBytes(2, seed)
+	reply, err := t.exchange(trezorMsgDipperinEvaluate, req, trezorMsgDipperinVrfProof)
+	if err != nil {
+		return index, nil, err
+	}
+	fields, err := decodeProto(reply)
+	if err != nil {
+		return index, nil, err
+	}
+	if len(fields.bytes(1)) != len(index) || len(fields.bytes(2)) == 0 {
+		return index, nil, errTrezorReply
+	}
+	copy(index[:], fields.bytes(1))
+	return index, fields.bytes(2), nil
+}
+
+// TerminalPinPrompt reads the pin from the standard input. The digits are the positions of the pin on the matrix
+// shown by the device, numbered as a keypad: 7 8 9 on the top row, 1 2 3 on the bottom row
+func TerminalPinPrompt() (string, error) {
+	fmt.Print("Enter the positions of the pin on the trezor matrix: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func appendTrezorPath(msg protoMsg, path accounts.DerivationPath) protoMsg {
+	for _, component := range path {
+		msg = msg.appendVarint(1, uint64(component))
+	}
+	return msg
+}
+
+// exchange sends the request and answers the pin, button and passphrase requests of the device until the reply arrives
+func (t *trezorDriver) exchange(reqType uint16, req []byte, replyType uint16) ([]byte, error) {
+	if t.device == nil {
+		return nil, accounts.ErrWalletNotOpen
+	}
+	for {
+		if err := writeTrezorFrames(t.device, reqType, req); err != nil {
+			return nil, err
+		}
+		kind, reply, err := readTrezorFrames(t.device)
+		if err != nil {
+			return nil, err
+		}
+
+		switch kind {
+		case replyType:
+			return reply, nil
+		case trezorMsgFailure:
+			fields, err := decodeProto(reply)
+			if err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("trezor: %s", fields.bytes(2))
+		case trezorMsgPinMatrixRequest:
+			// the matrix is scrambled again for every request, the positions of the last answer are useless
+			if t.prompt == nil {
+				return nil, errTrezorPinNeeded
+			}
+			pin, err := t.prompt()
+			if err != nil {
+				return nil, err
+			}
+			reqType, req = trezorMsgPinMatrixAck, protoMsg(nil).appendBytes(1, []byte(pin))
+		case trezorMsgButtonRequest:
+			log.Info("confirm on the trezor device")
+			reqType, req = trezorMsgButtonAck, nil
+		case trezorMsgPassphraseRequest:
+			// the passphrase isn't supported, the standard wallet is used
+			reqType, req = trezorMsgPassphraseAck, protoMsg(nil).appendBytes(1, nil)
+		default:
+			return nil, fmt.Errorf("trezor: unexpected reply %d", kind)
+		}
+	}
+}
+
+// the first report starts with '?' '#' '#' and the message header, the others start with '?'
+func writeTrezorFrames(w io.Writer, kind uint16, payload []byte) error {
+	data := make([]byte, trezorHeaderSize-1+len(payload))
+	data[0], data[1] = '#', '#'
+	binary.BigEndian.PutUint16(data[2:], kind)
+	binary.BigEndian.PutUint32(data[4:], uint32(len(payload)))
+	copy(data[trezorHeaderSize-1:], payload)
+
+	report := make([]byte, hidReportSize)
+	for len(data) > 0 {
+		for i := range report {
+			report[i] = 0
+		}
+		report[0] = '?'
+		n := copy(report[1:], data)
+		data = data[n:]
+		if _, err := w.Write(report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readTrezorFrames(r io.Reader) (uint16, []byte, error) {
+	var (
+		kind    uint16
+		payload []byte
+		length  = -1
+		report  = make([]byte, hidReportSize)
+	)
+	for length < 0 || len(payload) < length {
+		if _, err := io.ReadFull(r, report); err != nil {
+			return 0, nil, err
+		}
+		if report[0] != '?' {
+			return 0, nil, errTrezorReply
+		}
+		chunk := report[1:]
+		if length < 0 {
+			if chunk[0] != '#' || chunk[1] != '#' {
+				return 0, nil, errTrezorReply
+			}
+			kind = binary.BigEndian.Uint16(chunk[2:])
+			length = int(binary.BigEndian.Uint32(chunk[4:]))
+			chunk = chunk[trezorHeaderSize-1:]
+		}
+		payload = append(payload, chunk...)
+	}
+	return kind, payload[:length], nil
+}
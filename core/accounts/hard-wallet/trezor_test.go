@@ -0,0 +1,137 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hard_wallet
+
+import (
+	"bytes"
+	"github.com/dipperin/dipperin-core/core/accounts"
+	"github.com/dipperin/dipperin-core/third-party/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTrezorFrames(t *testing.T) {
+	payload := bytes.Repeat([]byte{0xaa}, 70)
+	var frames bytes.Buffer
+	assert.NoError(t, writeTrezorFrames(&frames, trezorMsgGetPublicKey, payload))
+	data := frames.Bytes()
+	assert.Equal(t, 2*hidReportSize, len(data))
+	assert.Equal(t, []byte{'?', '#', '#', 0, 11, 0, 0, 0, 70, 0xaa}, data[:10])
+	assert.Equal(t, byte('?'), data[hidReportSize])
+
+	kind, result, err := readTrezorFrames(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(trezorMsgGetPublicKey), kind)
+	assert.Equal(t, payload, result)
+
+	data[1] = '!'
+	_, _, err = readTrezorFrames(bytes.NewReader(data))
+	assert.Equal(t, errTrezorReply, err)
+}
+
+func TestTrezorDriver(t *testing.T) {
+	emulator := newTrezorEmulator("1234")
+	path := append(append(accounts.DerivationPath{}, DefaultBaseDerivationPath...), 1)
+
+	// the device asks for the pin
+	d := newTrezorDriver()
+	assert.NoError(t, d.open(emulator.device(), nil))
+	_, err := d.derive(path)
+	assert.Equal(t, errTrezorPinNeeded, err)
+
+	// the pin itself isn't the answer, the positions on the scrambled matrix are
+	assert.NoError(t, d.open(emulator.device(), func() (string, error) { return "1234", nil }))
+	_, err = d.derive(path)
+	assert.EqualError(t, err, "trezor: PIN invalid")
+
+	// the positions of an earlier matrix don't unlock the device
+	var last string
+	assert.NoError(t, d.open(emulator.device(), func() (string, error) {
+		stale := last
+		last = emulator.pinPositions("1234")
+		return stale, nil
+	}))
+	_, err = d.derive(path)
+	assert.EqualError(t, err, "trezor: PIN invalid")
+	_, err = d.derive(path)
+	assert.EqualError(t, err, "trezor: PIN invalid")
+
+	prompts := 0
+	assert.NoError(t, d.open(emulator.device(), func() (string, error) {
+		prompts++
+		return emulator.pinPositions("1234"), nil
+	}))
+	pk, err := d.derive(path)
+	assert.NoError(t, err)
+	key := emulator.keys.key(path)
+	assert.Equal(t, key.X, pk.X)
+	assert.Equal(t, key.Y, pk.Y)
+	assert.Equal(t, 1, prompts)
+
+	// the signing is confirmed by the button
+	data, err := rlp.EncodeToBytes([]interface{}{[]byte("tx"), uint64(1600)})
+	assert.NoError(t, err)
+	sig, err := d.sign(path, signKindTx, data)
+	assert.NoError(t, err)
+	hash := crypto.Keccak256(data)
+	assert.True(t, crypto.VerifySignature(crypto.FromECDSAPub(pk), hash, sig[:64]))
+	assert.Equal(t, []signKind{signKindTx}, emulator.signed)
+
+	// the device can't show a tx which isn't rlp encoded
+	_, err = d.sign(path, signKindTx, hash)
+	assert.EqualError(t, err, "trezor: rlp: expected input list for []rlp.RawValue")
+
+	index, proof, err := d.evaluate(path, hash)
+	assert.NoError(t, err)
+	expected, err := crypto.ProofToHash(pk, hash, proof)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, index)
+
+	emulator.deny = true
+	_, err = d.sign(path, signKindMsg, hash)
+	assert.EqualError(t, err, "trezor: Signing cancelled")
+
+	assert.NoError(t, d.close())
+	_, err = d.derive(path)
+	assert.Equal(t, accounts.ErrWalletNotOpen, err)
+}
+
+func TestDecodeProto(t *testing.T) {
+	msg := protoMsg(nil).appendVarint(1, 300).appendBytes(2, []byte("abc")).appendVarint(1, 5)
+	fields, err := decodeProto(msg)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(fields))
+	assert.Equal(t, uint64(5), fields.varint(1))
+	assert.Equal(t, []byte("abc"), fields.bytes(2))
+	assert.Nil(t, fields.bytes(3))
+
+	// the fixed width fields
+	fixed := append(protoMsg{2<<3 | protoWireFixed32}, 1, 0, 0, 0)
+	fixed = append(append(fixed, 3<<3|protoWireFixed64), 2, 0, 0, 0, 0, 0, 0, 0)
+	fields, err = decodeProto(fixed)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), fields.varint(2))
+	assert.Equal(t, uint64(2), fields.varint(3))
+
+	_, err = decodeProto(msg[:len(msg)-2])
+	assert.NoError(t, err)
+	_, err = decodeProto(protoMsg(nil).appendBytes(2, []byte("abc"))[:3])
+	assert.Equal(t, errInvalidProto, err)
+	_, err = decodeProto([]byte{1<<3 | 3})
+	assert.Equal(t, errInvalidProto, err)
+}
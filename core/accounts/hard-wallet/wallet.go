@@ -0,0 +1,353 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hard_wallet
+
+import (
+	"crypto/ecdsa"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/core/accounts"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/third-party/crypto/cs-crypto"
+	"github.com/dipperin/dipperin-core/third-party/log"
+	"math/big"
+	"sync"
+)
+
+// DefaultBaseDerivationPath is the same as the one of the soft wallet, m/44'/709394'/0'/0,
+// so a device restored from the mnemonic of a soft wallet has the same accounts
+var DefaultBaseDerivationPath = accounts.DerivationPath{0x80000000 + 44, 0x80000000 + 709394, 0x80000000 + 0, 0}
+
+// the index of the first account under the base path
+const addressIndexStartValue = 1
+
+// HardWallet keeps the keys in a ledger or trezor device, the accounts are derived and
+// the txs, bft votes and vrf seeds are signed on the device
+type HardWallet struct {
+	walletType accounts.WalletType
+	identifier accounts.WalletIdentifier
+	status     string
+
+	device Device
+	driver driver
+
+	accounts []accounts.Account
+	paths    map[common.Address]accounts.DerivationPath
+	pubKeys  map[common.Address]*ecdsa.PublicKey
+	nonces   map[common.Address]uint64
+	// the next index of the account under the default base path
+	nextIndex uint32
+
+	mu sync.RWMutex
+	// the device handles a request at a time
+	commsLock sync.Mutex
+
+	promptLock sync.Mutex
+	pinPrompt  PinPrompt
+}
+
+func NewHardWallet(walletType accounts.WalletType) (*HardWallet, error) {
+	if _, ok := deviceSpecs[walletType]; !ok {
+		return nil, accounts.ErrInvalidWalletType
+	}
+	return &HardWallet{
+		walletType: walletType,
+		identifier: accounts.WalletIdentifier{WalletType: walletType},
+		status:     accounts.Closed,
+	}, nil
+}
+
+func (w *HardWallet) GetWalletIdentifier() (accounts.WalletIdentifier, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.status != accounts.Opened {
+		return accounts.WalletIdentifier{}, accounts.ErrWalletNotOpen
+	}
+	return w.identifier, nil
+}
+
+func (w *HardWallet) Status() (string, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.status, nil
+}
+
+// the keys are generated on the device
+func (w *HardWallet) Establish(path, name, password, passPhrase string) (string, error) {
+	return "", accounts.ErrNotSupported
+}
+
+// the mnemonic is restored on the device
+func (w *HardWallet) RestoreWallet(path, name, password, passPhrase, mnemonic string, GetAddressRelatedInfo accounts.AddressInfoReader) (err error) {
+	return accounts.ErrNotSupported
+}
+
+// SetPinPrompt sets how the pin of a locked trezor is asked, the device must be unlocked in advance if nil
+func (w *HardWallet) SetPinPrompt(prompt PinPrompt) {
+	w.promptLock.Lock()
+	defer w.promptLock.Unlock()
+	w.pinPrompt = prompt
+}
+
+// askPin is the pin prompt of the driver, so that the prompt can be changed while the wallet is open
+func (w *HardWallet) askPin() (string, error) {
+	w.promptLock.Lock()
+	prompt := w.pinPrompt
+	w.promptLock.Unlock()
+	if prompt == nil {
+		return "", errTrezorPinNeeded
+	}
+	return prompt()
+}
+
+// Open connects to the device at the path, the first device of the wallet type is used if the path is empty.
+// The name is the product name of the device if empty. The password isn't used, the pin of a trezor is asked
+// by the pin prompt of the wallet so that it never has to be kept
+func (w *HardWallet) Open(path, name, password string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.status == accounts.Opened {
+		return nil
+	}
+
+	info, err := findDevice(w.walletType, path)
+	if err != nil {
+		return err
+	}
+	device, err := currentEnumerator().Open(info)
+	if err != nil {
+		return err
+	}
+	drv := deviceSpecs[w.walletType].newDriver()
+	if err = drv.open(device, w.askPin); err != nil {
+		device.Close()
+		return err
+	}
+
+	w.device, w.driver = device, drv
+	w.accounts = []accounts.Account{}
+	w.paths = make(map[common.Address]accounts.DerivationPath)
+	w.pubKeys = make(map[common.Address]*ecdsa.PublicKey)
+	w.nonces = make(map[common.Address]uint64)
+	w.nextIndex = addressIndexStartValue
+
+	// the first account is always in the wallet
+	if _, err = w.derive(nil, true); err != nil {
+		w.closeDevice()
+		return err
+	}
+
+	if name == "" {
+		name = info.Product
+	}
+	w.identifier = accounts.WalletIdentifier{WalletType: w.walletType, Path: info.Path, WalletName: name}
+	w.status = accounts.Opened
+	log.Info("open hardware wallet", "type", w.walletType, "path", info.Path, "account", w.accounts[0].Address.Hex())
+	return nil
+}
+
+func (w *HardWallet) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.status != accounts.Opened {
+		return accounts.ErrWalletNotOpen
+	}
+	w.status = accounts.Closed
+	return w.closeDevice()
+}
+
+func (w *HardWallet) closeDevice() error {
+	// closing the device breaks the request waiting for the user
+	err := w.device.Close()
+	w.commsLock.Lock()
+	w.driver.close()
+	w.commsLock.Unlock()
+	w.device, w.driver = nil, nil
+	return err
+}
+
+func (w *HardWallet) PaddingAddressNonce(GetAddressRelatedInfo accounts.AddressInfoReader) (err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, account := range w.accounts {
+		nonce, err := GetAddressRelatedInfo.GetTransactionNonce(account.Address)
+		if err != nil {
+			log.Warn("padding hardware wallet nonce failed", "address", account.Address.Hex(), "err", err)
+			continue
+		}
+		w.nonces[account.Address] = nonce
+	}
+	return nil
+}
+
+func (w *HardWallet) GetAddressNonce(address common.Address) (nonce uint64, err error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.nonces[address], nil
+}
+
+func (w *HardWallet) SetAddressNonce(address common.Address, nonce uint64) (err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.nonces == nil {
+		return accounts.ErrWalletNotOpen
+	}
+	w.nonces[address] = nonce
+	return nil
+}
+
+func (w *HardWallet) Accounts() ([]accounts.Account, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.status != accounts.Opened {
+		return []accounts.Account{}, accounts.ErrWalletNotOpen
+	}
+	return append([]accounts.Account{}, w.accounts...), nil
+}
+
+func (w *HardWallet) Contains(account accounts.Account) (bool, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.status != accounts.Opened {
+		return false, accounts.ErrWalletNotOpen
+	}
+	_, ok := w.paths[account.Address]
+	return ok, nil
+}
+
+// Derive asks the device for the account at the path, the next account under the default base path is derived
+// if the path is empty. The account is added to the wallet if pin is true, the accounts are kept until closed
+func (w *HardWallet) Derive(path accounts.DerivationPath, pin bool) (accounts.Account, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.status != accounts.Opened {
+		return accounts.Account{}, accounts.ErrWalletNotOpen
+	}
+	return w.derive(path, pin)
+}
+
+func (w *HardWallet) derive(path accounts.DerivationPath, pin bool) (accounts.Account, error) {
+	nextDefault := len(path) == 0
+	if nextDefault {
+		path = append(append(accounts.DerivationPath{}, DefaultBaseDerivationPath...), w.nextIndex)
+	}
+
+	w.commsLock.Lock()
+	pk, err := w.driver.derive(path)
+	w.commsLock.Unlock()
+	if err != nil {
+		return accounts.Account{}, err
+	}
+
+	account := accounts.Account{Address: cs_crypto.GetNormalAddress(*pk)}
+	if !pin {
+		return account, nil
+	}
+	if nextDefault {
+		w.nextIndex++
+	}
+	if _, ok := w.paths[account.Address]; !ok {
+		w.accounts = append(w.accounts, account)
+		w.paths[account.Address] = path
+		w.pubKeys[account.Address] = pk
+	}
+	return account, nil
+}
+
+// the used accounts can't be found without the chain, the accounts are derived by Derive
+func (w *HardWallet) SelfDerive(base accounts.DerivationPath) error {
+	return nil
+}
+
+// the path of the account and the driver of the opened device
+func (w *HardWallet) accountPath(account accounts.Account) (accounts.DerivationPath, driver, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.status != accounts.Opened {
+		return nil, nil, accounts.ErrWalletNotOpen
+	}
+	path, ok := w.paths[account.Address]
+	if !ok {
+		return nil, nil, accounts.ErrInvalidAddress
+	}
+	return path, w.driver, nil
+}
+
+// SignHash signs a message hash on the device, e.g. a bft vote
+func (w *HardWallet) SignHash(account accounts.Account, hash []byte) ([]byte, error) {
+	return w.sign(account, signKindMsg, hash)
+}
+
+func (w *HardWallet) sign(account accounts.Account, kind signKind, data []byte) ([]byte, error) {
+	path, drv, err := w.accountPath(account)
+	if err != nil {
+		return nil, err
+	}
+	w.commsLock.Lock()
+	defer w.commsLock.Unlock()
+	return drv.sign(path, kind, data)
+}
+
+func (w *HardWallet) GetPKFromAddress(account accounts.Account) (*ecdsa.PublicKey, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.status != accounts.Opened {
+		return nil, accounts.ErrWalletNotOpen
+	}
+	pk, ok := w.pubKeys[account.Address]
+	if !ok {
+		return nil, accounts.ErrInvalidAddress
+	}
+	return pk, nil
+}
+
+// the private keys never leave the device
+func (w *HardWallet) GetSKFromAddress(address common.Address) (*ecdsa.PrivateKey, error) {
+	return nil, accounts.ErrNotSupported
+}
+
+// SignTx sends the sign data of the tx to the device, so that the user confirms the tx shown on the device instead of
+// a hash. The signature is checked before filling the witness
+func (w *HardWallet) SignTx(account accounts.Account, tx *model.Transaction, chainID *big.Int) (*model.Transaction, error) {
+	s := model.NewMercurySigner(chainID)
+	data, err := s.GetSignData(tx)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := w.sign(account, signKindTx, data)
+	if err != nil {
+		return nil, err
+	}
+	signedTx, err := tx.WithSignature(s, sig)
+	if err != nil {
+		return nil, err
+	}
+	if sender, err := signedTx.Sender(s); err != nil || sender != account.Address {
+		return nil, accounts.ErrSignatureInvalid
+	}
+	return signedTx, nil
+}
+
+// Evaluate generates the vrf proof on the device
+func (w *HardWallet) Evaluate(account accounts.Account, seed []byte) (index [32]byte, proof []byte, err error) {
+	path, drv, err := w.accountPath(account)
+	if err != nil {
+		return [32]byte{}, []byte{}, err
+	}
+	w.commsLock.Lock()
+	defer w.commsLock.Unlock()
+	return drv.evaluate(path, seed)
+}
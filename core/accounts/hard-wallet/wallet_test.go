@@ -0,0 +1,193 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hard_wallet
+
+import (
+	"errors"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/core/accounts"
+	"github.com/dipperin/dipperin-core/core/accounts/soft-wallet"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/third-party/crypto"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+type fakeAddressInfoReader struct {
+	nonce uint64
+	err   error
+}
+
+func (r fakeAddressInfoReader) CurrentBalance(address common.Address) *big.Int {
+	return big.NewInt(0)
+}
+
+func (r fakeAddressInfoReader) GetTransactionNonce(addr common.Address) (nonce uint64, err error) {
+	return r.nonce, r.err
+}
+
+func TestNewHardWallet(t *testing.T) {
+	_, err := NewHardWallet(accounts.SoftWallet)
+	assert.Equal(t, accounts.ErrInvalidWalletType, err)
+
+	w, err := NewHardWallet(accounts.LedgerWallet)
+	assert.NoError(t, err)
+	status, _ := w.Status()
+	assert.Equal(t, accounts.Closed, status)
+	_, err = w.GetWalletIdentifier()
+	assert.Equal(t, accounts.ErrWalletNotOpen, err)
+	_, err = w.Establish("", "", "", "")
+	assert.Equal(t, accounts.ErrNotSupported, err)
+	assert.Equal(t, accounts.ErrNotSupported, w.RestoreWallet("", "", "", "", testMnemonic, nil))
+	assert.Equal(t, accounts.ErrWalletNotOpen, w.Close())
+	_, err = w.Accounts()
+	assert.Equal(t, accounts.ErrWalletNotOpen, err)
+	_, err = w.SignHash(accounts.Account{}, make([]byte, 32))
+	assert.Equal(t, accounts.ErrWalletNotOpen, err)
+
+	// no device plugged
+	defer setTestEnumerator(nil, nil)()
+	assert.Equal(t, errNoDevice, w.Open("", "", ""))
+}
+
+func TestHardWallet(t *testing.T) {
+	ledger, trezor := newLedgerEmulator(), newTrezorEmulator("1234")
+	defer setTestEnumerator(ledger, trezor)()
+
+	softAccounts, err := soft_wallet.GetAccountsFromMnemonic(testMnemonic, "", 2)
+	assert.NoError(t, err)
+
+	for _, walletType := range []accounts.WalletType{accounts.LedgerWallet, accounts.TrezorWallet} {
+		w, err := NewHardWallet(walletType)
+		assert.NoError(t, err)
+		w.SetPinPrompt(func() (string, error) { return trezor.pinPositions("1234"), nil })
+		assert.NoError(t, w.Open("", "", ""))
+		// opened again
+		assert.NoError(t, w.Open("", "", ""))
+
+		identifier, err := w.GetWalletIdentifier()
+		assert.NoError(t, err)
+		assert.Equal(t, walletType, identifier.WalletType)
+		if walletType == accounts.LedgerWallet {
+			assert.Equal(t, accounts.WalletIdentifier{WalletType: walletType, Path: testLedgerInfo.Path, WalletName: "Nano S"}, identifier)
+		}
+
+		// the accounts are the same as the soft wallet restored from the mnemonic of the device
+		walletAccounts, err := w.Accounts()
+		assert.NoError(t, err)
+		assert.Equal(t, softAccounts[:1], walletAccounts)
+		account, err := w.Derive(nil, true)
+		assert.NoError(t, err)
+		assert.Equal(t, softAccounts[1], account)
+		contains, err := w.Contains(account)
+		assert.NoError(t, err)
+		assert.True(t, contains)
+
+		// an account out of the wallet
+		other, err := w.Derive(accounts.DerivationPath{0x80000000 + 44, 0x80000000 + 709394, 0x80000000 + 1, 0, 1}, false)
+		assert.NoError(t, err)
+		contains, err = w.Contains(other)
+		assert.NoError(t, err)
+		assert.False(t, contains)
+		_, err = w.SignHash(other, make([]byte, 32))
+		assert.Equal(t, accounts.ErrInvalidAddress, err)
+		_, err = w.GetPKFromAddress(other)
+		assert.Equal(t, accounts.ErrInvalidAddress, err)
+		_, err = w.GetSKFromAddress(account.Address)
+		assert.Equal(t, accounts.ErrNotSupported, err)
+
+		pk, err := w.GetPKFromAddress(account)
+		assert.NoError(t, err)
+		hash := crypto.Keccak256([]byte("bft vote"))
+		sig, err := w.SignHash(account, hash)
+		assert.NoError(t, err)
+		assert.True(t, crypto.VerifySignature(crypto.FromECDSAPub(pk), hash, sig[:64]))
+
+		tx := model.NewTransaction(1, common.HexToAddress("0x0000970e8128aB834E8EAC17aB8E3812f010678CF791"), big.NewInt(100), big.NewInt(1), nil)
+		signedTx, err := w.SignTx(account, tx, big.NewInt(1600))
+		assert.NoError(t, err)
+		sender, err := signedTx.Sender(model.NewMercurySigner(big.NewInt(1600)))
+		assert.NoError(t, err)
+		assert.Equal(t, account.Address, sender)
+
+		seed := crypto.Keccak256([]byte("seed"))
+		index, proof, err := w.Evaluate(account, seed)
+		assert.NoError(t, err)
+		valid, err := crypto.VRFVerify(pk, seed, proof)
+		assert.NoError(t, err)
+		assert.True(t, valid)
+		expected, err := crypto.ProofToHash(pk, seed, proof)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, index)
+
+		assert.NoError(t, w.PaddingAddressNonce(fakeAddressInfoReader{nonce: 5}))
+		nonce, err := w.GetAddressNonce(account.Address)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(5), nonce)
+		assert.NoError(t, w.PaddingAddressNonce(fakeAddressInfoReader{err: errors.New("no state")}))
+		assert.NoError(t, w.SetAddressNonce(account.Address, 6))
+		nonce, _ = w.GetAddressNonce(account.Address)
+		assert.Equal(t, uint64(6), nonce)
+		assert.NoError(t, w.SelfDerive(DefaultBaseDerivationPath))
+
+		assert.NoError(t, w.Close())
+		_, err = w.SignHash(account, hash)
+		assert.Equal(t, accounts.ErrWalletNotOpen, err)
+	}
+
+	// the tx and the vote are told apart on the device
+	assert.Equal(t, []signKind{signKindMsg, signKindTx}, ledger.signed)
+	assert.Equal(t, []signKind{signKindMsg, signKindTx}, trezor.signed)
+}
+
+func TestHardWallet_Open(t *testing.T) {
+	ledger, trezor := newLedgerEmulator(), newTrezorEmulator("1234")
+	defer setTestEnumerator(ledger, trezor)()
+
+	w, _ := NewHardWallet(accounts.TrezorWallet)
+	assert.Equal(t, errTrezorPinNeeded, w.Open(testTrezorInfo.Path, "treasury", ""))
+	assert.Equal(t, errDeviceNotFound, w.Open(testLedgerInfo.Path, "treasury", ""))
+	w.SetPinPrompt(func() (string, error) { return trezor.pinPositions("1234"), nil })
+	assert.NoError(t, w.Open(testTrezorInfo.Path, "treasury", ""))
+	identifier, _ := w.GetWalletIdentifier()
+	assert.Equal(t, accounts.WalletIdentifier{WalletType: accounts.TrezorWallet, Path: testTrezorInfo.Path, WalletName: "treasury"}, identifier)
+
+	ledger.appOpen = false
+	w, _ = NewHardWallet(accounts.LedgerWallet)
+	assert.Equal(t, errLedgerAppNotOpen, w.Open("", "", ""))
+	status, _ := w.Status()
+	assert.Equal(t, accounts.Closed, status)
+}
+
+// the bft votes are signed on the device through the wallet signer
+func TestHardWallet_WalletSigner(t *testing.T) {
+	defer setTestEnumerator(newLedgerEmulator(), nil)()
+
+	w, _ := NewHardWallet(accounts.LedgerWallet)
+	assert.NoError(t, w.Open("", "", ""))
+	manager, err := accounts.NewWalletManager(fakeAddressInfoReader{}, w)
+	assert.NoError(t, err)
+
+	walletAccounts, _ := w.Accounts()
+	signer := accounts.MakeWalletSigner(walletAccounts[0].Address, manager)
+	hash := crypto.Keccak256([]byte("vote"))
+	sig, err := signer.SignHash(hash)
+	assert.NoError(t, err)
+	assert.NoError(t, signer.ValidSign(hash, crypto.FromECDSAPub(signer.PublicKey()), sig))
+	assert.NoError(t, w.Close())
+}
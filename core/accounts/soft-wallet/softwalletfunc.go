@@ -21,6 +21,7 @@ import (
 	"crypto/rand"
 	"encoding/binary"
 	"encoding/hex"
+	"github.com/dipperin/dipperin-core/common"
 	"github.com/dipperin/dipperin-core/common/util"
 	"github.com/dipperin/dipperin-core/core/accounts"
 	"github.com/dipperin/dipperin-core/third-party/crypto"
@@ -177,6 +178,68 @@ func GetAccountFromExtendedKey(keyData *ExtendedKey) (account accounts.Account,
 	return account, nil
 }
 
+//Find the private key of the address in the accounts derived from the mnemonic, used to sign offline without the wallet file
+func GetSKFromMnemonic(mnemonic, passPhrase string, address common.Address) (*ecdsa.PrivateKey, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passPhrase)
+	if err != nil {
+		return nil, err
+	}
+
+	walletInfo := NewHdWalletInfo()
+	walletInfo.Seed = seed
+	defer ClearSensitiveData(&walletInfo.Seed)
+
+	//the accounts of the wallet are derived on the default path one by one from the start index
+	for index := uint32(AddressIndexStartValue); index <= AddressIndexStartValue+SyncAccountNumber; index++ {
+		extKey, _, err := walletInfo.GenerateKeyFromSeedAndPath(DefaultDerivedPath, index)
+		if err != nil {
+			return nil, err
+		}
+
+		account, err := GetAccountFromExtendedKey(extKey)
+		if err != nil || account.Address != address {
+			ClearSensitiveData(extKey)
+			continue
+		}
+
+		privateKey, err := extKey.ECPrivKey()
+		ClearSensitiveData(extKey)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PrivateKey{PublicKey: privateKey.PublicKey, D: privateKey.D}, nil
+	}
+	return nil, accounts.ErrInvalidAddress
+}
+
+//Derive the first number accounts of the wallet restored from the mnemonic, used to prefund the accounts of the dev chain
+func GetAccountsFromMnemonic(mnemonic, passPhrase string, number int) ([]accounts.Account, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passPhrase)
+	if err != nil {
+		return nil, err
+	}
+
+	walletInfo := NewHdWalletInfo()
+	walletInfo.Seed = seed
+	defer ClearSensitiveData(&walletInfo.Seed)
+
+	result := make([]accounts.Account, 0, number)
+	for index := uint32(AddressIndexStartValue); len(result) < number; index++ {
+		extKey, _, err := walletInfo.GenerateKeyFromSeedAndPath(DefaultDerivedPath, index)
+		if err != nil {
+			return nil, err
+		}
+
+		account, err := GetAccountFromExtendedKey(extKey)
+		ClearSensitiveData(extKey)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, account)
+	}
+	return result, nil
+}
+
 //Encrypt wallet plaintext data based on wallet plaintext and derived encrypted key and mac key
 func EncryptWalletContent(walletPlain []byte, iv []byte, sysKey EncryptKey) (walletCipher WalletCipher, err error) {
 
@@ -19,6 +19,8 @@ package soft_wallet
 import (
 	"errors"
 	"github.com/dipperin/dipperin-core/core/accounts"
+	"github.com/dipperin/dipperin-core/third-party/crypto/cs-crypto"
+	"github.com/dipperin/dipperin-core/third-party/go-bip39"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -92,6 +94,50 @@ func TestGetAccountFromExtendedKey(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestGetSKFromMnemonic(t *testing.T) {
+	mnemonic, err := GenerateMnemonic(256)
+	assert.NoError(t, err)
+
+	walletInfo := NewHdWalletInfo()
+	walletInfo.Seed = bip39.NewSeed(mnemonic, "passphrase")
+	extKey, _, err := walletInfo.GenerateKeyFromSeedAndPath(DefaultDerivedPath, AddressIndexStartValue+2)
+	assert.NoError(t, err)
+	account, err := GetAccountFromExtendedKey(extKey)
+	assert.NoError(t, err)
+
+	sk, err := GetSKFromMnemonic(mnemonic, "passphrase", account.Address)
+	assert.NoError(t, err)
+	assert.Equal(t, account.Address, cs_crypto.GetNormalAddress(sk.PublicKey))
+
+	_, err = GetSKFromMnemonic(mnemonic, "", account.Address)
+	assert.Equal(t, accounts.ErrInvalidAddress, err)
+	_, err = GetSKFromMnemonic("invalid mnemonic", "passphrase", account.Address)
+	assert.Error(t, err)
+}
+
+func TestGetAccountsFromMnemonic(t *testing.T) {
+	mnemonic, err := GenerateMnemonic(256)
+	assert.NoError(t, err)
+
+	result, err := GetAccountsFromMnemonic(mnemonic, "passphrase", 3)
+	assert.NoError(t, err)
+	assert.Len(t, result, 3)
+
+	// the accounts are the ones derived by the wallet restored from the mnemonic
+	walletInfo := NewHdWalletInfo()
+	walletInfo.Seed = bip39.NewSeed(mnemonic, "passphrase")
+	for i := range result {
+		extKey, _, err := walletInfo.GenerateKeyFromSeedAndPath(DefaultDerivedPath, AddressIndexStartValue+uint32(i))
+		assert.NoError(t, err)
+		account, err := GetAccountFromExtendedKey(extKey)
+		assert.NoError(t, err)
+		assert.Equal(t, account, result[i])
+	}
+
+	_, err = GetAccountsFromMnemonic("invalid mnemonic", "passphrase", 3)
+	assert.Error(t, err)
+}
+
 func TestEncryptWalletContent(t *testing.T) {
 
 	cipher, err := EncryptWalletContent(testWalletPlain[:], testIv[:], encKey)
@@ -34,6 +34,34 @@ const (
 	TrezorWallet
 )
 
+var walletTypeNames = map[WalletType]string{
+	SoftWallet:   "SoftWallet",
+	LedgerWallet: "LedgerWallet",
+	TrezorWallet: "TrezorWallet",
+}
+
+func (t WalletType) String() string {
+	if name, ok := walletTypeNames[t]; ok {
+		return name
+	}
+	return "UnknownWallet"
+}
+
+//parse the wallet type name used by the command line
+func ParseWalletType(name string) (WalletType, error) {
+	for t, tName := range walletTypeNames {
+		if tName == name {
+			return t, nil
+		}
+	}
+	return 0, ErrInvalidWalletType
+}
+
+//the keys of the hardware wallet are kept in the device
+func (t WalletType) IsHardware() bool {
+	return t == LedgerWallet || t == TrezorWallet
+}
+
 //wallet status
 const (
 	Opened  = "Opened"
@@ -72,7 +72,7 @@ func NewWalletManager(getAddressInfo AddressInfoReader,wallets ...Wallet) (*Wall
 		if err !=nil{
 			return nil,err
 		}
-		if walletIdentifier.WalletType !=SoftWallet{
+		if walletIdentifier.WalletType !=SoftWallet && !walletIdentifier.WalletType.IsHardware(){
 			return nil, ErrNotSupportUsbWallet
 		}else {
 			tmpWallets = append(tmpWallets, tmpWallet)
@@ -99,7 +99,13 @@ func (manager *WalletManager)backend(){
 	log.Info("backend subscribe ManagerClose")
 	for {
 		select{
-			case walletEvent := <-manager.Event:
+			case walletEvent, ok := <-manager.Event:
+				//the manager is stopped before the backend subscribed ManagerClose
+				if !ok {
+					sub.Unsubscribe()
+					log.Info("Wallet manager backend return")
+					return
+				}
 				//new wallet event
 				manager.Lock.Lock()
 				if walletEvent.Type == WalletArrived{
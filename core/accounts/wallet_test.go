@@ -0,0 +1,40 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package accounts_test
+
+import (
+	"github.com/dipperin/dipperin-core/core/accounts"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseWalletType(t *testing.T) {
+	for _, walletType := range []accounts.WalletType{accounts.SoftWallet, accounts.LedgerWallet, accounts.TrezorWallet} {
+		result, err := accounts.ParseWalletType(walletType.String())
+		assert.NoError(t, err)
+		assert.Equal(t, walletType, result)
+	}
+	assert.Equal(t, "LedgerWallet", accounts.LedgerWallet.String())
+	assert.Equal(t, "UnknownWallet", accounts.WalletType(9).String())
+
+	_, err := accounts.ParseWalletType("PaperWallet")
+	assert.Equal(t, accounts.ErrInvalidWalletType, err)
+
+	assert.False(t, accounts.SoftWallet.IsHardware())
+	assert.True(t, accounts.LedgerWallet.IsHardware())
+	assert.True(t, accounts.TrezorWallet.IsHardware())
+}
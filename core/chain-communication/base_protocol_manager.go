@@ -19,6 +19,7 @@ package chain_communication
 
 import (
 	"errors"
+	"github.com/dipperin/dipperin-core/core/chain-config"
 	"github.com/dipperin/dipperin-core/common"
 	"github.com/dipperin/dipperin-core/third-party/log"
 	"github.com/dipperin/dipperin-core/third-party/log/pm_log"
@@ -131,6 +132,8 @@ type HandShakeData struct {
 	NodeName           string
 	// for pbft
 	RawUrl string
+	// fork schedule, the peer on an incompatible fork is rejected
+	Forks []chain_config.Fork `rlp:"tail"`
 }
 
 // for hand shake
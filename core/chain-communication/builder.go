@@ -140,10 +140,25 @@ func MakeCsProtocolManager(pmConfig *CsProtocolManagerConfig, txBConf *NewTxBroa
 		Pm:       pm,
 		PbftNode: pmConfig.PbftNode,
 		fetcher:  blockFetcher,
+
+		StateChain: pmConfig.StateChain,
+		FastSync:   pmConfig.FastSync && pmConfig.LightChain == nil,
+	})
+
+	// light client only syncs headers, but still serves the blocks
+	lightDownloader := MakeLightDownloader(&LightDownloaderConfig{
+		Chain:      pmConfig.Chain,
+		Pm:         pm,
+		LightChain: pmConfig.LightChain,
 	})
+	pm.registerCommunicationService(lightDownloader, lightDownloader)
 
 	//downloader.SetFetcher(bftOuterFetcher)
-	pm.registerCommunicationService(downloader, downloader)
+	if pmConfig.LightChain == nil {
+		pm.registerCommunicationService(downloader, downloader)
+	} else {
+		pm.registerCommunicationService(downloader, nil)
+	}
 
 	broadcastDelegate := &BroadcastDelegate{
 		newTxBroadcaster: newTxBroadcaster,
@@ -31,6 +31,16 @@ const (
 	NewBlockMsg        = 0x07
 	NewBlockByBloomMsg = 0x08
 
+	// light client sync
+	GetSuperBlockProofMsg = 0x05
+	SuperBlockProofMsg    = 0x06
+	GetHeadersMsg         = 0x09
+	HeadersMsg            = 0x0a
+
+	// fast state sync
+	GetNodeDataMsg = 0x0b
+	NodeDataMsg    = 0x0c
+
 	// finder verifier
 	GetVerifiersConnFromBootNode = 0x60
 	BootNodeVerifiersConn        = 0x61
@@ -69,7 +79,11 @@ var (
 )
 
 const (
-	MaxBlockFetch = 16
+	MaxBlockFetch  = 16
+	MaxHeaderFetch = 192
+	MaxStateFetch  = 384
+	// the headers of the light client proof suffix
+	LightProofSuffixLength = 10
 )
 
 var totalVerifierBootNode int
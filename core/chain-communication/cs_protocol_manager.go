@@ -54,6 +54,12 @@ type CsProtocolManagerConfig struct {
 	VerifiersReader VerifiersReader
 	PbftNode        PbftNode
 	MsgSigner       PbftSigner
+	// sync as light client if set
+	LightChain LightChain
+	// serve the state trie nodes if set
+	StateChain StateChain
+	// sync the state of a recent pivot block instead of processing all the blocks
+	FastSync bool
 }
 
 /*
@@ -804,9 +810,9 @@ func (pm *CsProtocolManager) HandShake(p PmAbstractPeer) error {
 	pbftSigner := pm.MsgSigner
 
 	statusDataChan := make(chan *StatusData)
+	curB := chainReader.CurrentBlock()
 
 	go func() {
-		curB := chainReader.CurrentBlock()
 		//log.Info("send hand shake msg", "cur block", curB.Number())
 		sData := StatusData{
 			HandShakeData: HandShakeData{
@@ -819,6 +825,7 @@ func (pm *CsProtocolManager) HandShake(p PmAbstractPeer) error {
 				CurrentBlock:       curB.Hash(),
 				GenesisBlock:       genesisBlock.Hash(),
 				RawUrl:             pm.P2PServer.Self().String(),
+				Forks:              chainConf.ForkList(),
 			},
 			//NodeType:
 		}
@@ -882,6 +889,15 @@ func (pm *CsProtocolManager) HandShake(p PmAbstractPeer) error {
 			return errors.New("cs protocol version not match")
 		}
 
+		head := curB.Number()
+		if remoteStatus.CurrentBlockHeight > head {
+			head = remoteStatus.CurrentBlockHeight
+		}
+		if err := chainConf.CheckForkCompatible(remoteStatus.Forks, head); err != nil {
+			log.Warn("fork schedule not compatible", "remote", remoteStatus.NodeName, "err", err)
+			return errors.New("fork schedule not compatible")
+		}
+
 		// If the other party does not sign, they will get an empty address.
 		verifierAddress := remoteStatus.Sender()
 		p.SetRemoteVerifierAddress(verifierAddress)
@@ -1015,10 +1031,16 @@ func (pm *CsProtocolManager) IsSync() bool {
 
 	_, bestPeerHeight := bestPeer.GetHead()
 
+	// the light node only syncs the headers
+	currentNumber := currentBlock.Number()
+	if pm.LightChain != nil {
+		currentNumber = pm.LightChain.CurrentHeader().Number
+	}
+
 	//log.Info("the currentBlock.Number is:","number",currentBlock.Number())
 	//log.Info("the bestPeerHeight is:","bestPeerHeight",bestPeerHeight)
 	// if peer current block number + 10 > best peer height , node is sync, but return false
-	if currentBlock.Number()+10 >= bestPeerHeight {
+	if currentNumber+10 >= bestPeerHeight {
 		return false
 	}
 
@@ -1485,6 +1485,7 @@ func TestCsProtocolManager_handle2(t *testing.T) {
 		NodeType:           chain_config.NodeTypeOfVerifier,
 		NodeName:           "test",
 		RawUrl:             n.String(),
+		Forks:              pm.ChainConfig.ForkList(),
 	}
 
 	statusData := &StatusData{HandShakeData: hsData}
@@ -1609,6 +1610,7 @@ func TestCsProtocolManager_handle3(t *testing.T) {
 		NodeType:           chain_config.NodeTypeOfVerifier,
 		NodeName:           "test",
 		RawUrl:             n.String(),
+		Forks:              pm.ChainConfig.ForkList(),
 	}
 
 	statusData := &StatusData{HandShakeData: hsData}
@@ -2227,6 +2229,7 @@ func TestCsProtocolManager_handShake4(t *testing.T) {
 		NodeType:           chain_config.NodeTypeOfVerifier,
 		NodeName:           "test",
 		RawUrl:             n.String(),
+		Forks:              pm.ChainConfig.ForkList(),
 	}
 
 	statusData := &StatusData{HandShakeData: hsData}
@@ -2269,6 +2272,95 @@ func TestCsProtocolManager_handShake4(t *testing.T) {
 	time.Sleep(600 * time.Millisecond)
 }
 
+func TestCsProtocolManager_handShake5(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mChain := NewMockChain(ctrl)
+	mNodeConf := NewMockNodeConf(ctrl)
+	mSigner := NewMockPbftSigner(ctrl)
+	mP2PServer := NewMockP2PServer(ctrl)
+
+	mPeer := NewMockPmAbstractPeer(ctrl)
+
+	pm := &CsProtocolManager{
+		CsProtocolManagerConfig: &CsProtocolManagerConfig{
+			ChainConfig: *chain_config.GetChainConfig(),
+			Chain:       mChain,
+			NodeConf:    mNodeConf,
+			MsgSigner:   mSigner,
+			P2PServer:   mP2PServer,
+		},
+	}
+
+	var pyRecord, _ = hex.DecodeString("f884b8407098ad865b00a582051940cb9cf36836572411a47278783077011599ed5cd16b76f2635f4e234738f30813a89eb9137e3e3df5266e3a1f11df72ecf1145ccb9c01826964827634826970847f00000189736563703235366b31a103ca634cae0d49acb401d8a4c6b6fe8c55b70d115bf400769cc1400f3258cd31388375647082765f")
+
+	var r enr.Record
+	if err := rlp.DecodeBytes(pyRecord, &r); err != nil {
+		t.Fatalf("can't decode: %v", err)
+	}
+	n, err := enode.New(enode.ValidSchemes, &r)
+	if err != nil {
+		t.Fatalf("can't verify record: %v", err)
+	}
+
+	block := model.NewBlock(model.NewHeader(11, 101, common.HexToHash("ss"), common.HexToHash("fdfs"), common.StringToDiff("0x22"), big.NewInt(111), common.StringToAddress("fdsfds"), common.EncodeNonce(33)), nil, nil)
+
+	// case 2
+	mChain.EXPECT().GetBlockByNumber(gomock.Eq(uint64(0))).Return(block)
+
+	//  send
+	mChain.EXPECT().CurrentBlock().Return(block)
+	mNodeConf.EXPECT().GetNodeType().Return(chain_config.NodeTypeOfNormal).Times(2)
+	mNodeConf.EXPECT().GetNodeName().Return("dsadsad")
+	mP2PServer.EXPECT().Self().Return(n)
+	mPeer.EXPECT().SendMsg(gomock.Any(), gomock.Any()).Return(nil)
+
+	hsData := HandShakeData{
+		ProtocolVersion:    chain_config.CsProtocolVersion,
+		ChainID:            big.NewInt(2),
+		NetworkId:          pm.ChainConfig.NetworkID,
+		CurrentBlock:       common.HexToHash("aaa"),
+		CurrentBlockHeight: 64,
+		GenesisBlock:       block.Hash(),
+		NodeType:           chain_config.NodeTypeOfVerifier,
+		NodeName:           "test",
+		RawUrl:             n.String(),
+		Forks:              []chain_config.Fork{{Name: chain_config.ForkDelegation, Block: 10}},
+	}
+
+	statusData := &StatusData{HandShakeData: hsData}
+
+	hash := statusData.DataHash()
+
+	assert.Equal(t, true, !hash.IsEmpty())
+
+	account := tests.AccFactory.GenAccount()
+
+	sign, err := account.SignHash(statusData.DataHash().Bytes())
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, true, len(sign) > 0)
+
+	statusData.Sign = sign
+
+	statusData.PubKey = crypto.CompressPubkey(&account.Pk.PublicKey)
+
+	size, r1, err := rlp.EncodeToReader(statusData)
+	assert.NoError(t, err)
+
+	msg := p2p.Msg{Code: StatusMsg, Size: uint32(size), Payload: r1}
+
+	// read
+	mPeer.EXPECT().ReadMsg().Return(msg, nil)
+
+	// the remote activates the fork later than the local and doesn't support the other forks
+	assert.EqualError(t, pm.HandShake(mPeer), "fork schedule not compatible")
+
+	time.Sleep(600 * time.Millisecond)
+}
+
 func TestCsProtocolManager_checkAndHandleVerBootNodes(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -2471,6 +2563,17 @@ func TestCsProtocolManager_IsSync(t *testing.T) {
 	mockPeerSetVerifierBootNode.EXPECT().BestPeer().Return(nil)
 	assert.Equal(t, true, pm.IsSync())
 
+	// case 5 the light node compares the head of its header chain
+	lc := NewLightHeaderChain(block.Header().(*model.Header))
+	lc.headers = []*model.Header{model.NewHeader(11, 495, common.HexToHash("ss"), common.HexToHash("fdfs"), common.StringToDiff("0x22"), big.NewInt(111), common.StringToAddress("fdsfds"), common.EncodeNonce(33))}
+	pm.LightChain = lc
+	mockPeer.EXPECT().GetHead().Return(common.HexToHash("sad"), uint64(500))
+	mockChain.EXPECT().CurrentBlock().Return(block)
+	mockPeerSetBasePeers.EXPECT().BestPeer().Return(mockPeer)
+	mockPeerSetCurrentVerifierPeers.EXPECT().BestPeer().Return(nil)
+	mockPeerSetnNextVerifierPeers.EXPECT().BestPeer().Return(nil)
+	mockPeerSetVerifierBootNode.EXPECT().BestPeer().Return(nil)
+	assert.Equal(t, false, pm.IsSync())
 }
 
 func TestCsProtocolManager_PrintPeerHealthCheck(t *testing.T) {
@@ -0,0 +1,363 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chain_communication
+
+import (
+	"errors"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/g-error"
+	"github.com/dipperin/dipperin-core/common/util"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/third-party/crypto/cs-crypto"
+	"github.com/dipperin/dipperin-core/third-party/log"
+	"github.com/dipperin/dipperin-core/third-party/p2p"
+	"github.com/dipperin/dipperin-core/third-party/trie"
+	"time"
+)
+
+/*
+Fast sync brings up a new node without processing all the blocks
+* the blocks until the pivot block are saved without processing, each one must be committed by the verifiers of its slot
+* the register trie of every saved block and the account tries of the first and the last block of every slot are downloaded
+  node by node, so the verifiers of the next slots are elected from the genesis with the tries of the checked blocks only,
+  and the performance of the verifiers is counted from the first block of the slot when the next change point is imported
+* the account and contract tries of the pivot are downloaded with the pre-images of the contract data keys,
+  then the pivot becomes the current block and the blocks are imported normally
+*/
+
+// the pivot is fastSyncPivotDistance blocks below the head of the best peer, so that it has been committed
+const fastSyncPivotDistance = 64
+
+var (
+	errStateNotFound   = errors.New("remote peer doesn't have the requested state")
+	errNoBlockReturned = errors.New("remote peer returns no block")
+)
+
+type nodeDataPack struct {
+	peerID string
+	data   [][]byte
+}
+
+// serves the state trie nodes and the secure key pre-images by their hashes, the unknown ones are skipped
+func (fd *NewPbftDownloader) onGetNodeData(msg p2p.Msg, p PmAbstractPeer) error {
+	var hashes []common.Hash
+	if err := msg.Decode(&hashes); err != nil {
+		return errors.New("decode error, invalid message")
+	}
+	if fd.StateChain == nil {
+		return nil
+	}
+
+	trieDB := fd.StateChain.GetStateStorage().TrieDB()
+	var data [][]byte
+	for i := 0; i < len(hashes) && i < MaxStateFetch; i++ {
+		if node, err := trieDB.Node(hashes[i]); err == nil {
+			data = append(data, node)
+		} else if preimage, err := trieDB.Preimage(hashes[i]); err == nil {
+			data = append(data, preimage)
+		}
+	}
+	log.Debug("downloader send node data to remote", "remote node", p.NodeName(), "request", len(hashes), "nodes", len(data))
+	return p.SendMsg(NodeDataMsg, data)
+}
+
+func (fd *NewPbftDownloader) onNodeData(msg p2p.Msg, p PmAbstractPeer) error {
+	var data [][]byte
+	if err := msg.Decode(&data); err != nil {
+		log.Error("downloader decode node data failed", "err", err)
+		return err
+	}
+
+	select {
+	case <-fd.quitCh:
+		return quitErr
+	case fd.nodeDataC <- &nodeDataPack{peerID: p.ID(), data: data}:
+	}
+	return nil
+}
+
+// the fast sync only starts from the genesis
+func (fd *NewPbftDownloader) needFastSync(bestPeer PmAbstractPeer) bool {
+	if !fd.FastSync || fd.StateChain == nil || fd.Chain.CurrentBlock().Number() != 0 {
+		return false
+	}
+	_, height := bestPeer.GetHead()
+	return height > fastSyncPivotDistance
+}
+
+func (fd *NewPbftDownloader) fastSync(bestPeer PmAbstractPeer) error {
+	_, height := bestPeer.GetHead()
+	pivot := height - fastSyncPivotDistance
+	log.Info("fast sync start", "pivot", pivot, "remote node", bestPeer.NodeName())
+
+	if err := fd.fetchSyncedBlocks(bestPeer, pivot); err != nil {
+		return err
+	}
+	pivotBlock := fd.StateChain.GetBlockByNumber(pivot)
+	if pivotBlock == nil {
+		return g_error.ErrBlockNotFound
+	}
+
+	// the 32 bytes leaves of the account trie may be the roots of the contract data tries
+	var candidates []common.Hash
+	collect := func(leaf []byte, parent common.Hash) error {
+		if len(leaf) == common.HashLength {
+			candidates = append(candidates, common.BytesToHash(leaf))
+		}
+		return nil
+	}
+	if err := fd.syncTries(bestPeer, []common.Hash{pivotBlock.StateRoot()}, collect); err != nil {
+		return err
+	}
+	if err := fd.syncContractTries(bestPeer, candidates); err != nil {
+		return err
+	}
+
+	if err := fd.StateChain.SetSyncedHead(pivotBlock, fd.StateChain.GetSeenCommit(pivot)); err != nil {
+		return err
+	}
+	log.Info("fast sync finished", "pivot", pivot, "remote node", bestPeer.NodeName())
+	return nil
+}
+
+// downloads the blocks until the pivot and saves them without processing, the next blocks are checked with
+// the slot in the register trie of the block and the verifiers elected with the tries of the last block of the slots.
+// The account tries of the first and the last block of the slots are kept, the same as the state pruning does
+func (fd *NewPbftDownloader) fetchSyncedBlocks(bestPeer PmAbstractPeer, pivot uint64) error {
+	next := uint64(1)
+	preChangePoint := false
+	for next <= pivot {
+		blocks, err := fd.fetchBlockPack(bestPeer, next)
+		if err != nil {
+			return err
+		}
+		if len(blocks) == 0 {
+			return errNoBlockReturned
+		}
+
+		for _, b := range blocks {
+			// the filtered blocks are requested again
+			if next > pivot || b.Block.Number() != next {
+				break
+			}
+			commits := make([]model.AbstractVerification, len(b.SeenCommit))
+			util.InterfaceSliceCopy(commits, b.SeenCommit)
+			if err = fd.StateChain.SaveSyncedBlock(b.Block, commits); err != nil {
+				return err
+			}
+
+			roots := []common.Hash{b.Block.GetRegisterRoot()}
+			changePoint := fd.StateChain.IsChangePoint(b.Block, false)
+			if next == 1 || preChangePoint || changePoint {
+				roots = append(roots, b.Block.StateRoot())
+			}
+			if err = fd.syncTries(bestPeer, roots, nil); err != nil {
+				return err
+			}
+			preChangePoint = changePoint
+			next++
+		}
+		log.Info("fast sync saved blocks", "next", next, "pivot", pivot)
+	}
+	return nil
+}
+
+func (fd *NewPbftDownloader) fetchBlockPack(bestPeer PmAbstractPeer, origin uint64) ([]*catchupRlp, error) {
+	go func() {
+		if err := bestPeer.SendMsg(GetBlocksMsg, &getBlockHeaders{OriginHeight: origin, Amount: MaxBlockFetch}); err != nil {
+			log.Warn("send get blocks msg failed", "err", err)
+		}
+	}()
+
+	timeoutTimer := time.NewTimer(fetchBlockTimeout)
+	defer timeoutTimer.Stop()
+	for {
+		select {
+		case packet := <-fd.blockC:
+			if packet.peerID != bestPeer.ID() {
+				log.Warn("Received blocks from incorrect peer", "peer", packet.peerID)
+				break
+			}
+			return packet.blocks, nil
+
+		case <-timeoutTimer.C:
+			return nil, errors.New("waiting for blocks timed out")
+
+		case <-fd.quitCh:
+			return nil, quitErr
+		}
+	}
+}
+
+// the candidates unknown by the peer aren't contract data tries
+func (fd *NewPbftDownloader) syncContractTries(bestPeer PmAbstractPeer, candidates []common.Hash) error {
+	var roots []common.Hash
+	for len(candidates) > 0 {
+		size := len(candidates)
+		if size > MaxStateFetch {
+			size = MaxStateFetch
+		}
+		data, err := fd.fetchNodeData(bestPeer, candidates[:size])
+		if err != nil {
+			return err
+		}
+		for _, node := range data {
+			roots = append(roots, cs_crypto.Keccak256Hash(node))
+		}
+		candidates = candidates[size:]
+	}
+
+	if len(roots) == 0 {
+		return nil
+	}
+	if err := fd.syncTries(bestPeer, roots, nil); err != nil {
+		return err
+	}
+	return fd.syncPreimages(bestPeer, roots)
+}
+
+// the contract data is read with the pre-images of the hashed keys of its trie, they are downloaded after the trie
+func (fd *NewPbftDownloader) syncPreimages(bestPeer PmAbstractPeer, roots []common.Hash) error {
+	storage := fd.StateChain.GetStateStorage()
+	var hashes []common.Hash
+	for _, root := range roots {
+		tr, err := storage.OpenTrie(root)
+		if err != nil {
+			return err
+		}
+		it := trie.NewIterator(tr.NodeIterator(nil))
+		for it.Next() {
+			hashes = append(hashes, common.BytesToHash(it.Key))
+		}
+	}
+
+	diskDB := storage.DiskDB()
+	for len(hashes) > 0 {
+		size := len(hashes)
+		if size > MaxStateFetch {
+			size = MaxStateFetch
+		}
+		data, err := fd.fetchNodeData(bestPeer, hashes[:size])
+		if err != nil {
+			return err
+		}
+
+		delivered := make(map[common.Hash]bool, len(data))
+		batch := diskDB.NewBatch()
+		for _, preimage := range data {
+			hash := cs_crypto.Keccak256Hash(preimage)
+			if err = trie.WritePreimage(batch, hash, preimage); err != nil {
+				return err
+			}
+			delivered[hash] = true
+		}
+		if len(delivered) == 0 {
+			return errStateNotFound
+		}
+		if err = batch.Write(); err != nil {
+			return err
+		}
+
+		// the pre-images not delivered are requested again
+		var retry []common.Hash
+		for _, hash := range hashes[:size] {
+			if !delivered[hash] {
+				retry = append(retry, hash)
+			}
+		}
+		hashes = append(retry, hashes[size:]...)
+	}
+	return nil
+}
+
+// downloads the tries of the roots node by node from the best peer, the nodes in the local db are skipped
+func (fd *NewPbftDownloader) syncTries(bestPeer PmAbstractPeer, roots []common.Hash, callback trie.LeafCallback) error {
+	diskDB := fd.StateChain.GetStateStorage().DiskDB()
+	sched := trie.NewSync(roots[0], diskDB, callback)
+	for _, root := range roots[1:] {
+		sched.AddSubTrie(root, 0, common.Hash{}, callback)
+	}
+
+	// the requested nodes not delivered are requested again
+	var retry []common.Hash
+	for sched.Pending() > 0 {
+		hashes := retry
+		if size := MaxStateFetch - len(retry); size > 0 {
+			hashes = append(hashes, sched.Missing(size)...)
+		}
+		data, err := fd.fetchNodeData(bestPeer, hashes)
+		if err != nil {
+			return err
+		}
+
+		delivered := make(map[common.Hash]bool, len(data))
+		for _, node := range data {
+			hash := cs_crypto.Keccak256Hash(node)
+			if _, _, err = sched.Process([]trie.SyncResult{{Hash: hash, Data: node}}); err != nil && err != trie.ErrNotRequested && err != trie.ErrAlreadyProcessed {
+				return err
+			}
+			delivered[hash] = true
+		}
+		// the peer may have pruned the state
+		if len(delivered) == 0 {
+			return errStateNotFound
+		}
+
+		retry = nil
+		for _, hash := range hashes {
+			if !delivered[hash] {
+				retry = append(retry, hash)
+			}
+		}
+
+		batch := diskDB.NewBatch()
+		if _, err = sched.Commit(batch); err != nil {
+			return err
+		}
+		if err = batch.Write(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (fd *NewPbftDownloader) fetchNodeData(bestPeer PmAbstractPeer, hashes []common.Hash) ([][]byte, error) {
+	go func() {
+		if err := bestPeer.SendMsg(GetNodeDataMsg, hashes); err != nil {
+			log.Warn("send get node data msg failed", "err", err)
+		}
+	}()
+
+	timeoutTimer := time.NewTimer(fetchBlockTimeout)
+	defer timeoutTimer.Stop()
+	for {
+		select {
+		case pack := <-fd.nodeDataC:
+			if pack.peerID != bestPeer.ID() {
+				log.Warn("Received node data from incorrect peer", "peer", pack.peerID)
+				break
+			}
+			return pack.data, nil
+
+		case <-timeoutTimer.C:
+			return nil, errors.New("waiting for node data timed out")
+
+		case <-fd.quitCh:
+			return nil, quitErr
+		}
+	}
+}
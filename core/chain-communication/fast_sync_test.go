@@ -0,0 +1,322 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chain_communication
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/util"
+	"github.com/dipperin/dipperin-core/core/chain-config"
+	"github.com/dipperin/dipperin-core/core/chain/state-processor"
+	"github.com/dipperin/dipperin-core/core/cs-chain"
+	"github.com/dipperin/dipperin-core/core/cs-chain/chain-state"
+	"github.com/dipperin/dipperin-core/core/cs-chain/chain-writer"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/tests"
+	"github.com/dipperin/dipperin-core/third-party/crypto/cs-crypto"
+	"github.com/dipperin/dipperin-core/third-party/p2p"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeStateChain struct {
+	*MockChain
+	storage     state_processor.StateStorage
+	changePoint uint64
+	saved       []uint64
+}
+
+func (c *fakeStateChain) GetStateStorage() state_processor.StateStorage {
+	return c.storage
+}
+
+func (c *fakeStateChain) SaveSyncedBlock(block model.AbstractBlock, seenCommits []model.AbstractVerification) error {
+	c.saved = append(c.saved, block.Number())
+	return nil
+}
+
+func (c *fakeStateChain) SetSyncedHead(block model.AbstractBlock, seenCommits []model.AbstractVerification) error {
+	return nil
+}
+
+func (c *fakeStateChain) IsChangePoint(block model.AbstractBlock, isProcessPackageBlock bool) bool {
+	return block.Number() == c.changePoint
+}
+
+type fakeCommitDB struct {
+	commits map[uint64][]model.AbstractVerification
+}
+
+func (db *fakeCommitDB) GetSeenCommits(blockHeight uint64, blockHash common.Hash) ([]model.AbstractVerification, error) {
+	return db.commits[blockHeight], nil
+}
+
+func (db *fakeCommitDB) SaveSeenCommits(blockHeight uint64, blockHash common.Hash, commits []model.AbstractVerification) error {
+	db.commits[blockHeight] = commits
+	return nil
+}
+
+type fakeResetPool struct{}
+
+func (p *fakeResetPool) Reset(oldHead, newHead *model.Header) {}
+
+// creates a chain service with the test genesis on a memory db
+func newTestChainService() (*cs_chain.CsChainService, *tests.GenesisEnv) {
+	f := chain_writer.NewChainWriterFactory()
+	chainState := chain_state.NewChainState(&chain_state.ChainStateConfig{
+		DataDir:       "",
+		WriterFactory: f,
+		ChainConfig:   chain_config.GetChainConfig(),
+	})
+	env := tests.NewGenesisEnv(chainState.GetChainDB(), chainState.GetStateStorage(), nil)
+
+	ccs := cs_chain.NewCsChainService(&cs_chain.CsChainServiceConfig{
+		CacheDB: &fakeCommitDB{commits: map[uint64][]model.AbstractVerification{}},
+		TxPool:  &fakeResetPool{},
+	}, chainState)
+	f.SetChain(ccs.CacheChainState)
+	return ccs, env
+}
+
+// creates a storage with a committed trie of n accounts
+func createTestStateStorage(t *testing.T, n int) (state_processor.StateStorage, common.Hash) {
+	storage := state_processor.NewStateStorageWithCache(ethdb.NewMemDatabase())
+	return storage, commitTestTrie(t, storage, "key", n)
+}
+
+func commitTestTrie(t *testing.T, storage state_processor.StateStorage, prefix string, n int) common.Hash {
+	tr, err := storage.OpenTrie(common.Hash{})
+	assert.NoError(t, err)
+	for i := 0; i < n; i++ {
+		assert.NoError(t, tr.TryUpdate([]byte(fmt.Sprintf("%s-%d", prefix, i)), []byte(fmt.Sprintf("value-%d", i))))
+	}
+	root, err := tr.Commit(nil)
+	assert.NoError(t, err)
+	assert.NoError(t, storage.TrieDB().Commit(root, false))
+	return root
+}
+
+// the peer serves the blocks from the requested height and the nodes and pre-images of its storage
+func newTestSyncPeer(ctrl *gomock.Controller, fd *NewPbftDownloader, remote state_processor.StateStorage, blocks []*catchupRlp) *MockPmAbstractPeer {
+	mockPeer := NewMockPmAbstractPeer(ctrl)
+	mockPeer.EXPECT().ID().Return("1").AnyTimes()
+	mockPeer.EXPECT().SendMsg(uint64(GetNodeDataMsg), gomock.Any()).DoAndReturn(func(msgCode uint64, msg interface{}) error {
+		var data [][]byte
+		for _, hash := range msg.([]common.Hash) {
+			if node, err := remote.TrieDB().Node(hash); err == nil {
+				data = append(data, node)
+			} else if preimage, err := remote.TrieDB().Preimage(hash); err == nil {
+				data = append(data, preimage)
+			}
+		}
+		fd.nodeDataC <- &nodeDataPack{peerID: "1", data: data}
+		return nil
+	}).AnyTimes()
+	mockPeer.EXPECT().SendMsg(uint64(GetBlocksMsg), gomock.Any()).DoAndReturn(func(msgCode uint64, msg interface{}) error {
+		var pack []*catchupRlp
+		for _, b := range blocks {
+			if b.Block.Number() >= msg.(*getBlockHeaders).OriginHeight {
+				pack = append(pack, b)
+			}
+		}
+		fd.blockC <- &npbPack{peerID: "1", blocks: pack}
+		return nil
+	}).AnyTimes()
+	return mockPeer
+}
+
+func TestNewPbftDownloader_FastSyncMsgHandlers(t *testing.T) {
+	handles := MakeNewPbftDownloader(&NewPbftDownloaderConfig{}).MsgHandlers()
+
+	assert.NotNil(t, handles[GetNodeDataMsg])
+	assert.NotNil(t, handles[NodeDataMsg])
+}
+
+func TestNewPbftDownloader_onGetNodeData(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage, root := createTestStateStorage(t, 10)
+	mockPeer := NewMockPmAbstractPeer(ctrl)
+	mockPeer.EXPECT().NodeName().Return("test").AnyTimes()
+
+	// no state chain
+	pbftDownloader := MakeNewPbftDownloader(&NewPbftDownloaderConfig{})
+	payload, _ := rlp.EncodeToBytes([]common.Hash{root})
+	assert.NoError(t, pbftDownloader.onGetNodeData(p2p.Msg{Payload: bytes.NewReader(payload)}, mockPeer))
+
+	pbftDownloader = MakeNewPbftDownloader(&NewPbftDownloaderConfig{StateChain: &fakeStateChain{storage: storage}})
+	assert.Error(t, pbftDownloader.onGetNodeData(p2p.Msg{Payload: bytes.NewReader([]byte{})}, mockPeer))
+
+	// the unknown hash is skipped
+	rootNode, err := storage.TrieDB().Node(root)
+	assert.NoError(t, err)
+	mockPeer.EXPECT().SendMsg(uint64(NodeDataMsg), [][]byte{rootNode}).Return(nil).Times(1)
+	payload, _ = rlp.EncodeToBytes([]common.Hash{root, common.HexToHash("0x123")})
+	assert.NoError(t, pbftDownloader.onGetNodeData(p2p.Msg{Payload: bytes.NewReader(payload)}, mockPeer))
+
+	// the pre-images of the secure trie keys are served too
+	mockPeer.EXPECT().SendMsg(uint64(NodeDataMsg), [][]byte{[]byte("key-0")}).Return(nil).Times(1)
+	payload, _ = rlp.EncodeToBytes([]common.Hash{cs_crypto.Keccak256Hash([]byte("key-0"))})
+	assert.NoError(t, pbftDownloader.onGetNodeData(p2p.Msg{Payload: bytes.NewReader(payload)}, mockPeer))
+}
+
+func TestNewPbftDownloader_onNodeData(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pbftDownloader := MakeNewPbftDownloader(&NewPbftDownloaderConfig{})
+	mockPeer := NewMockPmAbstractPeer(ctrl)
+	mockPeer.EXPECT().NodeName().Return("test").AnyTimes()
+	mockPeer.EXPECT().ID().Return("1").AnyTimes()
+
+	assert.Error(t, pbftDownloader.onNodeData(p2p.Msg{Payload: bytes.NewReader([]byte{})}, mockPeer))
+
+	payload, _ := rlp.EncodeToBytes([][]byte{{1, 2, 3}})
+	go func() {
+		assert.NoError(t, pbftDownloader.onNodeData(p2p.Msg{Payload: bytes.NewReader(payload)}, mockPeer))
+	}()
+
+	select {
+	case pack := <-pbftDownloader.nodeDataC:
+		assert.Equal(t, "1", pack.peerID)
+		assert.Equal(t, [][]byte{{1, 2, 3}}, pack.data)
+	case <-time.After(time.Second):
+		t.Fatal("node data not delivered")
+	}
+}
+
+func TestNewPbftDownloader_syncTries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	remote, root := createTestStateStorage(t, 200)
+	local := state_processor.NewStateStorageWithCache(ethdb.NewMemDatabase())
+	pbftDownloader := MakeNewPbftDownloader(&NewPbftDownloaderConfig{StateChain: &fakeStateChain{storage: local}})
+
+	mockPeer := newTestSyncPeer(ctrl, pbftDownloader, remote, nil)
+
+	var leaves int
+	assert.NoError(t, pbftDownloader.syncTries(mockPeer, []common.Hash{root}, func(leaf []byte, parent common.Hash) error {
+		leaves++
+		return nil
+	}))
+	assert.Equal(t, 200, leaves)
+
+	tr, err := local.OpenTrie(root)
+	assert.NoError(t, err)
+	value, err := tr.TryGet([]byte("key-100"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value-100"), value)
+
+	// the peer doesn't have the state
+	assert.Equal(t, errStateNotFound, pbftDownloader.syncTries(mockPeer, []common.Hash{cs_crypto.Keccak256Hash([]byte("unknown"))}, nil))
+}
+
+func TestNewPbftDownloader_fetchSyncedBlocks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	remote, registerRoot := createTestStateStorage(t, 10)
+	var stateRoots []common.Hash
+	var blocks []*catchupRlp
+	for i := uint64(1); i <= 5; i++ {
+		block := model.NewBlock(model.NewHeader(1, i, common.Hash{}, common.Hash{}, common.StringToDiff("0x22"), big.NewInt(111), common.Address{}, common.EncodeNonce(33)), nil, nil)
+		block.SetRegisterRoot(registerRoot)
+		stateRoots = append(stateRoots, commitTestTrie(t, remote, fmt.Sprintf("state%d", i), 20))
+		block.SetStateRoot(stateRoots[i-1])
+		blocks = append(blocks, &catchupRlp{Block: block})
+	}
+
+	local := state_processor.NewStateStorageWithCache(ethdb.NewMemDatabase())
+	chain := &fakeStateChain{storage: local, changePoint: 3}
+	pbftDownloader := MakeNewPbftDownloader(&NewPbftDownloaderConfig{StateChain: chain})
+	mockPeer := newTestSyncPeer(ctrl, pbftDownloader, remote, blocks)
+
+	assert.NoError(t, pbftDownloader.fetchSyncedBlocks(mockPeer, 5))
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5}, chain.saved)
+
+	// the register tries of all the blocks and the states of the first and the last block of the slots are synced
+	_, err := local.OpenTrie(registerRoot)
+	assert.NoError(t, err)
+	for i, root := range stateRoots {
+		_, err = local.OpenTrie(root)
+		if number := i + 1; number == 1 || number == 3 || number == 4 {
+			assert.NoError(t, err)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestNewPbftDownloader_fastSync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	model.IgnoreDifficultyValidation = true
+	conf := chain_config.GetChainConfig()
+	slotSize, verifierNumber := conf.SlotSize, conf.VerifierNumber
+	conf.SlotSize = 3
+	conf.VerifierNumber = 3
+	defer func() {
+		model.IgnoreDifficultyValidation = false
+		conf.SlotSize = slotSize
+		conf.VerifierNumber = verifierNumber
+	}()
+
+	// the change points are 2, 5, 8, 11, 14..., the pivot 13 is in the slot started by block 12
+	remote, env := newTestChainService()
+	builder := &tests.BlockBuilder{ChainState: remote.ChainState, PreBlock: remote.CurrentBlock(), MinerPk: env.Miner().Pk}
+	var blocks []*catchupRlp
+	for i := 0; i < fastSyncPivotDistance+13; i++ {
+		block := builder.Build()
+		commits := env.VoteBlock(conf.VerifierNumber, 0, block)
+		assert.NoError(t, remote.SaveBlock(block, commits))
+		builder.SetPreBlock(block)
+		builder.SetVerifivations(commits)
+
+		votes := make([]*model.VoteMsg, len(commits))
+		util.InterfaceSliceCopy(votes, commits)
+		blocks = append(blocks, &catchupRlp{Block: block.(*model.Block), SeenCommit: votes})
+	}
+	assert.Equal(t, uint64(fastSyncPivotDistance+13), remote.CurrentBlock().Number())
+
+	local, _ := newTestChainService()
+	pbftDownloader := MakeNewPbftDownloader(&NewPbftDownloaderConfig{StateChain: local})
+	mockPeer := newTestSyncPeer(ctrl, pbftDownloader, remote.GetStateStorage(), blocks)
+	mockPeer.EXPECT().GetHead().Return(remote.CurrentBlock().Hash(), remote.CurrentBlock().Number()).AnyTimes()
+	mockPeer.EXPECT().NodeName().Return("remote").AnyTimes()
+
+	assert.NoError(t, pbftDownloader.fastSync(mockPeer))
+	assert.Equal(t, uint64(13), local.CurrentBlock().Number())
+
+	// the performance at the first change point after the pivot is counted from the synced state of block 12
+	for _, b := range blocks[13:] {
+		commits := make([]model.AbstractVerification, len(b.SeenCommit))
+		util.InterfaceSliceCopy(commits, b.SeenCommit)
+		assert.NoError(t, local.SaveBlock(b.Block, commits))
+	}
+	assert.Equal(t, remote.CurrentBlock().Hash(), local.CurrentBlock().Hash())
+	assert.Equal(t, remote.CurrentBlock().StateRoot(), local.CurrentBlock().StateRoot())
+}
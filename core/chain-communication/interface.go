@@ -26,6 +26,7 @@ import (
 	"github.com/dipperin/dipperin-core/third-party/p2p"
 	"crypto/ecdsa"
 	"github.com/dipperin/dipperin-core/core/accounts"
+	"github.com/dipperin/dipperin-core/core/chain/state-processor"
 )
 
 //go:generate mockgen -destination=./peer_mock_test.go -package=chain_communication github.com/caiqingfeng/dipperin-core/core/chain-communication PmAbstractPeer
@@ -149,6 +150,15 @@ type Chain interface {
 	SaveBlock(block model.AbstractBlock, seenCommits []model.AbstractVerification) error
 }
 
+// the chain which serves the state trie nodes and is synced from the state of a pivot block by the fast sync
+type StateChain interface {
+	Chain
+	GetStateStorage() state_processor.StateStorage
+	SaveSyncedBlock(block model.AbstractBlock, seenCommits []model.AbstractVerification) error
+	SetSyncedHead(block model.AbstractBlock, seenCommits []model.AbstractVerification) error
+	IsChangePoint(block model.AbstractBlock, isProcessPackageBlock bool) bool
+}
+
 //go:generate mockgen -destination=./pbft_signer_mock_test.go -package=chain_communication github.com/caiqingfeng/dipperin-core/core/chain-communication PbftSigner
 type PbftSigner interface {
 	GetAddress() common.Address
@@ -185,6 +195,15 @@ type AbstractPbftProtocolManager interface {
 	MatchCurrentVerifiersToNext()
 }
 
+// header only chain of the light client, it starts from a super block proof
+type LightChain interface {
+	Genesis() *model.Header
+	CurrentHeader() *model.Header
+	Proof() *model.SuperBlockProof
+	SetSuperBlockProof(proof *model.SuperBlockProof) error
+	InsertHeaders(headers []*model.Header) error
+}
+
 type ChainDownloader interface {
 	Start() error
 	Stop()
@@ -14,7 +14,313 @@
 // You should have received a copy of the GNU Lesser General Public License
 // along with this program.  If not, see <https://www.gnu.org/licenses/>.
 
-
 package chain_communication
 
+import (
+	"errors"
+	"github.com/dipperin/dipperin-core/common/g-error"
+	"github.com/dipperin/dipperin-core/common/g-timer"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/third-party/log"
+	"github.com/dipperin/dipperin-core/third-party/p2p"
+	"sync/atomic"
+	"time"
+)
+
+/*
+Light downloader serves and syncs the light client
+* full nodes answer the super block proof and headers requests with the Chain
+* light client downloads a super block proof (suffix + interlinked super block chain) first, then tracks the head with headers only
+*/
+func MakeLightDownloader(config *LightDownloaderConfig) *LightDownloader {
+	service := &LightDownloader{
+		LightDownloaderConfig: config,
+
+		handlers: map[uint64]func(msg p2p.Msg, p PmAbstractPeer) error{},
+		proofC:   make(chan *lightProofPack),
+		headerC:  make(chan *lightHeaderPack),
+
+		quitCh: make(chan struct{}),
+	}
+	service.handlers[GetSuperBlockProofMsg] = service.onGetSuperBlockProof
+	service.handlers[SuperBlockProofMsg] = service.onSuperBlockProof
+	service.handlers[GetHeadersMsg] = service.onGetHeaders
+	service.handlers[HeadersMsg] = service.onHeaders
+	return service
+}
+
+type LightDownloaderConfig struct {
+	Chain Chain
+	Pm    PeerManager
+	// only light client set it
+	LightChain LightChain
+}
+
+type LightDownloader struct {
+	*LightDownloaderConfig
+
+	handlers map[uint64]func(msg p2p.Msg, p PmAbstractPeer) error
+
+	proofC  chan *lightProofPack
+	headerC chan *lightHeaderPack
+
+	synchronising int32
+
+	quitCh chan struct{}
+}
+
+type lightProofPack struct {
+	peerID string
+	proof  *model.SuperBlockProof
+}
+
+type lightHeaderPack struct {
+	peerID  string
+	headers []*model.Header
+}
+
+func (ld *LightDownloader) MsgHandlers() map[uint64]func(msg p2p.Msg, p PmAbstractPeer) error {
+	return ld.handlers
+}
+
+func (ld *LightDownloader) onGetSuperBlockProof(msg p2p.Msg, p PmAbstractPeer) error {
+	var query getSuperBlockProof
+	if err := msg.Decode(&query); err != nil {
+		return errors.New("decode error, invalid message")
+	}
+	if ld.Chain == nil {
+		return nil
+	}
+
+	proof, err := ld.buildSuperBlockProof(query.SuffixLength)
+	if err != nil {
+		log.Warn("build super block proof failed", "err", err, "remote node", p.NodeName())
+		return nil
+	}
+	log.Info("light downloader send super block proof", "remote node", p.NodeName(), "prefix len", len(proof.Prefix), "suffix len", len(proof.Suffix))
+	return p.SendMsg(SuperBlockProofMsg, proof)
+}
+
+// follow the highest level interlink from the parent of the suffix back to genesis
+func (ld *LightDownloader) buildSuperBlockProof(suffixLength uint64) (*model.SuperBlockProof, error) {
+	current := ld.Chain.CurrentBlock()
+	if current == nil {
+		return nil, g_error.ErrCurrentBlockIsNil
+	}
+	if suffixLength > MaxHeaderFetch {
+		suffixLength = MaxHeaderFetch
+	}
+	if suffixLength > current.Number() {
+		suffixLength = current.Number()
+	}
+
+	proof := &model.SuperBlockProof{}
+	for number := current.Number() - suffixLength + 1; number <= current.Number() && suffixLength > 0; number++ {
+		block := ld.Chain.GetBlockByNumber(number)
+		if block == nil {
+			return nil, g_error.ErrBlockNotFound
+		}
+		proof.Suffix = append(proof.Suffix, block.Header().(*model.Header))
+	}
+
+	block := ld.Chain.GetBlockByNumber(current.Number() - suffixLength)
+	for {
+		if block == nil {
+			return nil, g_error.ErrBlockNotFound
+		}
+		superBlock := &model.SuperBlock{Header: block.Header().(*model.Header), InterLinks: block.GetInterlinks()}
+		proof.Prefix = append([]*model.SuperBlock{superBlock}, proof.Prefix...)
+		if block.Number() == 0 {
+			break
+		}
+
+		links := block.GetInterlinks()
+		if len(links) == 0 {
+			return nil, model.ErrSuperBlockNotInterLinked
+		}
+		block = ld.Chain.GetBlockByHash(links[len(links)-1])
+	}
+	return proof, nil
+}
+
+func (ld *LightDownloader) onSuperBlockProof(msg p2p.Msg, p PmAbstractPeer) error {
+	var proof model.SuperBlockProof
+	if err := msg.Decode(&proof); err != nil {
+		log.Error("light downloader decode super block proof failed", "err", err)
+		return err
+	}
+
+	// drop the proof if not syncing
+	select {
+	case ld.proofC <- &lightProofPack{peerID: p.ID(), proof: &proof}:
+	default:
+		log.Debug("light downloader drop super block proof", "remote node", p.NodeName())
+	}
+	return nil
+}
+
+func (ld *LightDownloader) onGetHeaders(msg p2p.Msg, p PmAbstractPeer) error {
+	var query getBlockHeaders
+	if err := msg.Decode(&query); err != nil {
+		return errors.New("decode error, invalid message")
+	}
+	if ld.Chain == nil {
+		return nil
+	}
+
+	var headers []*model.Header
+	for uint64(len(headers)) < query.Amount && len(headers) < MaxHeaderFetch {
+		block := ld.Chain.GetBlockByNumber(query.OriginHeight)
+		if block == nil {
+			break
+		}
+		headers = append(headers, block.Header().(*model.Header))
+		query.OriginHeight += 1
+	}
+
+	log.Info("light downloader send headers to remote", "remote node", p.NodeName(), "header len", len(headers))
+	return p.SendMsg(HeadersMsg, headers)
+}
+
+func (ld *LightDownloader) onHeaders(msg p2p.Msg, p PmAbstractPeer) error {
+	var headers []*model.Header
+	if err := msg.Decode(&headers); err != nil {
+		log.Error("light downloader decode headers failed", "err", err)
+		return err
+	}
+
+	// drop the headers if not syncing
+	select {
+	case ld.headerC <- &lightHeaderPack{peerID: p.ID(), headers: headers}:
+	default:
+		log.Debug("light downloader drop headers", "remote node", p.NodeName())
+	}
+	return nil
+}
+
+// only the light client syncs
+func (ld *LightDownloader) Start() error {
+	if ld.LightChain == nil {
+		return nil
+	}
+	go ld.loop()
+	return nil
+}
+
+func (ld *LightDownloader) Stop() {
+	close(ld.quitCh)
+}
+
+func (ld *LightDownloader) loop() {
+	forceSync := g_timer.SetPeriodAndRun(ld.runSync, pollingInterval)
+	defer g_timer.StopWork(forceSync)
+
+	<-ld.quitCh
+}
+
+func (ld *LightDownloader) runSync() {
+	if !atomic.CompareAndSwapInt32(&ld.synchronising, 0, 1) {
+		log.Info("light downloader is busy")
+		return
+	}
+	defer atomic.StoreInt32(&ld.synchronising, 0)
+
+	bestPeer := ld.Pm.BestPeer()
+	if bestPeer == nil {
+		log.Warn("light downloader can't get best peer, do nothing")
+		return
+	}
+
+	if ld.LightChain.Proof() == nil {
+		ld.syncProof(bestPeer)
+		return
+	}
+
+	_, height := bestPeer.GetHead()
+	if height <= ld.LightChain.CurrentHeader().Number {
+		return
+	}
+	ld.syncHeaders(bestPeer)
+}
+
+func (ld *LightDownloader) syncProof(bestPeer PmAbstractPeer) {
+	go func() {
+		if err := bestPeer.SendMsg(GetSuperBlockProofMsg, &getSuperBlockProof{SuffixLength: LightProofSuffixLength}); err != nil {
+			log.Warn("send get super block proof msg failed", "err", err)
+		}
+	}()
+
+	timeoutTimer := time.NewTimer(fetchBlockTimeout)
+	defer timeoutTimer.Stop()
+	for {
+		select {
+		case pack := <-ld.proofC:
+			if pack.peerID != bestPeer.ID() {
+				log.Warn("Received super block proof from incorrect peer", "peer", pack.peerID)
+				break
+			}
+
+			if err := ld.LightChain.SetSuperBlockProof(pack.proof); err != nil {
+				log.Warn("light downloader set super block proof failed", "err", err, "remote node", bestPeer.NodeName())
+				return
+			}
+			log.Info("light downloader set super block proof", "tip", ld.LightChain.CurrentHeader().Number, "remote node", bestPeer.NodeName())
+			return
+
+		case <-timeoutTimer.C:
+			log.Warn("Waiting for super block proof timed out", "node name", bestPeer.NodeName())
+			return
+
+		case <-ld.quitCh:
+			return
+		}
+	}
+}
+
+func (ld *LightDownloader) syncHeaders(bestPeer PmAbstractPeer) {
+	_, height := bestPeer.GetHead()
+	requestHeaders := func() {
+		query := &getBlockHeaders{OriginHeight: ld.LightChain.CurrentHeader().Number + 1, Amount: MaxHeaderFetch}
+		if err := bestPeer.SendMsg(GetHeadersMsg, query); err != nil {
+			log.Warn("send get headers msg failed", "err", err)
+		}
+	}
+	go requestHeaders()
+
+	timeoutTimer := time.NewTimer(fetchBlockTimeout)
+	defer timeoutTimer.Stop()
+	for {
+		select {
+		case pack := <-ld.headerC:
+			if pack.peerID != bestPeer.ID() {
+				log.Warn("Received headers from incorrect peer", "peer", pack.peerID)
+				break
+			}
+			if len(pack.headers) == 0 {
+				return
+			}
+
+			if err := ld.LightChain.InsertHeaders(pack.headers); err != nil {
+				log.Warn("light downloader insert headers failed", "err", err, "remote node", bestPeer.NodeName())
+				// the best peer is on another fork, switch to it if its proof is better
+				if err == model.ErrHeaderNotLink {
+					ld.syncProof(bestPeer)
+				}
+				return
+			}
+
+			if height <= ld.LightChain.CurrentHeader().Number {
+				return
+			}
+			timeoutTimer.Reset(fetchBlockTimeout)
+			go requestHeaders()
+
+		case <-timeoutTimer.C:
+			log.Warn("Waiting for headers timed out", "node name", bestPeer.NodeName())
+			return
 
+		case <-ld.quitCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chain_communication
+
+import (
+	"bytes"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/third-party/p2p"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestLightDownloader_MsgHandlers(t *testing.T) {
+	ld := MakeLightDownloader(&LightDownloaderConfig{})
+	handlers := ld.MsgHandlers()
+
+	assert.NotNil(t, handlers[GetSuperBlockProofMsg])
+	assert.NotNil(t, handlers[SuperBlockProofMsg])
+	assert.NotNil(t, handlers[GetHeadersMsg])
+	assert.NotNil(t, handlers[HeadersMsg])
+
+	// full node does not sync as light client
+	assert.NoError(t, ld.Start())
+	ld.Stop()
+}
+
+func TestLightDownloader_buildSuperBlockProof(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blocks := createLightTestChain(30)
+	ld := MakeLightDownloader(&LightDownloaderConfig{Chain: newLightTestMockChain(ctrl, blocks)})
+
+	proof, err := ld.buildSuperBlockProof(LightProofSuffixLength)
+	assert.NoError(t, err)
+	assert.Len(t, proof.Suffix, LightProofSuffixLength)
+	assert.Equal(t, blocks[30].Hash(), proof.Tip().Hash())
+	assert.NoError(t, proof.Verify(blocks[0].Hash()))
+
+	// suffix longer than the chain
+	proof, err = ld.buildSuperBlockProof(100)
+	assert.NoError(t, err)
+	assert.Len(t, proof.Suffix, 30)
+	assert.Len(t, proof.Prefix, 1)
+	assert.NoError(t, proof.Verify(blocks[0].Hash()))
+
+	mockChain := NewMockChain(ctrl)
+	mockChain.EXPECT().CurrentBlock().Return(nil)
+	ld = MakeLightDownloader(&LightDownloaderConfig{Chain: mockChain})
+	_, err = ld.buildSuperBlockProof(LightProofSuffixLength)
+	assert.Error(t, err)
+}
+
+func TestLightDownloader_onGetSuperBlockProof(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blocks := createLightTestChain(15)
+	ld := MakeLightDownloader(&LightDownloaderConfig{Chain: newLightTestMockChain(ctrl, blocks)})
+
+	mockPeer := NewMockPmAbstractPeer(ctrl)
+	mockPeer.EXPECT().NodeName().Return("test").AnyTimes()
+
+	err := ld.onGetSuperBlockProof(p2p.Msg{Payload: bytes.NewReader([]byte{})}, mockPeer)
+	assert.Error(t, err)
+
+	mockPeer.EXPECT().SendMsg(uint64(SuperBlockProofMsg), gomock.Any()).Return(nil).Times(1)
+	query, _ := rlp.EncodeToBytes(&getSuperBlockProof{SuffixLength: LightProofSuffixLength})
+	err = ld.onGetSuperBlockProof(p2p.Msg{Payload: bytes.NewReader(query)}, mockPeer)
+	assert.NoError(t, err)
+}
+
+func TestLightDownloader_onGetHeaders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blocks := createLightTestChain(5)
+	ld := MakeLightDownloader(&LightDownloaderConfig{Chain: newLightTestMockChain(ctrl, blocks)})
+
+	mockPeer := NewMockPmAbstractPeer(ctrl)
+	mockPeer.EXPECT().NodeName().Return("test").AnyTimes()
+
+	err := ld.onGetHeaders(p2p.Msg{Payload: bytes.NewReader([]byte{})}, mockPeer)
+	assert.Error(t, err)
+
+	mockPeer.EXPECT().SendMsg(uint64(HeadersMsg), gomock.Any()).DoAndReturn(func(msgCode uint64, msg interface{}) error {
+		assert.Len(t, msg, 3)
+		return nil
+	}).Times(1)
+	query, _ := rlp.EncodeToBytes(&getBlockHeaders{OriginHeight: 3, Amount: 10})
+	err = ld.onGetHeaders(p2p.Msg{Payload: bytes.NewReader(query)}, mockPeer)
+	assert.NoError(t, err)
+}
+
+func TestLightDownloader_onSuperBlockProof(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blocks := createLightTestChain(15)
+	ld := MakeLightDownloader(&LightDownloaderConfig{})
+
+	mockPeer := NewMockPmAbstractPeer(ctrl)
+	mockPeer.EXPECT().NodeName().Return("test").AnyTimes()
+	mockPeer.EXPECT().ID().Return("1").AnyTimes()
+
+	err := ld.onSuperBlockProof(p2p.Msg{Payload: bytes.NewReader([]byte{})}, mockPeer)
+	assert.Error(t, err)
+
+	data, _ := rlp.EncodeToBytes(getLightTestProof(t, ctrl, blocks))
+
+	// not syncing
+	assert.NoError(t, ld.onSuperBlockProof(p2p.Msg{Payload: bytes.NewReader(data)}, mockPeer))
+
+	go func() {
+		assert.NoError(t, ld.onSuperBlockProof(p2p.Msg{Payload: bytes.NewReader(data)}, mockPeer))
+	}()
+	pack := <-ld.proofC
+	assert.Equal(t, "1", pack.peerID)
+	assert.Equal(t, blocks[15].Hash(), pack.proof.Tip().Hash())
+}
+
+func TestLightDownloader_onHeaders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blocks := createLightTestChain(2)
+	ld := MakeLightDownloader(&LightDownloaderConfig{})
+
+	mockPeer := NewMockPmAbstractPeer(ctrl)
+	mockPeer.EXPECT().NodeName().Return("test").AnyTimes()
+	mockPeer.EXPECT().ID().Return("1").AnyTimes()
+
+	err := ld.onHeaders(p2p.Msg{Payload: bytes.NewReader([]byte{})}, mockPeer)
+	assert.Error(t, err)
+
+	data, _ := rlp.EncodeToBytes([]*model.Header{blocks[1].Header().(*model.Header), blocks[2].Header().(*model.Header)})
+	assert.NoError(t, ld.onHeaders(p2p.Msg{Payload: bytes.NewReader(data)}, mockPeer))
+
+	go func() {
+		assert.NoError(t, ld.onHeaders(p2p.Msg{Payload: bytes.NewReader(data)}, mockPeer))
+	}()
+	pack := <-ld.headerC
+	assert.Len(t, pack.headers, 2)
+}
+
+func TestLightDownloader_runSync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blocks := createLightTestChain(30)
+	lc := NewLightHeaderChain(blocks[0].Header().(*model.Header))
+	mockPM := NewMockPeerManager(ctrl)
+	ld := MakeLightDownloader(&LightDownloaderConfig{Pm: mockPM, LightChain: lc})
+
+	ld.synchronising = 1
+	ld.runSync()
+	ld.synchronising = 0
+
+	mockPM.EXPECT().BestPeer().Return(nil).Times(1)
+	ld.runSync()
+
+	mockPeer := NewMockPmAbstractPeer(ctrl)
+	mockPeer.EXPECT().NodeName().Return("test").AnyTimes()
+	mockPeer.EXPECT().ID().Return("1").AnyTimes()
+	mockPeer.EXPECT().GetHead().Return(blocks[30].Hash(), uint64(30)).AnyTimes()
+	mockPM.EXPECT().BestPeer().Return(mockPeer).AnyTimes()
+
+	// download the proof first
+	mockPeer.EXPECT().SendMsg(uint64(GetSuperBlockProofMsg), gomock.Any()).Return(nil).Times(1)
+	go func() { ld.proofC <- &lightProofPack{peerID: "1", proof: getLightTestProof(t, ctrl, blocks[:21])} }()
+	ld.runSync()
+	assert.Equal(t, uint64(20), lc.CurrentHeader().Number)
+
+	// then follow the head with headers
+	var headers []*model.Header
+	for _, b := range blocks[21:] {
+		headers = append(headers, b.Header().(*model.Header))
+	}
+	mockPeer.EXPECT().SendMsg(uint64(GetHeadersMsg), gomock.Any()).Return(nil).Times(1)
+	go func() { ld.headerC <- &lightHeaderPack{peerID: "1", headers: headers} }()
+	ld.runSync()
+	assert.Equal(t, blocks[30].Hash(), lc.CurrentHeader().Hash())
+
+	// nothing to sync
+	ld.runSync()
+}
+
+func TestLightDownloader_Start(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blocks := createLightTestChain(1)
+	mockPM := NewMockPeerManager(ctrl)
+	mockPM.EXPECT().BestPeer().Return(nil).AnyTimes()
+	ld := MakeLightDownloader(&LightDownloaderConfig{Pm: mockPM, LightChain: NewLightHeaderChain(blocks[0].Header().(*model.Header))})
+
+	pollingInterval = 1 * time.Millisecond
+	assert.NoError(t, ld.Start())
+	time.Sleep(5 * time.Millisecond)
+	ld.Stop()
+}
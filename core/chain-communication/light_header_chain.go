@@ -0,0 +1,112 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chain_communication
+
+import (
+	"errors"
+	"github.com/dipperin/dipperin-core/core/model"
+	"sync"
+)
+
+// the light client only keeps the latest headers
+const maxLightHeaders = 1024
+
+var (
+	lightProofScoreErr = errors.New("super block proof score lower than current proof")
+	lightNoProofErr    = errors.New("light chain has no super block proof")
+)
+
+func NewLightHeaderChain(genesis *model.Header) *LightHeaderChain {
+	return &LightHeaderChain{genesis: genesis}
+}
+
+// LightHeaderChain is the in memory LightChain
+type LightHeaderChain struct {
+	lock sync.RWMutex
+
+	genesis *model.Header
+	proof   *model.SuperBlockProof
+	// headers from the parent of the proof suffix
+	headers []*model.Header
+}
+
+func (lc *LightHeaderChain) Genesis() *model.Header {
+	return lc.genesis
+}
+
+func (lc *LightHeaderChain) Proof() *model.SuperBlockProof {
+	lc.lock.RLock()
+	defer lc.lock.RUnlock()
+	return lc.proof
+}
+
+func (lc *LightHeaderChain) CurrentHeader() *model.Header {
+	lc.lock.RLock()
+	defer lc.lock.RUnlock()
+	if len(lc.headers) == 0 {
+		return lc.genesis
+	}
+	return lc.headers[len(lc.headers)-1]
+}
+
+func (lc *LightHeaderChain) GetHeaderByNumber(number uint64) *model.Header {
+	lc.lock.RLock()
+	defer lc.lock.RUnlock()
+	if len(lc.headers) == 0 || number < lc.headers[0].Number {
+		return nil
+	}
+	index := number - lc.headers[0].Number
+	if index >= uint64(len(lc.headers)) {
+		return nil
+	}
+	return lc.headers[index]
+}
+
+// SetSuperBlockProof replace the chain with the proof if it has a score not lower than current proof
+func (lc *LightHeaderChain) SetSuperBlockProof(proof *model.SuperBlockProof) error {
+	if err := proof.Verify(lc.genesis.Hash()); err != nil {
+		return err
+	}
+
+	lc.lock.Lock()
+	defer lc.lock.Unlock()
+	if lc.proof != nil && proof.Score().Cmp(lc.proof.Score()) < 0 {
+		return lightProofScoreErr
+	}
+
+	lc.proof = proof
+	lc.headers = append([]*model.Header{proof.Prefix[len(proof.Prefix)-1].Header}, proof.Suffix...)
+	return nil
+}
+
+// InsertHeaders append the headers which link to the current header
+func (lc *LightHeaderChain) InsertHeaders(headers []*model.Header) error {
+	lc.lock.Lock()
+	defer lc.lock.Unlock()
+	if lc.proof == nil {
+		return lightNoProofErr
+	}
+	if err := model.VerifyHeaderChain(lc.headers[len(lc.headers)-1], headers); err != nil {
+		return err
+	}
+
+	lc.headers = append(lc.headers, headers...)
+	if len(lc.headers) > maxLightHeaders {
+		lc.headers = lc.headers[len(lc.headers)-maxLightHeaders:]
+	}
+	return nil
+}
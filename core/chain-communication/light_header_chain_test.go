@@ -0,0 +1,156 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chain_communication
+
+import (
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+var testLightDiff = common.HexToDiff("0x201fffff")
+
+// mine an interlinked chain with an easy difficulty, the blocks of the special numbers have no pow
+func createLightTestChain(n int, specials ...uint64) []*model.Block {
+	coinbase := common.HexToAddress("0x00005586B883Ec6dd4f8c26063E18eb4Bd228e59c3E9")
+	genesis := model.NewBlock(model.NewHeader(1, 0, common.Hash{}, common.Hash{}, testLightDiff, big.NewInt(0), coinbase, common.BlockNonce{}), nil, nil)
+	blocks := []*model.Block{genesis}
+	for i := 1; i <= n; i++ {
+		pre := blocks[i-1]
+		diff := testLightDiff
+		for _, number := range specials {
+			if number == uint64(i) {
+				diff = common.Difficulty{}
+			}
+		}
+		header := model.NewHeader(1, uint64(i), pre.Hash(), common.Hash{}, diff, big.NewInt(int64(i)), coinbase, common.BlockNonce{})
+		block := model.NewBlockWithLink(header, nil, nil, pre.GetInterlinks())
+		for j := uint64(0); !block.IsSpecial() && !block.Header().(*model.Header).ValidPow(); j++ {
+			block.SetNonce(common.EncodeNonce(j))
+		}
+		block.RefreshHashCache()
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// a mock chain serving the blocks, the last block is the current block
+func newLightTestMockChain(ctrl *gomock.Controller, blocks []*model.Block) *MockChain {
+	mockChain := NewMockChain(ctrl)
+	mockChain.EXPECT().CurrentBlock().Return(blocks[len(blocks)-1]).AnyTimes()
+	mockChain.EXPECT().GetBlockByNumber(gomock.Any()).DoAndReturn(func(number uint64) model.AbstractBlock {
+		if number >= uint64(len(blocks)) {
+			return nil
+		}
+		return blocks[number]
+	}).AnyTimes()
+	mockChain.EXPECT().GetBlockByHash(gomock.Any()).DoAndReturn(func(hash common.Hash) model.AbstractBlock {
+		for _, b := range blocks {
+			if b.Hash().IsEqual(hash) {
+				return b
+			}
+		}
+		return nil
+	}).AnyTimes()
+	return mockChain
+}
+
+func getLightTestProof(t *testing.T, ctrl *gomock.Controller, blocks []*model.Block) *model.SuperBlockProof {
+	ld := MakeLightDownloader(&LightDownloaderConfig{Chain: newLightTestMockChain(ctrl, blocks)})
+	proof, err := ld.buildSuperBlockProof(LightProofSuffixLength)
+	assert.NoError(t, err)
+	return proof
+}
+
+func TestLightHeaderChain_SetSuperBlockProof(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blocks := createLightTestChain(30)
+	lc := NewLightHeaderChain(blocks[0].Header().(*model.Header))
+	assert.Equal(t, blocks[0].Hash(), lc.CurrentHeader().Hash())
+	assert.Nil(t, lc.Proof())
+	assert.Nil(t, lc.GetHeaderByNumber(1))
+
+	// a proof with another genesis
+	otherLc := NewLightHeaderChain(model.NewHeader(2, 0, common.Hash{}, common.Hash{}, testLightDiff, big.NewInt(0), common.Address{}, common.BlockNonce{}))
+	assert.Error(t, otherLc.SetSuperBlockProof(getLightTestProof(t, ctrl, blocks)))
+
+	shortProof := getLightTestProof(t, ctrl, blocks[:20])
+	assert.NoError(t, lc.SetSuperBlockProof(shortProof))
+	assert.Equal(t, uint64(19), lc.CurrentHeader().Number)
+
+	proof := getLightTestProof(t, ctrl, blocks)
+	assert.NoError(t, lc.SetSuperBlockProof(proof))
+	assert.Equal(t, proof, lc.Proof())
+	assert.Equal(t, blocks[30].Hash(), lc.CurrentHeader().Hash())
+	assert.Equal(t, blocks[25].Hash(), lc.GetHeaderByNumber(25).Hash())
+	assert.Nil(t, lc.GetHeaderByNumber(31))
+
+	// the shorter proof can not replace the current one
+	if shortProof.Score().Cmp(proof.Score()) < 0 {
+		assert.Equal(t, lightProofScoreErr, lc.SetSuperBlockProof(shortProof))
+	}
+}
+
+func TestLightHeaderChain_InsertHeaders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blocks := createLightTestChain(25)
+	lc := NewLightHeaderChain(blocks[0].Header().(*model.Header))
+
+	var headers []*model.Header
+	for _, b := range blocks[21:] {
+		headers = append(headers, b.Header().(*model.Header))
+	}
+	assert.Equal(t, lightNoProofErr, lc.InsertHeaders(headers))
+
+	assert.NoError(t, lc.SetSuperBlockProof(getLightTestProof(t, ctrl, blocks[:21])))
+	assert.Equal(t, uint64(20), lc.CurrentHeader().Number)
+
+	assert.Equal(t, model.ErrHeaderNotLink, lc.InsertHeaders(headers[1:]))
+	assert.NoError(t, lc.InsertHeaders(headers))
+	assert.Equal(t, blocks[25].Hash(), lc.CurrentHeader().Hash())
+}
+
+func TestLightHeaderChain_SpecialHeaders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// the special blocks are the parent of the suffix and in the suffix of the proof
+	blocks := createLightTestChain(35, 20, 25, 33)
+	lc := NewLightHeaderChain(blocks[0].Header().(*model.Header))
+
+	proof := getLightTestProof(t, ctrl, blocks[:31])
+	assert.Len(t, proof.Suffix, LightProofSuffixLength)
+	assert.True(t, proof.Prefix[len(proof.Prefix)-1].Header.IsSpecial())
+	assert.True(t, proof.Suffix[4].IsSpecial())
+	assert.NoError(t, lc.SetSuperBlockProof(proof))
+	assert.Equal(t, blocks[30].Hash(), lc.CurrentHeader().Hash())
+
+	// the light client goes on with the special headers
+	var headers []*model.Header
+	for _, b := range blocks[31:] {
+		headers = append(headers, b.Header().(*model.Header))
+	}
+	assert.NoError(t, lc.InsertHeaders(headers))
+	assert.Equal(t, blocks[35].Hash(), lc.CurrentHeader().Hash())
+}
@@ -37,13 +37,16 @@ func MakeNewPbftDownloader(config *NewPbftDownloaderConfig) *NewPbftDownloader {
 	service := &NewPbftDownloader{
 		NewPbftDownloaderConfig: config,
 
-		handlers: map[uint64]func(msg p2p.Msg, p PmAbstractPeer) error{},
-		blockC:   make(chan *npbPack),
+		handlers:  map[uint64]func(msg p2p.Msg, p PmAbstractPeer) error{},
+		blockC:    make(chan *npbPack),
+		nodeDataC: make(chan *nodeDataPack),
 
 		quitCh: make(chan struct{}),
 	}
 	service.handlers[GetBlocksMsg] = service.onGetBlocks
 	service.handlers[BlocksMsg] = service.onBlocks
+	service.handlers[GetNodeDataMsg] = service.onGetNodeData
+	service.handlers[NodeDataMsg] = service.onNodeData
 	return service
 }
 
@@ -53,6 +56,10 @@ type NewPbftDownloaderConfig struct {
 	PbftNode PbftNode
 	//fetcher  *EiBlockFetcher
 	fetcher *BlockFetcher
+
+	// serves the state trie nodes and syncs them in the fast sync
+	StateChain StateChain
+	FastSync   bool
 }
 
 type NewPbftDownloader struct {
@@ -60,7 +67,8 @@ type NewPbftDownloader struct {
 
 	handlers map[uint64]func(msg p2p.Msg, p PmAbstractPeer) error
 
-	blockC chan *npbPack
+	blockC    chan *npbPack
+	nodeDataC chan *nodeDataPack
 
 	synchronising int32
 
@@ -240,6 +248,12 @@ func (fd *NewPbftDownloader) runSync() {
 		return
 	}
 
+	if fd.needFastSync(bestPeer) {
+		if err := fd.fastSync(bestPeer); err != nil {
+			log.Warn("fast sync failed", "err", err, "remote node", bestPeer.NodeName())
+		}
+		return
+	}
 	fd.fetchBlocks(bestPeer)
 
 }
@@ -179,6 +179,34 @@ func (mr *MockAbstractTransactionMockRecorder) GetType() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetType", reflect.TypeOf((*MockAbstractTransaction)(nil).GetType))
 }
 
+// HashKey mocks base method
+func (m *MockAbstractTransaction) HashKey() []byte {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HashKey")
+	ret0, _ := ret[0].([]byte)
+	return ret0
+}
+
+// HashKey indicates an expected call of HashKey
+func (mr *MockAbstractTransactionMockRecorder) HashKey() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashKey", reflect.TypeOf((*MockAbstractTransaction)(nil).HashKey))
+}
+
+// HashLock mocks base method
+func (m *MockAbstractTransaction) HashLock() *common.Hash {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HashLock")
+	ret0, _ := ret[0].(*common.Hash)
+	return ret0
+}
+
+// HashLock indicates an expected call of HashLock
+func (mr *MockAbstractTransactionMockRecorder) HashLock() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashLock", reflect.TypeOf((*MockAbstractTransaction)(nil).HashLock))
+}
+
 // Nonce mocks base method
 func (m *MockAbstractTransaction) Nonce() uint64 {
 	m.ctrl.T.Helper()
@@ -237,6 +265,20 @@ func (mr *MockAbstractTransactionMockRecorder) Size() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Size", reflect.TypeOf((*MockAbstractTransaction)(nil).Size))
 }
 
+// TimeLock mocks base method
+func (m *MockAbstractTransaction) TimeLock() *big.Int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TimeLock")
+	ret0, _ := ret[0].(*big.Int)
+	return ret0
+}
+
+// TimeLock indicates an expected call of TimeLock
+func (mr *MockAbstractTransactionMockRecorder) TimeLock() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TimeLock", reflect.TypeOf((*MockAbstractTransaction)(nil).TimeLock))
+}
+
 // To mocks base method
 func (m *MockAbstractTransaction) To() *common.Address {
 	m.ctrl.T.Helper()
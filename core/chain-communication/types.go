@@ -21,3 +21,7 @@ type getBlockHeaders struct {
 	OriginHeight uint64
 	Amount       uint64
 }
+
+type getSuperBlockProof struct {
+	SuffixLength uint64
+}
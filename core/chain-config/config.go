@@ -68,7 +68,7 @@ var config = defaultChainConfig()
 func defaultChainConfig() *ChainConfig {
 	c := &ChainConfig{
 		//DeriveShaType:         DeriveShaTypeByHash,
-		SupportHardwareWallet: false,
+		SupportHardwareWallet: true,
 		ChainId:               big.NewInt(1),
 		Version:               uint64(0),
 		// verify segment size
@@ -96,6 +96,24 @@ func defaultChainConfig() *ChainConfig {
 		VerifierBootNodeNumber: 4,
 
 		BlockTimeRestriction: 15*time.Second,
+
+		// relayed headers needed on top of the block of a cross chain lock tx
+		CrossChainConfirmations: uint64(6),
+
+		// double sign slashing
+		SlashBurnPercent:     uint64(20),
+		SlashReporterPercent: uint64(10),
+		SlashJailSlot:        uint64(4),
+		SlashEvidenceSlot:    uint64(4),
+		// liveness slashing
+		LivenessSlashPercent: uint64(1),
+		LivenessJailSlot:     uint64(1),
+
+		// share of the delegators reward kept by the verifier
+		DelegationCommissionPercent: uint64(10),
+
+		// activation block of the protocol upgrades
+		Forks: defaultForks(),
 	}
 
 	switch os.Getenv(BootEnvTagName) {
@@ -153,6 +171,54 @@ type ChainConfig struct {
 
 	//timeStamp restriction
 	BlockTimeRestriction time.Duration
+
+	//cross chain conf
+	//relayed headers needed on top of the block of a cross chain lock tx
+	CrossChainConfirmations uint64
+	//trusted header of other chains, the relayed header chain starts from it
+	CrossChainCheckpoints []CrossChainCheckpoint
+
+	//slashing conf
+	//percentage of the stake burned for a double sign
+	SlashBurnPercent uint64
+	//percentage of the stake rewarded to the reporter of a double sign
+	SlashReporterPercent uint64
+	//slots the double signer is excluded from the election
+	SlashJailSlot uint64
+	//slots after the double sign the evidence can be processed, older evidence is rejected
+	SlashEvidenceSlot uint64
+	//percentage of the stake burned for missing all verifications of a slot
+	LivenessSlashPercent uint64
+	//slots the offline verifier is excluded from the election
+	LivenessJailSlot uint64
+
+	//delegation conf
+	//percentage of the delegators verifier reward kept by the verifier as commission
+	DelegationCommissionPercent uint64
+
+	//fork conf
+	//fork name -> activation block, new rules apply to the blocks from the activation block
+	Forks map[string]uint64
+}
+
+// CrossChainCheckpoint is a trusted block of the chain with ChainId, relayed headers must be at least as hard as MinDiff if set.
+// A lock tx is only redeemed once MinConfirmWork is relayed on top of its block, headers aren't relayed if it isn't set
+type CrossChainCheckpoint struct {
+	ChainId        *big.Int
+	Number         uint64
+	Hash           common.Hash
+	MinDiff        common.Difficulty
+	MinConfirmWork *big.Int
+}
+
+// get the trusted checkpoint of the chain, return nil if not set
+func (c *ChainConfig) GetCrossChainCheckpoint(chainId *big.Int) *CrossChainCheckpoint {
+	for i := range c.CrossChainCheckpoints {
+		if c.CrossChainCheckpoints[i].ChainId.Cmp(chainId) == 0 {
+			return &c.CrossChainCheckpoints[i]
+		}
+	}
+	return nil
 }
 
 func GetChainConfig() *ChainConfig {
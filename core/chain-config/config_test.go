@@ -18,6 +18,8 @@ package chain_config
 
 import (
 	"fmt"
+	"github.com/dipperin/dipperin-core/common"
+	"math/big"
 	"net"
 	"os"
 	"testing"
@@ -63,6 +65,17 @@ func TestGetChainConfig(t *testing.T) {
 	assert.Equal(t, uint64(99), chainConfig.NetworkID)
 }
 
+func TestChainConfig_GetCrossChainCheckpoint(t *testing.T) {
+	chainConfig := defaultChainConfig()
+	assert.Equal(t, uint64(6), chainConfig.CrossChainConfirmations)
+	assert.Nil(t, chainConfig.GetCrossChainCheckpoint(big.NewInt(2)))
+
+	chainConfig.CrossChainCheckpoints = []CrossChainCheckpoint{{ChainId: big.NewInt(2), Number: 10, Hash: common.HexToHash("0x123")}}
+	checkpoint := chainConfig.GetCrossChainCheckpoint(big.NewInt(2))
+	assert.Equal(t, uint64(10), checkpoint.Number)
+	assert.Nil(t, chainConfig.GetCrossChainCheckpoint(big.NewInt(3)))
+}
+
 func TestGetCurBootsEnv(t *testing.T) {
 	err := os.Setenv("boots_env", "mercury")
 	assert.NoError(t, err)
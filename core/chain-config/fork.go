@@ -0,0 +1,150 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chain_config
+
+import (
+	"fmt"
+	"github.com/dipperin/dipperin-core/common"
+	"os"
+	"sort"
+)
+
+// names of the scheduled protocol upgrades
+const (
+	ForkHashLock   = "hash_lock"
+	ForkMultiSig   = "multi_sig"
+	ForkDelegation = "delegation"
+	ForkReceipt    = "receipt"
+	ForkSlashing   = "slashing"
+)
+
+// tx types which are valid only after the fork activated, the forks only changing the rules have no tx type
+var forkTxTypes = map[string][]common.TxType{
+	ForkHashLock:   {common.AddressTypeCross},
+	ForkMultiSig:   {common.AddressTypeMultiSig},
+	ForkDelegation: {common.AddressTypeDelegate, common.AddressTypeUnDelegate},
+	ForkReceipt:    nil,
+	ForkSlashing:   nil,
+}
+
+// activation block of the forks on the running networks, the nodes without the upgrade keep syncing with
+// the upgraded ones until the activation block
+var networkForks = map[string]map[string]uint64{
+	"mercury": {
+		ForkHashLock:   1200000,
+		ForkMultiSig:   1200000,
+		ForkReceipt:    1200000,
+		ForkSlashing:   1300000,
+		ForkDelegation: 1300000,
+	},
+	"test": {
+		ForkHashLock:   60000,
+		ForkMultiSig:   60000,
+		ForkReceipt:    60000,
+		ForkSlashing:   70000,
+		ForkDelegation: 70000,
+	},
+}
+
+// Fork is the activation block of a protocol upgrade, it is sent to the peers in the hand shake
+type Fork struct {
+	Name  string
+	Block uint64
+}
+
+// the fork schedule of the boot env, all the known forks are active from the genesis block on a new local network
+func defaultForks() map[string]uint64 {
+	schedule := networkForks[os.Getenv(BootEnvTagName)]
+	forks := make(map[string]uint64, len(forkTxTypes))
+	for name := range forkTxTypes {
+		forks[name] = schedule[name]
+	}
+	return forks
+}
+
+// IsKnownFork check whether the fork name is supported by this node
+func IsKnownFork(name string) bool {
+	_, ok := forkTxTypes[name]
+	return ok
+}
+
+// IsForkActive check whether the rules of the fork apply to the block, a fork not in the schedule is never active
+func (c *ChainConfig) IsForkActive(name string, number uint64) bool {
+	activation, ok := c.Forks[name]
+	if !ok {
+		return false
+	}
+	return number >= activation
+}
+
+// IsTxTypeActive check whether the tx type can be packed into the block
+func (c *ChainConfig) IsTxTypeActive(txType common.TxType, number uint64) bool {
+	for name, txTypes := range forkTxTypes {
+		for _, t := range txTypes {
+			if t == txType {
+				return c.IsForkActive(name, number)
+			}
+		}
+	}
+	return true
+}
+
+// ForkList return the fork schedule sorted by the activation block
+func (c *ChainConfig) ForkList() []Fork {
+	forks := make([]Fork, 0, len(c.Forks))
+	for name, block := range c.Forks {
+		forks = append(forks, Fork{Name: name, Block: block})
+	}
+	sort.Slice(forks, func(i, j int) bool {
+		if forks[i].Block != forks[j].Block {
+			return forks[i].Block < forks[j].Block
+		}
+		return forks[i].Name < forks[j].Name
+	})
+	return forks
+}
+
+// CheckForkCompatible check the fork schedule of a peer. The schedules may differ on the forks which are
+// activated after the head, so that the nodes can be upgraded before the activation block, and a peer
+// without the fork schedule is accepted until the first fork activated.
+// head is the highest block of the two nodes
+func (c *ChainConfig) CheckForkCompatible(remote []Fork, head uint64) error {
+	remoteForks := make(map[string]uint64, len(remote))
+	for _, f := range remote {
+		remoteForks[f.Name] = f.Block
+	}
+
+	for name, local := range c.Forks {
+		block, ok := remoteForks[name]
+		if !ok {
+			if local <= head {
+				return fmt.Errorf("fork %v activated at %v isn't supported by the remote", name, local)
+			}
+			continue
+		}
+		if block != local && (block <= head || local <= head) {
+			return fmt.Errorf("fork %v not match, local: %v remote: %v", name, local, block)
+		}
+	}
+
+	for name, block := range remoteForks {
+		if _, ok := c.Forks[name]; !ok && block <= head {
+			return fmt.Errorf("remote fork %v activated at %v isn't supported", name, block)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chain_config
+
+import (
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestChainConfig_IsForkActive(t *testing.T) {
+	c := localChainConfig()
+	assert.True(t, c.IsForkActive(ForkDelegation, 0))
+	assert.False(t, c.IsForkActive("unknown", 100))
+	assert.True(t, IsKnownFork(ForkMultiSig))
+	assert.False(t, IsKnownFork("unknown"))
+
+	c.Forks[ForkDelegation] = 100
+	assert.False(t, c.IsForkActive(ForkDelegation, 99))
+	assert.True(t, c.IsForkActive(ForkDelegation, 100))
+
+	assert.False(t, c.IsTxTypeActive(common.AddressTypeDelegate, 99))
+	assert.False(t, c.IsTxTypeActive(common.AddressTypeUnDelegate, 99))
+	assert.True(t, c.IsTxTypeActive(common.AddressTypeUnDelegate, 100))
+	assert.True(t, c.IsTxTypeActive(common.AddressTypeNormal, 0))
+
+	delete(c.Forks, ForkMultiSig)
+	assert.False(t, c.IsTxTypeActive(common.AddressTypeMultiSig, 100))
+}
+
+func TestChainConfig_ForkList(t *testing.T) {
+	c := localChainConfig()
+	c.Forks[ForkDelegation] = 100
+	assert.Equal(t, []Fork{
+		{Name: ForkHashLock, Block: 0},
+		{Name: ForkMultiSig, Block: 0},
+		{Name: ForkReceipt, Block: 0},
+		{Name: ForkSlashing, Block: 0},
+		{Name: ForkDelegation, Block: 100},
+	}, c.ForkList())
+}
+
+func Test_defaultForks(t *testing.T) {
+	for name, block := range localChainConfig().Forks {
+		assert.True(t, IsKnownFork(name))
+		assert.Equal(t, uint64(0), block)
+	}
+
+	os.Setenv(BootEnvTagName, "mercury")
+	defer os.Unsetenv(BootEnvTagName)
+	forks := defaultForks()
+	assert.Len(t, forks, len(forkTxTypes))
+	for _, block := range forks {
+		assert.NotEqual(t, uint64(0), block)
+	}
+
+	// the nodes without the fork schedule are accepted until the first fork
+	c := &ChainConfig{Forks: forks}
+	assert.NoError(t, c.CheckForkCompatible(nil, forks[ForkHashLock]-1))
+	assert.Error(t, c.CheckForkCompatible(nil, forks[ForkHashLock]))
+}
+
+func TestChainConfig_CheckForkCompatible(t *testing.T) {
+	c := localChainConfig()
+	c.Forks[ForkDelegation] = 100
+	remote := c.ForkList()
+	assert.NoError(t, c.CheckForkCompatible(remote, 1000))
+
+	// the schedules differ only after the head
+	remote[4].Block = 200
+	assert.NoError(t, c.CheckForkCompatible(remote, 99))
+	assert.Error(t, c.CheckForkCompatible(remote, 100))
+
+	// the remote doesn't know the scheduled fork yet
+	assert.NoError(t, c.CheckForkCompatible(remote[:4], 99))
+	assert.Error(t, c.CheckForkCompatible(remote[:4], 100))
+	assert.Error(t, c.CheckForkCompatible(nil, 0))
+
+	// the local doesn't know the fork of the remote
+	remote = append(c.ForkList(), Fork{Name: "new_fork", Block: 500})
+	assert.NoError(t, c.CheckForkCompatible(remote, 499))
+	assert.Error(t, c.CheckForkCompatible(remote, 500))
+}
+
+// the chain config of the local network, the other tests may leave the boot env set
+func localChainConfig() *ChainConfig {
+	os.Unsetenv(BootEnvTagName)
+	return defaultChainConfig()
+}
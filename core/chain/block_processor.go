@@ -19,6 +19,7 @@
 package chain
 
 import (
+	"errors"
 	"github.com/dipperin/dipperin-core/core/chain-config"
 	"reflect"
 	"github.com/dipperin/dipperin-core/core/economy-model"
@@ -53,6 +54,8 @@ type BlockProcessor struct {
 	fullChain    AccountDBChainReader
 	*state_processor.AccountStateDB
 	economyModel economy_model.EconomyModel
+	// receipts of the txs in the processed block
+	receipts model.Receipts
 }
 
 func NewBlockProcessor(fullChain AccountDBChainReader, preStateRoot common.Hash, db state_processor.StateStorage) (*BlockProcessor, error) {
@@ -70,10 +73,11 @@ func (state *BlockProcessor) Process(block model.AbstractBlock, economyModel eco
 	mpt_log.Debug("AccountStateDB Process begin~~~~~~~~~~~~~~", "pre state", state.PreStateRoot().Hex(),"blockId",block.Hash().Hex())
 
 	state.economyModel = economyModel
+	state.receipts = nil
 	// special block doesn't process txs
 	if !block.IsSpecial() {
 		if err = block.TxIterator(func(i int, tx model.AbstractTransaction) (error) {
-			innerError := state.ProcessTx(tx, block.Number())
+			receipt, innerError := state.ProcessTxWithReceipt(tx, block.Number())
 			/*// unrecognized tx means no processing of the tx
 			if innerError == g_error.UnknownTxTypeErr {
 				log.Warn("unknown tx type", "type", tx.GetType())
@@ -82,6 +86,7 @@ func (state *BlockProcessor) Process(block model.AbstractBlock, economyModel eco
 			if innerError != nil {
 				return innerError
 			}
+			state.receipts = append(state.receipts, receipt)
 			return nil
 		}); err != nil {
 			return err
@@ -95,6 +100,10 @@ func (state *BlockProcessor) Process(block model.AbstractBlock, economyModel eco
 	return
 }
 
+// get the receipts of the txs in the last processed block
+func (state *BlockProcessor) Receipts() model.Receipts {
+	return state.receipts
+}
 
 func (state *BlockProcessor) ProcessExceptTxs(block model.AbstractBlock, economyModel economy_model.EconomyModel,isProcessPackageBlock bool) (err error) {
 	mpt_log.Debug("ProcessExceptTxs begin", "pre state", state.PreStateRoot().Hex())
@@ -158,12 +167,16 @@ func (state *BlockProcessor) processCommitList(block model.AbstractBlock,isProce
 			//　firstStateBySlot is the state of the first block in a round
 			var firstStateBySlot *state_processor.AccountStateDB
 			lastPoint := state.fullChain.GetLastChangePoint(block)
+			if lastPoint == nil {
+				return errors.New("can't get the last change point")
+			}
 
 			//If the previous block is also a change point, then there is only one block in this round.
 			if state.fullChain.IsChangePoint(preBlock, false) {
 				firstStateBySlot = state.AccountStateDB
-			} else {
-				firstStateBySlot, _ = state.fullChain.StateAtByBlockNumber(*lastPoint + 1)
+			} else if firstStateBySlot, err = state.fullChain.StateAtByBlockNumber(*lastPoint + 1); err != nil {
+				log.Error("get the state of the first block in the slot failed", "num", *lastPoint+1, "err", err)
+				return err
 			}
 
 			log.Info("process performance", "slot", slot, "current num", block.Number(), "len(vers)", verifiers)
@@ -177,6 +190,11 @@ func (state *BlockProcessor) processCommitList(block model.AbstractBlock,isProce
 				amount := reward
 				if commitNum == firstCommitNumBySlot {
 					amount = penalty
+					// missed all the verifications of the slot
+					if err = state.ProcessLivenessFault(ver, block.Number()); err != nil {
+						log.Error("process liveness fault error", "verifier", ver.Hex(), "err", err)
+						return err
+					}
 				}
 				state.ProcessPerformance(ver, amount)
 			}
@@ -48,7 +48,13 @@ func (state *BlockProcessor) RewardByzantiumVerifier(Block model.AbstractBlock,
 				}
 			}
 
-			if err = state.AddBalance(address, rewardValue); err != nil {
+			// the delegators share the reward of the verifier
+			if state.economyModel.ShareRewardWithDelegators(Block.Number()) {
+				err = state.DistributeVerifierReward(address, rewardValue)
+			} else {
+				err = state.AddBalance(address, rewardValue)
+			}
+			if err != nil {
 				return err
 			}
 		}
@@ -27,6 +27,8 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"reflect"
 	"github.com/dipperin/dipperin-core/core/contract"
+	"github.com/dipperin/dipperin-core/common/util"
+	"github.com/dipperin/dipperin-core/third-party/crypto"
 )
 
 var minDiff = common.HexToDiff("0x20ffffff")
@@ -65,6 +67,41 @@ func TestBlockProcessor_Process(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestBlockProcessor_Receipts(t *testing.T) {
+	db, root := createTestStateDB(t)
+	tdb := state_processor.NewStateStorageWithCache(db)
+	state, err := state_processor.NewAccountStateDB(root, tdb)
+	assert.NoError(t, err)
+	processor, err := NewBlockProcessor(fakeAccountDBChain{state: state}, root, tdb)
+	assert.NoError(t, err)
+
+	// a normal tx and a contract call of a not existed token
+	key, _ := crypto.HexToECDSA(testPriv1)
+	signer := model.NewMercurySigner(big.NewInt(1))
+	normalTx, _ := model.NewTransaction(0, bobAddr, big.NewInt(100), big.NewInt(10), nil).SignTx(key, signer)
+	contractAddr := common.HexToAddress("0x00102B9aE8e4a04B3d2Dc8a60D2E9E4E0A60A5b7aA30")
+	extraData := util.StringifyJsonToBytes(contract.ExtraDataForContract{ContractAddress: contractAddr, Action: "Transfer", Params: util.StringifyJson([]string{bobAddr.Hex(), "0x1"})})
+	contractTx, _ := model.NewTransaction(1, contractAddr, big.NewInt(0), big.NewInt(10), extraData).SignTx(key, signer)
+
+	header := model.NewHeader(1, 20, common.Hash{}, common.HexToHash("123456"), minDiff, big.NewInt(0), aliceAddr, common.BlockNonce{})
+	block := model.NewBlock(header, []*model.Transaction{normalTx, contractTx}, nil)
+	assert.NoError(t, processor.Process(block, fakeEconomyModel{}))
+
+	receipts := processor.Receipts()
+	assert.Len(t, receipts, 2)
+	assert.Equal(t, normalTx.CalTxId(), receipts[0].TxHash)
+	assert.False(t, receipts[0].Failed())
+	assert.Equal(t, contractTx.CalTxId(), receipts[1].TxHash)
+	assert.True(t, receipts[1].Failed())
+	assert.Equal(t, big.NewInt(10), receipts[1].Fee)
+	nonce, _ := processor.GetNonce(aliceAddr)
+	assert.Equal(t, uint64(2), nonce)
+
+	// the receipts are reset for the next block
+	assert.NoError(t, processor.Process(model.CreateBlock(20, common.Hash{}, 0), fakeEconomyModel{}))
+	assert.Empty(t, processor.Receipts())
+}
+
 func TestBlockProcessor_Process_Error(t *testing.T) {
 	header := model.NewHeader(1, 10, common.Hash{}, common.HexToHash("1111"), minDiff, big.NewInt(324234), common.Address{}, common.BlockNonceFromInt(432423))
 
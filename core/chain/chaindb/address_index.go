@@ -0,0 +1,188 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chaindb
+
+import (
+	"encoding/binary"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/core/contract"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/third-party/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"sort"
+)
+
+// the index is only maintained when enabled, it costs a few writes for every tx.
+// The blocks saved before are not indexed, so the first enabling records the block after the head as the start of the index
+func (chainDB *ChainDB) EnableAddressIndex() {
+	chainDB.addressIndex = true
+	if data, _ := chainDB.db.Get(addressIndexStartKey); len(data) == 8 {
+		return
+	}
+
+	start := uint64(0)
+	if number := chainDB.GetHeaderNumber(chainDB.GetHeadBlockHash()); number != nil {
+		start = *number + 1
+	}
+	if err := chainDB.db.Put(addressIndexStartKey, encodeBlockNumber(start)); err != nil {
+		log.Crit("Failed to store address index start", "err", err)
+	}
+}
+
+// the blocks saved while the index is disabled are not indexed, so the start is recorded again by the next enabling
+func (chainDB *ChainDB) DisableAddressIndex() {
+	chainDB.addressIndex = false
+	if err := chainDB.db.Delete(addressIndexStartKey); err != nil {
+		log.Crit("Failed to delete address index start", "err", err)
+	}
+}
+
+func (chainDB *ChainDB) AddressIndexEnabled() bool {
+	return chainDB.addressIndex
+}
+
+// AddressIndexStart get the first block of the index, the txs of the blocks before it are not in the index
+func (chainDB *ChainDB) AddressIndexStart() uint64 {
+	data, _ := chainDB.db.Get(addressIndexStartKey)
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+// the sender, the receiver and the token owner and receiver of the ERC20 call
+func txAddresses(tx model.AbstractTransaction) []common.Address {
+	var addresses []common.Address
+	add := func(address common.Address) {
+		if address.IsEmpty() {
+			return
+		}
+		for _, a := range addresses {
+			if a.IsEqual(address) {
+				return
+			}
+		}
+		addresses = append(addresses, address)
+	}
+
+	sender, _ := tx.Sender(nil)
+	add(sender)
+	if tx.To() != nil {
+		add(*tx.To())
+	}
+	if event := contract.ParseTokenEvent(tx); event != nil {
+		add(event.From)
+		add(event.To)
+	}
+	return addresses
+}
+
+func (chainDB *ChainDB) getAddressTxCount(address common.Address) uint64 {
+	data, _ := chainDB.db.Get(addressTxCountKey(address))
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+func (chainDB *ChainDB) getAddressTxEntry(address common.Address, seq uint64) *AddressTxEntry {
+	data, _ := chainDB.db.Get(addressTxKey(address, seq))
+	if len(data) == 0 {
+		return nil
+	}
+	var entry AddressTxEntry
+	if err := rlp.DecodeBytes(data, &entry); err != nil {
+		log.Error("Invalid address tx entry RLP", "address", address, "err", err)
+		return nil
+	}
+	return &entry
+}
+
+// SaveAddressTxEntries append the txs of the block to the entries of their addresses.
+// Entries left by a rolled back block at the same or higher number are replaced.
+func (chainDB *ChainDB) SaveAddressTxEntries(block model.AbstractBlock) {
+	entries := map[common.Address][]AddressTxEntry{}
+	var addresses []common.Address
+	if err := block.TxIterator(func(index int, tx model.AbstractTransaction) error {
+		entry := AddressTxEntry{TxHash: tx.CalTxId(), BlockHash: block.Hash(), BlockNumber: block.Number(), Index: uint64(index)}
+		for _, address := range txAddresses(tx) {
+			if _, ok := entries[address]; !ok {
+				addresses = append(addresses, address)
+			}
+			entries[address] = append(entries[address], entry)
+		}
+		return nil
+	}); err != nil {
+		log.Error("block tx iterator failed", "err", err)
+		return
+	}
+
+	batch := chainDB.db.NewBatch()
+	for _, address := range addresses {
+		count := chainDB.getAddressTxCount(address)
+		for count > 0 {
+			last := chainDB.getAddressTxEntry(address, count-1)
+			if last != nil && last.BlockNumber < block.Number() {
+				break
+			}
+			count--
+		}
+		for _, entry := range entries[address] {
+			data, _ := rlp.EncodeToBytes(entry)
+			if err := batch.Put(addressTxKey(address, count), data); err != nil {
+				log.Crit("Failed to store address tx entry", "err", err)
+				return
+			}
+			count++
+		}
+		if err := batch.Put(addressTxCountKey(address), encodeBlockNumber(count)); err != nil {
+			log.Crit("Failed to store address tx count", "err", err)
+			return
+		}
+	}
+
+	if err := batch.Write(); err != nil {
+		log.Error("address tx batch write failed", "err", err)
+	}
+}
+
+// GetAddressTxEntries get the entries of the address in the canonical blocks between fromBlock and toBlock.
+// The txs of a block are never split, so the result may exceed the limit and the next page starts from the block after the last entry.
+func (chainDB *ChainDB) GetAddressTxEntries(address common.Address, fromBlock, toBlock uint64, limit int) []AddressTxEntry {
+	count := chainDB.getAddressTxCount(address)
+	start := sort.Search(int(count), func(i int) bool {
+		entry := chainDB.getAddressTxEntry(address, uint64(i))
+		return entry == nil || entry.BlockNumber >= fromBlock
+	})
+
+	var result []AddressTxEntry
+	for seq := uint64(start); seq < count; seq++ {
+		entry := chainDB.getAddressTxEntry(address, seq)
+		if entry == nil || entry.BlockNumber > toBlock {
+			break
+		}
+		if len(result) > 0 && len(result) >= limit && result[len(result)-1].BlockNumber != entry.BlockNumber {
+			break
+		}
+		// skip the entries of the rolled back blocks
+		if !chainDB.GetBlockHashByNumber(entry.BlockNumber).IsEqual(entry.BlockHash) {
+			continue
+		}
+		result = append(result, *entry)
+	}
+	return result
+}
@@ -0,0 +1,130 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chaindb
+
+import (
+	"fmt"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/util"
+	"github.com/dipperin/dipperin-core/core/contract"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+func createTokenTransferTx(nonce uint64, contractAddr, to common.Address) *model.Transaction {
+	key, _ := model.CreateKey()
+	params := util.StringifyJson([]string{to.Hex(), fmt.Sprintf("0x%x", 10)})
+	extraData := util.StringifyJsonToBytes(contract.ExtraDataForContract{ContractAddress: contractAddr, Action: "Transfer", Params: params})
+	tx := model.NewTransaction(nonce, contractAddr, big.NewInt(0), big.NewInt(10000), extraData)
+	signedTx, _ := tx.SignTx(key, model.NewMercurySigner(big.NewInt(1)))
+	return signedTx
+}
+
+func TestTxAddresses(t *testing.T) {
+	tx := model.CreateSignedTx(0, big.NewInt(100))
+	sender, _ := tx.Sender(nil)
+	assert.Equal(t, []common.Address{sender, *tx.To()}, txAddresses(tx))
+
+	contractAddr := common.HexToAddress("0x00102B9aE8e4a04B3d2Dc8a60D2E9E4E0A60A5b7aA30")
+	receiver := common.HexToAddress("0x0000b4293d60F051936beDecfaE1B85d5A46d377aF37")
+	assert.Equal(t, []common.Address{sender, contractAddr, receiver}, txAddresses(createTokenTransferTx(0, contractAddr, receiver)))
+
+	// the token sent to the sender itself
+	assert.Equal(t, []common.Address{sender, contractAddr}, txAddresses(createTokenTransferTx(0, contractAddr, sender)))
+}
+
+func TestChainDB_AddressTxEntries(t *testing.T) {
+	db := newChainDB()
+	assert.False(t, db.AddressIndexEnabled())
+
+	b1 := model.CreateBlock(1, common.Hash{}, 2)
+	assert.NoError(t, db.InsertBlock(b1))
+	sender, _ := b1.GetTransactions()[0].Sender(nil)
+	assert.Empty(t, db.GetAddressTxEntries(sender, 0, 10, 10))
+
+	db.EnableAddressIndex()
+	assert.True(t, db.AddressIndexEnabled())
+	assert.Equal(t, uint64(2), db.AddressIndexStart())
+
+	var blocks []*model.Block
+	for i := uint64(1); i <= 3; i++ {
+		b := model.CreateBlock(i, common.Hash{}, 2)
+		blocks = append(blocks, b)
+		assert.NoError(t, db.InsertBlock(b))
+	}
+	receiver := *blocks[0].GetTransactions()[0].To()
+
+	entries := db.GetAddressTxEntries(sender, 0, 10, 10)
+	assert.Len(t, entries, 6)
+	assert.Equal(t, entries, db.GetAddressTxEntries(receiver, 0, 10, 10))
+	assert.Equal(t, blocks[0].GetTransactions()[1].CalTxId(), entries[1].TxHash)
+	assert.Equal(t, blocks[0].Hash(), entries[1].BlockHash)
+	assert.Equal(t, uint64(1), entries[1].BlockNumber)
+	assert.Equal(t, uint64(1), entries[1].Index)
+
+	// block range
+	entries = db.GetAddressTxEntries(sender, 2, 2, 10)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, uint64(2), entries[0].BlockNumber)
+	assert.Len(t, db.GetAddressTxEntries(sender, 3, 10, 10), 2)
+	assert.Empty(t, db.GetAddressTxEntries(sender, 4, 10, 10))
+
+	// the txs of a block are never split
+	entries = db.GetAddressTxEntries(sender, 0, 10, 3)
+	assert.Len(t, entries, 4)
+	assert.Equal(t, uint64(2), entries[3].BlockNumber)
+	assert.Len(t, db.GetAddressTxEntries(sender, 0, 10, 0), 2)
+
+	// roll back to a block 3 with a token transfer
+	contractAddr := common.HexToAddress("0x00102B9aE8e4a04B3d2Dc8a60D2E9E4E0A60A5b7aA30")
+	tokenReceiver := common.HexToAddress("0x0000b4293d60F051936beDecfaE1B85d5A46d377aF37")
+	tx := createTokenTransferTx(4, contractAddr, tokenReceiver)
+	header := model.NewHeader(1, 3, blocks[1].Hash(), common.HexToHash("123456"), common.HexToDiff("1fffffff"), big.NewInt(1), sender, common.BlockNonce{})
+	newBlock := model.NewBlock(header, []*model.Transaction{tx}, nil)
+	assert.NoError(t, db.InsertBlock(newBlock))
+
+	entries = db.GetAddressTxEntries(sender, 0, 10, 10)
+	assert.Len(t, entries, 5)
+	assert.Equal(t, tx.CalTxId(), entries[4].TxHash)
+	assert.Equal(t, newBlock.Hash(), entries[4].BlockHash)
+	assert.Equal(t, entries[4:], db.GetAddressTxEntries(tokenReceiver, 0, 10, 10))
+	assert.Equal(t, entries[4:], db.GetAddressTxEntries(contractAddr, 0, 10, 10))
+
+	// the entries of the old block 3 are kept but no longer canonical
+	assert.Len(t, db.GetAddressTxEntries(receiver, 0, 10, 10), 4)
+	assert.Equal(t, uint64(6), db.getAddressTxCount(receiver))
+}
+
+func TestChainDB_AddressIndexStart(t *testing.T) {
+	db := newChainDB()
+	db.EnableAddressIndex()
+	assert.Equal(t, uint64(0), db.AddressIndexStart())
+
+	// the start is kept while the index is enabled
+	assert.NoError(t, db.InsertBlock(model.CreateBlock(1, common.Hash{}, 1)))
+	db.EnableAddressIndex()
+	assert.Equal(t, uint64(0), db.AddressIndexStart())
+
+	// the blocks saved while disabled are not indexed
+	db.DisableAddressIndex()
+	assert.False(t, db.AddressIndexEnabled())
+	assert.NoError(t, db.InsertBlock(model.CreateBlock(2, common.Hash{}, 1)))
+	db.EnableAddressIndex()
+	assert.Equal(t, uint64(3), db.AddressIndexStart())
+}
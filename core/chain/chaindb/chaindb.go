@@ -28,6 +28,8 @@ import (
 type ChainDB struct {
 	db      ethdb.Database
 	decoder model.BlockDecoder
+
+	addressIndex bool
 }
 
 func NewChainDB(db ethdb.Database, decoder model.BlockDecoder) *ChainDB {
@@ -45,6 +47,9 @@ func (chainDB *ChainDB) InsertBlock(block model.AbstractBlock) error {
 
 	chainDB.SaveBlock(block)
 	chainDB.SaveTxLookupEntries(block)
+	if chainDB.addressIndex {
+		chainDB.SaveAddressTxEntries(block)
+	}
 
 	chainDB.SaveBlockHash(block.Hash(), block.Number())
 	chainDB.SaveHeadBlockHash(block.Hash())
@@ -258,6 +263,7 @@ func (chainDB *ChainDB) SaveBlock(block model.AbstractBlock) {
 func (chainDB *ChainDB) DeleteBlock(hash common.Hash, number uint64) {
 	chainDB.DeleteHeader(hash, number)
 	chainDB.DeleteBody(hash, number)
+	chainDB.DeleteReceipts(hash, number)
 }
 
 /*func (chainDB *ChainDB) FindCommonAncestor(a, b model.AbstractHeader) model.AbstractHeader {
@@ -362,6 +368,53 @@ func (chainDB *ChainDB) GetTransaction(txHash common.Hash) (model.AbstractTransa
 	return body.GetTxByIndex(int(txIndex)), blockHash, blockNumber, txIndex
 }
 
+func (chainDB *ChainDB) GetReceipts(hash common.Hash, number uint64) model.Receipts {
+	data, _ := chainDB.db.Get(blockReceiptsKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	var receipts model.Receipts
+	if err := rlp.DecodeBytes(data, &receipts); err != nil {
+		log.Error("Invalid receipts RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return receipts
+}
+
+func (chainDB *ChainDB) SaveReceipts(hash common.Hash, number uint64, receipts model.Receipts) {
+	data, err := rlp.EncodeToBytes(receipts)
+	if err != nil {
+		log.Crit("Failed to RLP encode receipts", "err", err)
+		return
+	}
+	if err := chainDB.db.Put(blockReceiptsKey(number, hash), data); err != nil {
+		log.Crit("Failed to store block receipts", "err", err)
+	}
+}
+
+func (chainDB *ChainDB) DeleteReceipts(hash common.Hash, number uint64) {
+	if err := chainDB.db.Delete(blockReceiptsKey(number, hash)); err != nil {
+		log.Crit("Failed to delete block receipts", "err", err)
+	}
+}
+
+// get the receipt of the mined tx with the block fields filled
+func (chainDB *ChainDB) GetReceipt(txHash common.Hash) *model.Receipt {
+	blockHash, blockNumber, txIndex := chainDB.GetTxLookupEntry(txHash)
+	if blockHash == (common.Hash{}) {
+		return nil
+	}
+
+	receipts := chainDB.GetReceipts(blockHash, blockNumber)
+	if len(receipts) <= int(txIndex) {
+		log.Error("Receipt referenced missing", "number", blockNumber, "hash", blockHash, "index", txIndex)
+		return nil
+	}
+	receipt := receipts[txIndex]
+	receipt.BlockHash, receipt.BlockNumber, receipt.TxIndex = blockHash, blockNumber, txIndex
+	return receipt
+}
+
 /*func (chainDB *ChainDB) GetInterLink(root common.Hash) (model.InterLink, error) {
 	return nil, nil
 }
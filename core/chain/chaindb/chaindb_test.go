@@ -18,8 +18,10 @@ package chaindb
 
 import (
 	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/core/model"
 	"testing"
 	"github.com/stretchr/testify/assert"
+	"math/big"
 )
 
 func TestChainDB_InsertBlock(t *testing.T) {
@@ -261,6 +263,45 @@ func TestReadTransaction(t *testing.T) {
 	assert.Nil(t, tx)
 }
 
+func TestChainDB_Receipts(t *testing.T) {
+	db := newChainDB()
+	b := createBlock(22)
+	assert.Nil(t, db.GetReceipts(b.Hash(), b.Number()))
+
+	var receipts model.Receipts
+	for i, tx := range b.GetTransactions() {
+		receipts = append(receipts, &model.Receipt{TxHash: tx.CalTxId(), Status: model.ReceiptStatusSuccessful, Fee: big.NewInt(int64(i))})
+	}
+	receipts[1].Status = model.ReceiptStatusFailed
+	receipts[1].Err = "contract method return false"
+	receipts[1].TokenEvents = []*model.TokenEvent{{Action: "Transfer", Value: big.NewInt(1)}}
+	db.SaveReceipts(b.Hash(), b.Number(), receipts)
+
+	saved := db.GetReceipts(b.Hash(), b.Number())
+	assert.Len(t, saved, len(receipts))
+	assert.Equal(t, receipts[1].Err, saved[1].Err)
+	assert.Equal(t, receipts[1].TokenEvents, saved[1].TokenEvents)
+
+	// the receipt is found by the tx lookup entry
+	txHash := b.GetTransactions()[1].CalTxId()
+	assert.Nil(t, db.GetReceipt(txHash))
+	db.SaveTxLookupEntries(b)
+	receipt := db.GetReceipt(txHash)
+	assert.Equal(t, txHash, receipt.TxHash)
+	assert.True(t, receipt.Failed())
+	assert.Equal(t, big.NewInt(1), receipt.Fee)
+	assert.Equal(t, b.Hash(), receipt.BlockHash)
+	assert.Equal(t, b.Number(), receipt.BlockNumber)
+	assert.Equal(t, uint64(1), receipt.TxIndex)
+
+	db.DeleteBlock(b.Hash(), b.Number())
+	assert.Nil(t, db.GetReceipts(b.Hash(), b.Number()))
+	assert.Nil(t, db.GetReceipt(txHash))
+
+	db.SaveReceipts(b.Hash(), b.Number(), receipts[:1])
+	assert.Nil(t, db.GetReceipt(txHash))
+}
+
 func TestChainDB_DB(t *testing.T) {
 	db := newChainDB()
 	assert.NotNil(t, db.DB())
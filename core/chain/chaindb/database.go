@@ -65,5 +65,15 @@ type Database interface {
 
 	GetTransaction(txHash common.Hash) (model.AbstractTransaction, common.Hash, uint64, uint64)
 
+	GetReceipts(hash common.Hash, number uint64) model.Receipts
+	SaveReceipts(hash common.Hash, number uint64, receipts model.Receipts)
+	DeleteReceipts(hash common.Hash, number uint64)
+	GetReceipt(txHash common.Hash) *model.Receipt
+
+	AddressIndexEnabled() bool
+	AddressIndexStart() uint64
+	SaveAddressTxEntries(block model.AbstractBlock)
+	GetAddressTxEntries(address common.Address, fromBlock, toBlock uint64, limit int) []AddressTxEntry
+
 	InsertBlock(block model.AbstractBlock) error
 }
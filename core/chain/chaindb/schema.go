@@ -39,6 +39,9 @@ var (
 	// fastTrieProgressKey tracks the number of trie entries imported during fast sync.
 	fastTrieProgressKey = []byte("TrieSync")
 
+	// addressIndexStartKey tracks the first block of the address index.
+	addressIndexStartKey = []byte("AddressIndexStart")
+
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`, used for indexes).
 	headerPrefix       = []byte("h") // headerPrefix + num (uint64 big endian) + hash -> header
 	headerTDSuffix     = []byte("t") // headerPrefix + num (uint64 big endian) + hash + headerTDSuffix -> td
@@ -49,6 +52,9 @@ var (
 	blockReceiptsPrefix = []byte("r") // blockReceiptsPrefix + num (uint64 big endian) + hash -> block receipts
 
 	txLookupPrefix  = []byte("l") // txLookupPrefix + hash -> transaction/receipt lookup metadata
+
+	addressTxCountPrefix = []byte("a") // addressTxCountPrefix + address -> number of the address tx entries (uint64 big endian)
+	addressTxPrefix      = []byte("A") // addressTxPrefix + address + seq (uint64 big endian) -> address tx entry
 	bloomBitsPrefix = []byte("B") // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
 
 	preimagePrefix = []byte("secure-key-")      // preimagePrefix + hash -> preimage
@@ -71,6 +77,15 @@ type TxLookupEntry struct {
 	Index      uint64
 }
 
+// AddressTxEntry locates a transaction sent from or to the address, the entries
+// of an address are stored in the order of the block number.
+type AddressTxEntry struct {
+	TxHash      common.Hash
+	BlockHash   common.Hash
+	BlockNumber uint64
+	Index       uint64
+}
+
 // encodeBlockNumber encodes a block number as big endian uint64
 func encodeBlockNumber(number uint64) []byte {
 	enc := make([]byte, 8)
@@ -104,15 +119,25 @@ func blockBodyKey(number uint64, hash common.Hash) []byte {
 }
 
 // blockReceiptsKey = blockReceiptsPrefix + num (uint64 big endian) + hash
-/*func blockReceiptsKey(number uint64, hash common.Hash) []byte {
+func blockReceiptsKey(number uint64, hash common.Hash) []byte {
 	return append(append(blockReceiptsPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
-}*/
+}
 
 // txLookupKey = txLookupPrefix + hash
 func txLookupKey(hash common.Hash) []byte {
 	return append(txLookupPrefix, hash.Bytes()...)
 }
 
+// addressTxCountKey = addressTxCountPrefix + address
+func addressTxCountKey(address common.Address) []byte {
+	return append(append([]byte{}, addressTxCountPrefix...), address.Bytes()...)
+}
+
+// addressTxKey = addressTxPrefix + address + seq (uint64 big endian)
+func addressTxKey(address common.Address, seq uint64) []byte {
+	return append(append(append([]byte{}, addressTxPrefix...), address.Bytes()...), encodeBlockNumber(seq)...)
+}
+
 // bloomBitsKey = bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash
 /*func bloomBitsKey(bit uint, section uint64, hash common.Hash) []byte {
 	key := append(append(bloomBitsPrefix, make([]byte, 10)...), hash.Bytes()...)
@@ -44,6 +44,9 @@ import (
 //the verifier address to generate the first twenty blocks
 var VerifierAddress []common.Address
 
+//the alloc of the local dev chain genesis, set by SetDevGenesis
+var devGenesisAlloc GenesisAlloc
+
 //delete angle verifier csWallet cipher in the Dipperin-core source code
 func init() {
 	env := os.Getenv("boots_env")
@@ -58,6 +61,12 @@ func init() {
 	chain_config.VerBootNodeAddress = chain_config.VerifierBootNodeAddress
 }
 
+//SetDevGenesis makes the default genesis the genesis of the local dev chain, the verifiers replace the default verifiers
+func SetDevGenesis(alloc GenesisAlloc, verifiers []common.Address) {
+	devGenesisAlloc = alloc
+	VerifierAddress = verifiers
+}
+
 var errGenesisNoConfig = errors.New("genesis has no chain configuration")
 // GenesisMismatchError is raised when trying to overwrite an existing
 // genesis block with an incompatible one.
@@ -458,6 +467,14 @@ func DefaultGenesisBlock(chainDB chaindb.Database, accountStateProcessor state_p
 		return mGenesis
 	}
 
+	if fileGenesis != nil {
+		return fileGenesis.toGenesis(chainDB, accountStateProcessor, registerProcessor)
+	}
+
+	if devGenesisAlloc != nil {
+		return devGenesisBlock(chainDB, accountStateProcessor, registerProcessor, chainConf)
+	}
+
 	gTime, _ := time.Parse("2006-01-02 15:04:05", "2018-08-08 08:08:08")
 	// use to reset test chain
 	if chain_config.GetCurBootsEnv() == "test" {
@@ -503,6 +520,41 @@ func DefaultGenesisBlock(chainDB chaindb.Database, accountStateProcessor state_p
 	}
 }
 
+func devGenesisBlock(chainDB chaindb.Database, accountStateProcessor state_processor.AccountStateProcessor, registerProcessor registerdb.RegisterProcessor, chainConf *chain_config.ChainConfig) *Genesis {
+	// the economy model allocs are merged into the alloc
+	alloc := GenesisAlloc{}
+	for addr, balance := range devGenesisAlloc {
+		alloc[addr] = new(big.Int).Set(balance)
+	}
+
+	gTime, _ := time.Parse("2006-01-02 15:04:05", "2019-01-01 00:00:00")
+	return &Genesis{
+		ChainDB:               chainDB,
+		AccountStateProcessor: accountStateProcessor,
+		RegisterProcessor:     registerProcessor,
+		Config:                chain_config.GetChainConfig(),
+		Timestamp:             big.NewInt(gTime.UnixNano()),
+		ExtraData:             []byte("dipperin dev Genesis"),
+		Difficulty:            chain_config.GenesisDifficulty,
+		Alloc:                 alloc,
+		Verifiers:             VerifierAddress[:chainConf.VerifierNumber],
+	}
+}
+
+// GenesisFileName is the name of the genesis file copied into the data dir by the init command
+const GenesisFileName = "genesis.json"
+
+var (
+	errGenesisNoVerifier        = errors.New("genesis file has no verifier")
+	errGenesisVerifierNumber    = errors.New("genesis file has less verifiers than the verifier number")
+	errGenesisAccountBalance    = errors.New("genesis account balance must be positive")
+	errGenesisEconomyIncomplete = errors.New("genesis economy proportion needs a positive base number, all the proportions and only one early token address")
+	errGenesisUnknownFork       = errors.New("genesis file has an unknown fork")
+)
+
+//the genesis file loaded by LoadGenesisFile, it's used as the default genesis
+var fileGenesis *genesisCfgFile
+
 type genesisCfgFile struct {
 	Nonce uint64 `json:"nonce"`
 	//Note       string           `json:"note"`
@@ -510,7 +562,16 @@ type genesisCfgFile struct {
 	Timestamp  string           `json:"timestamp"`
 	Difficulty string           `json:"difficulty" gencodec:"required"`
 	Verifiers  []string         `json:"verifiers" gencodec:"required"`
-	// todo add a foundation configuration
+
+	// the chain config of the private network, the default value is kept if not set
+	ChainId        *big.Int `json:"chain_id"`
+	NetworkId      uint64   `json:"network_id"`
+	SlotSize       uint64   `json:"slot_size"`
+	VerifierNumber int      `json:"verifier_number"`
+	// the pre-mining proportion of each economy model address
+	Economy *economy_model.AddressDIPProportion `json:"economy"`
+	// activation block of the forks, the forks not set are active from the genesis
+	Forks map[string]uint64 `json:"forks"`
 }
 
 func GenesisBlockFromFile(chainDB chaindb.Database, accountStateProcessor state_processor.AccountStateProcessor) *Genesis {
@@ -529,8 +590,12 @@ func GenesisBlockFromFile(chainDB chaindb.Database, accountStateProcessor state_
 		log.Error("unmarshal genesisCfgFile failed", "storageErr", err)
 		return nil
 	}
+	return info.toGenesis(chainDB, accountStateProcessor, nil)
+}
 
+func (info *genesisCfgFile) toGenesis(chainDB chaindb.Database, accountStateProcessor state_processor.AccountStateProcessor, registerProcessor registerdb.RegisterProcessor) *Genesis {
 	var gTime time.Time
+	var err error
 	if gTime, err = time.Parse("2006-01-02 15:04:05", info.Timestamp); err != nil {
 		gTime, _ = time.Parse("2006-01-02 15:04:05", "2018-08-08 08:08:08")
 	}
@@ -543,21 +608,113 @@ func GenesisBlockFromFile(chainDB chaindb.Database, accountStateProcessor state_
 		alloc[common.HexToAddress(k)] = big.NewInt(v * consts.DIP)
 	}
 
-	var verifiers []common.Address
-	for _, v := range info.Verifiers {
-		verifiers = append(verifiers, common.HexToAddress(v))
-	}
-
 	return &Genesis{
 		ChainDB: chainDB,
 
 		AccountStateProcessor: accountStateProcessor,
+		RegisterProcessor:     registerProcessor,
 		Config:                chain_config.GetChainConfig(),
 		Nonce:                 info.Nonce,
 		Timestamp:             big.NewInt(gTime.UnixNano()),
 		//ExtraData:             []byte(info.Note),
 		Difficulty: common.HexToDiff(info.Difficulty),
 		Alloc:      alloc,
-		Verifiers:  verifiers,
+		Verifiers:  info.verifiers(),
+	}
+}
+
+func (info *genesisCfgFile) verifiers() (verifiers []common.Address) {
+	for _, v := range info.Verifiers {
+		verifiers = append(verifiers, common.HexToAddress(v))
+	}
+	return
+}
+
+func (info *genesisCfgFile) valid(chainConf *chain_config.ChainConfig) error {
+	if len(info.Verifiers) == 0 {
+		return errGenesisNoVerifier
+	}
+	verifierNumber := chainConf.VerifierNumber
+	if info.VerifierNumber != 0 {
+		verifierNumber = info.VerifierNumber
+	}
+	if len(info.Verifiers) < verifierNumber {
+		return errGenesisVerifierNumber
+	}
+	for _, v := range info.Accounts {
+		if v <= 0 {
+			return errGenesisAccountBalance
+		}
+	}
+
+	if p := info.Economy; p != nil {
+		if p.BaseNumber <= 0 || len(p.InvestorProportion) == 0 || len(p.DeveloperProportion) == 0 || len(p.MaintenanceProportion) == 0 ||
+			len(p.ReMainRewardProportion) == 0 || len(p.EarlyTokenProportion) != 1 {
+			return errGenesisEconomyIncomplete
+		}
+	}
+	for name := range info.Forks {
+		if !chain_config.IsKnownFork(name) {
+			return errGenesisUnknownFork
+		}
+	}
+	return nil
+}
+
+// LoadGenesisFile applies the chain config, the verifiers and the economy proportion of the genesis file,
+// and the genesis block of the file becomes the default genesis
+func LoadGenesisFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var info genesisCfgFile
+	if err = json.Unmarshal(data, &info); err != nil {
+		return err
+	}
+	chainConf := chain_config.GetChainConfig()
+	if err = info.valid(chainConf); err != nil {
+		return err
+	}
+
+	if info.ChainId != nil {
+		chainConf.ChainId = info.ChainId
+	}
+	if info.NetworkId != 0 {
+		chainConf.NetworkID = info.NetworkId
+	}
+	if info.SlotSize != 0 {
+		chainConf.SlotSize = info.SlotSize
+	}
+	if info.VerifierNumber != 0 {
+		chainConf.VerifierNumber = info.VerifierNumber
+	}
+	if len(info.Forks) > 0 {
+		forks := make(map[string]uint64, len(chainConf.Forks))
+		for name, block := range chainConf.Forks {
+			forks[name] = block
+		}
+		for name, block := range info.Forks {
+			forks[name] = block
+		}
+		chainConf.Forks = forks
+	}
+	VerifierAddress = info.verifiers()
+	if info.Economy != nil {
+		economy_model.SetDIPProportion(*info.Economy)
+		contract.InitEarlyRewardContract()
+	}
+
+	fileGenesis = &info
+	log.Info("load genesis file", "path", path, "chain id", chainConf.ChainId, "network id", chainConf.NetworkID)
+	return nil
+}
+
+// LoadDataDirGenesis loads the genesis file copied into the data dir by the init command, nothing changes if there isn't the file
+func LoadDataDirGenesis(dataDir string) error {
+	path := filepath.Join(dataDir, GenesisFileName)
+	if !common.FileExist(path) {
+		return nil
 	}
+	return LoadGenesisFile(path)
 }
@@ -32,6 +32,9 @@ import (
 	"github.com/dipperin/dipperin-core/common/g-error"
 	"github.com/dipperin/dipperin-core/third-party/log"
 	"encoding/json"
+	"github.com/dipperin/dipperin-core/core/chain-config"
+	"github.com/dipperin/dipperin-core/core/contract"
+	"github.com/dipperin/dipperin-core/core/economy-model"
 )
 
 func TestSetupGenesisBlock(t *testing.T) {
@@ -248,4 +251,105 @@ func TestGenesis_SetEarlyTokenContract_Error(t *testing.T) {
 
 	err = defaultGenesis.SetEarlyTokenContract()
 	assert.Equal(t, TrieError, err)
-}
\ No newline at end of file
+}
+func TestSetDevGenesis(t *testing.T) {
+	conf := chain_config.GetChainConfig()
+	verifierNumber, verifiers := conf.VerifierNumber, VerifierAddress
+	defer func() {
+		conf.VerifierNumber = verifierNumber
+		SetDevGenesis(nil, verifiers)
+	}()
+
+	conf.VerifierNumber = 1
+	SetDevGenesis(GenesisAlloc{aliceAddr: big.NewInt(100)}, []common.Address{bobAddr})
+	genesis := createGenesis()
+	assert.Equal(t, []common.Address{bobAddr}, genesis.Verifiers)
+	assert.Equal(t, big.NewInt(100), genesis.Alloc[aliceAddr])
+	assert.Equal(t, []byte("dipperin dev Genesis"), genesis.ExtraData)
+
+	// the alloc of the dev genesis isn't changed by the genesis
+	genesis.Alloc[aliceAddr].SetInt64(1)
+	assert.Equal(t, big.NewInt(100), createGenesis().Alloc[aliceAddr])
+}
+
+func TestLoadGenesisFile(t *testing.T) {
+	conf := chain_config.GetChainConfig()
+	oldConf, verifiers, proportion := *conf, VerifierAddress, economy_model.DIPProportion
+	defer func() {
+		*conf = oldConf
+		VerifierAddress = verifiers
+		fileGenesis = nil
+		economy_model.SetDIPProportion(proportion)
+		contract.InitEarlyRewardContract()
+	}()
+
+	dir, err := ioutil.TempDir("", "genesis_file")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.NoError(t, LoadDataDirGenesis(dir))
+	assert.Error(t, LoadGenesisFile(filepath.Join(dir, GenesisFileName)))
+
+	earlyTokenAddr := common.HexToAddress("0x0000000000000000000000000000000000000000aa04")
+	cfg := genesisCfgFile{
+		Accounts:       map[string]int64{aliceAddr.String(): 10},
+		Verifiers:      []string{bobAddr.String()},
+		ChainId:        big.NewInt(1234),
+		NetworkId:      77,
+		SlotSize:       10,
+		VerifierNumber: 1,
+		Economy: &economy_model.AddressDIPProportion{
+			BaseNumber:             100,
+			InvestorProportion:     map[string]int{"0x0000000000000000000000000000000000000000aa01": 100},
+			DeveloperProportion:    map[string]int{"0x0000000000000000000000000000000000000000aa02": 100},
+			MaintenanceProportion:  map[string]int{"0x0000000000000000000000000000000000000000aa03": 100},
+			EarlyTokenProportion:   map[string]int{earlyTokenAddr.String(): 100},
+			ReMainRewardProportion: map[string]int{"0x0000000000000000000000000000000000000000aa05": 100},
+		},
+	}
+	writeFile := func() {
+		bytes, err := json.Marshal(cfg)
+		assert.NoError(t, err)
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, GenesisFileName), bytes, 0666))
+	}
+
+	// invalid files don't change the config
+	cfg.VerifierNumber = 2
+	writeFile()
+	assert.Equal(t, errGenesisVerifierNumber, LoadDataDirGenesis(dir))
+	cfg.VerifierNumber = 1
+	cfg.Accounts[aliceAddr.String()] = -1
+	writeFile()
+	assert.Equal(t, errGenesisAccountBalance, LoadDataDirGenesis(dir))
+	cfg.Accounts[aliceAddr.String()] = 10
+	cfg.Economy.EarlyTokenProportion["0x0000000000000000000000000000000000000000aa06"] = 100
+	writeFile()
+	assert.Equal(t, errGenesisEconomyIncomplete, LoadDataDirGenesis(dir))
+	delete(cfg.Economy.EarlyTokenProportion, "0x0000000000000000000000000000000000000000aa06")
+	cfg.Forks = map[string]uint64{"unknown": 10}
+	writeFile()
+	assert.Equal(t, errGenesisUnknownFork, LoadDataDirGenesis(dir))
+	cfg.Forks = map[string]uint64{chain_config.ForkDelegation: 10}
+	assert.Equal(t, oldConf, *conf)
+
+	writeFile()
+	assert.NoError(t, LoadDataDirGenesis(dir))
+	assert.Equal(t, big.NewInt(1234), conf.ChainId)
+	assert.Equal(t, uint64(77), conf.NetworkID)
+	assert.Equal(t, uint64(10), conf.SlotSize)
+	assert.Equal(t, 1, conf.VerifierNumber)
+	assert.False(t, conf.IsForkActive(chain_config.ForkDelegation, 9))
+	assert.True(t, conf.IsForkActive(chain_config.ForkMultiSig, 0))
+	assert.Equal(t, uint64(0), oldConf.Forks[chain_config.ForkDelegation])
+	assert.Equal(t, []common.Address{bobAddr}, VerifierAddress)
+	assert.Equal(t, []common.Address{earlyTokenAddr}, economy_model.EarlyTokenAddresses)
+
+	genesis := createGenesis()
+	assert.Equal(t, []common.Address{bobAddr}, genesis.Verifiers)
+	assert.Equal(t, big.NewInt(10*consts.DIP), genesis.Alloc[aliceAddr])
+	_, _, err = SetupGenesisBlock(genesis)
+	assert.NoError(t, err)
+	balance, err := genesis.AccountStateProcessor.GetBalance(common.HexToAddress("0x0000000000000000000000000000000000000000aa02"))
+	assert.NoError(t, err)
+	assert.Equal(t, economy_model.DeveloperDIP, balance)
+}
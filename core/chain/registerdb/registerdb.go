@@ -232,6 +232,7 @@ func (register RegisterDB) Commit() (root common.Hash, err error) {
 	if root, err = register.trie.Commit(nil); err != nil {
 		return root, err
 	}
+	// the register tries are small and needed to get the slot of any block, they aren't pruned
 	err = register.storage.TrieDB().Commit(root, false)
 	return root, err
 }
@@ -0,0 +1,102 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package state_processor
+
+import (
+	"bytes"
+	"errors"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/third-party/crypto"
+	"github.com/dipperin/dipperin-core/third-party/trie"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"math/big"
+)
+
+var (
+	AccountProofErr = errors.New("account proof not match the state root")
+)
+
+// AccountProof holds the account fields and the state trie nodes proving them
+type AccountProof struct {
+	Address common.Address
+	Nonce   uint64
+	Balance *big.Int
+	Stake   *big.Int
+	// encoded trie nodes on the paths from the state root to the nonce, balance and stake keys
+	Proof [][]byte
+}
+
+// the account fields are stored under separate keys of the state trie
+func accountProofKeys(addr common.Address) [][]byte {
+	return [][]byte{GetNonceKey(addr), GetBalanceKey(addr), GetStakeKey(addr)}
+}
+
+// GetAccountProof prove the nonce, balance and stake of the account against the state root
+func (state *AccountStateDB) GetAccountProof(addr common.Address) (*AccountProof, error) {
+	nonce, err := state.GetNonce(addr)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := state.GetBalance(addr)
+	if err != nil {
+		return nil, err
+	}
+	stake, err := state.GetStake(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// the state trie is a secure trie, the nodes are keyed by the hash of the key
+	proofDb := ethdb.NewMemDatabase()
+	for _, key := range accountProofKeys(addr) {
+		if err := state.blockStateTrie.Prove(crypto.Keccak256(key), 0, proofDb); err != nil {
+			return nil, err
+		}
+	}
+
+	proof := &AccountProof{Address: addr, Nonce: nonce, Balance: balance, Stake: stake}
+	for _, k := range proofDb.Keys() {
+		node, _ := proofDb.Get(k)
+		proof.Proof = append(proof.Proof, node)
+	}
+	return proof, nil
+}
+
+// VerifyAccountProof check the account fields in the proof are stored in the state trie with the root
+func VerifyAccountProof(root common.Hash, proof *AccountProof) error {
+	if proof == nil || proof.Balance == nil || proof.Stake == nil {
+		return AccountProofErr
+	}
+
+	proofDb := ethdb.NewMemDatabase()
+	for _, node := range proof.Proof {
+		proofDb.Put(crypto.Keccak256(node), node)
+	}
+
+	nonce, _ := rlp.EncodeToBytes(proof.Nonce)
+	balance, _ := rlp.EncodeToBytes(proof.Balance)
+	stake, _ := rlp.EncodeToBytes(proof.Stake)
+	want := [][]byte{nonce, balance, stake}
+	for i, key := range accountProofKeys(proof.Address) {
+		value, _, err := trie.VerifyProof(root, crypto.Keccak256(key), proofDb)
+		if err != nil || !bytes.Equal(value, want[i]) {
+			return AccountProofErr
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package state_processor
+
+import (
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/g-error"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+func TestAccountStateDB_GetAccountProof(t *testing.T) {
+	db, root := createTestStateDB()
+	processor, err := NewAccountStateDB(root, NewStateStorageWithCache(db))
+	assert.NoError(t, err)
+
+	_, err = processor.GetAccountProof(common.HexToAddress("0x1234"))
+	assert.Equal(t, g_error.AccountNotExist, err)
+
+	proof, err := processor.GetAccountProof(aliceAddr)
+	assert.NoError(t, err)
+	balance, _ := processor.GetBalance(aliceAddr)
+	nonce, _ := processor.GetNonce(aliceAddr)
+	assert.Equal(t, balance, proof.Balance)
+	assert.Equal(t, nonce, proof.Nonce)
+	assert.Equal(t, big.NewInt(0), proof.Stake)
+	assert.NotEmpty(t, proof.Proof)
+
+	assert.NoError(t, VerifyAccountProof(root, proof))
+	assert.Equal(t, AccountProofErr, VerifyAccountProof(common.HexToHash("0x1234"), proof))
+	assert.Equal(t, AccountProofErr, VerifyAccountProof(root, nil))
+
+	// a forged balance
+	forged := *proof
+	forged.Balance = new(big.Int).Add(proof.Balance, big.NewInt(1))
+	assert.Equal(t, AccountProofErr, VerifyAccountProof(root, &forged))
+
+	// the proof of another account
+	forged = *proof
+	forged.Address = bobAddr
+	assert.Equal(t, AccountProofErr, VerifyAccountProof(root, &forged))
+
+	// missing nodes
+	forged = *proof
+	forged.Proof = proof.Proof[1:]
+	assert.Equal(t, AccountProofErr, VerifyAccountProof(root, &forged))
+}
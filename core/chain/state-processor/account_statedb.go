@@ -19,6 +19,7 @@ package state_processor
 import (
 	"github.com/dipperin/dipperin-core/common"
 	"github.com/dipperin/dipperin-core/common/util/json-kv"
+	"github.com/dipperin/dipperin-core/core/chain-config"
 	"github.com/dipperin/dipperin-core/core/contract"
 	"github.com/dipperin/dipperin-core/core/model"
 	"github.com/dipperin/dipperin-core/third-party/log"
@@ -70,6 +71,10 @@ const (
 	verifyNumKeySuffix = "_verify_num"
 	lastElectKeySuffix = "_last_elect"
 	performanceSuffix  = "_performance"
+	multiSigKeySuffix  = "_multi_sig"
+	jailedKeySuffix    = "_jailed"
+	delegationKeySuffix = "_delegation"
+	evidenceKeySuffix   = "_evidence"
 )
 
 func GetContractFieldKey(address common.Address, key string) []byte {
@@ -129,6 +134,22 @@ func GetPerformanceKey(address common.Address) []byte {
 	return append(address[:], []byte(performanceSuffix)...)
 }
 
+func GetMultiSigKey(address common.Address) []byte {
+	return append(address[:], []byte(multiSigKeySuffix)...)
+}
+
+func GetJailedKey(address common.Address) []byte {
+	return append(address[:], []byte(jailedKeySuffix)...)
+}
+
+func GetDelegationKey(address common.Address) []byte {
+	return append(address[:], []byte(delegationKeySuffix)...)
+}
+
+func GetEvidenceKey(address common.Address) []byte {
+	return append(address[:], []byte(evidenceKeySuffix)...)
+}
+
 func (a *account) getNonce() uint64 {
 	return a.Nonce
 }
@@ -527,6 +548,81 @@ func (state *AccountStateDB) GetLastElect(addr common.Address) (uint64, error) {
 	return res, nil
 }
 
+// GetMultiSigKeySet returns the registered key set of the multi signature account
+func (state *AccountStateDB) GetMultiSigKeySet(addr common.Address) (*model.MultiSigKeySet, error) {
+	empty := state.IsEmptyAccount(addr)
+	if empty {
+		return nil, g_error.AccountNotExist
+	}
+	enc, err1 := state.blockStateTrie.TryGet(GetMultiSigKey(addr))
+	if err1 != nil {
+		return nil, err1
+	}
+	if len(enc) == 0 {
+		return nil, MultiSigNotRegisteredErr
+	}
+	var res model.MultiSigKeySet
+	err2 := rlp.DecodeBytes(enc, &res)
+	if err2 != nil {
+		return nil, err2
+	}
+	return &res, nil
+}
+
+// GetJailedUntil returns the block number the verifier is jailed until, 0 if it has never been jailed
+func (state *AccountStateDB) GetJailedUntil(addr common.Address) (uint64, error) {
+	empty := state.IsEmptyAccount(addr)
+	if empty {
+		return 0, g_error.AccountNotExist
+	}
+	enc, err1 := state.blockStateTrie.TryGet(GetJailedKey(addr))
+	if err1 != nil || len(enc) == 0 {
+		return 0, err1
+	}
+	var res uint64
+	err2 := rlp.DecodeBytes(enc, &res)
+	if err2 != nil {
+		return 0, err2
+	}
+	return res, nil
+}
+
+// GetDelegations returns the delegations bonded to the verifier
+func (state *AccountStateDB) GetDelegations(addr common.Address) ([]model.Delegation, error) {
+	empty := state.IsEmptyAccount(addr)
+	if empty {
+		return nil, g_error.AccountNotExist
+	}
+	enc, err1 := state.blockStateTrie.TryGet(GetDelegationKey(addr))
+	if err1 != nil || len(enc) == 0 {
+		return nil, err1
+	}
+	var res []model.Delegation
+	err2 := rlp.DecodeBytes(enc, &res)
+	if err2 != nil {
+		return nil, err2
+	}
+	return res, nil
+}
+
+// GetSlashedVotes returns the double signs of the verifier punished within the evidence window
+func (state *AccountStateDB) GetSlashedVotes(addr common.Address) ([]model.SlashedVote, error) {
+	empty := state.IsEmptyAccount(addr)
+	if empty {
+		return nil, g_error.AccountNotExist
+	}
+	enc, err1 := state.blockStateTrie.TryGet(GetEvidenceKey(addr))
+	if err1 != nil || len(enc) == 0 {
+		return nil, err1
+	}
+	var res []model.SlashedVote
+	err2 := rlp.DecodeBytes(enc, &res)
+	if err2 != nil {
+		return nil, err2
+	}
+	return res, nil
+}
+
 //func (state *AccountStateDB) GetContractRoot(addr common.Address) (common.Hash, error) {
 //    empty := state.IsEmptyAccount(addr)
 //    if empty {
@@ -929,6 +1025,112 @@ func (state *AccountStateDB) setLastElect(addr common.Address, blockID uint64) e
 	return nil
 }
 
+func (state *AccountStateDB) SetMultiSigKeySet(addr common.Address, keySet *model.MultiSigKeySet) error {
+	old, _ := state.blockStateTrie.TryGet(GetMultiSigKey(addr))
+	newEnc, _ := rlp.EncodeToBytes(keySet)
+	err := state.setMultiSigKeySet(addr, newEnc)
+	if err != nil {
+		return err
+	}
+	state.stateChangeList.append(multiSigChange{Account: &addr, Prev: old, Current: newEnc, ChangeType: MultiSigChange})
+	return nil
+}
+
+//setMultiSigKeySet do not change the changelist, usually called by the revert operation. Empty enc removes the key set
+func (state *AccountStateDB) setMultiSigKeySet(addr common.Address, enc []byte) error {
+	empty := state.IsEmptyAccount(addr)
+	if empty {
+		return g_error.AccountNotExist
+	}
+
+	mpt_log.Debug("setMultiSigKeySet", "addr", addr.Hex(), "pre state", state.preStateRoot.Hex())
+	if len(enc) == 0 {
+		return state.blockStateTrie.TryDelete(GetMultiSigKey(addr))
+	}
+	return state.blockStateTrie.TryUpdate(GetMultiSigKey(addr), enc)
+}
+
+func (state *AccountStateDB) SetJailedUntil(addr common.Address, blockNum uint64) error {
+	old, _ := state.GetJailedUntil(addr)
+	err := state.setJailedUntil(addr, blockNum)
+	if err != nil {
+		return err
+	}
+	state.stateChangeList.append(jailChange{Account: &addr, Prev: old, Current: blockNum, ChangeType: JailChange})
+	return nil
+}
+
+//setJailedUntil do not change the changelist, usually called by the revert operation. 0 removes the record
+func (state *AccountStateDB) setJailedUntil(addr common.Address, blockNum uint64) error {
+	empty := state.IsEmptyAccount(addr)
+	if empty {
+		return g_error.AccountNotExist
+	}
+
+	mpt_log.Debug("setJailedUntil", "addr", addr.Hex(), "v", blockNum, "pre state", state.preStateRoot.Hex())
+	if blockNum == 0 {
+		return state.blockStateTrie.TryDelete(GetJailedKey(addr))
+	}
+	enc, _ := rlp.EncodeToBytes(blockNum)
+	return state.blockStateTrie.TryUpdate(GetJailedKey(addr), enc)
+}
+
+func (state *AccountStateDB) SetDelegations(addr common.Address, delegations []model.Delegation) error {
+	old, _ := state.blockStateTrie.TryGet(GetDelegationKey(addr))
+	var newEnc []byte
+	if len(delegations) > 0 {
+		newEnc, _ = rlp.EncodeToBytes(delegations)
+	}
+	err := state.setDelegations(addr, newEnc)
+	if err != nil {
+		return err
+	}
+	state.stateChangeList.append(delegationChange{Account: &addr, Prev: old, Current: newEnc, ChangeType: DelegationChange})
+	return nil
+}
+
+//setDelegations do not change the changelist, usually called by the revert operation. Empty enc removes the delegations
+func (state *AccountStateDB) setDelegations(addr common.Address, enc []byte) error {
+	empty := state.IsEmptyAccount(addr)
+	if empty {
+		return g_error.AccountNotExist
+	}
+
+	mpt_log.Debug("setDelegations", "addr", addr.Hex(), "pre state", state.preStateRoot.Hex())
+	if len(enc) == 0 {
+		return state.blockStateTrie.TryDelete(GetDelegationKey(addr))
+	}
+	return state.blockStateTrie.TryUpdate(GetDelegationKey(addr), enc)
+}
+
+func (state *AccountStateDB) SetSlashedVotes(addr common.Address, votes []model.SlashedVote) error {
+	old, _ := state.blockStateTrie.TryGet(GetEvidenceKey(addr))
+	var newEnc []byte
+	if len(votes) > 0 {
+		newEnc, _ = rlp.EncodeToBytes(votes)
+	}
+	err := state.setSlashedVotes(addr, newEnc)
+	if err != nil {
+		return err
+	}
+	state.stateChangeList.append(evidenceChange{Account: &addr, Prev: old, Current: newEnc, ChangeType: EvidenceChange})
+	return nil
+}
+
+//setSlashedVotes do not change the changelist, usually called by the revert operation. Empty enc removes the record
+func (state *AccountStateDB) setSlashedVotes(addr common.Address, enc []byte) error {
+	empty := state.IsEmptyAccount(addr)
+	if empty {
+		return g_error.AccountNotExist
+	}
+
+	mpt_log.Debug("setSlashedVotes", "addr", addr.Hex(), "pre state", state.preStateRoot.Hex())
+	if len(enc) == 0 {
+		return state.blockStateTrie.TryDelete(GetEvidenceKey(addr))
+	}
+	return state.blockStateTrie.TryUpdate(GetEvidenceKey(addr), enc)
+}
+
 func (state *AccountStateDB) NewAccountState(addr common.Address) error {
 	_, err := state.newAccountState(addr)
 	if err != nil {
@@ -1044,6 +1246,22 @@ func (state *AccountStateDB) deleteAccountState(addr common.Address) (err error)
 	if err != nil {
 		return err
 	}
+	err = state.blockStateTrie.TryDelete(GetMultiSigKey(addr))
+	if err != nil {
+		return err
+	}
+	err = state.blockStateTrie.TryDelete(GetJailedKey(addr))
+	if err != nil {
+		return err
+	}
+	err = state.blockStateTrie.TryDelete(GetDelegationKey(addr))
+	if err != nil {
+		return err
+	}
+	err = state.blockStateTrie.TryDelete(GetEvidenceKey(addr))
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -1177,7 +1395,7 @@ func (state *AccountStateDB) Commit() (common.Hash, error) {
 	//        return common.Hash{}, errors.New("finalised state root not match commit state root")
 	//    }
 	//have committed in the finalise
-	err = state.storage.TrieDB().Commit(fStateRoot, false)
+	err = state.storage.CommitRoot(fStateRoot)
 	return fStateRoot, err
 	//}
 }
@@ -1241,19 +1459,34 @@ func (state *AccountStateDB) Finalise() (result common.Hash, err error) {
 //todo these processes are removed afterwards。
 // todo Write a unit test for each transaction to cover all situations
 func (state *AccountStateDB) ProcessTx(tx model.AbstractTransaction, height uint64) (err error) {
+	_, err = state.ProcessTxWithReceipt(tx, height)
+	return
+}
+
+// ProcessTxWithReceipt process the tx and return its execution result. After the receipt fork a failed contract call
+// doesn't make the tx invalid, the fee is still charged and the changes of the call are reverted
+func (state *AccountStateDB) ProcessTxWithReceipt(tx model.AbstractTransaction, height uint64) (receipt *model.Receipt, err error) {
+	// the tx type added by a fork can't be processed before the activation block
+	if !chain_config.GetChainConfig().IsTxTypeActive(tx.GetType(), height) {
+		return nil, g_error.UnknownTxTypeErr
+	}
+
 	// All transactions must be done with processBasicTx, and transactionBasicTx only deducts transaction fees. Amount is selectively handled in each type of transaction
 	err = state.processBasicTx(tx)
 	if err != nil {
 		log.Debug("processBasicTx failed", "err", err)
 		return
 	}
+	receipt = &model.Receipt{TxHash: tx.CalTxId(), Status: model.ReceiptStatusSuccessful, Fee: tx.Fee()}
 	switch tx.GetType() {
 	case common.AddressTypeNormal:
 		err = state.processNormalTx(tx)
 	case common.AddressTypeCross:
-		err = state.processCrossTx(tx)
-	case common.AddressTypeERC20:
-		err = state.processERC20Tx(tx, height)
+		err = state.processCrossTx(tx, height)
+	case common.AddressTypeMultiSig:
+		err = state.processMultiSigTx(tx)
+	case common.AddressTypeERC20, common.AddressTypeEarlyReward:
+		err = state.processContractTx(tx, height, receipt)
 		// Verifier relate transaction processor
 	case common.AddressTypeStake:
 		err = state.processStakeTx(tx)
@@ -1262,15 +1495,49 @@ func (state *AccountStateDB) ProcessTx(tx model.AbstractTransaction, height uint
 	case common.AddressTypeUnStake:
 		err = state.processUnStakeTx(tx)
 	case common.AddressTypeEvidence:
-		err = state.processEvidenceTx(tx)
-	case common.AddressTypeEarlyReward:
-		err = state.processEarlyTokenTx(tx, height)
+		err = state.processEvidenceTx(tx, height)
+	case common.AddressTypeDelegate:
+		err = state.processDelegateTx(tx)
+	case common.AddressTypeUnDelegate:
+		err = state.processUnDelegateTx(tx, height)
 	default:
 		err = g_error.UnknownTxTypeErr
 	}
+	if err != nil {
+		return nil, err
+	}
 	return
 }
 
+// run the contract call and record the result in the receipt, the failed call is an error before the receipt fork
+func (state *AccountStateDB) processContractTx(tx model.AbstractTransaction, height uint64, receipt *model.Receipt) error {
+	snap := state.Snapshot()
+	var err error
+	if tx.GetType() == common.AddressTypeERC20 {
+		err = state.processERC20Tx(tx, height)
+	} else {
+		err = state.processEarlyTokenTx(tx, height)
+	}
+	if err != nil {
+		if !chain_config.GetChainConfig().IsForkActive(chain_config.ForkReceipt, height) {
+			return err
+		}
+		log.Debug("contract call failed", "txId", tx.CalTxId().Hex(), "err", err)
+		state.RevertToSnapshot(snap)
+		receipt.Status = model.ReceiptStatusFailed
+		receipt.Err = err.Error()
+		return nil
+	}
+
+	if eData := contract.ParseExtraDataForContract(tx.ExtraData()); eData != nil && eData.Action == "create" {
+		receipt.ContractAddress = *tx.To()
+	}
+	if event := contract.ParseTokenEvent(tx); event != nil {
+		receipt.TokenEvents = append(receipt.TokenEvents, event)
+	}
+	return nil
+}
+
 func (state *AccountStateDB) processBasicTx(tx model.AbstractTransaction) (err error) {
 	sender, err := tx.Sender(nil)
 	receiver := *(tx.To())
@@ -1323,9 +1590,11 @@ func (state *AccountStateDB) processNormalTx(tx model.AbstractTransaction) (err
 	return
 }
 
-func (state *AccountStateDB) processCrossTx(tx model.AbstractTransaction) (err error) {
-	// TODO:
-	return errors.New("not support now")
+func (state *AccountStateDB) processCrossTx(tx model.AbstractTransaction, blockHeight uint64) (err error) {
+	if IsCrossChainTx(tx) {
+		return state.processCrossChainTx(tx)
+	}
+	return state.processHTLCTx(tx, blockHeight)
 }
 
 func (state *AccountStateDB) processERC20Tx(tx model.AbstractTransaction, blockHeight uint64) (err error) {
@@ -17,7 +17,13 @@
 package state_processor
 
 import (
+	"fmt"
 	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/address-util"
+	"github.com/dipperin/dipperin-core/common/util"
+	"github.com/dipperin/dipperin-core/core/chain-config"
+	"github.com/dipperin/dipperin-core/core/contract"
+	"github.com/dipperin/dipperin-core/third-party/crypto/cs-crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"testing"
 	"github.com/stretchr/testify/assert"
@@ -235,8 +241,38 @@ func TestAccountStateProcessor_Process_Evidence(t *testing.T) {
 	bobStake, _ := processor.GetStake(bobAddr)
 
 	assert.EqualValues(t, big.NewInt(0), aliceStake)
-	assert.EqualValues(t, big.NewInt(5750), aliceBalance)
-	assert.EqualValues(t, big.NewInt(0), bobStake)
+	assert.EqualValues(t, big.NewInt(4850), aliceBalance)
+	assert.EqualValues(t, big.NewInt(700), bobStake)
+
+	conf := chain_config.GetChainConfig()
+	bobJailed, _ := processor.GetJailedUntil(bobAddr)
+	assert.Equal(t, 1+conf.SlashJailSlot*conf.SlotSize, bobJailed)
+	assert.True(t, processor.IsJailed(bobAddr, 1))
+
+	slashed, err := processor.GetSlashedVotes(bobAddr)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.SlashedVote{{Height: 1, Round: 2}}, slashed)
+
+	// the same double sign can't be punished twice
+	tx = getTestEvidenceTransaction(2, key1, bobAddr, voteA, voteB)
+	err = processor.ProcessTx(tx, 2)
+	assert.Equal(t, EvidenceProcessedErr, err)
+
+	// the jail doesn't block the evidence of another double sign
+	voteA = model.CreateSignedVote(1, 3, common.HexToHash("0x123456"), model.VoteMessage)
+	voteB = model.CreateSignedVote(1, 3, common.HexToHash("0x654321"), model.VoteMessage)
+	tx = getTestEvidenceTransaction(3, key1, bobAddr, voteA, voteB)
+	err = processor.ProcessTx(tx, 2)
+	assert.NoError(t, err)
+	bobStake, _ = processor.GetStake(bobAddr)
+	assert.EqualValues(t, big.NewInt(490), bobStake)
+
+	// the evidence out of the window is rejected
+	voteA = model.CreateSignedVote(2, 0, common.HexToHash("0x123456"), model.VoteMessage)
+	voteB = model.CreateSignedVote(2, 0, common.HexToHash("0x654321"), model.VoteMessage)
+	tx = getTestEvidenceTransaction(4, key1, bobAddr, voteA, voteB)
+	err = processor.ProcessTx(tx, 3+conf.SlashEvidenceSlot*conf.SlotSize)
+	assert.Equal(t, EvidenceExpiredErr, err)
 }
 
 func TestAccountStateProcessor_Process_Cancel(t *testing.T) {
@@ -319,21 +355,25 @@ func TestAccountStateDB_ProcessTx(t *testing.T) {
 	err = processor.ProcessTx(tx, 1)
 	assert.Error(t, err)
 
+	// failed contract calls are recorded in the receipts
 	tx = fakeTransaction{
 		txType: common.AddressTypeERC20,
 		nonce:  1,
 		sender: aliceAddr,
 	}
-	err = processor.ProcessTx(tx, 1)
-	assert.Error(t, err)
+	receipt, err := processor.ProcessTxWithReceipt(tx, 1)
+	assert.NoError(t, err)
+	assert.True(t, receipt.Failed())
+	assert.NotEmpty(t, receipt.Err)
 
 	tx = fakeTransaction{
 		txType: common.AddressTypeEarlyReward,
 		nonce:  2,
 		sender: aliceAddr,
 	}
-	err = processor.ProcessTx(tx, 1)
-	assert.Error(t, err)
+	receipt, err = processor.ProcessTxWithReceipt(tx, 1)
+	assert.NoError(t, err)
+	assert.True(t, receipt.Failed())
 
 	tx = fakeTransaction{
 		txType: 0x0099,
@@ -348,6 +388,65 @@ func TestAccountStateDB_ProcessTx(t *testing.T) {
 	assert.Equal(t, TxError, err)
 }
 
+func createTestContractTx(nonce uint64, contractAddr common.Address, action string, params string) *model.Transaction {
+	key, _ := createKey()
+	extraData := util.StringifyJsonToBytes(contract.ExtraDataForContract{ContractAddress: contractAddr, Action: action, Params: params})
+	tx := model.NewTransaction(nonce, contractAddr, big.NewInt(0), big.NewInt(10), extraData)
+	tx.SignTx(key, model.NewMercurySigner(big.NewInt(1)))
+	return tx
+}
+
+func TestAccountStateDB_ProcessTxWithReceipt(t *testing.T) {
+	processor, err := NewAccountStateDB(common.Hash{}, NewStateStorageWithCache(ethdb.NewMemDatabase()))
+	assert.NoError(t, err)
+	key, _ := createKey()
+	sender := cs_crypto.GetNormalAddress(key.PublicKey)
+	assert.NoError(t, processor.NewAccountState(sender))
+	assert.NoError(t, processor.AddBalance(sender, big.NewInt(1000)))
+
+	// create a token
+	contractAddr, _ := address_util.GenERC20Address()
+	params := fmt.Sprintf(`{"owner":"%v","token_name":"EOS","token_decimals":18,"token_symbol":"EOS","token_total_supply":"0x64","balances":{},"allowed":{}}`, sender.Hex())
+	tx := createTestContractTx(0, contractAddr, "create", params)
+	receipt, err := processor.ProcessTxWithReceipt(tx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, &model.Receipt{TxHash: tx.CalTxId(), Status: model.ReceiptStatusSuccessful, Fee: big.NewInt(10), ContractAddress: contractAddr}, receipt)
+
+	// transfer the token
+	tx = createTestContractTx(1, contractAddr, "Transfer", util.StringifyJson([]string{bobAddr.Hex(), "0x10"}))
+	receipt, err = processor.ProcessTxWithReceipt(tx, 1)
+	assert.NoError(t, err)
+	assert.False(t, receipt.Failed())
+	assert.Equal(t, common.Address{}, receipt.ContractAddress)
+	assert.Equal(t, []*model.TokenEvent{{Contract: contractAddr, Action: "Transfer", From: sender, To: bobAddr, Value: big.NewInt(16)}}, receipt.TokenEvents)
+
+	// the token isn't enough, the fee is still charged
+	tx = createTestContractTx(2, contractAddr, "Transfer", util.StringifyJson([]string{bobAddr.Hex(), "0x1000"}))
+	receipt, err = processor.ProcessTxWithReceipt(tx, 1)
+	assert.NoError(t, err)
+	assert.True(t, receipt.Failed())
+	assert.Contains(t, receipt.Err, "remainder not enough")
+	assert.Nil(t, receipt.TokenEvents)
+	balance, _ := processor.GetBalance(sender)
+	assert.Equal(t, big.NewInt(970), balance)
+	nonce, _ := processor.GetNonce(sender)
+	assert.Equal(t, uint64(3), nonce)
+
+	// the nonce doesn't match, the tx is invalid
+	receipt, err = processor.ProcessTxWithReceipt(tx, 1)
+	assert.Equal(t, g_error.ErrTxNonceNotMatch, err)
+	assert.Nil(t, receipt)
+
+	// the failed contract call makes the tx invalid before the receipt fork
+	conf := chain_config.GetChainConfig()
+	conf.Forks[chain_config.ForkReceipt] = 10
+	defer func() { conf.Forks[chain_config.ForkReceipt] = 0 }()
+	tx = createTestContractTx(3, contractAddr, "Transfer", util.StringifyJson([]string{bobAddr.Hex(), "0x1000"}))
+	receipt, err = processor.ProcessTxWithReceipt(tx, 9)
+	assert.Error(t, err)
+	assert.Nil(t, receipt)
+}
+
 func TestAccountStateDB_processBasicTx_Error(t *testing.T) {
 	db := ethdb.NewMemDatabase()
 	tdb := NewStateStorageWithCache(db)
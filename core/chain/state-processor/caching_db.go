@@ -36,6 +36,9 @@ const (
 
 	// Number of codehash->size associations to keep.
 	codeSizeCacheSize = 100000
+
+	// Memory of the trie nodes kept by the pruning mode, the oldest nodes are flushed to disk beyond it.
+	pruneMemoryLimit = common.StorageSize(256 * 1024 * 1024)
 )
 
 // In addition to the modified state in the package will be used, other areas of the query status may also be used? Temporarily made public
@@ -47,11 +50,27 @@ func NewStateStorageWithCache(db ethdb.Database) StateStorage {
 	}
 }
 
+// NewPrunedStateStorage keeps the tries of the last retainBlocks blocks in memory and garbage collects
+// the older ones instead of committing every trie to disk, 0 retainBlocks is the archive mode
+func NewPrunedStateStorage(db ethdb.Database, retainBlocks int) StateStorage {
+	storage := NewStateStorageWithCache(db).(*cachingDB)
+	storage.retainBlocks = retainBlocks
+	return storage
+}
+
 type cachingDB struct {
 	db            *trie.Database
 	mu            sync.Mutex
 	pastTries     []*trie.SecureTrie
 	codeSizeCache *lru.Cache
+
+	// pruning mode
+	pruneMu      sync.Mutex
+	retainBlocks int
+	// roots committed since the last block was pruned
+	pendingRoots []common.Hash
+	// roots of the retained blocks, oldest first
+	blockRoots [][]common.Hash
 }
 
 // OpenTrie opens the main account trie.
@@ -127,6 +146,73 @@ func (db *cachingDB) DiskDB() ethdb.Database {
 	return db.db.DiskDB()
 }
 
+// CommitRoot writes the trie to disk in the archive mode, the pruning mode only references it in memory
+func (db *cachingDB) CommitRoot(root common.Hash) error {
+	if db.retainBlocks == 0 {
+		return db.db.Commit(root, false)
+	}
+
+	db.pruneMu.Lock()
+	defer db.pruneMu.Unlock()
+	db.db.Reference(root, common.Hash{})
+	db.pendingRoots = append(db.pendingRoots, root)
+	return nil
+}
+
+// Prune marks the roots committed since the last call as a new block, dereferences the tries of
+// the blocks out of the retained ones and flushes the oldest nodes if the memory limit is reached.
+// The tries of a persisted block are written to disk at once and never garbage collected
+func (db *cachingDB) Prune(persist bool) error {
+	if db.retainBlocks == 0 {
+		return nil
+	}
+
+	db.pruneMu.Lock()
+	defer db.pruneMu.Unlock()
+	if persist {
+		for _, root := range db.pendingRoots {
+			if err := db.db.Commit(root, false); err != nil {
+				return err
+			}
+		}
+		db.pendingRoots = nil
+	}
+	db.blockRoots = append(db.blockRoots, db.pendingRoots)
+	db.pendingRoots = nil
+	for len(db.blockRoots) > db.retainBlocks {
+		for _, root := range db.blockRoots[0] {
+			db.db.Dereference(root)
+		}
+		db.blockRoots = db.blockRoots[1:]
+	}
+
+	if nodes, _ := db.db.Size(); nodes > pruneMemoryLimit {
+		return db.db.Cap(pruneMemoryLimit - ethdb.IdealBatchSize)
+	}
+	return nil
+}
+
+// Flush writes the pending tries and the tries of the newest retained block to disk,
+// so that the node can restart from its current block
+func (db *cachingDB) Flush() error {
+	if db.retainBlocks == 0 {
+		return nil
+	}
+
+	db.pruneMu.Lock()
+	defer db.pruneMu.Unlock()
+	roots := db.pendingRoots
+	if len(db.blockRoots) > 0 {
+		roots = append(roots, db.blockRoots[len(db.blockRoots)-1]...)
+	}
+	for _, root := range roots {
+		if err := db.db.Commit(root, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // cachedTrie inserts its trie into a cachingDB on commit.
 type cachedTrie struct {
 	*trie.SecureTrie
@@ -107,4 +107,76 @@ func TestCachedTrie_Commit(t *testing.T) {
 		assert.NoError(t, err)
 	}
 	assert.Len(t, cache.pastTries, maxPastTries)
-}
\ No newline at end of file
+}
+func commitTestRoot(t *testing.T, storage StateStorage, value byte) common.Hash {
+	trie, err := storage.OpenTrie(common.Hash{})
+	assert.NoError(t, err)
+	assert.NoError(t, trie.TryUpdate([]byte{value}, []byte{value, value}))
+	root, err := trie.Commit(nil)
+	assert.NoError(t, err)
+	assert.NoError(t, storage.CommitRoot(root))
+	return root
+}
+
+func TestCachingDB_CommitRoot_Archive(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	storage := NewStateStorageWithCache(db)
+
+	root := commitTestRoot(t, storage, 1)
+	has, _ := db.Has(root[:])
+	assert.True(t, has)
+	assert.NoError(t, storage.Prune(false))
+	assert.NoError(t, storage.Flush())
+}
+
+func TestCachingDB_Prune(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	storage := NewPrunedStateStorage(db, 2)
+
+	var roots []common.Hash
+	for i := 1; i <= 3; i++ {
+		roots = append(roots, commitTestRoot(t, storage, byte(i)))
+		assert.NoError(t, storage.Prune(false))
+	}
+
+	// the tries are only kept in memory
+	for _, root := range roots {
+		has, _ := db.Has(root[:])
+		assert.False(t, has)
+	}
+	_, err := storage.TrieDB().Node(roots[0])
+	assert.Error(t, err)
+	for _, root := range roots[1:] {
+		_, err = storage.TrieDB().Node(root)
+		assert.NoError(t, err)
+	}
+
+	pending := commitTestRoot(t, storage, 4)
+	assert.NoError(t, storage.Flush())
+	has, _ := db.Has(roots[2][:])
+	assert.True(t, has)
+	has, _ = db.Has(pending[:])
+	assert.True(t, has)
+	has, _ = db.Has(roots[1][:])
+	assert.False(t, has)
+}
+
+func TestCachingDB_Prune_Persist(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	storage := NewPrunedStateStorage(db, 1)
+
+	persisted := commitTestRoot(t, storage, 1)
+	assert.NoError(t, storage.Prune(true))
+	has, _ := db.Has(persisted[:])
+	assert.True(t, has)
+
+	// the persisted trie is still there after it is out of the retained blocks
+	root := commitTestRoot(t, storage, 2)
+	assert.NoError(t, storage.Prune(false))
+	commitTestRoot(t, storage, 3)
+	assert.NoError(t, storage.Prune(false))
+	_, err := storage.TrieDB().Node(persisted)
+	assert.NoError(t, err)
+	_, err = storage.TrieDB().Node(root)
+	assert.Error(t, err)
+}
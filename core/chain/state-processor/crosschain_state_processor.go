@@ -0,0 +1,412 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package state_processor
+
+import (
+	"errors"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/core/chain-config"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/third-party/crypto/cs-crypto"
+	"github.com/dipperin/dipperin-core/third-party/log/pbft_log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"math/big"
+)
+
+var (
+	CrossChainInvalidTxErr       = errors.New("invalid cross chain transaction")
+	CrossChainAmountNotZeroErr   = errors.New("cross chain relay or redeem transaction amount must be zero")
+	CrossChainAmountZeroErr      = errors.New("cross chain lock transaction amount must be greater than zero")
+	CrossChainCheckpointErr      = errors.New("cross chain checkpoint not found")
+	CrossChainCheckpointWorkErr  = errors.New("cross chain checkpoint has no min confirm work")
+	CrossChainHeaderErr          = errors.New("invalid cross chain header")
+	CrossChainHeaderDiffErr      = errors.New("cross chain header difficulty is too low")
+	CrossChainHeaderNotLinkErr   = errors.New("cross chain header not link to the relayed tip")
+	CrossChainNoNewHeaderErr     = errors.New("no new cross chain header to relay")
+	CrossChainHeaderNotExistErr  = errors.New("cross chain header not relayed")
+	CrossChainHeaderNotBestErr   = errors.New("cross chain header not in the best relayed chain")
+	CrossChainConfirmationsErr   = errors.New("cross chain header not enough confirmations")
+	CrossChainProofErr           = errors.New("cross chain transaction proof invalid")
+	CrossChainLockTxErr          = errors.New("invalid cross chain lock transaction")
+	CrossChainAlreadyRedeemedErr = errors.New("cross chain lock transaction already redeemed")
+	CrossChainEscrowNotEnoughErr = errors.New("cross chain escrow balance not enough")
+)
+
+const (
+	crossChainHeaderTag = "header"
+	crossChainTipTag    = "tip"
+	crossChainWorkTag   = "work"
+	crossChainRedeemTag = "redeem"
+)
+
+/*
+Cross chain data is saved in pseudo accounts derived by GetCrossChainDataAddress
+* header: hash lock is the transaction root and time lock is the number of the relayed header
+* work: hash lock is the parent hash and time lock is the total work from the checkpoint to the relayed header
+* tip: hash lock is the hash and time lock is the number of the relayed header with the most total work
+* redeem: exists once the lock transaction is redeemed
+The relayed headers form a tree rooted at the checkpoint in chain config, any relayed header can be extended
+and the branch with the most work is the best one. A lock tx is only redeemed from the best branch
+*/
+func IsCrossChainTx(tx model.AbstractTransaction) bool {
+	return tx.To() != nil && tx.To().IsEqual(common.HexToAddress(common.AddressCrossChain))
+}
+
+func (state *AccountStateDB) getCrossChainData(addr common.Address) (hash common.Hash, number uint64, exist bool) {
+	if state.IsEmptyAccount(addr) {
+		return
+	}
+	hash, err := state.GetHashLock(addr)
+	if err != nil {
+		return
+	}
+	timeLock, err := state.GetTimeLock(addr)
+	if err != nil {
+		return
+	}
+	return hash, timeLock.Uint64(), true
+}
+
+func (state *AccountStateDB) setCrossChainData(addr common.Address, hash common.Hash, number uint64) (err error) {
+	if state.IsEmptyAccount(addr) {
+		if err = state.NewAccountState(addr); err != nil {
+			return
+		}
+	}
+	if err = state.SetHashLock(addr, hash); err != nil {
+		return
+	}
+	return state.SetTimeLock(addr, new(big.Int).SetUint64(number))
+}
+
+// GetCrossChainTip returns the relayed header of the chain with the most work, the checkpoint if nothing relayed
+func (state *AccountStateDB) GetCrossChainTip(chainId *big.Int) (hash common.Hash, number uint64, err error) {
+	hash, number, exist := state.getCrossChainData(cs_crypto.GetCrossChainDataAddress(crossChainTipTag, chainId, common.Hash{}))
+	if exist {
+		return
+	}
+	checkpoint := chain_config.GetChainConfig().GetCrossChainCheckpoint(chainId)
+	if checkpoint == nil {
+		return common.Hash{}, 0, CrossChainCheckpointErr
+	}
+	return checkpoint.Hash, checkpoint.Number, nil
+}
+
+// GetCrossChainHeader returns the transaction root and number of a relayed header
+func (state *AccountStateDB) GetCrossChainHeader(chainId *big.Int, hash common.Hash) (txRoot common.Hash, number uint64, err error) {
+	txRoot, number, exist := state.getCrossChainData(cs_crypto.GetCrossChainDataAddress(crossChainHeaderTag, chainId, hash))
+	if !exist {
+		err = CrossChainHeaderNotExistErr
+	}
+	return
+}
+
+// the work needed to mine a header with the difficulty, 2^256 / (target+1)
+func crossChainHeaderWork(diff common.Difficulty) *big.Int {
+	target := new(big.Int).Add(diff.DiffToTarget().Big(), big.NewInt(1))
+	return target.Div(new(big.Int).Lsh(big.NewInt(1), 256), target)
+}
+
+// return the number and the total work of the relayed header or the checkpoint
+func (state *AccountStateDB) getCrossChainWork(chainId *big.Int, checkpoint *chain_config.CrossChainCheckpoint, hash common.Hash) (number uint64, work *big.Int, err error) {
+	if hash.IsEqual(checkpoint.Hash) {
+		return checkpoint.Number, big.NewInt(0), nil
+	}
+	if _, number, err = state.GetCrossChainHeader(chainId, hash); err != nil {
+		return
+	}
+	if work, err = state.GetTimeLock(cs_crypto.GetCrossChainDataAddress(crossChainWorkTag, chainId, hash)); err != nil {
+		return 0, nil, CrossChainHeaderNotExistErr
+	}
+	return
+}
+
+// check whether the relayed header with the number is an ancestor of the tip or the tip itself
+func (state *AccountStateDB) onCrossChainBranch(chainId *big.Int, hash common.Hash, number uint64, tipHash common.Hash, tipNumber uint64) bool {
+	for ; tipNumber > number; tipNumber-- {
+		parent, _, exist := state.getCrossChainData(cs_crypto.GetCrossChainDataAddress(crossChainWorkTag, chainId, tipHash))
+		if !exist {
+			return false
+		}
+		tipHash = parent
+	}
+	return tipNumber == number && tipHash.IsEqual(hash)
+}
+
+// ValidCrossChainTx check whether the cross chain transaction can be processed
+func (state *AccountStateDB) ValidCrossChainTx(tx model.AbstractTransaction) error {
+	switch model.GetCrossChainTxType(tx.ExtraData()) {
+	case model.CrossChainRelay:
+		_, _, err := state.validCrossChainRelay(tx)
+		return err
+	case model.CrossChainLock:
+		_, err := validCrossChainLock(tx)
+		return err
+	case model.CrossChainRedeem:
+		_, _, err := state.validCrossChainRedeem(tx)
+		return err
+	default:
+		return CrossChainInvalidTxErr
+	}
+}
+
+// return the headers not relayed yet and their total work
+func (state *AccountStateDB) validCrossChainRelay(tx model.AbstractTransaction) (*model.CrossChainRelayData, []*big.Int, error) {
+	if tx.Amount().Cmp(big.NewInt(0)) != 0 {
+		return nil, nil, CrossChainAmountNotZeroErr
+	}
+	var data model.CrossChainRelayData
+	if err := model.DecodeCrossChainData(tx.ExtraData(), model.CrossChainRelay, &data); err != nil {
+		return nil, nil, err
+	}
+	if data.ChainId == nil {
+		return nil, nil, CrossChainInvalidTxErr
+	}
+	checkpoint := chain_config.GetChainConfig().GetCrossChainCheckpoint(data.ChainId)
+	if checkpoint == nil {
+		return nil, nil, CrossChainCheckpointErr
+	}
+	// the declared difficulty of the headers is all that is checked, without a min work a cheap chain could be relayed
+	if checkpoint.MinConfirmWork == nil || checkpoint.MinConfirmWork.Sign() <= 0 {
+		return nil, nil, CrossChainCheckpointWorkErr
+	}
+
+	var newHeaders []*model.Header
+	var works []*big.Int
+	for _, header := range data.Headers {
+		// DiffToTarget panics if the exponent of the difficulty is out of range
+		if header == nil || header.Diff[0] < 3 || header.Diff[0] > common.HashLength {
+			return nil, nil, CrossChainHeaderErr
+		}
+		hash := header.Hash()
+		if !hash.ValidHashForDifficulty(header.Diff) {
+			return nil, nil, CrossChainHeaderErr
+		}
+		if !checkpoint.MinDiff.Equal(common.Difficulty{}) && header.Diff.DiffToTarget().Cmp(checkpoint.MinDiff.DiffToTarget()) > 0 {
+			return nil, nil, CrossChainHeaderDiffErr
+		}
+
+		if _, _, err := state.GetCrossChainHeader(data.ChainId, hash); err == nil {
+			if len(newHeaders) > 0 {
+				return nil, nil, CrossChainHeaderNotLinkErr
+			}
+			continue
+		}
+
+		// the first new header can extend any relayed header, the others extend the previous one
+		var parentNumber uint64
+		var parentWork *big.Int
+		if len(newHeaders) > 0 {
+			parent := newHeaders[len(newHeaders)-1]
+			if !header.PreHash.IsEqual(parent.Hash()) {
+				return nil, nil, CrossChainHeaderNotLinkErr
+			}
+			parentNumber, parentWork = parent.Number, works[len(works)-1]
+		} else {
+			var err error
+			if parentNumber, parentWork, err = state.getCrossChainWork(data.ChainId, checkpoint, header.PreHash); err != nil {
+				return nil, nil, CrossChainHeaderNotLinkErr
+			}
+		}
+		if header.Number != parentNumber+1 {
+			return nil, nil, CrossChainHeaderNotLinkErr
+		}
+		newHeaders = append(newHeaders, header)
+		works = append(works, new(big.Int).Add(parentWork, crossChainHeaderWork(header.Diff)))
+	}
+
+	if len(newHeaders) == 0 {
+		return nil, nil, CrossChainNoNewHeaderErr
+	}
+	data.Headers = newHeaders
+	return &data, works, nil
+}
+
+func validCrossChainLock(tx model.AbstractTransaction) (*model.CrossChainLockData, error) {
+	if tx.Amount().Cmp(big.NewInt(0)) <= 0 {
+		return nil, CrossChainAmountZeroErr
+	}
+	var data model.CrossChainLockData
+	if err := model.DecodeCrossChainData(tx.ExtraData(), model.CrossChainLock, &data); err != nil {
+		return nil, err
+	}
+	if data.TargetChainId == nil || data.Receiver.IsEmpty() {
+		return nil, CrossChainInvalidTxErr
+	}
+	return &data, nil
+}
+
+// return the proved lock transaction and its lock data
+func (state *AccountStateDB) validCrossChainRedeem(tx model.AbstractTransaction) (*model.Transaction, *model.CrossChainLockData, error) {
+	if tx.Amount().Cmp(big.NewInt(0)) != 0 {
+		return nil, nil, CrossChainAmountNotZeroErr
+	}
+	var data model.CrossChainRedeemData
+	if err := model.DecodeCrossChainData(tx.ExtraData(), model.CrossChainRedeem, &data); err != nil {
+		return nil, nil, err
+	}
+	if data.SourceChainId == nil {
+		return nil, nil, CrossChainInvalidTxErr
+	}
+
+	checkpoint := chain_config.GetChainConfig().GetCrossChainCheckpoint(data.SourceChainId)
+	if checkpoint == nil {
+		return nil, nil, CrossChainCheckpointErr
+	}
+	if checkpoint.MinConfirmWork == nil || checkpoint.MinConfirmWork.Sign() <= 0 {
+		return nil, nil, CrossChainCheckpointWorkErr
+	}
+	txRoot, number, err := state.GetCrossChainHeader(data.SourceChainId, data.BlockHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	tipHash, tipNumber, err := state.GetCrossChainTip(data.SourceChainId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !state.onCrossChainBranch(data.SourceChainId, data.BlockHash, number, tipHash, tipNumber) {
+		return nil, nil, CrossChainHeaderNotBestErr
+	}
+	_, work, err := state.getCrossChainWork(data.SourceChainId, checkpoint, data.BlockHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, tipWork, err := state.getCrossChainWork(data.SourceChainId, checkpoint, tipHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tipNumber-number < chain_config.GetChainConfig().CrossChainConfirmations || new(big.Int).Sub(tipWork, work).Cmp(checkpoint.MinConfirmWork) < 0 {
+		return nil, nil, CrossChainConfirmationsErr
+	}
+
+	var lockTx model.Transaction
+	if err := rlp.DecodeBytes(data.LockTx, &lockTx); err != nil {
+		return nil, nil, CrossChainLockTxErr
+	}
+	value, err := model.VerifyDeriveProof(txRoot, lockTx.CalTxId().Bytes(), data.Proof)
+	if err != nil {
+		return nil, nil, CrossChainProofErr
+	}
+	enc, _ := rlp.EncodeToBytes(&lockTx)
+	if string(value) != string(enc) {
+		return nil, nil, CrossChainProofErr
+	}
+
+	if !IsCrossChainTx(&lockTx) || lockTx.ChainId().Cmp(data.SourceChainId) != 0 {
+		return nil, nil, CrossChainLockTxErr
+	}
+	lockData, err := validCrossChainLock(&lockTx)
+	if err != nil {
+		return nil, nil, CrossChainLockTxErr
+	}
+	if lockData.TargetChainId.Cmp(chain_config.GetChainConfig().ChainId) != 0 {
+		return nil, nil, CrossChainLockTxErr
+	}
+
+	if !state.IsEmptyAccount(cs_crypto.GetCrossChainDataAddress(crossChainRedeemTag, data.SourceChainId, lockTx.CalTxId())) {
+		return nil, nil, CrossChainAlreadyRedeemedErr
+	}
+	escrow, err := state.GetBalance(common.HexToAddress(common.AddressCrossChain))
+	if err != nil || escrow.Cmp(lockTx.Amount()) < 0 {
+		return nil, nil, CrossChainEscrowNotEnoughErr
+	}
+	return &lockTx, lockData, nil
+}
+
+/*
+Process cross chain transaction
+Relay saves the new headers and moves the tip if they are the most work, lock moves the amount to the escrow AddressCrossChain, redeem pays the proved lock amount out of the escrow
+*/
+func (state *AccountStateDB) processCrossChainTx(tx model.AbstractTransaction) (err error) {
+	escrowAddr := common.HexToAddress(common.AddressCrossChain)
+	switch model.GetCrossChainTxType(tx.ExtraData()) {
+	case model.CrossChainRelay:
+		data, works, err := state.validCrossChainRelay(tx)
+		if err != nil {
+			return err
+		}
+		for i, header := range data.Headers {
+			hash := header.Hash()
+			if err = state.setCrossChainData(cs_crypto.GetCrossChainDataAddress(crossChainHeaderTag, data.ChainId, hash), header.TransactionRoot, header.Number); err != nil {
+				return err
+			}
+			workAddr := cs_crypto.GetCrossChainDataAddress(crossChainWorkTag, data.ChainId, hash)
+			if err = state.NewAccountState(workAddr); err != nil {
+				return err
+			}
+			if err = state.SetHashLock(workAddr, header.PreHash); err != nil {
+				return err
+			}
+			if err = state.SetTimeLock(workAddr, works[i]); err != nil {
+				return err
+			}
+		}
+
+		tipHash, _, err := state.GetCrossChainTip(data.ChainId)
+		if err != nil {
+			return err
+		}
+		_, tipWork, err := state.getCrossChainWork(data.ChainId, chain_config.GetChainConfig().GetCrossChainCheckpoint(data.ChainId), tipHash)
+		if err != nil {
+			return err
+		}
+		last := data.Headers[len(data.Headers)-1]
+		pbft_log.Info("success process a cross chain relay transaction", "tx hash", tx.CalTxId().Hex(), "chain id", data.ChainId, "last", last.Number, "work", works[len(works)-1], "tip work", tipWork)
+		if works[len(works)-1].Cmp(tipWork) <= 0 {
+			return nil
+		}
+		return state.setCrossChainData(cs_crypto.GetCrossChainDataAddress(crossChainTipTag, data.ChainId, common.Hash{}), last.Hash(), last.Number)
+
+	case model.CrossChainLock:
+		if _, err = validCrossChainLock(tx); err != nil {
+			return
+		}
+		sender, _ := tx.Sender(nil)
+		if state.IsEmptyAccount(escrowAddr) {
+			if err = state.NewAccountState(escrowAddr); err != nil {
+				return
+			}
+		}
+		if err = state.SubBalance(sender, tx.Amount()); err != nil {
+			return
+		}
+		pbft_log.Info("success process a cross chain lock transaction", "tx hash", tx.CalTxId().Hex(), "amount", tx.Amount())
+		return state.AddBalance(escrowAddr, tx.Amount())
+
+	case model.CrossChainRedeem:
+		lockTx, lockData, err := state.validCrossChainRedeem(tx)
+		if err != nil {
+			return err
+		}
+		if err = state.NewAccountState(cs_crypto.GetCrossChainDataAddress(crossChainRedeemTag, lockTx.ChainId(), lockTx.CalTxId())); err != nil {
+			return err
+		}
+		if state.IsEmptyAccount(lockData.Receiver) {
+			if err = state.NewAccountState(lockData.Receiver); err != nil {
+				return err
+			}
+		}
+		if err = state.SubBalance(escrowAddr, lockTx.Amount()); err != nil {
+			return err
+		}
+		pbft_log.Info("success process a cross chain redeem transaction", "tx hash", tx.CalTxId().Hex(), "lock tx", lockTx.CalTxId().Hex(), "receiver", lockData.Receiver.Hex())
+		return state.AddBalance(lockData.Receiver, lockTx.Amount())
+
+	default:
+		return CrossChainInvalidTxErr
+	}
+}
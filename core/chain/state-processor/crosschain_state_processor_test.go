@@ -0,0 +1,227 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package state_processor
+
+import (
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/core/chain-config"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+var (
+	testSourceChainId    = big.NewInt(2)
+	testCheckpointHash   = common.HexToHash("0x1234")
+	testCrossChainDiff   = common.HexToDiff("0x20ffffff")
+	testCrossChainEscrow = common.HexToAddress(common.AddressCrossChain)
+)
+
+func setTestCrossChainCheckpoint() func() {
+	conf := chain_config.GetChainConfig()
+	checkpoints := conf.CrossChainCheckpoints
+	conf.CrossChainCheckpoints = []chain_config.CrossChainCheckpoint{{ChainId: testSourceChainId, Number: 0, Hash: testCheckpointHash, MinDiff: testCrossChainDiff, MinConfirmWork: big.NewInt(6)}}
+	return func() { conf.CrossChainCheckpoints = checkpoints }
+}
+
+// mine the header with the test difficulty
+func mineTestHeader(header *model.Header) *model.Header {
+	for i := uint64(0); !header.Hash().ValidHashForDifficulty(header.Diff); i++ {
+		header.Nonce = common.EncodeNonce(i)
+	}
+	return header
+}
+
+// build the header chain of the source chain on top of the checkpoint, the first block contains the txs
+func createTestSourceHeaders(n int, txs model.Transactions) []*model.Header {
+	return createTestSourceBranch(n, txs, aliceAddr)
+}
+
+// the headers of different coinbase are the competing branches
+func createTestSourceBranch(n int, txs model.Transactions, coinbase common.Address) []*model.Header {
+	var headers []*model.Header
+	preHash := testCheckpointHash
+	for i := 1; i <= n; i++ {
+		header := model.NewHeader(1, uint64(i), preHash, common.Hash{}, testCrossChainDiff, big.NewInt(int64(i)), coinbase, common.BlockNonce{})
+		if i == 1 {
+			header.TransactionRoot = model.DeriveSha(txs)
+		}
+		headers = append(headers, mineTestHeader(header))
+		preHash = header.Hash()
+	}
+	return headers
+}
+
+func getTestCrossChainTx(tx *model.Transaction, err error) *model.Transaction {
+	if err != nil {
+		panic(err)
+	}
+	key1, _ := createKey()
+	return signTestTx(tx, key1)
+}
+
+func getTestSourceLockTx() *model.Transaction {
+	key1, _ := createKey()
+	tx, _ := model.NewCrossChainLockTransaction(0, big.NewInt(1), bobAddr, big.NewInt(300), big.NewInt(10))
+	signedTx, _ := tx.SignTx(key1, model.NewMercurySigner(testSourceChainId))
+	return signedTx
+}
+
+func TestAccountStateDB_processCrossChainTx(t *testing.T) {
+	defer setTestCrossChainCheckpoint()()
+
+	db, root := createTestStateDB()
+	processor, err := NewAccountStateDB(root, NewStateStorageWithCache(db))
+	assert.NoError(t, err)
+
+	// lock on this chain funds the escrow
+	err = processor.ProcessTx(getTestCrossChainTx(model.NewCrossChainLockTransaction(1, testSourceChainId, bobAddr, big.NewInt(1000), big.NewInt(10))), 1)
+	assert.NoError(t, err)
+	balance, _ := processor.GetBalance(testCrossChainEscrow)
+	assert.Equal(t, big.NewInt(1000), balance)
+
+	// the lock tx is in the first block of the source chain
+	lockTx := getTestSourceLockTx()
+	_, otherTx := createTestTx()
+	txs := model.Transactions{lockTx, otherTx}
+	headers := createTestSourceHeaders(7, txs)
+
+	err = processor.ProcessTx(getTestCrossChainTx(model.NewCrossChainRelayTransaction(2, testSourceChainId, headers[:3], big.NewInt(10))), 1)
+	assert.NoError(t, err)
+	tipHash, tipNumber, err := processor.GetCrossChainTip(testSourceChainId)
+	assert.NoError(t, err)
+	assert.Equal(t, headers[2].Hash(), tipHash)
+	assert.Equal(t, uint64(3), tipNumber)
+
+	lockTxBytes, _ := rlp.EncodeToBytes(lockTx)
+	proof, err := model.DeriveProof(txs, lockTx.CalTxId().Bytes())
+	assert.NoError(t, err)
+	redeemTx := func(nonce uint64, proof [][]byte) *model.Transaction {
+		return getTestCrossChainTx(model.NewCrossChainRedeemTransaction(nonce, testSourceChainId, headers[0].Hash(), lockTxBytes, proof, big.NewInt(10)))
+	}
+
+	snapshot := processor.Snapshot()
+	err = processor.ProcessTx(redeemTx(3, proof), 1)
+	assert.Equal(t, CrossChainConfirmationsErr, err)
+	processor.RevertToSnapshot(snapshot)
+
+	// relayed headers are skipped
+	err = processor.ProcessTx(getTestCrossChainTx(model.NewCrossChainRelayTransaction(3, testSourceChainId, headers[1:], big.NewInt(10))), 1)
+	assert.NoError(t, err)
+	_, tipNumber, _ = processor.GetCrossChainTip(testSourceChainId)
+	assert.Equal(t, uint64(7), tipNumber)
+
+	snapshot = processor.Snapshot()
+	err = processor.ProcessTx(getTestCrossChainTx(model.NewCrossChainRelayTransaction(4, testSourceChainId, headers, big.NewInt(10))), 1)
+	assert.Equal(t, CrossChainNoNewHeaderErr, err)
+	processor.RevertToSnapshot(snapshot)
+
+	snapshot = processor.Snapshot()
+	err = processor.ProcessTx(redeemTx(4, proof[1:]), 1)
+	assert.Equal(t, CrossChainProofErr, err)
+	processor.RevertToSnapshot(snapshot)
+
+	err = processor.ProcessTx(redeemTx(4, proof), 1)
+	assert.NoError(t, err)
+	balance, _ = processor.GetBalance(bobAddr)
+	assert.Equal(t, big.NewInt(500), balance)
+	balance, _ = processor.GetBalance(testCrossChainEscrow)
+	assert.Equal(t, big.NewInt(700), balance)
+
+	err = processor.ProcessTx(redeemTx(5, proof), 1)
+	assert.Equal(t, CrossChainAlreadyRedeemedErr, err)
+}
+
+func TestAccountStateDB_processCrossChainTx_Branch(t *testing.T) {
+	defer setTestCrossChainCheckpoint()()
+
+	db, root := createTestStateDB()
+	processor, err := NewAccountStateDB(root, NewStateStorageWithCache(db))
+	assert.NoError(t, err)
+	err = processor.ProcessTx(getTestCrossChainTx(model.NewCrossChainLockTransaction(1, testSourceChainId, bobAddr, big.NewInt(1000), big.NewInt(10))), 1)
+	assert.NoError(t, err)
+
+	// the fake lock tx is in a branch that loses to the honest one
+	lockTx := getTestSourceLockTx()
+	txs := model.Transactions{lockTx}
+	fakeHeaders := createTestSourceBranch(7, txs, bobAddr)
+	honestHeaders := createTestSourceBranch(8, model.Transactions{}, aliceAddr)
+
+	err = processor.ProcessTx(getTestCrossChainTx(model.NewCrossChainRelayTransaction(2, testSourceChainId, fakeHeaders, big.NewInt(10))), 1)
+	assert.NoError(t, err)
+	tipHash, _, _ := processor.GetCrossChainTip(testSourceChainId)
+	assert.Equal(t, fakeHeaders[6].Hash(), tipHash)
+
+	// the honest branch is relayed in two txs, the tip moves once it has more work
+	err = processor.ProcessTx(getTestCrossChainTx(model.NewCrossChainRelayTransaction(3, testSourceChainId, honestHeaders[:4], big.NewInt(10))), 1)
+	assert.NoError(t, err)
+	tipHash, _, _ = processor.GetCrossChainTip(testSourceChainId)
+	assert.Equal(t, fakeHeaders[6].Hash(), tipHash)
+	err = processor.ProcessTx(getTestCrossChainTx(model.NewCrossChainRelayTransaction(4, testSourceChainId, honestHeaders[4:], big.NewInt(10))), 1)
+	assert.NoError(t, err)
+	tipHash, tipNumber, _ := processor.GetCrossChainTip(testSourceChainId)
+	assert.Equal(t, honestHeaders[7].Hash(), tipHash)
+	assert.Equal(t, uint64(8), tipNumber)
+
+	lockTxBytes, _ := rlp.EncodeToBytes(lockTx)
+	proof, err := model.DeriveProof(txs, lockTx.CalTxId().Bytes())
+	assert.NoError(t, err)
+	err = processor.ProcessTx(getTestCrossChainTx(model.NewCrossChainRedeemTransaction(5, testSourceChainId, fakeHeaders[0].Hash(), lockTxBytes, proof, big.NewInt(10))), 1)
+	assert.Equal(t, CrossChainHeaderNotBestErr, err)
+	balance, _ := processor.GetBalance(testCrossChainEscrow)
+	assert.Equal(t, big.NewInt(1000), balance)
+}
+
+func TestAccountStateDB_ValidCrossChainTx(t *testing.T) {
+	defer setTestCrossChainCheckpoint()()
+
+	db, root := createTestStateDB()
+	processor, err := NewAccountStateDB(root, NewStateStorageWithCache(db))
+	assert.NoError(t, err)
+
+	tx := model.NewTransaction(1, testCrossChainEscrow, big.NewInt(10), big.NewInt(10), []byte{1, 2})
+	assert.Equal(t, CrossChainInvalidTxErr, processor.ValidCrossChainTx(tx))
+
+	tx = getTestCrossChainTx(model.NewCrossChainLockTransaction(1, testSourceChainId, bobAddr, big.NewInt(0), big.NewInt(10)))
+	assert.Equal(t, CrossChainAmountZeroErr, processor.ValidCrossChainTx(tx))
+
+	tx = getTestCrossChainTx(model.NewCrossChainLockTransaction(1, testSourceChainId, common.Address{}, big.NewInt(10), big.NewInt(10)))
+	assert.Equal(t, CrossChainInvalidTxErr, processor.ValidCrossChainTx(tx))
+
+	headers := createTestSourceHeaders(2, model.Transactions{})
+	tx = getTestCrossChainTx(model.NewCrossChainRelayTransaction(1, big.NewInt(3), headers, big.NewInt(10)))
+	assert.Equal(t, CrossChainCheckpointErr, processor.ValidCrossChainTx(tx))
+
+	tx = getTestCrossChainTx(model.NewCrossChainRelayTransaction(1, testSourceChainId, headers[1:], big.NewInt(10)))
+	assert.Equal(t, CrossChainHeaderNotLinkErr, processor.ValidCrossChainTx(tx))
+
+	tx = getTestCrossChainTx(model.NewCrossChainRelayTransaction(1, testSourceChainId, headers, big.NewInt(10)))
+	chain_config.GetChainConfig().CrossChainCheckpoints[0].MinConfirmWork = nil
+	assert.Equal(t, CrossChainCheckpointWorkErr, processor.ValidCrossChainTx(tx))
+	chain_config.GetChainConfig().CrossChainCheckpoints[0].MinConfirmWork = big.NewInt(6)
+	assert.NoError(t, processor.ValidCrossChainTx(tx))
+
+	easyHeader := mineTestHeader(model.NewHeader(1, 1, testCheckpointHash, common.Hash{}, common.HexToDiff("0x20ffffff"), big.NewInt(1), bobAddr, common.BlockNonce{}))
+	chain_config.GetChainConfig().CrossChainCheckpoints[0].MinDiff = common.HexToDiff("0x1fffffff")
+	tx = getTestCrossChainTx(model.NewCrossChainRelayTransaction(1, testSourceChainId, []*model.Header{easyHeader}, big.NewInt(10)))
+	assert.Equal(t, CrossChainHeaderDiffErr, processor.ValidCrossChainTx(tx))
+
+	tx = getTestCrossChainTx(model.NewCrossChainRedeemTransaction(1, testSourceChainId, headers[0].Hash(), []byte{1}, nil, big.NewInt(10)))
+	assert.Equal(t, CrossChainHeaderNotExistErr, processor.ValidCrossChainTx(tx))
+}
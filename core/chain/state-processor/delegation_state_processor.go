@@ -0,0 +1,265 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package state_processor
+
+import (
+	"errors"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/core/chain-config"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/third-party/crypto/cs-crypto"
+	"github.com/dipperin/dipperin-core/third-party/log/pbft_log"
+	"math/big"
+)
+
+var (
+	DelegateAmountErr          = errors.New("delegate amount must be positive")
+	DelegateNotVerifierErr     = errors.New("delegate target isn't a registered verifier")
+	DelegationNotExistErr      = errors.New("delegation does not exist")
+	DelegationUnbondingErr     = errors.New("delegation is unbonding")
+	UnbondingNotFinishedErr    = errors.New("delegation unbonding period not finished")
+	DelegateToSelfErr          = errors.New("verifier can't delegate to itself")
+	UnDelegateAmountNotZeroErr = errors.New("undelegate transaction amount must be zero")
+)
+
+// GetDelegatedStake returns the bonded DIP delegated to the verifier
+func (state *AccountStateDB) GetDelegatedStake(verifier common.Address) (*big.Int, error) {
+	delegations, err := state.GetDelegations(verifier)
+	if err != nil {
+		return nil, err
+	}
+	total := big.NewInt(0)
+	for i := range delegations {
+		if delegations[i].IsBonded() {
+			total.Add(total, delegations[i].Amount)
+		}
+	}
+	return total, nil
+}
+
+func findDelegation(delegations []model.Delegation, delegator common.Address) int {
+	for i := range delegations {
+		if delegations[i].Delegator.IsEqual(delegator) {
+			return i
+		}
+	}
+	return -1
+}
+
+// whether the unbonding started at unbondNum is finished at the block, same as the unStake lock period
+func unbondingFinished(unbondNum uint64, blockNum uint64) bool {
+	config := chain_config.GetChainConfig()
+	return blockNum/config.SlotSize-unbondNum/config.SlotSize >= config.StakeLockSlot
+}
+
+// ValidDelegationTx check whether the delegate or undelegate tx can be processed at the block
+func (state *AccountStateDB) ValidDelegationTx(tx model.AbstractTransaction, blockNum uint64) error {
+	sender, err := tx.Sender(nil)
+	if err != nil {
+		return err
+	}
+	verifier := cs_crypto.GetNormalAddressFromEvidence(*tx.To())
+
+	switch tx.GetType() {
+	case common.AddressTypeDelegate:
+		_, _, err = state.validDelegate(sender, verifier, tx.Amount())
+		return err
+	case common.AddressTypeUnDelegate:
+		if tx.Amount().Sign() != 0 {
+			return UnDelegateAmountNotZeroErr
+		}
+		_, _, err = state.validUnDelegate(sender, verifier, blockNum)
+		return err
+	default:
+		return TransactionTypeError
+	}
+}
+
+// the verifier must be registered and not canceled, returns the delegations of the verifier and the index of the delegator
+func (state *AccountStateDB) validDelegate(delegator, verifier common.Address, amount *big.Int) ([]model.Delegation, int, error) {
+	if amount == nil || amount.Sign() <= 0 {
+		return nil, -1, DelegateAmountErr
+	}
+	if delegator.IsEqual(verifier) {
+		return nil, -1, DelegateToSelfErr
+	}
+	if state.IsEmptyAccount(verifier) {
+		return nil, -1, DelegateNotVerifierErr
+	}
+	stake, err := state.GetStake(verifier)
+	if err != nil {
+		return nil, -1, err
+	}
+	lastElect, err := state.GetLastElect(verifier)
+	if err != nil {
+		return nil, -1, err
+	}
+	if stake.Sign() == 0 || lastElect != 0 {
+		return nil, -1, DelegateNotVerifierErr
+	}
+
+	balance, err := state.GetBalance(delegator)
+	if err != nil {
+		return nil, -1, err
+	}
+	if balance.Cmp(amount) < 0 {
+		return nil, -1, NotEnoughBalanceError
+	}
+
+	delegations, err := state.GetDelegations(verifier)
+	if err != nil {
+		return nil, -1, err
+	}
+	index := findDelegation(delegations, delegator)
+	if index >= 0 && !delegations[index].IsBonded() {
+		return nil, -1, DelegationUnbondingErr
+	}
+	return delegations, index, nil
+}
+
+func (state *AccountStateDB) validUnDelegate(delegator, verifier common.Address, blockNum uint64) ([]model.Delegation, int, error) {
+	if state.IsEmptyAccount(verifier) {
+		return nil, -1, DelegationNotExistErr
+	}
+	delegations, err := state.GetDelegations(verifier)
+	if err != nil {
+		return nil, -1, err
+	}
+	index := findDelegation(delegations, delegator)
+	if index < 0 {
+		return nil, -1, DelegationNotExistErr
+	}
+	if !delegations[index].IsBonded() && !unbondingFinished(delegations[index].UnbondNum, blockNum) {
+		return nil, -1, UnbondingNotFinishedErr
+	}
+	return delegations, index, nil
+}
+
+/*
+Process delegate tx
+Bond the amount from the balance of the delegator to the verifier
+*/
+func (state *AccountStateDB) processDelegateTx(tx model.AbstractTransaction) (err error) {
+	//Check
+	sender, _ := tx.Sender(nil)
+	receiver := *(tx.To())
+	if receiver.GetAddressType() != common.AddressTypeDelegate {
+		return TransactionTypeError
+	}
+	verifier := cs_crypto.GetNormalAddressFromEvidence(receiver)
+	delegations, index, err := state.validDelegate(sender, verifier, tx.Amount())
+	if err != nil {
+		return err
+	}
+
+	//Process
+	if err = state.SubBalance(sender, tx.Amount()); err != nil {
+		return err
+	}
+	if index >= 0 {
+		delegations[index].Amount = new(big.Int).Add(delegations[index].Amount, tx.Amount())
+	} else {
+		delegations = append(delegations, model.Delegation{Delegator: sender, Amount: new(big.Int).Set(tx.Amount())})
+	}
+	if err = state.SetDelegations(verifier, delegations); err != nil {
+		return err
+	}
+	pbft_log.Info("success process a delegate transaction", "delegator", sender.Hex(), "verifier", verifier.Hex(), "amount", tx.Amount())
+	return nil
+}
+
+/*
+Process undelegate tx, num is processing block num
+The bonded delegation starts unbonding, the unbonded one returns to the balance of the delegator
+*/
+func (state *AccountStateDB) processUnDelegateTx(tx model.AbstractTransaction, num uint64) (err error) {
+	//Check
+	sender, _ := tx.Sender(nil)
+	receiver := *(tx.To())
+	if receiver.GetAddressType() != common.AddressTypeUnDelegate {
+		return TransactionTypeError
+	}
+	if tx.Amount().Sign() != 0 {
+		return UnDelegateAmountNotZeroErr
+	}
+	verifier := cs_crypto.GetNormalAddressFromEvidence(receiver)
+	delegations, index, err := state.validUnDelegate(sender, verifier, num)
+	if err != nil {
+		return err
+	}
+
+	//Process
+	if delegations[index].IsBonded() {
+		delegations[index].UnbondNum = num
+		pbft_log.Info("start unbonding the delegation", "delegator", sender.Hex(), "verifier", verifier.Hex(), "num", num)
+		return state.SetDelegations(verifier, delegations)
+	}
+
+	if err = state.AddBalance(sender, delegations[index].Amount); err != nil {
+		return err
+	}
+	pbft_log.Info("success undelegate", "delegator", sender.Hex(), "verifier", verifier.Hex(), "amount", delegations[index].Amount)
+	return state.SetDelegations(verifier, append(delegations[:index], delegations[index+1:]...))
+}
+
+/*
+Share the reward of the verifier with its bonded delegators in proportion to the stake,
+the verifier keeps the commission of the delegators share and the remainder of the division
+*/
+func (state *AccountStateDB) DistributeVerifierReward(verifier common.Address, reward *big.Int) error {
+	delegated, err := state.GetDelegatedStake(verifier)
+	if err != nil {
+		return err
+	}
+	if delegated.Sign() == 0 {
+		return state.AddBalance(verifier, reward)
+	}
+	stake, err := state.GetStake(verifier)
+	if err != nil {
+		return err
+	}
+
+	total := new(big.Int).Add(stake, delegated)
+	delegatorsShare := new(big.Int).Div(new(big.Int).Mul(reward, delegated), total)
+	commission := new(big.Int).Div(new(big.Int).Mul(delegatorsShare, new(big.Int).SetUint64(chain_config.GetChainConfig().DelegationCommissionPercent)), big.NewInt(100))
+	delegatorsShare.Sub(delegatorsShare, commission)
+
+	delegations, err := state.GetDelegations(verifier)
+	if err != nil {
+		return err
+	}
+	left := new(big.Int).Set(reward)
+	for i := range delegations {
+		if !delegations[i].IsBonded() {
+			continue
+		}
+		value := new(big.Int).Div(new(big.Int).Mul(delegatorsShare, delegations[i].Amount), delegated)
+		if value.Sign() == 0 {
+			continue
+		}
+		if empty := state.IsEmptyAccount(delegations[i].Delegator); empty {
+			if err = state.NewAccountState(delegations[i].Delegator); err != nil {
+				return err
+			}
+		}
+		if err = state.AddBalance(delegations[i].Delegator, value); err != nil {
+			return err
+		}
+		left.Sub(left, value)
+	}
+	return state.AddBalance(verifier, left)
+}
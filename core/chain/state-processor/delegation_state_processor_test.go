@@ -0,0 +1,190 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package state_processor
+
+import (
+	"crypto/ecdsa"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/g-error"
+	"github.com/dipperin/dipperin-core/core/chain-config"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+func getTestDelegateTransaction(nonce uint64, key *ecdsa.PrivateKey, verifier common.Address, amount *big.Int) *model.Transaction {
+	trans := model.NewDelegateTransaction(nonce, verifier, amount, big.NewInt(40))
+	fs := model.NewMercurySigner(big.NewInt(1))
+	signedTx, _ := trans.SignTx(key, fs)
+	return signedTx
+}
+
+func getTestUnDelegateTransaction(nonce uint64, key *ecdsa.PrivateKey, verifier common.Address) *model.Transaction {
+	trans := model.NewUnDelegateTransaction(nonce, verifier, big.NewInt(40))
+	fs := model.NewMercurySigner(big.NewInt(1))
+	signedTx, _ := trans.SignTx(key, fs)
+	return signedTx
+}
+
+func TestAccountStateProcessor_Process_Delegate(t *testing.T) {
+	processor := createStateProcessor(t)
+	key1, _ := createKey()
+
+	// bob isn't a verifier
+	tx := getTestDelegateTransaction(1, key1, bobAddr, big.NewInt(1000))
+	assert.Equal(t, DelegateNotVerifierErr, processor.ValidDelegationTx(tx, 1))
+
+	assert.NoError(t, processor.AddStake(bobAddr, big.NewInt(1000)))
+	tx = getTestDelegateTransaction(1, key1, bobAddr, big.NewInt(0))
+	assert.Equal(t, DelegateAmountErr, processor.ValidDelegationTx(tx, 1))
+	tx = getTestDelegateTransaction(1, key1, bobAddr, big.NewInt(1e6))
+	assert.Equal(t, NotEnoughBalanceError, processor.ValidDelegationTx(tx, 1))
+	tx = getTestDelegateTransaction(1, key1, aliceAddr, big.NewInt(100))
+	assert.Equal(t, DelegateToSelfErr, processor.ValidDelegationTx(tx, 1))
+
+	tx = getTestDelegateTransaction(1, key1, bobAddr, big.NewInt(1000))
+	assert.NoError(t, processor.ValidDelegationTx(tx, 1))
+	assert.NoError(t, processor.ProcessTx(tx, 1))
+	tx = getTestDelegateTransaction(2, key1, bobAddr, big.NewInt(500))
+	assert.NoError(t, processor.ProcessTx(tx, 1))
+
+	aliceBalance, _ := processor.GetBalance(aliceAddr)
+	assert.Equal(t, big.NewInt(4790-1500-80), aliceBalance)
+	delegated, err := processor.GetDelegatedStake(bobAddr)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1500), delegated)
+	delegations, err := processor.GetDelegations(bobAddr)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Delegation{{Delegator: aliceAddr, Amount: big.NewInt(1500)}}, delegations)
+
+	// the stake of the verifier isn't changed
+	bobStake, _ := processor.GetStake(bobAddr)
+	assert.Equal(t, big.NewInt(1000), bobStake)
+
+	// canceled verifier can't be delegated
+	assert.NoError(t, processor.SetLastElect(bobAddr, 3))
+	tx = getTestDelegateTransaction(3, key1, bobAddr, big.NewInt(100))
+	assert.Equal(t, DelegateNotVerifierErr, processor.ValidDelegationTx(tx, 4))
+}
+
+func TestAccountStateProcessor_Process_UnDelegate(t *testing.T) {
+	processor := createStateProcessor(t)
+	key1, _ := createKey()
+	conf := chain_config.GetChainConfig()
+
+	tx := getTestUnDelegateTransaction(1, key1, bobAddr)
+	assert.Equal(t, DelegationNotExistErr, processor.ValidDelegationTx(tx, 1))
+
+	assert.NoError(t, processor.AddStake(bobAddr, big.NewInt(1000)))
+	assert.NoError(t, processor.ProcessTx(getTestDelegateTransaction(1, key1, bobAddr, big.NewInt(1000)), 1))
+
+	// start unbonding
+	num := conf.SlotSize + 1
+	tx = getTestUnDelegateTransaction(2, key1, bobAddr)
+	assert.NoError(t, processor.ValidDelegationTx(tx, num))
+	assert.NoError(t, processor.ProcessTx(tx, num))
+	delegated, _ := processor.GetDelegatedStake(bobAddr)
+	assert.Equal(t, big.NewInt(0), delegated)
+	delegations, _ := processor.GetDelegations(bobAddr)
+	assert.Equal(t, num, delegations[0].UnbondNum)
+
+	// can't delegate while unbonding
+	assert.Equal(t, DelegationUnbondingErr, processor.ValidDelegationTx(getTestDelegateTransaction(3, key1, bobAddr, big.NewInt(10)), num))
+
+	// withdraw after the stake lock slots
+	tx = getTestUnDelegateTransaction(3, key1, bobAddr)
+	unlockNum := (conf.StakeLockSlot + 1) * conf.SlotSize
+	assert.Equal(t, UnbondingNotFinishedErr, processor.ValidDelegationTx(tx, unlockNum-1))
+	aliceBalance, _ := processor.GetBalance(aliceAddr)
+	assert.NoError(t, processor.ProcessTx(tx, unlockNum))
+
+	newBalance, _ := processor.GetBalance(aliceAddr)
+	assert.Equal(t, new(big.Int).Add(aliceBalance, big.NewInt(1000-40)), newBalance)
+	delegations, err := processor.GetDelegations(bobAddr)
+	assert.NoError(t, err)
+	assert.Len(t, delegations, 0)
+}
+
+func TestAccountStateProcessor_Process_DelegationFork(t *testing.T) {
+	processor := createStateProcessor(t)
+	key1, _ := createKey()
+	assert.NoError(t, processor.AddStake(bobAddr, big.NewInt(1000)))
+
+	conf := chain_config.GetChainConfig()
+	conf.Forks[chain_config.ForkDelegation] = 10
+	defer func() { conf.Forks[chain_config.ForkDelegation] = 0 }()
+
+	tx := getTestDelegateTransaction(1, key1, bobAddr, big.NewInt(1000))
+	assert.Equal(t, g_error.UnknownTxTypeErr, processor.ProcessTx(tx, 9))
+	assert.NoError(t, processor.ProcessTx(tx, 10))
+}
+
+func TestAccountStateDB_DistributeVerifierReward(t *testing.T) {
+	db, root := createTestStateDB()
+	processor, _ := NewAccountStateDB(root, NewStateStorageWithCache(db))
+	conf := chain_config.GetChainConfig()
+
+	// no delegation
+	bobBalance, _ := processor.GetBalance(bobAddr)
+	assert.NoError(t, processor.DistributeVerifierReward(bobAddr, big.NewInt(1000)))
+	balance, _ := processor.GetBalance(bobAddr)
+	assert.Equal(t, new(big.Int).Add(bobBalance, big.NewInt(1000)), balance)
+
+	assert.NoError(t, processor.NewAccountState(charlieAddr))
+	assert.NoError(t, processor.AddStake(bobAddr, big.NewInt(1000)))
+	assert.NoError(t, processor.SetDelegations(bobAddr, []model.Delegation{
+		{Delegator: aliceAddr, Amount: big.NewInt(2000)},
+		{Delegator: charlieAddr, Amount: big.NewInt(1000)},
+		// the unbonding delegation isn't rewarded
+		{Delegator: common.HexToAddress("0x1234"), Amount: big.NewInt(1000), UnbondNum: 1},
+	}))
+
+	aliceBalance, _ := processor.GetBalance(aliceAddr)
+	bobBalance, _ = processor.GetBalance(bobAddr)
+	assert.NoError(t, processor.DistributeVerifierReward(bobAddr, big.NewInt(10000)))
+
+	// 3/4 of the reward belongs to the delegators
+	delegatorsShare := int64(7500 * (100 - conf.DelegationCommissionPercent) / 100)
+	balance, _ = processor.GetBalance(aliceAddr)
+	assert.Equal(t, new(big.Int).Add(aliceBalance, big.NewInt(delegatorsShare*2/3)), balance)
+	balance, _ = processor.GetBalance(charlieAddr)
+	assert.Equal(t, big.NewInt(delegatorsShare/3), balance)
+	balance, _ = processor.GetBalance(bobAddr)
+	assert.Equal(t, new(big.Int).Add(bobBalance, big.NewInt(10000-delegatorsShare)), balance)
+	assert.True(t, processor.IsEmptyAccount(common.HexToAddress("0x1234")))
+}
+
+func TestAccountStateDB_SetDelegations(t *testing.T) {
+	db, root := createTestStateDB()
+	processor, _ := NewAccountStateDB(root, NewStateStorageWithCache(db))
+
+	delegations, err := processor.GetDelegations(common.HexToAddress("0x1234"))
+	assert.Error(t, err)
+	assert.Error(t, processor.SetDelegations(common.HexToAddress("0x1234"), nil))
+
+	snapshot := processor.Snapshot()
+	assert.NoError(t, processor.SetDelegations(aliceAddr, []model.Delegation{{Delegator: bobAddr, Amount: big.NewInt(10)}}))
+	delegations, err = processor.GetDelegations(aliceAddr)
+	assert.NoError(t, err)
+	assert.Len(t, delegations, 1)
+
+	processor.RevertToSnapshot(snapshot)
+	delegations, err = processor.GetDelegations(aliceAddr)
+	assert.NoError(t, err)
+	assert.Len(t, delegations, 0)
+}
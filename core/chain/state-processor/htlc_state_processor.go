@@ -0,0 +1,215 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package state_processor
+
+import (
+	"errors"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/third-party/crypto/cs-crypto"
+	"github.com/dipperin/dipperin-core/third-party/log/pbft_log"
+	"math/big"
+)
+
+var (
+	HTLCInvalidTxErr      = errors.New("invalid hash time lock transaction")
+	HTLCLockAddressErr    = errors.New("hash time lock address not match sender and receiver")
+	HTLCLockExistErr      = errors.New("hash time lock already exist")
+	HTLCLockNotExistErr   = errors.New("hash time lock does not exist")
+	HTLCTimeLockErr       = errors.New("time lock must be greater than current block height")
+	HTLCHashKeyErr        = errors.New("hash key not match hash lock")
+	HTLCLockExpiredErr    = errors.New("hash time lock is expired")
+	HTLCLockNotExpiredErr = errors.New("hash time lock is not expired")
+	HTLCAmountNotZeroErr  = errors.New("claim or refund transaction amount must be zero")
+	HTLCAmountZeroErr     = errors.New("lock transaction amount must be greater than zero")
+)
+
+type HTLCTxType uint8
+
+const (
+	HTLCInvalid HTLCTxType = iota
+	HTLCLock
+	HTLCClaim
+	HTLCRefund
+)
+
+/*
+Hash time lock transactions all send to the lock address GetLockAddress(alice, bob)
+* Lock: alice carries the hash lock and time lock, extra data is bob
+* Claim: bob reveals the hash key before the time lock, extra data is alice
+* Refund: alice takes the money back after the time lock, extra data is bob
+*/
+func GetHTLCTxType(tx model.AbstractTransaction) HTLCTxType {
+	hashLock := tx.HashLock()
+	hasHashLock := hashLock != nil && !hashLock.IsEmpty()
+	hasHashKey := len(tx.HashKey()) != 0
+	if len(tx.ExtraData()) != common.AddressLength {
+		return HTLCInvalid
+	}
+
+	switch {
+	case hasHashLock && !hasHashKey:
+		return HTLCLock
+	case !hasHashLock && hasHashKey:
+		return HTLCClaim
+	case !hasHashLock && !hasHashKey:
+		return HTLCRefund
+	default:
+		return HTLCInvalid
+	}
+}
+
+// get alice and bob of the lock, the sender must be alice when lock and refund, bob when claim
+func getHTLCParticipants(tx model.AbstractTransaction, htlcType HTLCTxType) (alice, bob common.Address, err error) {
+	sender, err := tx.Sender(nil)
+	if err != nil {
+		return
+	}
+	counterparty := common.BytesToAddress(tx.ExtraData())
+	if htlcType == HTLCClaim {
+		alice, bob = counterparty, sender
+	} else {
+		alice, bob = sender, counterparty
+	}
+
+	if !cs_crypto.GetLockAddress(alice, bob).IsEqual(*tx.To()) {
+		err = HTLCLockAddressErr
+	}
+	return
+}
+
+// an active lock is a lock account with a hash lock set
+func (state *AccountStateDB) hasActiveLock(lockAddr common.Address) bool {
+	if state.IsEmptyAccount(lockAddr) {
+		return false
+	}
+	hashLock, err := state.GetHashLock(lockAddr)
+	if err != nil {
+		return false
+	}
+	return !hashLock.IsEmpty()
+}
+
+// ValidHTLCTx check whether the hash time lock transaction can be processed at the height
+func (state *AccountStateDB) ValidHTLCTx(tx model.AbstractTransaction, height uint64) error {
+	htlcType := GetHTLCTxType(tx)
+	if htlcType == HTLCInvalid {
+		return HTLCInvalidTxErr
+	}
+	if _, _, err := getHTLCParticipants(tx, htlcType); err != nil {
+		return err
+	}
+	lockAddr := *tx.To()
+
+	if htlcType == HTLCLock {
+		if tx.Amount().Cmp(big.NewInt(0)) <= 0 {
+			return HTLCAmountZeroErr
+		}
+		if tx.TimeLock().Cmp(new(big.Int).SetUint64(height)) <= 0 {
+			return HTLCTimeLockErr
+		}
+		if state.hasActiveLock(lockAddr) {
+			return HTLCLockExistErr
+		}
+		return nil
+	}
+
+	if tx.Amount().Cmp(big.NewInt(0)) != 0 {
+		return HTLCAmountNotZeroErr
+	}
+	if !state.hasActiveLock(lockAddr) {
+		return HTLCLockNotExistErr
+	}
+	timeLock, err := state.GetTimeLock(lockAddr)
+	if err != nil {
+		return err
+	}
+	expired := timeLock.Cmp(new(big.Int).SetUint64(height)) <= 0
+
+	if htlcType == HTLCClaim {
+		if expired {
+			return HTLCLockExpiredErr
+		}
+		hashLock, err := state.GetHashLock(lockAddr)
+		if err != nil {
+			return err
+		}
+		if !cs_crypto.Keccak256Hash(tx.HashKey()).IsEqual(hashLock) {
+			return HTLCHashKeyErr
+		}
+		return nil
+	}
+
+	if !expired {
+		return HTLCLockNotExpiredErr
+	}
+	return nil
+}
+
+/*
+Process hash time lock transaction
+Lock moves the amount to the lock address, claim and refund move the locked money out and clear the lock
+*/
+func (state *AccountStateDB) processHTLCTx(tx model.AbstractTransaction, height uint64) (err error) {
+	if err = state.ValidHTLCTx(tx, height); err != nil {
+		return
+	}
+
+	sender, _ := tx.Sender(nil)
+	lockAddr := *tx.To()
+	if GetHTLCTxType(tx) == HTLCLock {
+		if empty := state.IsEmptyAccount(lockAddr); empty {
+			if err = state.NewAccountState(lockAddr); err != nil {
+				return
+			}
+		}
+		if err = state.SubBalance(sender, tx.Amount()); err != nil {
+			return
+		}
+		if err = state.AddBalance(lockAddr, tx.Amount()); err != nil {
+			return
+		}
+		if err = state.SetHashLock(lockAddr, *tx.HashLock()); err != nil {
+			return
+		}
+		if err = state.SetTimeLock(lockAddr, tx.TimeLock()); err != nil {
+			return
+		}
+		pbft_log.Info("success process a lock transaction", "tx hash", tx.CalTxId().Hex(), "lock address", lockAddr.Hex(), "amount", tx.Amount())
+		return
+	}
+
+	// claim and refund take all the locked money
+	amount, err := state.GetBalance(lockAddr)
+	if err != nil {
+		return
+	}
+	if err = state.SubBalance(lockAddr, amount); err != nil {
+		return
+	}
+	if err = state.AddBalance(sender, amount); err != nil {
+		return
+	}
+	if err = state.SetHashLock(lockAddr, common.Hash{}); err != nil {
+		return
+	}
+	if err = state.SetTimeLock(lockAddr, big.NewInt(0)); err != nil {
+		return
+	}
+	pbft_log.Info("success process an unlock transaction", "tx hash", tx.CalTxId().Hex(), "lock address", lockAddr.Hex(), "receiver", sender.Hex(), "amount", amount)
+	return
+}
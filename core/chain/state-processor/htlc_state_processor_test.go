@@ -0,0 +1,159 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package state_processor
+
+import (
+	"crypto/ecdsa"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/third-party/crypto/cs-crypto"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+var testHashKey = []byte("htlc test hash key")
+
+func signTestTx(tx *model.Transaction, key *ecdsa.PrivateKey) *model.Transaction {
+	fs := model.NewMercurySigner(big.NewInt(1))
+	signedTx, _ := tx.SignTx(key, fs)
+	return signedTx
+}
+
+func getTestLockTransaction(nonce uint64, amount *big.Int, timeLock uint64) *model.Transaction {
+	key1, _ := createKey()
+	lock := cs_crypto.Keccak256Hash(testHashKey)
+	tx := model.CreateRawLockTx(nonce, lock, new(big.Int).SetUint64(timeLock), amount, big.NewInt(40), aliceAddr, bobAddr)
+	return signTestTx(tx, key1)
+}
+
+func getTestClaimTransaction(nonce uint64, hashKey []byte) *model.Transaction {
+	_, key2 := createKey()
+	tx := model.CreateRawClaimTx(nonce, hashKey, big.NewInt(0), big.NewInt(40), aliceAddr, bobAddr)
+	return signTestTx(tx, key2)
+}
+
+func getTestRefundTransaction(nonce uint64) *model.Transaction {
+	key1, _ := createKey()
+	tx := model.CreateRawRefundTx(nonce, big.NewInt(0), big.NewInt(40), aliceAddr, bobAddr)
+	return signTestTx(tx, key1)
+}
+
+func TestGetHTLCTxType(t *testing.T) {
+	assert.Equal(t, HTLCLock, GetHTLCTxType(getTestLockTransaction(1, big.NewInt(100), 10)))
+	assert.Equal(t, HTLCClaim, GetHTLCTxType(getTestClaimTransaction(0, testHashKey)))
+	assert.Equal(t, HTLCRefund, GetHTLCTxType(getTestRefundTransaction(1)))
+
+	tx := fakeTransaction{txType: common.AddressTypeCross, sender: aliceAddr}
+	assert.Equal(t, HTLCInvalid, GetHTLCTxType(tx))
+}
+
+func TestAccountStateDB_processHTLCTx_Claim(t *testing.T) {
+	db, root := createTestStateDB()
+	processor, err := NewAccountStateDB(root, NewStateStorageWithCache(db))
+	assert.NoError(t, err)
+	lockAddr := cs_crypto.GetLockAddress(aliceAddr, bobAddr)
+
+	err = processor.ProcessTx(getTestLockTransaction(1, big.NewInt(1000), 10), 1)
+	assert.NoError(t, err)
+	balance, _ := processor.GetBalance(aliceAddr)
+	assert.Equal(t, big.NewInt(3750), balance)
+	balance, _ = processor.GetBalance(lockAddr)
+	assert.Equal(t, big.NewInt(1000), balance)
+	hashLock, _ := processor.GetHashLock(lockAddr)
+	assert.Equal(t, cs_crypto.Keccak256Hash(testHashKey), hashLock)
+	timeLock, _ := processor.GetTimeLock(lockAddr)
+	assert.Equal(t, big.NewInt(10), timeLock)
+
+	// only one lock between the same participants
+	snapshot := processor.Snapshot()
+	err = processor.ProcessTx(getTestLockTransaction(2, big.NewInt(1000), 10), 2)
+	assert.Equal(t, HTLCLockExistErr, err)
+	processor.RevertToSnapshot(snapshot)
+
+	snapshot = processor.Snapshot()
+	err = processor.ProcessTx(getTestClaimTransaction(0, []byte("wrong key")), 2)
+	assert.Equal(t, HTLCHashKeyErr, err)
+	processor.RevertToSnapshot(snapshot)
+
+	snapshot = processor.Snapshot()
+	err = processor.ProcessTx(getTestRefundTransaction(2), 2)
+	assert.Equal(t, HTLCLockNotExpiredErr, err)
+	processor.RevertToSnapshot(snapshot)
+
+	err = processor.ProcessTx(getTestClaimTransaction(0, testHashKey), 2)
+	assert.NoError(t, err)
+	balance, _ = processor.GetBalance(bobAddr)
+	assert.Equal(t, big.NewInt(1160), balance)
+	balance, _ = processor.GetBalance(lockAddr)
+	assert.Equal(t, big.NewInt(0), balance)
+	hashLock, _ = processor.GetHashLock(lockAddr)
+	assert.True(t, hashLock.IsEmpty())
+
+	err = processor.ProcessTx(getTestClaimTransaction(1, testHashKey), 3)
+	assert.Equal(t, HTLCLockNotExistErr, err)
+}
+
+func TestAccountStateDB_processHTLCTx_Refund(t *testing.T) {
+	db, root := createTestStateDB()
+	processor, err := NewAccountStateDB(root, NewStateStorageWithCache(db))
+	assert.NoError(t, err)
+	lockAddr := cs_crypto.GetLockAddress(aliceAddr, bobAddr)
+
+	snapshot := processor.Snapshot()
+	err = processor.ProcessTx(getTestLockTransaction(1, big.NewInt(1000), 1), 1)
+	assert.Equal(t, HTLCTimeLockErr, err)
+	processor.RevertToSnapshot(snapshot)
+
+	snapshot = processor.Snapshot()
+	err = processor.ProcessTx(getTestLockTransaction(1, big.NewInt(0), 10), 1)
+	assert.Equal(t, HTLCAmountZeroErr, err)
+	processor.RevertToSnapshot(snapshot)
+
+	err = processor.ProcessTx(getTestLockTransaction(1, big.NewInt(1000), 10), 1)
+	assert.NoError(t, err)
+
+	snapshot = processor.Snapshot()
+	err = processor.ProcessTx(getTestClaimTransaction(0, testHashKey), 10)
+	assert.Equal(t, HTLCLockExpiredErr, err)
+	processor.RevertToSnapshot(snapshot)
+
+	err = processor.ProcessTx(getTestRefundTransaction(2), 10)
+	assert.NoError(t, err)
+	balance, _ := processor.GetBalance(aliceAddr)
+	assert.Equal(t, big.NewInt(4710), balance)
+	balance, _ = processor.GetBalance(lockAddr)
+	assert.Equal(t, big.NewInt(0), balance)
+	timeLock, _ := processor.GetTimeLock(lockAddr)
+	assert.Equal(t, big.NewInt(0), timeLock)
+}
+
+func TestAccountStateDB_ValidHTLCTx_LockAddress(t *testing.T) {
+	db, root := createTestStateDB()
+	processor, err := NewAccountStateDB(root, NewStateStorageWithCache(db))
+	assert.NoError(t, err)
+
+	// bob can not refund the lock of alice
+	_, key2 := createKey()
+	tx := model.CreateRawRefundTx(0, big.NewInt(0), big.NewInt(40), aliceAddr, bobAddr)
+	err = processor.ValidHTLCTx(signTestTx(tx, key2), 1)
+	assert.Equal(t, HTLCLockAddressErr, err)
+
+	tx = model.CreateRawClaimTx(0, testHashKey, big.NewInt(10), big.NewInt(40), aliceAddr, bobAddr)
+	err = processor.ValidHTLCTx(signTestTx(tx, key2), 1)
+	assert.Equal(t, HTLCAmountNotZeroErr, err)
+}
@@ -50,6 +50,15 @@ type StateStorage interface {
 	TrieDB() *trie.Database
 
 	DiskDB() ethdb.Database
+
+	// CommitRoot persists the trie of the root according to the pruning mode.
+	CommitRoot(root common.Hash) error
+
+	// Prune garbage collects the tries of the blocks older than the retained ones, the tries of the block are kept on disk if persist.
+	Prune(persist bool) error
+
+	// Flush writes the tries of the newest block kept in memory to disk.
+	Flush() error
 }
 
 type AccountStateReader interface {
@@ -0,0 +1,174 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package state_processor
+
+import (
+	"errors"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/core/chain-config"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/third-party/log/pbft_log"
+	"math/big"
+)
+
+var (
+	MultiSigInvalidTxErr        = errors.New("invalid multi signature transaction")
+	MultiSigAddressErr          = errors.New("multi signature address not match the key set")
+	MultiSigRegisteredErr       = errors.New("multi signature account already registered")
+	MultiSigNotRegisteredErr    = errors.New("multi signature account not registered")
+	MultiSigNonceErr            = errors.New("multi signature proposal nonce not match")
+	MultiSigNotEnoughErr        = errors.New("multi signature owners signed not enough")
+	MultiSigAmountNotZeroErr    = errors.New("multi signature execute transaction amount must be zero")
+	MultiSigBalanceNotEnoughErr = errors.New("multi signature account balance not enough")
+)
+
+// ValidMultiSigTx check whether the transaction sent to the multi signature address can be processed
+func (state *AccountStateDB) ValidMultiSigTx(tx model.AbstractTransaction) error {
+	if len(tx.ExtraData()) == 0 {
+		_, err := state.GetMultiSigKeySet(*tx.To())
+		return err
+	}
+	switch model.GetMultiSigTxType(tx.ExtraData()) {
+	case model.MultiSigRegister:
+		_, err := state.validMultiSigRegister(tx)
+		return err
+	case model.MultiSigExecute:
+		_, err := state.validMultiSigExecute(tx)
+		return err
+	default:
+		return MultiSigInvalidTxErr
+	}
+}
+
+func (state *AccountStateDB) validMultiSigRegister(tx model.AbstractTransaction) (*model.MultiSigKeySet, error) {
+	var keySet model.MultiSigKeySet
+	if err := model.DecodeMultiSigData(tx.ExtraData(), model.MultiSigRegister, &keySet); err != nil {
+		return nil, err
+	}
+	if err := keySet.Valid(); err != nil {
+		return nil, err
+	}
+	if !keySet.Address().IsEqual(*tx.To()) {
+		return nil, MultiSigAddressErr
+	}
+	if _, err := state.GetMultiSigKeySet(*tx.To()); err == nil {
+		return nil, MultiSigRegisteredErr
+	}
+	return &keySet, nil
+}
+
+// the proposal must be signed by at least threshold owners with the current nonce of the multi signature account
+func (state *AccountStateDB) validMultiSigExecute(tx model.AbstractTransaction) (*model.MultiSigProposal, error) {
+	if tx.Amount().Cmp(big.NewInt(0)) != 0 {
+		return nil, MultiSigAmountNotZeroErr
+	}
+	var data model.MultiSigExecuteData
+	if err := model.DecodeMultiSigData(tx.ExtraData(), model.MultiSigExecute, &data); err != nil {
+		return nil, err
+	}
+	proposal := &data.Proposal
+	if !proposal.MultiSig.IsEqual(*tx.To()) || proposal.To.IsEmpty() || proposal.Amount == nil || proposal.Amount.Sign() < 0 {
+		return nil, MultiSigInvalidTxErr
+	}
+
+	keySet, err := state.GetMultiSigKeySet(proposal.MultiSig)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := state.GetNonce(proposal.MultiSig)
+	if err != nil {
+		return nil, err
+	}
+	if proposal.Nonce != nonce {
+		return nil, MultiSigNonceErr
+	}
+
+	signed := make(map[common.Address]bool)
+	for _, signer := range proposal.Signers(chain_config.GetChainConfig().ChainId, data.Signatures) {
+		if keySet.IsOwner(signer) {
+			signed[signer] = true
+		}
+	}
+	if uint64(len(signed)) < keySet.Threshold {
+		return nil, MultiSigNotEnoughErr
+	}
+
+	balance, err := state.GetBalance(proposal.MultiSig)
+	if err != nil || balance.Cmp(proposal.Amount) < 0 {
+		return nil, MultiSigBalanceNotEnoughErr
+	}
+	return proposal, nil
+}
+
+/*
+Process multi signature transaction
+Register saves the key set and deposits the amount, execute transfers the proposal amount out of the multi signature account
+and increases its nonce so the signatures can't be replayed, a transaction without extra data deposits to the registered account
+*/
+func (state *AccountStateDB) processMultiSigTx(tx model.AbstractTransaction) (err error) {
+	sender, _ := tx.Sender(nil)
+	multiSig := *tx.To()
+	if len(tx.ExtraData()) == 0 {
+		if _, err = state.GetMultiSigKeySet(multiSig); err != nil {
+			return
+		}
+		return state.processNormalTx(tx)
+	}
+
+	switch model.GetMultiSigTxType(tx.ExtraData()) {
+	case model.MultiSigRegister:
+		keySet, err := state.validMultiSigRegister(tx)
+		if err != nil {
+			return err
+		}
+		if state.IsEmptyAccount(multiSig) {
+			if err = state.NewAccountState(multiSig); err != nil {
+				return err
+			}
+		}
+		if err = state.SetMultiSigKeySet(multiSig, keySet); err != nil {
+			return err
+		}
+		if err = state.SubBalance(sender, tx.Amount()); err != nil {
+			return err
+		}
+		pbft_log.Info("success process a multi signature register transaction", "tx hash", tx.CalTxId().Hex(), "address", multiSig.Hex(), "threshold", keySet.Threshold)
+		return state.AddBalance(multiSig, tx.Amount())
+
+	case model.MultiSigExecute:
+		proposal, err := state.validMultiSigExecute(tx)
+		if err != nil {
+			return err
+		}
+		if state.IsEmptyAccount(proposal.To) {
+			if err = state.NewAccountState(proposal.To); err != nil {
+				return err
+			}
+		}
+		if err = state.SubBalance(multiSig, proposal.Amount); err != nil {
+			return err
+		}
+		if err = state.AddNonce(multiSig, 1); err != nil {
+			return err
+		}
+		pbft_log.Info("success process a multi signature execute transaction", "tx hash", tx.CalTxId().Hex(), "address", multiSig.Hex(), "nonce", proposal.Nonce)
+		return state.AddBalance(proposal.To, proposal.Amount)
+
+	default:
+		return MultiSigInvalidTxErr
+	}
+}
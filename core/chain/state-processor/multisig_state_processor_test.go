@@ -0,0 +1,147 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package state_processor
+
+import (
+	"crypto/ecdsa"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/g-error"
+	"github.com/dipperin/dipperin-core/core/chain-config"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/third-party/crypto"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+var testMultiSigKeySet = &model.MultiSigKeySet{Threshold: 2, Owners: []common.Address{aliceAddr, bobAddr, charlieAddr}}
+
+func getTestMultiSigRegisterTx(nonce uint64, keySet *model.MultiSigKeySet, amount *big.Int) *model.Transaction {
+	key1, _ := createKey()
+	tx, err := model.NewMultiSigRegisterTransaction(nonce, keySet, amount, big.NewInt(10))
+	if err != nil {
+		panic(err)
+	}
+	return signTestTx(tx, key1)
+}
+
+// bob submits the proposal signed by the keys
+func getTestMultiSigExecuteTx(nonce uint64, proposal model.MultiSigProposal, keys ...*ecdsa.PrivateKey) *model.Transaction {
+	_, key2 := createKey()
+	hash := proposal.Hash(chain_config.GetChainConfig().ChainId)
+	data := &model.MultiSigExecuteData{Proposal: proposal}
+	for _, key := range keys {
+		sig, _ := crypto.Sign(hash.Bytes(), key)
+		data.Signatures = append(data.Signatures, sig)
+	}
+	tx, _ := model.NewMultiSigExecuteTransaction(nonce, data, big.NewInt(10))
+	return signTestTx(tx, key2)
+}
+
+func TestAccountStateDB_processMultiSigTx(t *testing.T) {
+	db, root := createTestStateDB()
+	processor, err := NewAccountStateDB(root, NewStateStorageWithCache(db))
+	assert.NoError(t, err)
+
+	multiSig := testMultiSigKeySet.Address()
+	_, err = processor.GetMultiSigKeySet(multiSig)
+	assert.Equal(t, g_error.AccountNotExist, err)
+
+	// the registration is reverted with the snapshot
+	snapshot := processor.Snapshot()
+	assert.NoError(t, processor.ProcessTx(getTestMultiSigRegisterTx(1, testMultiSigKeySet, big.NewInt(1000)), 1))
+	processor.RevertToSnapshot(snapshot)
+	assert.True(t, processor.IsEmptyAccount(multiSig))
+
+	assert.NoError(t, processor.ProcessTx(getTestMultiSigRegisterTx(1, testMultiSigKeySet, big.NewInt(1000)), 1))
+	keySet, err := processor.GetMultiSigKeySet(multiSig)
+	assert.NoError(t, err)
+	assert.Equal(t, testMultiSigKeySet, keySet)
+	balance, _ := processor.GetBalance(multiSig)
+	assert.Equal(t, big.NewInt(1000), balance)
+
+	snapshot = processor.Snapshot()
+	err = processor.ProcessTx(getTestMultiSigRegisterTx(2, testMultiSigKeySet, big.NewInt(0)), 1)
+	assert.Equal(t, MultiSigRegisteredErr, err)
+	processor.RevertToSnapshot(snapshot)
+
+	// deposit without extra data
+	key1, key2 := createKey()
+	deposit := signTestTx(model.NewTransaction(2, multiSig, big.NewInt(100), big.NewInt(10), nil), key1)
+	assert.NoError(t, processor.ProcessTx(deposit, 1))
+	balance, _ = processor.GetBalance(multiSig)
+	assert.Equal(t, big.NewInt(1100), balance)
+
+	otherKeySet := &model.MultiSigKeySet{Threshold: 1, Owners: []common.Address{aliceAddr}}
+	deposit = signTestTx(model.NewTransaction(3, otherKeySet.Address(), big.NewInt(100), big.NewInt(10), nil), key1)
+	assert.Equal(t, g_error.AccountNotExist, processor.ValidMultiSigTx(deposit))
+
+	// execute needs the signatures of two owners
+	proposal := model.MultiSigProposal{MultiSig: multiSig, Nonce: 0, To: charlieAddr, Amount: big.NewInt(300)}
+	assert.Equal(t, MultiSigNotEnoughErr, processor.ValidMultiSigTx(getTestMultiSigExecuteTx(0, proposal, key1)))
+	assert.Equal(t, MultiSigNotEnoughErr, processor.ValidMultiSigTx(getTestMultiSigExecuteTx(0, proposal, key1, key1)))
+	notOwner, _ := crypto.GenerateKey()
+	assert.Equal(t, MultiSigNotEnoughErr, processor.ValidMultiSigTx(getTestMultiSigExecuteTx(0, proposal, key1, notOwner)))
+
+	assert.NoError(t, processor.ProcessTx(getTestMultiSigExecuteTx(0, proposal, key1, key2), 1))
+	balance, _ = processor.GetBalance(charlieAddr)
+	assert.Equal(t, big.NewInt(300), balance)
+	balance, _ = processor.GetBalance(multiSig)
+	assert.Equal(t, big.NewInt(800), balance)
+	nonce, _ := processor.GetNonce(multiSig)
+	assert.Equal(t, uint64(1), nonce)
+	balance, _ = processor.GetBalance(bobAddr)
+	assert.Equal(t, big.NewInt(190), balance)
+
+	// the signatures can't be replayed
+	assert.Equal(t, MultiSigNonceErr, processor.ValidMultiSigTx(getTestMultiSigExecuteTx(1, proposal, key1, key2)))
+
+	proposal.Nonce = 1
+	proposal.Amount = big.NewInt(801)
+	assert.Equal(t, MultiSigBalanceNotEnoughErr, processor.ValidMultiSigTx(getTestMultiSigExecuteTx(1, proposal, key1, key2)))
+}
+
+func TestAccountStateDB_ValidMultiSigTx(t *testing.T) {
+	db, root := createTestStateDB()
+	processor, err := NewAccountStateDB(root, NewStateStorageWithCache(db))
+	assert.NoError(t, err)
+
+	multiSig := testMultiSigKeySet.Address()
+	key1, _ := createKey()
+	assert.NoError(t, processor.ValidMultiSigTx(getTestMultiSigRegisterTx(1, testMultiSigKeySet, big.NewInt(1000))))
+
+	tx := signTestTx(model.NewTransaction(1, multiSig, big.NewInt(0), big.NewInt(10), []byte{1, 2, 3}), key1)
+	assert.Equal(t, MultiSigInvalidTxErr, processor.ValidMultiSigTx(tx))
+
+	// the key set doesn't match the address
+	otherKeySet := &model.MultiSigKeySet{Threshold: 1, Owners: []common.Address{aliceAddr}}
+	registerTx, _ := model.NewMultiSigRegisterTransaction(1, otherKeySet, big.NewInt(0), big.NewInt(10))
+	tx = signTestTx(model.NewTransaction(1, multiSig, big.NewInt(0), big.NewInt(10), registerTx.ExtraData()), key1)
+	assert.Equal(t, MultiSigAddressErr, processor.ValidMultiSigTx(tx))
+
+	proposal := model.MultiSigProposal{MultiSig: multiSig, Nonce: 0, To: charlieAddr, Amount: big.NewInt(300)}
+	assert.Equal(t, g_error.AccountNotExist, processor.ValidMultiSigTx(getTestMultiSigExecuteTx(0, proposal)))
+
+	// the proposal is for another multi signature account
+	proposal.MultiSig = otherKeySet.Address()
+	executeTx := getTestMultiSigExecuteTx(0, proposal)
+	tx = signTestTx(model.NewTransaction(1, multiSig, big.NewInt(0), big.NewInt(10), executeTx.ExtraData()), key1)
+	assert.Equal(t, MultiSigInvalidTxErr, processor.ValidMultiSigTx(tx))
+
+	tx = signTestTx(model.NewTransaction(1, otherKeySet.Address(), big.NewInt(1), big.NewInt(10), executeTx.ExtraData()), key1)
+	assert.Equal(t, MultiSigAmountNotZeroErr, processor.ValidMultiSigTx(tx))
+}
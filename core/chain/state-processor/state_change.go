@@ -121,6 +121,22 @@ func (scl *StateChangeList) DecodeRLP(s *rlp.Stream) (err error) {
 			var change deleteAccountChange
 			rlp.DecodeBytes(state.StateChange, &change)
 			scl.append(change)
+		case MultiSigChange:
+			var change multiSigChange
+			rlp.DecodeBytes(state.StateChange, &change)
+			scl.append(change)
+		case JailChange:
+			var change jailChange
+			rlp.DecodeBytes(state.StateChange, &change)
+			scl.append(change)
+		case DelegationChange:
+			var change delegationChange
+			rlp.DecodeBytes(state.StateChange, &change)
+			scl.append(change)
+		case EvidenceChange:
+			var change evidenceChange
+			rlp.DecodeBytes(state.StateChange, &change)
+			scl.append(change)
 		default:
 			panic("no type")
 		}
@@ -263,6 +279,10 @@ const (
 	LastElectChange
 
 	DeleteAccountChange
+	MultiSigChange
+	JailChange
+	DelegationChange
+	EvidenceChange
 )
 
 type (
@@ -341,6 +361,34 @@ type (
 		Current    uint64
 		ChangeType uint64
 	}
+	// the rlp encoded key sets, empty if not registered
+	multiSigChange struct {
+		Account    *common.Address
+		Prev       []byte
+		Current    []byte
+		ChangeType uint64
+	}
+	// the block number jailed until, 0 if not jailed
+	jailChange struct {
+		Account    *common.Address
+		Prev       uint64
+		Current    uint64
+		ChangeType uint64
+	}
+	// the rlp encoded delegations of the verifier, empty if no delegation
+	delegationChange struct {
+		Account    *common.Address
+		Prev       []byte
+		Current    []byte
+		ChangeType uint64
+	}
+	// the rlp encoded slashed votes of the verifier, empty if none within the evidence window
+	evidenceChange struct {
+		Account    *common.Address
+		Prev       []byte
+		Current    []byte
+		ChangeType uint64
+	}
 )
 
 func (sc deleteAccountChange) revert(s *AccountStateDB) {
@@ -386,6 +434,102 @@ func (sc newAccountChange) digest(change StateChange) StateChange {
 
 
 
+func (sc multiSigChange) revert(s *AccountStateDB) {
+	s.setMultiSigKeySet(*sc.Account, sc.Prev)
+}
+
+func (sc multiSigChange) recover(s *AccountStateDB) {
+	s.setMultiSigKeySet(*sc.Account, sc.Current)
+}
+
+func (sc multiSigChange) dirtied() *common.Address {
+	return sc.Account
+}
+
+func (sc multiSigChange) getType() int {
+	return int(sc.ChangeType)
+}
+
+func (sc multiSigChange) digest(change StateChange) StateChange {
+	if change.getType() == MultiSigChange {
+		c := change.(multiSigChange)
+		return multiSigChange{Account: sc.Account, Prev: c.Prev, Current: sc.Current, ChangeType: MultiSigChange}
+	}
+	return nil
+}
+
+func (sc delegationChange) revert(s *AccountStateDB) {
+	s.setDelegations(*sc.Account, sc.Prev)
+}
+
+func (sc delegationChange) recover(s *AccountStateDB) {
+	s.setDelegations(*sc.Account, sc.Current)
+}
+
+func (sc delegationChange) dirtied() *common.Address {
+	return sc.Account
+}
+
+func (sc delegationChange) getType() int {
+	return int(sc.ChangeType)
+}
+
+func (sc delegationChange) digest(change StateChange) StateChange {
+	if change.getType() == DelegationChange {
+		c := change.(delegationChange)
+		return delegationChange{Account: sc.Account, Prev: c.Prev, Current: sc.Current, ChangeType: DelegationChange}
+	}
+	return nil
+}
+
+func (sc evidenceChange) revert(s *AccountStateDB) {
+	s.setSlashedVotes(*sc.Account, sc.Prev)
+}
+
+func (sc evidenceChange) recover(s *AccountStateDB) {
+	s.setSlashedVotes(*sc.Account, sc.Current)
+}
+
+func (sc evidenceChange) dirtied() *common.Address {
+	return sc.Account
+}
+
+func (sc evidenceChange) getType() int {
+	return int(sc.ChangeType)
+}
+
+func (sc evidenceChange) digest(change StateChange) StateChange {
+	if change.getType() == EvidenceChange {
+		c := change.(evidenceChange)
+		return evidenceChange{Account: sc.Account, Prev: c.Prev, Current: sc.Current, ChangeType: EvidenceChange}
+	}
+	return nil
+}
+
+func (sc jailChange) revert(s *AccountStateDB) {
+	s.setJailedUntil(*sc.Account, sc.Prev)
+}
+
+func (sc jailChange) recover(s *AccountStateDB) {
+	s.setJailedUntil(*sc.Account, sc.Current)
+}
+
+func (sc jailChange) dirtied() *common.Address {
+	return sc.Account
+}
+
+func (sc jailChange) getType() int {
+	return int(sc.ChangeType)
+}
+
+func (sc jailChange) digest(change StateChange) StateChange {
+	if change.getType() == JailChange {
+		c := change.(jailChange)
+		return jailChange{Account: sc.Account, Prev: c.Prev, Current: sc.Current, ChangeType: JailChange}
+	}
+	return nil
+}
+
 func (sc lastElectChange) revert(s *AccountStateDB) {
 	s.setLastElect(*sc.Account, sc.Prev)
 }
@@ -151,6 +151,18 @@ func (storage fakeStateStorage) DiskDB() ethdb.Database {
 	return ethdb.NewMemDatabase()
 }
 
+func (storage fakeStateStorage) CommitRoot(root common.Hash) error {
+	return nil
+}
+
+func (storage fakeStateStorage) Prune(persist bool) error {
+	return nil
+}
+
+func (storage fakeStateStorage) Flush() error {
+	return nil
+}
+
 type fakeTrie struct {
 	getErr    error
 	setErr    error
@@ -273,3 +285,15 @@ func (tx fakeTransaction) Cost() *big.Int {
 func (tx fakeTransaction) EstimateFee() *big.Int {
 	panic("implement me")
 }
+
+func (tx fakeTransaction) HashLock() *common.Hash {
+	return nil
+}
+
+func (tx fakeTransaction) TimeLock() *big.Int {
+	return big.NewInt(0)
+}
+
+func (tx fakeTransaction) HashKey() []byte {
+	return nil
+}
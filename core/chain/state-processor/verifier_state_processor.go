@@ -18,12 +18,14 @@ package state_processor
 
 import (
 	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/core/chain-config"
 	"github.com/dipperin/dipperin-core/core/model"
 	"github.com/dipperin/dipperin-core/third-party/log"
 	"github.com/dipperin/dipperin-core/third-party/log/pbft_log"
 	"math/big"
 	"errors"
 	"github.com/dipperin/dipperin-core/third-party/crypto/cs-crypto"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 var (
@@ -34,6 +36,10 @@ var (
 	ReceiverNotExistErr   = errors.New("receiver account does not exist")
 	SendRegisterTxFirst   = errors.New("target need to send register transaction first")
 	SendCancelTxFirst     = errors.New("target need to send cancel transaction first")
+	SlashPercentErr       = errors.New("slash percent is more than 100")
+	EvidenceHeightErr     = errors.New("evidence height is above the block")
+	EvidenceExpiredErr    = errors.New("evidence is expired")
+	EvidenceProcessedErr  = errors.New("evidence has been processed")
 )
 
 /*
@@ -118,6 +124,127 @@ func (state *AccountStateDB) MoveStakeToAddress(fromAdd common.Address, toAdd co
 	return nil
 }
 
+/*
+Slash the stake of the offender, burn burnPercent of it and reward rewardPercent of it to the reporter.
+Returns the slashed amount
+*/
+func (state *AccountStateDB) SlashStake(offender common.Address, reporter common.Address, burnPercent uint64, rewardPercent uint64) (*big.Int, error) {
+	if burnPercent+rewardPercent > 100 {
+		return nil, SlashPercentErr
+	}
+	stake, err := state.GetStake(offender)
+	if err != nil {
+		return nil, err
+	}
+	if stake.Cmp(big.NewInt(0)) == 0 {
+		log.Warn("slash stake is zero", "address", offender.Hex())
+		return nil, NotEnoughStakeErr
+	}
+
+	burn := new(big.Int).Div(new(big.Int).Mul(stake, new(big.Int).SetUint64(burnPercent)), big.NewInt(100))
+	reward := new(big.Int).Div(new(big.Int).Mul(stake, new(big.Int).SetUint64(rewardPercent)), big.NewInt(100))
+	if reward.Sign() > 0 && state.IsEmptyAccount(reporter) {
+		if err = state.NewAccountState(reporter); err != nil {
+			return nil, err
+		}
+	}
+
+	slashed := new(big.Int).Add(burn, reward)
+	if err = state.SubStake(offender, slashed); err != nil {
+		return nil, err
+	}
+	if reward.Sign() > 0 {
+		if err = state.AddBalance(reporter, reward); err != nil {
+			return nil, err
+		}
+	}
+	pbft_log.Info("slash stake", "offender", offender.Hex(), "reporter", reporter.Hex(), "burn", burn, "reward", reward)
+	return slashed, nil
+}
+
+/*Exclude the verifier from the election until the block number, a shorter jail doesn't override a longer one*/
+func (state *AccountStateDB) Jail(addr common.Address, until uint64) error {
+	old, err := state.GetJailedUntil(addr)
+	if err != nil {
+		return err
+	}
+	if old >= until {
+		return nil
+	}
+	pbft_log.Info("jail verifier", "address", addr.Hex(), "until", until)
+	return state.SetJailedUntil(addr, until)
+}
+
+func (state *AccountStateDB) IsJailed(addr common.Address, blockNum uint64) bool {
+	until, err := state.GetJailedUntil(addr)
+	if err != nil {
+		return false
+	}
+	return until > blockNum
+}
+
+/*
+Check the double sign of the offender can be punished in the block,
+it must not be older than the evidence window or recorded by an earlier evidence
+*/
+func (state *AccountStateDB) CheckEvidence(offender common.Address, vote model.SlashedVote, blockNum uint64) error {
+	if vote.Height > blockNum {
+		return EvidenceHeightErr
+	}
+	conf := chain_config.GetChainConfig()
+	if blockNum-vote.Height > conf.SlashEvidenceSlot*conf.SlotSize {
+		return EvidenceExpiredErr
+	}
+	slashed, err := state.GetSlashedVotes(offender)
+	if err != nil {
+		return err
+	}
+	for _, v := range slashed {
+		if v == vote {
+			return EvidenceProcessedErr
+		}
+	}
+	return nil
+}
+
+/*Record the punished double sign, the votes out of the evidence window are dropped*/
+func (state *AccountStateDB) recordSlashedVote(offender common.Address, vote model.SlashedVote, blockNum uint64) error {
+	slashed, err := state.GetSlashedVotes(offender)
+	if err != nil {
+		return err
+	}
+	conf := chain_config.GetChainConfig()
+	votes := []model.SlashedVote{vote}
+	for _, v := range slashed {
+		if blockNum-v.Height <= conf.SlashEvidenceSlot*conf.SlotSize {
+			votes = append(votes, v)
+		}
+	}
+	return state.SetSlashedVotes(offender, votes)
+}
+
+/*Punish the verifier that missed all the verifications of a slot*/
+func (state *AccountStateDB) ProcessLivenessFault(addr common.Address, blockNum uint64) error {
+	stake, err := state.GetStake(addr)
+	if err != nil || stake.Cmp(big.NewInt(0)) == 0 {
+		return nil
+	}
+
+	conf := chain_config.GetChainConfig()
+	if !conf.IsForkActive(chain_config.ForkSlashing, blockNum) {
+		return nil
+	}
+	if conf.LivenessSlashPercent > 0 {
+		if _, err = state.SlashStake(addr, common.Address{}, conf.LivenessSlashPercent, 0); err != nil {
+			return err
+		}
+	}
+	if conf.LivenessJailSlot > 0 {
+		return state.Jail(addr, blockNum+conf.LivenessJailSlot*conf.SlotSize)
+	}
+	return nil
+}
+
 /*
 * Process verifier related transactions
 * Include AddPeerSet(Stake), Evidence, Cancel, UnStake
@@ -243,7 +370,7 @@ Process Evidence tx
 Punish target account
 Move all target account stake to the sender of this transaction
 */
-func (state *AccountStateDB) processEvidenceTx(tx model.AbstractTransaction) (err error) {
+func (state *AccountStateDB) processEvidenceTx(tx model.AbstractTransaction, blockNum uint64) (err error) {
 
 	//Check
 	sender, _ := tx.Sender(nil)
@@ -259,12 +386,34 @@ func (state *AccountStateDB) processEvidenceTx(tx model.AbstractTransaction) (er
 	if empty := state.IsEmptyAccount(originalReceiver); empty {
 		return ReceiverNotExistErr
 	}
+	// the whole stake is moved to the reporter before the slashing fork
+	conf := chain_config.GetChainConfig()
+	if !conf.IsForkActive(chain_config.ForkSlashing, blockNum) {
+		return state.MoveStakeToAddress(originalReceiver, sender)
+	}
+
+	var proofs model.Proofs
+	if err = rlp.DecodeBytes(tx.ExtraData(), &proofs); err != nil {
+		return err
+	}
+	vote := proofs.DoubleSign()
+	if err = state.CheckEvidence(originalReceiver, vote, blockNum); err != nil {
+		return err
+	}
 
 	//Process
-	err = state.MoveStakeToAddress(originalReceiver, sender)
+	_, err = state.SlashStake(originalReceiver, sender, conf.SlashBurnPercent, conf.SlashReporterPercent)
 	if err != nil {
 		return err
 	}
+	if conf.SlashJailSlot > 0 {
+		if err = state.Jail(originalReceiver, blockNum+conf.SlashJailSlot*conf.SlotSize); err != nil {
+			return err
+		}
+	}
+	if err = state.recordSlashedVote(originalReceiver, vote, blockNum); err != nil {
+		return err
+	}
 
 	//TODO add receipt return
 	return nil
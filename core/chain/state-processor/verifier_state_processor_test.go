@@ -26,6 +26,7 @@ import (
 	"github.com/dipperin/dipperin-core/common/g-error"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/core/chain-config"
 )
 
 /*
@@ -248,10 +249,97 @@ func TestAccountStateDB_processEvidenceTx_Error(t *testing.T) {
 	assert.NoError(t, err)
 
 	tx := getTestRegisterTransaction(0, key1, big.NewInt(10))
-	err = processor.processEvidenceTx(tx)
+	err = processor.processEvidenceTx(tx, 1)
 	assert.Equal(t, TransactionTypeError, err)
 
 	tx = getTestEvidenceTransaction(0, key1, common.HexToAddress("123"), &model.VoteMsg{}, &model.VoteMsg{})
-	err = processor.processEvidenceTx(tx)
+	err = processor.processEvidenceTx(tx, 1)
 	assert.Equal(t, ReceiverNotExistErr, err)
-}
\ No newline at end of file
+}
+func TestAccountStateDB_SlashStake(t *testing.T) {
+	db, root := createTestStateDB()
+	processor, _ := NewAccountStateDB(root, NewStateStorageWithCache(db))
+	reporter := common.HexToAddress("0x0000b4293d60F051936beDecfaE1B85d5A46d377aF37")
+
+	_, err := processor.SlashStake(aliceAddr, reporter, 60, 50)
+	assert.Equal(t, SlashPercentErr, err)
+	_, err = processor.SlashStake(aliceAddr, reporter, 20, 10)
+	assert.Equal(t, NotEnoughStakeErr, err)
+
+	assert.NoError(t, processor.AddStake(aliceAddr, big.NewInt(1000)))
+	slashed, err := processor.SlashStake(aliceAddr, reporter, 20, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(300), slashed)
+
+	stake, _ := processor.GetStake(aliceAddr)
+	assert.Equal(t, big.NewInt(700), stake)
+	balance, _ := processor.GetBalance(reporter)
+	assert.Equal(t, big.NewInt(100), balance)
+}
+
+func TestAccountStateDB_Jail(t *testing.T) {
+	db, root := createTestStateDB()
+	processor, _ := NewAccountStateDB(root, NewStateStorageWithCache(db))
+
+	assert.Equal(t, g_error.AccountNotExist, processor.Jail(common.HexToAddress("0x1234"), 10))
+	assert.False(t, processor.IsJailed(aliceAddr, 1))
+
+	snapshot := processor.Snapshot()
+	assert.NoError(t, processor.Jail(aliceAddr, 10))
+	assert.True(t, processor.IsJailed(aliceAddr, 9))
+	assert.False(t, processor.IsJailed(aliceAddr, 10))
+
+	// a shorter jail doesn't override the longer one
+	assert.NoError(t, processor.Jail(aliceAddr, 5))
+	until, _ := processor.GetJailedUntil(aliceAddr)
+	assert.Equal(t, uint64(10), until)
+
+	processor.RevertToSnapshot(snapshot)
+	until, _ = processor.GetJailedUntil(aliceAddr)
+	assert.Equal(t, uint64(0), until)
+}
+
+func TestAccountStateDB_ProcessLivenessFault(t *testing.T) {
+	db, root := createTestStateDB()
+	processor, _ := NewAccountStateDB(root, NewStateStorageWithCache(db))
+	conf := chain_config.GetChainConfig()
+
+	// no stake no punishment
+	assert.NoError(t, processor.ProcessLivenessFault(aliceAddr, 10))
+	assert.False(t, processor.IsJailed(aliceAddr, 10))
+
+	assert.NoError(t, processor.AddStake(aliceAddr, big.NewInt(1000)))
+	assert.NoError(t, processor.ProcessLivenessFault(aliceAddr, 10))
+	stake, _ := processor.GetStake(aliceAddr)
+	assert.Equal(t, big.NewInt(int64(1000-10*conf.LivenessSlashPercent)), stake)
+	until, _ := processor.GetJailedUntil(aliceAddr)
+	assert.Equal(t, 10+conf.LivenessJailSlot*conf.SlotSize, until)
+}
+
+func TestAccountStateDB_SlashingFork(t *testing.T) {
+	processor := createStateProcessor(t)
+	key1, _ := createKey()
+	assert.NoError(t, processor.AddStake(bobAddr, big.NewInt(1000)))
+
+	conf := chain_config.GetChainConfig()
+	conf.Forks[chain_config.ForkSlashing] = 10
+	defer func() { conf.Forks[chain_config.ForkSlashing] = 0 }()
+
+	// the offline verifier isn't punished before the fork
+	assert.NoError(t, processor.ProcessLivenessFault(bobAddr, 9))
+	stake, _ := processor.GetStake(bobAddr)
+	assert.Equal(t, big.NewInt(1000), stake)
+	assert.False(t, processor.IsJailed(bobAddr, 9))
+
+	// the whole stake is moved to the reporter before the fork
+	voteA := model.CreateSignedVote(1, 2, common.HexToHash("0x123456"), model.VoteMessage)
+	voteB := model.CreateSignedVote(1, 2, common.HexToHash("0x654321"), model.VoteMessage)
+	aliceBalance, _ := processor.GetBalance(aliceAddr)
+	tx := getTestEvidenceTransaction(1, key1, bobAddr, voteA, voteB)
+	assert.NoError(t, processor.ProcessTx(tx, 9))
+	stake, _ = processor.GetStake(bobAddr)
+	assert.Equal(t, big.NewInt(0), stake)
+	balance, _ := processor.GetBalance(aliceAddr)
+	assert.Equal(t, new(big.Int).Add(aliceBalance, big.NewInt(1000-40)), balance)
+	assert.False(t, processor.IsJailed(bobAddr, 9))
+}
@@ -248,6 +248,18 @@ func (storage fakeStateStorage) DiskDB() ethdb.Database {
 	return ethdb.NewMemDatabase()
 }
 
+func (storage fakeStateStorage) CommitRoot(root common.Hash) error {
+	return nil
+}
+
+func (storage fakeStateStorage) Prune(persist bool) error {
+	return nil
+}
+
+func (storage fakeStateStorage) Flush() error {
+	return nil
+}
+
 type fakeTrie struct {
 	getErr          error
 	setErr          error
@@ -259,6 +271,10 @@ func (t fakeTrie) TryGet(key []byte) ([]byte, error) {
 	if t.errKey == string(key[22:]) {
 		return nil, TrieError
 	}
+	// no delegation
+	if string(key[22:]) == "_delegation" {
+		return nil, t.getErr
+	}
 	if t.contractBalance != nil {
 		result, _ := rlp.EncodeToBytes(big.NewInt(*t.contractBalance))
 		return result, t.getErr
@@ -366,6 +382,10 @@ func (model fakeEconomyModel) GetOneBlockTotalDIPReward(blockNumber uint64) (*bi
 	panic("implement me")
 }
 
+func (model fakeEconomyModel) ShareRewardWithDelegators(blockNumber uint64) bool {
+	return true
+}
+
 type earlyContractFakeChainService struct{}
 
 func (s *earlyContractFakeChainService) GetVerifiers(slotNum uint64) (addresses []common.Address) {
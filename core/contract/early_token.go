@@ -65,6 +65,11 @@ type EarlyRewardContractForMarshaling struct {
 var EarlyRewardContractStr string
 
 func init(){
+	InitEarlyRewardContract()
+}
+
+// InitEarlyRewardContract makes the early token contract of the genesis from the pre-mining proportion of the economy model
+func InitEarlyRewardContract() {
 	foundation := economy_model.MakeDipperinFoundation(economy_model.DIPProportion)
 	owner := economy_model.EarlyTokenAddresses[0]
 	decimalBase := big.NewInt(0).Exp(big.NewInt(10), big.NewInt(int64(DecimalUnits)), nil)
@@ -0,0 +1,55 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package contract
+
+import (
+	"encoding/json"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/hexutil"
+	"github.com/dipperin/dipperin-core/core/model"
+)
+
+// parse the token transfer or approve made by the contract call, nil if the tx isn't one of them
+func ParseTokenEvent(tx model.AbstractTransaction) *model.TokenEvent {
+	eData := ParseExtraDataForContract(tx.ExtraData())
+	if eData == nil || tx.To() == nil {
+		return nil
+	}
+	var params []string
+	if err := json.Unmarshal([]byte(eData.Params), &params); err != nil {
+		return nil
+	}
+
+	sender, _ := tx.Sender(nil)
+	event := &model.TokenEvent{Contract: *tx.To(), Action: eData.Action}
+	var valueStr string
+	switch {
+	case (eData.Action == "Transfer" || eData.Action == "Approve") && len(params) == 2:
+		event.From, event.To, valueStr = sender, common.HexToAddress(params[0]), params[1]
+	case eData.Action == "TransferFrom" && len(params) == 3:
+		event.From, event.To, valueStr = common.HexToAddress(params[0]), common.HexToAddress(params[1]), params[2]
+	default:
+		return nil
+	}
+
+	value, err := hexutil.DecodeBig(valueStr)
+	if err != nil {
+		return nil
+	}
+	event.Value = value
+	return event
+}
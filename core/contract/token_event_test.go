@@ -0,0 +1,62 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package contract
+
+import (
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/util"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+func createTokenEventTx(contractAddr common.Address, action string, params ...interface{}) *model.Transaction {
+	extraData := ExtraDataForContract{ContractAddress: contractAddr, Action: action, Params: util.StringifyJson(params)}
+	return createTokenEventTxWithData(contractAddr, util.StringifyJsonToBytes(extraData))
+}
+
+func createTokenEventTxWithData(contractAddr common.Address, extraData []byte) *model.Transaction {
+	tx := model.NewTransaction(0, contractAddr, big.NewInt(0), big.NewInt(10), extraData)
+	key, _ := model.CreateKey()
+	tx.SignTx(key, model.NewMercurySigner(big.NewInt(1)))
+	return tx
+}
+
+func TestParseTokenEvent(t *testing.T) {
+	contractAddr := common.HexToAddress("0x00102B9aE8e4a04B3d2Dc8a60D2E9E4E0A60A5b7aA30")
+	receiver := common.HexToAddress("0x0000b4293d60F051936beDecfaE1B85d5A46d377aF37")
+	owner := common.HexToAddress("0x00005586B883Ec6dd4f8c26063E18eb4Bd228e59c3E9")
+
+	tx := createTokenEventTx(contractAddr, "Transfer", receiver.Hex(), "0x64")
+	sender, _ := tx.Sender(nil)
+	event := ParseTokenEvent(tx)
+	assert.Equal(t, &model.TokenEvent{Contract: contractAddr, Action: "Transfer", From: sender, To: receiver, Value: big.NewInt(100)}, event)
+
+	tx = createTokenEventTx(contractAddr, "Approve", receiver.Hex(), "0x1")
+	sender, _ = tx.Sender(nil)
+	event = ParseTokenEvent(tx)
+	assert.Equal(t, &model.TokenEvent{Contract: contractAddr, Action: "Approve", From: sender, To: receiver, Value: big.NewInt(1)}, event)
+
+	event = ParseTokenEvent(createTokenEventTx(contractAddr, "TransferFrom", owner.Hex(), receiver.Hex(), "0x10"))
+	assert.Equal(t, &model.TokenEvent{Contract: contractAddr, Action: "TransferFrom", From: owner, To: receiver, Value: big.NewInt(16)}, event)
+
+	assert.Nil(t, ParseTokenEvent(createTokenEventTx(contractAddr, "Approve", receiver.Hex(), "100")))
+	assert.Nil(t, ParseTokenEvent(createTokenEventTx(contractAddr, "Transfer", receiver.Hex())))
+	assert.Nil(t, ParseTokenEvent(createTokenEventTx(contractAddr, "BalanceOf", receiver.Hex())))
+	assert.Nil(t, ParseTokenEvent(createTokenEventTxWithData(contractAddr, []byte("test"))))
+}
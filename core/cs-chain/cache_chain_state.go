@@ -20,6 +20,7 @@ package cs_chain
 import (
 	"errors"
 	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/g-event"
 	"github.com/dipperin/dipperin-core/core/chain-config"
 	"github.com/dipperin/dipperin-core/core/cs-chain/chain-state"
 	"github.com/dipperin/dipperin-core/core/cs-chain/chain-writer/middleware"
@@ -27,6 +28,7 @@ import (
 	"github.com/dipperin/dipperin-core/third-party/log"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/hashicorp/golang-lru"
+	"sync"
 	"sync/atomic"
 )
 
@@ -93,6 +95,10 @@ type CacheChainState struct {
 	genesisBlock  model.AbstractBlock
 	currentBlock  atomic.Value
 	currentHeader atomic.Value
+
+	// the blocks removed by the rollbacks and not notified yet
+	rolledBackLock sync.Mutex
+	rolledBack     []model.Block
 }
 
 func (chain *CacheChainState) SaveBftBlock(block model.AbstractBlock, seenCommits []model.AbstractVerification) error {
@@ -241,6 +247,23 @@ func (chain *CacheChainState) GetVerifiers(slot uint64) []common.Address {
 	return vs
 }
 
+func (chain *CacheChainState) GetBlockVerifiers(block model.AbstractBlock) []common.Address {
+	slot := chain.GetSlot(block)
+	if slot == nil {
+		return nil
+	}
+	if vs, ok := chain.cachedVerifiers.Get(*slot); ok {
+		return vs.([]common.Address)
+	}
+
+	vs := chain.ChainState.GetBlockVerifiers(block)
+	if len(vs) > 0 {
+		chain.cachedVerifiers.Add(*slot, vs)
+	}
+
+	return vs
+}
+
 func (chain *CacheChainState) GetSlotByNum(num uint64) *uint64 {
 	if s, ok := chain.slotCache.Get(num); ok {
 		return s.(*uint64)
@@ -274,6 +297,7 @@ func (chain *CacheChainState) GetBlockByHash(hash common.Hash) model.AbstractBlo
 }
 
 func (chain *CacheChainState) Rollback(target uint64) error {
+	oldCurrent := chain.CurrentBlock()
 
 	// rollback chain to target number
 	tarBlock := chain.GetBlockByNumber(target)
@@ -284,13 +308,36 @@ func (chain *CacheChainState) Rollback(target uint64) error {
 			log.Error("chain can't roll back target block, no found target block and pre target block")
 			return errors.New("pre target block is nil")
 		}
+		tarBlock = perTarBlock
+	}
 
-		chain.currentBlock.Store(perTarBlock)
-		chain.currentHeader.Store(perTarBlock.Header())
-		return nil
+	// the removed blocks are still canonical until the new blocks are saved at their numbers
+	var removed []model.Block
+	for number := oldCurrent.Number(); number > tarBlock.Number(); number-- {
+		if block, ok := chain.GetBlockByNumber(number).(*model.Block); ok {
+			removed = append(removed, *block)
+		}
 	}
 
 	chain.currentBlock.Store(tarBlock)
 	chain.currentHeader.Store(tarBlock.Header())
+
+	chain.rolledBackLock.Lock()
+	chain.rolledBack = append(chain.rolledBack, removed...)
+	chain.rolledBackLock.Unlock()
 	return nil
 }
+
+// SendRollbackEvents notify the blocks removed by the rollbacks since the last call from the highest one.
+// The rollback happens while the block is saved, so it's called after the save block lock is released
+// to let the subscribers read the chain
+func (chain *CacheChainState) SendRollbackEvents() {
+	chain.rolledBackLock.Lock()
+	removed := chain.rolledBack
+	chain.rolledBack = nil
+	chain.rolledBackLock.Unlock()
+
+	for _, block := range removed {
+		g_event.Send(g_event.ChainRollbackEvent, block)
+	}
+}
@@ -38,6 +38,10 @@ type ChainStateConfig struct {
 	ChainConfig   *chain_config.ChainConfig
 	DataDir string
 	WriterFactory chain_writer.AbstractChainWriterFactory
+	// maintain the address tx index
+	AddressIndex bool
+	// keep the state tries of the last blocks in memory and garbage collect the older ones, 0 archives all the states
+	StateRetainBlocks int
 }
 
 // the struct of ChainState
@@ -67,6 +71,9 @@ func (cs *ChainState) BlockProcessorByNumber(num uint64) (*chain.BlockProcessor,
 // create a new ChainState
 func NewChainState(conf *ChainStateConfig) *ChainState {
 	g_event.Add(g_event.NewBlockInsertEvent)
+	g_event.Add(g_event.ChainRollbackEvent)
+	// the tx pool is built on the chain state, its events are added once with the chain events
+	g_event.Add(g_event.NewTxInPoolEvent)
 	cs := &ChainState{ ChainStateConfig: conf }
 	cs.initConfigAndDB(conf.DataDir)
 	cs.WriterFactory = conf.WriterFactory
@@ -91,9 +98,19 @@ func (cs *ChainState) initConfigAndDB(dataDir string) {
 	cs.ChainConfig = chain_config.GetChainConfig()
 
 	// init chainDB
-	cs.ChainDB = chaindb.NewChainDB(ethDB, blockDecoder)
+	chainDB := chaindb.NewChainDB(ethDB, blockDecoder)
+	if cs.AddressIndex {
+		chainDB.EnableAddressIndex()
+	} else {
+		chainDB.DisableAddressIndex()
+	}
+	cs.ChainDB = chainDB
 
-	cs.StateStorage = state_processor.NewStateStorageWithCache(ethDB)
+	if cs.StateRetainBlocks > 0 {
+		cs.StateStorage = state_processor.NewPrunedStateStorage(ethDB, cs.StateRetainBlocks)
+	} else {
+		cs.StateStorage = state_processor.NewStateStorageWithCache(ethDB)
+	}
 
 	// init economy model
 	cs.EconomyModel = economy_model.MakeDipperinEconomyModel(cs, economy_model.DIPProportion)
@@ -25,6 +25,7 @@ import (
 	"github.com/dipperin/dipperin-core/core/model"
 	"github.com/dipperin/dipperin-core/third-party/log"
 	"github.com/dipperin/dipperin-core/third-party/log/pbft_log"
+	"math/big"
 )
 
 func (cs *ChainState) BuildRegisterProcessor(preBlockRegisterRoot common.Hash) (*registerdb.RegisterDB, error) {
@@ -199,7 +200,11 @@ func (cs *ChainState) NumBeforeLastBySlot(slot uint64) *uint64 {
 
 // The returned value is the last block of the slot. If the slot is incomplete, it returns null.
 func (cs *ChainState) GetNumBySlot(slot uint64) *uint64 {
-	block := cs.CurrentBlock()
+	return cs.getNumBySlotFrom(cs.CurrentBlock(), slot)
+}
+
+// the last block of the slot found by walking down from the block
+func (cs *ChainState) getNumBySlotFrom(block model.AbstractBlock, slot uint64) *uint64 {
 	for {
 		blockSlot := cs.GetSlot(block)
 		if slot > *blockSlot {
@@ -222,6 +227,29 @@ func (cs *ChainState) GetNumBySlot(slot uint64) *uint64 {
 	}
 }
 
+// GetBlockVerifiers returns the verifiers of the block's slot elected with the blocks below it,
+// the fast sync checks the saved blocks with it before the current block is set
+func (cs *ChainState) GetBlockVerifiers(block model.AbstractBlock) []common.Address {
+	config := cs.GetChainConfig()
+	slot := cs.GetSlot(block)
+	if slot == nil {
+		return nil
+	}
+	if *slot < config.SlotMargin {
+		return chain.VerifierAddress[:config.VerifierNumber]
+	}
+
+	preBlock := cs.GetBlockByNumber(block.Number() - 1)
+	if preBlock == nil {
+		return nil
+	}
+	num := cs.getNumBySlotFrom(preBlock, *slot-config.SlotMargin)
+	if num == nil {
+		return nil
+	}
+	return cs.CalVerifiers(cs.GetBlockByNumber(*num))
+}
+
 // Calculate the verifiers after two rounds based on the last block of each round
 func (cs *ChainState) CalVerifiers(block model.AbstractBlock) []common.Address {
 
@@ -232,16 +260,26 @@ func (cs *ChainState) CalVerifiers(block model.AbstractBlock) []common.Address {
 	log.Info("the register root is:","root",root.Hex())
 	register, err := cs.BuildRegisterProcessor(root)
 	if err != nil {
-		pbft_log.Debug("BuildRegisterProcessor failed", "err", err)
+		log.Error("BuildRegisterProcessor failed", "num", block.Number(), "err", err)
+		return nil
 	}
 	list := register.GetRegisterData()
 	//pbft_log.Debug("GetRegisterData", "register data", list, "root", root)
 	//log.Info("GetRegisterData", "register data", list, "root", root)
+	state, err := cs.StateAtByStateRoot(block.StateRoot())
+	if err != nil {
+		pbft_log.Debug("StateAtByStateRoot failed", "err", err)
+	}
 
 	// get top verifiers
 	var topAddress []common.Address
 	var topPriority []uint64
 	for i := 0; i < len(list); i++ {
+		// jailed verifiers can't be elected until the jail expires
+		if state != nil && state.IsJailed(list[i], block.Number()) {
+			pbft_log.Info("skip jailed verifier", "addr", list[i].Hex(), "num", block.Number())
+			continue
+		}
 		priority, err := cs.calPriority(list[i], block.Number())
 		if err != nil {
 			pbft_log.Info("calPriority", "err", err)
@@ -323,6 +361,11 @@ func (cs *ChainState) calPriority(addr common.Address, blockNum uint64) (uint64,
 	stake, err := state.GetStake(addr)
 	performance, err := state.GetPerformance(addr)
 
+	// the bonded delegations count toward the election weight
+	if delegated, err := state.GetDelegatedStake(addr); err == nil {
+		stake = new(big.Int).Add(stake, delegated)
+	}
+
 	// todo take this shit to Ox Star Star
 	priority, err := model.DefaultPriorityCalculator.GetElectPriority(luck, accountNonce, stake, performance)
 	if err != nil {
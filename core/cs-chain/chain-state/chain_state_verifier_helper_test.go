@@ -28,6 +28,16 @@ import (
 )
 
 var testFee = economy_model.GetMinimumTxFee(20001)
+
+type fakeStateRootBlock struct {
+	model.AbstractBlock
+	root common.Hash
+}
+
+func (b fakeStateRootBlock) StateRoot() common.Hash {
+	return b.root
+}
+
 func (suite *chainWriterSuite) TestChainState_BuildRegisterProcessor(t *check.C) {
 	config := suite.chainState.ChainConfig
 	cv := suite.chainState.GetCurrVerifiers()
@@ -172,6 +182,20 @@ func (suite *chainWriterSuite) TestChainState_GetNumBySlot(t *check.C) {
 
 }
 
+func (suite *chainWriterSuite) TestChainState_GetBlockVerifiers(t *check.C) {
+	config := suite.chainState.ChainConfig
+	suite.InsertBlock(t, int(config.SlotMargin*config.SlotSize+1))
+
+	block := suite.chainState.GetBlockByNumber(1)
+	assert.Equal(t, suite.chainState.GetVerifiers(0), suite.chainState.GetBlockVerifiers(block))
+
+	// the verifiers are elected with the blocks below the block, not the current block
+	block = suite.chainState.CurrentBlock()
+	slot := suite.chainState.GetSlot(block)
+	assert.Equal(t, config.SlotMargin, *slot)
+	assert.Equal(t, suite.chainState.GetVerifiers(*slot), suite.chainState.GetBlockVerifiers(block))
+}
+
 func (suite *chainWriterSuite) TestChainState_CalVerifiers(t *check.C) {
 	// insert block
 	config := suite.chainState.ChainConfig
@@ -188,6 +212,16 @@ func (suite *chainWriterSuite) TestChainState_CalVerifiers(t *check.C) {
 	assert.Equal(t, ver[1].Address(), verifiers[0])
 	assert.Len(t, verifiers, config.VerifierNumber)
 
+	// the jailed verifier isn't elected by its stake, the angel nodes take the place
+	state, err := suite.chainState.StateAtByBlockNumber(block.Number())
+	assert.NoError(t, err)
+	assert.NoError(t, state.Jail(ver[1].Address(), block.Number()+1))
+	jailedRoot, err := state.Commit()
+	assert.NoError(t, err)
+	verifiers = suite.chainState.CalVerifiers(fakeStateRootBlock{AbstractBlock: block, root: jailedRoot})
+	assert.NotEqual(t, ver[1].Address(), verifiers[0])
+	assert.Len(t, verifiers, config.VerifierNumber)
+
 	var txs []*model.Transaction
 	for i := 0; i < config.VerifierNumber; i++ {
 		tx = createRegisterTX(0, economy_model.MiniPledgeValue, ver[i])
@@ -55,6 +55,7 @@ func (cw *BftChainWriter) SaveBlock() error {
 
 	c.Use(middleware.UpdateBlockVerifier(&c.BlockContext))
 	c.Use(middleware.InsertBlock(&c.BlockContext))
+	c.Use(middleware.PruneState(&c.BlockContext))
 
 	// after insert block, update verifier
 	c.Use(middleware.NextRoundVerifier(&c.BlockContext))
@@ -98,6 +99,7 @@ func (cw *BftChainWriterWithoutVotes) SaveBlock() error {
 
 	c.Use(middleware.UpdateBlockVerifier(&c.BlockContext))
 	c.Use(middleware.InsertBlock(&c.BlockContext))
+	c.Use(middleware.PruneState(&c.BlockContext))
 
 	// after insert block, update verifier
 	c.Use(middleware.NextRoundVerifier(&c.BlockContext))
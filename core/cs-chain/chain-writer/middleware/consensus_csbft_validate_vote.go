@@ -60,6 +60,40 @@ func ValidateVotesForBFT(c *BlockContext) Middleware {
 	}
 }
 
+// ValidSeenCommits checks the seen commits of the block are signed by the verifiers, the fast sync uses it for the pivot block
+func ValidSeenCommits(block model.AbstractBlock, votes []model.AbstractVerification, verifiers []common.Address) error {
+	if err := validVotesForBlock(votes, block, verifiers); err != nil {
+		return err
+	}
+	return validBlockHash(votes, block)
+}
+
+// ValidSyncedBlock checks the block saved by the fast sync without its state, the PoW, the tx and verification roots
+// and the seen commits of the block signed by the verifiers of its slot
+func ValidSyncedBlock(block model.AbstractBlock, votes []model.AbstractVerification, verifiers []common.Address) error {
+	if block.IsSpecial() {
+		if !model.CheckAddressIsVerifierBootNode(block.CoinBaseAddress()) {
+			return g_error.ErrSpecialInvalidCoinBase
+		}
+	} else if !block.RefreshHashCache().ValidHashForDifficulty(block.Difficulty()) {
+		return g_error.ErrWrongHashDiff
+	}
+
+	txRoot := model.DeriveSha(model.AbsTransactions(block.GetAbsTransactions()))
+	if !txRoot.IsEqual(block.TxRoot()) {
+		return errors.New(fmt.Sprintf("tx root not match, target: %v, root in block: %v", txRoot.Hex(), block.TxRoot().Hex()))
+	}
+
+	if block.Number() == 1 {
+		if !block.VerificationRoot().IsEqual(model.EmptyVerfRoot) || len(block.GetVerifications()) != 0 {
+			return g_error.ErrFirstBlockShouldNotHaveVerifications
+		}
+	} else if err := validVerificationRoot(block.GetVerifications(), block.VerificationRoot()); err != nil {
+		return err
+	}
+	return ValidSeenCommits(block, votes, verifiers)
+}
+
 func validateVotes(block model.AbstractBlock, chain ChainInterface) error {
 
 	// The first block has no votes
@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/g-error"
 	"github.com/dipperin/dipperin-core/core/model"
 )
 
@@ -93,3 +94,33 @@ func Test_validVotesForBlock(t *testing.T) {
 
 	assert.Error(t, validVotesForBlock([]model.AbstractVerification{v}, &fakeBlock{}, []common.Address{{}}))
 }
+
+func TestValidSeenCommits(t *testing.T) {
+	a := &Account{Pk: crypto.HexToECDSAErrPanic("fe10ee89565549d616d43c4e71b61d46a963fdb69489093a57cacf06836ecd91")}
+	block := &fakeBlock{num: 1, hash: common.Hash{0x12}}
+	v := a.getVoteMsg(1, 1, block.Hash(), model.VoteMessage)
+
+	assert.NoError(t, ValidSeenCommits(block, []model.AbstractVerification{v}, []common.Address{a.Address()}))
+	assert.Error(t, ValidSeenCommits(block, nil, []common.Address{a.Address()}))
+	assert.Error(t, ValidSeenCommits(block, []model.AbstractVerification{v}, []common.Address{common.HexToAddress("0x1234")}))
+	assert.Error(t, ValidSeenCommits(&fakeBlock{num: 1, hash: common.Hash{0x13}}, []model.AbstractVerification{v}, []common.Address{a.Address()}))
+}
+
+func TestValidSyncedBlock(t *testing.T) {
+	a := &Account{Pk: crypto.HexToECDSAErrPanic("fe10ee89565549d616d43c4e71b61d46a963fdb69489093a57cacf06836ecd91")}
+	verifiers := []common.Address{a.Address()}
+	txRoot := model.DeriveSha(model.AbsTransactions(nil))
+	vRoot := model.DeriveSha(model.Verifications(nil))
+	block := &fakeBlock{num: 2, hash: common.Hash{0x12}, txRoot: txRoot, vRoot: vRoot}
+	v := a.getVoteMsg(2, 1, block.Hash(), model.VoteMessage)
+	votes := []model.AbstractVerification{v}
+
+	assert.NoError(t, ValidSyncedBlock(block, votes, verifiers))
+	assert.Error(t, ValidSyncedBlock(block, nil, verifiers))
+
+	// the block hash doesn't meet the difficulty
+	assert.Equal(t, g_error.ErrWrongHashDiff, ValidSyncedBlock(&fakeBlock{num: 2, hash: common.Hash{0x12}, txRoot: txRoot, vRoot: vRoot, preHash: common.Hash{0xff}}, votes, verifiers))
+	assert.Error(t, ValidSyncedBlock(&fakeBlock{num: 2, hash: common.Hash{0x12}, vRoot: vRoot}, votes, verifiers))
+	assert.Error(t, ValidSyncedBlock(&fakeBlock{num: 2, hash: common.Hash{0x12}, txRoot: txRoot}, votes, verifiers))
+	assert.Equal(t, g_error.ErrSpecialInvalidCoinBase, ValidSyncedBlock(&fakeBlock{num: 2, hash: common.Hash{0x12}, isSpecial: true}, votes, verifiers))
+}
@@ -39,12 +39,16 @@ var txValidators = map[common.TxType]func(tx model.AbstractTransaction, chain Ch
 	common.TxType(common.AddressTypeNormal): func(tx model.AbstractTransaction, chain ChainInterface, blockHeight uint64) error {
 		return nil
 	},
+	common.TxType(common.AddressTypeCross):       validCrossTx,
 	common.TxType(common.AddressTypeStake):       validRegisterTx,
 	common.TxType(common.AddressTypeCancel):      validCancelTx,
 	common.TxType(common.AddressTypeUnStake):     validUnStakeTx,
 	common.TxType(common.AddressTypeEvidence):    validEvidenceTx,
 	common.TxType(common.AddressTypeERC20):       validContractTx,
 	common.TxType(common.AddressTypeEarlyReward): validEarlyTokenTx,
+	common.TxType(common.AddressTypeMultiSig):    validMultiSigTx,
+	common.TxType(common.AddressTypeDelegate):    validDelegationTx,
+	common.TxType(common.AddressTypeUnDelegate):  validDelegationTx,
 }
 
 //type TxContext struct {
@@ -172,6 +176,17 @@ func ValidTxSender(tx model.AbstractTransaction, chain ChainInterface, blockHeig
 	return nil
 }
 
+// the tx type added by a fork can't be packed before the activation block, the tx from the rpc is checked with the next block
+func ValidTxTypeActive(tx model.AbstractTransaction, chain ChainInterface, blockHeight uint64) error {
+	if blockHeight == 0 {
+		blockHeight = chain.CurrentBlock().Number() + 1
+	}
+	if !chain.GetChainConfig().IsTxTypeActive(tx.GetType(), blockHeight) {
+		return g_error.ErrTxTypeNotActive
+	}
+	return nil
+}
+
 // do checking for different types of transactions
 func ValidTxByType(tx model.AbstractTransaction, chain ChainInterface, blockHeight uint64) Middleware {
 	return func() error {
@@ -203,6 +218,10 @@ func validTx(tx model.AbstractTransaction, chain ChainInterface, blockHeight uin
 		return err
 	}
 
+	if err := ValidTxTypeActive(tx, chain, blockHeight); err != nil {
+		return err
+	}
+
 	validator := txValidators[tx.GetType()]
 	if validator == nil {
 		return errors.New(fmt.Sprintf("no validator for tx, type: %v", tx.GetType()))
@@ -267,7 +286,15 @@ func validCancelTx(tx model.AbstractTransaction, chain ChainInterface, blockHeig
 	return nil
 }
 
+// after the receipt fork the contract call of a tx in the block may fail, the error is recorded in its receipt
 func validContractTx(tx model.AbstractTransaction, chain ChainInterface, blockHeight uint64) error {
+	if blockHeight != 0 && chain.GetChainConfig().IsForkActive(chain_config.ForkReceipt, blockHeight) {
+		if contract.ParseExtraDataForContract(tx.ExtraData()) == nil {
+			return contract.CanNotParseContractErr
+		}
+		return nil
+	}
+
 	curState, err := chain.CurrentState()
 	if err != nil {
 		return err
@@ -276,6 +303,42 @@ func validContractTx(tx model.AbstractTransaction, chain ChainInterface, blockHe
 	return contract.NewProcessor(curState, blockHeight).Process(tx)
 }
 
+// valid hash time lock tx against the state before the block
+func validCrossTx(tx model.AbstractTransaction, chain ChainInterface, blockHeight uint64) error {
+	state, err := getPreStateForHeight(blockHeight, chain)
+	if err != nil {
+		return err
+	}
+	if state_processor.IsCrossChainTx(tx) {
+		return state.ValidCrossChainTx(tx)
+	}
+	if blockHeight == 0 {
+		blockHeight = chain.CurrentBlock().Number() + 1
+	}
+	return state.ValidHTLCTx(tx, blockHeight)
+}
+
+// valid multi signature tx against the state before the block
+func validMultiSigTx(tx model.AbstractTransaction, chain ChainInterface, blockHeight uint64) error {
+	state, err := getPreStateForHeight(blockHeight, chain)
+	if err != nil {
+		return err
+	}
+	return state.ValidMultiSigTx(tx)
+}
+
+// valid delegate and undelegate tx against the state before the block
+func validDelegationTx(tx model.AbstractTransaction, chain ChainInterface, blockHeight uint64) error {
+	state, err := getPreStateForHeight(blockHeight, chain)
+	if err != nil {
+		return err
+	}
+	if blockHeight == 0 {
+		blockHeight = chain.CurrentBlock().Number() + 1
+	}
+	return state.ValidDelegationTx(tx, blockHeight)
+}
+
 func validEarlyTokenTx(tx model.AbstractTransaction, chain ChainInterface, blockHeight uint64) error {
 	return nil
 }
@@ -447,5 +510,14 @@ func validTargetStake(tx model.AbstractTransaction, chain ChainInterface, blockH
 	if stake.Cmp(big.NewInt(0)) == 0 {
 		return errors.New("not enough stake")
 	}
-	return nil
+	// the double sign must be within the evidence window and not punished yet after the slashing fork
+	next := chain.CurrentBlock().Number() + 1
+	if !chain.GetChainConfig().IsForkActive(chain_config.ForkSlashing, next) {
+		return nil
+	}
+	proofData := model.Proofs{}
+	if err = rlp.DecodeBytes(tx.ExtraData(), &proofData); err != nil {
+		return err
+	}
+	return currentStake.CheckEvidence(target, proofData.DoubleSign(), next)
 }
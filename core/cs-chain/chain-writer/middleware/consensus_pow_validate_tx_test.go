@@ -20,11 +20,13 @@ import (
 	"crypto/ecdsa"
 	"errors"
 	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/g-error"
 	"github.com/dipperin/dipperin-core/core/bloom"
 	"github.com/dipperin/dipperin-core/core/chain"
 	"github.com/dipperin/dipperin-core/core/chain-config"
 	"github.com/dipperin/dipperin-core/core/chain/chaindb"
 	"github.com/dipperin/dipperin-core/core/chain/registerdb"
+	"github.com/dipperin/dipperin-core/core/contract"
 	"github.com/dipperin/dipperin-core/core/economy-model"
 	"github.com/dipperin/dipperin-core/third-party/crypto"
 	"github.com/dipperin/dipperin-core/third-party/crypto/cs-crypto"
@@ -165,6 +167,84 @@ func Test_validContractTx(t *testing.T) {
 	assert.Error(t, validContractTx(&fakeTx{}, &fakeChainInterface{}, 0))
 	s, _ := NewEmptyAccountDB()
 	assert.Error(t, validContractTx(&fakeTx{}, &fakeChainInterface{ state: s }, 0))
+
+	// the contract call of a block tx isn't run, it may fail
+	assert.Equal(t, contract.CanNotParseContractErr, validContractTx(&fakeTx{}, &fakeChainInterface{}, 1))
+	assert.NoError(t, validContractTx(&fakeTx{ extraData: []byte(`{"action":"Transfer","params":"[]"}`) }, &fakeChainInterface{}, 1))
+
+	// the contract call is run before the receipt fork
+	conf := *chain_config.GetChainConfig()
+	conf.Forks = map[string]uint64{chain_config.ForkReceipt: 10}
+	assert.Error(t, validContractTx(&fakeTx{ extraData: []byte(`{"action":"Transfer","params":"[]"}`) }, &fakeChainInterface{cf: &conf}, 9))
+}
+
+func Test_validCrossTx(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	sender := cs_crypto.GetNormalAddress(key.PublicKey)
+	receiver := common.HexToAddress("0x000062be10f46b5d01Ecd9b502c4bA3d6131f6fc2e41")
+	lock := cs_crypto.Keccak256Hash([]byte("hash key"))
+	tx := model.CreateRawLockTx(0, lock, big.NewInt(10), big.NewInt(100), big.NewInt(1), sender, receiver)
+	signedTx, err := tx.SignTx(key, model.NewMercurySigner(big.NewInt(1)))
+	assert.NoError(t, err)
+
+	assert.Error(t, validCrossTx(signedTx, &fakeChainInterface{}, 0))
+	s, _ := NewEmptyAccountDB()
+	assert.NoError(t, validCrossTx(signedTx, &fakeChainInterface{state: s, block: &fakeBlock{num: 1}}, 0))
+	assert.Equal(t, state_processor.HTLCTimeLockErr, validCrossTx(signedTx, &fakeChainInterface{state: s}, 10))
+	assert.Equal(t, state_processor.HTLCInvalidTxErr, validCrossTx(&fakeTx{}, &fakeChainInterface{state: s}, 1))
+
+	crossChainTx, err := model.NewCrossChainLockTransaction(0, big.NewInt(2), receiver, big.NewInt(0), big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, state_processor.CrossChainAmountZeroErr, validCrossTx(crossChainTx, &fakeChainInterface{state: s}, 1))
+}
+
+func Test_validMultiSigTx(t *testing.T) {
+	keySet := &model.MultiSigKeySet{Threshold: 1, Owners: []common.Address{common.HexToAddress("0x000062be10f46b5d01Ecd9b502c4bA3d6131f6fc2e41")}}
+	tx, err := model.NewMultiSigRegisterTransaction(0, keySet, big.NewInt(10), big.NewInt(1))
+	assert.NoError(t, err)
+
+	assert.Error(t, validMultiSigTx(tx, &fakeChainInterface{}, 0))
+	s, _ := NewEmptyAccountDB()
+	assert.NoError(t, validMultiSigTx(tx, &fakeChainInterface{state: s}, 1))
+
+	deposit := model.NewTransaction(0, keySet.Address(), big.NewInt(10), big.NewInt(1), nil)
+	assert.Equal(t, g_error.AccountNotExist, validMultiSigTx(deposit, &fakeChainInterface{state: s}, 1))
+}
+
+func Test_validDelegationTx(t *testing.T) {
+	delegator := NewAccount()
+	verifier := common.HexToAddress("0x000062be10f46b5d01Ecd9b502c4bA3d6131f6fc2e41")
+	tx, err := model.NewDelegateTransaction(0, verifier, big.NewInt(10), big.NewInt(1)).SignTx(delegator.Pk, model.NewMercurySigner(big.NewInt(1)))
+	assert.NoError(t, err)
+
+	assert.Error(t, validDelegationTx(tx, &fakeChainInterface{}, 1))
+	s, _ := NewEmptyAccountDB()
+	assert.Equal(t, state_processor.DelegateNotVerifierErr, validDelegationTx(tx, &fakeChainInterface{state: s}, 1))
+
+	assert.NoError(t, s.NewAccountState(verifier))
+	assert.NoError(t, s.AddStake(verifier, big.NewInt(10)))
+	assert.NoError(t, s.NewAccountState(delegator.Address()))
+	assert.NoError(t, s.AddBalance(delegator.Address(), big.NewInt(100)))
+	assert.NoError(t, validDelegationTx(tx, &fakeChainInterface{state: s}, 1))
+
+	unDelegateTx, err := model.NewUnDelegateTransaction(0, verifier, big.NewInt(1)).SignTx(delegator.Pk, model.NewMercurySigner(big.NewInt(1)))
+	assert.NoError(t, err)
+	assert.Equal(t, state_processor.DelegationNotExistErr, validDelegationTx(unDelegateTx, &fakeChainInterface{state: s, block: &fakeBlock{}}, 0))
+}
+
+func TestValidTxTypeActive(t *testing.T) {
+	conf := *chain_config.GetChainConfig()
+	conf.Forks = map[string]uint64{chain_config.ForkDelegation: 10}
+	delegator := NewAccount()
+	tx, err := model.NewDelegateTransaction(0, common.HexToAddress("0x1234"), big.NewInt(10), big.NewInt(1)).SignTx(delegator.Pk, model.NewMercurySigner(big.NewInt(1)))
+	assert.NoError(t, err)
+
+	assert.Equal(t, g_error.ErrTxTypeNotActive, ValidTxTypeActive(tx, &fakeChainInterface{cf: &conf}, 9))
+	assert.NoError(t, ValidTxTypeActive(tx, &fakeChainInterface{cf: &conf}, 10))
+
+	// the rpc tx is checked with the next block
+	assert.Equal(t, g_error.ErrTxTypeNotActive, ValidTxTypeActive(tx, &fakeChainInterface{cf: &conf, block: &fakeBlock{num: 8}}, 0))
+	assert.NoError(t, ValidTxTypeActive(tx, &fakeChainInterface{cf: &conf, block: &fakeBlock{num: 9}}, 0))
 }
 
 func Test_validEarlyTokenTx(t *testing.T) {
@@ -225,7 +305,23 @@ func Test_validTargetStake(t *testing.T) {
 	assert.Error(t, validTargetStake(passTx, passChain, 0))
 
 	assert.NoError(t, adb.AddStake(target, big.NewInt(10)))
+	assert.Error(t, validTargetStake(passTx, passChain, 0))
+
+	pb, err := rlp.EncodeToBytes(model.Proofs{VoteA: model.NewVoteMsg(1, 2, common.Hash{}, model.VoteMessage), VoteB: model.NewVoteMsg(1, 2, common.Hash{0x12}, model.VoteMessage)})
+	assert.NoError(t, err)
+	passTx.extraData = pb
+	assert.NoError(t, validTargetStake(passTx, passChain, 0))
+
+	// the jail doesn't block the evidence of another double sign
+	assert.NoError(t, adb.Jail(target, 2))
 	assert.NoError(t, validTargetStake(passTx, passChain, 0))
+
+	assert.NoError(t, adb.SetSlashedVotes(target, []model.SlashedVote{{Height: 1, Round: 2}}))
+	assert.Equal(t, state_processor.EvidenceProcessedErr, validTargetStake(passTx, passChain, 0))
+
+	conf := chain_config.GetChainConfig()
+	passChain.block = &fakeBlock{num: 1 + conf.SlashEvidenceSlot*conf.SlotSize}
+	assert.Equal(t, state_processor.EvidenceExpiredErr, validTargetStake(passTx, passChain, 0))
 }
 
 func Test_validUnStakeTime(t *testing.T) {
@@ -320,6 +416,7 @@ type fakeChainInterface struct {
 	slot uint64
 	verifiers []common.Address
 	cf *chain_config.ChainConfig
+	chainDB chaindb.Database
 }
 
 func (ci *fakeChainInterface) Genesis() model.AbstractBlock {
@@ -449,7 +546,7 @@ func (ci *fakeChainInterface) BuildRegisterProcessor(preRoot common.Hash) (*regi
 }
 
 func (ci *fakeChainInterface) GetStateStorage() state_processor.StateStorage {
-	panic("implement me")
+	return ci.storage
 }
 
 func (ci *fakeChainInterface) CurrentState() (*state_processor.AccountStateDB, error) {
@@ -486,6 +583,9 @@ func (ci *fakeChainInterface) GetEconomyModel() economy_model.EconomyModel {
 }
 
 func (ci *fakeChainInterface) GetChainDB() chaindb.Database {
+	if ci.chainDB != nil {
+		return ci.chainDB
+	}
 	return chaindb.NewChainDB(ethdb.NewMemDatabase(), model.MakeDefaultBlockDecoder())
 }
 
@@ -544,6 +644,10 @@ func (fe *fakeEconomyModel) GetOneBlockTotalDIPReward(blockNumber uint64) (*big.
 	panic("implement me")
 }
 
+func (fe *fakeEconomyModel) ShareRewardWithDelegators(blockNumber uint64) bool {
+	panic("implement me")
+}
+
 type fakeTx struct {
 	sender common.Address
 	fee *big.Int
@@ -613,6 +717,18 @@ func (ft *fakeTx) EstimateFee() *big.Int {
 	panic("implement me")
 }
 
+func (ft *fakeTx) HashLock() *common.Hash {
+	return nil
+}
+
+func (ft *fakeTx) TimeLock() *big.Int {
+	return big.NewInt(0)
+}
+
+func (ft *fakeTx) HashKey() []byte {
+	return nil
+}
+
 type fakeBlock struct {
 	txRoot common.Hash
 	isSpecial bool
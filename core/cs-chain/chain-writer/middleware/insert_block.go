@@ -42,6 +42,9 @@ func InsertBlock(c *BlockContext) Middleware{
 		if err := c.Chain.GetChainDB().InsertBlock(c.Block); err != nil {
 			return err
 		}
+		if len(c.Receipts) > 0 {
+			c.Chain.GetChainDB().SaveReceipts(c.Block.Hash(), c.Block.Number(), c.Receipts)
+		}
 		log.Info("insert block successful", "num", c.Block.Number())
 		//currentBlock := c.Chain.CurrentBlock()
 		//log.Info("the currentBlock number is~~~~~~~~~~~~~`:","number",currentBlock.Number())
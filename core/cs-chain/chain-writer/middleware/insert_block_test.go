@@ -17,7 +17,12 @@
 package middleware
 
 import (
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/core/chain/chaindb"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/stretchr/testify/assert"
+	"math/big"
 	"testing"
 )
 
@@ -38,3 +43,17 @@ func TestInsertBlock(t *testing.T) {
 		Chain: passChain,
 	})())
 }
+
+func TestInsertBlock_Receipts(t *testing.T) {
+	_, _, _, passChain := getTxTestEnv(t)
+	passChain.chainDB = chaindb.NewChainDB(ethdb.NewMemDatabase(), model.MakeDefaultBlockDecoder())
+
+	block := &fakeBlock{num: 1, hash: common.HexToHash("0x123")}
+	receipts := model.Receipts{{TxHash: common.HexToHash("0x1"), Status: model.ReceiptStatusSuccessful, Fee: big.NewInt(10)}}
+	assert.NoError(t, InsertBlock(&BlockContext{Block: block, Chain: passChain, Receipts: receipts})())
+
+	saved := passChain.chainDB.GetReceipts(block.Hash(), block.Number())
+	assert.Len(t, saved, 1)
+	assert.Equal(t, receipts[0].TxHash, saved[0].TxHash)
+	assert.Equal(t, receipts[0].Fee, saved[0].Fee)
+}
@@ -39,6 +39,8 @@ type BlockContext struct {
 	Block model.AbstractBlock
 	// chain
 	Chain ChainInterface
+	// receipts of the block txs, set after the state root is validated
+	Receipts model.Receipts
 }
 
 // basic middleware, can be comprised by other middleware
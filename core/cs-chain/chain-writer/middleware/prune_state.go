@@ -0,0 +1,33 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package middleware
+
+// PruneState garbage collects the state tries of the blocks out of the retained ones after the block is inserted.
+// The state of the first and the last block of a slot is kept on disk, the verifiers are elected with the last one
+// and their performance is counted from the first one. The node restarts from them after a crash
+func PruneState(c *BlockContext) Middleware {
+	return func() error {
+		persist := c.Block.Number() == 1 || c.Chain.IsChangePoint(c.Block, false)
+		if preBlock := c.Chain.GetBlockByNumber(c.Block.Number() - 1); preBlock != nil && c.Chain.IsChangePoint(preBlock, false) {
+			persist = true
+		}
+		if err := c.Chain.GetStateStorage().Prune(persist); err != nil {
+			return err
+		}
+		return c.Next()
+	}
+}
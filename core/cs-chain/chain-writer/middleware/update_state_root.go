@@ -78,6 +78,7 @@ func validStateRoot(c *BlockContext) (*chain.BlockProcessor, error) {
 		return nil, errors.New("state root not match")
 	}
 
+	c.Receipts = processor.Receipts()
 	return processor, nil
 }
 
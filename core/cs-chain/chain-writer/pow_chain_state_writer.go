@@ -42,6 +42,7 @@ func (cw *PowChainWriter) SaveBlock() error {
 	c.Use(middleware.ValidateBlockTxs(c))
 	c.Use(middleware.UpdateStateRoot(c))
 	c.Use(middleware.InsertBlock(c))
+	c.Use(middleware.PruneState(c))
 
 	//Call BlockProcessor.Process
 	return c.Process()
@@ -0,0 +1,190 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cs_chain
+
+import (
+	"errors"
+	"fmt"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/g-error"
+	"github.com/dipperin/dipperin-core/common/util"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/third-party/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"io"
+)
+
+var (
+	errExportRange      = errors.New("the first block to export is higher than the last one")
+	errBlockNotImported = errors.New("the imported block isn't the next block of the chain")
+)
+
+// the blocks are exported with the votes committing them
+type exportedBlock struct {
+	Block      model.AbstractBlock
+	SeenCommit []model.AbstractVerification
+}
+
+type exportedBlockRlp struct {
+	Block      *model.Block
+	SeenCommit []*model.VoteMsg
+}
+
+// StateSnapshotHeader is written before the trie nodes of the state snapshot
+type StateSnapshotHeader struct {
+	Number       uint64
+	BlockHash    common.Hash
+	StateRoot    common.Hash
+	RegisterRoot common.Hash
+}
+
+// ExportChain writes the blocks from first to last into w as a RLP stream
+func (cs *CsChainService) ExportChain(w io.Writer, first, last uint64) error {
+	if first > last {
+		return errExportRange
+	}
+
+	for number := first; number <= last; number++ {
+		block := cs.GetBlockByNumber(number)
+		if block == nil {
+			return g_error.ErrBlockNotFound
+		}
+		if err := rlp.Encode(w, &exportedBlock{Block: block, SeenCommit: cs.GetSeenCommit(number)}); err != nil {
+			return err
+		}
+	}
+	log.Info("exported blocks", "first", first, "last", last)
+	return nil
+}
+
+/*
+ImportChain inserts the blocks of the RLP stream through the chain writer as the blocks received from the network,
+the blocks already in the chain are skipped but must be the same. Returns the number of inserted blocks
+*/
+func (cs *CsChainService) ImportChain(r io.Reader) (int, error) {
+	stream := rlp.NewStream(r, 0)
+	imported := 0
+	for {
+		var b exportedBlockRlp
+		if err := stream.Decode(&b); err == io.EOF {
+			break
+		} else if err != nil {
+			return imported, err
+		}
+
+		if local := cs.GetBlockByNumber(b.Block.Number()); local != nil {
+			if !local.Hash().IsEqual(b.Block.Hash()) {
+				return imported, fmt.Errorf("block %v in the file is different from the local one", b.Block.Number())
+			}
+			continue
+		}
+
+		seenCommits := make([]model.AbstractVerification, len(b.SeenCommit))
+		util.InterfaceSliceCopy(seenCommits, b.SeenCommit)
+		if err := cs.SaveBlock(b.Block, seenCommits); err != nil {
+			return imported, err
+		}
+		// the future block is kept without inserting
+		if cs.CurrentBlock().Number() != b.Block.Number() {
+			return imported, errBlockNotImported
+		}
+		imported++
+	}
+
+	log.Info("imported blocks", "count", imported, "current block", cs.CurrentBlock().Number())
+	return imported, nil
+}
+
+/*
+ExportStateSnapshot writes the state of the block into w, a StateSnapshotHeader followed by the nodes
+of the account trie, the register trie and the contract tries.
+The state of the block must not be pruned
+*/
+func (cs *CsChainService) ExportStateSnapshot(w io.Writer, number uint64) error {
+	block := cs.GetBlockByNumber(number)
+	if block == nil {
+		return g_error.ErrBlockNotFound
+	}
+
+	header := &StateSnapshotHeader{
+		Number:       number,
+		BlockHash:    block.Hash(),
+		StateRoot:    block.StateRoot(),
+		RegisterRoot: block.GetRegisterRoot(),
+	}
+	if err := rlp.Encode(w, header); err != nil {
+		return err
+	}
+
+	written := make(map[common.Hash]bool)
+	candidates, err := cs.exportTrie(w, header.StateRoot, written)
+	if err != nil {
+		return err
+	}
+	if _, err = cs.exportTrie(w, header.RegisterRoot, written); err != nil {
+		return err
+	}
+
+	// the 32 bytes leaves of the account trie may be the roots of the contract tries
+	storage := cs.GetStateStorage()
+	for _, root := range candidates {
+		if _, err := storage.TrieDB().Node(root); err != nil {
+			continue
+		}
+		if _, err = cs.exportTrie(w, root, written); err != nil {
+			return err
+		}
+	}
+
+	log.Info("exported state snapshot", "number", number, "nodes", len(written))
+	return nil
+}
+
+// writes the nodes of the trie not written yet, returns the 32 bytes leaves
+func (cs *CsChainService) exportTrie(w io.Writer, root common.Hash, written map[common.Hash]bool) ([]common.Hash, error) {
+	storage := cs.GetStateStorage()
+	tr, err := storage.OpenStorageTrie(common.Hash{}, root)
+	if err != nil {
+		return nil, err
+	}
+
+	var leaves []common.Hash
+	it := tr.NodeIterator(nil)
+	for it.Next(true) {
+		if it.Leaf() {
+			if len(it.LeafBlob()) == common.HashLength {
+				leaves = append(leaves, common.BytesToHash(it.LeafBlob()))
+			}
+			continue
+		}
+
+		// the embedded nodes are written with their parents
+		hash := it.Hash()
+		if hash == (common.Hash{}) || written[hash] {
+			continue
+		}
+		node, err := storage.TrieDB().Node(hash)
+		if err != nil {
+			return nil, err
+		}
+		if err = rlp.Encode(w, node); err != nil {
+			return nil, err
+		}
+		written[hash] = true
+	}
+	return leaves, it.Error()
+}
@@ -0,0 +1,103 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cs_chain
+
+import (
+	"bytes"
+	"github.com/dipperin/dipperin-core/common/g-error"
+	"github.com/dipperin/dipperin-core/core/chain/cachedb"
+	"github.com/dipperin/dipperin-core/core/chain/state-processor"
+	"github.com/dipperin/dipperin-core/third-party/crypto/cs-crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func TestCsChainService_ExportChain(t *testing.T) {
+	cachedb.SetCacheDataDecoder(&cachedb.BFTCacheDataDecoder{})
+	ccs, gEnv, _, bB := getTestChainEnv(t, cachedb.NewCacheDB(ethdb.NewMemDatabase()), &fakeTxPool{})
+	for i := 0; i < 2; i++ {
+		block := bB.Build()
+		v := gEnv.VoteBlock(3, 1, block)
+		assert.NoError(t, ccs.SaveBlock(block, v))
+		bB.PreBlock = block
+		bB.Vers = v
+	}
+
+	var buf bytes.Buffer
+	assert.Equal(t, errExportRange, ccs.ExportChain(&buf, 2, 1))
+	assert.Equal(t, g_error.ErrBlockNotFound, ccs.ExportChain(&buf, 0, 3))
+	buf.Reset()
+	assert.NoError(t, ccs.ExportChain(&buf, 0, 2))
+	exported := buf.Bytes()
+
+	// import into a new chain with the same genesis
+	ccs2, _, _, _ := getTestChainEnv(t, cachedb.NewCacheDB(ethdb.NewMemDatabase()), &fakeTxPool{})
+	imported, err := ccs2.ImportChain(bytes.NewReader(exported))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, imported)
+	assert.Equal(t, ccs.CurrentBlock().Hash(), ccs2.CurrentBlock().Hash())
+	assert.Len(t, ccs2.GetSeenCommit(2), 3)
+
+	// the known blocks are skipped
+	imported, err = ccs2.ImportChain(bytes.NewReader(exported))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, imported)
+
+	_, err = ccs2.ImportChain(bytes.NewReader(exported[:len(exported)-1]))
+	assert.Error(t, err)
+}
+
+func TestCsChainService_ExportStateSnapshot(t *testing.T) {
+	ccs, gEnv, _, bB := getTestChainEnv(t, &fakeCacheDB{}, &fakeTxPool{})
+	block := bB.Build()
+	assert.NoError(t, ccs.SaveBlock(block, gEnv.VoteBlock(3, 1, block)))
+
+	var buf bytes.Buffer
+	assert.Equal(t, g_error.ErrBlockNotFound, ccs.ExportStateSnapshot(&buf, 2))
+	assert.NoError(t, ccs.ExportStateSnapshot(&buf, 1))
+
+	// load the snapshot nodes into a new db
+	stream := rlp.NewStream(&buf, 0)
+	var header StateSnapshotHeader
+	assert.NoError(t, stream.Decode(&header))
+	assert.Equal(t, block.Hash(), header.BlockHash)
+	assert.Equal(t, block.StateRoot(), header.StateRoot)
+
+	db := ethdb.NewMemDatabase()
+	for {
+		var node []byte
+		if err := stream.Decode(&node); err == io.EOF {
+			break
+		} else {
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, db.Put(cs_crypto.Keccak256Hash(node).Bytes(), node))
+	}
+
+	storage := state_processor.NewStateStorageWithCache(db)
+	state, err := state_processor.NewAccountStateDB(header.StateRoot, storage)
+	assert.NoError(t, err)
+	verifier := gEnv.DefaultVerifiers()[0].Address()
+	balance, err := state.GetBalance(verifier)
+	assert.NoError(t, err)
+	assert.Equal(t, ccs.CurrentBalance(verifier), balance)
+	_, err = storage.OpenTrie(header.RegisterRoot)
+	assert.NoError(t, err)
+}
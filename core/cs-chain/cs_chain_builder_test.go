@@ -29,6 +29,7 @@ import (
 	"github.com/dipperin/dipperin-core/tests"
 	"github.com/dipperin/dipperin-core/core/cs-chain"
 	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/g-event"
 	"github.com/dipperin/dipperin-core/core/cs-chain/chain-writer"
 	"github.com/dipperin/dipperin-core/core/cs-chain/chain-state"
 	"github.com/dipperin/dipperin-core/third-party/crypto"
@@ -269,15 +270,34 @@ func TestCacheChainState_Rollback(t *testing.T) {
 	assert.Equal(t, nBlock.Number(), uint64(4))
 	assert.Equal(t, nBlock.IsSpecial(), false)
 
-	// reverse chain
+	rollbackCh := make(chan model.Block, 3)
+	sub := g_event.Subscribe(g_event.ChainRollbackEvent, rollbackCh)
+	defer sub.Unsubscribe()
+
+	// reverse chain, the removed block is notified after the save
 	seenCommit := CreateVerBootVote(specialBlock)
 	err := chain.SaveBftBlock(specialBlock, []model.AbstractVerification{seenCommit})
 	assert.NoError(t, err)
+	assert.Len(t, rollbackCh, 0)
+	chain.SendRollbackEvents()
+	removed := <-rollbackCh
+	assert.Equal(t, nBlock.Hash(), removed.Hash())
 	assert.Equal(t, chain.CurrentBlock().Hash(), specialBlock.Hash())
 	assert.Equal(t, chain.CurrentBlock().IsSpecial(), true)
 	assert.Equal(t, chain.GetBlockByNumber(4).Hash(), specialBlock.Hash())
 	assert.Equal(t, chain.GetBlockByNumber(4).IsSpecial(), true)
 	assert.Equal(t, chain.HasBlock(nBlock.Hash(), uint64(4)), true)
+
+	// all the blocks above the target are notified from the highest one
+	assert.NoError(t, chain.Rollback(1))
+	chain.SendRollbackEvents()
+	for number := uint64(4); number > 1; number-- {
+		removed = <-rollbackCh
+		assert.Equal(t, number, removed.Number())
+	}
+	assert.Equal(t, uint64(1), chain.CurrentBlock().Number())
+	chain.SendRollbackEvents()
+	assert.Len(t, rollbackCh, 0)
 }
 
 func TestCacheChainState_GetSlot_GetLastChangePoint(t *testing.T) {
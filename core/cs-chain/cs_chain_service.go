@@ -30,6 +30,7 @@ import (
 	"github.com/dipperin/dipperin-core/core/chain/registerdb"
 	"github.com/dipperin/dipperin-core/core/chain/state-processor"
 	"github.com/dipperin/dipperin-core/core/cs-chain/chain-state"
+	"github.com/dipperin/dipperin-core/core/cs-chain/chain-writer/middleware"
 	"github.com/dipperin/dipperin-core/core/model"
 	"github.com/dipperin/dipperin-core/third-party/log"
 	"github.com/dipperin/dipperin-core/third-party/log/pbft_log"
@@ -194,6 +195,12 @@ func (cs *CsChainService) SaveBlock(block model.AbstractBlock, seenCommits []mod
 	cs.wg.Add(1)
 	defer cs.wg.Done()
 
+	err := cs.saveBlock(block, seenCommits)
+	cs.SendRollbackEvents()
+	return err
+}
+
+func (cs *CsChainService) saveBlock(block model.AbstractBlock, seenCommits []model.AbstractVerification) error {
 	cs.saveBlockLock.Lock()
 	defer cs.saveBlockLock.Unlock()
 
@@ -264,6 +271,66 @@ func (cs *CsChainService) saveBftBlock(block model.AbstractBlock, seenCommits []
 	}
 }
 
+// SaveSyncedBlock saves the block downloaded by the fast sync without processing it, the current block isn't changed.
+// The block must be committed by the verifiers of its slot, which are elected with the register and state tries
+// synced for the blocks saved before it
+func (cs *CsChainService) SaveSyncedBlock(block model.AbstractBlock, seenCommits []model.AbstractVerification) error {
+	cs.saveBlockLock.Lock()
+	defer cs.saveBlockLock.Unlock()
+
+	preBlock := cs.GetBlockByNumber(block.Number() - 1)
+	if preBlock == nil {
+		return g_error.ErrPreBlockIsNil
+	}
+	if !preBlock.Hash().IsEqual(block.PreHash()) {
+		return g_error.ErrPreBlockHashNotMatch
+	}
+	verifiers := cs.GetBlockVerifiers(block)
+	if len(verifiers) == 0 {
+		return errors.New("can't get the verifiers of the synced block")
+	}
+	if err := middleware.ValidSyncedBlock(block, seenCommits, verifiers); err != nil {
+		return err
+	}
+
+	chainDB := cs.GetChainDB()
+	chainDB.SaveBlock(block)
+	chainDB.SaveTxLookupEntries(block)
+	if chainDB.AddressIndexEnabled() {
+		chainDB.SaveAddressTxEntries(block)
+	}
+	chainDB.SaveBlockHash(block.Hash(), block.Number())
+	return cs.CacheDB.SaveSeenCommits(block.Number(), common.Hash{}, seenCommits)
+}
+
+// SetSyncedHead makes the pivot block of the fast sync the current block after its state is downloaded,
+// the pivot must be committed by the verifiers of its slot
+func (cs *CsChainService) SetSyncedHead(block model.AbstractBlock, seenCommits []model.AbstractVerification) error {
+	cs.saveBlockLock.Lock()
+	defer cs.saveBlockLock.Unlock()
+
+	if err := middleware.ValidSeenCommits(block, seenCommits, cs.GetBlockVerifiers(block)); err != nil {
+		return err
+	}
+	if _, err := cs.StateAtByStateRoot(block.StateRoot()); err != nil {
+		return err
+	}
+
+	oldHead := cs.CurrentBlock()
+	cs.setHead(block)
+	cs.TxPool.Reset(oldHead.Header().(*model.Header), block.Header().(*model.Header))
+	g_metrics.Set(g_metrics.CurChainHeight, "", float64(block.Number()))
+	pbft_log.Info("fast sync set pivot block as current block", "num", block.Number(), "hash", block.Hash().Hex())
+	return nil
+}
+
+func (cs *CsChainService) setHead(block model.AbstractBlock) {
+	cs.GetChainDB().SaveHeadBlockHash(block.Hash())
+	cs.GetChainDB().SaveHeadHeaderHash(block.Header().Hash())
+	cs.currentBlock.Store(block)
+	cs.currentHeader.Store(block.Header())
+}
+
 type futureBlock struct {
 	block       model.AbstractBlock
 	seenCommits []model.AbstractVerification
@@ -293,6 +360,10 @@ func (cs *CsChainService) checkGenesis() {
 	if _, _, err = chain.SetupGenesisBlock(defaultGenesis); err != nil {
 		panic("setup genesis block failed: " + err.Error())
 	}
+	// the genesis state must be on disk in the pruning mode
+	if err = cs.ChainState.StateStorage.Flush(); err != nil {
+		panic("flush genesis state failed: " + err.Error())
+	}
 }
 
 func (cs *CsChainService) InitService() error {
@@ -319,6 +390,13 @@ func (cs *CsChainService) initService() error {
 	headBlockHash := cs.CacheChainState.ChainState.ChainDB.GetHeadBlockHash()
 	currentBlock := cs.CacheChainState.GetBlockByHash(headBlockHash)
 
+	// the pruning mode keeps the state of the last blocks in memory, restart from the last block with state after a crash
+	if stateBlock := cs.lastBlockWithState(currentBlock); stateBlock.Number() != currentBlock.Number() {
+		log.Warn("the state of the head block is lost, restart from the last block with state", "head", currentBlock.Number(), "num", stateBlock.Number())
+		currentBlock = stateBlock
+		cs.setHead(currentBlock)
+	}
+
 	cs.CacheChainState.currentBlock.Store(currentBlock)
 	currentHeader := currentBlock.Header()
 	cs.CacheChainState.currentHeader.Store(currentHeader)
@@ -347,6 +425,17 @@ func (cs *CsChainService) initService() error {
 	return nil
 }
 
+// find the last block whose state is on disk from the block
+func (cs *CsChainService) lastBlockWithState(block model.AbstractBlock) model.AbstractBlock {
+	for block.Number() > 0 {
+		if _, err := cs.CacheChainState.StateAtByStateRoot(block.StateRoot()); err == nil {
+			return block
+		}
+		block = cs.CacheChainState.GetBlockByNumber(block.Number() - 1)
+	}
+	return block
+}
+
 func (cs *CsChainService) handleFutureBlockTask() {
 	// 5s update chain future block
 	tickHandler := func() { cs.handleFutureBlock() }
@@ -22,6 +22,7 @@ import (
 	"github.com/dipperin/dipperin-core/core/chain/chaindb"
 	"github.com/dipperin/dipperin-core/core/economy-model"
 	"github.com/dipperin/dipperin-core/tests"
+	"github.com/dipperin/dipperin-core/tests/factory"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/golang/mock/gomock"
 	"math/big"
@@ -30,6 +31,7 @@ import (
 
 	"github.com/dipperin/dipperin-core/cmd/utils"
 	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/g-error"
 	"github.com/dipperin/dipperin-core/core/chain-config"
 	"github.com/dipperin/dipperin-core/core/cs-chain/chain-state"
 	"github.com/dipperin/dipperin-core/core/cs-chain/chain-writer"
@@ -179,6 +181,29 @@ func TestCsChainService_SaveBlock(t *testing.T) {
 	assert.Error(t, ccs.checkBftBlock(block, nil))
 }
 
+func TestCsChainService_SaveSyncedBlock(t *testing.T) {
+	ccs, gEnv, _, bB := getTestChainEnv(t, &fakeCacheDB{}, &fakeTxPool{})
+	block := bB.Build()
+	v := gEnv.VoteBlock(3, 1, block)
+
+	// the synced block must be committed by the verifiers of its slot
+	assert.Error(t, ccs.SaveSyncedBlock(block, gEnv.VoteBlock(1, 1, block)))
+
+	// the synced block doesn't change the current block
+	assert.NoError(t, ccs.SaveSyncedBlock(block, v))
+	assert.Equal(t, uint64(0), ccs.CurrentBlock().Number())
+	assert.Equal(t, block.Hash(), ccs.GetBlockByNumber(1).Hash())
+
+	assert.Equal(t, g_error.ErrPreBlockIsNil, ccs.SaveSyncedBlock(factory.CreateBlock(3), nil))
+	assert.Equal(t, g_error.ErrPreBlockHashNotMatch, ccs.SaveSyncedBlock(factory.CreateBlockByPH(2, common.HexToHash("0x123")), nil))
+
+	// the pivot must be committed by the verifiers
+	assert.Error(t, ccs.SetSyncedHead(block, gEnv.VoteBlock(1, 1, block)))
+	assert.Equal(t, uint64(0), ccs.CurrentBlock().Number())
+	assert.NoError(t, ccs.SetSyncedHead(block, v))
+	assert.Equal(t, block.Hash(), ccs.CurrentBlock().Hash())
+}
+
 func TestCsChainService_checkGenesis(t *testing.T) {
 	ccs := &CsChainService{CacheChainState: &CacheChainState{ChainState: &chain_state.ChainState{}}}
 	assert.Panics(t, func() {
@@ -218,6 +243,19 @@ func TestCsChainService_initService(t *testing.T) {
 	assert.NoError(t, ccs.InitService())
 }
 
+func TestCsChainService_lastBlockWithState(t *testing.T) {
+	ccs, gEnv, _, bB := getTestChainEnv(t, &fakeCacheDB{}, &fakeTxPool{})
+	assert.Equal(t, uint64(0), ccs.lastBlockWithState(ccs.CurrentBlock()).Number())
+
+	// the state of the block saved without processing isn't on disk
+	block := bB.Build()
+	block.SetStateRoot(common.HexToHash("0x123"))
+	model.CalNonce(block.(*model.Block))
+	block.RefreshHashCache()
+	assert.NoError(t, ccs.SaveSyncedBlock(block, gEnv.VoteBlock(3, 1, block)))
+	assert.Equal(t, uint64(0), ccs.lastBlockWithState(block).Number())
+}
+
 func TestCsChainService_handleFutureBlock(t *testing.T) {
 	cMock := &fakeCacheDB{}
 	pMock := &fakeTxPool{}
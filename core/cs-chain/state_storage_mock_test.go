@@ -52,6 +52,20 @@ func (m *MockStateStorage) EXPECT() *MockStateStorageMockRecorder {
 	return m.recorder
 }
 
+// CommitRoot mocks base method
+func (m *MockStateStorage) CommitRoot(arg0 common.Hash) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitRoot", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CommitRoot indicates an expected call of CommitRoot
+func (mr *MockStateStorageMockRecorder) CommitRoot(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitRoot", reflect.TypeOf((*MockStateStorage)(nil).CommitRoot), arg0)
+}
+
 // CopyTrie mocks base method
 func (m *MockStateStorage) CopyTrie(arg0 state_processor.StateTrie) state_processor.StateTrie {
 	m.ctrl.T.Helper()
@@ -80,6 +94,20 @@ func (mr *MockStateStorageMockRecorder) DiskDB() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiskDB", reflect.TypeOf((*MockStateStorage)(nil).DiskDB))
 }
 
+// Flush mocks base method
+func (m *MockStateStorage) Flush() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Flush")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Flush indicates an expected call of Flush
+func (mr *MockStateStorageMockRecorder) Flush() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockStateStorage)(nil).Flush))
+}
+
 // OpenStorageTrie mocks base method
 func (m *MockStateStorage) OpenStorageTrie(arg0, arg1 common.Hash) (state_processor.StateTrie, error) {
 	m.ctrl.T.Helper()
@@ -110,6 +138,20 @@ func (mr *MockStateStorageMockRecorder) OpenTrie(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenTrie", reflect.TypeOf((*MockStateStorage)(nil).OpenTrie), arg0)
 }
 
+// Prune mocks base method
+func (m *MockStateStorage) Prune(arg0 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Prune", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Prune indicates an expected call of Prune
+func (mr *MockStateStorageMockRecorder) Prune(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prune", reflect.TypeOf((*MockStateStorage)(nil).Prune), arg0)
+}
+
 // TrieDB mocks base method
 func (m *MockStateStorage) TrieDB() *trie.Database {
 	m.ctrl.T.Helper()
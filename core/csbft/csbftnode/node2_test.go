@@ -375,7 +375,7 @@ func NewTestNode() *CsBft{
 	fs := newFackSigner(sks[1])
 	fcn := &FC{}
 	fetcher := components.NewFetcher(fcn)
-	config := &state_machine.BftConfig{fc,fetcher,fs,&FackMsgSender{}, &FakeValidtor{}}
+	config := &state_machine.BftConfig{fc,fetcher,fs,&FackMsgSender{}, &FakeValidtor{}, nil}
 	csbft := NewCsBft(config)
 	fc.SetNewHeightNotifier(csbft.OnEnterNewHeight)
 	csbft.SetFetcher(fetcher)
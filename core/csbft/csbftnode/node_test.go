@@ -71,7 +71,7 @@ func TestCsBft_AddPeer2(t *testing.T) {
 	sks, _ := CreateKey()
 	fs := newFackSigner(sks[1])
 	fetcher := components.NewFetcher(nil)
-	config := &state_machine.BftConfig{fc,fetcher,fs,&FackMsgSender{}, &FakeValidtor{}}
+	config := &state_machine.BftConfig{fc,fetcher,fs,&FackMsgSender{}, &FakeValidtor{}, nil}
 
 	node1 := NewCsBft(config)
 	node1.SetFetcher(fetcher)
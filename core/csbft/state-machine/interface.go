@@ -46,6 +46,11 @@ type Validator interface {
 	FullValid(block model.AbstractBlock) error
 }
 
+// submit the evidence of two conflicting votes signed by the same verifier
+type EvidenceReporter interface {
+	ReportDoubleSign(voteA, voteB *model.VoteMsg)
+}
+
 type Fetcher interface {
 	FetchBlock(from common.Address, blockHash common.Hash) model.AbstractBlock
 }
\ No newline at end of file
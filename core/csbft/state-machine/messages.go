@@ -214,6 +214,8 @@ type VoteSet struct {
 	Votes      map[uint64]map[common.Address]*model.VoteMsg
 	blockVote  map[uint64]map[common.Hash]int
 	verifiers  []common.Address
+	// the verifiers found double sign at this height
+	doubleSigned map[common.Address]bool
 	lock       sync.Mutex
 }
 
@@ -223,6 +225,7 @@ func NewVoteSet(height uint64, vers []common.Address) *VoteSet {
 		Votes:      make(map[uint64]map[common.Address]*model.VoteMsg),
 		blockVote:  make(map[uint64]map[common.Hash]int),
 		verifiers:  vers,
+		doubleSigned: make(map[common.Address]bool),
 	}
 }
 
@@ -298,6 +301,27 @@ func (vs *VoteSet) validVote(v *model.VoteMsg) error {
 	return nil
 }
 
+// get the recorded vote conflicting with v, which is signed by the same verifier for another block at the same round.
+// only the first conflict of a verifier is returned at a height
+func (vs *VoteSet) ConflictVote(v *model.VoteMsg) *model.VoteMsg {
+	vs.lock.Lock()
+	defer vs.lock.Unlock()
+
+	if v.Height != vs.Height || v.Witness == nil || vs.doubleSigned[v.Witness.Address] {
+		return nil
+	}
+	recorded := vs.Votes[v.Round][v.Witness.Address]
+	if recorded == nil || recorded.VoteType != v.VoteType || recorded.BlockID.IsEqual(v.BlockID) {
+		return nil
+	}
+	if err := v.Witness.Valid(v.Hash().Bytes()); err != nil {
+		return nil
+	}
+
+	vs.doubleSigned[v.Witness.Address] = true
+	return recorded
+}
+
 // check is current verifier
 func (vs *VoteSet) isCurrentVerifier(vAddr common.Address) bool {
 	for _, curV := range vs.verifiers {
@@ -1,6 +1,7 @@
 package state_machine
 
 import (
+	"github.com/dipperin/dipperin-core/common"
 	"testing"
 	"github.com/stretchr/testify/assert"
 )
@@ -16,4 +17,30 @@ func TestVoteSet_AddVote(t *testing.T) {
 	err := vs.AddVote(vote)
 
 	assert.Error(t,err,"vote height not match")
-}
\ No newline at end of file
+}
+func TestVoteSet_ConflictVote(t *testing.T) {
+	vs := MakeVoteSet(1)
+	blockA := &FakeBlock{1, common.HexToHash("0x1"), nil}
+	blockB := &FakeBlock{1, common.HexToHash("0x2"), nil}
+
+	vote := MakeNewVote(1, 0, blockA, 0)
+	assert.Nil(t, vs.ConflictVote(vote))
+	assert.NoError(t, vs.AddVote(vote))
+
+	// same block, other round, other height or other type
+	assert.Nil(t, vs.ConflictVote(MakeNewVote(1, 0, blockA, 0)))
+	assert.Nil(t, vs.ConflictVote(MakeNewVote(1, 1, blockB, 0)))
+	assert.Nil(t, vs.ConflictVote(MakeNewVote(2, 0, blockB, 0)))
+	assert.Nil(t, vs.ConflictVote(MakeNewProVote(1, 0, blockB, 0)))
+
+	// forged witness
+	forged := MakeNewVote(1, 0, blockB, 1)
+	forged.Witness.Address = vote.Witness.Address
+	assert.Nil(t, vs.ConflictVote(forged))
+
+	conflict := MakeNewVote(1, 0, blockB, 0)
+	assert.Equal(t, vote, vs.ConflictVote(conflict))
+	// only report once at a height
+	assert.Nil(t, vs.ConflictVote(conflict))
+	assert.Error(t, vs.AddVote(conflict))
+}
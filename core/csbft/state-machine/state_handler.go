@@ -57,6 +57,8 @@ type BftConfig struct {
 	Signer      MsgSigner
 	Sender      MsgSender
 	Validator   Validator
+	// optional, report the double sign found in the received votes
+	EvidenceReporter EvidenceReporter
 }
 
 type ReqRoundMsg struct {
@@ -243,6 +245,7 @@ func (h *StateHandler) OnNewProposal(proposal *model2.Proposal) {
 
 func (h *StateHandler) OnPreVote(pv *model.VoteMsg) {
 	pbft_log.Info("[StateHandler-OnPreVote]")
+	h.checkDoubleSign(h.bs.PreVotes, pv)
 	preStep := h.bs.Step
 	h.bs.OnPreVote(pv)
 	curStep := h.bs.Step
@@ -257,6 +260,7 @@ func (h *StateHandler) OnPreVote(pv *model.VoteMsg) {
 
 func (h *StateHandler) OnVote(v *model.VoteMsg) {
 	pbft_log.Debug("[StateHandler-OnVote]: handle new vote")
+	h.checkDoubleSign(h.bs.Votes, v)
 	blockId, commits := h.bs.OnVote(v)
 	if commits != nil {
 		pbft_log.Info("the commit 0 is:","round",commits[0].GetRound(),"height",commits[0].GetHeight(),"blockId",commits[0].GetBlockId().Hex(),"address",commits[0].GetAddress().Hex())
@@ -304,6 +308,22 @@ func (h *StateHandler) OnTimeout(toutInfo components.TimeoutInfo) {
 	}
 }
 
+// check the vote conflicts with the recorded one of the same verifier, the evidence is submitted by the reporter
+func (h *StateHandler) checkDoubleSign(votes *VoteSet, v *model.VoteMsg) {
+	if votes == nil {
+		return
+	}
+	conflict := votes.ConflictVote(v)
+	if conflict == nil {
+		return
+	}
+
+	pbft_log.Warn("[StateHandler] found double sign", "verifier", v.GetAddress().Hex(), "height", v.Height, "round", v.Round, "blockA", conflict.BlockID.Hex(), "blockB", v.BlockID.Hex())
+	if h.EvidenceReporter != nil {
+		go h.EvidenceReporter.ReportDoubleSign(conflict, v)
+	}
+}
+
 // broadcast new round msg
 func (h *StateHandler) broadcastNewRoundMsg() {
 	msg := &model2.NewRoundMsg{
@@ -414,3 +414,39 @@ func TestStateHandler_OnNewRound(t *testing.T) {
 	assert.Equal(t,uint64(2),sh0.bs.Round)
 }
 
+
+type fakeEvidenceReporter struct {
+	evidence chan [2]*model.VoteMsg
+}
+
+func (r *fakeEvidenceReporter) ReportDoubleSign(voteA, voteB *model.VoteMsg) {
+	r.evidence <- [2]*model.VoteMsg{voteA, voteB}
+}
+
+func TestStateHandler_checkDoubleSign(t *testing.T) {
+	reporter := &fakeEvidenceReporter{evidence: make(chan [2]*model.VoteMsg, 1)}
+	h := &StateHandler{BftConfig: &BftConfig{}}
+	blockA := &FakeBlock{1, common.HexToHash("0x1"), nil}
+	blockB := &FakeBlock{1, common.HexToHash("0x2"), nil}
+
+	// no vote set or no reporter
+	h.checkDoubleSign(nil, MakeNewProVote(1, 0, blockA, 0))
+	vs := MakeVoteSet(1)
+	pv := MakeNewProVote(1, 0, blockA, 0)
+	assert.NoError(t, vs.AddVote(pv))
+	h.checkDoubleSign(vs, MakeNewProVote(1, 0, blockB, 0))
+
+	h.EvidenceReporter = reporter
+	vs = MakeVoteSet(1)
+	assert.NoError(t, vs.AddVote(pv))
+	h.checkDoubleSign(vs, MakeNewProVote(1, 0, blockA, 0))
+	conflict := MakeNewProVote(1, 0, blockB, 0)
+	h.checkDoubleSign(vs, conflict)
+	select {
+	case evidence := <-reporter.evidence:
+		assert.Equal(t, pv, evidence[0])
+		assert.Equal(t, conflict, evidence[1])
+	case <-time.After(time.Second):
+		t.Fatal("the double sign isn't reported")
+	}
+}
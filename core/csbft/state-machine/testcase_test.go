@@ -67,7 +67,7 @@ var TestConfig = Config{
 func NewFakeStateHandle(id uint64) *StateHandler {
 	fc := NewFakeFullChain()
 	sks, _ := CreateKey()
-	config := &BftConfig{fc,&FakeFetcher{},newFackSigner(sks[id]),&FackMsgSender{}, &FakeValidtor{}}
+	config := &BftConfig{fc,&FakeFetcher{},newFackSigner(sks[id]),&FackMsgSender{}, &FakeValidtor{}, nil}
 	sh := NewStateHandler(config, TestConfig, components.NewBlockPool(fc.Height+1, nil))
 	sh.blockPool = components.NewBlockPool(fc.Height+1, sh)
 	fc.SetNewHeightNotifier(sh.NewHeight)
@@ -24,6 +24,9 @@ import (
 	"os"
 	"runtime"
 	"strconv"
+	"time"
+	"math/big"
+	"github.com/dipperin/dipperin-core/common/consts"
 	"github.com/dipperin/dipperin-core/core/dipperin/service"
 	"github.com/dipperin/dipperin-core/third-party/rpc"
 )
@@ -63,6 +66,37 @@ type NodeConfig struct {
 	// ephemeral nodes).
 	WSPort int `toml:",omitempty"`
 
+	// ExplorerHost is the host interface on which to start the explorer REST server. If
+	// this field is empty, the explorer is disabled.
+	ExplorerHost string `toml:",omitempty"`
+
+	// ExplorerPort is the TCP port number on which to start the explorer REST server.
+	ExplorerPort int `toml:",omitempty"`
+
+	// StratumHost is the host interface on which the mine master serves the stratum miners. If
+	// this field is empty, the stratum server is disabled.
+	StratumHost string `toml:",omitempty"`
+
+	// StratumPort is the TCP port number on which to start the stratum server.
+	StratumPort int `toml:",omitempty"`
+
+	// MineRewardMode is how the mine master shares the block rewards with the workers, pplns or pps.
+	MineRewardMode string `toml:",omitempty"`
+
+	// MinePPLNSWindow is the PPLNS window of the last shares in multiples of the block difficulty.
+	MinePPLNSWindow float64 `toml:",omitempty"`
+
+	// MinePayoutThreshold is the balance in DIP of a worker paid by the mine master automatically,
+	// the payout is disabled if it's zero.
+	MinePayoutThreshold float64 `toml:",omitempty"`
+
+	// MinePayoutInterval is the interval of the payout rounds.
+	MinePayoutInterval time.Duration `toml:",omitempty"`
+
+	// MinerThreads is the number of the hashing threads of the local worker, all the cpu cores
+	// are used if it's zero.
+	MinerThreads int `toml:",omitempty"`
+
 	// 0 normal 1 mine master 2 verifier
 	NodeType int
 
@@ -72,7 +106,29 @@ type NodeConfig struct {
 	SoftWalletPassword   string
 	SoftWalletPassPhrase string
 	SoftWalletPath		 string
+	// LedgerWallet or TrezorWallet, the default account is kept in the hardware wallet instead of the soft wallet if set
+	HardWallet			 string `toml:",omitempty"`
+	// the hid device path of the hardware wallet, the first device is used if empty
+	HardWalletPath		 string `toml:",omitempty"`
 	IsStartMine			 bool
+	// sync the chain as a light client with super block proof and headers
+	LightSync			 bool
+	// index the txs by their sender and receivers
+	AddressIndex		 bool
+	// minimum fee bump percentage to replace a pool tx, 0 uses the default
+	TxFeeBump			 uint64
+	// the account in the wallet sending the double sign evidence found by bft, empty disables it
+	EvidenceReporter	 string
+	// keep the state of all blocks instead of pruning the old ones
+	Archive				 bool
+	// number of recent blocks whose state is kept by the pruning mode
+	StateRetainBlocks	 int
+	// download the state of a recent block from the peers instead of processing all blocks
+	FastSync			 bool
+	// single process local dev chain sealing a block when txs arrive
+	DevMode				 bool
+	// the accounts derived from the mnemonic are prefunded in the dev chain genesis
+	DevMnemonic			 string
 
 
 	//used to set the default account of pbft
@@ -201,3 +257,37 @@ func (conf NodeConfig) WsEndpoint() string {
 	}
 	return fmt.Sprintf("%s:%d", conf.WSHost, conf.WSPort)
 }
+func (conf NodeConfig) ExplorerEndpoint() string {
+	if conf.ExplorerHost == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", conf.ExplorerHost, conf.ExplorerPort)
+}
+func (conf NodeConfig) StratumEndpoint() string {
+	if conf.StratumHost == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", conf.StratumHost, conf.StratumPort)
+}
+
+// GetMinePayoutThreshold converts the payout threshold in DIP to the smallest unit
+func (conf NodeConfig) GetMinePayoutThreshold() *big.Int {
+	threshold, _ := new(big.Float).Mul(big.NewFloat(conf.MinePayoutThreshold), big.NewFloat(consts.DIP)).Int(nil)
+	return threshold
+}
+
+// GetStateRetainBlocks returns 0 for the archive node, which keeps the state of all blocks
+func (conf NodeConfig) GetStateRetainBlocks() int {
+	if conf.Archive || conf.StateRetainBlocks < 0 {
+		return 0
+	}
+	return conf.StateRetainBlocks
+}
+
+// GetDevMnemonic returns the well known DefaultDevMnemonic if the mnemonic of the dev chain accounts isn't set
+func (conf NodeConfig) GetDevMnemonic() string {
+	if conf.DevMnemonic == "" {
+		return DefaultDevMnemonic
+	}
+	return conf.DevMnemonic
+}
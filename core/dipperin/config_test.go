@@ -19,6 +19,7 @@ package dipperin
 
 import (
 	"testing"
+	"math/big"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -147,4 +148,37 @@ func TestNodeConfig_WsEndpoint(t *testing.T) {
 	nodeConfig = NodeConfig{WSHost:"host"}
 	result = nodeConfig.WsEndpoint()
 	assert.Equal(t, "host:0", result)
-}
\ No newline at end of file
+}
+func TestNodeConfig_ExplorerEndpoint(t *testing.T) {
+	nodeConfig := NodeConfig{}
+	assert.Equal(t, "", nodeConfig.ExplorerEndpoint())
+
+	nodeConfig = NodeConfig{ExplorerHost: "host", ExplorerPort: 7003}
+	assert.Equal(t, "host:7003", nodeConfig.ExplorerEndpoint())
+}
+func TestNodeConfig_StratumEndpoint(t *testing.T) {
+	nodeConfig := NodeConfig{}
+	assert.Equal(t, "", nodeConfig.StratumEndpoint())
+
+	nodeConfig = NodeConfig{StratumHost: "host", StratumPort: 7004}
+	assert.Equal(t, "host:7004", nodeConfig.StratumEndpoint())
+}
+
+func TestNodeConfig_GetMinePayoutThreshold(t *testing.T) {
+	nodeConfig := NodeConfig{}
+	assert.Equal(t, big.NewInt(0), nodeConfig.GetMinePayoutThreshold())
+
+	nodeConfig = NodeConfig{MinePayoutThreshold: 1.5}
+	assert.Equal(t, big.NewInt(1.5e9), nodeConfig.GetMinePayoutThreshold())
+}
+
+func TestNodeConfig_GetStateRetainBlocks(t *testing.T) {
+	nodeConfig := NodeConfig{StateRetainBlocks: 128}
+	assert.Equal(t, 128, nodeConfig.GetStateRetainBlocks())
+
+	nodeConfig = NodeConfig{StateRetainBlocks: 128, Archive: true}
+	assert.Equal(t, 0, nodeConfig.GetStateRetainBlocks())
+
+	nodeConfig = NodeConfig{StateRetainBlocks: -1}
+	assert.Equal(t, 0, nodeConfig.GetStateRetainBlocks())
+}
@@ -40,6 +40,8 @@ const (
 
 	staticNodes     = "static-nodes.json"
 	trustedNodes    = "trusted-nodes.json"
+	// balances of the workers of the mine master
+	mineRewardsFile = "mine-rewards.json"
 )
 
 // DefaultDataDir is the default data directory to use for the databases and other
@@ -0,0 +1,140 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package dipperin
+
+import (
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/consts"
+	"github.com/dipperin/dipperin-core/common/g-event"
+	"github.com/dipperin/dipperin-core/core/accounts/soft-wallet"
+	"github.com/dipperin/dipperin-core/core/chain"
+	"github.com/dipperin/dipperin-core/core/chain-communication"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/core/model/builder"
+	"github.com/dipperin/dipperin-core/core/tx-pool"
+	"github.com/dipperin/dipperin-core/third-party/log"
+	"math/big"
+)
+
+const (
+	// the accounts of the well known mnemonic are public, never use them out of the dev chain
+	DefaultDevMnemonic       = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	DefaultDevWalletPassword = "123"
+
+	// number of the prefunded accounts derived from the dev mnemonic
+	devAccountNumber = 10
+
+	// the dev chain has its own chain id and network id, so its txs and peers never mix with the other chains
+	devChainId   = 1337
+	devNetworkID = 1337
+)
+
+var devAccountBalance = new(big.Int).Mul(big.NewInt(1000000), big.NewInt(consts.DIP))
+
+/*
+The dev chain has only one verifier, the first account derived from the dev mnemonic, which is also
+the default account of the node wallet. The difficulty isn't validated, so the blocks are sealed without pow
+*/
+func (b *BaseComponent) initDevMode() {
+	if !b.nodeConfig.DevMode {
+		return
+	}
+
+	devAccounts, err := soft_wallet.GetAccountsFromMnemonic(b.nodeConfig.GetDevMnemonic(), b.nodeConfig.SoftWalletPassPhrase, devAccountNumber)
+	if err != nil {
+		panic("derive dev accounts failed: " + err.Error())
+	}
+	alloc := chain.GenesisAlloc{}
+	for _, account := range devAccounts {
+		alloc[account.Address] = devAccountBalance
+	}
+
+	b.chainConfig.ChainId = big.NewInt(devChainId)
+	b.chainConfig.NetworkID = devNetworkID
+	b.chainConfig.VerifierNumber = 1
+	b.txSigner = model.NewMercurySigner(b.chainConfig.ChainId)
+	chain.SetDevGenesis(alloc, []common.Address{devAccounts[0].Address})
+	model.EnableDevMode()
+	log.Info("dev mode enabled", "verifier", devAccounts[0].Address.Hex(), "prefunded accounts", len(devAccounts))
+}
+
+func (b *BaseComponent) initDevSealer() {
+	if !b.nodeConfig.DevMode {
+		return
+	}
+
+	b.devSealer = &devSealer{
+		blockBuilder: builder.MakeBftBlockBuilder(b.builderModelConfig()),
+		chain:        b.fullChain,
+		signer:       b.msgSigner,
+		quit:         make(chan struct{}),
+	}
+}
+
+type devChain interface {
+	SaveBlock(block model.AbstractBlock, seenCommits []model.AbstractVerification) error
+}
+
+// devSealer seals a block with the vote of the only verifier when new txs arrive in the tx pool of the dev chain
+type devSealer struct {
+	blockBuilder builder.AbstractBlockBuilder
+	chain        devChain
+	signer       chain_communication.PbftSigner
+
+	quit chan struct{}
+}
+
+func (s *devSealer) Start() error {
+	txsCh := make(chan tx_pool.NewTxsEvent)
+	sub := g_event.Subscribe(g_event.NewTxInPoolEvent, txsCh)
+
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case <-txsCh:
+				s.seal()
+			case <-s.quit:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *devSealer) Stop() {
+	close(s.quit)
+}
+
+// the txs arriving while sealing are packed into the next block, no empty block is sealed
+func (s *devSealer) seal() {
+	block := s.blockBuilder.BuildWaitPackBlock(s.signer.GetAddress())
+	if block == nil || block.TxCount() == 0 {
+		return
+	}
+
+	vote, err := model.NewVoteMsgWithSign(block.Number(), 0, block.Hash(), model.VoteMessage, s.signer.SignHash, s.signer.GetAddress())
+	if err != nil {
+		log.Error("dev sealer sign vote failed", "err", err)
+		return
+	}
+	if err = s.chain.SaveBlock(block, []model.AbstractVerification{vote}); err != nil {
+		log.Error("dev sealer save block failed", "num", block.Number(), "err", err)
+		return
+	}
+	log.Info("dev sealer sealed block", "num", block.Number(), "txs", block.TxCount())
+}
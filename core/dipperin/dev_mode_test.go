@@ -0,0 +1,128 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package dipperin
+
+import (
+	"errors"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/core/chain"
+	"github.com/dipperin/dipperin-core/core/chain-communication"
+	"github.com/dipperin/dipperin-core/core/chain-config"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/tests/factory"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+type fakeDevBuilder struct {
+	block model.AbstractBlock
+}
+
+func (b *fakeDevBuilder) BuildWaitPackBlock(coinbaseAddr common.Address) model.AbstractBlock {
+	return b.block
+}
+
+type fakeDevChain struct {
+	err   error
+	saved []model.AbstractBlock
+	votes [][]model.AbstractVerification
+}
+
+func (c *fakeDevChain) SaveBlock(block model.AbstractBlock, seenCommits []model.AbstractVerification) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.saved = append(c.saved, block)
+	c.votes = append(c.votes, seenCommits)
+	return nil
+}
+
+type fakeDevSigner struct {
+	chain_communication.PbftSigner
+	signErr error
+}
+
+func (s *fakeDevSigner) GetAddress() common.Address {
+	return aliceAddr
+}
+
+func (s *fakeDevSigner) SignHash(hash []byte) ([]byte, error) {
+	return []byte{1, 2, 3}, s.signErr
+}
+
+func TestDevSealer_seal(t *testing.T) {
+	builder := &fakeDevBuilder{}
+	chain := &fakeDevChain{}
+	signer := &fakeDevSigner{}
+	sealer := &devSealer{blockBuilder: builder, chain: chain, signer: signer, quit: make(chan struct{})}
+
+	// no block or no tx
+	sealer.seal()
+	builder.block = factory.CreateSpecialBlock(1)
+	sealer.seal()
+	assert.Len(t, chain.saved, 0)
+
+	builder.block = factory.CreateBlock(1)
+	signer.signErr = errors.New("sign failed")
+	sealer.seal()
+	assert.Len(t, chain.saved, 0)
+
+	signer.signErr = nil
+	chain.err = errors.New("save failed")
+	sealer.seal()
+	assert.Len(t, chain.saved, 0)
+
+	chain.err = nil
+	sealer.seal()
+	assert.Len(t, chain.saved, 1)
+	assert.Equal(t, builder.block.Hash(), chain.saved[0].Hash())
+	assert.Len(t, chain.votes[0], 1)
+	vote := chain.votes[0][0].(*model.VoteMsg)
+	assert.Equal(t, builder.block.Hash(), vote.GetBlockId())
+	assert.Equal(t, aliceAddr, vote.GetAddress())
+	assert.Equal(t, model.VoteMessage, vote.GetType())
+}
+
+func TestBaseComponent_initDevMode(t *testing.T) {
+	verifiers := chain.VerifierAddress
+	defer chain.SetDevGenesis(nil, verifiers)
+
+	b := &BaseComponent{
+		chainConfig: &chain_config.ChainConfig{ChainId: big.NewInt(1), NetworkID: 99, VerifierNumber: 22},
+		nodeConfig:  NodeConfig{SoftWalletPassPhrase: DefaultDevWalletPassword},
+	}
+	b.initDevMode()
+	assert.Equal(t, big.NewInt(1), b.chainConfig.ChainId)
+	assert.Equal(t, uint64(99), b.chainConfig.NetworkID)
+
+	// the dev chain doesn't keep the chain id and the network id of mercury
+	b.nodeConfig.DevMode = true
+	b.initDevMode()
+	assert.Equal(t, big.NewInt(devChainId), b.chainConfig.ChainId)
+	assert.Equal(t, uint64(devNetworkID), b.chainConfig.NetworkID)
+	assert.Equal(t, 1, b.chainConfig.VerifierNumber)
+	assert.Equal(t, model.NewMercurySigner(big.NewInt(devChainId)), b.txSigner)
+	assert.Len(t, chain.VerifierAddress, 1)
+}
+
+func TestNodeConfig_GetDevMnemonic(t *testing.T) {
+	conf := NodeConfig{}
+	assert.Equal(t, DefaultDevMnemonic, conf.GetDevMnemonic())
+	conf.DevMnemonic = "test mnemonic"
+	assert.Equal(t, "test mnemonic", conf.GetDevMnemonic())
+}
@@ -29,7 +29,7 @@ import (
 )
 
 func NewMinerNode(master string, coinbase string, minerCount int, p2pListenAddr string) (n Node, err error) {
-	if coinbase == "" || minerCount <= 0 {
+	if coinbase == "" || minerCount < 0 {
 		err = errors.New("coinbase or miner count not right")
 		return
 	}
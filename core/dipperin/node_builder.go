@@ -23,6 +23,7 @@ import (
 	"github.com/dipperin/dipperin-core/common/g-metrics"
 	"github.com/dipperin/dipperin-core/common/util"
 	"github.com/dipperin/dipperin-core/core/accounts"
+	"github.com/dipperin/dipperin-core/core/accounts/hard-wallet"
 	"github.com/dipperin/dipperin-core/core/accounts/soft-wallet"
 	"github.com/dipperin/dipperin-core/core/chain"
 	"github.com/dipperin/dipperin-core/core/chain-communication"
@@ -36,6 +37,7 @@ import (
 	"github.com/dipperin/dipperin-core/core/csbft/components"
 	"github.com/dipperin/dipperin-core/core/csbft/csbftnode"
 	"github.com/dipperin/dipperin-core/core/csbft/state-machine"
+	"github.com/dipperin/dipperin-core/core/economy-model"
 	"github.com/dipperin/dipperin-core/core/mine/minemaster"
 	"github.com/dipperin/dipperin-core/core/model"
 	"github.com/dipperin/dipperin-core/core/model/builder"
@@ -54,6 +56,8 @@ import (
 	"github.com/dipperin/dipperin-core/third-party/p2p/nat"
 	"github.com/dipperin/dipperin-core/third-party/p2p/netutil"
 	"github.com/dipperin/dipperin-core/third-party/rpc"
+	"github.com/mattn/go-isatty"
+	"math/big"
 	"os"
 	"path/filepath"
 	"strings"
@@ -80,6 +84,10 @@ type BaseComponent struct {
 	fullChain                   *cs_chain.CsChainService
 	txPool                      *tx_pool.TxPool
 	rpcService                  *rpc_interface.Service
+	explorerService             *rpc_interface.ExplorerService
+	stratumServer               *minemaster.StratumServer
+	rewardPayer                 *minemaster.RewardPayer
+	stateFlusher                *stateFlushService
 	txSigner                    model.Signer
 	defaultPriorityCalculator   model.PriofityCalculator
 	coinbaseAddr                *atomic.Value
@@ -97,13 +105,17 @@ type BaseComponent struct {
 	minePm                      *chain_communication.MineProtocolManager
 	mineMaster                  minemaster.Master
 	mineMasterServer            minemaster.MasterServer
+	lightChain                  *chain_communication.LightHeaderChain
 	defaultAccountAddress       common.Address
 	verHaltCheck                *verifiers_halt_check.SystemHaltedCheck
+	devSealer                   *devSealer
 }
 
 func NewBftNode(nodeConfig NodeConfig) (n Node) {
 	// newBaseComponent
 	baseComponent := newBaseComponent(nodeConfig)
+	// replace the genesis and verifiers for the dev chain
+	baseComponent.initDevMode()
 	// init full chain
 	baseComponent.initFullChain()
 	// init tx pool
@@ -125,6 +137,8 @@ func NewBftNode(nodeConfig NodeConfig) (n Node) {
 	baseComponent.initRpc()
 	// init mine master
 	baseComponent.initMineMaster()
+	// init the block sealer of the dev chain
+	baseComponent.initDevSealer()
 	// setup service config
 	baseComponent.buildDipperinConfig()
 	//init verifier halt check
@@ -151,8 +165,16 @@ func NewBftNode(nodeConfig NodeConfig) (n Node) {
 	return
 }
 
+// the private network is configured by the genesis file copied into the data dir by the init command
+func loadDataDirGenesis(dataDir string) {
+	if err := chain.LoadDataDirGenesis(dataDir); err != nil {
+		panic("load genesis file failed: " + err.Error())
+	}
+}
+
 // newBaseComponent configs and base components
 func newBaseComponent(nodeConfig NodeConfig) *BaseComponent {
+	loadDataDirGenesis(nodeConfig.DataDir)
 	promeS := g_metrics.NewPrometheusMetricsServer(nodeConfig.GetPMetricsPort())
 	g_metrics.InitCSMetrics()
 	b := &BaseComponent{
@@ -213,8 +235,12 @@ func (b *BaseComponent) buildDipperinConfig() {
 	b.DipperinConfig.WalletManager = b.walletManager
 	b.DipperinConfig.MineMaster = b.mineMaster
 	b.DipperinConfig.MineMasterServer = b.mineMasterServer
+	b.DipperinConfig.MinerThreads = b.nodeConfig.MinerThreads
 	b.DipperinConfig.DefaultAccount = b.defaultAccountAddress
 	b.DipperinConfig.MsgSigner = b.msgSigner
+	if b.lightChain != nil {
+		b.DipperinConfig.LightChain = b.lightChain
+	}
 }
 
 func (b *BaseComponent) buildBftConfig() {
@@ -226,6 +252,9 @@ func (b *BaseComponent) buildBftConfig() {
 		//Sender:MsgSender,
 		Validator: b.consensusBeforeInsertBlocks,
 	}
+	if b.nodeConfig.EvidenceReporter != "" {
+		b.bftConfig.EvidenceReporter = &EvidenceReporter{reporter: common.HexToAddress(b.nodeConfig.EvidenceReporter), sender: b.chainService}
+	}
 }
 
 func (b *BaseComponent) buildHaltCheckConfig() {
@@ -248,6 +277,17 @@ func (b *BaseComponent) buildCommunicationConfig() {
 		VerifiersReader: b.verifiersReader,
 		PbftNode:        b.bftNode,
 		MsgSigner:       b.msgSigner,
+		StateChain:      b.fullChain,
+		FastSync:        b.nodeConfig.FastSync,
+	}
+	// the light node only syncs the headers, it can't verify or mine the blocks
+	if b.nodeConfig.LightSync {
+		if b.nodeConfig.NodeType != chain_config.NodeTypeOfNormal {
+			panic("light sync only works on the normal node")
+		}
+		genesis := b.fullChain.GetBlockByNumber(0)
+		b.lightChain = chain_communication.NewLightHeaderChain(genesis.Header().(*model.Header))
+		b.pmConf.LightChain = b.lightChain
 	}
 	b.txBConf = &chain_communication.NewTxBroadcasterConfig{
 		P2PMsgDecoder: b.defaultMsgDecoder,
@@ -261,6 +301,15 @@ func (b *BaseComponent) buildMineConfig(modelConfig builder.ModelConfig) minemas
 		CoinbaseAddress:  b.coinbaseAddr,
 		BlockBuilder:     builder.MakeBftBlockBuilder(modelConfig),
 		BlockBroadcaster: b.broadcastDelegate,
+		Reward: minemaster.RewardConfig{
+			Mode:            b.nodeConfig.MineRewardMode,
+			PPLNSWindow:     b.nodeConfig.MinePPLNSWindow,
+			File:            filepath.Join(b.nodeConfig.DataDir, mineRewardsFile),
+			PayoutThreshold: b.nodeConfig.GetMinePayoutThreshold(),
+			PayoutInterval:  b.nodeConfig.MinePayoutInterval,
+			Calculator:      b.fullChain.GetEconomyModel(),
+			Sender:          b.chainService,
+		},
 	}
 }
 
@@ -281,8 +330,11 @@ func (b *BaseComponent) initFullChain() {
 		ChainConfig: b.chainConfig,
 		DataDir:     b.nodeConfig.DataDir,
 		WriterFactory: chain_writer.NewChainWriterFactory(),
+		AddressIndex: b.nodeConfig.AddressIndex,
+		StateRetainBlocks: b.nodeConfig.GetStateRetainBlocks(),
 	}))
 	b.csChainServiceConfig.CacheDB = cachedb.NewCacheDB(b.fullChain.GetDB())
+	b.stateFlusher = &stateFlushService{storage: b.fullChain.GetStateStorage()}
 	cachedb.SetCacheDataDecoder(&cachedb.BFTCacheDataDecoder{})
 
 	b.verifiersReader = chain.MakeVerifiersReader(b.fullChain)
@@ -301,6 +353,9 @@ func (b *BaseComponent) initFullChain() {
 func (b *BaseComponent) initTxPool() {
 	txPoolConfig := tx_pool.DefaultTxPoolConfig
 	txPoolConfig.Journal = filepath.Join(b.nodeConfig.DataDir, "transaction.rlp")
+	if b.nodeConfig.TxFeeBump > 0 {
+		txPoolConfig.FeeBump = b.nodeConfig.TxFeeBump
+	}
 	// no need to replace with context
 	b.txPool = tx_pool.NewTxPool(txPoolConfig, *b.chainConfig, b.fullChain)
 	b.csChainServiceConfig.TxPool = b.txPool
@@ -321,13 +376,17 @@ func (b *BaseComponent) initWalletManager() {
 	tmpLog.SetHandler(log.StdoutHandler)
 	var err error
 	log.Info("the nodeType is:","nodeType",b.nodeConfig.NodeType)
-	// No need to create or open a default wallet when the normal node starts
-	if b.nodeConfig.NodeType == chain_config.NodeTypeOfNormal {
+	// No need to create or open a default wallet when the normal node starts, the dev chain needs the wallet to seal blocks
+	if b.nodeConfig.NodeType == chain_config.NodeTypeOfNormal && !b.nodeConfig.DevMode {
 		if b.walletManager, err = accounts.NewWalletManager(b.chainService); err != nil {
 			panic("init wallet manager failed: " + err.Error())
 		}
 		return
 	}
+	if b.nodeConfig.HardWallet != "" {
+		b.initHardWallet()
+		return
+	}
 	// load wallet manager
 	defaultWallet, wErr := soft_wallet.NewSoftWallet()
 	if wErr != nil {
@@ -338,6 +397,9 @@ func (b *BaseComponent) initWalletManager() {
 	exit, _ := soft_wallet.PathExists(b.nodeConfig.SoftWalletFile())
 	if exit {
 		err = defaultWallet.Open(b.nodeConfig.SoftWalletFile(), b.nodeConfig.SoftWalletName(), b.nodeConfig.SoftWalletPassword)
+	} else if b.nodeConfig.DevMode {
+		// the default account is the verifier of the dev chain
+		err = defaultWallet.RestoreWallet(b.nodeConfig.SoftWalletFile(), b.nodeConfig.SoftWalletName(), b.nodeConfig.SoftWalletPassword, b.nodeConfig.SoftWalletPassPhrase, b.nodeConfig.GetDevMnemonic(), b.fullChain)
 	} else {
 		mnemonic, err = defaultWallet.Establish(b.nodeConfig.SoftWalletFile(), b.nodeConfig.SoftWalletName(), b.nodeConfig.SoftWalletPassword, b.nodeConfig.SoftWalletPassPhrase)
 		mnemonic = strings.Replace(mnemonic, " ", ",", -1)
@@ -364,6 +426,50 @@ func (b *BaseComponent) initWalletManager() {
 	log.Info("open wallet success", "b.defaultAccountAddress", b.defaultAccountAddress)
 }
 
+// the verifier or the mine master signs with the first account of the hardware wallet, the key never leaves the device
+func (b *BaseComponent) initHardWallet() {
+	tmpLog := log.New()
+	tmpLog.SetHandler(log.StdoutHandler)
+
+	walletType, err := accounts.ParseWalletType(b.nodeConfig.HardWallet)
+	if err != nil || !walletType.IsHardware() {
+		tmpLog.Info("invalid hardware wallet type", "type", b.nodeConfig.HardWallet)
+		os.Exit(1)
+	}
+	if !chain_config.GetChainConfig().SupportHardwareWallet {
+		tmpLog.Info("the chain doesn't support hardware wallet")
+		os.Exit(1)
+	}
+
+	defaultWallet, err := hard_wallet.NewHardWallet(walletType)
+	if err == nil {
+		// a locked trezor asks for the pin on the terminal only while it is opened, the console owns the terminal
+		// later, so the device must stay unlocked while the node is running
+		if isatty.IsTerminal(os.Stdin.Fd()) {
+			defaultWallet.SetPinPrompt(hard_wallet.TerminalPinPrompt)
+		}
+		err = defaultWallet.Open(b.nodeConfig.HardWalletPath, "", "")
+		defaultWallet.SetPinPrompt(nil)
+	}
+	if err != nil {
+		tmpLog.Info("open hardware wallet error ", "err", err)
+		os.Exit(1)
+	}
+
+	if b.walletManager, err = accounts.NewWalletManager(b.chainService, defaultWallet); err != nil {
+		tmpLog.Info("init wallet manager failed: ", "err", err)
+		os.Exit(1)
+	}
+	defaultAccounts, err := defaultWallet.Accounts()
+	if err != nil {
+		tmpLog.Info("get default accounts failed: ", "err", err)
+		os.Exit(1)
+	}
+	b.coinbaseAddr.Store(defaultAccounts[0].Address)
+	b.defaultAccountAddress = defaultAccounts[0].Address
+	log.Info("open hardware wallet success", "b.defaultAccountAddress", b.defaultAccountAddress)
+}
+
 func (b *BaseComponent) initP2PService() {
 	// load p2p
 	p2pConf := DefaultP2PConf()
@@ -451,6 +557,10 @@ func (b *BaseComponent) initRpc() {
 		debug.Memsize.Add("rpc server", b.rpcService)
 	}
 
+	if endpoint := b.nodeConfig.ExplorerEndpoint(); endpoint != "" {
+		b.explorerService = rpc_interface.MakeExplorerService(endpoint, b.chainService)
+	}
+
 	return
 }
 
@@ -460,6 +570,9 @@ func (b *BaseComponent) initMineMaster() {
 	}
 
 	mineConfig := b.buildMineConfig(b.builderModelConfig())
+	if mode := mineConfig.Reward.Mode; mode != "" && mode != minemaster.RewardModePPLNS && mode != minemaster.RewardModePPS {
+		panic("unknown mine reward mode: " + mode)
+	}
 	// chain service not init here
 	mineMaster, mineMasterServer := minemaster.MakeMineMaster(mineConfig)
 	minePm := chain_communication.NewMineProtocolManager(mineMasterServer)
@@ -468,11 +581,16 @@ func (b *BaseComponent) initMineMaster() {
 	b.minePm = minePm
 	b.mineMaster = mineMaster
 	b.mineMasterServer = mineMasterServer
+
+	if endpoint := b.nodeConfig.StratumEndpoint(); endpoint != "" {
+		b.stratumServer = minemaster.MakeStratumServer(endpoint, mineMaster, mineMasterServer)
+	}
+	b.rewardPayer = minemaster.MakeRewardPayer(mineMasterServer)
 }
 
 // must have init wallet manager
 func (b *BaseComponent) initMsgSigner() {
-	if b.nodeConfig.NodeType == chain_config.NodeTypeOfNormal {
+	if b.nodeConfig.NodeType == chain_config.NodeTypeOfNormal && !b.nodeConfig.DevMode {
 		b.msgSigner = nil
 	} else {
 		log.Info("setup default sign address", "addr", b.defaultAccountAddress.Hex())
@@ -511,7 +629,9 @@ func (b *BaseComponent) getNodeServices() []NodeService {
 	// these services may have nil
 	return filterNilService([]NodeService{
 		b.chainService, b.bftNode, b.walletManager, b.csPm,
-		b.p2pServer, b.rpcService,b.txPool, b.prometheusServer,
+		b.p2pServer, b.rpcService,b.txPool, b.devSealer, b.prometheusServer, b.explorerService, b.stratumServer, b.rewardPayer,
+		// stopped at last to flush the state of the last inserted block
+		b.stateFlusher,
 	})
 }
 
@@ -550,3 +670,27 @@ func (m *MsgSender) SendReqRoundMsg(msgCode uint64, from []common.Address, msg i
 func (m *MsgSender) BroadcastEiBlock(block model.AbstractBlock) {
 	m.broadcastDelegate.BroadcastEiBlock(block)
 }
+
+type evidenceSender interface {
+	SendEvidenceTransaction(from, target common.Address, fee *big.Int, voteA *model.VoteMsg, voteB *model.VoteMsg, nonce *uint64) (common.Hash, error)
+}
+
+// send the evidence tx of the double sign found by bft from the reporter account
+type EvidenceReporter struct {
+	reporter common.Address
+	sender   evidenceSender
+}
+
+func (r *EvidenceReporter) ReportDoubleSign(voteA, voteB *model.VoteMsg) {
+	target := voteA.GetAddress()
+	// the unsigned tx size is doubled to cover the witness
+	tx := model.NewEvidenceTransaction(0, nil, &target, voteA, voteB)
+	fee := economy_model.GetMinimumTxFee(tx.Size() * 2)
+
+	txHash, err := r.sender.SendEvidenceTransaction(r.reporter, target, fee, voteA, voteB, nil)
+	if err != nil {
+		pbft_log.Error("send double sign evidence failed", "target", target.Hex(), "err", err)
+		return
+	}
+	pbft_log.Info("send double sign evidence", "target", target.Hex(), "txId", txHash.Hex())
+}
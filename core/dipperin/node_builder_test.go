@@ -17,6 +17,10 @@
 package dipperin
 
 import (
+	"errors"
+	"github.com/dipperin/dipperin-core/core/economy-model"
+	"github.com/dipperin/dipperin-core/core/model"
+	"math/big"
 	"testing"
 	"github.com/dipperin/dipperin-core/core/cs-chain"
 	"github.com/stretchr/testify/assert"
@@ -95,3 +99,38 @@ func TestMsgSender(t *testing.T) {
 	msgSender.SendReqRoundMsg(0, []common.Address{aliceAddr}, block.Hash())
 	os.RemoveAll(nodeConfig.DataDir)
 }
+
+type fakeEvidenceSender struct {
+	from, target common.Address
+	fee          *big.Int
+	err          error
+}
+
+func (s *fakeEvidenceSender) SendEvidenceTransaction(from, target common.Address, fee *big.Int, voteA *model.VoteMsg, voteB *model.VoteMsg, nonce *uint64) (common.Hash, error) {
+	s.from, s.target, s.fee = from, target, fee
+	return common.Hash{}, s.err
+}
+
+func TestEvidenceReporter_ReportDoubleSign(t *testing.T) {
+	b := &BaseComponent{nodeConfig: NodeConfig{}}
+	b.buildBftConfig()
+	assert.Nil(t, b.bftConfig.EvidenceReporter)
+
+	b.nodeConfig.EvidenceReporter = aliceAddr.Hex()
+	b.buildBftConfig()
+	assert.Equal(t, aliceAddr, b.bftConfig.EvidenceReporter.(*EvidenceReporter).reporter)
+
+	target := common.HexToAddress("0x0000b4293d60F051936beDecfaE1B85d5A46d377aF37")
+	voteA := &model.VoteMsg{Height: 1, BlockID: common.HexToHash("0x1"), Witness: &model.WitMsg{Address: target}}
+	voteB := &model.VoteMsg{Height: 1, BlockID: common.HexToHash("0x2"), Witness: &model.WitMsg{Address: target}}
+	sender := &fakeEvidenceSender{}
+	reporter := &EvidenceReporter{reporter: aliceAddr, sender: sender}
+	reporter.ReportDoubleSign(voteA, voteB)
+	assert.Equal(t, aliceAddr, sender.from)
+	assert.Equal(t, target, sender.target)
+	tx := model.NewEvidenceTransaction(0, sender.fee, &target, voteA, voteB)
+	assert.True(t, sender.fee.Cmp(economy_model.GetMinimumTxFee(tx.Size())) > 0)
+
+	sender.err = errors.New("test")
+	reporter.ReportDoubleSign(voteA, voteB)
+}
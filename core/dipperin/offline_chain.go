@@ -0,0 +1,48 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package dipperin
+
+import (
+	"github.com/dipperin/dipperin-core/core/chain-config"
+	"github.com/dipperin/dipperin-core/core/chain/cachedb"
+	"github.com/dipperin/dipperin-core/core/cs-chain"
+	"github.com/dipperin/dipperin-core/core/cs-chain/chain-state"
+	"github.com/dipperin/dipperin-core/core/cs-chain/chain-writer"
+	"github.com/dipperin/dipperin-core/core/model"
+)
+
+// the chain opened without the node has no tx pool to reset
+type offlineTxPool struct{}
+
+func (offlineTxPool) Reset(oldHead, newHead *model.Header) {}
+
+// OpenOfflineChain opens the full chain in the data dir without starting the node, used by the chain export and import commands.
+// The state kept in memory by the pruning mode must be flushed and the db closed when finished
+func OpenOfflineChain(conf NodeConfig) *cs_chain.CsChainService {
+	loadDataDirGenesis(conf.DataDir)
+	csConfig := &cs_chain.CsChainServiceConfig{TxPool: offlineTxPool{}}
+	fullChain := cs_chain.NewCsChainService(csConfig, chain_state.NewChainState(&chain_state.ChainStateConfig{
+		ChainConfig:       chain_config.GetChainConfig(),
+		DataDir:           conf.DataDir,
+		WriterFactory:     chain_writer.NewChainWriterFactory(),
+		AddressIndex:      conf.AddressIndex,
+		StateRetainBlocks: conf.GetStateRetainBlocks(),
+	}))
+	csConfig.CacheDB = cachedb.NewCacheDB(fullChain.GetDB())
+	cachedb.SetCacheDataDecoder(&cachedb.BFTCacheDataDecoder{})
+	return fullChain
+}
@@ -24,8 +24,8 @@ import (
 	"github.com/dipperin/dipperin-core/common/g-event"
 	"github.com/dipperin/dipperin-core/common/g-metrics"
 	"github.com/dipperin/dipperin-core/common/g-timer"
-	"github.com/dipperin/dipperin-core/common/hexutil"
 	"github.com/dipperin/dipperin-core/core/accounts"
+	"github.com/dipperin/dipperin-core/core/accounts/hard-wallet"
 	"github.com/dipperin/dipperin-core/core/accounts/soft-wallet"
 	"github.com/dipperin/dipperin-core/core/chain-communication"
 	"github.com/dipperin/dipperin-core/core/chain-config"
@@ -36,11 +36,14 @@ import (
 	"github.com/dipperin/dipperin-core/core/mine/minemaster"
 	"github.com/dipperin/dipperin-core/core/mine/mineworker"
 	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/core/tx-pool"
 	"github.com/dipperin/dipperin-core/third-party/log"
 	"github.com/dipperin/dipperin-core/third-party/log/pbft_log"
+	"github.com/dipperin/dipperin-core/third-party/crypto/cs-crypto"
 	"github.com/dipperin/dipperin-core/third-party/p2p"
 	"github.com/dipperin/dipperin-core/third-party/p2p/enode"
 	"github.com/dipperin/dipperin-core/third-party/rpc"
+	"github.com/ethereum/go-ethereum/rlp"
 	"math/big"
 	"os"
 	"time"
@@ -85,6 +88,7 @@ type TxPool interface {
 	AddLocals(txs []model.AbstractTransaction) []error
 	AddRemote(tx model.AbstractTransaction) error
 	Stats() (int, int)
+	ContentFrom(addr common.Address) ([]model.AbstractTransaction, []tx_pool.QueuedTx)
 }
 
 type Node interface {
@@ -124,12 +128,16 @@ type DipperinConfig struct {
 
 	NodeConf           NodeConf
 	GetMineCoinBase    common.Address
+	// hashing threads of the local worker, 0 uses all the cpu cores
+	MinerThreads       int
 	MsgSigner          MsgSigner
 	ChainConfig        chain_config.ChainConfig
 	PriorityCalculator model.PriofityCalculator
 	MineMasterServer   minemaster.MasterServer
 	P2PServer          *p2p.Server
 	NormalPm           chain_communication.PeerManager
+	// only the light node has it
+	LightChain chain_communication.LightChain
 
 	Node Node
 }
@@ -154,6 +162,14 @@ func (service *MercuryFullChainService) CurrentBlock() model.AbstractBlock {
 	return service.ChainReader.CurrentBlock()
 }
 
+// CurrentLightHeader returns the head of the header chain synced by the light node
+func (service *MercuryFullChainService) CurrentLightHeader() (*model.Header, error) {
+	if service.LightChain == nil {
+		return nil, errors.New("the node isn't started with light sync")
+	}
+	return service.LightChain.CurrentHeader(), nil
+}
+
 func (service *MercuryFullChainService) GetBlockByNumber(number uint64) (model.AbstractBlock, error) {
 	return service.ChainReader.GetBlockByNumber(number), nil
 }
@@ -208,7 +224,7 @@ func (service *MercuryFullChainService) Start() error {
 	if service.MineMaster != nil && !service.MineMaster.CurrentCoinbaseAddress().IsEmpty() {
 		if service.localWorker == nil {
 			time.Sleep(500 * time.Millisecond)
-			service.localWorker = mineworker.MakeLocalWorker(service.MineMaster.CurrentCoinbaseAddress(), 1, service.MineMasterServer)
+			service.localWorker = mineworker.MakeLocalWorker(service.MineMaster.CurrentCoinbaseAddress(), service.MinerThreads, service.MineMasterServer)
 			log.Info("start local worker")
 			service.localWorker.Start()
 		}
@@ -241,6 +257,14 @@ func (service *MercuryFullChainService) Stop() {
 }
 
 func (service *MercuryFullChainService) checkWalletIdentifier(walletIdentifier *accounts.WalletIdentifier) error {
+	if walletIdentifier.WalletType.IsHardware() {
+		if !chain_config.GetChainConfig().SupportHardwareWallet {
+			return accounts.ErrNotSupportUsbWallet
+		}
+		service.completeHardWalletIdentifier(walletIdentifier)
+		return nil
+	}
+
 	if walletIdentifier.WalletType != accounts.SoftWallet {
 		return errors.New("wallet type error")
 	}
@@ -256,6 +280,30 @@ func (service *MercuryFullChainService) checkWalletIdentifier(walletIdentifier *
 	return nil
 }
 
+// a hardware wallet is identified by the device path, the opened wallet of the type is used if the path is empty
+func (service *MercuryFullChainService) completeHardWalletIdentifier(walletIdentifier *accounts.WalletIdentifier) {
+	if service.WalletManager == nil {
+		return
+	}
+	identifiers, err := service.WalletManager.ListWalletIdentifier()
+	if err != nil {
+		return
+	}
+	for _, identifier := range identifiers {
+		if identifier.WalletType == walletIdentifier.WalletType && (walletIdentifier.Path == "" || identifier.Path == walletIdentifier.Path) {
+			*walletIdentifier = identifier
+			return
+		}
+	}
+}
+
+func newWallet(walletType accounts.WalletType) (accounts.Wallet, error) {
+	if walletType.IsHardware() {
+		return hard_wallet.NewHardWallet(walletType)
+	}
+	return soft_wallet.NewSoftWallet()
+}
+
 //set CoinBase Address
 func (service *MercuryFullChainService) SetMineCoinBase(addr common.Address) error {
 	if service.NodeConf.GetNodeType() != chain_config.NodeTypeOfMineMaster {
@@ -282,8 +330,11 @@ func (service *MercuryFullChainService) EstablishWallet(walletIdentifier account
 		return "", err
 	}
 
-	//establish softWallet
-	wallet, _ := soft_wallet.NewSoftWallet()
+	//establish wallet, the keys of a hardware wallet can't be generated here
+	wallet, err := newWallet(walletIdentifier.WalletType)
+	if err != nil {
+		return "", err
+	}
 	mnemonic, err := wallet.Establish(walletIdentifier.Path, walletIdentifier.WalletName, password, passPhrase)
 	if err != nil {
 		log.Info("the err3 is :", "err", err)
@@ -313,9 +364,18 @@ func (service *MercuryFullChainService) OpenWallet(walletIdentifier accounts.Wal
 		return err
 	}
 
-	//Open according to the path
-	//establish softWallet
-	wallet, _ := soft_wallet.NewSoftWallet()
+	// the device is held by the opened hardware wallet
+	if walletIdentifier.WalletType.IsHardware() {
+		if opened, _ := service.WalletManager.FindWalletFromIdentifier(walletIdentifier); opened != nil {
+			return nil
+		}
+	}
+
+	//Open according to the path, the path of a hardware wallet is the device path
+	wallet, err := newWallet(walletIdentifier.WalletType)
+	if err != nil {
+		return err
+	}
 	err = wallet.Open(walletIdentifier.Path, walletIdentifier.WalletName, password)
 	if err != nil {
 		return err
@@ -394,8 +454,11 @@ func (service *MercuryFullChainService) RestoreWallet(walletIdentifier accounts.
 		}
 	}
 
-	//establish softWallet
-	wallet, _ := soft_wallet.NewSoftWallet()
+	//restore wallet
+	wallet, err := newWallet(walletIdentifier.WalletType)
+	if err != nil {
+		return err
+	}
 	err = wallet.RestoreWallet(walletIdentifier.Path, walletIdentifier.WalletName, password, passPhrase, mnemonic, service)
 	if err != nil {
 		return err
@@ -643,6 +706,72 @@ func (service *MercuryFullChainService) SendTransaction(from, to common.Address,
 	return txHash, nil
 }
 
+//get the pending and queued transactions of the address in the tx pool
+func (service *MercuryFullChainService) GetPoolContent(address common.Address) ([]model.AbstractTransaction, []tx_pool.QueuedTx) {
+	return service.TxPool.ContentFrom(address)
+}
+
+func (service *MercuryFullChainService) getPoolTransaction(from common.Address, nonce uint64) (model.AbstractTransaction, error) {
+	pending, queued := service.TxPool.ContentFrom(from)
+	for _, tx := range pending {
+		if tx.Nonce() == nonce {
+			return tx, nil
+		}
+	}
+	for _, q := range queued {
+		if q.Tx.Nonce() == nonce {
+			return q.Tx, nil
+		}
+	}
+	return nil, errors.New("the transaction isn't in the tx pool")
+}
+
+// the nonce of the wallet has been used by the replaced tx, so it isn't changed here
+func (service *MercuryFullChainService) sendReplaceTx(from common.Address, tx *model.Transaction) (common.Hash, error) {
+	tmpWallet, err := service.WalletManager.FindWalletFromAddress(from)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	signedTx, err := tmpWallet.SignTx(accounts.Account{Address: from}, tx, service.ChainConfig.ChainId)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := service.TxValidator.Valid(signedTx); err != nil {
+		return common.Hash{}, err
+	}
+
+	tsx := []model.AbstractTransaction{signedTx}
+	errs := service.TxPool.AddRemotes(tsx)
+	for i := range errs {
+		if errs[i] != nil {
+			return common.Hash{}, errs[i]
+		}
+	}
+	service.Broadcaster.BroadcastTx(tsx)
+
+	txHash := signedTx.CalTxId()
+	log.Info("the replace transaction txId is: ", "txId", txHash.Hex(), "nonce", tx.Nonce())
+	return txHash, nil
+}
+
+//replace the tx of the nonce in the tx pool with a higher fee, the receiver, value, data and the locks of the htlc txs are kept
+func (service *MercuryFullChainService) ReplaceTransaction(from common.Address, nonce uint64, fee *big.Int) (common.Hash, error) {
+	old, err := service.getPoolTransaction(from, nonce)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return service.sendReplaceTx(from, model.NewReplaceTransaction(old, fee))
+}
+
+//cancel the tx of the nonce in the tx pool by replacing it with a zero value tx to from itself
+func (service *MercuryFullChainService) CancelPoolTransaction(from common.Address, nonce uint64, fee *big.Int) (common.Hash, error) {
+	if _, err := service.getPoolTransaction(from, nonce); err != nil {
+		return common.Hash{}, err
+	}
+	return service.sendReplaceTx(from, model.NewTransaction(nonce, from, big.NewInt(0), fee, nil))
+}
+
 //send a register transaction
 func (service *MercuryFullChainService) SendRegisterTransaction(from common.Address, stake, fee *big.Int, nonce *uint64) (common.Hash, error) {
 	if service.NodeConf.GetNodeType() != chain_config.NodeTypeOfVerifier {
@@ -812,6 +941,374 @@ func (service *MercuryFullChainService) SendCancelTransaction(from common.Addres
 	return txHash, nil
 }
 
+//send a hash time lock transaction, lock value to the receiver until the time lock height
+func (service *MercuryFullChainService) SendLockTransaction(from, to common.Address, hashLock common.Hash, timeLock, value, fee *big.Int, nonce *uint64) (common.Hash, error) {
+	tmpWallet, usedNonce, err := service.getSendTxInfo(from, nonce)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	tx := model.CreateRawLockTx(usedNonce, hashLock, timeLock, value, fee, from, to)
+	signTx, err := service.signTxAndSend(tmpWallet, from, tx, usedNonce)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	txHash := signTx.CalTxId()
+	log.Info("the SendLockTransaction txId is: ", "txId", txHash.Hex())
+	return txHash, nil
+}
+
+//claim the money locked by lockSender with the hash key
+func (service *MercuryFullChainService) SendClaimTransaction(from, lockSender common.Address, hashKey []byte, fee *big.Int, nonce *uint64) (common.Hash, error) {
+	tmpWallet, usedNonce, err := service.getSendTxInfo(from, nonce)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	tx := model.CreateRawClaimTx(usedNonce, hashKey, big.NewInt(0), fee, lockSender, from)
+	signTx, err := service.signTxAndSend(tmpWallet, from, tx, usedNonce)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	txHash := signTx.CalTxId()
+	log.Info("the SendClaimTransaction txId is: ", "txId", txHash.Hex())
+	return txHash, nil
+}
+
+//refund the money locked to lockReceiver after the time lock
+func (service *MercuryFullChainService) SendRefundTransaction(from, lockReceiver common.Address, fee *big.Int, nonce *uint64) (common.Hash, error) {
+	tmpWallet, usedNonce, err := service.getSendTxInfo(from, nonce)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	tx := model.CreateRawRefundTx(usedNonce, big.NewInt(0), fee, from, lockReceiver)
+	signTx, err := service.signTxAndSend(tmpWallet, from, tx, usedNonce)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	txHash := signTx.CalTxId()
+	log.Info("the SendRefundTransaction txId is: ", "txId", txHash.Hex())
+	return txHash, nil
+}
+
+//get the locked value, hash lock and time lock between the lock sender and receiver
+func (service *MercuryFullChainService) GetLockInfo(lockSender, lockReceiver common.Address) (lockAddr common.Address, value *big.Int, hashLock common.Hash, timeLock *big.Int, err error) {
+	lockAddr = cs_crypto.GetLockAddress(lockSender, lockReceiver)
+	curState, err := service.ChainReader.CurrentState()
+	if err != nil {
+		return
+	}
+	if value, err = curState.GetBalance(lockAddr); err != nil {
+		return
+	}
+	if hashLock, err = curState.GetHashLock(lockAddr); err != nil {
+		return
+	}
+	timeLock, err = curState.GetTimeLock(lockAddr)
+	return
+}
+
+//get the headers between start and end which can be relayed to other chains
+func (service *MercuryFullChainService) GetCrossChainHeaders(start, end uint64) ([]*model.Header, error) {
+	if start > end {
+		return nil, errors.New("the start height is greater than the end height")
+	}
+
+	var headers []*model.Header
+	for i := start; i <= end; i++ {
+		block := service.ChainReader.GetBlockByNumber(i)
+		if block == nil {
+			return nil, g_error.ErrBlockNotFound
+		}
+		headers = append(headers, block.Header().(*model.Header))
+	}
+	return headers, nil
+}
+
+//get the proof of the transaction against the transaction root of its block for relaying
+func (service *MercuryFullChainService) GetCrossChainProof(txHash common.Hash) (blockHash common.Hash, blockNumber uint64, txBytes []byte, proof [][]byte, err error) {
+	blockHash, blockNumber, _, txBytes, proof, err = service.GetTransactionProof(txHash)
+	return
+}
+
+//get the proof of the transaction against the transaction root of its block
+func (service *MercuryFullChainService) GetTransactionProof(txHash common.Hash) (blockHash common.Hash, blockNumber uint64, txRoot common.Hash, txBytes []byte, proof [][]byte, err error) {
+	tx, blockHash, blockNumber, _ := service.ChainReader.GetTransaction(txHash)
+	if tx == nil {
+		return common.Hash{}, 0, common.Hash{}, nil, nil, g_error.ErrTransactionNotFound
+	}
+	block := service.ChainReader.GetBlockByHash(blockHash)
+	if block == nil {
+		return common.Hash{}, 0, common.Hash{}, nil, nil, g_error.ErrBlockNotFound
+	}
+
+	if txBytes, err = rlp.EncodeToBytes(tx); err != nil {
+		return
+	}
+	txRoot = block.TxRoot()
+	proof, err = model.DeriveProof(model.Transactions(block.GetTransactions()), txHash.Bytes())
+	return
+}
+
+//get the execution result of the mined transaction
+func (service *MercuryFullChainService) GetTransactionReceipt(txHash common.Hash) (*model.Receipt, error) {
+	receipt := service.ChainReader.GetChainDB().GetReceipt(txHash)
+	if receipt == nil {
+		return nil, g_error.ErrReceiptNotFound
+	}
+	return receipt, nil
+}
+
+// the max number of the txs returned by one address history query
+const maxAddressTxsLimit = 1000
+
+// a mined tx sent from or to the address
+type AddressTransaction struct {
+	Transaction *model.Transaction
+	BlockHash   common.Hash
+	BlockNumber uint64
+	TxIndex     uint64
+}
+
+//get the txs of the address in the blocks between fromBlock and toBlock, toBlock 0 means the current block.
+//The txs of the last block are all returned even if they exceed the limit, so the next page starts from the block after it.
+//The blocks saved before the index was enabled aren't indexed, so fromBlock can't be before the start of the index
+func (service *MercuryFullChainService) GetTransactionsByAddress(address common.Address, fromBlock, toBlock, limit uint64) ([]*AddressTransaction, error) {
+	chainDB := service.ChainReader.GetChainDB()
+	if !chainDB.AddressIndexEnabled() {
+		return nil, g_error.ErrAddressIndexDisabled
+	}
+	if fromBlock < chainDB.AddressIndexStart() {
+		return nil, g_error.ErrBlockNotInAddressIndex
+	}
+	if toBlock == 0 {
+		toBlock = service.ChainReader.CurrentBlock().Number()
+	}
+	if limit == 0 || limit > maxAddressTxsLimit {
+		limit = maxAddressTxsLimit
+	}
+
+	var txs []*AddressTransaction
+	for _, entry := range chainDB.GetAddressTxEntries(address, fromBlock, toBlock, int(limit)) {
+		tx, blockHash, blockNumber, txIndex := chainDB.GetTransaction(entry.TxHash)
+		if tx == nil {
+			return nil, g_error.ErrTransactionNotFound
+		}
+		txs = append(txs, &AddressTransaction{Transaction: tx.(*model.Transaction), BlockHash: blockHash, BlockNumber: blockNumber, TxIndex: txIndex})
+	}
+	return txs, nil
+}
+
+//get the proof of the nonce, balance and stake of the account against the state root of the block
+func (service *MercuryFullChainService) GetAccountProof(address common.Address, blockNumber uint64) (stateRoot common.Hash, proof *state_processor.AccountProof, err error) {
+	block := service.ChainReader.GetBlockByNumber(blockNumber)
+	if block == nil {
+		return common.Hash{}, nil, g_error.ErrBlockNotFound
+	}
+	state, err := service.ChainReader.StateAtByBlockNumber(blockNumber)
+	if err != nil {
+		return
+	}
+
+	if proof, err = state.GetAccountProof(address); err != nil {
+		return
+	}
+	return block.StateRoot(), proof, nil
+}
+
+//get the relayed header with the most work of the chain
+func (service *MercuryFullChainService) GetCrossChainTip(chainId *big.Int) (hash common.Hash, number uint64, err error) {
+	curState, err := service.ChainReader.CurrentState()
+	if err != nil {
+		return
+	}
+	return curState.GetCrossChainTip(chainId)
+}
+
+//lock value on this chain for the receiver on the target chain
+func (service *MercuryFullChainService) SendCrossChainLockTransaction(from, receiver common.Address, targetChainId, value, fee *big.Int, nonce *uint64) (common.Hash, error) {
+	tmpWallet, usedNonce, err := service.getSendTxInfo(from, nonce)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	tx, err := model.NewCrossChainLockTransaction(usedNonce, targetChainId, receiver, value, fee)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return service.sendCrossChainTx(tmpWallet, from, tx, usedNonce)
+}
+
+//relay the headers of the chain with chainId to this chain
+func (service *MercuryFullChainService) SendCrossChainRelayTransaction(from common.Address, chainId *big.Int, headers []*model.Header, fee *big.Int, nonce *uint64) (common.Hash, error) {
+	tmpWallet, usedNonce, err := service.getSendTxInfo(from, nonce)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	tx, err := model.NewCrossChainRelayTransaction(usedNonce, chainId, headers, fee)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return service.sendCrossChainTx(tmpWallet, from, tx, usedNonce)
+}
+
+//redeem the lock transaction of the source chain with its proof
+func (service *MercuryFullChainService) SendCrossChainRedeemTransaction(from common.Address, sourceChainId *big.Int, blockHash common.Hash, lockTx []byte, proof [][]byte, fee *big.Int, nonce *uint64) (common.Hash, error) {
+	tmpWallet, usedNonce, err := service.getSendTxInfo(from, nonce)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	tx, err := model.NewCrossChainRedeemTransaction(usedNonce, sourceChainId, blockHash, lockTx, proof, fee)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return service.sendCrossChainTx(tmpWallet, from, tx, usedNonce)
+}
+
+func (service *MercuryFullChainService) sendCrossChainTx(tmpWallet accounts.Wallet, from common.Address, tx *model.Transaction, usedNonce uint64) (common.Hash, error) {
+	signTx, err := service.signTxAndSend(tmpWallet, from, tx, usedNonce)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	txHash := signTx.CalTxId()
+	log.Info("the cross chain transaction txId is: ", "txId", txHash.Hex())
+	return txHash, nil
+}
+
+//get the key set, nonce and balance of the multi signature account
+func (service *MercuryFullChainService) GetMultiSigAccount(address common.Address) (keySet *model.MultiSigKeySet, nonce uint64, balance *big.Int, err error) {
+	curState, err := service.ChainReader.CurrentState()
+	if err != nil {
+		return
+	}
+	if keySet, err = curState.GetMultiSigKeySet(address); err != nil {
+		return
+	}
+	if nonce, err = curState.GetNonce(address); err != nil {
+		return
+	}
+	balance, err = curState.GetBalance(address)
+	return
+}
+
+//register the multi signature account of the owners and deposit the value to it
+func (service *MercuryFullChainService) SendRegisterMultiSigTransaction(from common.Address, threshold uint64, owners []common.Address, value, fee *big.Int, nonce *uint64) (common.Hash, error) {
+	tmpWallet, usedNonce, err := service.getSendTxInfo(from, nonce)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	tx, err := model.NewMultiSigRegisterTransaction(usedNonce, &model.MultiSigKeySet{Threshold: threshold, Owners: owners}, value, fee)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return service.sendMultiSigTx(tmpWallet, from, tx, usedNonce)
+}
+
+//create the proposal transferring value from the multi signature account, the owners sign the returned hash
+func (service *MercuryFullChainService) NewMultiSigProposal(multiSig, to common.Address, value *big.Int) (*model.MultiSigProposal, common.Hash, error) {
+	_, nonce, _, err := service.GetMultiSigAccount(multiSig)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	proposal := &model.MultiSigProposal{MultiSig: multiSig, Nonce: nonce, To: to, Amount: value}
+	return proposal, proposal.Hash(service.ChainConfig.ChainId), nil
+}
+
+//sign the proposal with the owner account in the wallet
+func (service *MercuryFullChainService) SignMultiSigProposal(signer common.Address, proposal model.MultiSigProposal) ([]byte, error) {
+	tmpWallet, err := service.WalletManager.FindWalletFromAddress(signer)
+	if err != nil {
+		return nil, err
+	}
+	hash := proposal.Hash(service.ChainConfig.ChainId)
+	return tmpWallet.SignHash(accounts.Account{Address: signer}, hash.Bytes())
+}
+
+//submit the proposal with the owners signatures, from pays the fee
+func (service *MercuryFullChainService) SendMultiSigTransaction(from common.Address, proposal model.MultiSigProposal, signatures [][]byte, fee *big.Int, nonce *uint64) (common.Hash, error) {
+	tmpWallet, usedNonce, err := service.getSendTxInfo(from, nonce)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	tx, err := model.NewMultiSigExecuteTransaction(usedNonce, &model.MultiSigExecuteData{Proposal: proposal, Signatures: signatures}, fee)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return service.sendMultiSigTx(tmpWallet, from, tx, usedNonce)
+}
+
+func (service *MercuryFullChainService) sendMultiSigTx(tmpWallet accounts.Wallet, from common.Address, tx *model.Transaction, usedNonce uint64) (common.Hash, error) {
+	signTx, err := service.signTxAndSend(tmpWallet, from, tx, usedNonce)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	txHash := signTx.CalTxId()
+	log.Info("the multi signature transaction txId is: ", "txId", txHash.Hex())
+	return txHash, nil
+}
+
+//get the stake of the verifier, the bonded DIP delegated to it and all its delegations
+func (service *MercuryFullChainService) GetDelegations(verifier common.Address) (stake, delegated *big.Int, delegations []model.Delegation, err error) {
+	curState, err := service.ChainReader.CurrentState()
+	if err != nil {
+		return
+	}
+	if stake, err = curState.GetStake(verifier); err != nil {
+		return
+	}
+	if delegated, err = curState.GetDelegatedStake(verifier); err != nil {
+		return
+	}
+	delegations, err = curState.GetDelegations(verifier)
+	return
+}
+
+//bond the value to the verifier
+func (service *MercuryFullChainService) SendDelegateTransaction(from, verifier common.Address, value, fee *big.Int, nonce *uint64) (common.Hash, error) {
+	tmpWallet, usedNonce, err := service.getSendTxInfo(from, nonce)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	tx := model.NewDelegateTransaction(usedNonce, verifier, value, fee)
+	signTx, err := service.signTxAndSend(tmpWallet, from, tx, usedNonce)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	txHash := signTx.CalTxId()
+	log.Info("the SendDelegateTransaction txId is: ", "txId", txHash.Hex())
+	return txHash, nil
+}
+
+//start unbonding the delegation to the verifier, send it again after the stake lock slots to get the DIP back
+func (service *MercuryFullChainService) SendUnDelegateTransaction(from, verifier common.Address, fee *big.Int, nonce *uint64) (common.Hash, error) {
+	tmpWallet, usedNonce, err := service.getSendTxInfo(from, nonce)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	tx := model.NewUnDelegateTransaction(usedNonce, verifier, fee)
+	signTx, err := service.signTxAndSend(tmpWallet, from, tx, usedNonce)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	txHash := signTx.CalTxId()
+	log.Info("the SendUnDelegateTransaction txId is: ", "txId", txHash.Hex())
+	return txHash, nil
+}
+
 //get address nonce from chain
 func (service *MercuryFullChainService) GetTransactionNonce(addr common.Address) (nonce uint64, err error) {
 	state, err := service.ChainReader.CurrentState()
@@ -994,6 +1491,30 @@ func (service *MercuryFullChainService) StopMine() error {
 	return nil
 }
 
+// get the statistics of the workers registered to the mine master
+func (service *MercuryFullChainService) GetMineWorkers() ([]minemaster.WorkerStats, error) {
+	if service.MineMaster == nil {
+		return nil, errors.New("current node is not mine master")
+	}
+	return service.MineMaster.WorkerStats(), nil
+}
+
+// stop the worker and remove it from the mine master
+func (service *MercuryFullChainService) KickMineWorker(workerId minemaster.WorkerId) error {
+	if service.MineMaster == nil {
+		return errors.New("current node is not mine master")
+	}
+	return service.MineMaster.KickWorker(workerId)
+}
+
+// change the coinbase which the rewards of the worker are accounted to
+func (service *MercuryFullChainService) SetMineWorkerCoinbase(workerId minemaster.WorkerId, coinbase common.Address) error {
+	if service.MineMaster == nil {
+		return errors.New("current node is not mine master")
+	}
+	return service.MineMaster.SetWorkerCoinbase(workerId, coinbase)
+}
+
 // check if is mining
 func (service *MercuryFullChainService) Mining() bool {
 	if service.MineMaster != nil {
@@ -1419,17 +1940,7 @@ func (service *MercuryFullChainService) SubscribeBlock(ctx context.Context) (*rp
 			case b := <-blockCh:
 				var respTxs []*SubBlockTxResp
 				_ = b.TxIterator(func(i int, transaction model.AbstractTransaction) error {
-					from, _ := transaction.Sender(nil)
-					respTxs = append(respTxs, &SubBlockTxResp{
-						TxID:         transaction.CalTxId(),
-						From:         from,
-						AccountNonce: transaction.Nonce(),
-						Recipient:    transaction.To(),
-						Amount:       transaction.Amount(),
-						Fee:          transaction.Fee(),
-						ExtraData:    transaction.ExtraData(),
-						ExtraDataStr: hexutil.Encode(transaction.ExtraData()),
-					})
+					respTxs = append(respTxs, newSubBlockTxResp(transaction))
 					return nil
 				})
 
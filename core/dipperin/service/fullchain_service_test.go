@@ -18,16 +18,21 @@ package service
 
 import (
 	"context"
+	"errors"
 	"github.com/dipperin/dipperin-core/common"
 	"github.com/dipperin/dipperin-core/common/util"
 	"github.com/dipperin/dipperin-core/core/accounts"
+	"github.com/dipperin/dipperin-core/core/accounts/hard-wallet"
 	"github.com/dipperin/dipperin-core/core/accounts/soft-wallet"
 	"github.com/dipperin/dipperin-core/core/chain"
 	"github.com/dipperin/dipperin-core/core/chain-communication"
 	"github.com/dipperin/dipperin-core/core/chain-config"
+	"github.com/dipperin/dipperin-core/core/chain/chaindb"
+	"github.com/dipperin/dipperin-core/core/chain/state-processor"
 	contract2 "github.com/dipperin/dipperin-core/core/contract"
 	"github.com/dipperin/dipperin-core/core/economy-model"
 	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/core/tx-pool"
 	"github.com/dipperin/dipperin-core/tests"
 	"github.com/dipperin/dipperin-core/third-party/p2p"
 	"github.com/stretchr/testify/assert"
@@ -37,6 +42,7 @@ import (
 	"time"
 	"github.com/dipperin/dipperin-core/common/g-error"
 	"github.com/dipperin/dipperin-core/third-party/crypto"
+	"github.com/dipperin/dipperin-core/third-party/crypto/cs-crypto"
 )
 
 var testFee = economy_model.GetMinimumTxFee(1000)
@@ -161,6 +167,19 @@ func TestMercuryFullChainService_CurrentBlock(t *testing.T) {
 	assert.Equal(t, uint64(0), service.CurrentBlock().Number())
 }
 
+func TestMercuryFullChainService_CurrentLightHeader(t *testing.T) {
+	csChain := createCsChain(nil)
+	service := MakeFullChainService(&DipperinConfig{ChainReader: csChain})
+	_, err := service.CurrentLightHeader()
+	assert.Error(t, err)
+
+	genesis := csChain.CurrentBlock().Header().(*model.Header)
+	service.LightChain = chain_communication.NewLightHeaderChain(genesis)
+	header, err := service.CurrentLightHeader()
+	assert.NoError(t, err)
+	assert.Equal(t, genesis.Hash(), header.Hash())
+}
+
 func TestMercuryFullChainService_CurrentStake(t *testing.T) {
 	csChain := createCsChain(nil)
 	config := &DipperinConfig{ChainReader: csChain}
@@ -461,7 +480,13 @@ func TestMakeFullChainService_checkWalletIdentifier(t *testing.T) {
 	}
 
 	err := service.checkWalletIdentifier(identifier)
-	assert.Error(t, err)
+	assert.NoError(t, err)
+	assert.Equal(t, accounts.WalletIdentifier{WalletType: accounts.LedgerWallet}, *identifier)
+
+	chain_config.GetChainConfig().SupportHardwareWallet = false
+	err = service.checkWalletIdentifier(identifier)
+	assert.Equal(t, accounts.ErrNotSupportUsbWallet, err)
+	chain_config.GetChainConfig().SupportHardwareWallet = true
 
 	identifier = &accounts.WalletIdentifier{
 		WalletType: accounts.SoftWallet,
@@ -469,6 +494,41 @@ func TestMakeFullChainService_checkWalletIdentifier(t *testing.T) {
 
 	err = service.checkWalletIdentifier(identifier)
 	assert.NoError(t, err)
+
+	identifier = &accounts.WalletIdentifier{
+		WalletType: 123,
+	}
+	err = service.checkWalletIdentifier(identifier)
+	assert.Equal(t, "wallet type error", err.Error())
+}
+
+type emptyHidEnumerator struct{}
+
+func (emptyHidEnumerator) Enumerate(vendorID uint16) ([]hard_wallet.DeviceInfo, error) {
+	return nil, nil
+}
+
+func (emptyHidEnumerator) Open(info hard_wallet.DeviceInfo) (hard_wallet.Device, error) {
+	return nil, errors.New("no device")
+}
+
+func TestMercuryFullChainService_HardWallet(t *testing.T) {
+	hard_wallet.SetEnumerator(emptyHidEnumerator{})
+	manager := createWalletManager(t)
+	defer os.Remove(util.HomeDir() + testPath)
+	service := MakeFullChainService(&DipperinConfig{NodeConf: fakeNodeConfig{}, WalletManager: manager})
+
+	identifier := accounts.WalletIdentifier{WalletType: accounts.TrezorWallet}
+	err := service.OpenWallet(identifier, "1234")
+	assert.EqualError(t, err, "no hardware wallet device found")
+
+	_, err = service.EstablishWallet(identifier, "123", "")
+	assert.Equal(t, accounts.ErrNotSupported, err)
+	err = service.RestoreWallet(identifier, "123", "", "mnemonic")
+	assert.Equal(t, accounts.ErrNotSupported, err)
+
+	_, err = service.ListWalletAccount(identifier)
+	assert.Equal(t, accounts.ErrNotFindWallet, err)
 }
 
 func TestMercuryFullChainService_GetVerifierReward(t *testing.T) {
@@ -781,6 +841,23 @@ func TestMercuryFullChainService_StopMine(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestMercuryFullChainService_GetMineWorkers(t *testing.T) {
+	service := MakeFullChainService(&DipperinConfig{})
+	_, err := service.GetMineWorkers()
+	assert.Error(t, err)
+	assert.Error(t, service.KickMineWorker("worker"))
+	assert.Error(t, service.SetMineWorkerCoinbase("worker", aliceAddr))
+
+	service = MakeFullChainService(&DipperinConfig{MineMaster: fakeMaster{}})
+	workers, err := service.GetMineWorkers()
+	assert.NoError(t, err)
+	assert.Len(t, workers, 1)
+	assert.Equal(t, aliceAddr, workers[0].Coinbase)
+	assert.NoError(t, service.KickMineWorker("worker"))
+	assert.Error(t, service.KickMineWorker("unknown"))
+	assert.NoError(t, service.SetMineWorkerCoinbase("worker", common.HexToAddress("0x1234")))
+}
+
 func TestMercuryFullChainService_SetMineCoinBase(t *testing.T) {
 	config := &DipperinConfig{
 		NodeConf: &fakeNodeConfig{nodeType: chain_config.NodeTypeOfVerifier},
@@ -1065,6 +1142,439 @@ func TestMercuryFullChainService_SendTransaction(t *testing.T) {
 	assert.Equal(t, common.Hash{}, hash)
 }
 
+func TestMercuryFullChainService_SendLockTransaction(t *testing.T) {
+	manager := createWalletManager(t)
+	defer os.Remove(util.HomeDir() + testPath)
+	account, err := manager.Wallets[0].Accounts()
+	assert.NoError(t, err)
+
+	address := account[0].Address
+	pk, err := manager.Wallets[0].GetSKFromAddress(address)
+	testAccount := tests.NewAccount(pk, address)
+	testAccounts := []tests.Account{*testAccount}
+
+	serviceChain := createCsChainService(testAccounts)
+	txPool := createTxPool(serviceChain.ChainState)
+	serviceChain.TxPool = txPool
+
+	broadcaster := chain_communication.NewBroadcastDelegate(txPool, fakeNodeConfig{}, fakePeerManager{}, serviceChain, fakePbftNode{})
+	config := &DipperinConfig{
+		NodeConf:      fakeNodeConfig{nodeType: chain_config.NodeTypeOfNormal},
+		WalletManager: manager,
+		ChainReader:   serviceChain,
+		TxPool:        txPool,
+		ChainConfig:   *chain_config.GetChainConfig(),
+		Broadcaster:   broadcaster,
+	}
+
+	service := MercuryFullChainService{
+		DipperinConfig: config,
+		TxValidator:    fakeValidator{},
+	}
+
+	nonce := uint64(0)
+	hashLock := cs_crypto.Keccak256Hash([]byte("hash key"))
+	hash, err := service.SendLockTransaction(address, aliceAddr, hashLock, big.NewInt(10), big.NewInt(100), testFee, &nonce)
+	assert.NoError(t, err)
+	assert.NotEqual(t, common.Hash{}, hash)
+
+	nonce = uint64(1)
+	hash, err = service.SendClaimTransaction(address, aliceAddr, []byte("hash key"), testFee, &nonce)
+	assert.NoError(t, err)
+	assert.NotEqual(t, common.Hash{}, hash)
+
+	nonce = uint64(2)
+	hash, err = service.SendRefundTransaction(address, aliceAddr, testFee, &nonce)
+	assert.NoError(t, err)
+	assert.NotEqual(t, common.Hash{}, hash)
+
+	// the lock tx is not packaged yet
+	lockAddr, _, _, _, err := service.GetLockInfo(address, aliceAddr)
+	assert.Equal(t, g_error.AccountNotExist, err)
+	assert.Equal(t, cs_crypto.GetLockAddress(address, aliceAddr), lockAddr)
+
+	// getSendTxInfo error
+	fakeAddr := common.HexToAddress("123")
+	hash, err = service.SendLockTransaction(fakeAddr, aliceAddr, hashLock, big.NewInt(10), big.NewInt(100), testFee, &nonce)
+	assert.Equal(t, accounts.ErrNotFindWallet, err)
+	assert.Equal(t, common.Hash{}, hash)
+
+	hash, err = service.SendClaimTransaction(fakeAddr, aliceAddr, []byte("hash key"), testFee, &nonce)
+	assert.Equal(t, accounts.ErrNotFindWallet, err)
+	assert.Equal(t, common.Hash{}, hash)
+
+	hash, err = service.SendRefundTransaction(fakeAddr, aliceAddr, testFee, &nonce)
+	assert.Equal(t, accounts.ErrNotFindWallet, err)
+	assert.Equal(t, common.Hash{}, hash)
+
+	// signTxAndSend error
+	service.TxValidator = fakeValidator{err: testErr}
+	hash, err = service.SendLockTransaction(address, aliceAddr, hashLock, big.NewInt(10), big.NewInt(100), testFee, &nonce)
+	assert.Equal(t, testErr, err)
+	assert.Equal(t, common.Hash{}, hash)
+
+	hash, err = service.SendClaimTransaction(address, aliceAddr, []byte("hash key"), testFee, &nonce)
+	assert.Equal(t, testErr, err)
+	assert.Equal(t, common.Hash{}, hash)
+
+	hash, err = service.SendRefundTransaction(address, aliceAddr, testFee, &nonce)
+	assert.Equal(t, testErr, err)
+	assert.Equal(t, common.Hash{}, hash)
+}
+
+func TestMercuryFullChainService_GetCrossChainProof(t *testing.T) {
+	csChain := createCsChain(nil)
+	tx := createSignedTx(0, aliceAddr, big.NewInt(1000), []byte{})
+	block := createBlock(csChain, []*model.Transaction{tx}, nil)
+	votes := createVerifiersVotes(block, csChain.ChainConfig.VerifierNumber)
+	assert.NoError(t, csChain.SaveBftBlock(block, votes))
+
+	config := &DipperinConfig{ChainReader: csChain}
+	service := MakeFullChainService(config)
+
+	headers, err := service.GetCrossChainHeaders(0, 1)
+	assert.NoError(t, err)
+	assert.Len(t, headers, 2)
+	assert.Equal(t, block.Hash(), headers[1].Hash())
+
+	_, err = service.GetCrossChainHeaders(1, 0)
+	assert.Error(t, err)
+
+	_, err = service.GetCrossChainHeaders(0, 5)
+	assert.Equal(t, g_error.ErrBlockNotFound, err)
+
+	blockHash, blockNumber, txBytes, proof, err := service.GetCrossChainProof(tx.CalTxId())
+	assert.NoError(t, err)
+	assert.Equal(t, block.Hash(), blockHash)
+	assert.Equal(t, uint64(1), blockNumber)
+	value, err := model.VerifyDeriveProof(headers[1].TransactionRoot, tx.CalTxId().Bytes(), proof)
+	assert.NoError(t, err)
+	assert.Equal(t, txBytes, value)
+
+	_, _, _, _, err = service.GetCrossChainProof(common.Hash{})
+	assert.Equal(t, g_error.ErrTransactionNotFound, err)
+
+	_, _, err = service.GetCrossChainTip(big.NewInt(2))
+	assert.Equal(t, state_processor.CrossChainCheckpointErr, err)
+}
+
+func TestMercuryFullChainService_GetTransactionProof(t *testing.T) {
+	csChain := createCsChain(nil)
+	tx := createSignedTx(0, aliceAddr, big.NewInt(1000), []byte{})
+	block := createBlock(csChain, []*model.Transaction{tx}, nil)
+	votes := createVerifiersVotes(block, csChain.ChainConfig.VerifierNumber)
+	assert.NoError(t, csChain.SaveBftBlock(block, votes))
+
+	config := &DipperinConfig{ChainReader: csChain}
+	service := MakeFullChainService(config)
+
+	blockHash, blockNumber, txRoot, txBytes, proof, err := service.GetTransactionProof(tx.CalTxId())
+	assert.NoError(t, err)
+	assert.Equal(t, block.Hash(), blockHash)
+	assert.Equal(t, uint64(1), blockNumber)
+	assert.Equal(t, block.TxRoot(), txRoot)
+	provedTx, err := model.VerifyTransactionProof(txRoot, txBytes, proof)
+	assert.NoError(t, err)
+	assert.Equal(t, tx.CalTxId(), provedTx.CalTxId())
+
+	_, _, _, _, _, err = service.GetTransactionProof(common.Hash{})
+	assert.Equal(t, g_error.ErrTransactionNotFound, err)
+}
+
+func TestMercuryFullChainService_GetTransactionReceipt(t *testing.T) {
+	csChain := createCsChain(nil)
+	contractAddr := common.HexToAddress("0x00102B9aE8e4a04B3d2Dc8a60D2E9E4E0A60A5b7aA30")
+	extraData := util.StringifyJsonToBytes(contract2.ExtraDataForContract{ContractAddress: contractAddr, Action: "Transfer", Params: util.StringifyJson([]string{aliceAddr.Hex(), "0x1"})})
+	tx := createSignedTx(0, aliceAddr, big.NewInt(1000), []byte{})
+	contractTx := createSignedTx(1, contractAddr, big.NewInt(0), extraData)
+	block := createBlock(csChain, []*model.Transaction{tx, contractTx}, nil)
+	votes := createVerifiersVotes(block, csChain.ChainConfig.VerifierNumber)
+	assert.NoError(t, csChain.SaveBftBlock(block, votes))
+
+	config := &DipperinConfig{ChainReader: csChain}
+	service := MakeFullChainService(config)
+
+	receipt, err := service.GetTransactionReceipt(tx.CalTxId())
+	assert.NoError(t, err)
+	assert.False(t, receipt.Failed())
+	assert.Equal(t, block.Hash(), receipt.BlockHash)
+	assert.Equal(t, uint64(1), receipt.BlockNumber)
+	assert.Equal(t, tx.Fee(), receipt.Fee)
+
+	// the failed token transfer is mined with its error
+	receipt, err = service.GetTransactionReceipt(contractTx.CalTxId())
+	assert.NoError(t, err)
+	assert.True(t, receipt.Failed())
+	assert.NotEmpty(t, receipt.Err)
+	assert.Equal(t, uint64(1), receipt.TxIndex)
+
+	_, err = service.GetTransactionReceipt(common.Hash{})
+	assert.Equal(t, g_error.ErrReceiptNotFound, err)
+}
+
+func TestMercuryFullChainService_GetTransactionsByAddress(t *testing.T) {
+	csChain := createCsChain(nil)
+	config := &DipperinConfig{ChainReader: csChain}
+	service := MakeFullChainService(config)
+
+	_, err := service.GetTransactionsByAddress(aliceAddr, 0, 0, 0)
+	assert.Equal(t, g_error.ErrAddressIndexDisabled, err)
+
+	csChain.ChainDB.(*chaindb.ChainDB).EnableAddressIndex()
+	contractAddr := common.HexToAddress("0x00102B9aE8e4a04B3d2Dc8a60D2E9E4E0A60A5b7aA30")
+	tokenReceiver := common.HexToAddress("0x0000b4293d60F051936beDecfaE1B85d5A46d377aF37")
+	extraData := util.StringifyJsonToBytes(contract2.ExtraDataForContract{ContractAddress: contractAddr, Action: "Transfer", Params: util.StringifyJson([]string{tokenReceiver.Hex(), "0x1"})})
+	tx := createSignedTx(0, aliceAddr, big.NewInt(1000), []byte{})
+	contractTx := createSignedTx(1, contractAddr, big.NewInt(0), extraData)
+	block := createBlock(csChain, []*model.Transaction{tx, contractTx}, nil)
+	votes := createVerifiersVotes(block, csChain.ChainConfig.VerifierNumber)
+	assert.NoError(t, csChain.SaveBftBlock(block, votes))
+
+	// the genesis block was saved before the index was enabled
+	sender, _ := tx.Sender(nil)
+	_, err = service.GetTransactionsByAddress(sender, 0, 0, 0)
+	assert.Equal(t, g_error.ErrBlockNotInAddressIndex, err)
+
+	txs, err := service.GetTransactionsByAddress(sender, 1, 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, txs, 2)
+	assert.Equal(t, contractTx.CalTxId(), txs[1].Transaction.CalTxId())
+	assert.Equal(t, block.Hash(), txs[1].BlockHash)
+	assert.Equal(t, uint64(1), txs[1].BlockNumber)
+	assert.Equal(t, uint64(1), txs[1].TxIndex)
+
+	// the ERC20 token receiver
+	txs, err = service.GetTransactionsByAddress(tokenReceiver, 1, 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, txs, 1)
+	assert.Equal(t, contractTx.CalTxId(), txs[0].Transaction.CalTxId())
+
+	txs, err = service.GetTransactionsByAddress(sender, 2, 10, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, txs)
+}
+
+func TestMercuryFullChainService_GetAccountProof(t *testing.T) {
+	csChain := createCsChain(nil)
+	tx := createSignedTx(0, aliceAddr, big.NewInt(1000), []byte{})
+	block := createBlock(csChain, []*model.Transaction{tx}, nil)
+	votes := createVerifiersVotes(block, csChain.ChainConfig.VerifierNumber)
+	assert.NoError(t, csChain.SaveBftBlock(block, votes))
+
+	config := &DipperinConfig{ChainReader: csChain}
+	service := MakeFullChainService(config)
+
+	stateRoot, proof, err := service.GetAccountProof(aliceAddr, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, block.StateRoot(), stateRoot)
+	curState, _ := csChain.CurrentState()
+	balance, _ := curState.GetBalance(aliceAddr)
+	assert.Equal(t, balance, proof.Balance)
+	assert.NoError(t, state_processor.VerifyAccountProof(stateRoot, proof))
+
+	// the proof is not valid for the genesis state
+	genesis := csChain.GetBlockByNumber(0)
+	assert.Equal(t, state_processor.AccountProofErr, state_processor.VerifyAccountProof(genesis.StateRoot(), proof))
+
+	_, _, err = service.GetAccountProof(aliceAddr, 5)
+	assert.Equal(t, g_error.ErrBlockNotFound, err)
+
+	_, _, err = service.GetAccountProof(common.HexToAddress("0x1234"), 1)
+	assert.Equal(t, g_error.AccountNotExist, err)
+}
+
+func TestMercuryFullChainService_SendCrossChainTransaction(t *testing.T) {
+	manager := createWalletManager(t)
+	defer os.Remove(util.HomeDir() + testPath)
+	account, err := manager.Wallets[0].Accounts()
+	assert.NoError(t, err)
+
+	address := account[0].Address
+	pk, err := manager.Wallets[0].GetSKFromAddress(address)
+	testAccount := tests.NewAccount(pk, address)
+	testAccounts := []tests.Account{*testAccount}
+
+	serviceChain := createCsChainService(testAccounts)
+	txPool := createTxPool(serviceChain.ChainState)
+	serviceChain.TxPool = txPool
+
+	broadcaster := chain_communication.NewBroadcastDelegate(txPool, fakeNodeConfig{}, fakePeerManager{}, serviceChain, fakePbftNode{})
+	config := &DipperinConfig{
+		NodeConf:      fakeNodeConfig{nodeType: chain_config.NodeTypeOfNormal},
+		WalletManager: manager,
+		ChainReader:   serviceChain,
+		TxPool:        txPool,
+		ChainConfig:   *chain_config.GetChainConfig(),
+		Broadcaster:   broadcaster,
+	}
+
+	service := MercuryFullChainService{
+		DipperinConfig: config,
+		TxValidator:    fakeValidator{},
+	}
+
+	nonce := uint64(0)
+	hash, err := service.SendCrossChainLockTransaction(address, aliceAddr, big.NewInt(2), big.NewInt(100), testFee, &nonce)
+	assert.NoError(t, err)
+	assert.NotEqual(t, common.Hash{}, hash)
+
+	nonce = uint64(1)
+	headers, err := service.GetCrossChainHeaders(0, 0)
+	assert.NoError(t, err)
+	hash, err = service.SendCrossChainRelayTransaction(address, big.NewInt(2), headers, testFee, &nonce)
+	assert.NoError(t, err)
+	assert.NotEqual(t, common.Hash{}, hash)
+
+	nonce = uint64(2)
+	hash, err = service.SendCrossChainRedeemTransaction(address, big.NewInt(2), headers[0].Hash(), []byte{1}, [][]byte{{1}}, testFee, &nonce)
+	assert.NoError(t, err)
+	assert.NotEqual(t, common.Hash{}, hash)
+
+	// getSendTxInfo error
+	fakeAddr := common.HexToAddress("123")
+	hash, err = service.SendCrossChainLockTransaction(fakeAddr, aliceAddr, big.NewInt(2), big.NewInt(100), testFee, &nonce)
+	assert.Equal(t, accounts.ErrNotFindWallet, err)
+	assert.Equal(t, common.Hash{}, hash)
+
+	hash, err = service.SendCrossChainRelayTransaction(fakeAddr, big.NewInt(2), headers, testFee, &nonce)
+	assert.Equal(t, accounts.ErrNotFindWallet, err)
+	assert.Equal(t, common.Hash{}, hash)
+
+	hash, err = service.SendCrossChainRedeemTransaction(fakeAddr, big.NewInt(2), headers[0].Hash(), []byte{1}, nil, testFee, &nonce)
+	assert.Equal(t, accounts.ErrNotFindWallet, err)
+	assert.Equal(t, common.Hash{}, hash)
+
+	// signTxAndSend error
+	service.TxValidator = fakeValidator{err: testErr}
+	hash, err = service.SendCrossChainLockTransaction(address, aliceAddr, big.NewInt(2), big.NewInt(100), testFee, &nonce)
+	assert.Equal(t, testErr, err)
+	assert.Equal(t, common.Hash{}, hash)
+}
+
+func TestMercuryFullChainService_SendMultiSigTransaction(t *testing.T) {
+	manager := createWalletManager(t)
+	defer os.Remove(util.HomeDir() + testPath)
+	account, err := manager.Wallets[0].Accounts()
+	assert.NoError(t, err)
+
+	address := account[0].Address
+	pk, err := manager.Wallets[0].GetSKFromAddress(address)
+	testAccount := tests.NewAccount(pk, address)
+	testAccounts := []tests.Account{*testAccount}
+
+	serviceChain := createCsChainService(testAccounts)
+	txPool := createTxPool(serviceChain.ChainState)
+	serviceChain.TxPool = txPool
+
+	broadcaster := chain_communication.NewBroadcastDelegate(txPool, fakeNodeConfig{}, fakePeerManager{}, serviceChain, fakePbftNode{})
+	config := &DipperinConfig{
+		NodeConf:      fakeNodeConfig{nodeType: chain_config.NodeTypeOfNormal},
+		WalletManager: manager,
+		ChainReader:   serviceChain,
+		TxPool:        txPool,
+		ChainConfig:   *chain_config.GetChainConfig(),
+		Broadcaster:   broadcaster,
+	}
+
+	service := MercuryFullChainService{
+		DipperinConfig: config,
+		TxValidator:    fakeValidator{},
+	}
+
+	owners := []common.Address{address, aliceAddr}
+	multiSig := (&model.MultiSigKeySet{Threshold: 2, Owners: owners}).Address()
+	nonce := uint64(0)
+	hash, err := service.SendRegisterMultiSigTransaction(address, 2, owners, big.NewInt(100), testFee, &nonce)
+	assert.NoError(t, err)
+	assert.NotEqual(t, common.Hash{}, hash)
+
+	_, err = service.SendRegisterMultiSigTransaction(address, 3, owners, big.NewInt(100), testFee, &nonce)
+	assert.Equal(t, model.MultiSigKeySetErr, err)
+
+	// the register tx is not mined
+	_, _, _, err = service.GetMultiSigAccount(multiSig)
+	assert.Equal(t, g_error.AccountNotExist, err)
+	_, _, err = service.NewMultiSigProposal(multiSig, aliceAddr, big.NewInt(10))
+	assert.Equal(t, g_error.AccountNotExist, err)
+
+	proposal := model.MultiSigProposal{MultiSig: multiSig, Nonce: 0, To: aliceAddr, Amount: big.NewInt(10)}
+	sig, err := service.SignMultiSigProposal(address, proposal)
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Address{address}, proposal.Signers(service.ChainConfig.ChainId, [][]byte{sig}))
+
+	_, err = service.SignMultiSigProposal(aliceAddr, proposal)
+	assert.Equal(t, accounts.ErrNotFindWallet, err)
+
+	nonce = uint64(1)
+	_, err = service.SendMultiSigTransaction(address, proposal, [][]byte{sig}, testFee, &nonce)
+	assert.Equal(t, g_error.AccountNotExist, err)
+
+	hash, err = service.SendMultiSigTransaction(common.HexToAddress("123"), proposal, [][]byte{sig}, testFee, &nonce)
+	assert.Equal(t, accounts.ErrNotFindWallet, err)
+	assert.Equal(t, common.Hash{}, hash)
+}
+
+func TestMercuryFullChainService_SendDelegateTransaction(t *testing.T) {
+	manager := createWalletManager(t)
+	defer os.Remove(util.HomeDir() + testPath)
+	account, err := manager.Wallets[0].Accounts()
+	assert.NoError(t, err)
+
+	address := account[0].Address
+	pk, err := manager.Wallets[0].GetSKFromAddress(address)
+	testAccount := tests.NewAccount(pk, address)
+	testAccounts := []tests.Account{*testAccount}
+
+	serviceChain := createCsChainService(testAccounts)
+	txPool := createTxPool(serviceChain.ChainState)
+	serviceChain.TxPool = txPool
+
+	broadcaster := chain_communication.NewBroadcastDelegate(txPool, fakeNodeConfig{}, fakePeerManager{}, serviceChain, fakePbftNode{})
+	config := &DipperinConfig{
+		NodeConf:      fakeNodeConfig{nodeType: chain_config.NodeTypeOfNormal},
+		WalletManager: manager,
+		ChainReader:   serviceChain,
+		TxPool:        txPool,
+		ChainConfig:   *chain_config.GetChainConfig(),
+		Broadcaster:   broadcaster,
+	}
+
+	service := MercuryFullChainService{
+		DipperinConfig: config,
+		TxValidator:    fakeValidator{},
+	}
+
+	// alice isn't a verifier
+	nonce := uint64(0)
+	_, err = service.SendDelegateTransaction(address, aliceAddr, big.NewInt(100), testFee, &nonce)
+	assert.Equal(t, state_processor.DelegateNotVerifierErr, err)
+	_, err = service.SendUnDelegateTransaction(address, aliceAddr, testFee, &nonce)
+	assert.Equal(t, state_processor.DelegationNotExistErr, err)
+
+	stake, delegated, delegations, err := service.GetDelegations(address)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(0), stake)
+	assert.Equal(t, big.NewInt(0), delegated)
+	assert.Len(t, delegations, 0)
+	_, _, _, err = service.GetDelegations(aliceAddr)
+	assert.Equal(t, g_error.AccountNotExist, err)
+
+	// the pool accepts the tx
+	service.TxPool = fakeTxPool{}
+	hash, err := service.SendDelegateTransaction(address, aliceAddr, big.NewInt(100), testFee, &nonce)
+	assert.NoError(t, err)
+	assert.NotEqual(t, common.Hash{}, hash)
+	nonce = uint64(1)
+	hash, err = service.SendUnDelegateTransaction(address, aliceAddr, testFee, &nonce)
+	assert.NoError(t, err)
+	assert.NotEqual(t, common.Hash{}, hash)
+
+	_, err = service.SendDelegateTransaction(common.HexToAddress("123"), aliceAddr, big.NewInt(100), testFee, &nonce)
+	assert.Equal(t, accounts.ErrNotFindWallet, err)
+	_, err = service.SendUnDelegateTransaction(common.HexToAddress("123"), aliceAddr, testFee, &nonce)
+	assert.Equal(t, accounts.ErrNotFindWallet, err)
+}
+
 func TestMercuryFullChainService_SendTransaction_Error(t *testing.T) {
 	manager := createWalletManager(t)
 	defer os.Remove(util.HomeDir() + testPath)
@@ -1221,4 +1731,81 @@ func TestMercuryFullChainService_Metrics(t *testing.T) {
 	service.Metrics(false)
 	service.NewBlock(context.Background())
 	service.SubscribeBlock(context.Background())
-}
\ No newline at end of file
+}
+func TestMercuryFullChainService_ReplaceTransaction(t *testing.T) {
+	manager := createWalletManager(t)
+	defer os.Remove(util.HomeDir() + testPath)
+	account, err := manager.Wallets[0].Accounts()
+	assert.NoError(t, err)
+
+	address := account[0].Address
+	pk, err := manager.Wallets[0].GetSKFromAddress(address)
+	testAccount := tests.NewAccount(pk, address)
+	testAccounts := []tests.Account{*testAccount}
+
+	serviceChain := createCsChainService(testAccounts)
+	txPool := createTxPool(serviceChain.ChainState)
+	serviceChain.TxPool = txPool
+
+	broadcaster := chain_communication.NewBroadcastDelegate(txPool, fakeNodeConfig{}, fakePeerManager{}, serviceChain, fakePbftNode{})
+	config := &DipperinConfig{
+		NodeConf:      fakeNodeConfig{nodeType: chain_config.NodeTypeOfNormal},
+		WalletManager: manager,
+		ChainReader:   serviceChain,
+		TxPool:        txPool,
+		ChainConfig:   *chain_config.GetChainConfig(),
+		Broadcaster:   broadcaster,
+	}
+
+	service := MercuryFullChainService{
+		DipperinConfig: config,
+		TxValidator:    fakeValidator{},
+	}
+
+	_, err = service.ReplaceTransaction(address, 0, testFee)
+	assert.EqualError(t, err, "the transaction isn't in the tx pool")
+
+	nonce := uint64(0)
+	oldHash, err := service.SendTransaction(address, aliceAddr, big.NewInt(100), testFee, nil, &nonce)
+	assert.NoError(t, err)
+	nonce = uint64(2)
+	queuedHash, err := service.SendTransaction(address, aliceAddr, big.NewInt(100), testFee, nil, &nonce)
+	assert.NoError(t, err)
+
+	pending, queued := service.GetPoolContent(address)
+	assert.Len(t, pending, 1)
+	assert.Equal(t, oldHash, pending[0].CalTxId())
+	assert.Len(t, queued, 1)
+	assert.Equal(t, queuedHash, queued[0].Tx.CalTxId())
+	assert.Equal(t, tx_pool.QueuedNonceGap, queued[0].Reason)
+
+	// the fee bump is not enough
+	_, err = service.ReplaceTransaction(address, 0, new(big.Int).Add(testFee, big.NewInt(1)))
+	assert.Equal(t, tx_pool.ErrReplaceUnderpriced, err)
+
+	newFee := new(big.Int).Mul(testFee, big.NewInt(2))
+	newHash, err := service.ReplaceTransaction(address, 0, newFee)
+	assert.NoError(t, err)
+	pending, _ = service.GetPoolContent(address)
+	assert.Len(t, pending, 1)
+	assert.Equal(t, newHash, pending[0].CalTxId())
+	assert.Equal(t, newFee, pending[0].Fee())
+	assert.Equal(t, aliceAddr, *pending[0].To())
+	assert.Equal(t, big.NewInt(100), pending[0].Amount())
+
+	cancelHash, err := service.CancelPoolTransaction(address, 2, newFee)
+	assert.NoError(t, err)
+	_, queued = service.GetPoolContent(address)
+	assert.Len(t, queued, 1)
+	assert.Equal(t, cancelHash, queued[0].Tx.CalTxId())
+	assert.Equal(t, address, *queued[0].Tx.To())
+	assert.Equal(t, big.NewInt(0), queued[0].Tx.Amount())
+
+	_, err = service.CancelPoolTransaction(address, 1, newFee)
+	assert.EqualError(t, err, "the transaction isn't in the tx pool")
+
+	// the wallet nonce isn't changed by the replacement
+	walletNonce, err := service.GetAddressNonceFromWallet(address)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), walletNonce)
+}
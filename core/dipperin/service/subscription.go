@@ -0,0 +1,227 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"context"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/g-event"
+	"github.com/dipperin/dipperin-core/common/hexutil"
+	"github.com/dipperin/dipperin-core/core/contract"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/core/tx-pool"
+	"github.com/dipperin/dipperin-core/third-party/log"
+	"github.com/dipperin/dipperin-core/third-party/rpc"
+	"math/big"
+)
+
+// mined transaction touching the subscribed address
+type SubAddressTxResp struct {
+	BlockNumber uint64          `json:"block_number"`
+	BlockHash   common.Hash     `json:"block_hash"`
+	Transaction *SubBlockTxResp `json:"transaction"`
+}
+
+// mined ERC20 transfer or approve, From is the token owner and To is the receiver or spender
+type SubERC20Resp struct {
+	BlockNumber uint64         `json:"block_number"`
+	BlockHash   common.Hash    `json:"block_hash"`
+	TxID        common.Hash    `json:"tx_id"`
+	Contract    common.Address `json:"contract"`
+	Action      string         `json:"action"`
+	From        common.Address `json:"from"`
+	To          common.Address `json:"to"`
+	Value       *big.Int       `json:"value"`
+}
+
+// verifiers of the next round calculated at the change point
+type SubVerifiersResp struct {
+	BlockNumber uint64           `json:"block_number"`
+	BlockHash   common.Hash      `json:"block_hash"`
+	Verifiers   []common.Address `json:"verifiers"`
+}
+
+// block removed by the rollback
+type SubRollbackResp struct {
+	Number uint64      `json:"number"`
+	Hash   common.Hash `json:"hash"`
+}
+
+func newSubBlockTxResp(tx model.AbstractTransaction) *SubBlockTxResp {
+	from, _ := tx.Sender(nil)
+	return &SubBlockTxResp{
+		TxID:         tx.CalTxId(),
+		From:         from,
+		AccountNonce: tx.Nonce(),
+		Recipient:    tx.To(),
+		Amount:       tx.Amount(),
+		Fee:          tx.Fee(),
+		ExtraData:    tx.ExtraData(),
+		ExtraDataStr: hexutil.Encode(tx.ExtraData()),
+	}
+}
+
+// run the handler with every inserted block until the subscription is closed
+func (service *MercuryFullChainService) subscribeNewBlock(ctx context.Context, handler func(notifier *rpc.Notifier, id rpc.ID, b *model.Block)) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		blockCh := make(chan model.Block)
+		blockSub := g_event.Subscribe(g_event.NewBlockInsertEvent, blockCh)
+		defer blockSub.Unsubscribe()
+
+		for {
+			select {
+			case b := <-blockCh:
+				handler(notifier, rpcSub.ID, &b)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// notify the transactions entering the tx pool
+func (service *MercuryFullChainService) SubscribePendingTransaction(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		txsCh := make(chan tx_pool.NewTxsEvent)
+		txsSub := g_event.Subscribe(g_event.NewTxInPoolEvent, txsCh)
+		defer txsSub.Unsubscribe()
+
+		for {
+			select {
+			case e := <-txsCh:
+				for _, tx := range e.Txs {
+					if err := notifier.Notify(rpcSub.ID, newSubBlockTxResp(tx)); err != nil {
+						log.Error("can't notify pending transaction", "err", err)
+					}
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// notify the mined transactions sent from or to the address
+func (service *MercuryFullChainService) SubscribeAddressTransaction(ctx context.Context, address common.Address) (*rpc.Subscription, error) {
+	return service.subscribeNewBlock(ctx, func(notifier *rpc.Notifier, id rpc.ID, b *model.Block) {
+		_ = b.TxIterator(func(i int, tx model.AbstractTransaction) error {
+			from, _ := tx.Sender(nil)
+			if !from.IsEqual(address) && (tx.To() == nil || !tx.To().IsEqual(address)) {
+				return nil
+			}
+
+			if err := notifier.Notify(id, &SubAddressTxResp{BlockNumber: b.Number(), BlockHash: b.Hash(), Transaction: newSubBlockTxResp(tx)}); err != nil {
+				log.Error("can't notify address transaction", "err", err)
+			}
+			return nil
+		})
+	})
+}
+
+// notify the mined ERC20 transfer and approve transactions of the contract
+func (service *MercuryFullChainService) SubscribeERC20(ctx context.Context, contractAddr common.Address) (*rpc.Subscription, error) {
+	return service.subscribeNewBlock(ctx, func(notifier *rpc.Notifier, id rpc.ID, b *model.Block) {
+		_ = b.TxIterator(func(i int, tx model.AbstractTransaction) error {
+			if tx.To() == nil || !tx.To().IsEqual(contractAddr) {
+				return nil
+			}
+			event := contract.ParseTokenEvent(tx)
+			if event == nil {
+				return nil
+			}
+
+			resp := &SubERC20Resp{
+				BlockNumber: b.Number(),
+				BlockHash:   b.Hash(),
+				TxID:        tx.CalTxId(),
+				Contract:    contractAddr,
+				Action:      event.Action,
+				From:        event.From,
+				To:          event.To,
+				Value:       event.Value,
+			}
+			if err := notifier.Notify(id, resp); err != nil {
+				log.Error("can't notify erc20 activity", "err", err)
+			}
+			return nil
+		})
+	})
+}
+
+// notify the verifiers of the next round at the change point
+func (service *MercuryFullChainService) SubscribeVerifiersChange(ctx context.Context) (*rpc.Subscription, error) {
+	return service.subscribeNewBlock(ctx, func(notifier *rpc.Notifier, id rpc.ID, b *model.Block) {
+		if !service.ChainReader.IsChangePoint(b, false) {
+			return
+		}
+
+		resp := &SubVerifiersResp{BlockNumber: b.Number(), BlockHash: b.Hash(), Verifiers: service.ChainReader.GetNextVerifiers()}
+		if err := notifier.Notify(id, resp); err != nil {
+			log.Error("can't notify verifiers change", "err", err)
+		}
+	})
+}
+
+// notify the blocks removed by chain rollback
+func (service *MercuryFullChainService) SubscribeRollback(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		blockCh := make(chan model.Block)
+		blockSub := g_event.Subscribe(g_event.ChainRollbackEvent, blockCh)
+		defer blockSub.Unsubscribe()
+
+		for {
+			select {
+			case b := <-blockCh:
+				if err := notifier.Notify(rpcSub.ID, &SubRollbackResp{Number: b.Number(), Hash: b.Hash()}); err != nil {
+					log.Error("can't notify rollback", "err", err)
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
@@ -0,0 +1,188 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/g-event"
+	"github.com/dipperin/dipperin-core/common/util"
+	"github.com/dipperin/dipperin-core/core/contract"
+	"github.com/dipperin/dipperin-core/core/cs-chain/chain-writer/middleware"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/core/tx-pool"
+	"github.com/dipperin/dipperin-core/third-party/rpc"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeChangePointChain struct {
+	middleware.ChainInterface
+	verifiers []common.Address
+}
+
+func (chain fakeChangePointChain) IsChangePoint(block model.AbstractBlock, isProcessPackageBlock bool) bool {
+	return block.Number() == 2
+}
+
+func (chain fakeChangePointChain) GetNextVerifiers() []common.Address {
+	return chain.verifiers
+}
+
+func createTestSubClient(t *testing.T, service *MercuryFullChainService) *rpc.Client {
+	server := rpc.NewServer()
+	assert.NoError(t, server.RegisterName("dipperin", service))
+	return rpc.DialInProc(server)
+}
+
+// the event subscriber is registered asynchronously, send the event until the notification arrives
+func sendEventUntilNotified(t *testing.T, event string, v interface{}, ch interface{}) reflect.Value {
+	chVal := reflect.ValueOf(ch)
+	for i := 0; i < 100; i++ {
+		g_event.Send(event, v)
+		chosen, recv, _ := reflect.Select([]reflect.SelectCase{
+			{Dir: reflect.SelectRecv, Chan: chVal},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(time.After(10 * time.Millisecond))},
+		})
+		if chosen == 0 {
+			return recv
+		}
+	}
+	t.Fatal("no notification received")
+	return reflect.Value{}
+}
+
+func createSubTestBlock(number uint64, txs []*model.Transaction) *model.Block {
+	header := model.NewHeader(1, number, common.Hash{}, common.Hash{}, common.HexToDiff("0x1fffffff"), big.NewInt(0), aliceAddr, common.BlockNonce{})
+	return model.NewBlock(header, txs, nil)
+}
+
+func createERC20ActivityTx(contractAddr common.Address, action string, params ...interface{}) *model.Transaction {
+	extraData := contract.ExtraDataForContract{ContractAddress: contractAddr, Action: action, Params: util.StringifyJson(params)}
+	return createSignedTx(0, contractAddr, big.NewInt(0), util.StringifyJsonToBytes(extraData))
+}
+
+func TestMercuryFullChainService_SubscribeNotSupported(t *testing.T) {
+	service := MakeFullChainService(&DipperinConfig{})
+
+	_, err := service.SubscribePendingTransaction(context.Background())
+	assert.Equal(t, rpc.ErrNotificationsUnsupported, err)
+	_, err = service.SubscribeAddressTransaction(context.Background(), aliceAddr)
+	assert.Equal(t, rpc.ErrNotificationsUnsupported, err)
+	_, err = service.SubscribeERC20(context.Background(), aliceAddr)
+	assert.Equal(t, rpc.ErrNotificationsUnsupported, err)
+	_, err = service.SubscribeVerifiersChange(context.Background())
+	assert.Equal(t, rpc.ErrNotificationsUnsupported, err)
+	_, err = service.SubscribeRollback(context.Background())
+	assert.Equal(t, rpc.ErrNotificationsUnsupported, err)
+}
+
+func TestMercuryFullChainService_SubscribePendingTransaction(t *testing.T) {
+	g_event.Add(g_event.NewTxInPoolEvent)
+	client := createTestSubClient(t, MakeFullChainService(&DipperinConfig{}))
+	defer client.Close()
+
+	ch := make(chan SubBlockTxResp)
+	sub, err := client.Subscribe(context.Background(), "dipperin", ch, "subscribePendingTransaction")
+	assert.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	tx := createSignedTx(0, aliceAddr, big.NewInt(100), nil)
+	resp := sendEventUntilNotified(t, g_event.NewTxInPoolEvent, tx_pool.NewTxsEvent{Txs: []model.AbstractTransaction{tx}}, ch).Interface().(SubBlockTxResp)
+	sender, _ := tx.Sender(nil)
+	assert.Equal(t, tx.CalTxId(), resp.TxID)
+	assert.Equal(t, sender, resp.From)
+}
+
+func TestMercuryFullChainService_SubscribeAddressTransaction(t *testing.T) {
+	g_event.Add(g_event.NewBlockInsertEvent)
+	client := createTestSubClient(t, MakeFullChainService(&DipperinConfig{}))
+	defer client.Close()
+
+	receiver := common.HexToAddress("0x0000b4293d60F051936beDecfaE1B85d5A46d377aF37")
+	ch := make(chan SubAddressTxResp)
+	sub, err := client.Subscribe(context.Background(), "dipperin", ch, "subscribeAddressTransaction", receiver)
+	assert.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	tx := createSignedTx(0, receiver, big.NewInt(100), nil)
+	block := createSubTestBlock(1, []*model.Transaction{tx})
+	resp := sendEventUntilNotified(t, g_event.NewBlockInsertEvent, *block, ch).Interface().(SubAddressTxResp)
+	assert.Equal(t, block.Hash(), resp.BlockHash)
+	assert.Equal(t, tx.CalTxId(), resp.Transaction.TxID)
+}
+
+func TestMercuryFullChainService_SubscribeERC20(t *testing.T) {
+	g_event.Add(g_event.NewBlockInsertEvent)
+	client := createTestSubClient(t, MakeFullChainService(&DipperinConfig{}))
+	defer client.Close()
+
+	contractAddr := common.HexToAddress("0x00102B9aE8e4a04B3d2Dc8a60D2E9E4E0A60A5b7aA30")
+	receiver := common.HexToAddress("0x0000b4293d60F051936beDecfaE1B85d5A46d377aF37")
+	ch := make(chan SubERC20Resp)
+	sub, err := client.Subscribe(context.Background(), "dipperin", ch, "subscribeERC20", contractAddr)
+	assert.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	tx := createERC20ActivityTx(contractAddr, "Approve", receiver.Hex(), fmt.Sprintf("0x%x", 100))
+	block := createSubTestBlock(1, []*model.Transaction{tx})
+	resp := sendEventUntilNotified(t, g_event.NewBlockInsertEvent, *block, ch).Interface().(SubERC20Resp)
+	assert.Equal(t, tx.CalTxId(), resp.TxID)
+	assert.Equal(t, "Approve", resp.Action)
+	sender, _ := tx.Sender(nil)
+	assert.Equal(t, sender, resp.From)
+	assert.Equal(t, receiver, resp.To)
+	assert.Equal(t, big.NewInt(100), resp.Value)
+}
+
+func TestMercuryFullChainService_SubscribeVerifiersChange(t *testing.T) {
+	g_event.Add(g_event.NewBlockInsertEvent)
+	csChain := createCsChain(nil)
+	verifiers := []common.Address{aliceAddr}
+	client := createTestSubClient(t, MakeFullChainService(&DipperinConfig{ChainReader: fakeChangePointChain{ChainInterface: csChain, verifiers: verifiers}}))
+	defer client.Close()
+
+	ch := make(chan SubVerifiersResp)
+	sub, err := client.Subscribe(context.Background(), "dipperin", ch, "subscribeVerifiersChange")
+	assert.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	block := createSubTestBlock(2, nil)
+	resp := sendEventUntilNotified(t, g_event.NewBlockInsertEvent, *block, ch).Interface().(SubVerifiersResp)
+	assert.Equal(t, uint64(2), resp.BlockNumber)
+	assert.Equal(t, verifiers, resp.Verifiers)
+}
+
+func TestMercuryFullChainService_SubscribeRollback(t *testing.T) {
+	g_event.Add(g_event.ChainRollbackEvent)
+	client := createTestSubClient(t, MakeFullChainService(&DipperinConfig{}))
+	defer client.Close()
+
+	ch := make(chan SubRollbackResp)
+	sub, err := client.Subscribe(context.Background(), "dipperin", ch, "subscribeRollback")
+	assert.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	block := createSubTestBlock(5, nil)
+	resp := sendEventUntilNotified(t, g_event.ChainRollbackEvent, *block, ch).Interface().(SubRollbackResp)
+	assert.Equal(t, uint64(5), resp.Number)
+	assert.Equal(t, block.Hash(), resp.Hash)
+}
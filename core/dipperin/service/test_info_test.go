@@ -415,6 +415,21 @@ func (m fakeMaster) GetPerformance(address common.Address) uint64 {
 	panic("implement me")
 }
 
+func (m fakeMaster) WorkerStats() []minemaster.WorkerStats {
+	return []minemaster.WorkerStats{{Id: "worker", Coinbase: aliceAddr, Accepted: 1, PendingReward: big.NewInt(1)}}
+}
+
+func (m fakeMaster) KickWorker(workerId minemaster.WorkerId) error {
+	if workerId != "worker" {
+		return errors.New("worker not found")
+	}
+	return nil
+}
+
+func (m fakeMaster) SetWorkerCoinbase(workerId minemaster.WorkerId, coinbase common.Address) error {
+	return m.KickWorker(workerId)
+}
+
 func (m fakeMaster) Mining() bool {
 	return m.isMine
 }
@@ -460,7 +475,9 @@ func (fakeNode) Stop() {
 }
 
 type fakeTxPool struct {
-	err error
+	err     error
+	pending []model.AbstractTransaction
+	queued  []tx_pool.QueuedTx
 }
 
 func (pool fakeTxPool) AddRemotes(txs []model.AbstractTransaction) []error {
@@ -486,3 +503,7 @@ func (pool fakeTxPool) AddRemote(tx model.AbstractTransaction) error {
 func (pool fakeTxPool) Stats() (int, int) {
 	panic("implement me")
 }
+
+func (pool fakeTxPool) ContentFrom(addr common.Address) ([]model.AbstractTransaction, []tx_pool.QueuedTx) {
+	return pool.pending, pool.queued
+}
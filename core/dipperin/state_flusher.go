@@ -0,0 +1,39 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package dipperin
+
+import (
+	"github.com/dipperin/dipperin-core/core/chain/state-processor"
+	"github.com/dipperin/dipperin-core/third-party/log"
+)
+
+// stateFlushService writes the state kept in memory by the pruning mode to disk when the node stops
+type stateFlushService struct {
+	storage state_processor.StateStorage
+}
+
+func (s *stateFlushService) Start() error {
+	return nil
+}
+
+func (s *stateFlushService) Stop() {
+	if err := s.storage.Flush(); err != nil {
+		log.Error("flush state failed", "err", err)
+		return
+	}
+	log.Info("state flushed")
+}
@@ -190,25 +190,29 @@ func init() {
 	exchangeRate.Div(exchangeRate, EarlyTokenAmount)
 	InitExchangeRate = exchangeRate.Int64()
 
-	for address := range DIPProportion.InvestorProportion {
-		InvestorAddresses = append(InvestorAddresses, common.HexToAddress(address))
-	}
-
-	for address := range DIPProportion.DeveloperProportion {
-		DeveloperAddresses = append(DeveloperAddresses, common.HexToAddress(address))
-	}
+	initProportionAddresses()
+}
 
-	for address := range DIPProportion.MaintenanceProportion {
-		MaintenanceAddresses = append(MaintenanceAddresses, common.HexToAddress(address))
-	}
+// SetDIPProportion replaces the pre-mining proportion of each address, used by the genesis file of a private network
+func SetDIPProportion(proportion AddressDIPProportion) {
+	DIPProportion = proportion
+	initProportionAddresses()
+}
 
-	for address := range DIPProportion.EarlyTokenProportion {
-		EarlyTokenAddresses = append(EarlyTokenAddresses, common.HexToAddress(address))
-	}
+func initProportionAddresses() {
+	InvestorAddresses = proportionAddresses(DIPProportion.InvestorProportion)
+	DeveloperAddresses = proportionAddresses(DIPProportion.DeveloperProportion)
+	MaintenanceAddresses = proportionAddresses(DIPProportion.MaintenanceProportion)
+	EarlyTokenAddresses = proportionAddresses(DIPProportion.EarlyTokenProportion)
+	RemainRewardAddresses = proportionAddresses(DIPProportion.ReMainRewardProportion)
+}
 
-	for address := range DIPProportion.ReMainRewardProportion {
-		RemainRewardAddresses = append(RemainRewardAddresses, common.HexToAddress(address))
+func proportionAddresses(proportion map[string]int) []common.Address {
+	addresses := make([]common.Address, 0, len(proportion))
+	for address := range proportion {
+		addresses = append(addresses, common.HexToAddress(address))
 	}
+	return addresses
 }
 
 type PreMineMainType int
@@ -320,6 +324,11 @@ func (economyModel *DipperinEconomyModel) GetOneBlockTotalDIPReward(blockNumber
 	return rewardOneBlock, nil
 }
 
+// the verifier reward is shared with the delegators after the delegation fork
+func (economyModel *DipperinEconomyModel) ShareRewardWithDelegators(blockNumber uint64) bool {
+	return chain_config.GetChainConfig().IsForkActive(chain_config.ForkDelegation, blockNumber)
+}
+
 //calculate different verifier reward
 func (economyModel *DipperinEconomyModel) calcDifferentVerifierReward(totalReward *big.Int) map[VerifierType]*big.Int {
 	conf := chain_config.GetChainConfig()
@@ -315,3 +315,30 @@ func TestDipperinEconomyModel_GetDiffVerifierAddress(t *testing.T) {
 	log.Info("the verAddr is:","verAddr",verAddr)
 	//assert.Equal(t,map[economy_model.VerifierType][]common.Address{},verAddr)
 }
+
+func TestSetDIPProportion(t *testing.T) {
+	proportion := economy_model.DIPProportion
+	defer economy_model.SetDIPProportion(proportion)
+
+	investor := common.HexToAddress("0x0000000000000000000000000000000000000000aa01")
+	economy_model.SetDIPProportion(economy_model.AddressDIPProportion{
+		BaseNumber:         100,
+		InvestorProportion: map[string]int{investor.String(): 100},
+	})
+	assert.Equal(t, []common.Address{investor}, economy_model.InvestorAddresses)
+	assert.Len(t, economy_model.DeveloperAddresses, 0)
+
+	economy_model.SetDIPProportion(proportion)
+	assert.Len(t, economy_model.InvestorAddresses, len(proportion.InvestorProportion))
+}
+
+func TestDipperinEconomyModel_ShareRewardWithDelegators(t *testing.T) {
+	economyModel := economy_model.MakeDipperinEconomyModel(nil, economy_model.DIPProportion)
+	assert.True(t, economyModel.ShareRewardWithDelegators(1))
+
+	conf := chain_config.GetChainConfig()
+	conf.Forks[chain_config.ForkDelegation] = 10
+	defer func() { conf.Forks[chain_config.ForkDelegation] = 0 }()
+	assert.False(t, economyModel.ShareRewardWithDelegators(9))
+	assert.True(t, economyModel.ShareRewardWithDelegators(10))
+}
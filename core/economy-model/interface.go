@@ -50,4 +50,6 @@ type EconomyModel interface {
 
 	GetBlockYear(blockNumber uint64) (uint64,error)
 	GetOneBlockTotalDIPReward(blockNumber uint64) (*big.Int, error)
+
+	ShareRewardWithDelegators(blockNumber uint64) bool
 }
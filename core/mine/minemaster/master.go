@@ -23,6 +23,7 @@ import (
 	"github.com/dipperin/dipperin-core/core/model"
 	"github.com/dipperin/dipperin-core/third-party/log"
 	"math/big"
+	"sync"
 	"time"
 	"fmt"
 	"github.com/dipperin/dipperin-core/common/g-event"
@@ -35,6 +36,7 @@ func newMaster(config MineConfig) *master {
 		MineConfig: config,
 
 		workers: map[WorkerId]WorkerForMaster{},
+		workerRecords: map[WorkerId]*workerRecord{},
 		registerWorkerChan: make(chan WorkerForMaster),
 		unRegisterWorkerChan: make(chan WorkerId),
 		onNewBlockChan: make(chan model.AbstractBlock),
@@ -48,6 +50,9 @@ type master struct {
 	MineConfig
 
 	workers         map[WorkerId]WorkerForMaster
+	// the statistics of the workers, they are read outside the loop
+	statsLock     sync.Mutex
+	workerRecords map[WorkerId]*workerRecord
 
 	workDispatcher dispatcher
 	workManager workManager
@@ -65,18 +70,15 @@ type master struct {
 	stopTimerFunc func()
 }
 
+// RetrieveReward pays the whole balance of the worker at once regardless of the payout threshold
 func (ms *master) RetrieveReward(address common.Address) {
-	ms.sendReward(address)
-
-	ms.workManager.clearReward(address)
-	ms.workManager.clearPerformance(address)
-
-}
-
-func (ms *master) sendReward(address common.Address) {
-	//reward := ms.workManager.getReward(address)
-	// ms.send(reward)
-	// TODO, initiate transaction
+	if err := ms.workManager.payReward(address); err != nil {
+		log.Warn("retrieve mine reward failed", "worker", address.Hex(), "err", err)
+		return
+	}
+	if err := ms.workManager.saveRewards(); err != nil {
+		log.Error("save mine rewards failed", "err", err)
+	}
 }
 
 func (ms *master) GetReward(address common.Address) *big.Int {
@@ -111,6 +113,7 @@ func (ms *master) loop() {
 			//	//continue
 			//}
 			ms.workers[worker.GetId()] = worker
+			ms.addWorkerRecord(worker)
 
 		case wId := <- ms.unRegisterWorkerChan:
 			log.Info("un register worker", "w id", wId)
@@ -118,6 +121,7 @@ func (ms *master) loop() {
 				ms.workers[wId].Stop()
 			}
 			delete(ms.workers, wId)
+			ms.removeWorkerRecord(wId)
 
 		case block := <- ms.onNewBlockChan:
 			ms.doOnNewBlock(block)
@@ -253,8 +257,15 @@ func (ms *master) registerWorker(worker WorkerForMaster) {
 	ms.registerWorkerChan <- worker
 }
 
+// the loop isn't running before the master starts or after it stops, so the worker isn't waited for
 func (ms *master) unRegisterWorker(workerId WorkerId) {
-	ms.unRegisterWorkerChan <- workerId
+	if ms.stopped() {
+		return
+	}
+	select {
+	case ms.unRegisterWorkerChan <- workerId:
+	case <-ms.stopChan:
+	}
 }
 
 func (ms *master) setWorkDispatcher(dispatcher dispatcher) {
@@ -28,6 +28,8 @@ func MakeMineMaster(config MineConfig) (Master, MasterServer) {
 	dispatcher := newWorkDispatcher(config, master.Workers)
 	// manage worker's work and submit work to broadcast
 	manager := newDefaultWorkManager(config)
+	// the shares are accounted by the difficulty of the current work block
+	manager.getCurWorkBlockFunc = dispatcher.curWorkBlock
 	// communicator for master with workers
 	server := newServer(master, manager, dispatcher.curWorkBlock)
 	master.workManager = manager
@@ -24,6 +24,7 @@ import (
 	"github.com/dipperin/dipperin-core/third-party/p2p"
 	"math/big"
 	"sync/atomic"
+	"time"
 )
 
 type WorkerId string
@@ -39,6 +40,11 @@ type Master interface {
 	GetReward(address common.Address) *big.Int
 	GetPerformance(address common.Address) uint64
 
+	// statistics and management of the registered workers
+	WorkerStats() []WorkerStats
+	KickWorker(workerId WorkerId) error
+	SetWorkerCoinbase(workerId WorkerId, coinbase common.Address) error
+
 	// whether the mining is ongoing
 	Mining() bool
 	// cur mine block tx count
@@ -56,6 +62,7 @@ type mineMaster interface {
 	unRegisterWorker(workerId WorkerId)
 	startWaitTimer()
 	getWorker(id WorkerId) WorkerForMaster
+	recordSubmit(workerId WorkerId, difficulty float64, accepted bool)
 }
 
 type SpendableMaster interface {
@@ -87,6 +94,7 @@ type MasterServer interface {
 // there is only one workManager to manage all worker's works
 type workManager interface {
 	submitBlock(workerAddress common.Address, block model.AbstractBlock)
+	submitShare(workerAddress common.Address, difficulty float64)
 	getPerformance(address common.Address) uint64
 	getReward(address common.Address) *big.Int
 	onNewBlock(block model.AbstractBlock)
@@ -103,6 +111,8 @@ type dispatcher interface {
 type spendableWorkManager interface {
 	clearPerformance(address common.Address)
 	clearReward(address common.Address)
+	payReward(address common.Address) error
+	saveRewards() error
 }
 
 type partialSpendableWorkManager interface {
@@ -147,10 +157,40 @@ type BlockBroadcaster interface {
 	BroadcastMinedBlock(block model.AbstractBlock)
 }
 
+// RewardCalculator calculates the reward of the mine master in the block
+type RewardCalculator interface {
+	GetMineMasterDIPReward(block model.AbstractBlock) (*big.Int, error)
+}
+
+// RewardSender sends the payout txs from the coinbase of the mine master and looks them up on the chain
+type RewardSender interface {
+	SendTransaction(from, to common.Address, value, transactionFee *big.Int, data []byte, nonce *uint64) (common.Hash, error)
+	Transaction(hash common.Hash) (transaction *model.Transaction, blockHash common.Hash, blockNumber uint64, txIndex uint64, err error)
+	GetTransactionNonce(addr common.Address) (nonce uint64, err error)
+	GetAddressNonceFromWallet(address common.Address) (nonce uint64, err error)
+}
+
+// RewardConfig configures how the block rewards are shared by the workers and paid to them
+type RewardConfig struct {
+	// RewardModePPLNS or RewardModePPS, PPLNS by default
+	Mode string
+	// the PPLNS window in multiples of the block difficulty
+	PPLNSWindow float64
+	// the file keeping the balances of the workers, the balances aren't persisted if empty
+	File string
+	// the balances reaching the threshold are paid every PayoutInterval, no payout if nil or zero
+	PayoutThreshold *big.Int
+	PayoutInterval  time.Duration
+	// the coinbase of the block is used as the reward if nil
+	Calculator RewardCalculator
+	Sender     RewardSender
+}
+
 type MineConfig struct {
 	CoinbaseAddress  *atomic.Value
 	BlockBuilder     BlockBuilder
 	BlockBroadcaster BlockBroadcaster
+	Reward           RewardConfig
 }
 
 func (conf *MineConfig) GetCoinbaseAddr() (result common.Address) {
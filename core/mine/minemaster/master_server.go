@@ -70,11 +70,17 @@ func (s *server) ReceiveMsg(workerID WorkerId, code uint64, msg interface{}) {
 }
 
 func (s *server) onSubmitBlock(workerID WorkerId, work minemsg.Work) {
+	// the block is the only share of the local and p2p workers, the shares of the stratum workers
+	// have been accounted by the stratum server
+	_, isStratum := work.(*stratumWork)
 
 	block := s.getCurWorkBlockFunc()
 	pbft_log.Debug("onSubmitBlock", "block id", block.Number(), "block txs", block.TxCount())
 	if err := work.FillSealResult(block); err != nil {
 		log.Warn("fill seal result failed", "err", err)
+		if !isStratum {
+			s.master.recordSubmit(workerID, 0, false)
+		}
 		return
 	}
 
@@ -83,9 +89,18 @@ func (s *server) onSubmitBlock(workerID WorkerId, work minemsg.Work) {
 	if !block.RefreshHashCache().ValidHashForDifficulty(block.Difficulty()) {
 		log.Warn("master receive invalid mined block", "do unregister worker", workerID)
 		//s.UnRegisterWorker(workerID)
+		if !isStratum {
+			s.master.recordSubmit(workerID, 0, false)
+		}
 		return
 	}
 
+	if !isStratum {
+		difficulty := shareDifficulty(block.Difficulty().Big())
+		s.master.recordSubmit(workerID, difficulty, true)
+		s.workManager.submitShare(work.GetWorkerCoinbaseAddress(), difficulty)
+	}
+
 	//fmt.Println("mine master prepare broadcast block", util.StringifyJson(block), block.Hash())
 	//log.Info("mine master receive new work", "block hash", block.Hash().Hex(), "block number", block.Number())
 	s.workManager.submitBlock(work.GetWorkerCoinbaseAddress(), block)
@@ -63,6 +63,7 @@ func Test_server_ReceiveMsg(t *testing.T) {
 	dispatch := mockDispatch{curBlock: nil}
 	m.setWorkDispatcher(&dispatch)
 	wm := newDefaultWorkManager(testMineConfig)
+	wm.getCurWorkBlockFunc = fakeGetCurWorkBlockFunc
 	s := newServer(m, wm, fakeGetCurWorkBlockFunc)
 	s.master.(*master).Start()
 	p := peer_spec.PeerBuilder()
@@ -80,13 +81,23 @@ func Test_server_ReceiveMsg(t *testing.T) {
 
 	s.ReceiveMsg("123", minemsg.SubmitDefaultWorkMsg, &work)
 
+	assert.Len(t, wm.shares, 0)
+
 	model.CalNonce(fakeBlock)
 
 	s.ReceiveMsg("123", minemsg.SubmitDefaultWorkMsg, &work)
+	// the mined block is accounted as a share of the block difficulty
+	assert.Len(t, wm.shares, 1)
+	assert.Equal(t, shareDifficulty(diff.Big()), wm.shares[0].Difficulty)
 
 	work2 := mockWork{fillSealResultError: errors.New("test")}
 
 	s.ReceiveMsg("123", minemsg.SubmitDefaultWorkMsg, &work2)
+
+	stats := m.WorkerStats()
+	assert.Len(t, stats, 1)
+	assert.Equal(t, uint64(1), stats[0].Accepted)
+	assert.Equal(t, uint64(2), stats[0].Stale)
 }
 
 func Test_server_SetMineMasterPeer(t *testing.T) {
@@ -324,6 +324,7 @@ func testMasterBuilder(config MineConfig) *master {
 
 		workManager:          manager,
 		workers:              map[WorkerId]WorkerForMaster{},
+		workerRecords:        map[WorkerId]*workerRecord{},
 		registerWorkerChan:   make(chan WorkerForMaster),
 		unRegisterWorkerChan: make(chan WorkerId),
 		onNewBlockChan:       make(chan model.AbstractBlock),
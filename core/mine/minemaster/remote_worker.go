@@ -55,6 +55,11 @@ func (worker *remoteWorker) CurrentCoinbaseAddress() common.Address {
 	return common.Address{}
 }
 
+// the worker submits the blocks with its own coinbase
+func (worker *remoteWorker) syncCoinbase(coinbase common.Address) error {
+	return worker.peer.SendMsg(minemsg.SetCurrentCoinbaseMsg, minemsg.SetCurrentCoinbase{Coinbase: coinbase})
+}
+
 func (worker *remoteWorker) Start() {
 	worker.peer.SendMsg(minemsg.StartMineMsg, "")
 }
@@ -63,6 +68,11 @@ func (worker *remoteWorker) Stop() {
 	worker.peer.SendMsg(minemsg.StopMineMsg, "")
 }
 
+// the kicked worker is disconnected, it has to connect again to register
+func (worker *remoteWorker) disconnect() {
+	worker.peer.DisconnectPeer()
+}
+
 func (worker *remoteWorker) GetId() WorkerId {
 	return worker.workerId
 }
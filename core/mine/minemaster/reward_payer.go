@@ -0,0 +1,371 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package minemaster
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/core/economy-model"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/third-party/log"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPayoutInterval = 10 * time.Minute
+	rewardSaveInterval    = 10 * time.Second
+	// txs sent in a payout round, the rest are paid in the next rounds
+	maxPayoutBatch = 64
+)
+
+var (
+	errNoRewardSender = errors.New("no sender of the reward txs")
+	errNoReward       = errors.New("no reward to pay")
+	errRewardTooLow   = errors.New("reward is not enough for the tx fee")
+	errPayoutPending  = errors.New("the last payout isn't confirmed yet")
+)
+
+type rewardAccount struct {
+	Address common.Address `json:"address"`
+	Balance *big.Int       `json:"balance"`
+	Paid    *big.Int       `json:"paid"`
+}
+
+// rewardPayout is a payout tx not confirmed yet, the balance is credited back if its nonce is used by another tx
+type rewardPayout struct {
+	Address common.Address `json:"address"`
+	From    common.Address `json:"from"`
+	TxHash  common.Hash    `json:"tx_hash"`
+	Nonce   uint64         `json:"nonce"`
+	Balance *big.Int       `json:"balance"`
+	Value   *big.Int       `json:"value"`
+}
+
+// the content of the reward file
+type rewardRecord struct {
+	Mode        string          `json:"mode"`
+	TotalReward *big.Int        `json:"total_reward"`
+	Accounts    []rewardAccount `json:"accounts"`
+	Payouts     []rewardPayout  `json:"payouts"`
+	Shares      []workerShare   `json:"shares"`
+}
+
+func (manager *defaultWorkManager) loadRewards() error {
+	if manager.Reward.File == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(manager.Reward.File)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var record rewardRecord
+	if err = json.Unmarshal(data, &record); err != nil {
+		return err
+	}
+	if record.TotalReward != nil {
+		manager.totalReward = record.TotalReward
+	}
+	for _, account := range record.Accounts {
+		if account.Balance != nil && account.Balance.Sign() > 0 {
+			manager.reward[account.Address] = account.Balance
+		}
+		if account.Paid != nil && account.Paid.Sign() > 0 {
+			manager.paid[account.Address] = account.Paid
+		}
+	}
+	for i := range record.Payouts {
+		manager.payouts[record.Payouts[i].Address] = &record.Payouts[i]
+	}
+	manager.shares = record.Shares
+	for _, share := range manager.shares {
+		manager.sharesDifficulty += share.Difficulty
+	}
+	log.Info("load mine rewards", "mode", record.Mode, "accounts", len(record.Accounts), "payouts", len(record.Payouts), "shares", len(record.Shares))
+	return nil
+}
+
+// saveRewards writes the balances to the reward file if they changed since the last save
+func (manager *defaultWorkManager) saveRewards() error {
+	if manager.Reward.File == "" {
+		return nil
+	}
+	manager.rewardLock.Lock()
+	if !manager.rewardDirty {
+		manager.rewardLock.Unlock()
+		return nil
+	}
+	record := rewardRecord{
+		Mode:        manager.rewardMode(),
+		TotalReward: manager.totalReward,
+		Shares:      manager.shares,
+	}
+	for address, balance := range manager.reward {
+		record.Accounts = append(record.Accounts, rewardAccount{Address: address, Balance: balance, Paid: manager.paid[address]})
+	}
+	for address, paid := range manager.paid {
+		if manager.reward[address] == nil {
+			record.Accounts = append(record.Accounts, rewardAccount{Address: address, Balance: new(big.Int), Paid: paid})
+		}
+	}
+	sort.Slice(record.Accounts, func(i, j int) bool {
+		return record.Accounts[i].Address.Hex() < record.Accounts[j].Address.Hex()
+	})
+	for _, payout := range manager.payouts {
+		record.Payouts = append(record.Payouts, *payout)
+	}
+	sort.Slice(record.Payouts, func(i, j int) bool {
+		return record.Payouts[i].Nonce < record.Payouts[j].Nonce
+	})
+	data, err := json.MarshalIndent(&record, "", "  ")
+	manager.rewardDirty = false
+	manager.rewardLock.Unlock()
+
+	// replace the file at once so that a crash never leaves a broken one
+	if err == nil {
+		if err = ioutil.WriteFile(manager.Reward.File+".new", data, 0600); err == nil {
+			err = os.Rename(manager.Reward.File+".new", manager.Reward.File)
+		}
+	}
+	if err != nil {
+		// retry in the next save
+		manager.rewardLock.Lock()
+		manager.rewardDirty = true
+		manager.rewardLock.Unlock()
+	}
+	return err
+}
+
+// payReward sends the whole balance of the worker from the coinbase of the master, the tx fee is paid by the worker.
+// The balance is pending until the payout is confirmed on the chain, it is credited back if the tx can't be sent
+func (manager *defaultWorkManager) payReward(address common.Address) error {
+	if manager.Reward.Sender == nil {
+		return errNoRewardSender
+	}
+	manager.payoutLock.Lock()
+	defer manager.payoutLock.Unlock()
+
+	manager.rewardLock.Lock()
+	if manager.payouts[address] != nil {
+		manager.rewardLock.Unlock()
+		return errPayoutPending
+	}
+	balance := manager.reward[address]
+	if balance == nil || balance.Sign() <= 0 {
+		manager.rewardLock.Unlock()
+		return errNoReward
+	}
+	// the unsigned tx size is doubled to cover the witness
+	fee := economy_model.GetMinimumTxFee(model.NewTransaction(0, address, balance, balance, nil).Size() * 2)
+	if balance.Cmp(fee) <= 0 {
+		manager.rewardLock.Unlock()
+		return errRewardTooLow
+	}
+	payout := &rewardPayout{
+		Address: address,
+		From:    manager.GetCoinbaseAddr(),
+		Balance: balance,
+		Value:   new(big.Int).Sub(balance, fee),
+	}
+	delete(manager.reward, address)
+	manager.payouts[address] = payout
+	manager.rewardLock.Unlock()
+
+	// the shares keep being credited while the tx is sent
+	nonce, err := manager.payoutNonce(payout.From)
+	var txHash common.Hash
+	if err == nil {
+		txHash, err = manager.Reward.Sender.SendTransaction(payout.From, address, payout.Value, fee, nil, &nonce)
+	}
+
+	manager.rewardLock.Lock()
+	defer manager.rewardLock.Unlock()
+	if err != nil {
+		delete(manager.payouts, address)
+		manager.addReward(address, balance)
+		return err
+	}
+	payout.TxHash = txHash
+	payout.Nonce = nonce
+	manager.rewardDirty = true
+	log.Info("send mine reward", "worker", address.Hex(), "value", payout.Value, "fee", fee, "nonce", nonce, "txId", txHash.Hex())
+	return nil
+}
+
+// payoutNonce returns the nonce of the next tx of the coinbase, the same as the one picked by the chain service
+func (manager *defaultWorkManager) payoutNonce(from common.Address) (uint64, error) {
+	chainNonce, err := manager.Reward.Sender.GetTransactionNonce(from)
+	if err != nil {
+		return 0, err
+	}
+	walletNonce, err := manager.Reward.Sender.GetAddressNonceFromWallet(from)
+	if err != nil || walletNonce < chainNonce {
+		return chainNonce, nil
+	}
+	return walletNonce, nil
+}
+
+// confirmPayouts moves the payouts mined on the chain to the paid rewards, and credits the balances back if
+// the nonces of the payouts have been used by other txs of the coinbase
+func (manager *defaultWorkManager) confirmPayouts() {
+	if manager.Reward.Sender == nil {
+		return
+	}
+	manager.payoutLock.Lock()
+	defer manager.payoutLock.Unlock()
+
+	manager.rewardLock.Lock()
+	payouts := make([]*rewardPayout, 0, len(manager.payouts))
+	for _, payout := range manager.payouts {
+		payouts = append(payouts, payout)
+	}
+	manager.rewardLock.Unlock()
+
+	for _, payout := range payouts {
+		// the nonce is read first, a payout mined after it is still found by the tx lookup
+		chainNonce, err := manager.Reward.Sender.GetTransactionNonce(payout.From)
+		if err != nil {
+			log.Warn("get the coinbase nonce failed", "address", payout.From.Hex(), "err", err)
+			continue
+		}
+		tx, _, blockNumber, _, err := manager.Reward.Sender.Transaction(payout.TxHash)
+		confirmed := err == nil && tx != nil
+		if !confirmed && chainNonce <= payout.Nonce {
+			continue
+		}
+
+		manager.rewardLock.Lock()
+		delete(manager.payouts, payout.Address)
+		if confirmed {
+			if manager.paid[payout.Address] == nil {
+				manager.paid[payout.Address] = new(big.Int)
+			}
+			manager.paid[payout.Address].Add(manager.paid[payout.Address], payout.Value)
+			log.Info("pay mine reward", "worker", payout.Address.Hex(), "value", payout.Value, "block", blockNumber, "txId", payout.TxHash.Hex())
+		} else {
+			manager.addReward(payout.Address, payout.Balance)
+			log.Warn("mine reward payout dropped", "worker", payout.Address.Hex(), "nonce", payout.Nonce, "txId", payout.TxHash.Hex())
+		}
+		manager.rewardDirty = true
+		manager.rewardLock.Unlock()
+	}
+}
+
+// payRewards pays the balances reaching the threshold, it stops at the first failed tx to keep the nonces in order
+func (manager *defaultWorkManager) payRewards(threshold *big.Int) {
+	manager.confirmPayouts()
+
+	manager.rewardLock.Lock()
+	var due []common.Address
+	for address, balance := range manager.reward {
+		if balance.Cmp(threshold) >= 0 && manager.payouts[address] == nil {
+			due = append(due, address)
+		}
+	}
+	manager.rewardLock.Unlock()
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].Hex() < due[j].Hex()
+	})
+	if len(due) > maxPayoutBatch {
+		due = due[:maxPayoutBatch]
+	}
+	for _, address := range due {
+		if err := manager.payReward(address); err != nil {
+			log.Warn("pay mine reward failed", "worker", address.Hex(), "err", err)
+			break
+		}
+	}
+
+	if err := manager.saveRewards(); err != nil {
+		log.Error("save mine rewards failed", "err", err)
+	}
+}
+
+// RewardPayer flushes the balances of the workers to the reward file, pays the balances
+// reaching the threshold through the normal txs of the coinbase account and confirms the payouts
+type RewardPayer struct {
+	manager *defaultWorkManager
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+func MakeRewardPayer(mServer MasterServer) *RewardPayer {
+	ms, ok := mServer.(*server)
+	if !ok {
+		return nil
+	}
+	manager, ok := ms.workManager.(*defaultWorkManager)
+	if !ok {
+		return nil
+	}
+	return &RewardPayer{manager: manager}
+}
+
+func (p *RewardPayer) Start() error {
+	p.stopChan = make(chan struct{})
+	p.wg.Add(1)
+	go p.loop()
+	return nil
+}
+
+func (p *RewardPayer) Stop() {
+	close(p.stopChan)
+	p.wg.Wait()
+
+	if err := p.manager.saveRewards(); err != nil {
+		log.Error("save mine rewards failed", "err", err)
+	}
+}
+
+func (p *RewardPayer) loop() {
+	defer p.wg.Done()
+
+	interval := p.manager.Reward.PayoutInterval
+	if interval <= 0 {
+		interval = defaultPayoutInterval
+	}
+	payTicker := time.NewTicker(interval)
+	defer payTicker.Stop()
+	saveTicker := time.NewTicker(rewardSaveInterval)
+	defer saveTicker.Stop()
+
+	for {
+		select {
+		case <-payTicker.C:
+			if threshold := p.manager.Reward.PayoutThreshold; threshold != nil && threshold.Sign() > 0 {
+				p.manager.payRewards(threshold)
+			}
+		case <-saveTicker.C:
+			p.manager.confirmPayouts()
+			if err := p.manager.saveRewards(); err != nil {
+				log.Error("save mine rewards failed", "err", err)
+			}
+		case <-p.stopChan:
+			return
+		}
+	}
+}
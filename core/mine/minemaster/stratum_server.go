@@ -0,0 +1,631 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package minemaster
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/hexutil"
+	"github.com/dipperin/dipperin-core/core/mine/minemsg"
+	"github.com/dipperin/dipperin-core/third-party/log"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	stratumSubscribe     = "mining.subscribe"
+	stratumAuthorize     = "mining.authorize"
+	stratumSubmit        = "mining.submit"
+	stratumNotify        = "mining.notify"
+	stratumSetDifficulty = "mining.set_difficulty"
+
+	// the first 8 bytes of the nonce are the extranonce1 of the job, the rest is chosen by the stratum miner
+	stratumExtraNonce1Size = 8
+	stratumExtraNonce2Size = common.NonceLength - stratumExtraNonce1Size
+
+	defaultStratumDifficulty = float64(1)
+	minStratumDifficulty     = float64(1) / (1 << 20)
+	maxStratumDifficulty     = float64(1 << 40)
+
+	// vardiff aims at a share per stratumShareInterval for each worker, the difficulty is adjusted
+	// after stratumRetargetShares shares or stratumRetargetInterval
+	stratumShareInterval    = 10 * time.Second
+	stratumRetargetInterval = time.Minute
+	stratumRetargetShares   = 16
+
+	// jobs kept for the late submits of a worker
+	stratumMaxJobs = 8
+
+	stratumMaxLineSize  = 16 * 1024
+	stratumReadTimeout  = 10 * time.Minute
+	stratumWriteTimeout = 10 * time.Second
+)
+
+var (
+	// share target of the difficulty 1, the hash of a share must not be bigger than stratumDiffOneTarget / difficulty
+	stratumDiffOneTarget = new(big.Int).Lsh(big.NewInt(1), 224)
+	stratumMaxTarget     = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+	errStratumUnknownMethod  = errors.New("unknown method")
+	errStratumBadParams      = errors.New("invalid params")
+	errStratumNotSubscribed  = errors.New("not subscribed")
+	errStratumNotAuthorized  = errors.New("unauthorized worker")
+	errStratumNotMining      = errors.New("mining not started")
+	errStratumStaleJob       = errors.New("job not found")
+	errStratumDuplicateShare = errors.New("duplicate share")
+	errStratumLowDifficulty  = errors.New("low difficulty share")
+)
+
+// error codes of the stratum protocol
+var stratumErrorCodes = map[error]int{
+	errStratumStaleJob:       21,
+	errStratumDuplicateShare: 22,
+	errStratumLowDifficulty:  23,
+	errStratumNotAuthorized:  24,
+	errStratumNotSubscribed:  25,
+}
+
+type stratumRequest struct {
+	Id     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+type stratumResponse struct {
+	Id     json.RawMessage `json:"id"`
+	Result interface{}     `json:"result"`
+	Error  interface{}     `json:"error"`
+}
+
+type stratumNotification struct {
+	Id     interface{}   `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+// StratumServer serves the mine works to the stratum miners through line delimited json over tcp.
+// Each connection is a worker of the mine master and gets the DefaultWork of the worker as jobs:
+//
+//	mining.subscribe      [agent]                        -> [[subscriptions], session id, extranonce2 size]
+//	mining.authorize      [coinbase[.rig], password]     -> true
+//	mining.submit         [worker, job id, extranonce2]  -> true
+//	mining.set_difficulty [difficulty]
+//	mining.notify         [job id, header rlp without nonce, extranonce1, share target, block number, clean jobs]
+//
+// The hash of a share is keccak256(header rlp without nonce ++ extranonce1 ++ extranonce2)
+type StratumServer struct {
+	endpoint            string
+	master              Master
+	server              MasterServer
+	getCurWorkBlockFunc getCurWorkBlockFunc
+	workManager         workManager
+	mineMaster          mineMaster
+
+	listener  net.Listener
+	sessionId uint32
+	lock      sync.Mutex
+	sessions  map[uint32]*stratumSession
+	wg        sync.WaitGroup
+}
+
+func MakeStratumServer(endpoint string, master Master, mServer MasterServer) *StratumServer {
+	s := &StratumServer{
+		endpoint: endpoint,
+		master:   master,
+		server:   mServer,
+		sessions: map[uint32]*stratumSession{},
+	}
+	// the new worker gets the current work block at once instead of waiting for the next dispatch,
+	// and the shares are accounted by the work manager and the worker statistics of the master
+	if ms, ok := mServer.(*server); ok {
+		s.getCurWorkBlockFunc = ms.getCurWorkBlockFunc
+		s.workManager = ms.workManager
+		s.mineMaster = ms.master
+	}
+	return s
+}
+
+func (s *StratumServer) Start() error {
+	if s.endpoint == "" {
+		return nil
+	}
+	listener, err := net.Listen("tcp", s.endpoint)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	s.wg.Add(1)
+	go s.accept(listener)
+	log.Info("stratum endpoint opened", "url", fmt.Sprintf("stratum+tcp://%s", listener.Addr()))
+	return nil
+}
+
+func (s *StratumServer) Stop() {
+	if s.listener == nil {
+		return
+	}
+	s.listener.Close()
+	s.lock.Lock()
+	for _, session := range s.sessions {
+		session.conn.Close()
+	}
+	s.lock.Unlock()
+	s.wg.Wait()
+	s.listener = nil
+}
+
+// Addr return the listening address of the server
+func (s *StratumServer) Addr() net.Addr {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+func (s *StratumServer) accept(listener net.Listener) {
+	defer s.wg.Done()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			log.Info("stratum listener closed", "err", err)
+			return
+		}
+		session := newStratumSession(atomic.AddUint32(&s.sessionId, 1), conn)
+		s.lock.Lock()
+		s.sessions[session.id] = session
+		s.lock.Unlock()
+
+		s.wg.Add(1)
+		go s.handleSession(session)
+	}
+}
+
+func (s *StratumServer) handleSession(session *stratumSession) {
+	defer s.wg.Done()
+	defer func() {
+		session.conn.Close()
+		s.lock.Lock()
+		delete(s.sessions, session.id)
+		s.lock.Unlock()
+		// the master loop is gone after the mining stopped
+		if session.isAuthorized() && s.master.Mining() {
+			s.server.UnRegisterWorker(session.GetId())
+		}
+		accepted, rejected, blocks := session.shareStats()
+		log.Info("stratum worker disconnected", "worker", session.GetId(), "remote", session.conn.RemoteAddr(), "accepted", accepted, "rejected", rejected, "blocks", blocks)
+	}()
+
+	scanner := bufio.NewScanner(session.conn)
+	scanner.Buffer(make([]byte, 0, 1024), stratumMaxLineSize)
+	for {
+		session.conn.SetReadDeadline(time.Now().Add(stratumReadTimeout))
+		if !scanner.Scan() {
+			return
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var req stratumRequest
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			log.Info("invalid stratum request", "worker", session.GetId(), "err", err)
+			return
+		}
+		result, err := s.handleRequest(session, &req)
+		if err = session.reply(req.Id, result, err); err != nil {
+			log.Info("write stratum response failed", "worker", session.GetId(), "err", err)
+			return
+		}
+		if req.Method == stratumAuthorize && session.isAuthorized() {
+			s.sendFirstJob(session)
+		}
+	}
+}
+
+func (s *StratumServer) handleRequest(session *stratumSession, req *stratumRequest) (interface{}, error) {
+	var params []string
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, errStratumBadParams
+		}
+	}
+
+	switch req.Method {
+	case stratumSubscribe:
+		return session.subscribe(), nil
+	case stratumAuthorize:
+		return s.authorize(session, params)
+	case stratumSubmit:
+		return s.submit(session, params)
+	default:
+		return nil, errStratumUnknownMethod
+	}
+}
+
+// the user name is the coinbase address of the worker, it can be followed by the rig name
+func (s *StratumServer) authorize(session *stratumSession, params []string) (interface{}, error) {
+	if !session.isSubscribed() {
+		return nil, errStratumNotSubscribed
+	}
+	if len(params) < 1 {
+		return nil, errStratumBadParams
+	}
+	coinbase, err := parseStratumUser(params[0])
+	if err != nil {
+		return nil, err
+	}
+	if !s.master.Mining() {
+		return nil, errStratumNotMining
+	}
+
+	session.SetCoinbase(coinbase)
+	if !session.isAuthorized() {
+		session.setAuthorized()
+		s.server.RegisterWorker(session)
+	}
+	log.Info("stratum worker authorized", "worker", session.GetId(), "user", params[0], "remote", session.conn.RemoteAddr())
+	return true, nil
+}
+
+func (s *StratumServer) sendFirstJob(session *stratumSession) {
+	if err := session.sendDifficulty(); err != nil {
+		log.Info("send stratum difficulty failed", "worker", session.GetId(), "err", err)
+		return
+	}
+	if s.getCurWorkBlockFunc == nil {
+		return
+	}
+	block := s.getCurWorkBlockFunc()
+	if block == nil {
+		return
+	}
+	if _, works := minemsg.MakeDefaultWorkBuilder().BuildWorks(block, 1); len(works) > 0 {
+		session.SendNewWork(minemsg.NewDefaultWorkMsg, works[0])
+	}
+}
+
+func (s *StratumServer) submit(session *stratumSession, params []string) (interface{}, error) {
+	if !session.isAuthorized() {
+		return nil, errStratumNotAuthorized
+	}
+	if len(params) < 3 {
+		return nil, errStratumBadParams
+	}
+	work, difficulty, err := session.checkShare(params[1], params[2])
+	if s.mineMaster != nil {
+		s.mineMaster.recordSubmit(session.GetId(), difficulty, err == nil)
+	}
+	if err != nil {
+		log.Debug("stratum share rejected", "worker", session.GetId(), "err", err)
+		return nil, err
+	}
+	if s.workManager != nil {
+		s.workManager.submitShare(session.CurrentCoinbaseAddress(), difficulty)
+	}
+	if work != nil {
+		log.Info("stratum worker found block", "worker", session.GetId(), "block number", work.BlockHeader.Number)
+		s.server.ReceiveMsg(session.GetId(), minemsg.SubmitDefaultWorkMsg, &stratumWork{DefaultWork: work})
+	}
+	return true, nil
+}
+
+func parseStratumUser(user string) (common.Address, error) {
+	addr := strings.SplitN(user, ".", 2)[0]
+	b, err := hexutil.Decode(addr)
+	if err != nil || len(b) != common.AddressLength {
+		return common.Address{}, errStratumNotAuthorized
+	}
+	return common.BytesToAddress(b), nil
+}
+
+// the share target of the job, it is never harder than the block so that no block is missed
+func stratumJobTarget(difficulty float64, blockDiff common.Difficulty) *big.Int {
+	shareTarget, _ := new(big.Float).Quo(new(big.Float).SetInt(stratumDiffOneTarget), big.NewFloat(difficulty)).Int(nil)
+	if blockTarget := blockDiff.Big(); blockTarget.Cmp(shareTarget) > 0 {
+		shareTarget = blockTarget
+	}
+	if shareTarget.Cmp(stratumMaxTarget) > 0 {
+		shareTarget = stratumMaxTarget
+	}
+	return shareTarget
+}
+
+// the block found by a stratum share, the share has been accounted when it was accepted
+type stratumWork struct {
+	*minemsg.DefaultWork
+}
+
+type stratumJob struct {
+	id     string
+	work   *minemsg.DefaultWork
+	target *big.Int
+	shares map[common.BlockNonce]struct{}
+}
+
+// a stratum connection, it is registered to the mine master as a worker after authorized
+type stratumSession struct {
+	id   uint32
+	conn net.Conn
+
+	writeLock sync.Mutex
+	encoder   *json.Encoder
+
+	subscribed int32
+	authorized int32
+	coinbase   atomic.Value
+
+	lock           sync.Mutex
+	jobSeq         uint64
+	jobs           []*stratumJob
+	difficulty     float64
+	retargetAt     time.Time
+	retargetShares uint64
+
+	acceptedShares uint64
+	rejectedShares uint64
+	minedBlocks    uint64
+}
+
+func newStratumSession(id uint32, conn net.Conn) *stratumSession {
+	return &stratumSession{
+		id:         id,
+		conn:       conn,
+		encoder:    json.NewEncoder(conn),
+		difficulty: defaultStratumDifficulty,
+		retargetAt: time.Now(),
+	}
+}
+
+func (session *stratumSession) Start() {}
+
+// the miner keeps the connection, there is no new job after stopped
+func (session *stratumSession) Stop() {}
+
+// close the connection, the session is unregistered after the connection closed
+func (session *stratumSession) disconnect() {
+	session.conn.Close()
+}
+
+func (session *stratumSession) GetId() WorkerId {
+	return WorkerId(fmt.Sprintf("stratum-%d", session.id))
+}
+
+func (session *stratumSession) SetCoinbase(coinbase common.Address) {
+	session.coinbase.Store(coinbase)
+}
+
+func (session *stratumSession) CurrentCoinbaseAddress() common.Address {
+	if addr := session.coinbase.Load(); addr != nil {
+		return addr.(common.Address)
+	}
+	return common.Address{}
+}
+
+func (session *stratumSession) isSubscribed() bool {
+	return atomic.LoadInt32(&session.subscribed) == 1
+}
+
+func (session *stratumSession) isAuthorized() bool {
+	return atomic.LoadInt32(&session.authorized) == 1
+}
+
+func (session *stratumSession) setAuthorized() {
+	atomic.StoreInt32(&session.authorized, 1)
+}
+
+func (session *stratumSession) subscribe() interface{} {
+	atomic.StoreInt32(&session.subscribed, 1)
+	sessionId := session.extraNonceSuffix()
+	return []interface{}{
+		[][]string{{stratumSetDifficulty, sessionId}, {stratumNotify, sessionId}},
+		sessionId,
+		stratumExtraNonce2Size,
+	}
+}
+
+// the worker assigned bytes of the nonce
+func (session *stratumSession) extraNonceSuffix() string {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], session.id)
+	return hexutil.Encode(b[:])
+}
+
+// SendNewWork is called by the work dispatcher of the master, the job replaces the old jobs
+func (session *stratumSession) SendNewWork(msgCode int, work minemsg.Work) {
+	defaultWork, ok := work.(*minemsg.DefaultWork)
+	if !ok || msgCode != minemsg.NewDefaultWorkMsg {
+		log.Warn("stratum worker receive unsupported work", "worker", session.GetId(), "code", msgCode)
+		return
+	}
+
+	job, diffChanged := session.newJob(defaultWork, time.Now())
+	if diffChanged {
+		if err := session.sendDifficulty(); err != nil {
+			log.Info("send stratum difficulty failed", "worker", session.GetId(), "err", err)
+			return
+		}
+	}
+	if err := session.notify(job); err != nil {
+		log.Info("send stratum job failed", "worker", session.GetId(), "err", err)
+	}
+}
+
+func (session *stratumSession) newJob(defaultWork *minemsg.DefaultWork, now time.Time) (*stratumJob, bool) {
+	work := *defaultWork
+	binary.BigEndian.PutUint32(work.BlockHeader.Nonce[4:8], session.id)
+	work.CalBlockRlpWithoutNonce()
+
+	session.lock.Lock()
+	defer session.lock.Unlock()
+
+	diffChanged := session.retarget(now, false)
+	session.jobSeq++
+	job := &stratumJob{
+		id:     fmt.Sprintf("%x", session.jobSeq),
+		work:   &work,
+		target: stratumJobTarget(session.difficulty, work.BlockHeader.Diff),
+		shares: map[common.BlockNonce]struct{}{},
+	}
+	// the jobs of the old blocks can't be submitted
+	if len(session.jobs) > 0 && session.jobs[len(session.jobs)-1].work.BlockHeader.Number != work.BlockHeader.Number {
+		session.jobs = nil
+	}
+	session.jobs = append(session.jobs, job)
+	if len(session.jobs) > stratumMaxJobs {
+		session.jobs = session.jobs[len(session.jobs)-stratumMaxJobs:]
+	}
+	return job, diffChanged
+}
+
+// adjust the difficulty to the share rate of the worker, return true if the difficulty changed
+func (session *stratumSession) retarget(now time.Time, share bool) bool {
+	if share {
+		session.retargetShares++
+	}
+	elapsed := now.Sub(session.retargetAt)
+	if elapsed < stratumRetargetInterval && session.retargetShares < stratumRetargetShares {
+		return false
+	}
+
+	oldDifficulty := session.difficulty
+	if session.retargetShares == 0 || elapsed/time.Duration(session.retargetShares) > 2*stratumShareInterval {
+		session.difficulty /= 2
+	} else if elapsed/time.Duration(session.retargetShares) < stratumShareInterval/2 {
+		session.difficulty *= 2
+	}
+	if session.difficulty < minStratumDifficulty {
+		session.difficulty = minStratumDifficulty
+	}
+	if session.difficulty > maxStratumDifficulty {
+		session.difficulty = maxStratumDifficulty
+	}
+	session.retargetAt = now
+	session.retargetShares = 0
+	return session.difficulty != oldDifficulty
+}
+
+// check the submitted share, the work is returned if the share is a block. difficulty is the share difficulty of the job
+func (session *stratumSession) checkShare(jobId, extraNonce2 string) (work *minemsg.DefaultWork, difficulty float64, err error) {
+	session.lock.Lock()
+	defer session.lock.Unlock()
+
+	err = errStratumStaleJob
+	var job *stratumJob
+	for _, j := range session.jobs {
+		if j.id == jobId {
+			job, err = j, nil
+		}
+	}
+	var nonce common.BlockNonce
+	if err == nil {
+		b, decodeErr := hexutil.Decode(extraNonce2)
+		if decodeErr != nil || len(b) != stratumExtraNonce2Size {
+			err = errStratumBadParams
+		} else {
+			nonce = job.work.BlockHeader.Nonce
+			copy(nonce[stratumExtraNonce1Size:], b)
+		}
+	}
+	if err == nil {
+		if _, ok := job.shares[nonce]; ok {
+			err = errStratumDuplicateShare
+		}
+	}
+
+	var hash common.Hash
+	if err == nil {
+		shareWork := *job.work
+		shareWork.BlockHeader.Nonce = nonce
+		if hash, err = shareWork.CalHash(); err == nil && hash.Big().Cmp(job.target) > 0 {
+			err = errStratumLowDifficulty
+		}
+	}
+	if err != nil {
+		session.rejectedShares++
+		return nil, 0, err
+	}
+
+	job.shares[nonce] = struct{}{}
+	session.acceptedShares++
+	session.retarget(time.Now(), true)
+	difficulty = shareDifficulty(job.target)
+	if !hash.ValidHashForDifficulty(job.work.BlockHeader.Diff) {
+		return nil, difficulty, nil
+	}
+
+	session.minedBlocks++
+	blockWork := *job.work
+	blockWork.BlockHeader.Nonce = nonce
+	blockWork.ResultNonce = nonce
+	blockWork.WorkerCoinbaseAddress = session.CurrentCoinbaseAddress()
+	return &blockWork, difficulty, nil
+}
+
+func (session *stratumSession) sendDifficulty() error {
+	session.lock.Lock()
+	difficulty := session.difficulty
+	session.lock.Unlock()
+	return session.write(&stratumNotification{Method: stratumSetDifficulty, Params: []interface{}{difficulty}})
+}
+
+func (session *stratumSession) notify(job *stratumJob) error {
+	nonce := job.work.BlockHeader.Nonce
+	return session.write(&stratumNotification{Method: stratumNotify, Params: []interface{}{
+		job.id,
+		hexutil.Encode(job.work.RlpPreCal),
+		hexutil.Encode(nonce[:stratumExtraNonce1Size]),
+		hexutil.Encode(common.BigToHash(job.target).Bytes()),
+		job.work.BlockHeader.Number,
+		true,
+	}})
+}
+
+func (session *stratumSession) reply(id json.RawMessage, result interface{}, err error) error {
+	resp := &stratumResponse{Id: id, Result: result}
+	if err != nil {
+		code, ok := stratumErrorCodes[err]
+		if !ok {
+			code = 20
+		}
+		resp.Result = nil
+		resp.Error = []interface{}{code, err.Error(), nil}
+	}
+	return session.write(resp)
+}
+
+func (session *stratumSession) write(v interface{}) error {
+	session.writeLock.Lock()
+	defer session.writeLock.Unlock()
+	session.conn.SetWriteDeadline(time.Now().Add(stratumWriteTimeout))
+	return session.encoder.Encode(v)
+}
+
+// shares of the worker since connected
+func (session *stratumSession) shareStats() (accepted, rejected, blocks uint64) {
+	session.lock.Lock()
+	defer session.lock.Unlock()
+	return session.acceptedShares, session.rejectedShares, session.minedBlocks
+}
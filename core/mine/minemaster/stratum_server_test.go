@@ -0,0 +1,233 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package minemaster
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/hexutil"
+	"github.com/dipperin/dipperin-core/core/mine/minemsg"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/tests/factory"
+	"github.com/dipperin/dipperin-core/third-party/crypto/cs-crypto"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+type fakeStratumMaster struct {
+	Master
+}
+
+func (m *fakeStratumMaster) Mining() bool {
+	return true
+}
+
+type fakeStratumMasterServer struct {
+	MasterServer
+	registered   chan WorkerForMaster
+	unRegistered chan WorkerId
+	submitted    chan minemsg.Work
+}
+
+func (s *fakeStratumMasterServer) RegisterWorker(worker WorkerForMaster) {
+	s.registered <- worker
+}
+
+func (s *fakeStratumMasterServer) UnRegisterWorker(workerId WorkerId) {
+	s.unRegistered <- workerId
+}
+
+func (s *fakeStratumMasterServer) ReceiveMsg(workerID WorkerId, code uint64, msg interface{}) {
+	s.submitted <- msg.(minemsg.Work)
+}
+
+type stratumTestClient struct {
+	t       *testing.T
+	conn    net.Conn
+	scanner *bufio.Scanner
+	id      int
+}
+
+func (c *stratumTestClient) call(method string, params ...string) map[string]interface{} {
+	c.id++
+	b, err := json.Marshal(map[string]interface{}{"id": c.id, "method": method, "params": params})
+	assert.NoError(c.t, err)
+	_, err = c.conn.Write(append(b, '\n'))
+	assert.NoError(c.t, err)
+	return c.read()
+}
+
+func (c *stratumTestClient) read() map[string]interface{} {
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	assert.True(c.t, c.scanner.Scan())
+	var msg map[string]interface{}
+	assert.NoError(c.t, json.Unmarshal(c.scanner.Bytes(), &msg))
+	return msg
+}
+
+func stratumErrorCode(msg map[string]interface{}) int {
+	if msg["error"] == nil {
+		return 0
+	}
+	return int(msg["error"].([]interface{})[0].(float64))
+}
+
+// find a extranonce2 whose share hash does or doesn't meet the target
+func grindStratumShare(headerRlp, extraNonce1 []byte, target *big.Int, valid bool) string {
+	extraNonce2 := make([]byte, stratumExtraNonce2Size)
+	for i := uint64(0); ; i++ {
+		binary.BigEndian.PutUint64(extraNonce2[stratumExtraNonce2Size-8:], i)
+		raw := append(append(append([]byte{}, headerRlp...), extraNonce1...), extraNonce2...)
+		if (cs_crypto.Keccak256Hash(raw).Big().Cmp(target) <= 0) == valid {
+			return hexutil.Encode(extraNonce2)
+		}
+	}
+}
+
+func TestStratumServer(t *testing.T) {
+	mServer := &fakeStratumMasterServer{
+		registered:   make(chan WorkerForMaster, 1),
+		unRegistered: make(chan WorkerId, 1),
+		submitted:    make(chan minemsg.Work, 1),
+	}
+	block := factory.CreateBlock2(common.HexToDiff("0x1effffff"), 3)
+	s := MakeStratumServer("127.0.0.1:0", &fakeStratumMaster{}, mServer)
+	s.getCurWorkBlockFunc = func() model.AbstractBlock { return block }
+	assert.NoError(t, s.Start())
+	defer s.Stop()
+
+	conn, err := net.Dial("tcp", s.Addr().String())
+	assert.NoError(t, err)
+	c := &stratumTestClient{t: t, conn: conn, scanner: bufio.NewScanner(conn)}
+
+	assert.Equal(t, 25, stratumErrorCode(c.call(stratumAuthorize, "0x0000970e8128aB834E8EAC17aB8E3812f010678CF791", "x")))
+	assert.Equal(t, 20, stratumErrorCode(c.call("mining.unknown")))
+
+	resp := c.call(stratumSubscribe, "test-miner")
+	assert.Equal(t, 0, stratumErrorCode(resp))
+	assert.Equal(t, float64(stratumExtraNonce2Size), resp["result"].([]interface{})[2])
+
+	assert.Equal(t, 24, stratumErrorCode(c.call(stratumSubmit, "rig", "1", "0x00")))
+	assert.Equal(t, 24, stratumErrorCode(c.call(stratumAuthorize, "rig", "x")))
+	coinbase := common.HexToAddress("0x0000970e8128aB834E8EAC17aB8E3812f010678CF791")
+	resp = c.call(stratumAuthorize, coinbase.Hex()+".rig", "x")
+	assert.Equal(t, true, resp["result"])
+	worker := <-mServer.registered
+	assert.Equal(t, coinbase, worker.CurrentCoinbaseAddress())
+
+	// the difficulty and the current work are sent after authorized
+	msg := c.read()
+	assert.Equal(t, stratumSetDifficulty, msg["method"])
+	assert.Equal(t, defaultStratumDifficulty, msg["params"].([]interface{})[0])
+	msg = c.read()
+	assert.Equal(t, stratumNotify, msg["method"])
+	params := msg["params"].([]interface{})
+	jobId := params[0].(string)
+	headerRlp, _ := hexutil.Decode(params[1].(string))
+	extraNonce1, _ := hexutil.Decode(params[2].(string))
+	target, _ := hexutil.Decode(params[3].(string))
+	assert.Equal(t, float64(3), params[4])
+	assert.Equal(t, []byte{0, 0, 0, 1}, extraNonce1[4:])
+	// the share target isn't harder than the block
+	assert.Equal(t, block.Difficulty().Big(), new(big.Int).SetBytes(target))
+
+	assert.Equal(t, 21, stratumErrorCode(c.call(stratumSubmit, "rig", "ff", "0x00")))
+	assert.Equal(t, 20, stratumErrorCode(c.call(stratumSubmit, "rig", jobId, "0x00")))
+	lowShare := grindStratumShare(headerRlp, extraNonce1, new(big.Int).SetBytes(target), false)
+	assert.Equal(t, 23, stratumErrorCode(c.call(stratumSubmit, "rig", jobId, lowShare)))
+
+	share := grindStratumShare(headerRlp, extraNonce1, new(big.Int).SetBytes(target), true)
+	assert.Equal(t, true, c.call(stratumSubmit, "rig", jobId, share)["result"])
+	work := (<-mServer.submitted).(*stratumWork)
+	assert.Equal(t, coinbase, work.GetWorkerCoinbaseAddress())
+	assert.NoError(t, work.FillSealResult(block))
+	assert.True(t, block.RefreshHashCache().ValidHashForDifficulty(block.Difficulty()))
+	assert.Equal(t, 22, stratumErrorCode(c.call(stratumSubmit, "rig", jobId, share)))
+
+	// the jobs of the old block are dropped
+	worker.SendNewWork(minemsg.NewDefaultWorkMsg, &minemsg.DefaultWork{BlockHeader: *factory.CreateBlock2(block.Difficulty(), 4).Header().(*model.Header)})
+	msg = c.read()
+	assert.Equal(t, stratumNotify, msg["method"])
+	assert.Equal(t, 21, stratumErrorCode(c.call(stratumSubmit, "rig", jobId, share)))
+
+	accepted, rejected, blocks := worker.(*stratumSession).shareStats()
+	assert.Equal(t, uint64(1), accepted)
+	assert.Equal(t, uint64(5), rejected)
+	assert.Equal(t, uint64(1), blocks)
+
+	conn.Close()
+	assert.Equal(t, worker.GetId(), <-mServer.unRegistered)
+}
+
+func TestStratumSession_retarget(t *testing.T) {
+	now := time.Now()
+	session := newStratumSession(1, nil)
+	session.retargetAt = now
+
+	assert.False(t, session.retarget(now.Add(time.Second), true))
+	// no share in the retarget interval
+	assert.True(t, session.retarget(now.Add(stratumRetargetInterval), false))
+	assert.Equal(t, defaultStratumDifficulty/2, session.difficulty)
+
+	// too many shares
+	now = session.retargetAt
+	for i := 0; i < stratumRetargetShares-1; i++ {
+		assert.False(t, session.retarget(now.Add(time.Second), true))
+	}
+	assert.True(t, session.retarget(now.Add(time.Second), true))
+	assert.Equal(t, defaultStratumDifficulty, session.difficulty)
+
+	// the share rate meets the interval
+	now = session.retargetAt
+	session.retargetShares = 5
+	assert.False(t, session.retarget(now.Add(6*stratumShareInterval), true))
+	assert.Equal(t, defaultStratumDifficulty, session.difficulty)
+
+	session.difficulty = minStratumDifficulty
+	assert.False(t, session.retarget(session.retargetAt.Add(stratumRetargetInterval), false))
+	assert.Equal(t, minStratumDifficulty, session.difficulty)
+}
+
+func TestStratumJobTarget(t *testing.T) {
+	hardDiff := common.HexToDiff("0x1a0fffff")
+	assert.Equal(t, stratumDiffOneTarget, stratumJobTarget(1, hardDiff))
+	assert.Equal(t, new(big.Int).Rsh(stratumDiffOneTarget, 1), stratumJobTarget(2, hardDiff))
+
+	easyDiff := common.HexToDiff("0x1effffff")
+	assert.Equal(t, easyDiff.Big(), stratumJobTarget(1, easyDiff))
+	assert.Equal(t, stratumMaxTarget, stratumJobTarget(1e-80, hardDiff))
+}
+
+func TestParseStratumUser(t *testing.T) {
+	addr := common.HexToAddress("0x0000970e8128aB834E8EAC17aB8E3812f010678CF791")
+	result, err := parseStratumUser(addr.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, addr, result)
+	result, err = parseStratumUser(addr.Hex() + ".rig1")
+	assert.NoError(t, err)
+	assert.Equal(t, addr, result)
+
+	_, err = parseStratumUser("0x1234")
+	assert.Equal(t, errStratumNotAuthorized, err)
+	_, err = parseStratumUser("rig")
+	assert.Equal(t, errStratumNotAuthorized, err)
+}
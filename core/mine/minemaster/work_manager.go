@@ -25,14 +25,31 @@ import (
 	"math/big"
 )
 
+const (
+	// PPLNS shares the reward of a block by the last N shares, PPS pays every share by its expected reward
+	RewardModePPLNS = "pplns"
+	RewardModePPS   = "pps"
+
+	defaultPPLNSWindow = float64(2)
+	// the share window is cut to keep the memory bounded when the shares are much easier than the block
+	maxPPLNSShares = 1 << 16
+)
+
 func newDefaultWorkManager(config MineConfig) *defaultWorkManager {
-	return &defaultWorkManager{
+	manager := &defaultWorkManager{
 		MineConfig: config,
 
 		performance: make(map[common.Address]workerPerformance),
 		reward:      make(map[common.Address]*big.Int),
+		paid:        make(map[common.Address]*big.Int),
+		payouts:     make(map[common.Address]*rewardPayout),
 		totalReward: new(big.Int),
 	}
+	// the balances are money of the workers, never start over with an empty ledger silently
+	if err := manager.loadRewards(); err != nil {
+		panic("load mine rewards failed: " + err.Error())
+	}
+	return manager
 }
 
 type defaultWorkManager struct {
@@ -41,10 +58,32 @@ type defaultWorkManager struct {
 	submitBlockLock sync.Mutex
 
 	performance map[common.Address]workerPerformance
-	reward      map[common.Address]*big.Int
+
+	// the shares are submitted by the stratum sessions concurrently
+	rewardLock sync.Mutex
+	// the recent shares of the PPLNS window
+	shares           []workerShare
+	sharesDifficulty float64
+	// the unpaid balances of the workers
+	reward map[common.Address]*big.Int
+	// the rewards confirmed on the chain
+	paid map[common.Address]*big.Int
+	// the payouts sent but not confirmed yet
+	payouts     map[common.Address]*rewardPayout
+	rewardDirty bool
+	// the payout txs are sent one by one to keep the nonces in order
+	payoutLock sync.Mutex
 
 	// wallet sums up all the rewards that this minemaster had received
 	totalReward *big.Int
+
+	getCurWorkBlockFunc getCurWorkBlockFunc
+}
+
+// a share submitted by the worker, the difficulty 1 target of the shares is 2^224
+type workerShare struct {
+	Address    common.Address `json:"address"`
+	Difficulty float64        `json:"difficulty"`
 }
 
 func (manager *defaultWorkManager) subtractPerformance(address common.Address, performance uint64) {
@@ -62,11 +101,13 @@ func (manager *defaultWorkManager) subtractPerformance(address common.Address, p
 }
 
 func (manager *defaultWorkManager) subtractReward(address common.Address, reward *big.Int) {
+	manager.rewardLock.Lock()
+	defer manager.rewardLock.Unlock()
+
 	if r := manager.reward[address]; r != nil {
 		if r.Cmp(reward) > 0 {
-			newPer := r.Sub(r, reward)
-			manager.clearReward(address)
-			manager.reward[address] = newPer
+			manager.reward[address] = new(big.Int).Sub(r, reward)
+			manager.rewardDirty = true
 		} else {
 			log.Debug("reward is less than current reward", "reward", reward, "current reward", r)
 		}
@@ -80,48 +121,163 @@ func (manager *defaultWorkManager) clearPerformance(address common.Address) {
 }
 
 func (manager *defaultWorkManager) clearReward(address common.Address) {
+	manager.rewardLock.Lock()
+	defer manager.rewardLock.Unlock()
+
 	delete(manager.reward, address)
+	manager.rewardDirty = true
 }
 
+// getReward returns the unpaid balance of the worker
 func (manager *defaultWorkManager) getReward(address common.Address) *big.Int {
+	manager.rewardLock.Lock()
+	defer manager.rewardLock.Unlock()
+
 	if manager.reward[address] == nil {
 		return big.NewInt(0)
 	}
-	return manager.reward[address]
+	return new(big.Int).Set(manager.reward[address])
 }
 
-// divideReward updates the reward distribution every time it is called.
-// it refreshes the reward map according to the performance and totalReward
-func (manager *defaultWorkManager) divideReward(coinbase *big.Int) map[common.Address]*big.Int {
-	res := make(map[common.Address]*big.Int)
+// must be called with the reward lock held
+func (manager *defaultWorkManager) addReward(address common.Address, reward *big.Int) {
+	if reward.Sign() <= 0 {
+		return
+	}
+	if manager.reward[address] == nil {
+		manager.reward[address] = new(big.Int)
+	}
+	manager.reward[address].Add(manager.reward[address], reward)
+	manager.rewardDirty = true
+}
 
-	manager.totalReward.Add(manager.totalReward, coinbase)
-	totalPerformance := uint64(0)
+func (manager *defaultWorkManager) rewardMode() string {
+	if manager.Reward.Mode == "" {
+		return RewardModePPLNS
+	}
+	return manager.Reward.Mode
+}
 
-	// divide equally
-	for _, v := range manager.performance {
-		totalPerformance += v.getPerformance()
+func (manager *defaultWorkManager) curWorkBlock() model.AbstractBlock {
+	if manager.getCurWorkBlockFunc == nil {
+		return nil
 	}
+	return manager.getCurWorkBlockFunc()
+}
 
-	performance := big.NewInt(int64(totalPerformance))
-	// could not avoid two loops
-	for k, v := range manager.performance {
-		num := big.NewInt(int64(v.getPerformance()))
-		num.Mul(num, manager.totalReward)
-		res[k] = num.Quo(num, performance)
+// submitShare accounts a share of the worker. PPLNS keeps it in the window until enough newer shares
+// come, PPS credits the worker at once with the expected reward of the share in the current work block.
+func (manager *defaultWorkManager) submitShare(address common.Address, difficulty float64) {
+	block := manager.curWorkBlock()
+	if block == nil {
+		log.Warn("no work block to account the share", "worker", address.Hex())
+		return
+	}
+	blockDifficulty := shareDifficulty(block.Difficulty().Big())
+	if difficulty <= 0 || blockDifficulty <= 0 {
+		return
 	}
 
-	// refresh the reward map
-	manager.reward = res
+	if manager.rewardMode() == RewardModePPS {
+		reward := proportionReward(manager.blockReward(block), difficulty, blockDifficulty)
+		manager.rewardLock.Lock()
+		manager.addReward(address, reward)
+		manager.rewardLock.Unlock()
+		return
+	}
 
-	return manager.reward
+	manager.rewardLock.Lock()
+	defer manager.rewardLock.Unlock()
+	manager.shares = append(manager.shares, workerShare{Address: address, Difficulty: difficulty})
+	manager.sharesDifficulty += difficulty
+	manager.trimShares(blockDifficulty)
+	manager.rewardDirty = true
+}
+
+// drop the oldest shares out of the window, which is the multiple of the block difficulty
+func (manager *defaultWorkManager) trimShares(blockDifficulty float64) {
+	window := manager.Reward.PPLNSWindow
+	if window <= 0 {
+		window = defaultPPLNSWindow
+	}
+	window *= blockDifficulty
+
+	drop := 0
+	for drop < len(manager.shares)-1 {
+		if len(manager.shares)-drop <= maxPPLNSShares && manager.sharesDifficulty-manager.shares[drop].Difficulty < window {
+			break
+		}
+		manager.sharesDifficulty -= manager.shares[drop].Difficulty
+		drop++
+	}
+	manager.shares = manager.shares[drop:]
+}
+
+// divideReward credits the reward of a block mined by this master. PPLNS shares it by the difficulty of
+// the shares in the window, PPS has paid the shares in advance so the reward is kept by the master.
+func (manager *defaultWorkManager) divideReward(coinbase *big.Int) map[common.Address]*big.Int {
+	manager.rewardLock.Lock()
+	defer manager.rewardLock.Unlock()
+
+	res := make(map[common.Address]*big.Int)
+	manager.totalReward.Add(manager.totalReward, coinbase)
+	manager.rewardDirty = true
+	if manager.rewardMode() == RewardModePPS {
+		return res
+	}
+
+	weights := make(map[common.Address]float64)
+	for _, share := range manager.shares {
+		weights[share.Address] += share.Difficulty
+	}
+	if manager.sharesDifficulty <= 0 {
+		log.Warn("no share in the PPLNS window, the block reward is kept by the master", "reward", coinbase)
+		return res
+	}
+	for address, weight := range weights {
+		res[address] = proportionReward(coinbase, weight, manager.sharesDifficulty)
+		manager.addReward(address, res[address])
+	}
+	return res
 }
 
 func (manager *defaultWorkManager) onNewBlock(block model.AbstractBlock) {
-	coinbase := block.CoinBase()
-	txFees := block.GetTransactionFees()
+	divided := manager.divideReward(manager.blockReward(block))
+	log.Info("mine master divide block reward", "block number", block.Number(), "workers", len(divided))
+
+	if err := manager.saveRewards(); err != nil {
+		log.Error("save mine rewards failed", "err", err)
+	}
+}
+
+// the coinbase reward and the tx fees of the block
+func (manager *defaultWorkManager) blockReward(block model.AbstractBlock) *big.Int {
+	reward := block.CoinBase()
+	if manager.Reward.Calculator != nil {
+		if r, err := manager.Reward.Calculator.GetMineMasterDIPReward(block); err != nil {
+			log.Warn("calculate the block reward failed", "block number", block.Number(), "err", err)
+		} else {
+			reward = r
+		}
+	}
+	return new(big.Int).Add(reward, block.GetTransactionFees())
+}
+
+// the share difficulty of the target, the target of the difficulty 1 is 2^224
+func shareDifficulty(target *big.Int) float64 {
+	if target.Sign() <= 0 {
+		return 0
+	}
+	difficulty, _ := new(big.Float).Quo(new(big.Float).SetInt(stratumDiffOneTarget), new(big.Float).SetInt(target)).Float64()
+	return difficulty
+}
 
-	manager.divideReward(coinbase.Add(coinbase, txFees))
+// reward * weight / total, the fraction is dropped
+func proportionReward(reward *big.Int, weight, total float64) *big.Int {
+	result := new(big.Float).SetPrec(256).SetInt(reward)
+	result.Mul(result, big.NewFloat(weight)).Quo(result, big.NewFloat(total))
+	r, _ := result.Int(nil)
+	return r
 }
 
 func (manager *defaultWorkManager) getPerformance(address common.Address) uint64 {
@@ -18,12 +18,17 @@
 package minemaster
 
 import (
+	"errors"
 	"github.com/dipperin/dipperin-core/common"
 	"github.com/dipperin/dipperin-core/core/bloom"
 	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/tests/factory"
 	"testing"
 	"math/big"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"sync/atomic"
 )
 
@@ -136,7 +141,7 @@ func (fakeCalculableBlock) Version() uint64 {
 }
 
 func (fakeCalculableBlock) Number() uint64 {
-	panic("implement me")
+	return 1
 }
 
 func (fakeCalculableBlock) Difficulty() common.Difficulty {
@@ -229,8 +234,14 @@ func fakeMineConfig() MineConfig {
 	}
 }
 
+// the share difficulty of the work block is about 2^20
+func fakeWorkBlock() model.AbstractBlock {
+	return factory.CreateBlock2(common.HexToDiff("0x1a0fffff"), 1)
+}
+
 func TestWorkerManager_GetReward(t *testing.T) {
 	manager := newDefaultWorkManager(fakeMineConfig())
+	manager.getCurWorkBlockFunc = fakeWorkBlock
 	block := fakeCalculableBlock{}
 
 	worker1 := common.HexToAddress("0x123")
@@ -246,6 +257,9 @@ func TestWorkerManager_GetReward(t *testing.T) {
 	manager.submitBlock(worker1, &model.Block{})
 	manager.submitBlock(worker2, &model.Block{})
 	manager.submitBlock(worker2, &model.Block{})
+	manager.submitShare(worker1, 1)
+	manager.submitShare(worker2, 1)
+	manager.submitShare(worker2, 1)
 
 	assert.EqualValues(t, 1, manager.getPerformance(worker1))
 	assert.EqualValues(t, 2, manager.getPerformance(worker2))
@@ -261,7 +275,7 @@ func TestWorkerManager_GetReward(t *testing.T) {
 	assert.EqualValues(t, 1, manager.getPerformance(worker1))
 	assert.EqualValues(t, 2, manager.getPerformance(worker2))
 
-	// we have only one worker, all the rewards go to that worker
+	// the reward is shared by the difficulty of the shares
 	assert.EqualValues(t, big.NewInt(7e9), manager.getReward(worker1))
 	assert.EqualValues(t, big.NewInt(14e9), manager.getReward(worker2))
 
@@ -274,6 +288,7 @@ func TestWorkerManager_GetReward(t *testing.T) {
 
 func TestWorkerManager_WithdrawReward(t *testing.T) {
 	manager := newDefaultWorkManager(fakeMineConfig())
+	manager.getCurWorkBlockFunc = fakeWorkBlock
 	rcBlock := fakeCalculableBlock{}
 	minedBlock := &model.Block{}
 
@@ -283,6 +298,8 @@ func TestWorkerManager_WithdrawReward(t *testing.T) {
 	manager.submitBlock(worker1, minedBlock)
 	manager.submitBlock(worker2, minedBlock)
 	manager.submitBlock(worker2, minedBlock)
+	manager.submitShare(worker1, 1)
+	manager.submitShare(worker2, 2)
 
 	manager.onNewBlock(rcBlock)
 	manager.onNewBlock(rcBlock)
@@ -302,6 +319,217 @@ func TestWorkerManager_WithdrawReward(t *testing.T) {
 	assert.EqualValues(t, 1, manager.getPerformance(worker2))
 }
 
+func TestWorkerManager_PPLNSWindow(t *testing.T) {
+	config := fakeMineConfig()
+	config.Reward.PPLNSWindow = 1
+	manager := newDefaultWorkManager(config)
+	manager.getCurWorkBlockFunc = fakeWorkBlock
+	blockDifficulty := shareDifficulty(fakeWorkBlock().Difficulty().Big())
+	assert.InDelta(t, float64(1<<20), blockDifficulty, 2)
+
+	worker1 := common.HexToAddress("0x123")
+	worker2 := common.HexToAddress("0x123223")
+
+	// the share of worker1 slides out of the window
+	manager.submitShare(worker1, blockDifficulty)
+	manager.submitShare(worker2, blockDifficulty/2)
+	assert.Len(t, manager.shares, 2)
+	manager.submitShare(worker2, blockDifficulty/2)
+	assert.Len(t, manager.shares, 2)
+	assert.Equal(t, blockDifficulty, manager.sharesDifficulty)
+
+	divided := manager.divideReward(big.NewInt(21e9))
+	assert.Len(t, divided, 1)
+	assert.EqualValues(t, big.NewInt(0), manager.getReward(worker1))
+	assert.EqualValues(t, big.NewInt(21e9), manager.getReward(worker2))
+
+	// the shares are kept for the next block
+	manager.divideReward(big.NewInt(21e9))
+	assert.EqualValues(t, big.NewInt(42e9), manager.getReward(worker2))
+
+	// the share without the work block is ignored
+	manager.getCurWorkBlockFunc = nil
+	manager.submitShare(worker1, 1)
+	assert.Len(t, manager.shares, 2)
+}
+
+type fakeRewardCalculator struct{}
+
+func (fakeRewardCalculator) GetMineMasterDIPReward(block model.AbstractBlock) (*big.Int, error) {
+	return big.NewInt(10e9), nil
+}
+
+func TestWorkerManager_PPS(t *testing.T) {
+	config := fakeMineConfig()
+	config.Reward.Mode = RewardModePPS
+	config.Reward.Calculator = fakeRewardCalculator{}
+	manager := newDefaultWorkManager(config)
+	manager.getCurWorkBlockFunc = fakeWorkBlock
+	blockReward := manager.blockReward(fakeWorkBlock())
+	assert.Equal(t, new(big.Int).Add(big.NewInt(10e9), fakeWorkBlock().GetTransactionFees()), blockReward)
+
+	worker := common.HexToAddress("0x123")
+	manager.submitShare(worker, shareDifficulty(fakeWorkBlock().Difficulty().Big())/4)
+	assert.Equal(t, new(big.Int).Div(blockReward, big.NewInt(4)), manager.getReward(worker))
+	assert.Len(t, manager.shares, 0)
+
+	// the shares have been paid, the block reward goes to the master
+	manager.onNewBlock(fakeCalculableBlock{})
+	assert.Equal(t, new(big.Int).Div(blockReward, big.NewInt(4)), manager.getReward(worker))
+	assert.Equal(t, big.NewInt(25e9), manager.totalReward)
+}
+
+type fakeRewardSender struct {
+	from, to   common.Address
+	value, fee *big.Int
+	nonce      uint64
+	sent       int
+	err        error
+
+	chainNonce  uint64
+	walletNonce uint64
+	mined       map[common.Hash]bool
+}
+
+func (s *fakeRewardSender) SendTransaction(from, to common.Address, value, transactionFee *big.Int, data []byte, nonce *uint64) (common.Hash, error) {
+	if s.err != nil {
+		return common.Hash{}, s.err
+	}
+	s.from, s.to, s.value, s.fee, s.nonce = from, to, value, transactionFee, *nonce
+	s.sent++
+	s.walletNonce = *nonce + 1
+	return common.Hash{byte(s.sent)}, nil
+}
+
+func (s *fakeRewardSender) Transaction(hash common.Hash) (*model.Transaction, common.Hash, uint64, uint64, error) {
+	if s.mined[hash] {
+		return &model.Transaction{}, common.Hash{}, 1, 0, nil
+	}
+	return nil, common.Hash{}, 0, 0, nil
+}
+
+func (s *fakeRewardSender) GetTransactionNonce(addr common.Address) (uint64, error) {
+	return s.chainNonce, nil
+}
+
+func (s *fakeRewardSender) GetAddressNonceFromWallet(address common.Address) (uint64, error) {
+	return s.walletNonce, nil
+}
+
+func TestWorkerManager_PayRewards(t *testing.T) {
+	config := fakeMineConfig()
+	manager := newDefaultWorkManager(config)
+	worker1 := common.HexToAddress("0x123")
+	worker2 := common.HexToAddress("0x123223")
+	manager.reward[worker1] = big.NewInt(1e9)
+	manager.reward[worker2] = big.NewInt(5e8)
+	assert.Equal(t, errNoRewardSender, manager.payReward(worker1))
+
+	sender := &fakeRewardSender{chainNonce: 3, mined: make(map[common.Hash]bool)}
+	manager.Reward.Sender = sender
+	assert.Equal(t, errNoReward, manager.payReward(common.HexToAddress("0x1")))
+
+	// only the balance reaching the threshold is paid, the fee is paid by the worker
+	manager.payRewards(big.NewInt(6e8))
+	assert.Equal(t, 1, sender.sent)
+	assert.Equal(t, config.GetCoinbaseAddr(), sender.from)
+	assert.Equal(t, worker1, sender.to)
+	assert.Equal(t, uint64(3), sender.nonce)
+	assert.Equal(t, big.NewInt(1e9), new(big.Int).Add(sender.value, sender.fee))
+	assert.Equal(t, big.NewInt(0), manager.getReward(worker1))
+
+	// the payout is pending until the tx is on the chain
+	assert.Nil(t, manager.paid[worker1])
+	manager.reward[worker1] = big.NewInt(1e9)
+	assert.Equal(t, errPayoutPending, manager.payReward(worker1))
+	manager.payRewards(big.NewInt(6e8))
+	assert.Equal(t, 1, sender.sent)
+
+	sender.mined[common.Hash{1}] = true
+	sender.chainNonce = 4
+	manager.confirmPayouts()
+	assert.Equal(t, sender.value, manager.paid[worker1])
+	assert.Len(t, manager.payouts, 0)
+
+	// the balance is kept if the tx failed
+	sender.err = errors.New("test")
+	assert.Error(t, manager.payReward(worker2))
+	assert.Equal(t, big.NewInt(5e8), manager.getReward(worker2))
+	assert.Len(t, manager.payouts, 0)
+
+	// the balance is credited back if the nonce of the payout is used by another tx
+	sender.err = nil
+	assert.NoError(t, manager.payReward(worker2))
+	assert.Equal(t, uint64(4), sender.nonce)
+	manager.confirmPayouts()
+	assert.Equal(t, big.NewInt(0), manager.getReward(worker2))
+	sender.chainNonce = 5
+	manager.confirmPayouts()
+	assert.Equal(t, big.NewInt(5e8), manager.getReward(worker2))
+	assert.Nil(t, manager.paid[worker2])
+
+	manager.reward[worker2] = big.NewInt(1)
+	assert.Equal(t, errRewardTooLow, manager.payReward(worker2))
+}
+
+func TestWorkerManager_SaveRewards(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mine_rewards")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	config := fakeMineConfig()
+	config.Reward.File = filepath.Join(dir, "mine-rewards.json")
+	manager := newDefaultWorkManager(config)
+	manager.getCurWorkBlockFunc = fakeWorkBlock
+	worker1 := common.HexToAddress("0x123")
+	worker2 := common.HexToAddress("0x123223")
+	manager.submitShare(worker1, 1)
+	manager.submitShare(worker2, 3)
+	manager.onNewBlock(fakeCalculableBlock{})
+	sender := &fakeRewardSender{mined: make(map[common.Hash]bool)}
+	manager.Reward.Sender = sender
+	assert.NoError(t, manager.payReward(worker2))
+	assert.NoError(t, manager.saveRewards())
+
+	// the balances and the pending payouts are restored after restart
+	loaded := newDefaultWorkManager(config)
+	assert.Equal(t, manager.getReward(worker1), loaded.getReward(worker1))
+	assert.Equal(t, big.NewInt(0), loaded.getReward(worker2))
+	assert.Equal(t, manager.payouts, loaded.payouts)
+
+	sender.mined[common.Hash{1}] = true
+	loaded.Reward.Sender = sender
+	loaded.confirmPayouts()
+	assert.Equal(t, manager.payouts[worker2].Value, loaded.paid[worker2])
+	assert.Equal(t, manager.shares, loaded.shares)
+	assert.Equal(t, float64(4), loaded.sharesDifficulty)
+	assert.Equal(t, big.NewInt(21e9), loaded.totalReward)
+
+	assert.NoError(t, ioutil.WriteFile(config.Reward.File, []byte("{"), 0600))
+	assert.Panics(t, func() { newDefaultWorkManager(config) })
+}
+
+func TestRewardPayer(t *testing.T) {
+	assert.Nil(t, MakeRewardPayer(&fakeStratumMasterServer{}))
+
+	dir, err := ioutil.TempDir("", "mine_rewards")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	config := fakeMineConfig()
+	config.Reward.File = filepath.Join(dir, "mine-rewards.json")
+	_, mServer := MakeMineMaster(config)
+	payer := MakeRewardPayer(mServer)
+	assert.NotNil(t, payer)
+	assert.NoError(t, payer.Start())
+	payer.manager.subtractReward(common.HexToAddress("0x123"), big.NewInt(1))
+	payer.manager.clearReward(common.HexToAddress("0x123"))
+	payer.Stop()
+
+	_, err = os.Stat(config.Reward.File)
+	assert.NoError(t, err)
+}
+
 type fakeContext struct {
 	coinbase *atomic.Value
 }
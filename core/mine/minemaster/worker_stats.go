@@ -0,0 +1,206 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package minemaster
+
+import (
+	"errors"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/third-party/log"
+	"math"
+	"math/big"
+	"sort"
+	"time"
+)
+
+// the hash rate of a worker is estimated by the shares in the window
+const hashRateWindow = 10 * time.Minute
+
+var (
+	errWorkerNotFound  = errors.New("worker not found")
+	errMasterNotMining = errors.New("mine master isn't mining")
+	errInvalidCoinbase = errors.New("invalid coinbase address")
+)
+
+// WorkerStats is the mining statistics of a worker registered to the mine master
+type WorkerStats struct {
+	Id       WorkerId
+	Coinbase common.Address
+	// hashes per second estimated by the difficulty of the accepted shares
+	HashRate float64
+	// the submitted shares, a block is the only share of the local and p2p workers
+	Accepted uint64
+	Stale    uint64
+
+	RegisteredAt time.Time
+	LastSeen     time.Time
+	// the balance of the coinbase which isn't paid yet
+	PendingReward *big.Int
+}
+
+// the worker keeping its coinbase on the miner side is told to change it
+type coinbaseSyncer interface {
+	syncCoinbase(coinbase common.Address) error
+}
+
+// the worker holding a connection to the master, it is closed when the worker is kicked
+type disconnecter interface {
+	disconnect()
+}
+
+type timedShare struct {
+	at         time.Time
+	difficulty float64
+}
+
+type workerRecord struct {
+	worker       WorkerForMaster
+	registeredAt time.Time
+	lastSeen     time.Time
+	accepted     uint64
+	stale        uint64
+	// the accepted shares in the hash rate window
+	shares []timedShare
+}
+
+// drop the shares out of the window
+func (record *workerRecord) trimShares(now time.Time) {
+	i := 0
+	for i < len(record.shares) && now.Sub(record.shares[i].at) > hashRateWindow {
+		i++
+	}
+	record.shares = record.shares[i:]
+}
+
+// a share of difficulty d takes d * 2^32 hashes on average
+func (record *workerRecord) hashRate(now time.Time) float64 {
+	record.trimShares(now)
+	elapsed := now.Sub(record.registeredAt)
+	if elapsed > hashRateWindow {
+		elapsed = hashRateWindow
+	}
+	if elapsed < time.Second {
+		elapsed = time.Second
+	}
+	var total float64
+	for _, share := range record.shares {
+		total += share.difficulty
+	}
+	return total * math.Exp2(32) / elapsed.Seconds()
+}
+
+func (ms *master) addWorkerRecord(worker WorkerForMaster) {
+	now := time.Now()
+	ms.statsLock.Lock()
+	defer ms.statsLock.Unlock()
+	ms.workerRecords[worker.GetId()] = &workerRecord{worker: worker, registeredAt: now, lastSeen: now}
+}
+
+func (ms *master) removeWorkerRecord(workerId WorkerId) {
+	ms.statsLock.Lock()
+	defer ms.statsLock.Unlock()
+	delete(ms.workerRecords, workerId)
+}
+
+func (ms *master) getWorkerRecord(workerId WorkerId) *workerRecord {
+	ms.statsLock.Lock()
+	defer ms.statsLock.Unlock()
+	return ms.workerRecords[workerId]
+}
+
+// recordSubmit counts a submission of the worker, difficulty is the share difficulty of an accepted share
+func (ms *master) recordSubmit(workerId WorkerId, difficulty float64, accepted bool) {
+	ms.statsLock.Lock()
+	defer ms.statsLock.Unlock()
+	record := ms.workerRecords[workerId]
+	if record == nil {
+		return
+	}
+	now := time.Now()
+	record.lastSeen = now
+	if !accepted {
+		record.stale++
+		return
+	}
+	record.accepted++
+	record.shares = append(record.shares, timedShare{at: now, difficulty: difficulty})
+	record.trimShares(now)
+}
+
+// WorkerStats return the statistics of the registered workers sorted by the worker id
+func (ms *master) WorkerStats() []WorkerStats {
+	now := time.Now()
+	ms.statsLock.Lock()
+	result := make([]WorkerStats, 0, len(ms.workerRecords))
+	for id, record := range ms.workerRecords {
+		result = append(result, WorkerStats{
+			Id:           id,
+			Coinbase:     record.worker.CurrentCoinbaseAddress(),
+			HashRate:     record.hashRate(now),
+			Accepted:     record.accepted,
+			Stale:        record.stale,
+			RegisteredAt: record.registeredAt,
+			LastSeen:     record.lastSeen,
+		})
+	}
+	ms.statsLock.Unlock()
+
+	for i := range result {
+		result[i].PendingReward = ms.GetReward(result[i].Coinbase)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id < result[j].Id
+	})
+	return result
+}
+
+// KickWorker stops the worker and removes it from the master, the connection of a stratum worker or the peer of a remote worker is closed
+func (ms *master) KickWorker(workerId WorkerId) error {
+	if ms.stopped() {
+		return errMasterNotMining
+	}
+	record := ms.getWorkerRecord(workerId)
+	if record == nil {
+		return errWorkerNotFound
+	}
+	ms.unRegisterWorker(workerId)
+	// the worker is gone from the statistics at once, the loop stops it later
+	ms.removeWorkerRecord(workerId)
+	if d, ok := record.worker.(disconnecter); ok {
+		d.disconnect()
+	}
+	log.Info("kick mine worker", "worker", workerId)
+	return nil
+}
+
+// SetWorkerCoinbase changes the coinbase which the rewards of the worker are accounted to
+func (ms *master) SetWorkerCoinbase(workerId WorkerId, coinbase common.Address) error {
+	if coinbase.IsEmpty() {
+		return errInvalidCoinbase
+	}
+	record := ms.getWorkerRecord(workerId)
+	if record == nil {
+		return errWorkerNotFound
+	}
+	record.worker.SetCoinbase(coinbase)
+	if s, ok := record.worker.(coinbaseSyncer); ok {
+		if err := s.syncCoinbase(coinbase); err != nil {
+			return err
+		}
+	}
+	log.Info("set mine worker coinbase", "worker", workerId, "coinbase", coinbase.Hex())
+	return nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package minemaster
+
+import (
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/core/mine/minemsg"
+	"github.com/dipperin/dipperin-core/tests/peer"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestWorkerRecord_hashRate(t *testing.T) {
+	now := time.Now()
+	record := &workerRecord{registeredAt: now.Add(-time.Minute)}
+	assert.Equal(t, float64(0), record.hashRate(now))
+
+	record.shares = []timedShare{
+		{at: now.Add(-hashRateWindow - time.Second), difficulty: 100},
+		{at: now.Add(-time.Second), difficulty: 3},
+		{at: now, difficulty: 3},
+	}
+	// the first share is out of the window
+	assert.Equal(t, 6*math.Exp2(32)/60, record.hashRate(now))
+	assert.Len(t, record.shares, 2)
+
+	record.registeredAt = now.Add(-time.Hour)
+	assert.Equal(t, 6*math.Exp2(32)/hashRateWindow.Seconds(), record.hashRate(now))
+}
+
+func TestMaster_WorkerStats(t *testing.T) {
+	m := testMasterBuilder(testMineConfig)
+	m.setWorkDispatcher(&mockDispatch{})
+	coinbase := common.HexToAddress("0x1234")
+	worker := &mockWorker{workerId: "1", coinbase: coinbase}
+	p := peer_spec.PeerBuilder()
+	remote := newRemoteWorker(p, coinbase, "2")
+
+	assert.Equal(t, errMasterNotMining, m.KickWorker("1"))
+	m.Start()
+	m.registerWorker(worker)
+	m.registerWorker(remote)
+	m.recordSubmit("1", 2, true)
+	m.recordSubmit("1", 0, false)
+	m.recordSubmit("unknown", 1, true)
+
+	stats := m.WorkerStats()
+	assert.Len(t, stats, 2)
+	assert.Equal(t, WorkerId("1"), stats[0].Id)
+	assert.Equal(t, coinbase, stats[0].Coinbase)
+	assert.Equal(t, uint64(1), stats[0].Accepted)
+	assert.Equal(t, uint64(1), stats[0].Stale)
+	assert.True(t, stats[0].HashRate > 0)
+	assert.Equal(t, big.NewInt(0), stats[0].PendingReward)
+	assert.Equal(t, WorkerId("2"), stats[1].Id)
+	assert.Equal(t, uint64(0), stats[1].Accepted)
+
+	newCoinbase := common.HexToAddress("0x5678")
+	assert.Equal(t, errInvalidCoinbase, m.SetWorkerCoinbase("1", common.Address{}))
+	assert.Equal(t, errWorkerNotFound, m.SetWorkerCoinbase("unknown", newCoinbase))
+	assert.NoError(t, m.SetWorkerCoinbase("1", newCoinbase))
+	assert.Equal(t, newCoinbase, worker.CurrentCoinbaseAddress())
+	// the new coinbase is sent to the remote worker
+	assert.NoError(t, m.SetWorkerCoinbase("2", newCoinbase))
+	assert.Equal(t, newCoinbase, remote.CurrentCoinbaseAddress())
+	assert.Equal(t, uint64(minemsg.SetCurrentCoinbaseMsg), p.(*peer_spec.FakePeer).TestMsg)
+
+	assert.Equal(t, errWorkerNotFound, m.KickWorker("unknown"))
+	assert.NoError(t, m.KickWorker("2"))
+	assert.True(t, p.(*peer_spec.FakePeer).Disconnected)
+	stats = m.WorkerStats()
+	assert.Len(t, stats, 1)
+	assert.Equal(t, WorkerId("1"), stats[0].Id)
+
+	// the unregistration doesn't wait for the stopped master
+	m.Stop()
+	m.unRegisterWorker("1")
+}
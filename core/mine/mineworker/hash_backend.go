@@ -0,0 +1,61 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package mineworker
+
+import (
+	"github.com/dipperin/dipperin-core/common"
+	"golang.org/x/crypto/sha3"
+	"sync/atomic"
+)
+
+// HashBackend calculates the block hashes of a batch of nonces. It is called by all the miners at the same time,
+// so an implementation must be safe for concurrent use
+type HashBackend interface {
+	// Hash writes keccak256(rlpPreCal + nonces[i]) to hashes[i], the two slices have the same length
+	Hash(rlpPreCal []byte, nonces []common.BlockNonce, hashes []common.Hash)
+}
+
+var hashBackend atomic.Value
+
+func init() {
+	SetHashBackend(keccakHashBackend{})
+}
+
+// SetHashBackend replaces the hashing of the miners, e.g. with an assembly or a batched keccak implementation.
+// The works received afterwards use the new backend
+func SetHashBackend(backend HashBackend) {
+	hashBackend.Store(&backend)
+}
+
+func currentHashBackend() HashBackend {
+	return *hashBackend.Load().(*HashBackend)
+}
+
+// the pure go keccak256, it is the same as minemsg.DefaultWork.CalHash
+type keccakHashBackend struct{}
+
+func (keccakHashBackend) Hash(rlpPreCal []byte, nonces []common.BlockNonce, hashes []common.Hash) {
+	raw := make([]byte, len(rlpPreCal)+common.NonceLength)
+	copy(raw, rlpPreCal)
+	d := sha3.NewLegacyKeccak256()
+	for i := range nonces {
+		copy(raw[len(rlpPreCal):], nonces[i][:])
+		d.Reset()
+		d.Write(raw)
+		d.Sum(hashes[i][:0])
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package mineworker
+
+import (
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/core/mine/minemsg"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/tests/factory"
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+)
+
+type countHashBackend struct {
+	keccakHashBackend
+	count uint64
+}
+
+func (b *countHashBackend) Hash(rlpPreCal []byte, nonces []common.BlockNonce, hashes []common.Hash) {
+	atomic.AddUint64(&b.count, uint64(len(nonces)))
+	b.keccakHashBackend.Hash(rlpPreCal, nonces, hashes)
+}
+
+func newTestWork() *minemsg.DefaultWork {
+	block := factory.CreateBlock2(common.HexToDiff("0x1effffff"), 1)
+	work := &minemsg.DefaultWork{BlockHeader: *(block.Header().(*model.Header))}
+	work.CalBlockRlpWithoutNonce()
+	return work
+}
+
+func TestKeccakHashBackend_Hash(t *testing.T) {
+	work := newTestWork()
+	nonces := []common.BlockNonce{{1}, {2, 3}, {31: 4}}
+	hashes := make([]common.Hash, len(nonces))
+	keccakHashBackend{}.Hash(work.RlpPreCal, nonces, hashes)
+
+	for i := range nonces {
+		work.BlockHeader.Nonce = nonces[i]
+		expect, err := work.CalHash()
+		assert.NoError(t, err)
+		assert.Equal(t, expect, hashes[i])
+	}
+}
+
+func TestSetHashBackend(t *testing.T) {
+	backend := &countHashBackend{}
+	SetHashBackend(backend)
+	defer SetHashBackend(keccakHashBackend{})
+
+	// the nonce prefix of the split work is kept
+	work := newTestWork().Split(4)[3]
+	executor := NewDefaultWorkExecutor(work, &fakeWorkSubmitter{})
+	found := executor.ChangeNonce()
+	assert.Equal(t, uint64(hashBatchSize), atomic.LoadUint64(&backend.count))
+	if found {
+		assert.True(t, executor.TriedNonces() <= hashBatchSize)
+	} else {
+		assert.Equal(t, hashBatchSize, executor.TriedNonces())
+	}
+	assert.Equal(t, []byte{0, 0, 0, 3}, work.BlockHeader.Nonce[4:8])
+	assert.Equal(t, []byte{0, 0, 0, 3}, executor.nonces[hashBatchSize-1][4:8])
+}
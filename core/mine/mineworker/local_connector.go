@@ -64,7 +64,11 @@ func (conn *localConnector) WaitForCommit() {
 
 // implement for minemaster worker
 
-func (conn *localConnector) SetCoinbase(coinbase common.Address) {}
+func (conn *localConnector) SetCoinbase(coinbase common.Address) {
+	if conn.worker != nil {
+		conn.worker.SetCoinbaseAddress(coinbase)
+	}
+}
 
 func (conn *localConnector) Start() {
 	conn.worker.Start()
@@ -75,6 +75,11 @@ func Test_localConnector_SetCoinbase(t *testing.T) {
 	ms := mine_spec.MasterServerBuilder()
 	lc := newLocalConnector("123", ms)
 	lc.SetCoinbase(common.HexToAddress("0x123"))
+
+	w := newWorker(common.HexToAddress("0x123"), 1, lc)
+	lc.worker = w
+	lc.SetCoinbase(common.HexToAddress("0x456"))
+	assert.Equal(t, common.HexToAddress("0x456"), w.CurrentCoinbaseAddress())
 }
 
 func Test_localConnector_Start(t *testing.T) {
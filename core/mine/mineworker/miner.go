@@ -20,13 +20,17 @@ package mineworker
 import (
 	"github.com/dipperin/dipperin-core/common/util"
 	"github.com/dipperin/dipperin-core/third-party/log"
+	"math"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 const (
 	//newWorkReceiveTimeOut = 1 * time.Second
 	newWorkReceiveTimeOut = 500 * time.Millisecond
+	// the hash rate is the average of this interval
+	hashRateInterval = 5 * time.Second
 )
 
 func NewMiner() *defaultMiner {
@@ -45,6 +49,12 @@ type defaultMiner struct {
 	lock    sync.Mutex
 
 	mineStartAt time.Time
+
+	// hashes since rateStartAt, only changed by the mine loop
+	hashes      uint64
+	rateStartAt time.Time
+	// bits of the float64 hash rate
+	rate uint64
 }
 
 func (miner *defaultMiner) receiveWork(work workExecutor) {
@@ -71,6 +81,7 @@ func (miner *defaultMiner) stopMine() {
 	}
 
 	miner.curWork = nil
+	atomic.StoreUint64(&miner.rate, 0)
 }
 
 func (miner *defaultMiner) loop() {
@@ -110,8 +121,13 @@ func (miner *defaultMiner) doMine() {
 	if miner.curWork == nil {
 		miner.waitNewWork()
 	}
+	if miner.curWork == nil {
+		return
+	}
+	found := miner.curWork.ChangeNonce()
+	miner.countHashes(miner.curWork.TriedNonces())
 	// Submit if it is discovered, and wait for a new task
-	if miner.curWork != nil && miner.curWork.ChangeNonce() {
+	if found {
 		log.Info("miner found nonce", "use time", time.Now().Sub(miner.mineStartAt))
 
 		miner.curWork.Submit()
@@ -121,6 +137,8 @@ func (miner *defaultMiner) doMine() {
 
 func (miner *defaultMiner) waitNewWork() {
 	//log.Debug("miner waitNewWork")
+	// no hash while waiting
+	miner.resetHashRate()
 	select {
 	case miner.curWork = <-miner.newWorkChan:
 		miner.mineStartAt = time.Now()
@@ -130,6 +148,31 @@ func (miner *defaultMiner) waitNewWork() {
 }
 
 // check whether mining is stopped
+func (miner *defaultMiner) countHashes(count int) {
+	miner.hashes += uint64(count)
+	now := time.Now()
+	if miner.rateStartAt.IsZero() {
+		miner.rateStartAt = now
+		return
+	}
+	if elapsed := now.Sub(miner.rateStartAt); elapsed >= hashRateInterval {
+		atomic.StoreUint64(&miner.rate, math.Float64bits(float64(miner.hashes)/elapsed.Seconds()))
+		miner.hashes = 0
+		miner.rateStartAt = now
+	}
+}
+
+func (miner *defaultMiner) resetHashRate() {
+	miner.hashes = 0
+	miner.rateStartAt = time.Time{}
+	atomic.StoreUint64(&miner.rate, 0)
+}
+
+// hashRate return the hashes per second in the last interval
+func (miner *defaultMiner) hashRate() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&miner.rate))
+}
+
 func (miner *defaultMiner) stopped() bool {
 	// if stop chan is closed, it means the mining is stopped
 	return util.StopChanClosed(miner.stopChan)
@@ -39,6 +39,10 @@ func (fw *fakeWork) ChangeNonce() bool {
 	return true
 }
 
+func (fw *fakeWork) TriedNonces() int {
+	return 1
+}
+
 func (fw *fakeWork) Submit() {
 	//log.Debug("fakeWork submit", "id", fw.id, "fw", fw)
 	fw.submitWorkChan <- fw.id
@@ -99,3 +103,19 @@ func TestCanNotStartTwice(t *testing.T) {
 	m.startMine()
 	time.Sleep(50 * time.Millisecond)
 }
+
+func TestDefaultMiner_hashRate(t *testing.T) {
+	m := NewMiner()
+	assert.Equal(t, float64(0), m.hashRate())
+
+	m.countHashes(10)
+	assert.Equal(t, float64(0), m.hashRate())
+	// the rate is updated after the interval
+	m.rateStartAt = time.Now().Add(-2 * hashRateInterval)
+	m.countHashes(90)
+	assert.InDelta(t, 100/(2*hashRateInterval).Seconds(), m.hashRate(), 1)
+	assert.Equal(t, uint64(0), m.hashes)
+
+	m.resetHashRate()
+	assert.Equal(t, float64(0), m.hashRate())
+}
@@ -63,6 +63,13 @@ func (conn *RemoteConnector) OnNewMsg(msg p2p.Msg, p chain_communication.PmAbstr
 		conn.worker.Start()
 	case minemsg.StopMineMsg:
 		conn.worker.Stop()
+	case minemsg.SetCurrentCoinbaseMsg:
+		// the coinbase is changed by the master
+		var setCoinbaseReq minemsg.SetCurrentCoinbase
+		if err := msg.Decode(&setCoinbaseReq); err != nil {
+			return err
+		}
+		conn.worker.SetCoinbaseAddress(setCoinbaseReq.Coinbase)
 	case minemsg.WaitForCommitMsg:
 		// todo deal this msg
 
@@ -99,6 +99,17 @@ func TestRemoteConnector_OnNewMsg(t *testing.T) {
 	err = rc.OnNewMsg(p2p.Msg{Code: minemsg.WaitForCommitMsg}, p)
 	assert.NoError(t, err)
 
+	// the coinbase is changed by the master
+	coinbasePayload, _ := rlp.EncodeToBytes(minemsg.SetCurrentCoinbase{Coinbase: common.HexToAddress("0x5678")})
+	err = rc.OnNewMsg(p2p.Msg{
+		Code:    minemsg.SetCurrentCoinbaseMsg,
+		Payload: bytes.NewReader(coinbasePayload),
+	}, p)
+	assert.NoError(t, err)
+	assert.Equal(t, common.HexToAddress("0x5678"), w.CurrentCoinbaseAddress())
+	err = rc.OnNewMsg(p2p.Msg{Code: minemsg.SetCurrentCoinbaseMsg, Payload: bytes.NewReader([]byte{1})}, p)
+	assert.Error(t, err)
+
 	var register minemsg.Register
 
 	payload, _ := rlp.EncodeToBytes(register)
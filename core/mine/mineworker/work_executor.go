@@ -24,10 +24,14 @@ import (
 	"github.com/dipperin/dipperin-core/third-party/log/health-info-log"
 )
 
+// nonces hashed in one ChangeNonce, a batch lets the hash backend amortize its call overhead
+const hashBatchSize = 64
+
 func NewDefaultWorkExecutor(work *minemsg.DefaultWork, submitter workSubmitter) *defaultWorkExecutor {
 	ex := &defaultWorkExecutor{
 		curWork:   work,
 		submitter: submitter,
+		backend:   currentHashBackend(),
 		//nonceSuffix:big.NewInt(0),
 	}
 	return ex
@@ -41,18 +45,21 @@ type workSubmitter interface {
 type defaultWorkExecutor struct {
 	curWork   *minemsg.DefaultWork
 	submitter workSubmitter
+	backend   HashBackend
 
 	// The first 8 bytes are allocated by the fragment server and the miner server, and cannot be changed.
 	// The latter field is freely played by the miners.
 
 	nonceSuffix [common.NonceLength - 8]byte
 	//nonceSuffix *big.Int
+
+	nonces [hashBatchSize]common.BlockNonce
+	hashes [hashBatchSize]common.Hash
+	// nonces checked in the last ChangeNonce
+	tried int
 }
 
-//
-func (executor *defaultWorkExecutor) ChangeNonce() bool {
-	//todo use byte operation to optimal this nonce change step
-	// Nonce increments and verify the validity
+func (executor *defaultWorkExecutor) nextNonce() {
 	for index := len(executor.nonceSuffix) - 1; index >= 0; {
 		if executor.nonceSuffix[index] < 255 {
 			executor.nonceSuffix[index]++
@@ -62,33 +69,42 @@ func (executor *defaultWorkExecutor) ChangeNonce() bool {
 			index--
 		}
 	}
-	//log.Info("change nonce", "suffix", executor.nonceSuffix)
-	//put result to header nonce
-	copy(executor.curWork.BlockHeader.Nonce[8:], executor.nonceSuffix[:])
+}
 
-	//
-	//executor.nonceSuffix.Add(executor.nonceSuffix,big.NewInt(1))
-	//copy(executor.curWork.BlockHeader.Nonce[8:], executor.nonceSuffix.Bytes())
+func (executor *defaultWorkExecutor) ChangeNonce() bool {
+	executor.tried = 0
+	if len(executor.curWork.RlpPreCal) == 0 {
+		log.Info("search nonce", "error", "DefaultWork rlp be not calculated yet")
+		return false
+	}
+	// Nonce increments and put the batch to the header nonce
+	for i := range executor.nonces {
+		executor.nextNonce()
+		executor.nonces[i] = executor.curWork.BlockHeader.Nonce
+		copy(executor.nonces[i][8:], executor.nonceSuffix[:])
+	}
+	executor.backend.Hash(executor.curWork.RlpPreCal, executor.nonces[:], executor.hashes[:])
 
-	//log.Debug("defaultWorkExecutor ChangeNonce", "executor.curWork.BlockHeader.Hash()", executor.curWork.BlockHeader.Hash().Hex())
 	// check nonce valid
-	//if executor.curWork.BlockHeader.Hash().ValidHashForDifficulty(executor.curWork.BlockHeader.Diff) {
-	//new hash calculation replacing upstairs
-	bHash, err := executor.curWork.CalHash()
-	if err == nil {
-		if bHash.ValidHashForDifficulty(executor.curWork.BlockHeader.Diff) {
+	for i := range executor.hashes {
+		executor.tried++
+		if executor.hashes[i].ValidHashForDifficulty(executor.curWork.BlockHeader.Diff) {
 			// some thing interesting here
-			executor.curWork.ResultNonce = executor.curWork.BlockHeader.Nonce
+			executor.curWork.BlockHeader.Nonce = executor.nonces[i]
+			executor.curWork.ResultNonce = executor.nonces[i]
 			log.Info("found nonce", "diff", executor.curWork.BlockHeader.Diff.Hex(), "nonce", executor.curWork.BlockHeader.Nonce.Hex(), "block hash hex", executor.curWork.BlockHeader.Hash().Hex(), "coinbase address", executor.curWork.BlockHeader.CoinBaseAddress().Hex(), "block num", executor.curWork.BlockHeader.Number, "register root", executor.curWork.BlockHeader.RegisterRoot, "v root", executor.curWork.BlockHeader.VerificationRoot)
 			health_info_log.Info("found nonce", "height", executor.curWork.BlockHeader.Number)
 			return true
 		}
-	} else {
-		log.Info("search nonce", "error", err)
 	}
+	copy(executor.curWork.BlockHeader.Nonce[8:], executor.nonceSuffix[:])
 	return false
 }
 
+func (executor *defaultWorkExecutor) TriedNonces() int {
+	return executor.tried
+}
+
 func (executor *defaultWorkExecutor) Submit() {
 	executor.submitter.SubmitWork(executor.curWork)
 }
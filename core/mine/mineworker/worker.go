@@ -19,13 +19,22 @@ package mineworker
 
 import (
 	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/util"
 	"github.com/dipperin/dipperin-core/third-party/log"
+	"runtime"
 	"sync/atomic"
+	"time"
 )
 
+const hashRateLogInterval = time.Minute
+
 func newWorker(coinbaseAddr common.Address, workerCount int, connector connector) *worker {
 	worker := &worker{connector: connector}
 	worker.SetCoinbaseAddress(coinbaseAddr)
+	// use all the cores by default
+	if workerCount <= 0 {
+		workerCount = runtime.NumCPU()
+	}
 	for i := 0; i < workerCount; i++ {
 		worker.miners = append(worker.miners, NewMiner())
 	}
@@ -49,6 +58,7 @@ type worker struct {
 	miners []miner
 	coinbaseAddress atomic.Value
 	connector connector
+	stopChan chan struct{}
 }
 
 func (worker *worker) Miners() []miner {
@@ -65,6 +75,8 @@ func (worker *worker) Start() {
 	for _, m := range worker.miners {
 		m.startMine()
 	}
+	worker.stopChan = make(chan struct{})
+	go worker.logHashRate(worker.stopChan)
 }
 
 func (worker *worker) register() error {
@@ -78,6 +90,34 @@ func (worker *worker) Stop() {
 	for _, m := range worker.miners {
 		m.stopMine()
 	}
+	if worker.stopChan != nil && !util.StopChanClosed(worker.stopChan) {
+		close(worker.stopChan)
+	}
+}
+
+func (worker *worker) HashRates() []float64 {
+	rates := make([]float64, len(worker.miners))
+	for i, m := range worker.miners {
+		rates[i] = m.hashRate()
+	}
+	return rates
+}
+
+func (worker *worker) logHashRate(stopChan chan struct{}) {
+	ticker := time.NewTicker(hashRateLogInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			var total float64
+			for _, rate := range worker.HashRates() {
+				total += rate
+			}
+			log.Info("worker hash rate", "threads", len(worker.miners), "hashes/s", total)
+		case <-stopChan:
+			return
+		}
+	}
 }
 
 func (worker *worker) unRegister() {
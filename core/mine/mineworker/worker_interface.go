@@ -31,12 +31,16 @@ type Worker interface {
 	SetCoinbaseAddress(address common.Address)
 	// consult the current coinbase
 	CurrentCoinbaseAddress() common.Address
+	// hashes per second of each miner thread
+	HashRates() []float64
 }
 
 // Work comes with the function of modifying the random number and verifying compliance
 type workExecutor interface {
 	// Modify the nonce and verify that it is qualified. If it passes, it returns true.
 	ChangeNonce() bool
+	// the number of nonces checked in the last ChangeNonce
+	TriedNonces() int
 	// submit the mission
 	Submit()
 }
@@ -46,4 +50,5 @@ type miner interface {
 	startMine()
 	stopMine()
 	receiveWork(work workExecutor)
+	hashRate() float64
 }
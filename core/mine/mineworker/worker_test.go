@@ -19,6 +19,7 @@ package mineworker
 
 import (
 	"github.com/dipperin/dipperin-core/common"
+	"runtime"
 	"testing"
 	"github.com/stretchr/testify/assert"
 )
@@ -36,3 +37,9 @@ func TestNewDefaultWorker(t *testing.T) {
 	worker := newWorker(tmpAddr, 2, nil)
 	assert.Equal(t, tmpAddr, worker.CurrentCoinbaseAddress())
 }
+
+func TestWorker_HashRates(t *testing.T) {
+	worker := newWorker(common.Address{}, 0, nil)
+	assert.Len(t, worker.Miners(), runtime.NumCPU())
+	assert.Equal(t, make([]float64, runtime.NumCPU()), worker.HashRates())
+}
@@ -0,0 +1,124 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"errors"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"math/big"
+)
+
+var (
+	CrossChainDataErr = errors.New("invalid cross chain transaction data")
+)
+
+type CrossChainTxType uint8
+
+const (
+	CrossChainInvalid CrossChainTxType = iota
+	CrossChainRelay
+	CrossChainLock
+	CrossChainRedeem
+)
+
+/*
+Cross chain transactions all send to AddressCrossChain, the extra data tells the type
+* Relay: submit headers of another Dipperin chain, they must link to the checkpoint or relayed headers
+* Lock: lock the amount on this chain for the receiver on the target chain
+* Redeem: prove a lock transaction of the source chain with the transaction root of a relayed header
+*/
+type crossChainData struct {
+	Type CrossChainTxType
+	Data []byte
+}
+
+type CrossChainRelayData struct {
+	ChainId *big.Int
+	Headers []*Header
+}
+
+type CrossChainLockData struct {
+	TargetChainId *big.Int
+	Receiver      common.Address
+}
+
+type CrossChainRedeemData struct {
+	SourceChainId *big.Int
+	BlockHash     common.Hash
+	LockTx        []byte
+	Proof         [][]byte
+}
+
+func encodeCrossChainData(txType CrossChainTxType, data interface{}) ([]byte, error) {
+	enc, err := rlp.EncodeToBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	return rlp.EncodeToBytes(crossChainData{Type: txType, Data: enc})
+}
+
+// GetCrossChainTxType decode the type of the cross chain transaction extra data
+func GetCrossChainTxType(extraData []byte) CrossChainTxType {
+	var data crossChainData
+	if err := rlp.DecodeBytes(extraData, &data); err != nil {
+		return CrossChainInvalid
+	}
+	switch data.Type {
+	case CrossChainRelay, CrossChainLock, CrossChainRedeem:
+		return data.Type
+	default:
+		return CrossChainInvalid
+	}
+}
+
+// DecodeCrossChainData decode the extra data into the result if the type matches
+func DecodeCrossChainData(extraData []byte, txType CrossChainTxType, result interface{}) error {
+	var data crossChainData
+	if err := rlp.DecodeBytes(extraData, &data); err != nil {
+		return err
+	}
+	if data.Type != txType {
+		return CrossChainDataErr
+	}
+	return rlp.DecodeBytes(data.Data, result)
+}
+
+func newCrossChainTransaction(nonce uint64, amount, fee *big.Int, txType CrossChainTxType, data interface{}) (*Transaction, error) {
+	extraData, err := encodeCrossChainData(txType, data)
+	if err != nil {
+		return nil, err
+	}
+	return NewTransaction(nonce, common.HexToAddress(common.AddressCrossChain), amount, fee, extraData), nil
+}
+
+func NewCrossChainRelayTransaction(nonce uint64, chainId *big.Int, headers []*Header, fee *big.Int) (*Transaction, error) {
+	return newCrossChainTransaction(nonce, big.NewInt(0), fee, CrossChainRelay, CrossChainRelayData{ChainId: chainId, Headers: headers})
+}
+
+func NewCrossChainLockTransaction(nonce uint64, targetChainId *big.Int, receiver common.Address, amount, fee *big.Int) (*Transaction, error) {
+	return newCrossChainTransaction(nonce, amount, fee, CrossChainLock, CrossChainLockData{TargetChainId: targetChainId, Receiver: receiver})
+}
+
+func NewCrossChainRedeemTransaction(nonce uint64, sourceChainId *big.Int, blockHash common.Hash, lockTx []byte, proof [][]byte, fee *big.Int) (*Transaction, error) {
+	return newCrossChainTransaction(nonce, big.NewInt(0), fee, CrossChainRedeem, CrossChainRedeemData{
+		SourceChainId: sourceChainId,
+		BlockHash:     blockHash,
+		LockTx:        lockTx,
+		Proof:         proof,
+	})
+}
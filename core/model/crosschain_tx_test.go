@@ -0,0 +1,74 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+func TestNewCrossChainRelayTransaction(t *testing.T) {
+	header := NewHeader(1, 10, common.HexToHash("0x123"), common.HexToHash("0x456"), common.HexToDiff("0x1fffffff"), big.NewInt(100), aliceAddr, common.BlockNonce{})
+	tx, err := NewCrossChainRelayTransaction(1, big.NewInt(2), []*Header{header}, big.NewInt(10))
+	assert.NoError(t, err)
+	assert.Equal(t, common.HexToAddress(common.AddressCrossChain), *tx.To())
+	assert.Equal(t, CrossChainRelay, GetCrossChainTxType(tx.ExtraData()))
+
+	var data CrossChainRelayData
+	assert.NoError(t, DecodeCrossChainData(tx.ExtraData(), CrossChainRelay, &data))
+	assert.Equal(t, big.NewInt(2), data.ChainId)
+	assert.Equal(t, header.Hash(), data.Headers[0].Hash())
+
+	var lockData CrossChainLockData
+	assert.Equal(t, CrossChainDataErr, DecodeCrossChainData(tx.ExtraData(), CrossChainLock, &lockData))
+}
+
+func TestNewCrossChainLockTransaction(t *testing.T) {
+	tx, err := NewCrossChainLockTransaction(1, big.NewInt(2), bobAddr, big.NewInt(100), big.NewInt(10))
+	assert.NoError(t, err)
+	assert.Equal(t, CrossChainLock, GetCrossChainTxType(tx.ExtraData()))
+	assert.Equal(t, big.NewInt(100), tx.Amount())
+
+	var data CrossChainLockData
+	assert.NoError(t, DecodeCrossChainData(tx.ExtraData(), CrossChainLock, &data))
+	assert.Equal(t, bobAddr, data.Receiver)
+	assert.Equal(t, big.NewInt(2), data.TargetChainId)
+}
+
+func TestNewCrossChainRedeemTransaction(t *testing.T) {
+	proof := [][]byte{{1, 2}, {3}}
+	tx, err := NewCrossChainRedeemTransaction(1, big.NewInt(2), common.HexToHash("0x123"), []byte{1, 2, 3}, proof, big.NewInt(10))
+	assert.NoError(t, err)
+	assert.Equal(t, CrossChainRedeem, GetCrossChainTxType(tx.ExtraData()))
+	assert.Equal(t, big.NewInt(0), tx.Amount())
+
+	var data CrossChainRedeemData
+	assert.NoError(t, DecodeCrossChainData(tx.ExtraData(), CrossChainRedeem, &data))
+	assert.Equal(t, common.HexToHash("0x123"), data.BlockHash)
+	assert.Equal(t, []byte{1, 2, 3}, data.LockTx)
+	assert.Equal(t, proof, data.Proof)
+}
+
+func TestGetCrossChainTxType(t *testing.T) {
+	assert.Equal(t, CrossChainInvalid, GetCrossChainTxType(nil))
+	assert.Equal(t, CrossChainInvalid, GetCrossChainTxType([]byte{1, 2, 3}))
+
+	extraData, _ := encodeCrossChainData(CrossChainTxType(10), []byte{1})
+	assert.Equal(t, CrossChainInvalid, GetCrossChainTxType(extraData))
+}
@@ -0,0 +1,45 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/third-party/crypto/cs-crypto"
+	"math/big"
+)
+
+// Delegation the DIP bonded by the delegator to a verifier
+type Delegation struct {
+	Delegator common.Address
+	Amount    *big.Int
+	// block number the undelegate tx was processed, 0 if the delegation is bonded
+	UnbondNum uint64
+}
+
+func (d *Delegation) IsBonded() bool {
+	return d.UnbondNum == 0
+}
+
+// NewDelegateTransaction bond the amount to the verifier
+func NewDelegateTransaction(nonce uint64, verifier common.Address, amount, fee *big.Int) *Transaction {
+	return NewTransaction(nonce, cs_crypto.GetDelegateAddress(verifier), amount, fee, []byte{})
+}
+
+// NewUnDelegateTransaction the first tx starts unbonding the delegation, the second one returns it after the stake lock slots
+func NewUnDelegateTransaction(nonce uint64, verifier common.Address, fee *big.Int) *Transaction {
+	return NewTransaction(nonce, cs_crypto.GetUnDelegateAddress(verifier), big.NewInt(0), fee, []byte{})
+}
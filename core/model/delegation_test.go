@@ -0,0 +1,44 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+func TestNewDelegateTransaction(t *testing.T) {
+	tx := NewDelegateTransaction(1, bobAddr, big.NewInt(100), big.NewInt(2))
+	assert.Equal(t, common.AddressTypeDelegate, int(tx.GetType()))
+	assert.True(t, tx.To().IsEqualWithoutType(bobAddr))
+	assert.Equal(t, big.NewInt(100), tx.Amount())
+	assert.Equal(t, uint64(1), tx.Nonce())
+
+	tx = NewUnDelegateTransaction(2, bobAddr, big.NewInt(2))
+	assert.Equal(t, common.AddressTypeUnDelegate, int(tx.GetType()))
+	assert.True(t, tx.To().IsEqualWithoutType(bobAddr))
+	assert.Equal(t, big.NewInt(0), tx.Amount())
+}
+
+func TestDelegation_IsBonded(t *testing.T) {
+	d := Delegation{Delegator: aliceAddr, Amount: big.NewInt(1)}
+	assert.True(t, d.IsBonded())
+	d.UnbondNum = 10
+	assert.False(t, d.IsBonded())
+}
@@ -19,11 +19,16 @@ package model
 
 import (
 	"bytes"
+	"errors"
 	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/third-party/crypto"
 	"github.com/dipperin/dipperin-core/third-party/trie"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
+var ErrTransactionProof = errors.New("transaction not match the proof")
+
 //var EmptyRoot = common.HexToHash("0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
 
 type DerivableList interface {
@@ -44,6 +49,54 @@ func DeriveSha(list DerivableList) common.Hash {
 	return tree.Hash()
 }
 
+// DeriveProof returns the encoded trie nodes on the path from the DeriveSha root to the key
+func DeriveProof(list DerivableList, key []byte) ([][]byte, error) {
+	tree := new(trie.Trie)
+	for i := 0; i < list.Len(); i++ {
+		tree.Update(list.GetKey(i), list.GetRlp(i))
+	}
+
+	proofDb := ethdb.NewMemDatabase()
+	if err := tree.Prove(key, 0, proofDb); err != nil {
+		return nil, err
+	}
+
+	var proof [][]byte
+	for _, k := range proofDb.Keys() {
+		node, _ := proofDb.Get(k)
+		proof = append(proof, node)
+	}
+	return proof, nil
+}
+
+// VerifyDeriveProof checks the proof nodes against the root and returns the value of the key
+func VerifyDeriveProof(root common.Hash, key []byte, proof [][]byte) ([]byte, error) {
+	proofDb := ethdb.NewMemDatabase()
+	for _, node := range proof {
+		proofDb.Put(crypto.Keccak256(node), node)
+	}
+
+	value, _, err := trie.VerifyProof(root, key, proofDb)
+	return value, err
+}
+
+// VerifyTransactionProof checks the rlp encoded transaction is in the transaction root and decodes it
+func VerifyTransactionProof(txRoot common.Hash, txBytes []byte, proof [][]byte) (*Transaction, error) {
+	var tx Transaction
+	if err := rlp.DecodeBytes(txBytes, &tx); err != nil {
+		return nil, err
+	}
+
+	value, err := VerifyDeriveProof(txRoot, tx.CalTxId().Bytes(), proof)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(value, txBytes) {
+		return nil, ErrTransactionProof
+	}
+	return &tx, nil
+}
+
 // merkle root for AbstractVerification
 type Verifications []AbstractVerification
 
@@ -59,6 +59,49 @@ func TestDeriveShaByHash_DeriveSha(t *testing.T) {
 	assert.Equal(t, len(enc2), 0)
 }
 
+func TestDeriveProof(t *testing.T) {
+	txs := CreateSignedTxList(50)
+	root := DeriveSha(Transactions(txs))
+
+	key := txs[10].CalTxId().Bytes()
+	proof, err := DeriveProof(Transactions(txs), key)
+	assert.NoError(t, err)
+
+	value, err := VerifyDeriveProof(root, key, proof)
+	assert.NoError(t, err)
+	enc, _ := rlp.EncodeToBytes(txs[10])
+	assert.Equal(t, enc, value)
+
+	_, err = VerifyDeriveProof(root, key, proof[1:])
+	assert.Error(t, err)
+
+	_, err = VerifyDeriveProof(common.Hash{}, key, proof)
+	assert.Error(t, err)
+}
+
+func TestVerifyTransactionProof(t *testing.T) {
+	txs := CreateSignedTxList(20)
+	root := DeriveSha(Transactions(txs))
+	proof, err := DeriveProof(Transactions(txs), txs[3].CalTxId().Bytes())
+	assert.NoError(t, err)
+
+	enc, _ := rlp.EncodeToBytes(txs[3])
+	tx, err := VerifyTransactionProof(root, enc, proof)
+	assert.NoError(t, err)
+	assert.Equal(t, txs[3].CalTxId(), tx.CalTxId())
+
+	_, err = VerifyTransactionProof(root, []byte{0x12}, proof)
+	assert.Error(t, err)
+
+	_, err = VerifyTransactionProof(common.Hash{}, enc, proof)
+	assert.Error(t, err)
+
+	// the proof of another transaction
+	enc, _ = rlp.EncodeToBytes(txs[4])
+	_, err = VerifyTransactionProof(root, enc, proof)
+	assert.Error(t, err)
+}
+
 func TestVerifications_GetKey(t *testing.T) {
 	v := Verifications{}
 	result := v.GetKey(0)
@@ -53,7 +53,18 @@ func init(){
 // The variable needs to be changed to true in the test to skip validation
 var IgnoreDifficultyValidation = false
 
+// the local dev chain seals the blocks without pow, so the difficulty isn't validated
+var devMode = false
+
+// EnableDevMode skips the difficulty validation for the local dev chain
+func EnableDevMode() {
+	devMode = true
+}
+
 func IsIgnoreDifficultyValidation() bool {
+	if devMode {
+		return true
+	}
 	// Both unit tests and ignores can be skipped, otherwise they must be executed
 	if util.IsTestEnv() && IgnoreDifficultyValidation {
 		return true
@@ -90,3 +90,16 @@ func TestNewCalNewWorkDiff(t *testing.T) {
 	result = NewCalNewWorkDiff(block1, block2, 12)
 	assert.Equal(t, common.HexToDiff("0x1fffffff"), result)
 }
+
+func TestEnableDevMode(t *testing.T) {
+	ignore := IgnoreDifficultyValidation
+	defer func() {
+		devMode = false
+		IgnoreDifficultyValidation = ignore
+	}()
+
+	IgnoreDifficultyValidation = false
+	assert.False(t, IsIgnoreDifficultyValidation())
+	EnableDevMode()
+	assert.True(t, IsIgnoreDifficultyValidation())
+}
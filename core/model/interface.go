@@ -114,6 +114,9 @@ type AbstractTransaction interface {
 	ExtraData() []byte
 	Cost() *big.Int
 	EstimateFee() *big.Int
+	HashLock() *common.Hash
+	TimeLock() *big.Int
+	HashKey() []byte
 }
 
 //go:generate mockgen -destination=./../economy-model/verification_mock_test.go -package=economy_model github.com/caiqingfeng/dipperin-core/core/model AbstractVerification
@@ -0,0 +1,176 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"errors"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/third-party/crypto/cs-crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"math/big"
+)
+
+var (
+	MultiSigDataErr   = errors.New("invalid multi signature transaction data")
+	MultiSigKeySetErr = errors.New("invalid multi signature key set")
+)
+
+// the max number of the owners in a multi signature key set
+const MaxMultiSigOwners = 16
+
+type MultiSigTxType uint8
+
+const (
+	MultiSigInvalid MultiSigTxType = iota
+	MultiSigRegister
+	MultiSigExecute
+)
+
+/*
+Multi signature transactions are sent to the multi signature address, the extra data tells the type
+* Register: register the key set of the address, the address is derived from the key set
+* Execute: transfer from the multi signature account with the signatures of at least threshold owners
+A transaction without extra data deposits the amount to the registered account
+*/
+type multiSigData struct {
+	Type MultiSigTxType
+	Data []byte
+}
+
+// MultiSigKeySet is the owners of a multi signature account, threshold of them must sign to spend
+type MultiSigKeySet struct {
+	Threshold uint64
+	Owners    []common.Address
+}
+
+func (keySet *MultiSigKeySet) Valid() error {
+	if keySet.Threshold == 0 || keySet.Threshold > uint64(len(keySet.Owners)) || len(keySet.Owners) > MaxMultiSigOwners {
+		return MultiSigKeySetErr
+	}
+	for i, owner := range keySet.Owners {
+		if owner.IsEmpty() || owner.GetAddressType() != common.AddressTypeNormal {
+			return MultiSigKeySetErr
+		}
+		for _, other := range keySet.Owners[:i] {
+			if owner.IsEqual(other) {
+				return MultiSigKeySetErr
+			}
+		}
+	}
+	return nil
+}
+
+func (keySet *MultiSigKeySet) Address() common.Address {
+	return cs_crypto.GetMultiSigAddress(keySet.Threshold, keySet.Owners)
+}
+
+func (keySet *MultiSigKeySet) IsOwner(address common.Address) bool {
+	for _, owner := range keySet.Owners {
+		if owner.IsEqual(address) {
+			return true
+		}
+	}
+	return false
+}
+
+// MultiSigProposal is the transfer from the multi signature account signed by the owners
+type MultiSigProposal struct {
+	MultiSig common.Address
+	Nonce    uint64
+	To       common.Address
+	Amount   *big.Int
+}
+
+// Hash is signed by the owners, the chain id prevents replaying the proposal on other chains
+func (proposal *MultiSigProposal) Hash(chainId *big.Int) common.Hash {
+	enc, _ := rlp.EncodeToBytes([]interface{}{chainId, proposal})
+	return cs_crypto.Keccak256Hash(enc)
+}
+
+// Signers recover the owners from the signatures, invalid signatures are skipped
+func (proposal *MultiSigProposal) Signers(chainId *big.Int, signatures [][]byte) []common.Address {
+	hash := proposal.Hash(chainId)
+	var signers []common.Address
+	for _, sig := range signatures {
+		signer, err := cs_crypto.RecoverAddressFromSig(hash, sig)
+		if err != nil {
+			continue
+		}
+		signers = append(signers, signer)
+	}
+	return signers
+}
+
+type MultiSigExecuteData struct {
+	Proposal   MultiSigProposal
+	Signatures [][]byte
+}
+
+func encodeMultiSigData(txType MultiSigTxType, data interface{}) ([]byte, error) {
+	enc, err := rlp.EncodeToBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	return rlp.EncodeToBytes(multiSigData{Type: txType, Data: enc})
+}
+
+// GetMultiSigTxType decode the type of the multi signature transaction extra data
+func GetMultiSigTxType(extraData []byte) MultiSigTxType {
+	var data multiSigData
+	if err := rlp.DecodeBytes(extraData, &data); err != nil {
+		return MultiSigInvalid
+	}
+	switch data.Type {
+	case MultiSigRegister, MultiSigExecute:
+		return data.Type
+	default:
+		return MultiSigInvalid
+	}
+}
+
+// DecodeMultiSigData decode the extra data into the result if the type matches
+func DecodeMultiSigData(extraData []byte, txType MultiSigTxType, result interface{}) error {
+	var data multiSigData
+	if err := rlp.DecodeBytes(extraData, &data); err != nil {
+		return err
+	}
+	if data.Type != txType {
+		return MultiSigDataErr
+	}
+	return rlp.DecodeBytes(data.Data, result)
+}
+
+// NewMultiSigRegisterTransaction register the key set and deposit the amount to its address
+func NewMultiSigRegisterTransaction(nonce uint64, keySet *MultiSigKeySet, amount, fee *big.Int) (*Transaction, error) {
+	if err := keySet.Valid(); err != nil {
+		return nil, err
+	}
+	extraData, err := encodeMultiSigData(MultiSigRegister, keySet)
+	if err != nil {
+		return nil, err
+	}
+	return NewTransaction(nonce, keySet.Address(), amount, fee, extraData), nil
+}
+
+// NewMultiSigExecuteTransaction submit the signed proposal, the sender pays the fee
+func NewMultiSigExecuteTransaction(nonce uint64, data *MultiSigExecuteData, fee *big.Int) (*Transaction, error) {
+	extraData, err := encodeMultiSigData(MultiSigExecute, data)
+	if err != nil {
+		return nil, err
+	}
+	return NewTransaction(nonce, data.Proposal.MultiSig, big.NewInt(0), fee, extraData), nil
+}
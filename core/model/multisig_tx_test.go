@@ -0,0 +1,108 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/third-party/crypto"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+func TestMultiSigKeySet_Valid(t *testing.T) {
+	keySet := &MultiSigKeySet{Threshold: 2, Owners: []common.Address{aliceAddr, bobAddr}}
+	assert.NoError(t, keySet.Valid())
+	assert.Equal(t, common.AddressTypeMultiSig, int(keySet.Address().GetAddressType()))
+	assert.True(t, keySet.IsOwner(bobAddr))
+	assert.False(t, keySet.IsOwner(common.HexToAddress("0x1234")))
+
+	// the threshold is part of the address
+	assert.NotEqual(t, keySet.Address(), (&MultiSigKeySet{Threshold: 1, Owners: keySet.Owners}).Address())
+
+	owners := make([]common.Address, MaxMultiSigOwners+1)
+	for i := range owners {
+		owners[i] = common.BigToAddress(big.NewInt(int64(i + 1)))
+	}
+	for _, invalid := range []*MultiSigKeySet{
+		{Threshold: 0, Owners: []common.Address{aliceAddr}},
+		{Threshold: 3, Owners: []common.Address{aliceAddr, bobAddr}},
+		{Threshold: 1, Owners: []common.Address{aliceAddr, aliceAddr}},
+		{Threshold: 1, Owners: []common.Address{{}}},
+		{Threshold: 1, Owners: []common.Address{common.HexToAddress(common.AddressStake)}},
+		{Threshold: 1, Owners: owners},
+	} {
+		assert.Equal(t, MultiSigKeySetErr, invalid.Valid())
+	}
+}
+
+func TestMultiSigProposal_Signers(t *testing.T) {
+	aliceKey, bobKey := CreateKey()
+	proposal := &MultiSigProposal{MultiSig: common.HexToAddress("0x0006123"), Nonce: 1, To: bobAddr, Amount: big.NewInt(100)}
+	hash := proposal.Hash(big.NewInt(1))
+	assert.NotEqual(t, hash, proposal.Hash(big.NewInt(2)))
+
+	aliceSig, err := crypto.Sign(hash.Bytes(), aliceKey)
+	assert.NoError(t, err)
+	bobSig, err := crypto.Sign(hash.Bytes(), bobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Address{aliceAddr, bobAddr}, proposal.Signers(big.NewInt(1), [][]byte{aliceSig, {1, 2}, bobSig}))
+
+	// signed for another chain
+	assert.NotContains(t, proposal.Signers(big.NewInt(2), [][]byte{aliceSig}), aliceAddr)
+}
+
+func TestNewMultiSigRegisterTransaction(t *testing.T) {
+	keySet := &MultiSigKeySet{Threshold: 1, Owners: []common.Address{aliceAddr, bobAddr}}
+	tx, err := NewMultiSigRegisterTransaction(1, keySet, big.NewInt(100), big.NewInt(10))
+	assert.NoError(t, err)
+	assert.Equal(t, keySet.Address(), *tx.To())
+	assert.Equal(t, MultiSigRegister, GetMultiSigTxType(tx.ExtraData()))
+
+	var data MultiSigKeySet
+	assert.NoError(t, DecodeMultiSigData(tx.ExtraData(), MultiSigRegister, &data))
+	assert.Equal(t, *keySet, data)
+	var executeData MultiSigExecuteData
+	assert.Equal(t, MultiSigDataErr, DecodeMultiSigData(tx.ExtraData(), MultiSigExecute, &executeData))
+
+	_, err = NewMultiSigRegisterTransaction(1, &MultiSigKeySet{}, big.NewInt(100), big.NewInt(10))
+	assert.Equal(t, MultiSigKeySetErr, err)
+}
+
+func TestNewMultiSigExecuteTransaction(t *testing.T) {
+	data := &MultiSigExecuteData{
+		Proposal:   MultiSigProposal{MultiSig: common.HexToAddress("0x0006123"), Nonce: 1, To: bobAddr, Amount: big.NewInt(100)},
+		Signatures: [][]byte{{1, 2}, {3}},
+	}
+	tx, err := NewMultiSigExecuteTransaction(2, data, big.NewInt(10))
+	assert.NoError(t, err)
+	assert.Equal(t, data.Proposal.MultiSig, *tx.To())
+	assert.Equal(t, big.NewInt(0), tx.Amount())
+	assert.Equal(t, MultiSigExecute, GetMultiSigTxType(tx.ExtraData()))
+
+	var decoded MultiSigExecuteData
+	assert.NoError(t, DecodeMultiSigData(tx.ExtraData(), MultiSigExecute, &decoded))
+	assert.Equal(t, *data, decoded)
+}
+
+func TestGetMultiSigTxType(t *testing.T) {
+	assert.Equal(t, MultiSigInvalid, GetMultiSigTxType(nil))
+	assert.Equal(t, MultiSigInvalid, GetMultiSigTxType([]byte{1, 2, 3}))
+
+	extraData, _ := encodeMultiSigData(MultiSigTxType(10), []byte{1})
+	assert.Equal(t, MultiSigInvalid, GetMultiSigTxType(extraData))
+}
@@ -31,6 +31,20 @@ type Proofs struct {
 	Priority uint64
 }
 
+// SlashedVote is the height and round of a punished double sign, recorded to reject the same evidence twice
+type SlashedVote struct {
+	Height uint64
+	Round  uint64
+}
+
+// DoubleSign returns the height and round the two conflicting votes were signed at
+func (p *Proofs) DoubleSign() SlashedVote {
+	if p.VoteA == nil {
+		return SlashedVote{}
+	}
+	return SlashedVote{Height: p.VoteA.GetHeight(), Round: p.VoteA.GetRound()}
+}
+
 /*
 Name
 CalledBy
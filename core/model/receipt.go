@@ -0,0 +1,62 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"github.com/dipperin/dipperin-core/common"
+	"math/big"
+)
+
+const (
+	ReceiptStatusFailed uint64 = iota
+	ReceiptStatusSuccessful
+)
+
+// token transfer or approve made by a successful contract call
+type TokenEvent struct {
+	Contract common.Address
+	Action   string
+	// the token owner
+	From common.Address
+	// the receiver or spender
+	To    common.Address
+	Value *big.Int
+}
+
+// Receipt is the execution result of a mined transaction
+type Receipt struct {
+	TxHash common.Hash
+	Status uint64
+	// fee actually charged from the sender
+	Fee *big.Int
+	// the error of the failed contract call
+	Err string
+	// the contract created by the transaction
+	ContractAddress common.Address
+	TokenEvents     []*TokenEvent
+
+	// filled from the tx lookup entry, not stored with the receipts
+	BlockHash   common.Hash `rlp:"-"`
+	BlockNumber uint64      `rlp:"-"`
+	TxIndex     uint64      `rlp:"-"`
+}
+
+func (r *Receipt) Failed() bool {
+	return r.Status == ReceiptStatusFailed
+}
+
+type Receipts []*Receipt
@@ -0,0 +1,176 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"errors"
+	"github.com/dipperin/dipperin-core/common"
+	"math/big"
+)
+
+var (
+	ErrSuperBlockProofEmpty     = errors.New("super block proof is empty")
+	ErrSuperBlockProofGenesis   = errors.New("super block proof not start from genesis")
+	ErrSuperBlockInterLinkRoot  = errors.New("super block interlinks not match interlink root")
+	ErrSuperBlockNotInterLinked = errors.New("super block not in the interlinks of the next super block")
+	ErrHeaderPow                = errors.New("header hash not valid for difficulty")
+	ErrHeaderNotLink            = errors.New("header not link to the parent header")
+)
+
+// SuperBlock is a header together with its interlinks which can be checked by the interlink root
+type SuperBlock struct {
+	Header     *Header
+	InterLinks InterLink
+}
+
+func (s *SuperBlock) Hash() common.Hash {
+	return s.Header.Hash()
+}
+
+/*
+SuperBlockProof is the NiPoPoW style proof of a chain
+* Prefix: the interlinked super block chain from genesis, the last one is the parent of the suffix
+* Suffix: the last headers of the chain linked by pre hash
+*/
+type SuperBlockProof struct {
+	Prefix []*SuperBlock
+	Suffix []*Header
+}
+
+// the special block is generated by verifiers without pow
+func (h *Header) IsSpecial() bool {
+	return h.Diff.Equal(common.Difficulty{0}) && h.Nonce.IsEqual(common.BlockNonce{0})
+}
+
+// ValidPow check the header hash with its difficulty, which can't be easier than the main pow limit.
+// DiffToTarget panics if the exponent is out of range
+func (h *Header) ValidPow() bool {
+	if h.Diff[0] < 3 || h.Diff[0] > common.HashLength {
+		return false
+	}
+	if h.Diff.DiffToTarget().Big().Cmp(mainPowLimit) > 0 {
+		return false
+	}
+	return h.Hash().ValidHashForDifficulty(h.Diff)
+}
+
+// Level is the super block level of the header against the main pow limit rather than its own difficulty,
+// so a header can't get a higher level by declaring a lower difficulty. 0 if the pow isn't valid
+func (h *Header) Level() int {
+	if h.IsSpecial() || !h.ValidPow() {
+		return 0
+	}
+	return HashLevel(h.Hash(), common.BigToHash(mainPowLimit))
+}
+
+// VerifyHeaderChain check the headers one by one link to the parent with valid pow. The special headers have no pow,
+// they are only checked by the pre hash since the light client has no verifiers to check their votes
+func VerifyHeaderChain(parent *Header, headers []*Header) error {
+	for _, header := range headers {
+		if header == nil || header.Number != parent.Number+1 || !header.PreHash.IsEqual(parent.Hash()) {
+			return ErrHeaderNotLink
+		}
+		if !header.IsSpecial() && !header.ValidPow() {
+			return ErrHeaderPow
+		}
+		parent = header
+	}
+	return nil
+}
+
+func (p *SuperBlockProof) Tip() *Header {
+	if len(p.Suffix) > 0 {
+		return p.Suffix[len(p.Suffix)-1]
+	}
+	if len(p.Prefix) > 0 {
+		return p.Prefix[len(p.Prefix)-1].Header
+	}
+	return nil
+}
+
+// Verify check the proof starts from genesis, the super blocks are interlinked and the suffix links to the prefix.
+// The special headers in the prefix have no pow to check, they are level 0 in the score
+func (p *SuperBlockProof) Verify(genesis common.Hash) error {
+	if len(p.Prefix) == 0 || p.Prefix[0] == nil || p.Prefix[0].Header == nil {
+		return ErrSuperBlockProofEmpty
+	}
+	if !p.Prefix[0].Hash().IsEqual(genesis) {
+		return ErrSuperBlockProofGenesis
+	}
+
+	for i := 1; i < len(p.Prefix); i++ {
+		superBlock := p.Prefix[i]
+		if superBlock == nil || superBlock.Header == nil {
+			return ErrSuperBlockProofEmpty
+		}
+		if !DeriveSha(superBlock.InterLinks).IsEqual(superBlock.Header.InterlinkRoot) {
+			return ErrSuperBlockInterLinkRoot
+		}
+
+		if !superBlock.Header.IsSpecial() && !superBlock.Header.ValidPow() {
+			return ErrHeaderPow
+		}
+
+		pre := p.Prefix[i-1]
+		if superBlock.Header.Number <= pre.Header.Number || !containsHash(superBlock.InterLinks, pre.Hash()) {
+			return ErrSuperBlockNotInterLinked
+		}
+	}
+
+	return VerifyHeaderChain(p.Prefix[len(p.Prefix)-1].Header, p.Suffix)
+}
+
+/*
+Score is the best argument of the prefix, max(2^level * count of super blocks not lower than the level).
+The levels are counted against the same target for all the proofs, so a chain with more work has a higher score,
+the light client use it to choose the proof
+*/
+func (p *SuperBlockProof) Score() *big.Int {
+	if len(p.Prefix) == 0 {
+		return big.NewInt(0)
+	}
+
+	counts := map[int]int64{}
+	maxLevel := 0
+	for _, superBlock := range p.Prefix[1:] {
+		level := superBlock.Header.Level()
+		counts[level]++
+		if level > maxLevel {
+			maxLevel = level
+		}
+	}
+
+	best := big.NewInt(0)
+	count := int64(0)
+	for level := maxLevel; level >= 0; level-- {
+		count += counts[level]
+		score := new(big.Int).Lsh(big.NewInt(count), uint(level))
+		if score.Cmp(best) > 0 {
+			best = score
+		}
+	}
+	return best.Add(best, big.NewInt(int64(len(p.Suffix))))
+}
+
+func containsHash(links InterLink, hash common.Hash) bool {
+	for _, link := range links {
+		if link.IsEqual(hash) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+var testSuperBlockDiff = common.HexToDiff("0x201fffff")
+
+// create a mined chain with interlinks, blocks[0] is the genesis, the blocks of the special numbers have no pow
+func createTestLinkedChain(n int, specials ...uint64) []*Block {
+	genesis := NewBlock(NewHeader(1, 0, common.Hash{}, common.Hash{}, testSuperBlockDiff, big.NewInt(0), aliceAddr, common.BlockNonce{}), nil, nil)
+	blocks := []*Block{genesis}
+	for i := 1; i <= n; i++ {
+		pre := blocks[i-1]
+		diff := testSuperBlockDiff
+		for _, number := range specials {
+			if number == uint64(i) {
+				diff = common.Difficulty{}
+			}
+		}
+		header := NewHeader(1, uint64(i), pre.Hash(), common.Hash{}, diff, big.NewInt(int64(i)), aliceAddr, common.BlockNonce{})
+		block := NewBlockWithLink(header, nil, nil, pre.GetInterlinks())
+		for j := uint64(0); !block.header.IsSpecial() && !block.header.ValidPow(); j++ {
+			block.SetNonce(common.EncodeNonce(j))
+		}
+		block.RefreshHashCache()
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// follow the highest level interlink from the block to genesis
+func createTestSuperBlockProof(blocks []*Block, k int) *SuperBlockProof {
+	byHash := map[common.Hash]*Block{}
+	for _, b := range blocks {
+		byHash[b.Hash()] = b
+	}
+
+	cur := blocks[len(blocks)-1-k]
+	var prefix []*SuperBlock
+	for {
+		prefix = append([]*SuperBlock{{Header: cur.header, InterLinks: cur.GetInterlinks()}}, prefix...)
+		if cur.Number() == 0 {
+			break
+		}
+		links := cur.GetInterlinks()
+		cur = byHash[links[len(links)-1]]
+	}
+
+	proof := &SuperBlockProof{Prefix: prefix}
+	for _, b := range blocks[len(blocks)-k:] {
+		proof.Suffix = append(proof.Suffix, b.header)
+	}
+	return proof
+}
+
+func TestHeader_ValidPow(t *testing.T) {
+	blocks := createTestLinkedChain(1)
+	header := blocks[1].header
+	assert.True(t, header.ValidPow())
+	assert.False(t, header.IsSpecial())
+	assert.True(t, header.Level() > 0)
+
+	// the difficulty can't be easier than the main pow limit
+	header = CopyHeader(header)
+	header.Diff = common.HexToDiff("0x20ffffff")
+	assert.False(t, header.ValidPow())
+	assert.Equal(t, 0, header.Level())
+
+	header.Diff = common.Difficulty{}
+	assert.False(t, header.ValidPow())
+	header.Nonce = common.BlockNonce{}
+	assert.True(t, header.IsSpecial())
+	assert.Equal(t, 0, header.Level())
+}
+
+func TestVerifyHeaderChain(t *testing.T) {
+	blocks := createTestLinkedChain(3)
+	headers := []*Header{blocks[2].header, blocks[3].header}
+	assert.NoError(t, VerifyHeaderChain(blocks[1].header, headers))
+	assert.Equal(t, ErrHeaderNotLink, VerifyHeaderChain(blocks[0].header, headers))
+
+	fake := CopyHeader(blocks[2].header)
+	fake.Diff = common.HexToDiff("0x03000001")
+	assert.Equal(t, ErrHeaderPow, VerifyHeaderChain(blocks[1].header, []*Header{fake}))
+
+	// the special header is only checked by the pre hash
+	fake.Diff = common.Difficulty{}
+	fake.Nonce = common.BlockNonce{}
+	assert.NoError(t, VerifyHeaderChain(blocks[1].header, []*Header{fake}))
+	assert.Equal(t, ErrHeaderNotLink, VerifyHeaderChain(blocks[1].header, []*Header{fake, blocks[3].header}))
+}
+
+func TestSuperBlockProof_Verify(t *testing.T) {
+	blocks := createTestLinkedChain(60)
+	genesis := blocks[0].Hash()
+	proof := createTestSuperBlockProof(blocks, 5)
+
+	assert.NoError(t, proof.Verify(genesis))
+	assert.Equal(t, blocks[60].Hash(), proof.Tip().Hash())
+	assert.True(t, len(proof.Prefix) < 55)
+	assert.True(t, proof.Score().Cmp(big.NewInt(5)) > 0)
+
+	assert.Equal(t, ErrSuperBlockProofGenesis, proof.Verify(common.Hash{}))
+	assert.Equal(t, ErrSuperBlockProofEmpty, (&SuperBlockProof{}).Verify(genesis))
+	assert.Equal(t, big.NewInt(0), (&SuperBlockProof{}).Score())
+
+	// break the interlinks
+	last := proof.Prefix[len(proof.Prefix)-1]
+	links := last.InterLinks
+	last.InterLinks = InterLink{}
+	assert.Equal(t, ErrSuperBlockInterLinkRoot, proof.Verify(genesis))
+	last.InterLinks = links
+
+	// super blocks must be in order
+	disorder := &SuperBlockProof{Prefix: []*SuperBlock{
+		proof.Prefix[0],
+		{Header: blocks[2].header, InterLinks: blocks[2].GetInterlinks()},
+		{Header: blocks[1].header, InterLinks: blocks[1].GetInterlinks()},
+	}}
+	assert.Equal(t, ErrSuperBlockNotInterLinked, disorder.Verify(genesis))
+
+	// suffix not link
+	broken := &SuperBlockProof{Prefix: proof.Prefix, Suffix: proof.Suffix[1:]}
+	assert.Equal(t, ErrHeaderNotLink, broken.Verify(genesis))
+
+	// the special super block has no pow
+	special := CopyHeader(last.Header)
+	special.Diff = common.Difficulty{}
+	special.Nonce = common.BlockNonce{}
+	prefix := append(append([]*SuperBlock{}, proof.Prefix[:len(proof.Prefix)-1]...), &SuperBlock{Header: special, InterLinks: links})
+	assert.NoError(t, (&SuperBlockProof{Prefix: prefix}).Verify(genesis))
+}
+
+func TestSuperBlockProof_VerifySpecial(t *testing.T) {
+	// the special blocks are the parent of the suffix and in the suffix
+	blocks := createTestLinkedChain(60, 50, 55, 56)
+	genesis := blocks[0].Hash()
+	proof := createTestSuperBlockProof(blocks, 10)
+	assert.True(t, proof.Prefix[len(proof.Prefix)-1].Header.IsSpecial())
+	assert.True(t, proof.Suffix[5].IsSpecial())
+
+	assert.NoError(t, proof.Verify(genesis))
+	assert.Equal(t, blocks[60].Hash(), proof.Tip().Hash())
+
+	// the special header must still link to its parent
+	fake := CopyHeader(proof.Suffix[5])
+	fake.TimeStamp = big.NewInt(0)
+	proof.Suffix[5] = fake
+	assert.Equal(t, ErrHeaderNotLink, proof.Verify(genesis))
+}
@@ -55,6 +55,20 @@ func NewContractCreation(nonce uint64, amount *big.Int, fee *big.Int, data []byt
 	return newTransaction(nonce, nil, amount, fee, data)
 }
 
+// NewReplaceTransaction returns an unsigned copy of the tx with the fee, the hash lock, the time lock and the hash key
+// of the htlc txs are kept
+func NewReplaceTransaction(tx AbstractTransaction, fee *big.Int) *Transaction {
+	replaced := newTransaction(tx.Nonce(), tx.To(), tx.Amount(), fee, tx.ExtraData())
+	if lock := tx.HashLock(); lock != nil {
+		replaced.data.HashLock = common.CopyHash(lock)
+	}
+	replaced.data.TimeLock.Set(tx.TimeLock())
+	if len(tx.HashKey()) > 0 {
+		replaced.wit.HashKey = common.CopyBytes(tx.HashKey())
+	}
+	return replaced
+}
+
 func newTransaction(nonce uint64, to *common.Address, amount *big.Int, fee *big.Int, data []byte) *Transaction {
 	if len(data) > 0 {
 		data = common.CopyBytes(data)
@@ -312,8 +326,14 @@ func (tx *Transaction) CalTxId() common.Hash {
 		return common.Hash{}
 	}
 
-	//calculate TxId = hash(tx.data+address)
-	txId, err := rlpHash([]interface{}{tx.data, address})
+	//calculate TxId = hash(tx.data+address), the hash key of a htlc claim is appended
+	//so that the claim with another key isn't taken as the known tx
+	var txId common.Hash
+	if len(tx.wit.HashKey) != 0 {
+		txId, err = rlpHash([]interface{}{tx.data, address, tx.wit.HashKey})
+	} else {
+		txId, err = rlpHash([]interface{}{tx.data, address})
+	}
 	if err != nil {
 		return common.Hash{}
 	}
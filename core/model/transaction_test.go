@@ -37,6 +37,34 @@ func TestNewContractCreation(t *testing.T) {
 	assert.NotNil(t, result)
 }
 
+func TestNewReplaceTransaction(t *testing.T) {
+	alice := common.HexToAddress("0x00001234")
+	bob := common.HexToAddress("0x00005678")
+	lock := common.HexToHash("0x1234")
+
+	lockTx := CreateRawLockTx(1, lock, big.NewInt(20), big.NewInt(100), big.NewInt(10), alice, bob)
+	result := NewReplaceTransaction(lockTx, big.NewInt(30))
+	assert.Equal(t, big.NewInt(30), result.Fee())
+	assert.Equal(t, lockTx.Nonce(), result.Nonce())
+	assert.Equal(t, lockTx.To(), result.To())
+	assert.Equal(t, lockTx.Amount(), result.Amount())
+	assert.Equal(t, lockTx.ExtraData(), result.ExtraData())
+	assert.Equal(t, lock, *result.HashLock())
+	assert.Equal(t, big.NewInt(20), result.TimeLock())
+	assert.Equal(t, lockTx.GetType(), result.GetType())
+
+	claimTx := CreateRawClaimTx(1, []byte("key"), big.NewInt(100), big.NewInt(10), alice, bob)
+	result = NewReplaceTransaction(claimTx, big.NewInt(30))
+	assert.Nil(t, result.HashLock())
+	assert.Equal(t, []byte("key"), result.HashKey())
+	assert.Equal(t, claimTx.GetType(), result.GetType())
+
+	result = NewReplaceTransaction(NewContractCreation(1, big.NewInt(100), big.NewInt(10), []byte{123}), big.NewInt(30))
+	assert.Nil(t, result.To())
+	assert.Nil(t, result.HashLock())
+	assert.Nil(t, result.HashKey())
+}
+
 func TestTransaction_EncodeRLP(t *testing.T) {
 	tx := CreateSignedTx(0, big.NewInt(10000))
 	buffer := new(bytes.Buffer)
@@ -25,6 +25,7 @@ import (
 	"errors"
 	"github.com/dipperin/dipperin-core/third-party/crypto"
 	"github.com/dipperin/dipperin-core/third-party/crypto/cs-crypto"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 var (
@@ -57,6 +58,21 @@ func (tx *Transaction) SignTx(priKey *ecdsa.PrivateKey, s Signer) (*Transaction,
 	return tx, nil
 }
 
+// WithSignature fills the witness with the [R || S || V] signature of the sign hash, which is signed outside, e.g. by a hardware wallet
+func (tx *Transaction) WithSignature(s Signer, sig []byte) (*Transaction, error) {
+	if len(sig) != 65 {
+		return nil, ErrInvalidSig
+	}
+	wit := witness{HashKey: tx.wit.HashKey}
+	var err error
+	wit.R, wit.S, wit.V, err = s.SignatureValues(tx, sig)
+	if err != nil {
+		return nil, err
+	}
+	tx.wit = wit
+	return tx, nil
+}
+
 //todo set signer config
 func MakeSigner(config *chain_config.ChainConfig, blockNumber uint64) Signer {
 	var signer Signer
@@ -98,12 +114,23 @@ func (fs MercurySigner) Equal(s2 Signer) bool {
 	return ok && fs.chainId.Cmp(s.chainId) == 0
 }
 
-// GetSignHash will return the VRFHash of the transaction with have the raw transaction data and chainId
+// GetSignHash will return the VRFHash of the transaction with have the raw transaction data and chainId.
+// The hash key of a htlc claim is in the witness, it is signed too so that it can't be stripped or changed by a relayer
 func (fs MercurySigner) GetSignHash(rtx *Transaction) (common.Hash, error) {
-	//log.Debug("MercurySigner GetSignHash","tx",rtx.data)
-	//log.Debug("MercurySigner GetSignHash","chainId",fs.chainId)
-	res, err := rlpHash([]interface{}{rtx.data, fs.chainId})
-	return res, err
+	data, err := fs.GetSignData(rtx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return cs_crypto.Keccak256Hash(data), nil
+}
+
+// GetSignData is the rlp encoded data hashed by GetSignHash, a hardware wallet shows the tx decoded from it
+// and hashes it on the device
+func (fs MercurySigner) GetSignData(rtx *Transaction) ([]byte, error) {
+	if len(rtx.wit.HashKey) != 0 {
+		return rlp.EncodeToBytes([]interface{}{rtx.data, fs.chainId, rtx.wit.HashKey})
+	}
+	return rlp.EncodeToBytes([]interface{}{rtx.data, fs.chainId})
 }
 
 // SignatureValues returns signature values. This signature
@@ -22,6 +22,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/dipperin/dipperin-core/third-party/crypto"
 	"github.com/dipperin/dipperin-core/core/chain-config"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 func TestMercurySigner_Sender(t *testing.T) {
@@ -71,6 +72,25 @@ func TestMercurySigner_GetSignHash(t *testing.T) {
 	assert.NotEqual(t, getHash1, getHash3)
 }
 
+func TestMercurySigner_GetSignData(t *testing.T) {
+	tx, _ := createTestTx()
+	fs := NewMercurySigner(big.NewInt(1600))
+	claim := CreateRawClaimTx(1, []byte("key"), big.NewInt(100), big.NewInt(10), aliceAddr, bobAddr)
+
+	// a hardware wallet decodes the data and signs its hash, the hash key of the claim is in the data
+	for rtx, fields := range map[*Transaction]int{tx: 2, claim: 3} {
+		data, err := fs.GetSignData(rtx)
+		assert.NoError(t, err)
+		hash, err := fs.GetSignHash(rtx)
+		assert.NoError(t, err)
+		assert.Equal(t, hash.Bytes(), crypto.Keccak256(data))
+
+		var decoded []rlp.RawValue
+		assert.NoError(t, rlp.DecodeBytes(data, &decoded))
+		assert.Equal(t, fields, len(decoded))
+	}
+}
+
 func TestMercurySigner_SignatureValues(t *testing.T) {
 	tx1, _ := createTestTx()
 	key1, _ := CreateKey()
@@ -140,3 +160,53 @@ func TestMakeSigner(t *testing.T) {
 	signer := MakeSigner(config, 10)
 	assert.Equal(t, result, signer)
 }
+
+func TestTransaction_WithSignature(t *testing.T) {
+	tx1, _ := createTestTx()
+	key1, _ := CreateKey()
+	fs := NewMercurySigner(big.NewInt(1))
+	sigHash, err := fs.GetSignHash(tx1)
+	assert.NoError(t, err)
+	sig, err := crypto.Sign(sigHash[:], key1)
+	assert.NoError(t, err)
+
+	tx := NewTransaction(tx1.Nonce(), *tx1.To(), tx1.Amount(), tx1.Fee(), tx1.ExtraData())
+	_, err = tx.WithSignature(fs, sig[:64])
+	assert.Equal(t, ErrInvalidSig, err)
+
+	signedTx, err := tx.WithSignature(fs, sig)
+	assert.NoError(t, err)
+	sender, err := signedTx.Sender(fs)
+	assert.NoError(t, err)
+	assert.Equal(t, aliceAddr, sender)
+	assert.Equal(t, tx1.CalTxId(), signedTx.CalTxId())
+}
+
+func TestMercurySigner_HashKey(t *testing.T) {
+	key1, _ := CreateKey()
+	fs := NewMercurySigner(big.NewInt(1))
+	claim, err := CreateRawClaimTx(1, []byte("key"), big.NewInt(100), big.NewInt(10), aliceAddr, bobAddr).SignTx(key1, fs)
+	assert.NoError(t, err)
+
+	// the hash key is signed
+	sigHash, err := fs.GetSignHash(claim)
+	assert.NoError(t, err)
+	claim.wit.HashKey = nil
+	stripped, err := fs.GetSignHash(claim)
+	assert.NoError(t, err)
+	assert.NotEqual(t, sigHash, stripped)
+	claim.wit.HashKey = []byte("key")
+
+	// a relayer changing the key changes the id and the sender
+	enc, err := claim.EncodeRlpToBytes()
+	assert.NoError(t, err)
+	var tampered Transaction
+	assert.NoError(t, rlp.DecodeBytes(enc, &tampered))
+	tampered.wit.HashKey = []byte("other key")
+	assert.NotEqual(t, claim.CalTxId(), tampered.CalTxId())
+	sender, err := claim.Sender(fs)
+	assert.NoError(t, err)
+	assert.Equal(t, aliceAddr, sender)
+	tamperedSender, _ := tampered.Sender(fs)
+	assert.NotEqual(t, sender, tamperedSender)
+}
@@ -24,6 +24,7 @@ import (
     "github.com/dipperin/dipperin-core/core/chain-config"
     "github.com/dipperin/dipperin-core/core/contract"
     "github.com/dipperin/dipperin-core/core/economy-model"
+    "github.com/dipperin/dipperin-core/core/mine/minemaster"
     "github.com/dipperin/dipperin-core/core/model"
     "github.com/dipperin/dipperin-core/third-party/log"
     "github.com/dipperin/dipperin-core/common/util"
@@ -78,6 +79,19 @@ func (api *DipperinMercuryApi) CurrentBlock() (*BlockResp, error) {
     return blockResp, nil
 }
 
+// swagger:operation GET /url/CurrentLightHeader block information header
+// ---
+// summary: get the current header of the light node
+// description: get the head of the header chain synced by the node started with light sync
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        "$ref": "#/responses/Header"
+func (api *DipperinMercuryApi) CurrentLightHeader() (*model.Header, error) {
+    return api.service.CurrentLightHeader()
+}
+
 // swagger:operation POST /url/GetBlockByNumber block information block
 // ---
 // summary: get the block by height
@@ -439,6 +453,82 @@ func (api *DipperinMercuryApi) StopMine() error {
     return api.service.StopMine()
 }
 
+// get mine workers:
+// swagger:operation POST /url/GetMineWorkers mineOperation GetMineWorkers
+// ---
+// summary: get mine workers
+// description: get the statistics of the workers registered to the mine master
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return the coinbase, hash rate, submissions, last seen time and pending reward of the workers
+func (api *DipperinMercuryApi) GetMineWorkers() ([]*MineWorkerResp, error) {
+    workers, err := api.service.GetMineWorkers()
+    if err != nil {
+        return nil, err
+    }
+    resp := make([]*MineWorkerResp, 0, len(workers))
+    for _, w := range workers {
+        resp = append(resp, &MineWorkerResp{
+            WorkerId:      string(w.Id),
+            Coinbase:      w.Coinbase,
+            HashRate:      w.HashRate,
+            Accepted:      w.Accepted,
+            Stale:         w.Stale,
+            RegisteredAt:  w.RegisteredAt.Unix(),
+            LastSeen:      w.LastSeen.Unix(),
+            PendingReward: (*hexutil.Big)(w.PendingReward),
+        })
+    }
+    return resp, nil
+}
+
+// kick mine worker:
+// swagger:operation POST /url/KickMineWorker mineOperation KickMineWorker
+// ---
+// summary: kick mine worker
+// description: stop the worker and remove it from the mine master
+// parameters:
+// - name: workerId
+//   in: body
+//   description: the worker id
+//   type: string
+//   required: true
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return the operation result
+func (api *DipperinMercuryApi) KickMineWorker(workerId string) error {
+    return api.service.KickMineWorker(minemaster.WorkerId(workerId))
+}
+
+// set mine worker coinbase:
+// swagger:operation POST /url/SetMineWorkerCoinbase mineOperation SetMineWorkerCoinbase
+// ---
+// summary: set mine worker coinbase
+// description: change the coinbase which the rewards of the worker are accounted to
+// parameters:
+// - name: workerId
+//   in: body
+//   description: the worker id
+//   type: string
+//   required: true
+// - name: coinbase
+//   in: body
+//   description: the new coinbase address
+//   type: common.Address
+//   required: true
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return the operation result
+func (api *DipperinMercuryApi) SetMineWorkerCoinbase(workerId string, coinbase common.Address) error {
+    return api.service.SetMineWorkerCoinbase(minemaster.WorkerId(workerId), coinbase)
+}
+
 // establish wallet
 // swagger:operation POST /url/EstablishWallet WalletOperation Wallet
 // ---
@@ -793,6 +883,94 @@ func (api *DipperinMercuryApi) SendTransaction(from, to common.Address, value, t
     return api.service.SendTransaction(from, to, value, transactionFee, data, nonce)
 }
 
+// get the tx pool content of the address
+// swagger:operation POST /url/GetPoolContent transactionOperation transaction
+// ---
+// summary: get the tx pool content of the address
+// description: get the pending and queued transactions sent from the address, with the reason why a tx is queued
+// parameters:
+// - name: address
+//   in: body
+//   description: the sender address
+//   type: common.Address
+//   required: true
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return the pool content
+func (api *DipperinMercuryApi) GetPoolContent(address common.Address) (*PoolContentResp, error) {
+    pending, queued := api.service.GetPoolContent(address)
+    resp := &PoolContentResp{Pending: []*PoolTxResp{}, Queued: []*PoolTxResp{}}
+    for _, tx := range pending {
+        resp.Pending = append(resp.Pending, &PoolTxResp{Transaction: tx.(*model.Transaction)})
+    }
+    for _, q := range queued {
+        resp.Queued = append(resp.Queued, &PoolTxResp{Transaction: q.Tx.(*model.Transaction), Reason: q.Reason})
+    }
+    return resp, nil
+}
+
+// replace transaction
+// swagger:operation POST /url/ReplaceTransaction transactionOperation transaction
+// ---
+// summary: replace transaction
+// description: replace the transaction of the nonce in the tx pool with a higher fee
+// parameters:
+// - name: from
+//   in: body
+//   description: the sender address
+//   type: common.Address
+//   required: true
+// - name: nonce
+//   in: body
+//   description: the nonce of the replaced transaction
+//   type: uint64
+//   required: true
+// - name: fee
+//   in: body
+//   description: the new transaction fee, it must exceed the old one by the fee bump
+//   type: *big.Int
+//   required: true
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return the new transaction hash
+func (api *DipperinMercuryApi) ReplaceTransaction(from common.Address, nonce uint64, fee *big.Int) (common.Hash, error) {
+    return api.service.ReplaceTransaction(from, nonce, fee)
+}
+
+// cancel transaction
+// swagger:operation POST /url/CancelPoolTransaction transactionOperation transaction
+// ---
+// summary: cancel transaction
+// description: cancel the transaction of the nonce in the tx pool by replacing it with a zero value transaction to the sender
+// parameters:
+// - name: from
+//   in: body
+//   description: the sender address
+//   type: common.Address
+//   required: true
+// - name: nonce
+//   in: body
+//   description: the nonce of the canceled transaction
+//   type: uint64
+//   required: true
+// - name: fee
+//   in: body
+//   description: the new transaction fee, it must exceed the old one by the fee bump
+//   type: *big.Int
+//   required: true
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return the cancel transaction hash
+func (api *DipperinMercuryApi) CancelPoolTransaction(from common.Address, nonce uint64, fee *big.Int) (common.Hash, error) {
+    return api.service.CancelPoolTransaction(from, nonce, fee)
+}
+
 //send multiple-txs
 func (api *DipperinMercuryApi) SendTransactions(from common.Address, rpcTxs []model.RpcTransaction) (int, error) {
     return api.service.SendTransactions(from, rpcTxs)
@@ -929,120 +1107,890 @@ func (api *DipperinMercuryApi) SendCancelTransaction(from common.Address, fee *b
     return api.service.SendCancelTransaction(from, fee, nonce)
 }
 
-// get verifiers info by round
-// swagger:operation POST /url/GetVerifiersBySlot verifierInfo verifierInfo
+// send hash time lock transaction
+// swagger:operation POST /url/SendLockTransaction transactionOperation transaction
 // ---
-// summary: get verifiers info by round
-// description: get verifiers info by round
+// summary: send hash time lock transaction
+// description: lock value to the receiver, the receiver can claim it with the hash key before the time lock
 // parameters:
-// - name: slotNum
+// - name: from
 //   in: body
-//   description: the round (slotNum = blockNumber/slotSize)
-//   type: uint64
+//   description: the address that lock the value
+//   type: common.Address
+//   required: true
+// - name: to
+//   in: body
+//   description: the address that can claim the value
+//   type: common.Address
+//   required: true
+// - name: hashLock
+//   in: body
+//   description: the keccak256 hash of the hash key
+//   type: common.Hash
+//   required: true
+// - name: timeLock
+//   in: body
+//   description: the block height after which the value can be refunded
+//   type: *big.Int
+//   required: true
+// - name: value
+//   in: body
+//   description: the locked value
+//   type: *big.Int
+//   required: true
+// - name: fee
+//   in: body
+//   description: the transaction fee
+//   type: *big.Int
 //   required: true
 // produces:
 // - application/json
 // responses:
 //   "200":
-//        description: return verifier address list and the operation result
-func (api *DipperinMercuryApi) GetVerifiersBySlot(slotNum uint64) ([]common.Address, error) {
-    return api.service.GetVerifiers(slotNum), nil
+//        description: return operation result
+func (api *DipperinMercuryApi) SendLockTransaction(from, to common.Address, hashLock common.Hash, timeLock, value, fee *big.Int, nonce *uint64) (common.Hash, error) {
+    return api.service.SendLockTransaction(from, to, hashLock, timeLock, value, fee, nonce)
 }
 
-func (api *DipperinMercuryApi) GetSlot(block model.AbstractBlock) *uint64 {
-    return api.service.GetSlot(block)
+// send hash time lock claim transaction
+// swagger:operation POST /url/SendClaimTransaction transactionOperation transaction
+// ---
+// summary: send hash time lock claim transaction
+// description: claim the locked value with the hash key
+// parameters:
+// - name: from
+//   in: body
+//   description: the address that claim the value
+//   type: common.Address
+//   required: true
+// - name: lockSender
+//   in: body
+//   description: the address that lock the value
+//   type: common.Address
+//   required: true
+// - name: hashKey
+//   in: body
+//   description: the hash key of the hash lock
+//   type: hexutil.Bytes
+//   required: true
+// - name: fee
+//   in: body
+//   description: the transaction fee
+//   type: *big.Int
+//   required: true
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return operation result
+func (api *DipperinMercuryApi) SendClaimTransaction(from, lockSender common.Address, hashKey hexutil.Bytes, fee *big.Int, nonce *uint64) (common.Hash, error) {
+    return api.service.SendClaimTransaction(from, lockSender, hashKey, fee, nonce)
 }
 
-// get current verifiers
-// swagger:operation POST /url/GetCurVerifiers verifierInfo verifierInfo
+// send hash time lock refund transaction
+// swagger:operation POST /url/SendRefundTransaction transactionOperation transaction
 // ---
-// summary: get current verifiers
-// description: get current verifiers
+// summary: send hash time lock refund transaction
+// description: refund the locked value after the time lock
+// parameters:
+// - name: from
+//   in: body
+//   description: the address that lock the value
+//   type: common.Address
+//   required: true
+// - name: lockReceiver
+//   in: body
+//   description: the address that can claim the value
+//   type: common.Address
+//   required: true
+// - name: fee
+//   in: body
+//   description: the transaction fee
+//   type: *big.Int
+//   required: true
 // produces:
 // - application/json
 // responses:
 //   "200":
-//        description: return verifier address list and the operation result
-func (api *DipperinMercuryApi) GetCurVerifiers() ([]common.Address) {
-    return api.service.GetCurVerifiers()
+//        description: return operation result
+func (api *DipperinMercuryApi) SendRefundTransaction(from, lockReceiver common.Address, fee *big.Int, nonce *uint64) (common.Hash, error) {
+    return api.service.SendRefundTransaction(from, lockReceiver, fee, nonce)
 }
 
-// get next verifiers
-// swagger:operation POST /url/GetNextVerifiers verifierInfo verifierInfo
+// get hash time lock info
+// swagger:operation POST /url/GetLockInfo lockInfo lockInfo
 // ---
-// summary: get next verifiers
-// description: get next verifiers
+// summary: get hash time lock info
+// description: get the locked value, hash lock and time lock between the lock sender and receiver
 // produces:
 // - application/json
 // responses:
 //   "200":
-//        description: return verifier address list and the operation result
-func (api *DipperinMercuryApi) GetNextVerifiers() ([]common.Address) {
-    return api.service.GetNextVerifiers()
+//        description: return the lock info and the operation result
+func (api *DipperinMercuryApi) GetLockInfo(lockSender, lockReceiver common.Address) (resp *LockInfoResp, err error) {
+    lockAddr, value, hashLock, timeLock, err := api.service.GetLockInfo(lockSender, lockReceiver)
+    if err != nil {
+        return nil, err
+    }
+    return &LockInfoResp{
+        LockAddress: lockAddr,
+        Value:       (*hexutil.Big)(value),
+        HashLock:    hashLock,
+        TimeLock:    (*hexutil.Big)(timeLock),
+    }, nil
 }
 
-// verifier status
-// swagger:operation POST /url/VerifierStatus verifierInfo verifierInfo
+// get rlp encoded headers which can be relayed to other chains
+// swagger:operation POST /url/GetCrossChainHeaders crossChain crossChain
 // ---
-// summary: verifier status
-// description: verifier status
+// summary: get cross chain headers
+// description: get the rlp encoded headers between start and end
+// parameters:
+// - name: start
+//   in: body
+//   description: the start block number
+//   type: uint64
+//   required: true
+// - name: end
+//   in: body
+//   description: the end block number
+//   type: uint64
+//   required: true
 // produces:
 // - application/json
 // responses:
 //   "200":
-//        description: return verifier status and the operation result
-func (api *DipperinMercuryApi) VerifierStatus(address common.Address) (resp *VerifierStatus, err error) {
-    state, stake, balance, reputation, isCurrentVerifier, err := api.service.VerifierStatus(address)
-    return &VerifierStatus{
-        Status:            state,
-        Stake:             (*hexutil.Big)(stake),
-        Balance:           (*hexutil.Big)(balance),
-        Reputation:        reputation,
-        IsCurrentVerifier: isCurrentVerifier,
-    }, err
+//        description: return the rlp encoded headers and the operation result
+func (api *DipperinMercuryApi) GetCrossChainHeaders(start, end uint64) (hexutil.Bytes, error) {
+    headers, err := api.service.GetCrossChainHeaders(start, end)
+    if err != nil {
+        return nil, err
+    }
+    return rlp.EncodeToBytes(headers)
 }
 
-// get address stake
-// swagger:operation POST /url/CurrentStake stakeInfo stakeInfo
+// get the transaction root proof of a transaction
+// swagger:operation POST /url/GetCrossChainProof crossChain crossChain
 // ---
-// summary: get address stake
-// description: get address stake
+// summary: get cross chain proof
+// description: get the block, rlp encoded transaction and the proof against the transaction root
+// parameters:
+// - name: txHash
+//   in: body
+//   description: the transaction hash
+//   type: common.Hash
+//   required: true
 // produces:
 // - application/json
 // responses:
 //   "200":
-//        description: return address stake and the operation result
-func (api *DipperinMercuryApi) CurrentStake(address common.Address) (resp *CurStakeResp, err error) {
-    stake := api.service.CurrentStake(address)
-    return &CurStakeResp{
-        Stake: (*hexutil.Big)(stake),
-    }, nil
-}
+//        description: return the proof and the operation result
+func (api *DipperinMercuryApi) GetCrossChainProof(txHash common.Hash) (*CrossChainProofResp, error) {
+    blockHash, blockNumber, txBytes, proof, err := api.service.GetCrossChainProof(txHash)
+    if err != nil {
+        return nil, err
+    }
 
-func (api *DipperinMercuryApi) CurrentReputation(address common.Address) (uint64, error) {
-    return api.service.CurrentReputation(address)
+    resp := &CrossChainProofResp{
+        BlockHash:   blockHash,
+        BlockNumber: blockNumber,
+        Transaction: txBytes,
+    }
+    for _, node := range proof {
+        resp.Proof = append(resp.Proof, node)
+    }
+    return resp, nil
 }
 
-//get current practical verifiers
-func (api *DipperinMercuryApi) GetCurrentConnectPeers() ([]PeerInfoResp, error) {
-    peersInfo := make([]PeerInfoResp, 0)
-    tmpInfo := api.service.GetCurrentConnectPeers()
-
-    for nodeId, address := range tmpInfo {
-        verifier := PeerInfoResp{
-            NodeId:  nodeId,
-            Address: address,
-        }
-        peersInfo = append(peersInfo, verifier)
+// get the relayed header with the most work of the chain
+// swagger:operation POST /url/GetCrossChainTip crossChain crossChain
+// ---
+// summary: get cross chain tip
+// description: get the relayed header with the most work of the chain with chainId
+// parameters:
+// - name: chainId
+//   in: body
+//   description: the chain id of the other chain
+//   type: *big.Int
+//   required: true
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return the tip and the operation result
+func (api *DipperinMercuryApi) GetCrossChainTip(chainId *big.Int) (*CrossChainTipResp, error) {
+    hash, number, err := api.service.GetCrossChainTip(chainId)
+    if err != nil {
+        return nil, err
     }
-    return peersInfo, nil
+    return &CrossChainTipResp{Hash: hash, Number: number}, nil
 }
 
-/*//SyncUsedAccounts
-func (api *DipperinMercuryApi)SyncUsedAccounts(walletIdentifier accounts.WalletIdentifier,MaxChangeValue ,MaxIndex uint32) error{
-	return api.service.SyncUsedAccounts(walletIdentifier,MaxChangeValue,MaxIndex)
-}*/
-
+// send cross chain lock transaction
+// swagger:operation POST /url/SendCrossChainLockTransaction transactionOperation transaction
+// ---
+// summary: send cross chain lock transaction
+// description: lock value on this chain for the receiver on the target chain
+// parameters:
+// - name: from
+//   in: body
+//   description: the address that lock the value
+//   type: common.Address
+//   required: true
+// - name: receiver
+//   in: body
+//   description: the address that redeem the value on the target chain
+//   type: common.Address
+//   required: true
+// - name: targetChainId
+//   in: body
+//   description: the chain id of the target chain
+//   type: *big.Int
+//   required: true
+// - name: value
+//   in: body
+//   description: the locked value
+//   type: *big.Int
+//   required: true
+// - name: fee
+//   in: body
+//   description: the transaction fee
+//   type: *big.Int
+//   required: true
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return operation result
+func (api *DipperinMercuryApi) SendCrossChainLockTransaction(from, receiver common.Address, targetChainId, value, fee *big.Int, nonce *uint64) (common.Hash, error) {
+    return api.service.SendCrossChainLockTransaction(from, receiver, targetChainId, value, fee, nonce)
+}
+
+// send cross chain relay transaction
+// swagger:operation POST /url/SendCrossChainRelayTransaction transactionOperation transaction
+// ---
+// summary: send cross chain relay transaction
+// description: relay the rlp encoded headers of the chain with chainId
+// parameters:
+// - name: from
+//   in: body
+//   description: the address that relay the headers
+//   type: common.Address
+//   required: true
+// - name: chainId
+//   in: body
+//   description: the chain id of the headers
+//   type: *big.Int
+//   required: true
+// - name: headers
+//   in: body
+//   description: the rlp encoded headers got by GetCrossChainHeaders
+//   type: hexutil.Bytes
+//   required: true
+// - name: fee
+//   in: body
+//   description: the transaction fee
+//   type: *big.Int
+//   required: true
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return operation result
+func (api *DipperinMercuryApi) SendCrossChainRelayTransaction(from common.Address, chainId *big.Int, headers hexutil.Bytes, fee *big.Int, nonce *uint64) (common.Hash, error) {
+    var decodedHeaders []*model.Header
+    if err := rlp.DecodeBytes(headers, &decodedHeaders); err != nil {
+        return common.Hash{}, err
+    }
+    return api.service.SendCrossChainRelayTransaction(from, chainId, decodedHeaders, fee, nonce)
+}
+
+// send cross chain redeem transaction
+// swagger:operation POST /url/SendCrossChainRedeemTransaction transactionOperation transaction
+// ---
+// summary: send cross chain redeem transaction
+// description: redeem the lock transaction of the source chain with the proof got by GetCrossChainProof
+// parameters:
+// - name: from
+//   in: body
+//   description: the address that send the redeem transaction
+//   type: common.Address
+//   required: true
+// - name: sourceChainId
+//   in: body
+//   description: the chain id of the lock transaction
+//   type: *big.Int
+//   required: true
+// - name: blockHash
+//   in: body
+//   description: the block hash of the lock transaction
+//   type: common.Hash
+//   required: true
+// - name: lockTx
+//   in: body
+//   description: the rlp encoded lock transaction
+//   type: hexutil.Bytes
+//   required: true
+// - name: proof
+//   in: body
+//   description: the proof of the lock transaction
+//   type: []hexutil.Bytes
+//   required: true
+// - name: fee
+//   in: body
+//   description: the transaction fee
+//   type: *big.Int
+//   required: true
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return operation result
+func (api *DipperinMercuryApi) SendCrossChainRedeemTransaction(from common.Address, sourceChainId *big.Int, blockHash common.Hash, lockTx hexutil.Bytes, proof []hexutil.Bytes, fee *big.Int, nonce *uint64) (common.Hash, error) {
+    var proofNodes [][]byte
+    for _, node := range proof {
+        proofNodes = append(proofNodes, node)
+    }
+    return api.service.SendCrossChainRedeemTransaction(from, sourceChainId, blockHash, lockTx, proofNodes, fee, nonce)
+}
+
+// get the multi signature account
+// swagger:operation POST /url/GetMultiSigAccount multiSig multiSig
+// ---
+// summary: get multi signature account
+// description: get the registered key set, nonce and balance of the multi signature account
+// parameters:
+// - name: address
+//   in: body
+//   description: the multi signature address
+//   type: common.Address
+//   required: true
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return the account and the operation result
+func (api *DipperinMercuryApi) GetMultiSigAccount(address common.Address) (*MultiSigAccountResp, error) {
+    keySet, nonce, balance, err := api.service.GetMultiSigAccount(address)
+    if err != nil {
+        return nil, err
+    }
+    return &MultiSigAccountResp{Threshold: keySet.Threshold, Owners: keySet.Owners, Nonce: nonce, Balance: (*hexutil.Big)(balance)}, nil
+}
+
+// send multi signature register transaction
+// swagger:operation POST /url/SendRegisterMultiSigTransaction transactionOperation transaction
+// ---
+// summary: send multi signature register transaction
+// description: register the multi signature account of the owners and deposit the value to it, the address is derived from the threshold and the owners
+// parameters:
+// - name: from
+//   in: body
+//   description: the address that send the register transaction
+//   type: common.Address
+//   required: true
+// - name: threshold
+//   in: body
+//   description: the number of the owners must sign to spend
+//   type: uint64
+//   required: true
+// - name: owners
+//   in: body
+//   description: the owner addresses
+//   type: []common.Address
+//   required: true
+// - name: value
+//   in: body
+//   description: the value deposited to the multi signature account
+//   type: *big.Int
+//   required: true
+// - name: fee
+//   in: body
+//   description: the transaction fee
+//   type: *big.Int
+//   required: true
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return operation result
+func (api *DipperinMercuryApi) SendRegisterMultiSigTransaction(from common.Address, threshold uint64, owners []common.Address, value, fee *big.Int, nonce *uint64) (common.Hash, error) {
+    return api.service.SendRegisterMultiSigTransaction(from, threshold, owners, value, fee, nonce)
+}
+
+// create multi signature proposal
+// swagger:operation POST /url/NewMultiSigProposal multiSig multiSig
+// ---
+// summary: create multi signature proposal
+// description: create the proposal transferring value from the multi signature account with its current nonce, the owners sign the returned hash
+// parameters:
+// - name: multiSig
+//   in: body
+//   description: the multi signature address
+//   type: common.Address
+//   required: true
+// - name: to
+//   in: body
+//   description: the receiver address
+//   type: common.Address
+//   required: true
+// - name: value
+//   in: body
+//   description: the transfer value
+//   type: *big.Int
+//   required: true
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return the proposal and the operation result
+func (api *DipperinMercuryApi) NewMultiSigProposal(multiSig, to common.Address, value *big.Int) (*MultiSigProposalResp, error) {
+    proposal, hash, err := api.service.NewMultiSigProposal(multiSig, to, value)
+    if err != nil {
+        return nil, err
+    }
+    return &MultiSigProposalResp{Proposal: *proposal, Hash: hash}, nil
+}
+
+// sign multi signature proposal
+// swagger:operation POST /url/SignMultiSigProposal multiSig multiSig
+// ---
+// summary: sign multi signature proposal
+// description: sign the proposal with the owner account in the wallet of the node
+// parameters:
+// - name: signer
+//   in: body
+//   description: the owner address
+//   type: common.Address
+//   required: true
+// - name: proposal
+//   in: body
+//   description: the proposal got by NewMultiSigProposal
+//   type: model.MultiSigProposal
+//   required: true
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return the signature and the operation result
+func (api *DipperinMercuryApi) SignMultiSigProposal(signer common.Address, proposal model.MultiSigProposal) (hexutil.Bytes, error) {
+    return api.service.SignMultiSigProposal(signer, proposal)
+}
+
+// send multi signature transaction
+// swagger:operation POST /url/SendMultiSigTransaction transactionOperation transaction
+// ---
+// summary: send multi signature transaction
+// description: submit the proposal with the signatures of at least threshold owners, from pays the transaction fee
+// parameters:
+// - name: from
+//   in: body
+//   description: the address that send the transaction
+//   type: common.Address
+//   required: true
+// - name: proposal
+//   in: body
+//   description: the proposal got by NewMultiSigProposal
+//   type: model.MultiSigProposal
+//   required: true
+// - name: signatures
+//   in: body
+//   description: the owners signatures of the proposal
+//   type: []hexutil.Bytes
+//   required: true
+// - name: fee
+//   in: body
+//   description: the transaction fee
+//   type: *big.Int
+//   required: true
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return operation result
+func (api *DipperinMercuryApi) SendMultiSigTransaction(from common.Address, proposal model.MultiSigProposal, signatures []hexutil.Bytes, fee *big.Int, nonce *uint64) (common.Hash, error) {
+    var sigs [][]byte
+    for _, sig := range signatures {
+        sigs = append(sigs, sig)
+    }
+    return api.service.SendMultiSigTransaction(from, proposal, sigs, fee, nonce)
+}
+
+// get the delegations of the verifier
+// swagger:operation POST /url/GetDelegations delegation delegation
+// ---
+// summary: get delegations
+// description: get the stake of the verifier, the bonded DIP delegated to it and all its delegations including the unbonding ones
+// parameters:
+// - name: verifier
+//   in: body
+//   description: the verifier address
+//   type: common.Address
+//   required: true
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return the delegations and the operation result
+func (api *DipperinMercuryApi) GetDelegations(verifier common.Address) (*DelegationsResp, error) {
+    stake, delegated, delegations, err := api.service.GetDelegations(verifier)
+    if err != nil {
+        return nil, err
+    }
+    resp := &DelegationsResp{Stake: (*hexutil.Big)(stake), Delegated: (*hexutil.Big)(delegated), Delegations: []DelegationResp{}}
+    for _, d := range delegations {
+        resp.Delegations = append(resp.Delegations, DelegationResp{Delegator: d.Delegator, Amount: (*hexutil.Big)(d.Amount), UnbondNum: d.UnbondNum})
+    }
+    return resp, nil
+}
+
+// send delegate transaction
+// swagger:operation POST /url/SendDelegateTransaction transactionOperation transaction
+// ---
+// summary: send delegate transaction
+// description: bond the value to the registered verifier, the delegation counts toward the verifier election weight and shares its reward
+// parameters:
+// - name: from
+//   in: body
+//   description: the delegator address
+//   type: common.Address
+//   required: true
+// - name: verifier
+//   in: body
+//   description: the verifier address
+//   type: common.Address
+//   required: true
+// - name: value
+//   in: body
+//   description: the value bonded to the verifier
+//   type: *big.Int
+//   required: true
+// - name: fee
+//   in: body
+//   description: the transaction fee
+//   type: *big.Int
+//   required: true
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return operation result
+func (api *DipperinMercuryApi) SendDelegateTransaction(from, verifier common.Address, value, fee *big.Int, nonce *uint64) (common.Hash, error) {
+    return api.service.SendDelegateTransaction(from, verifier, value, fee, nonce)
+}
+
+// send undelegate transaction
+// swagger:operation POST /url/SendUnDelegateTransaction transactionOperation transaction
+// ---
+// summary: send undelegate transaction
+// description: the first transaction starts unbonding the delegation, the second one sent after the stake lock slots returns the DIP to the delegator
+// parameters:
+// - name: from
+//   in: body
+//   description: the delegator address
+//   type: common.Address
+//   required: true
+// - name: verifier
+//   in: body
+//   description: the verifier address
+//   type: common.Address
+//   required: true
+// - name: fee
+//   in: body
+//   description: the transaction fee
+//   type: *big.Int
+//   required: true
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return operation result
+func (api *DipperinMercuryApi) SendUnDelegateTransaction(from, verifier common.Address, fee *big.Int, nonce *uint64) (common.Hash, error) {
+    return api.service.SendUnDelegateTransaction(from, verifier, fee, nonce)
+}
+
+// get the merkle proof of an account
+// swagger:operation POST /url/GetAccountProof proof proof
+// ---
+// summary: get account proof
+// description: get the nonce, balance and stake of the account with the state trie nodes proving them against the state root of the block
+// parameters:
+// - name: address
+//   in: body
+//   description: the account address
+//   type: common.Address
+//   required: true
+// - name: blockNumber
+//   in: body
+//   description: the block number of the state
+//   type: uint64
+//   required: true
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return the account proof and the operation result
+func (api *DipperinMercuryApi) GetAccountProof(address common.Address, blockNumber uint64) (*AccountProofResp, error) {
+    stateRoot, proof, err := api.service.GetAccountProof(address, blockNumber)
+    if err != nil {
+        return nil, err
+    }
+
+    resp := &AccountProofResp{
+        BlockNumber: blockNumber,
+        StateRoot:   stateRoot,
+        Address:     proof.Address,
+        Nonce:       proof.Nonce,
+        Balance:     (*hexutil.Big)(proof.Balance),
+        Stake:       (*hexutil.Big)(proof.Stake),
+    }
+    for _, node := range proof.Proof {
+        resp.Proof = append(resp.Proof, node)
+    }
+    return resp, nil
+}
+
+// get the merkle proof of a transaction
+// swagger:operation POST /url/GetTransactionProof proof proof
+// ---
+// summary: get transaction proof
+// description: get the rlp encoded transaction with the trie nodes proving it against the transaction root of its block
+// parameters:
+// - name: txHash
+//   in: body
+//   description: the transaction hash
+//   type: common.Hash
+//   required: true
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return the transaction proof and the operation result
+func (api *DipperinMercuryApi) GetTransactionProof(txHash common.Hash) (*TransactionProofResp, error) {
+    blockHash, blockNumber, txRoot, txBytes, proof, err := api.service.GetTransactionProof(txHash)
+    if err != nil {
+        return nil, err
+    }
+
+    resp := &TransactionProofResp{
+        BlockHash:       blockHash,
+        BlockNumber:     blockNumber,
+        TransactionRoot: txRoot,
+        Transaction:     txBytes,
+    }
+    for _, node := range proof {
+        resp.Proof = append(resp.Proof, node)
+    }
+    return resp, nil
+}
+
+// get the execution result of a mined transaction
+// swagger:operation POST /url/GetTransactionReceipt receipt receipt
+// ---
+// summary: get transaction receipt
+// description: get the status, charged fee, contract error, created contract and token events of a mined transaction
+// parameters:
+// - name: txHash
+//   in: body
+//   description: the transaction hash
+//   type: common.Hash
+//   required: true
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return the transaction receipt and the operation result
+func (api *DipperinMercuryApi) GetTransactionReceipt(txHash common.Hash) (*ReceiptResp, error) {
+    receipt, err := api.service.GetTransactionReceipt(txHash)
+    if err != nil {
+        return nil, err
+    }
+
+    resp := &ReceiptResp{
+        TxHash:          receipt.TxHash,
+        BlockHash:       receipt.BlockHash,
+        BlockNumber:     receipt.BlockNumber,
+        TxIndex:         receipt.TxIndex,
+        Status:          receipt.Status,
+        Fee:             (*hexutil.Big)(receipt.Fee),
+        Err:             receipt.Err,
+        ContractAddress: receipt.ContractAddress,
+    }
+    for _, event := range receipt.TokenEvents {
+        resp.TokenEvents = append(resp.TokenEvents, &TokenEventResp{
+            Contract: event.Contract,
+            Action:   event.Action,
+            From:     event.From,
+            To:       event.To,
+            Value:    (*hexutil.Big)(event.Value),
+        })
+    }
+    return resp, nil
+}
+
+// get the transaction history of an address, the node must run with the address index
+// swagger:operation POST /url/GetTransactionsByAddress transaction transaction
+// ---
+// summary: get the transactions sent from or to an address
+// description: get the transactions of the address in the blocks between fromBlock and toBlock in the block order, the txs of the last block are never split so the next page starts from the block after it
+// parameters:
+// - name: address
+//   in: body
+//   description: the sender, receiver or ERC20 token receiver
+//   type: common.Address
+//   required: true
+// - name: fromBlock
+//   in: body
+//   description: the first block number
+//   type: uint64
+//   required: true
+// - name: toBlock
+//   in: body
+//   description: the last block number, 0 means the current block
+//   type: uint64
+//   required: true
+// - name: limit
+//   in: body
+//   description: the max number of the transactions, 0 means the max limit 1000
+//   type: uint64
+//   required: true
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return the transactions and the operation result
+func (api *DipperinMercuryApi) GetTransactionsByAddress(address common.Address, fromBlock, toBlock, limit uint64) ([]*TransactionResp, error) {
+    txs, err := api.service.GetTransactionsByAddress(address, fromBlock, toBlock, limit)
+    if err != nil {
+        return nil, err
+    }
+
+    resp := make([]*TransactionResp, 0, len(txs))
+    for _, tx := range txs {
+        resp = append(resp, &TransactionResp{
+            Transaction: tx.Transaction,
+            BlockHash:   tx.BlockHash,
+            BlockNumber: tx.BlockNumber,
+            TxIndex:     tx.TxIndex,
+        })
+    }
+    return resp, nil
+}
+
+// get verifiers info by round
+// swagger:operation POST /url/GetVerifiersBySlot verifierInfo verifierInfo
+// ---
+// summary: get verifiers info by round
+// description: get verifiers info by round
+// parameters:
+// - name: slotNum
+//   in: body
+//   description: the round (slotNum = blockNumber/slotSize)
+//   type: uint64
+//   required: true
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return verifier address list and the operation result
+func (api *DipperinMercuryApi) GetVerifiersBySlot(slotNum uint64) ([]common.Address, error) {
+    return api.service.GetVerifiers(slotNum), nil
+}
+
+func (api *DipperinMercuryApi) GetSlot(block model.AbstractBlock) *uint64 {
+    return api.service.GetSlot(block)
+}
+
+// get current verifiers
+// swagger:operation POST /url/GetCurVerifiers verifierInfo verifierInfo
+// ---
+// summary: get current verifiers
+// description: get current verifiers
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return verifier address list and the operation result
+func (api *DipperinMercuryApi) GetCurVerifiers() ([]common.Address) {
+    return api.service.GetCurVerifiers()
+}
+
+// get next verifiers
+// swagger:operation POST /url/GetNextVerifiers verifierInfo verifierInfo
+// ---
+// summary: get next verifiers
+// description: get next verifiers
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return verifier address list and the operation result
+func (api *DipperinMercuryApi) GetNextVerifiers() ([]common.Address) {
+    return api.service.GetNextVerifiers()
+}
+
+// verifier status
+// swagger:operation POST /url/VerifierStatus verifierInfo verifierInfo
+// ---
+// summary: verifier status
+// description: verifier status
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return verifier status and the operation result
+func (api *DipperinMercuryApi) VerifierStatus(address common.Address) (resp *VerifierStatus, err error) {
+    state, stake, balance, reputation, isCurrentVerifier, err := api.service.VerifierStatus(address)
+    return &VerifierStatus{
+        Status:            state,
+        Stake:             (*hexutil.Big)(stake),
+        Balance:           (*hexutil.Big)(balance),
+        Reputation:        reputation,
+        IsCurrentVerifier: isCurrentVerifier,
+    }, err
+}
+
+// get address stake
+// swagger:operation POST /url/CurrentStake stakeInfo stakeInfo
+// ---
+// summary: get address stake
+// description: get address stake
+// produces:
+// - application/json
+// responses:
+//   "200":
+//        description: return address stake and the operation result
+func (api *DipperinMercuryApi) CurrentStake(address common.Address) (resp *CurStakeResp, err error) {
+    stake := api.service.CurrentStake(address)
+    return &CurStakeResp{
+        Stake: (*hexutil.Big)(stake),
+    }, nil
+}
+
+func (api *DipperinMercuryApi) CurrentReputation(address common.Address) (uint64, error) {
+    return api.service.CurrentReputation(address)
+}
+
+//get current practical verifiers
+func (api *DipperinMercuryApi) GetCurrentConnectPeers() ([]PeerInfoResp, error) {
+    peersInfo := make([]PeerInfoResp, 0)
+    tmpInfo := api.service.GetCurrentConnectPeers()
+
+    for nodeId, address := range tmpInfo {
+        verifier := PeerInfoResp{
+            NodeId:  nodeId,
+            Address: address,
+        }
+        peersInfo = append(peersInfo, verifier)
+    }
+    return peersInfo, nil
+}
+
+/*//SyncUsedAccounts
+func (api *DipperinMercuryApi)SyncUsedAccounts(walletIdentifier accounts.WalletIdentifier,MaxChangeValue ,MaxIndex uint32) error{
+	return api.service.SyncUsedAccounts(walletIdentifier,MaxChangeValue,MaxIndex)
+}*/
+
 //get address nonce from wallet
 func (api *DipperinMercuryApi) GetAddressNonceFromWallet(address common.Address) (nonce uint64, err error) {
     return api.service.GetAddressNonceFromWallet(address)
@@ -1196,6 +2144,31 @@ func (api *DipperinMercuryApi) SubscribeBlock(ctx context.Context) (*rpc.Subscri
     return api.service.SubscribeBlock(ctx)
 }
 
+// notify the transactions entering the tx pool
+func (api *DipperinMercuryApi) SubscribePendingTransaction(ctx context.Context) (*rpc.Subscription, error) {
+    return api.service.SubscribePendingTransaction(ctx)
+}
+
+// notify the mined transactions sent from or to the address
+func (api *DipperinMercuryApi) SubscribeAddressTransaction(ctx context.Context, address common.Address) (*rpc.Subscription, error) {
+    return api.service.SubscribeAddressTransaction(ctx, address)
+}
+
+// notify the mined ERC20 transfer and approve transactions of the contract
+func (api *DipperinMercuryApi) SubscribeERC20(ctx context.Context, contractAddr common.Address) (*rpc.Subscription, error) {
+    return api.service.SubscribeERC20(ctx, contractAddr)
+}
+
+// notify the verifiers of the next round at the change point
+func (api *DipperinMercuryApi) SubscribeVerifiersChange(ctx context.Context) (*rpc.Subscription, error) {
+    return api.service.SubscribeVerifiersChange(ctx)
+}
+
+// notify the blocks removed by chain rollback
+func (api *DipperinMercuryApi) SubscribeRollback(ctx context.Context) (*rpc.Subscription, error) {
+    return api.service.SubscribeRollback(ctx)
+}
+
 func (api *DipperinMercuryApi) StopDipperin() {
     api.service.StopDipperin()
 }
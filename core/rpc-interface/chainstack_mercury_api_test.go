@@ -18,11 +18,14 @@ package rpc_interface
 
 import (
 	"fmt"
+	"github.com/dipperin/dipperin-core/common/hexutil"
 	"github.com/dipperin/dipperin-core/core/accounts"
 	"github.com/dipperin/dipperin-core/core/chain-config"
+	"github.com/dipperin/dipperin-core/core/chain/chaindb"
 	"github.com/dipperin/dipperin-core/core/chain/state-processor"
 	"github.com/dipperin/dipperin-core/core/contract"
 	"github.com/dipperin/dipperin-core/core/economy-model"
+	"github.com/dipperin/dipperin-core/core/tx-pool"
 	"github.com/dipperin/dipperin-core/third-party/p2p/enode"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -181,6 +184,89 @@ func TestDipperinMercuryApi(t *testing.T) {
 	assert.Error(t, err)
 	_, err = api.SendCancelTransaction(common.Address{}, big.NewInt(1), &nonce)
 	assert.Error(t, err)
+	_, err = api.SendLockTransaction(common.Address{}, common.Address{}, common.Hash{}, big.NewInt(1), big.NewInt(1), big.NewInt(1), &nonce)
+	assert.Error(t, err)
+	_, err = api.SendClaimTransaction(common.Address{}, common.Address{}, []byte{}, big.NewInt(1), &nonce)
+	assert.Error(t, err)
+	_, err = api.SendRefundTransaction(common.Address{}, common.Address{}, big.NewInt(1), &nonce)
+	assert.Error(t, err)
+	_, err = api.GetLockInfo(common.Address{}, common.Address{})
+	assert.Error(t, err)
+	_, err = api.GetCrossChainHeaders(1, 0)
+	assert.Error(t, err)
+	mc.EXPECT().GetTransaction(common.HexToHash("0x1")).Return(nil, common.Hash{}, uint64(0), uint64(0))
+	_, err = api.GetCrossChainProof(common.HexToHash("0x1"))
+	assert.Error(t, err)
+	_, err = api.GetCrossChainTip(big.NewInt(2))
+	assert.Error(t, err)
+	mc.EXPECT().GetTransaction(common.HexToHash("0x2")).Return(nil, common.Hash{}, uint64(0), uint64(0))
+	_, err = api.GetTransactionProof(common.HexToHash("0x2"))
+	assert.Error(t, err)
+	chainDB := chaindb.NewChainDB(ethdb.NewMemDatabase(), model.MakeDefaultBlockDecoder())
+	receiptBlock := model.CreateBlock(1, common.Hash{}, 1)
+	receiptTxHash := receiptBlock.GetTransactions()[0].CalTxId()
+	chainDB.SaveTxLookupEntries(receiptBlock)
+	chainDB.SaveReceipts(receiptBlock.Hash(), 1, model.Receipts{{TxHash: receiptTxHash, Status: model.ReceiptStatusSuccessful, Fee: big.NewInt(1), TokenEvents: []*model.TokenEvent{{Action: "Transfer", Value: big.NewInt(2)}}}})
+	mc.EXPECT().GetChainDB().Return(chainDB).Times(2)
+	_, err = api.GetTransactionReceipt(common.HexToHash("0x3"))
+	assert.Error(t, err)
+	receiptResp, err := api.GetTransactionReceipt(receiptTxHash)
+	assert.NoError(t, err)
+	assert.Equal(t, receiptBlock.Hash(), receiptResp.BlockHash)
+	assert.Equal(t, model.ReceiptStatusSuccessful, receiptResp.Status)
+	assert.Equal(t, big.NewInt(2), receiptResp.TokenEvents[0].Value.ToInt())
+	mc.EXPECT().GetChainDB().Return(chainDB).Times(2)
+	_, err = api.GetTransactionsByAddress(common.Address{}, 0, 1, 0)
+	assert.Error(t, err)
+	chainDB.EnableAddressIndex()
+	assert.NoError(t, chainDB.InsertBlock(receiptBlock))
+	receiptSender, _ := receiptBlock.GetTransactions()[0].Sender(nil)
+	addressTxs, err := api.GetTransactionsByAddress(receiptSender, 0, 1, 0)
+	assert.NoError(t, err)
+	assert.Len(t, addressTxs, 1)
+	assert.Equal(t, receiptTxHash, addressTxs[0].Transaction.CalTxId())
+	mc.EXPECT().StateAtByBlockNumber(uint64(1)).Return(adb, nil)
+	_, err = api.GetAccountProof(common.Address{}, 1)
+	assert.Error(t, err)
+	assert.Error(t, (&AccountProofResp{}).Verify(common.Hash{}))
+	_, err = (&TransactionProofResp{}).Verify(common.Hash{})
+	assert.Error(t, err)
+	_, err = api.SendCrossChainLockTransaction(common.Address{}, common.Address{}, big.NewInt(2), big.NewInt(1), big.NewInt(1), &nonce)
+	assert.Error(t, err)
+	_, err = api.SendCrossChainRelayTransaction(common.Address{}, big.NewInt(2), []byte{1}, big.NewInt(1), &nonce)
+	assert.Error(t, err)
+	_, err = api.SendCrossChainRelayTransaction(common.Address{}, big.NewInt(2), []byte{0xc0}, big.NewInt(1), &nonce)
+	assert.Error(t, err)
+	_, err = api.SendCrossChainRedeemTransaction(common.Address{}, big.NewInt(2), common.Hash{}, []byte{1}, []hexutil.Bytes{{1}}, big.NewInt(1), &nonce)
+	assert.Error(t, err)
+	_, err = api.GetMultiSigAccount(common.Address{})
+	assert.Error(t, err)
+	_, err = api.SendRegisterMultiSigTransaction(common.Address{}, 1, []common.Address{{}}, big.NewInt(1), big.NewInt(1), &nonce)
+	assert.Error(t, err)
+	_, err = api.NewMultiSigProposal(common.Address{}, common.Address{}, big.NewInt(1))
+	assert.Error(t, err)
+	_, err = api.SignMultiSigProposal(common.Address{}, model.MultiSigProposal{})
+	assert.Error(t, err)
+	_, err = api.SendMultiSigTransaction(common.Address{}, model.MultiSigProposal{}, []hexutil.Bytes{{1}}, big.NewInt(1), &nonce)
+	assert.Error(t, err)
+	_, err = api.GetDelegations(common.Address{})
+	assert.Error(t, err)
+	_, err = api.SendDelegateTransaction(common.Address{}, common.Address{}, big.NewInt(1), big.NewInt(1), &nonce)
+	assert.Error(t, err)
+	_, err = api.SendUnDelegateTransaction(common.Address{}, common.Address{}, big.NewInt(1), &nonce)
+	assert.Error(t, err)
+	poolContent, err := api.GetPoolContent(common.Address{})
+	assert.NoError(t, err)
+	assert.Len(t, poolContent.Pending, 0)
+	assert.Len(t, poolContent.Queued, 0)
+	_, err = api.ReplaceTransaction(common.Address{}, 0, big.NewInt(1))
+	assert.Error(t, err)
+	_, err = api.CancelPoolTransaction(common.Address{}, 0, big.NewInt(1))
+	assert.Error(t, err)
+	_, err = api.GetMineWorkers()
+	assert.Error(t, err)
+	assert.Error(t, api.KickMineWorker("worker"))
+	assert.Error(t, api.SetMineWorkerCoinbase("worker", common.Address{}))
 
 	mc.EXPECT().GetVerifiers(gomock.Any()).Return([]common.Address{{}}).AnyTimes()
 	mc.EXPECT().GetCurrVerifiers().Return([]common.Address{{}}).AnyTimes()
@@ -274,6 +360,10 @@ func (p *fakeTxPool) AddRemote(tx model.AbstractTransaction) error {
 	return nil
 }
 
+func (p *fakeTxPool) ContentFrom(addr common.Address) ([]model.AbstractTransaction, []tx_pool.QueuedTx) {
+	return nil, nil
+}
+
 type fakeBroadcaster struct {}
 
 func (f *fakeBroadcaster) BroadcastTx(txs []model.AbstractTransaction) {}
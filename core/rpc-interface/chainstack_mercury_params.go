@@ -21,6 +21,7 @@ import (
 	"github.com/dipperin/dipperin-core/common/hexutil"
 	"github.com/dipperin/dipperin-core/core/model"
 	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/core/chain/state-processor"
 )
 
 // swagger:response CurBalanceResp
@@ -62,6 +63,145 @@ type ERC20Resp struct {
 	CtId common.Address `json:"ctid"`
 }
 
+//hash time lock info resp
+type LockInfoResp struct {
+	LockAddress common.Address
+	Value       *hexutil.Big
+	HashLock    common.Hash
+	TimeLock    *hexutil.Big
+}
+
+//cross chain transaction proof resp
+type CrossChainProofResp struct {
+	BlockHash   common.Hash
+	BlockNumber uint64
+	Transaction hexutil.Bytes
+	Proof       []hexutil.Bytes
+}
+
+//account merkle proof resp
+type AccountProofResp struct {
+	BlockNumber uint64
+	StateRoot   common.Hash
+	Address     common.Address
+	Nonce       uint64
+	Balance     *hexutil.Big
+	Stake       *hexutil.Big
+	Proof       []hexutil.Bytes
+}
+
+// Verify check the account fields against the state root, the state root should come from a trusted header
+func (resp *AccountProofResp) Verify(stateRoot common.Hash) error {
+	proof := &state_processor.AccountProof{
+		Address: resp.Address,
+		Nonce:   resp.Nonce,
+		Balance: resp.Balance.ToInt(),
+		Stake:   resp.Stake.ToInt(),
+	}
+	for _, node := range resp.Proof {
+		proof.Proof = append(proof.Proof, node)
+	}
+	return state_processor.VerifyAccountProof(stateRoot, proof)
+}
+
+//transaction merkle proof resp
+type TransactionProofResp struct {
+	BlockHash       common.Hash
+	BlockNumber     uint64
+	TransactionRoot common.Hash
+	Transaction     hexutil.Bytes
+	Proof           []hexutil.Bytes
+}
+
+// Verify check the transaction against the transaction root and decode it, the root should come from a trusted header
+func (resp *TransactionProofResp) Verify(txRoot common.Hash) (*model.Transaction, error) {
+	var proof [][]byte
+	for _, node := range resp.Proof {
+		proof = append(proof, node)
+	}
+	return model.VerifyTransactionProof(txRoot, resp.Transaction, proof)
+}
+
+//token transfer or approve of the contract call
+type TokenEventResp struct {
+	Contract common.Address
+	Action   string
+	From     common.Address
+	To       common.Address
+	Value    *hexutil.Big
+}
+
+//transaction receipt resp, status 1 is successful and 0 is failed with the error of the contract call
+type ReceiptResp struct {
+	TxHash          common.Hash
+	BlockHash       common.Hash
+	BlockNumber     uint64
+	TxIndex         uint64
+	Status          uint64
+	Fee             *hexutil.Big
+	Err             string
+	ContractAddress common.Address
+	TokenEvents     []*TokenEventResp
+}
+
+//relayed header with the most work of the other chain resp
+type CrossChainTipResp struct {
+	Hash   common.Hash
+	Number uint64
+}
+
+//multi signature account resp
+type MultiSigAccountResp struct {
+	Threshold uint64
+	Owners    []common.Address
+	Nonce     uint64
+	Balance   *hexutil.Big
+}
+
+//delegation resp, UnbondNum is 0 if the delegation is bonded
+type DelegationResp struct {
+	Delegator common.Address
+	Amount    *hexutil.Big
+	UnbondNum uint64
+}
+
+//delegations of the verifier resp
+type DelegationsResp struct {
+	Stake       *hexutil.Big
+	Delegated   *hexutil.Big
+	Delegations []DelegationResp
+}
+
+//multi signature proposal resp, the owners sign the hash
+type MultiSigProposalResp struct {
+	Proposal model.MultiSigProposal
+	Hash     common.Hash
+}
+
+//tx pool transaction resp, Reason tells why a queued tx isn't pending
+type PoolTxResp struct {
+	Transaction *model.Transaction
+	Reason      string
+}
+
+//pending and queued transactions of an address in the tx pool
+type PoolContentResp struct {
+	Pending []*PoolTxResp
+	Queued  []*PoolTxResp
+}
+
+//mine worker statistics resp, the times are unix seconds
+type MineWorkerResp struct {
+	WorkerId      string
+	Coinbase      common.Address
+	HashRate      float64
+	Accepted      uint64
+	Stale         uint64
+	RegisteredAt  int64
+	LastSeen      int64
+	PendingReward *hexutil.Big
+}
+
 //current practical verifiers resp
 type PeerInfoResp struct {
 	NodeId string
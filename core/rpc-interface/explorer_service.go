@@ -0,0 +1,496 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rpc_interface
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/hexutil"
+	"github.com/dipperin/dipperin-core/common/util"
+	"github.com/dipperin/dipperin-core/core/chain-config"
+	"github.com/dipperin/dipperin-core/core/contract"
+	"github.com/dipperin/dipperin-core/core/dipperin/service"
+	"github.com/dipperin/dipperin-core/core/economy-model"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/third-party/log"
+	"math/big"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	explorerDefaultLimit = 20
+	explorerMaxLimit     = 100
+	// max blocks scanned by one filtered block list request
+	explorerMaxScanBlocks = 1000
+)
+
+var (
+	errExplorerNotFound   = errors.New("resource not found")
+	errExplorerBadAddress = errors.New("invalid address")
+	errExplorerBadHash    = errors.New("invalid hash")
+	errExplorerFutureSlot = errors.New("the verifiers of the slot are not elected yet")
+)
+
+// the chain data the explorer reads, implemented by the MercuryFullChainService
+type explorerBackend interface {
+	CurrentBlock() model.AbstractBlock
+	GetBlockByNumber(number uint64) (model.AbstractBlock, error)
+	GetBlockByHash(hash common.Hash) (model.AbstractBlock, error)
+	GetSlot(block model.AbstractBlock) *uint64
+	Transaction(hash common.Hash) (transaction *model.Transaction, blockHash common.Hash, blockNumber uint64, txIndex uint64, err error)
+	GetTransactionsByAddress(address common.Address, fromBlock, toBlock, limit uint64) ([]*service.AddressTransaction, error)
+	GetTransactionNonce(addr common.Address) (nonce uint64, err error)
+	VerifierStatus(addr common.Address) (verifierState string, stake *big.Int, balance *big.Int, reputation uint64, isCurrentVerifier bool, err error)
+	GetVerifiers(slotNum uint64) (addresses []common.Address)
+	GetContract(contractAddr common.Address) (interface{}, error)
+	GetContractInfo(eData *contract.ExtraDataForContract) (interface{}, error)
+	GetMineMasterDIPReward(blockNumber uint64) (*big.Int, error)
+	GetVerifierDIPReward(blockNumber uint64) (map[economy_model.VerifierType]*big.Int, error)
+}
+
+// ExplorerService serves the blocks, txs, accounts, verifiers, tokens and rewards
+// of the chain as read only REST resources for block explorers
+type ExplorerService struct {
+	endpoint string
+	backend  explorerBackend
+
+	listener net.Listener
+	server   *http.Server
+}
+
+func MakeExplorerService(endpoint string, backend explorerBackend) *ExplorerService {
+	return &ExplorerService{endpoint: endpoint, backend: backend}
+}
+
+func (s *ExplorerService) Start() error {
+	if s.endpoint == "" {
+		return nil
+	}
+	listener, err := net.Listen("tcp", s.endpoint)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	s.server = &http.Server{
+		Handler:      s.Handler(),
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		IdleTimeout:  50 * time.Second,
+	}
+	go s.server.Serve(listener)
+	log.Info("explorer endpoint opened", "url", fmt.Sprintf("http://%s", s.endpoint))
+	return nil
+}
+
+func (s *ExplorerService) Stop() {
+	if s.server != nil {
+		s.server.Close()
+		s.server = nil
+		s.listener = nil
+	}
+}
+
+// Handler routes the explorer resources:
+//
+//	GET /blocks?start=&limit=&coinbase=&min_txs=
+//	GET /blocks/{number|hash}
+//	GET /transactions/{hash}
+//	GET /accounts/{address}
+//	GET /accounts/{address}/transactions?from_block=&to_block=&limit=
+//	GET /verifiers?slot=
+//	GET /tokens/{contract}
+//	GET /tokens/{contract}/balances/{owner}
+//	GET /rewards/{number}
+func (s *ExplorerService) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/blocks", s.wrap(s.listBlocks))
+	mux.HandleFunc("/blocks/", s.wrap(s.getBlock))
+	mux.HandleFunc("/transactions/", s.wrap(s.getTransaction))
+	mux.HandleFunc("/accounts/", s.wrap(s.getAccount))
+	mux.HandleFunc("/verifiers", s.wrap(s.listVerifiers))
+	mux.HandleFunc("/tokens/", s.wrap(s.getToken))
+	mux.HandleFunc("/rewards/", s.wrap(s.getRewards))
+	return mux
+}
+
+type explorerHandler func(r *http.Request, path []string) (interface{}, error)
+
+// wrap checks the method, splits the path after the resource name and writes the result as json
+func (s *ExplorerService) wrap(h explorerHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodGet {
+			writeExplorerError(w, http.StatusMethodNotAllowed, errors.New("only GET is supported"))
+			return
+		}
+
+		var path []string
+		for _, p := range strings.Split(strings.Trim(r.URL.Path, "/"), "/")[1:] {
+			if p != "" {
+				path = append(path, p)
+			}
+		}
+
+		result, err := h(r, path)
+		switch err {
+		case nil:
+			json.NewEncoder(w).Encode(result)
+		case errExplorerNotFound:
+			writeExplorerError(w, http.StatusNotFound, err)
+		default:
+			writeExplorerError(w, http.StatusBadRequest, err)
+		}
+	}
+}
+
+func writeExplorerError(w http.ResponseWriter, status int, err error) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// parse the uint query parameter, return def if it is missing
+func queryUint(r *http.Request, name string, def uint64) (uint64, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %v: %v", name, v)
+	}
+	return n, nil
+}
+
+func queryLimit(r *http.Request) (uint64, error) {
+	limit, err := queryUint(r, "limit", explorerDefaultLimit)
+	if err != nil {
+		return 0, err
+	}
+	if limit == 0 || limit > explorerMaxLimit {
+		limit = explorerMaxLimit
+	}
+	return limit, nil
+}
+
+func parseExplorerAddress(v string) (common.Address, error) {
+	addr, err := hexutil.Decode(v)
+	if err != nil || len(addr) != common.AddressLength {
+		return common.Address{}, errExplorerBadAddress
+	}
+	return common.BytesToAddress(addr), nil
+}
+
+type ExplorerBlockSummary struct {
+	Number    uint64         `json:"number"`
+	Hash      common.Hash    `json:"hash"`
+	CoinBase  common.Address `json:"coinbase"`
+	TimeStamp *hexutil.Big   `json:"timestamp"`
+	TxCount   int            `json:"tx_count"`
+}
+
+type ExplorerBlocksResp struct {
+	Blocks []ExplorerBlockSummary `json:"blocks"`
+	// the start of the next page, nil if the genesis block is reached
+	Next *uint64 `json:"next"`
+}
+
+// list the blocks from start to the genesis block, filtered by the coinbase and the tx count
+func (s *ExplorerService) listBlocks(r *http.Request, path []string) (interface{}, error) {
+	current := s.backend.CurrentBlock().Number()
+	start, err := queryUint(r, "start", current)
+	if err != nil {
+		return nil, err
+	}
+	if start > current {
+		start = current
+	}
+	limit, err := queryLimit(r)
+	if err != nil {
+		return nil, err
+	}
+	minTxs, err := queryUint(r, "min_txs", 0)
+	if err != nil {
+		return nil, err
+	}
+	var coinbase *common.Address
+	if v := r.URL.Query().Get("coinbase"); v != "" {
+		addr, err := parseExplorerAddress(v)
+		if err != nil {
+			return nil, err
+		}
+		coinbase = &addr
+	}
+
+	resp := &ExplorerBlocksResp{Blocks: []ExplorerBlockSummary{}}
+	num := int64(start)
+	for scanned := 0; num >= 0 && uint64(len(resp.Blocks)) < limit && scanned < explorerMaxScanBlocks; scanned++ {
+		block, _ := s.backend.GetBlockByNumber(uint64(num))
+		num--
+		if util.InterfaceIsNil(block) {
+			continue
+		}
+		if coinbase != nil && !block.CoinBaseAddress().IsEqual(*coinbase) {
+			continue
+		}
+		if uint64(block.TxCount()) < minTxs {
+			continue
+		}
+		resp.Blocks = append(resp.Blocks, ExplorerBlockSummary{
+			Number:    block.Number(),
+			Hash:      block.Hash(),
+			CoinBase:  block.CoinBaseAddress(),
+			TimeStamp: (*hexutil.Big)(block.Timestamp()),
+			TxCount:   block.TxCount(),
+		})
+	}
+	if num >= 0 {
+		next := uint64(num)
+		resp.Next = &next
+	}
+	return resp, nil
+}
+
+// get the block by number or hash
+func (s *ExplorerService) getBlock(r *http.Request, path []string) (interface{}, error) {
+	if len(path) != 1 {
+		return nil, errExplorerNotFound
+	}
+
+	var block model.AbstractBlock
+	if strings.HasPrefix(path[0], "0x") {
+		hash, err := hexutil.Decode(path[0])
+		if err != nil || len(hash) != common.HashLength {
+			return nil, errExplorerBadHash
+		}
+		block, _ = s.backend.GetBlockByHash(common.BytesToHash(hash))
+	} else {
+		number, err := strconv.ParseUint(path[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid block number: %v", path[0])
+		}
+		block, _ = s.backend.GetBlockByNumber(number)
+	}
+	if util.InterfaceIsNil(block) {
+		return nil, errExplorerNotFound
+	}
+	return &BlockResp{Header: *block.Header().(*model.Header), Body: *block.Body().(*model.Body)}, nil
+}
+
+func (s *ExplorerService) getTransaction(r *http.Request, path []string) (interface{}, error) {
+	if len(path) != 1 {
+		return nil, errExplorerNotFound
+	}
+	hash, err := hexutil.Decode(path[0])
+	if err != nil || len(hash) != common.HashLength {
+		return nil, errExplorerBadHash
+	}
+
+	tx, blockHash, blockNumber, txIndex, err := s.backend.Transaction(common.BytesToHash(hash))
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, errExplorerNotFound
+	}
+	return &TransactionResp{Transaction: tx, BlockHash: blockHash, BlockNumber: blockNumber, TxIndex: txIndex}, nil
+}
+
+type ExplorerAccountResp struct {
+	Address           common.Address `json:"address"`
+	Balance           *hexutil.Big   `json:"balance"`
+	Nonce             uint64         `json:"nonce"`
+	Stake             *hexutil.Big   `json:"stake"`
+	Reputation        uint64         `json:"reputation"`
+	VerifierStatus    string         `json:"verifier_status"`
+	IsCurrentVerifier bool           `json:"is_current_verifier"`
+}
+
+type ExplorerAccountTxsResp struct {
+	Transactions []*TransactionResp `json:"transactions"`
+	// the from_block of the next page, nil if there are no more txs
+	NextFromBlock *uint64 `json:"next_from_block"`
+}
+
+func (s *ExplorerService) getAccount(r *http.Request, path []string) (interface{}, error) {
+	if len(path) == 0 || len(path) > 2 {
+		return nil, errExplorerNotFound
+	}
+	addr, err := parseExplorerAddress(path[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(path) == 2 {
+		if path[1] != "transactions" {
+			return nil, errExplorerNotFound
+		}
+		return s.getAccountTransactions(r, addr)
+	}
+
+	status, stake, balance, reputation, isCurrent, err := s.backend.VerifierStatus(addr)
+	if err != nil {
+		return nil, errExplorerNotFound
+	}
+	nonce, err := s.backend.GetTransactionNonce(addr)
+	if err != nil {
+		return nil, err
+	}
+	return &ExplorerAccountResp{
+		Address:           addr,
+		Balance:           (*hexutil.Big)(balance),
+		Nonce:             nonce,
+		Stake:             (*hexutil.Big)(stake),
+		Reputation:        reputation,
+		VerifierStatus:    status,
+		IsCurrentVerifier: isCurrent,
+	}, nil
+}
+
+// the txs of the address page by block range, needs the address index of the node
+func (s *ExplorerService) getAccountTransactions(r *http.Request, addr common.Address) (interface{}, error) {
+	fromBlock, err := queryUint(r, "from_block", 0)
+	if err != nil {
+		return nil, err
+	}
+	toBlock, err := queryUint(r, "to_block", 0)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := queryLimit(r)
+	if err != nil {
+		return nil, err
+	}
+
+	txs, err := s.backend.GetTransactionsByAddress(addr, fromBlock, toBlock, limit)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ExplorerAccountTxsResp{Transactions: make([]*TransactionResp, 0, len(txs))}
+	for _, tx := range txs {
+		resp.Transactions = append(resp.Transactions, &TransactionResp{
+			Transaction: tx.Transaction,
+			BlockHash:   tx.BlockHash,
+			BlockNumber: tx.BlockNumber,
+			TxIndex:     tx.TxIndex,
+		})
+	}
+	// the txs of the last block are all returned, so the next page starts from the block after it
+	if uint64(len(txs)) >= limit {
+		next := txs[len(txs)-1].BlockNumber + 1
+		resp.NextFromBlock = &next
+	}
+	return resp, nil
+}
+
+type ExplorerVerifiersResp struct {
+	Slot      uint64           `json:"slot"`
+	Verifiers []common.Address `json:"verifiers"`
+}
+
+// the verifiers of the slot, the current slot by default.
+// The verifiers are elected SlotMargin slots ahead, so the later slots are rejected
+func (s *ExplorerService) listVerifiers(r *http.Request, path []string) (interface{}, error) {
+	var curSlot uint64
+	if slot := s.backend.GetSlot(s.backend.CurrentBlock()); slot != nil {
+		curSlot = *slot
+	}
+	slot, err := queryUint(r, "slot", curSlot)
+	if err != nil {
+		return nil, err
+	}
+	if slot > curSlot+chain_config.GetChainConfig().SlotMargin {
+		return nil, errExplorerFutureSlot
+	}
+	return &ExplorerVerifiersResp{Slot: slot, Verifiers: s.backend.GetVerifiers(slot)}, nil
+}
+
+type ExplorerTokenBalanceResp struct {
+	Contract common.Address `json:"contract"`
+	Owner    common.Address `json:"owner"`
+	Balance  interface{}    `json:"balance"`
+}
+
+func (s *ExplorerService) getToken(r *http.Request, path []string) (interface{}, error) {
+	if len(path) != 1 && (len(path) != 3 || path[1] != "balances") {
+		return nil, errExplorerNotFound
+	}
+	contractAddr, err := parseExplorerAddress(path[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(path) == 1 {
+		token, err := s.backend.GetContract(contractAddr)
+		if err != nil {
+			return nil, errExplorerNotFound
+		}
+		return token, nil
+	}
+
+	owner, err := parseExplorerAddress(path[2])
+	if err != nil {
+		return nil, err
+	}
+	params := util.StringifyJson([]interface{}{fmt.Sprintf("%v", owner)})
+	balance, err := s.backend.GetContractInfo(&contract.ExtraDataForContract{ContractAddress: contractAddr, Action: "BalanceOf", Params: params})
+	if err != nil {
+		return nil, err
+	}
+	return &ExplorerTokenBalanceResp{Contract: contractAddr, Owner: owner, Balance: balance}, nil
+}
+
+type ExplorerRewardsResp struct {
+	Number            uint64       `json:"number"`
+	MineMaster        *hexutil.Big `json:"mine_master"`
+	MasterVerifier    *hexutil.Big `json:"master_verifier"`
+	CommitVerifier    *hexutil.Big `json:"commit_verifier"`
+	NotCommitVerifier *hexutil.Big `json:"not_commit_verifier"`
+}
+
+// the DIP rewards of the miner and each kind of verifiers of the block
+func (s *ExplorerService) getRewards(r *http.Request, path []string) (interface{}, error) {
+	if len(path) != 1 {
+		return nil, errExplorerNotFound
+	}
+	number, err := strconv.ParseUint(path[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid block number: %v", path[0])
+	}
+	if block, _ := s.backend.GetBlockByNumber(number); util.InterfaceIsNil(block) {
+		return nil, errExplorerNotFound
+	}
+
+	mineMaster, err := s.backend.GetMineMasterDIPReward(number)
+	if err != nil {
+		return nil, err
+	}
+	verifiers, err := s.backend.GetVerifierDIPReward(number)
+	if err != nil {
+		return nil, err
+	}
+	return &ExplorerRewardsResp{
+		Number:            number,
+		MineMaster:        (*hexutil.Big)(mineMaster),
+		MasterVerifier:    (*hexutil.Big)(verifiers[economy_model.MasterVerifier]),
+		CommitVerifier:    (*hexutil.Big)(verifiers[economy_model.CommitVerifier]),
+		NotCommitVerifier: (*hexutil.Big)(verifiers[economy_model.NotCommitVerifier]),
+	}, nil
+}
@@ -0,0 +1,309 @@
+// Copyright 2019, Keychain Foundation Ltd.
+// This file is part of the dipperin-core library.
+//
+// The dipperin-core library is free software: you can redistribute
+// it and/or modify it under the terms of the GNU Lesser General Public License
+// as published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// The dipperin-core library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rpc_interface
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/core/contract"
+	"github.com/dipperin/dipperin-core/core/dipperin/service"
+	"github.com/dipperin/dipperin-core/core/economy-model"
+	"github.com/dipperin/dipperin-core/core/model"
+	"github.com/dipperin/dipperin-core/third-party/crypto"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var (
+	explorerMiner1 = common.HexToAddress("0x000062be10f46b5d01ecd9b502c4ba3d6131f6fc2e41")
+	explorerMiner2 = common.HexToAddress("0x0000e447b8b7851d3fbd5c6a03625d288cfe9bb5ef0e")
+)
+
+type fakeExplorerBackend struct {
+	blocks []*model.Block
+	tx     *model.Transaction
+	err    error
+}
+
+func newFakeExplorerBackend() *fakeExplorerBackend {
+	b := &fakeExplorerBackend{}
+	for i := uint64(0); i < 5; i++ {
+		coinbase := explorerMiner1
+		if i%2 == 1 {
+			coinbase = explorerMiner2
+		}
+		header := model.NewHeader(1, i, common.Hash{}, common.Hash{}, common.HexToDiff("0x1fffffff"), big.NewInt(int64(i)), coinbase, common.BlockNonce{})
+		var txs []*model.Transaction
+		if i == 4 {
+			txs = append(txs, model.NewTransaction(0, explorerMiner2, big.NewInt(1), big.NewInt(1), nil))
+		}
+		b.blocks = append(b.blocks, model.NewBlock(header, txs, nil))
+	}
+	key, _ := crypto.GenerateKey()
+	b.tx, _ = model.NewTransaction(1, explorerMiner1, big.NewInt(10), big.NewInt(1), nil).SignTx(key, model.NewMercurySigner(big.NewInt(1)))
+	return b
+}
+
+func (b *fakeExplorerBackend) CurrentBlock() model.AbstractBlock {
+	return b.blocks[len(b.blocks)-1]
+}
+
+func (b *fakeExplorerBackend) GetBlockByNumber(number uint64) (model.AbstractBlock, error) {
+	if number >= uint64(len(b.blocks)) {
+		return (*model.Block)(nil), nil
+	}
+	return b.blocks[number], nil
+}
+
+func (b *fakeExplorerBackend) GetBlockByHash(hash common.Hash) (model.AbstractBlock, error) {
+	for _, block := range b.blocks {
+		if block.Hash().IsEqual(hash) {
+			return block, nil
+		}
+	}
+	return (*model.Block)(nil), nil
+}
+
+func (b *fakeExplorerBackend) GetSlot(block model.AbstractBlock) *uint64 {
+	slot := block.Number() / 2
+	return &slot
+}
+
+func (b *fakeExplorerBackend) Transaction(hash common.Hash) (*model.Transaction, common.Hash, uint64, uint64, error) {
+	if b.tx.CalTxId().IsEqual(hash) {
+		return b.tx, b.blocks[1].Hash(), 1, 0, nil
+	}
+	return nil, common.Hash{}, 0, 0, nil
+}
+
+func (b *fakeExplorerBackend) GetTransactionsByAddress(address common.Address, fromBlock, toBlock, limit uint64) ([]*service.AddressTransaction, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return []*service.AddressTransaction{{Transaction: b.tx, BlockHash: b.blocks[1].Hash(), BlockNumber: 1}}, nil
+}
+
+func (b *fakeExplorerBackend) GetTransactionNonce(addr common.Address) (uint64, error) {
+	return 3, nil
+}
+
+func (b *fakeExplorerBackend) VerifierStatus(addr common.Address) (string, *big.Int, *big.Int, uint64, bool, error) {
+	return "Registered", big.NewInt(100), big.NewInt(200), 10, true, b.err
+}
+
+func (b *fakeExplorerBackend) GetVerifiers(slotNum uint64) []common.Address {
+	return []common.Address{explorerMiner1, explorerMiner2}
+}
+
+func (b *fakeExplorerBackend) GetContract(contractAddr common.Address) (interface{}, error) {
+	return map[string]string{"name": "token"}, b.err
+}
+
+func (b *fakeExplorerBackend) GetContractInfo(eData *contract.ExtraDataForContract) (interface{}, error) {
+	return "0x10", b.err
+}
+
+func (b *fakeExplorerBackend) GetMineMasterDIPReward(blockNumber uint64) (*big.Int, error) {
+	return big.NewInt(100), b.err
+}
+
+func (b *fakeExplorerBackend) GetVerifierDIPReward(blockNumber uint64) (map[economy_model.VerifierType]*big.Int, error) {
+	return map[economy_model.VerifierType]*big.Int{
+		economy_model.MasterVerifier:    big.NewInt(3),
+		economy_model.CommitVerifier:    big.NewInt(2),
+		economy_model.NotCommitVerifier: big.NewInt(1),
+	}, b.err
+}
+
+func explorerGet(t *testing.T, s *ExplorerService, url string, result interface{}) int {
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+	if result != nil && w.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), result))
+	}
+	return w.Code
+}
+
+func TestExplorerService_Blocks(t *testing.T) {
+	backend := newFakeExplorerBackend()
+	s := MakeExplorerService("", backend)
+
+	var blocks ExplorerBlocksResp
+	assert.Equal(t, http.StatusOK, explorerGet(t, s, "/blocks?limit=2", &blocks))
+	assert.Len(t, blocks.Blocks, 2)
+	assert.Equal(t, uint64(4), blocks.Blocks[0].Number)
+	assert.Equal(t, 1, blocks.Blocks[0].TxCount)
+	assert.Equal(t, uint64(2), *blocks.Next)
+
+	blocks = ExplorerBlocksResp{}
+	assert.Equal(t, http.StatusOK, explorerGet(t, s, "/blocks?start=3&coinbase="+explorerMiner2.Hex(), &blocks))
+	assert.Len(t, blocks.Blocks, 2)
+	assert.Equal(t, uint64(3), blocks.Blocks[0].Number)
+	assert.Equal(t, uint64(1), blocks.Blocks[1].Number)
+	assert.Nil(t, blocks.Next)
+
+	blocks = ExplorerBlocksResp{}
+	assert.Equal(t, http.StatusOK, explorerGet(t, s, "/blocks?min_txs=1", &blocks))
+	assert.Len(t, blocks.Blocks, 1)
+
+	assert.Equal(t, http.StatusBadRequest, explorerGet(t, s, "/blocks?start=a", nil))
+	assert.Equal(t, http.StatusBadRequest, explorerGet(t, s, "/blocks?coinbase=0x12", nil))
+	assert.Equal(t, http.StatusBadRequest, explorerGet(t, s, "/blocks?min_txs=-1", nil))
+
+	var block BlockResp
+	assert.Equal(t, http.StatusOK, explorerGet(t, s, "/blocks/2", &block))
+	assert.Equal(t, uint64(2), block.Header.Number)
+	block = BlockResp{}
+	assert.Equal(t, http.StatusOK, explorerGet(t, s, "/blocks/"+backend.blocks[3].Hash().Hex(), &block))
+	assert.Equal(t, uint64(3), block.Header.Number)
+
+	assert.Equal(t, http.StatusNotFound, explorerGet(t, s, "/blocks/10", nil))
+	assert.Equal(t, http.StatusNotFound, explorerGet(t, s, "/blocks/"+common.Hash{}.Hex(), nil))
+	assert.Equal(t, http.StatusNotFound, explorerGet(t, s, "/blocks/1/2", nil))
+	assert.Equal(t, http.StatusBadRequest, explorerGet(t, s, "/blocks/a", nil))
+	assert.Equal(t, http.StatusBadRequest, explorerGet(t, s, "/blocks/0x12", nil))
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/blocks", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestExplorerService_Transactions(t *testing.T) {
+	backend := newFakeExplorerBackend()
+	s := MakeExplorerService("", backend)
+
+	var tx TransactionResp
+	assert.Equal(t, http.StatusOK, explorerGet(t, s, "/transactions/"+backend.tx.CalTxId().Hex(), &tx))
+	assert.Equal(t, uint64(1), tx.BlockNumber)
+	assert.Equal(t, backend.tx.CalTxId(), tx.Transaction.CalTxId())
+
+	assert.Equal(t, http.StatusNotFound, explorerGet(t, s, "/transactions/"+common.Hash{}.Hex(), nil))
+	assert.Equal(t, http.StatusNotFound, explorerGet(t, s, "/transactions/", nil))
+	assert.Equal(t, http.StatusBadRequest, explorerGet(t, s, "/transactions/0x12", nil))
+}
+
+func TestExplorerService_Accounts(t *testing.T) {
+	backend := newFakeExplorerBackend()
+	s := MakeExplorerService("", backend)
+
+	var account ExplorerAccountResp
+	assert.Equal(t, http.StatusOK, explorerGet(t, s, "/accounts/"+explorerMiner1.Hex(), &account))
+	assert.Equal(t, explorerMiner1, account.Address)
+	assert.Equal(t, big.NewInt(200), account.Balance.ToInt())
+	assert.Equal(t, big.NewInt(100), account.Stake.ToInt())
+	assert.Equal(t, uint64(3), account.Nonce)
+	assert.Equal(t, "Registered", account.VerifierStatus)
+	assert.True(t, account.IsCurrentVerifier)
+
+	var txs ExplorerAccountTxsResp
+	assert.Equal(t, http.StatusOK, explorerGet(t, s, "/accounts/"+explorerMiner1.Hex()+"/transactions?limit=1", &txs))
+	assert.Len(t, txs.Transactions, 1)
+	assert.Equal(t, uint64(2), *txs.NextFromBlock)
+
+	txs = ExplorerAccountTxsResp{}
+	assert.Equal(t, http.StatusOK, explorerGet(t, s, "/accounts/"+explorerMiner1.Hex()+"/transactions", &txs))
+	assert.Nil(t, txs.NextFromBlock)
+
+	assert.Equal(t, http.StatusBadRequest, explorerGet(t, s, "/accounts/0x12", nil))
+	assert.Equal(t, http.StatusNotFound, explorerGet(t, s, "/accounts/", nil))
+	assert.Equal(t, http.StatusNotFound, explorerGet(t, s, "/accounts/"+explorerMiner1.Hex()+"/blocks", nil))
+	assert.Equal(t, http.StatusBadRequest, explorerGet(t, s, "/accounts/"+explorerMiner1.Hex()+"/transactions?from_block=a", nil))
+	assert.Equal(t, http.StatusBadRequest, explorerGet(t, s, "/accounts/"+explorerMiner1.Hex()+"/transactions?to_block=a", nil))
+	assert.Equal(t, http.StatusBadRequest, explorerGet(t, s, "/accounts/"+explorerMiner1.Hex()+"/transactions?limit=a", nil))
+
+	backend.err = errors.New("test")
+	assert.Equal(t, http.StatusNotFound, explorerGet(t, s, "/accounts/"+explorerMiner1.Hex(), nil))
+	assert.Equal(t, http.StatusBadRequest, explorerGet(t, s, "/accounts/"+explorerMiner1.Hex()+"/transactions", nil))
+}
+
+func TestExplorerService_Verifiers(t *testing.T) {
+	s := MakeExplorerService("", newFakeExplorerBackend())
+
+	var verifiers ExplorerVerifiersResp
+	assert.Equal(t, http.StatusOK, explorerGet(t, s, "/verifiers", &verifiers))
+	assert.Equal(t, uint64(2), verifiers.Slot)
+	assert.Equal(t, []common.Address{explorerMiner1, explorerMiner2}, verifiers.Verifiers)
+
+	assert.Equal(t, http.StatusOK, explorerGet(t, s, "/verifiers?slot=4", &verifiers))
+	assert.Equal(t, uint64(4), verifiers.Slot)
+	assert.Equal(t, http.StatusBadRequest, explorerGet(t, s, "/verifiers?slot=a", nil))
+
+	// the verifiers are elected two slots ahead
+	assert.Equal(t, http.StatusBadRequest, explorerGet(t, s, "/verifiers?slot=5", nil))
+}
+
+func TestExplorerService_Tokens(t *testing.T) {
+	backend := newFakeExplorerBackend()
+	s := MakeExplorerService("", backend)
+
+	var token map[string]string
+	assert.Equal(t, http.StatusOK, explorerGet(t, s, "/tokens/"+explorerMiner1.Hex(), &token))
+	assert.Equal(t, "token", token["name"])
+
+	var balance ExplorerTokenBalanceResp
+	assert.Equal(t, http.StatusOK, explorerGet(t, s, "/tokens/"+explorerMiner1.Hex()+"/balances/"+explorerMiner2.Hex(), &balance))
+	assert.Equal(t, explorerMiner2, balance.Owner)
+	assert.Equal(t, "0x10", balance.Balance)
+
+	assert.Equal(t, http.StatusNotFound, explorerGet(t, s, "/tokens/"+explorerMiner1.Hex()+"/balances", nil))
+	assert.Equal(t, http.StatusBadRequest, explorerGet(t, s, "/tokens/0x12", nil))
+	assert.Equal(t, http.StatusBadRequest, explorerGet(t, s, "/tokens/"+explorerMiner1.Hex()+"/balances/0x12", nil))
+
+	backend.err = errors.New("test")
+	assert.Equal(t, http.StatusNotFound, explorerGet(t, s, "/tokens/"+explorerMiner1.Hex(), nil))
+	assert.Equal(t, http.StatusBadRequest, explorerGet(t, s, "/tokens/"+explorerMiner1.Hex()+"/balances/"+explorerMiner2.Hex(), nil))
+}
+
+func TestExplorerService_Rewards(t *testing.T) {
+	backend := newFakeExplorerBackend()
+	s := MakeExplorerService("", backend)
+
+	var rewards ExplorerRewardsResp
+	assert.Equal(t, http.StatusOK, explorerGet(t, s, "/rewards/2", &rewards))
+	assert.Equal(t, big.NewInt(100), rewards.MineMaster.ToInt())
+	assert.Equal(t, big.NewInt(3), rewards.MasterVerifier.ToInt())
+	assert.Equal(t, big.NewInt(2), rewards.CommitVerifier.ToInt())
+	assert.Equal(t, big.NewInt(1), rewards.NotCommitVerifier.ToInt())
+
+	assert.Equal(t, http.StatusNotFound, explorerGet(t, s, "/rewards/10", nil))
+	assert.Equal(t, http.StatusNotFound, explorerGet(t, s, "/rewards/", nil))
+	assert.Equal(t, http.StatusBadRequest, explorerGet(t, s, "/rewards/a", nil))
+
+	backend.err = errors.New("test")
+	assert.Equal(t, http.StatusBadRequest, explorerGet(t, s, "/rewards/2", nil))
+}
+
+func TestExplorerService_StartStop(t *testing.T) {
+	s := MakeExplorerService("", newFakeExplorerBackend())
+	assert.NoError(t, s.Start())
+	s.Stop()
+
+	s = MakeExplorerService("127.0.0.1:0", newFakeExplorerBackend())
+	assert.NoError(t, s.Start())
+	resp, err := http.Get("http://" + s.listener.Addr().String() + "/verifiers")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+	s.Stop()
+
+	s = MakeExplorerService("a:b:c", newFakeExplorerBackend())
+	assert.Error(t, s.Start())
+}
@@ -37,9 +37,26 @@ import (
 	"sort"
 	"github.com/dipperin/dipperin-core/common/g-timer"
 	"github.com/dipperin/dipperin-core/common/g-error"
+	"github.com/dipperin/dipperin-core/common/g-event"
 	"github.com/dipperin/dipperin-core/core/cs-chain/chain-writer/middleware"
 )
 
+var (
+	ErrReplaceUnderpriced = errors.New("new fee is too low to replace old one")
+)
+
+// the reasons why a queued transaction is not executable
+const (
+	QueuedNonceGap         = "nonce gap"
+	QueuedBalanceNotEnough = "insufficient balance"
+	QueuedWaitPromotion    = "waiting for promotion"
+)
+
+// NewTxsEvent is sent when transactions enter the pool
+type NewTxsEvent struct {
+	Txs []model.AbstractTransaction
+}
+
 //go:generate mockgen -destination=./block_chain_mock_test.go -package=tx_pool github.com/caiqingfeng/dipperin-core/core/tx-pool BlockChain
 type BlockChain interface {
 	CurrentBlock() model.AbstractBlock
@@ -336,7 +353,7 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx model.AbstractTransaction) (b
 
 	// insertion fails, means the replace transaction fee does not exceed the FeeBump
 	if !inserted {
-		return false, errors.New("new fee is too low to replace old one")
+		return false, ErrReplaceUnderpriced
 	}
 	// insertion success and replace an old transaction , and old transaction  should be removed
 	if old != nil {
@@ -402,6 +419,18 @@ func (pool *TxPool) validateTx(tx model.AbstractTransaction, local bool) error {
 	if err != nil || curBalance.Cmp(tx.Cost()) < 0 {
 		return errors.New(fmt.Sprintf("tx exceed balance limit, from:%v, cur balance:%v, cost:%v, err:%v", from.Hex(), curBalance.String(), tx.Cost().String(), err))
 	}
+	// the owners signatures of the multi signature tx are checked against the current state
+	if tx.GetType() == common.AddressTypeMultiSig {
+		if err := pool.currentState.ValidMultiSigTx(tx); err != nil {
+			return err
+		}
+	}
+	// the delegated verifier and the unbonding period are checked against the current state
+	if tx.GetType() == common.AddressTypeDelegate || tx.GetType() == common.AddressTypeUnDelegate {
+		if err := pool.currentState.ValidDelegationTx(tx, pool.chain.CurrentBlock().Number()+1); err != nil {
+			return err
+		}
+	}
 	//TODO Add economy validator
 	return nil
 }
@@ -445,7 +474,7 @@ func (pool *TxPool) add(tx model.AbstractTransaction, local bool) (bool, error)
 
 		// add failed, which means the fee is too low to replace the old one
 		if !inserted {
-			return false, errors.New("new fee is too low to replace old one")
+			return false, ErrReplaceUnderpriced
 		}
 
 		// New transaction is replace an old transaction ,so need remove the old transaction.
@@ -561,6 +590,55 @@ func (pool *TxPool) Queueing() (map[common.Address][]model.AbstractTransaction,
 	return queueing, nil
 }
 
+// QueuedTx is a transaction in the future queue with the reason it is not executable
+type QueuedTx struct {
+	Tx     model.AbstractTransaction
+	Reason string
+}
+
+// ContentFrom returns the pending and the queued transactions of the address sorted by nonce.
+// The queued transactions after a missing nonce are nonce gap, the ones the balance can't cover
+// together with the transactions before them are insufficient balance
+func (pool *TxPool) ContentFrom(addr common.Address) ([]model.AbstractTransaction, []QueuedTx) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	var pending []model.AbstractTransaction
+	if list := pool.pending[addr]; list != nil {
+		pending = list.Flatten()
+	}
+	list := pool.queue[addr]
+	if list == nil {
+		return pending, nil
+	}
+
+	balance, err := pool.currentState.GetBalance(addr)
+	if err != nil {
+		balance = big.NewInt(0)
+	}
+	cost := big.NewInt(0)
+	for _, tx := range pending {
+		cost.Add(cost, tx.Cost())
+	}
+
+	nonce := pool.pendingState.GetNonce(addr)
+	var queued []QueuedTx
+	for _, tx := range list.Flatten() {
+		cost.Add(cost, tx.Cost())
+		reason := QueuedWaitPromotion
+		if tx.Nonce() > nonce {
+			reason = QueuedNonceGap
+		} else if balance.Cmp(cost) < 0 {
+			reason = QueuedBalanceNotEnough
+		}
+		if tx.Nonce() == nonce {
+			nonce++
+		}
+		queued = append(queued, QueuedTx{Tx: tx, Reason: reason})
+	}
+	return pending, queued
+}
+
 // addressByHeartbeat is an account address tagged with its last activity timestamp.
 type addressByHeartbeat struct {
 	address   common.Address
@@ -896,6 +974,7 @@ func (pool *TxPool) addTx(tx model.AbstractTransaction, local bool) error {
 		pool.promoteExecutables([]common.Address{from})
 	}
 	pbft_log.Debug("Add tx success", "txid", tx.CalTxId().Hex())
+	pool.sendNewTxsEvent([]model.AbstractTransaction{tx})
 	return nil
 }
 
@@ -913,6 +992,7 @@ func (pool *TxPool) addTxsLocked(txs []model.AbstractTransaction, local bool) []
 	// Add the batch of transaction, tracking the accepted ones
 	dirty := make(map[common.Address]struct{})
 	errs := make([]error, len(txs))
+	var added []model.AbstractTransaction
 
 	for i, tx := range txs {
 		var replace bool
@@ -924,6 +1004,9 @@ func (pool *TxPool) addTxsLocked(txs []model.AbstractTransaction, local bool) []
 			//txSender, _ := tx.Sender(nil)
 			//log.Warn("add tx to pool failed", "err", errs[i], "tx sender", txSender.Hex())
 		}
+		if errs[i] == nil {
+			added = append(added, tx)
+		}
 	}
 	// Only reprocess the internal state if something was actually added
 	if len(dirty) > 0 {
@@ -933,9 +1016,18 @@ func (pool *TxPool) addTxsLocked(txs []model.AbstractTransaction, local bool) []
 		}
 		pool.promoteExecutables(addrs)
 	}
+	pool.sendNewTxsEvent(added)
 	return errs
 }
 
+// sendNewTxsEvent notify the subscribers without holding the pool lock
+func (pool *TxPool) sendNewTxsEvent(txs []model.AbstractTransaction) {
+	if len(txs) == 0 {
+		return
+	}
+	go g_event.Send(g_event.NewTxInPoolEvent, NewTxsEvent{Txs: txs})
+}
+
 // AddLocal enqueues a single transaction into the pool if it is valid, marking
 // the sender as a local one in the mean time, ensuring it goes around the local
 // pricing constraints.
@@ -21,6 +21,7 @@ import (
 	"crypto/ecdsa"
 	"fmt"
 	"github.com/dipperin/dipperin-core/common"
+	"github.com/dipperin/dipperin-core/common/g-event"
 	"github.com/dipperin/dipperin-core/common/consts"
 	"github.com/dipperin/dipperin-core/common/g-error"
 	"github.com/dipperin/dipperin-core/common/util"
@@ -55,6 +56,7 @@ var testTxPoolConfig TxPoolConfig
 var ms = model.NewMercurySigner(big.NewInt(1))
 
 func init() {
+	g_event.Add(g_event.NewTxInPoolEvent)
 	testTxPoolConfig = DefaultTxPoolConfig
 	testTxPoolConfig.NoLocals = true
 	testTxPoolConfig.GlobalSlots = 4096
@@ -283,6 +285,12 @@ func TestTxPool_validateTx(t *testing.T) {
 	curBalance, e := pool.currentState.GetBalance(aliceAddr)
 	err = pool.validateTx(signedTx5, false)
 	assert.EqualError(t, err, fmt.Sprintf("tx exceed balance limit, from:%v, cur balance:%v, cost:%v, err:%v", aliceAddr.Hex(), curBalance.String(), signedTx5.Cost().String(), e))
+
+	// deposit to the multi signature account not registered
+	keySet := &model.MultiSigKeySet{Threshold: 1, Owners: []common.Address{aliceAddr, bobAddr}}
+	signedTx6 := transaction(40, keySet.Address(), big.NewInt(1), testTxFee, key1)
+	err = pool.validateTx(signedTx6, false)
+	assert.Equal(t, g_error.AccountNotExist, err)
 }
 
 func TestTxPool_Pending(t *testing.T) {
@@ -388,6 +396,57 @@ func TestTxPool_Add(t *testing.T) {
 
 }
 
+func TestTxPool_ReplacePending(t *testing.T) {
+	pool := setupTxPool()
+	key1, key2, _ := createKey()
+	bobAddr := cs_crypto.GetNormalAddress(key2.PublicKey)
+
+	tx1 := transaction(20, bobAddr, big.NewInt(5000), testTxFee, key1)
+	assert.True(t, pool.promoteTx(cs_crypto.GetNormalAddress(key1.PublicKey), tx1.CalTxId(), tx1))
+
+	// the fee bump is not enough
+	tx2 := transaction(20, bobAddr, big.NewInt(0), new(big.Int).Add(testTxFee, big.NewInt(1)), key1)
+	replaced, err := pool.add(tx2, false)
+	assert.Equal(t, ErrReplaceUnderpriced, err)
+	assert.False(t, replaced)
+
+	tx3 := transaction(20, bobAddr, big.NewInt(0), new(big.Int).Mul(testTxFee, big.NewInt(2)), key1)
+	replaced, err = pool.add(tx3, false)
+	assert.NoError(t, err)
+	assert.True(t, replaced)
+	assert.Nil(t, pool.Get(tx1.CalTxId()))
+	assert.NotNil(t, pool.Get(tx3.CalTxId()))
+}
+
+func TestTxPool_ContentFrom(t *testing.T) {
+	pool := setupTxPool()
+	key1, key2, _ := createKey()
+	aliceAddr := cs_crypto.GetNormalAddress(key1.PublicKey)
+	bobAddr := cs_crypto.GetNormalAddress(key2.PublicKey)
+
+	pending, queued := pool.ContentFrom(aliceAddr)
+	assert.Len(t, pending, 0)
+	assert.Len(t, queued, 0)
+
+	tx20 := transaction(20, bobAddr, big.NewInt(1000), testTxFee, key1)
+	assert.True(t, pool.promoteTx(aliceAddr, tx20.CalTxId(), tx20))
+	tx21 := transaction(21, bobAddr, big.NewInt(100), testTxFee, key1)
+	tx22 := transaction(22, bobAddr, big.NewInt(999000), testTxFee, key1)
+	tx24 := transaction(24, bobAddr, big.NewInt(100), testTxFee, key1)
+	for _, tx := range []model.AbstractTransaction{tx24, tx22, tx21} {
+		_, err := pool.enqueueTx(tx.CalTxId(), tx)
+		assert.NoError(t, err)
+	}
+
+	pending, queued = pool.ContentFrom(aliceAddr)
+	assert.Equal(t, []model.AbstractTransaction{tx20}, pending)
+	assert.Equal(t, []QueuedTx{
+		{Tx: tx21, Reason: QueuedWaitPromotion},
+		{Tx: tx22, Reason: QueuedBalanceNotEnough},
+		{Tx: tx24, Reason: QueuedNonceGap},
+	}, queued)
+}
+
 func TestTxPool_removeTx(t *testing.T) {
 	pool := setupTxPool()
 	key1, key2, _ := createKey()
@@ -537,6 +596,40 @@ func TestTxPool_AddLocal(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestTxPool_NewTxsEvent(t *testing.T) {
+	pool := setupTxPool()
+	key1, key2, _ := createKey()
+	aliceAddr := cs_crypto.GetNormalAddress(key1.PublicKey)
+
+	txsCh := make(chan NewTxsEvent, 10)
+	sub := g_event.Subscribe(g_event.NewTxInPoolEvent, txsCh)
+	defer sub.Unsubscribe()
+
+	// skip the events sent by other pools
+	waitEvent := func(first common.Hash) NewTxsEvent {
+		for {
+			select {
+			case e := <-txsCh:
+				if e.Txs[0].CalTxId() == first {
+					return e
+				}
+			case <-time.After(time.Second):
+				t.Fatal("no new txs event")
+			}
+		}
+	}
+
+	tx := transaction(uint64(30), aliceAddr, big.NewInt(1), testTxFee, key2)
+	assert.NoError(t, pool.AddLocal(tx))
+	waitEvent(tx.CalTxId())
+
+	// only the accepted transactions
+	tx2 := transaction(uint64(31), aliceAddr, big.NewInt(1), testTxFee, key2)
+	errs := pool.AddRemotes([]model.AbstractTransaction{tx, tx2})
+	assert.Error(t, errs[0])
+	assert.Len(t, waitEvent(tx2.CalTxId()).Txs, 1)
+}
+
 func TestTxPool_LocalAdd(t *testing.T) {
 	pool := setupTxPool()
 	key1, key2, _ := createKey()
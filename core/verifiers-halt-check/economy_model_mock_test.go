@@ -213,6 +213,20 @@ func (mr *MockEconomyModelMockRecorder) GetOneBlockTotalDIPReward(arg0 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOneBlockTotalDIPReward", reflect.TypeOf((*MockEconomyModel)(nil).GetOneBlockTotalDIPReward), arg0)
 }
 
+// ShareRewardWithDelegators mocks base method
+func (m *MockEconomyModel) ShareRewardWithDelegators(arg0 uint64) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShareRewardWithDelegators", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// ShareRewardWithDelegators indicates an expected call of ShareRewardWithDelegators
+func (mr *MockEconomyModelMockRecorder) ShareRewardWithDelegators(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShareRewardWithDelegators", reflect.TypeOf((*MockEconomyModel)(nil).ShareRewardWithDelegators), arg0)
+}
+
 // GetVerifierDIPReward mocks base method
 func (m *MockEconomyModel) GetVerifierDIPReward(arg0 model.AbstractBlock) (map[economy_model.VerifierType]*big.Int, error) {
 	m.ctrl.T.Helper()
@@ -112,6 +112,10 @@ func (em fakeEconomyModel) GetOneBlockTotalDIPReward(blockNumber uint64) (*big.I
 	panic("implement me")
 }
 
+func (em fakeEconomyModel) ShareRewardWithDelegators(blockNumber uint64) bool {
+	panic("implement me")
+}
+
 func (em fakeEconomyModel) GetMineMasterDIPReward(block model.AbstractBlock) (*big.Int, error) {
 	panic("implement me")
 }
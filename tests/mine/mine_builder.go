@@ -66,6 +66,18 @@ func (m *fakeMaster) GetPerformance(address common.Address) uint64 {
 	panic("implement me")
 }
 
+func (m *fakeMaster) WorkerStats() []minemaster.WorkerStats {
+	panic("implement me")
+}
+
+func (m *fakeMaster) KickWorker(workerId minemaster.WorkerId) error {
+	panic("implement me")
+}
+
+func (m *fakeMaster) SetWorkerCoinbase(workerId minemaster.WorkerId, coinbase common.Address) error {
+	panic("implement me")
+}
+
 func (m *fakeMaster) Mining() bool {
 	panic("implement me")
 }
@@ -28,7 +28,8 @@ func PeerBuilder() chain_communication.PmAbstractPeer {
 }
 
 type FakePeer struct {
-	TestMsg uint64
+	TestMsg      uint64
+	Disconnected bool
 }
 
 func (p *FakePeer) NodeName() string {
@@ -65,7 +66,7 @@ func (p *FakePeer) GetPeerRawUrl() string {
 }
 
 func (p *FakePeer) DisconnectPeer() {
-	panic("implement me")
+	p.Disconnected = true
 }
 
 func (p *FakePeer) RemoteVerifierAddress() (addr common.Address) {
@@ -97,6 +97,30 @@ func GetLockAddress(alice,bob common.Address)common.Address{
 	return common.BytesToAddress(append(tmpTypeB[:], tmpAddr...))
 }
 
+// the address used to save cross chain data of chainId in state, tag tells what the data is
+func GetCrossChainDataAddress(tag string, chainId *big.Int, hash common.Hash) common.Address {
+	res, err := rlp.EncodeToBytes([]interface{}{tag, chainId, hash})
+	if err != nil {
+		return common.Address{}
+	}
+	var tmpTypeB [2]byte
+	binary.BigEndian.PutUint16(tmpTypeB[:], uint16(common.AddressTypeCross))
+	tmpAddr := crypto.Keccak256(res[:])[12:]
+	return common.BytesToAddress(append(tmpTypeB[:], tmpAddr...))
+}
+
+// the multi signature address commits to the threshold and the owners of the key set
+func GetMultiSigAddress(threshold uint64, owners []common.Address) common.Address {
+	res, err := rlp.EncodeToBytes([]interface{}{threshold, owners})
+	if err != nil {
+		return common.Address{}
+	}
+	var tmpTypeB [2]byte
+	binary.BigEndian.PutUint16(tmpTypeB[:], uint16(common.AddressTypeMultiSig))
+	tmpAddr := crypto.Keccak256(res[:])[12:]
+	return common.BytesToAddress(append(tmpTypeB[:], tmpAddr...))
+}
+
 func GetEvidenceAddress(target common.Address) common.Address{
 	var tmpType [2]byte
 	binary.BigEndian.PutUint16(tmpType[:], uint16(common.AddressTypeEvidence))
@@ -117,6 +141,20 @@ func GetNormalAddressFromEvidence(target common.Address) common.Address{
 	return common.BytesToAddress(evAdd)
 }
 
+// GetDelegateAddress the delegate tx is sent to the verifier address with the delegate type
+func GetDelegateAddress(verifier common.Address) common.Address {
+	var tmpType [2]byte
+	binary.BigEndian.PutUint16(tmpType[:], uint16(common.AddressTypeDelegate))
+	return common.BytesToAddress(append(tmpType[:], verifier[2:]...))
+}
+
+// GetUnDelegateAddress the undelegate tx is sent to the verifier address with the undelegate type
+func GetUnDelegateAddress(verifier common.Address) common.Address {
+	var tmpType [2]byte
+	binary.BigEndian.PutUint16(tmpType[:], uint16(common.AddressTypeUnDelegate))
+	return common.BytesToAddress(append(tmpType[:], verifier[2:]...))
+}
+
 func GetContractAddress(address common.Address) common.Address {
 	var tmpType [2]byte
 	binary.BigEndian.PutUint16(tmpType[:], uint16(common.AddressTypeERC20))
@@ -378,6 +378,18 @@ func (db *Database) preimage(hash common.Hash) ([]byte, error) {
 	return db.diskdb.Get(db.secureKey(hash[:]))
 }
 
+// Preimage retrieves a secure trie key pre-image from memory or disk, the keys
+// of the contract data tries are read with them after the state sync.
+func (db *Database) Preimage(hash common.Hash) ([]byte, error) {
+	return db.preimage(hash)
+}
+
+// WritePreimage writes a secure trie key pre-image downloaded by the state sync.
+func WritePreimage(db ethdb.Putter, hash common.Hash, preimage []byte) error {
+	key := append(append([]byte{}, secureKeyPrefix...), hash[:]...)
+	return db.Put(key, preimage)
+}
+
 // secureKey returns the database key for the preimage of key, as an ephemeral
 // buffer. The caller must not hold onto the return value because it will become
 // invalid on the next call.